@@ -0,0 +1,48 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+)
+
+const bundleHelp = `
+This command consists of multiple subcommands to move charts into air-gapped
+environments.
+
+A bundle is a single archive holding a packaged chart with its dependencies,
+its provenance file when there is one, and the list of the container images
+used by the chart. Export a bundle where the chart is available, copy it to
+the air-gapped environment, then import it into a registry or a directory.
+`
+
+func newBundleCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "export and import charts for air-gapped environments",
+		Long:  bundleHelp,
+	}
+	cmd.AddCommand(
+		newBundleExportCmd(out),
+		newBundleImportCmd(cfg, out),
+	)
+	return cmd
+}
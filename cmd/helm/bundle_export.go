@@ -0,0 +1,74 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/cmd/helm/require"
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/values"
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+const bundleExportDesc = `
+Export a chart to a bundle.
+
+The chart can be a chart directory or a packaged chart. Its dependencies must
+already be in its charts/ directory; run 'helm dependency build' first if
+needed. The provenance file of a packaged chart is included when it exists.
+
+The images are found by rendering the chart with the given values and
+collecting every "image" field of the manifests. Images that do not appear in
+the manifests can be added with '--image'.
+
+  $ helm bundle export ./mychart-0.1.0.tgz -f production.yaml --image busybox:1.36
+`
+
+func newBundleExportCmd(out io.Writer) *cobra.Command {
+	client := action.NewBundleExport()
+	valueOpts := &values.Options{}
+
+	cmd := &cobra.Command{
+		Use:   "export [CHART]",
+		Short: "export a chart to a bundle",
+		Long:  bundleExportDesc,
+		Args:  require.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			vals, err := valueOpts.MergeValues(getter.All(settings))
+			if err != nil {
+				return err
+			}
+			p, err := client.Run(args[0], vals)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Successfully exported chart to bundle: %s\n", p)
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&client.Destination, "destination", "d", ".", "location to write the bundle")
+	f.StringArrayVar(&client.Images, "image", []string{}, "additional image to list in the bundle (can specify multiple)")
+	addValueOptionsFlags(f, valueOpts)
+
+	return cmd
+}
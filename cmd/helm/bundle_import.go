@@ -0,0 +1,90 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/cmd/helm/require"
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/registry"
+)
+
+const bundleImportDesc = `
+Import a bundle into a registry or a directory.
+
+The digests of the bundle are checked before anything is imported. When the
+destination is an OCI registry (oci://), the chart and its provenance file are
+pushed to it. Otherwise the destination is a directory, such as the repository
+cache, where the chart and its provenance file are written.
+
+The images listed in the bundle are printed so that they can be mirrored with
+the tools of your registry.
+
+  $ helm bundle import mychart-0.1.0-bundle.tgz oci://registry.internal/charts
+`
+
+func newBundleImportCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewBundleImport(cfg)
+	o := &registryPushOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "import [BUNDLE] [DESTINATION]",
+		Short: "import a bundle into a registry or a directory",
+		Long:  bundleImportDesc,
+		Args:  require.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if registry.IsOCI(args[1]) {
+				registryClient, err := newRegistryClient(
+					o.certFile, o.keyFile, o.caFile, o.insecureSkipTLSverify, o.plainHTTP, o.username, o.password,
+				)
+				if err != nil {
+					return fmt.Errorf("missing registry client: %w", err)
+				}
+				cfg.RegistryClient = registryClient
+			}
+			res, err := client.Run(args[0], args[1])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Imported %s %s to %s\n", res.Manifest.Chart.Name, res.Manifest.Chart.Version, res.Ref)
+			if len(res.Manifest.Images) > 0 {
+				fmt.Fprintln(out, "Images used by the chart:")
+				for _, image := range res.Manifest.Images {
+					fmt.Fprintf(out, "  %s\n", image)
+				}
+			}
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&client.Verify, "verify", false, "verify the provenance of the chart before importing it")
+	f.StringVar(&client.Keyring, "keyring", defaultKeyring(), "location of public keys used for verification")
+	f.StringVar(&o.certFile, "cert-file", "", "identify registry client using this SSL certificate file")
+	f.StringVar(&o.keyFile, "key-file", "", "identify registry client using this SSL key file")
+	f.StringVar(&o.caFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
+	f.BoolVar(&o.insecureSkipTLSverify, "insecure-skip-tls-verify", false, "skip tls certificate checks for the chart upload")
+	f.BoolVar(&o.plainHTTP, "plain-http", false, "use insecure HTTP connections for the chart upload")
+	f.StringVar(&o.username, "username", "", "registry username")
+	f.StringVar(&o.password, "password", "", "registry password")
+
+	return cmd
+}
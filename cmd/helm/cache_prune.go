@@ -0,0 +1,77 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"helm.sh/helm/v4/cmd/helm/require"
+	"helm.sh/helm/v4/pkg/action"
+)
+
+const cachePruneDesc = `
+This command removes the content of the cache which does not match its digest,
+then evicts the least recently used content until the cache is at most the
+maximum size, which defaults to $HELM_CONTENT_CACHE_MAX_SIZE.
+
+Use '--all' to empty the cache.
+`
+
+func newCachePruneCmd(out io.Writer) *cobra.Command {
+	client := action.NewCachePrune(settings)
+	var maxSize string
+
+	cmd := &cobra.Command{
+		Use:               "prune",
+		Short:             "remove corrupted and least recently used content from the cache",
+		Long:              cachePruneDesc,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			// The settings may have been changed by flags since the action
+			// was created.
+			client.Settings = settings
+			client.MaxSize = settings.ContentCacheMaxSize
+			if maxSize != "" {
+				q, err := resource.ParseQuantity(maxSize)
+				if err != nil {
+					return errors.Wrapf(err, "invalid maximum size %q", maxSize)
+				}
+				client.MaxSize = q.Value()
+			}
+
+			removed, err := client.Run()
+			var size int64
+			for _, e := range removed {
+				size += e.Size
+			}
+			fmt.Fprintf(out, "Removed %d entries (%s) from the content cache\n", len(removed), formatSize(size))
+			return err
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&maxSize, "max-size", "", "maximum size of the cache after pruning, e.g. 500Mi")
+	f.BoolVar(&client.All, "all", false, "remove all the content of the cache")
+
+	return cmd
+}
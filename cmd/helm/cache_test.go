@@ -0,0 +1,92 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/downloader"
+)
+
+func TestCacheCmd(t *testing.T) {
+	dir := t.TempDir()
+	cache := &downloader.DiskCache{Root: dir}
+	for _, content := range []string{"first", "second"} {
+		if _, err := cache.Put(sha256.Sum256([]byte(content)), []byte(content), downloader.CacheChart); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, out, err := executeActionCommand(fmt.Sprintf("cache list --content-cache %s -o json", dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("first")))
+	if !strings.Contains(out, digest) {
+		t.Errorf("expected %s to be listed, got %s", digest, out)
+	}
+
+	_, out, err = executeActionCommand(fmt.Sprintf("cache prune --content-cache %s --max-size 6", dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "Removed 1 entries (5 B) from the content cache\n"; out != expect {
+		t.Errorf("expected %q, got %q", expect, out)
+	}
+
+	_, out, err = executeActionCommand(fmt.Sprintf("cache prune --content-cache %s --all", dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "Removed 1 entries (6 B) from the content cache\n"; out != expect {
+		t.Errorf("expected %q, got %q", expect, out)
+	}
+
+	_, out, err = executeActionCommand(fmt.Sprintf("cache list --content-cache %s -o json", dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "[]\n" {
+		t.Errorf("expected an empty list, got %q", out)
+	}
+
+	if _, _, err := executeActionCommand("cache prune --max-size lots"); err == nil {
+		t.Error("expected an error for an invalid maximum size")
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	for size, expect := range map[int64]string{
+		0:         "0 B",
+		1023:      "1023 B",
+		1024:      "1.0 KiB",
+		1536:      "1.5 KiB",
+		5 << 30:   "5.0 GiB",
+		123 << 20: "123.0 MiB",
+	} {
+		if got := formatSize(size); got != expect {
+			t.Errorf("formatSize(%d): expected %q, got %q", size, expect, got)
+		}
+	}
+}
+
+func TestCacheListOutputCompletion(t *testing.T) {
+	outputFlagCompletionTest(t, "cache list")
+}
@@ -0,0 +1,96 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+	"log"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/cmd/helm/require"
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cli/values"
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+const dependencyGraphDesc = `
+Print the dependency graph of a chart.
+
+The graph contains the transitive dependencies of the chart found in the
+'charts/' directories, including aliased dependencies and charts that are not
+declared in 'Chart.yaml'. The conditions, tags and enabledWhen expressions of
+the dependencies are evaluated against the chart values and the values given
+with the usual flags.
+
+The graph is printed in the DOT language of Graphviz by default:
+
+    $ helm dependency graph mychart | dot -Tsvg > mychart.svg
+
+In DOT output, edges are labeled with the declared version ranges, disabled
+charts are dashed and charts missing from 'charts/' are red. Use '--output json'
+for a machine-readable tree.
+`
+
+func newDependencyGraphCmd(out io.Writer) *cobra.Command {
+	client := action.NewDependency()
+	valueOpts := &values.Options{}
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "graph CHART",
+		Short: "print the dependency graph of the given chart",
+		Long:  dependencyGraphDesc,
+		Args:  require.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			chartpath := "."
+			if len(args) > 0 {
+				chartpath = filepath.Clean(args[0])
+			}
+			if format != "dot" && format != "json" {
+				return errors.Errorf("invalid output format %q, expected dot or json", format)
+			}
+			vals, err := valueOpts.MergeValues(getter.All(settings))
+			if err != nil {
+				return err
+			}
+			graph, err := client.Graph(chartpath, vals)
+			if err != nil {
+				return err
+			}
+			if format == "json" {
+				return output.EncodeJSON(out, graph)
+			}
+			return graph.WriteDOT(out)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&format, outputFlag, "o", "dot", "prints the graph in the specified format. Allowed values: dot, json")
+	err := cmd.RegisterFlagCompletionFunc(outputFlag, func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"dot", "json"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	addValueOptionsFlags(f, valueOpts)
+
+	return cmd
+}
@@ -16,7 +16,11 @@ limitations under the License.
 package main
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -52,6 +56,49 @@ func TestDependencyListCmd(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func TestDependencyGraphCmd(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "DOT graph",
+		cmd:    "dependency graph testdata/testcharts/reqtest",
+		golden: "output/dependency-graph.txt",
+	}, {
+		name:   "JSON graph",
+		cmd:    "dependency graph testdata/testcharts/reqtest --output json",
+		golden: "output/dependency-graph.json",
+	}, {
+		name:      "invalid format",
+		cmd:       "dependency graph testdata/testcharts/reqtest --output yaml",
+		golden:    "output/dependency-graph-invalid-format.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestDependencyVendorCmd(t *testing.T) {
+	dest := t.TempDir()
+	_, out, err := executeActionCommand(fmt.Sprintf("dependency vendor testdata/testcharts/subchart --destination %s --set subchartb.enabled=false", dest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	outdir := filepath.Join(dest, "subchart")
+	if expect := fmt.Sprintf("Vendored the chart to %s\n", outdir); out != expect {
+		t.Errorf("expected %q, got %q", expect, out)
+	}
+	if _, err := os.Stat(filepath.Join(outdir, "charts", "subcharta", "Chart.yaml")); err != nil {
+		t.Error(err)
+	}
+	if _, err := os.Stat(filepath.Join(outdir, "charts", "subchartb")); !os.IsNotExist(err) {
+		t.Errorf("expected the disabled dependency to be left out, got %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(outdir, "values.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "imported-chartA:") {
+		t.Errorf("expected the imported values in values.yaml, got:\n%s", data)
+	}
+}
+
 func TestDependencyFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "dependency", false)
 }
@@ -58,6 +58,10 @@ func newDependencyUpdateCmd(_ *action.Configuration, out io.Writer) *cobra.Comma
 			if len(args) > 0 {
 				chartpath = filepath.Clean(args[0])
 			}
+			repoOpts, err := client.RepositoryOptions()
+			if err != nil {
+				return err
+			}
 			registryClient, err := newRegistryClient(client.CertFile, client.KeyFile, client.CaFile,
 				client.InsecureSkipTLSverify, client.PlainHTTP, client.Username, client.Password)
 			if err != nil {
@@ -65,15 +69,18 @@ func newDependencyUpdateCmd(_ *action.Configuration, out io.Writer) *cobra.Comma
 			}
 
 			man := &downloader.Manager{
-				Out:              out,
-				ChartPath:        chartpath,
-				Keyring:          client.Keyring,
-				SkipUpdate:       client.SkipRefresh,
-				Getters:          getter.All(settings),
-				RegistryClient:   registryClient,
-				RepositoryConfig: settings.RepositoryConfig,
-				RepositoryCache:  settings.RepositoryCache,
-				Debug:            settings.Debug,
+				Out:                 out,
+				ChartPath:           chartpath,
+				Keyring:             client.Keyring,
+				SkipUpdate:          client.SkipRefresh,
+				Getters:             getter.All(settings),
+				RegistryClient:      registryClient,
+				RepositoryConfig:    settings.RepositoryConfig,
+				RepositoryConfigDir: settings.RepositoryConfigDir,
+				RepositoryCache:     settings.RepositoryCache,
+				Cache:               action.ContentCache(settings),
+				Debug:               settings.Debug,
+				RepositoryOptions:   repoOpts,
 			}
 			if client.Verify {
 				man.Verify = downloader.VerifyAlways
@@ -0,0 +1,85 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/cmd/helm/require"
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/values"
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+const dependencyVendorDesc = `
+Write a flattened copy of a chart which can be installed without fetching any
+dependency.
+
+The dependencies of the chart must be present in 'charts/', see
+'helm dependency build'. In the copy:
+
+- the dependencies are expanded as directories in 'charts/', recursively, and
+  the dependencies disabled by the values are left out,
+- the aliases of the dependencies are applied to their names,
+- 'values.yaml' contains the resolved values of the chart and its dependencies,
+  including the imported values and the values given with the usual flags,
+- the 'Chart.lock' files record where the dependencies came from.
+
+The copy is written to a directory named after the chart in the destination:
+
+    $ helm dependency build mychart
+    $ helm dependency vendor mychart --destination vendored
+`
+
+func newDependencyVendorCmd(out io.Writer) *cobra.Command {
+	client := action.NewDependency()
+	valueOpts := &values.Options{}
+	var dest string
+
+	cmd := &cobra.Command{
+		Use:   "vendor CHART",
+		Short: "write a copy of the given chart with its dependencies flattened",
+		Long:  dependencyVendorDesc,
+		Args:  require.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			chartpath := "."
+			if len(args) > 0 {
+				chartpath = filepath.Clean(args[0])
+			}
+			vals, err := valueOpts.MergeValues(getter.All(settings))
+			if err != nil {
+				return err
+			}
+			outdir, err := client.Vendor(chartpath, dest, vals)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Vendored the chart to %s\n", outdir)
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&dest, "destination", "d", ".", "location to write the chart to")
+	addValueOptionsFlags(f, valueOpts)
+
+	return cmd
+}
@@ -0,0 +1,102 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/cmd/helm/require"
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+)
+
+const doctorDesc = `
+Diagnose the environment of Helm.
+
+This command checks the local environment of Helm: the repositories file and
+the cached indexes of the repositories, the content cache, the installed
+plugins and the reachability of the registries Helm is logged in to. It then
+checks that the cluster is reachable, that the releases can be read from the
+storage, and that you are allowed to manage releases in the namespace.
+
+The checks do not modify anything. Each problem found is reported with the
+command fixing it, if any. The command fails when a check fails, which makes
+its report, e.g. with '--output json', suitable to attach to a support request.
+
+Use '--client-only' to skip the checks of the cluster.
+`
+
+func newDoctorCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewDoctor(cfg, settings)
+	var outfmt output.Format
+
+	cmd := &cobra.Command{
+		Use:               "doctor",
+		Short:             "diagnose the environment of Helm",
+		Long:              doctorDesc,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			report := client.Run(context.Background())
+			if err := outfmt.Write(out, &doctorWriter{report}); err != nil {
+				return err
+			}
+			if failed := report.Failed(); len(failed) > 0 {
+				return fmt.Errorf("%d of %d checks failed", len(failed), len(report.Diagnostics))
+			}
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&client.ClientOnly, "client-only", false, "skip the checks of the cluster")
+	// The checks do not wait for Kubernetes operations, so the timeout keeps
+	// the short default of the action rather than the one of addTimeoutFlag.
+	f.Var(&timeoutValue{timeout: &client.Timeout}, "timeout", "time to wait for each registry to reply, as a duration such as 10s or 1m, or an RFC 3339 deadline")
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+type doctorWriter struct {
+	report *action.DoctorReport
+}
+
+func (w *doctorWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	table.AddRow("CHECK", "STATUS", "MESSAGE")
+	for _, d := range w.report.Diagnostics {
+		table.AddRow(d.Check, d.Status, d.Message)
+		for _, detail := range d.Details {
+			table.AddRow("", "", "- "+detail)
+		}
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (w *doctorWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.report)
+}
+
+func (w *doctorWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.report)
+}
@@ -0,0 +1,49 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/cli"
+)
+
+func TestDoctorCmd(t *testing.T) {
+	defer resetEnv()()
+
+	// The settings are reset from the environment by every test case.
+	dir := t.TempDir()
+	t.Setenv("HELM_PLUGINS", "testdata/helmhome/helm/plugins")
+	t.Setenv("HELM_REPOSITORY_CONFIG", "testdata/helmhome/helm/repositories.yaml")
+	t.Setenv("HELM_REPOSITORY_CONFIG_DIR", filepath.Join(dir, "repositories.d"))
+	t.Setenv("HELM_REPOSITORY_CACHE", "testdata/helmhome/helm/repository")
+	t.Setenv("HELM_REGISTRY_CONFIG", filepath.Join(dir, "config.json"))
+	t.Setenv("HELM_CONTENT_CACHE", filepath.Join(dir, "content"))
+	settings = cli.New()
+
+	tests := []cmdTestCase{{
+		name:   "diagnose the environment",
+		cmd:    "doctor",
+		golden: "output/doctor.txt",
+	}, {
+		name:   "diagnose the local environment",
+		cmd:    "doctor --client-only -o json",
+		golden: "output/doctor-client-only.json",
+	}}
+	runTestCmd(t, tests)
+}
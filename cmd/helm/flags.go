@@ -20,9 +20,12 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -31,24 +34,70 @@ import (
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cli/values"
+	"helm.sh/helm/v4/pkg/engine"
 	"helm.sh/helm/v4/pkg/helmpath"
 	"helm.sh/helm/v4/pkg/postrender"
+	"helm.sh/helm/v4/pkg/redact"
+	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/releaseutil"
 	"helm.sh/helm/v4/pkg/repo"
+	helmtime "helm.sh/helm/v4/pkg/time"
 )
 
 const (
 	outputFlag         = "output"
 	postRenderFlag     = "post-renderer"
 	postRenderArgsFlag = "post-renderer-args"
+	redactSecretsFlag  = "redact-secrets"
+	manifestFormatFlag = "manifest-format"
 )
 
 func addValueOptionsFlags(f *pflag.FlagSet, v *values.Options) {
-	f.StringSliceVarP(&v.ValueFiles, "values", "f", []string{}, "specify values in a YAML file or a URL (can specify multiple)")
-	f.StringArrayVar(&v.Values, "set", []string{}, "set values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2)")
-	f.StringArrayVar(&v.StringValues, "set-string", []string{}, "set STRING values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2)")
-	f.StringArrayVar(&v.FileValues, "set-file", []string{}, "set values from respective files specified via the command line (can specify multiple or separate values with commas: key1=path1,key2=path2)")
-	f.StringArrayVar(&v.JSONValues, "set-json", []string{}, "set JSON values on the command line (can specify multiple or separate values with commas: key1=jsonval1,key2=jsonval2)")
-	f.StringArrayVar(&v.LiteralValues, "set-literal", []string{}, "set a literal STRING value on the command line")
+	v.AddFlags(f)
+}
+
+// addReleaseValuesFlags adds the flags keeping the inputs of the values with
+// the release, for the commands storing releases.
+func addReleaseValuesFlags(f *pflag.FlagSet, v *values.Options) {
+	f.BoolVar(&v.PreserveAnchors, "preserve-anchors", false, "when the values are given by a single values file, store the file as written, with its YAML anchors, merge keys and comments, for 'helm get values' to return")
+	f.Var((*recordModeValue)(&v.Record), "record-values", "record the values files and --set flags of the revision in the release, with the sensitive values redacted: \"digest\" records the digests of the values files, \"content\" also their content")
+}
+
+type recordModeValue values.RecordMode
+
+func (r *recordModeValue) String() string { return string(*r) }
+
+func (r *recordModeValue) Set(s string) error {
+	switch mode := values.RecordMode(s); mode {
+	case values.RecordNone, values.RecordDigest, values.RecordContent:
+		*r = recordModeValue(mode)
+		return nil
+	}
+	return fmt.Errorf("invalid values record mode %q, must be %q or %q", s, values.RecordDigest, values.RecordContent)
+}
+
+func (r *recordModeValue) Type() string { return "string" }
+
+func addStrictnessFlags(f *pflag.FlagSet, s *engine.Strictness) {
+	f.BoolVar(&s.FailOnMissingValues, "fail-on-missing-values", false, "fail rendering when a template references a value that is not set")
+	f.BoolVar(&s.FailOnUndefinedTemplates, "fail-on-undefined-templates", false, "fail rendering when a template includes a named template that is not defined, even in branches that are not rendered")
+	f.BoolVar(&s.FailOnLookupWithoutCluster, "fail-on-lookup-without-cluster", false, "fail rendering when a template calls 'lookup' while no cluster is available, instead of returning an empty result")
+}
+
+func addValuesFromFlags(f *pflag.FlagSet, v *action.ValuesFromOptions) {
+	f.StringVar(&v.Environment, "environment", "", "select the values sources of this environment among the valuesFrom sources declared in Chart.yaml")
+	f.BoolVar(&v.EnableValuesFrom, "enable-values-from", false, "read the valuesFrom sources declared in Chart.yaml, which may read ConfigMaps and Secrets of the release namespace and fetch URLs")
+}
+
+func addImageOverridesFlags(f *pflag.FlagSet, o *postrender.ImageOverrides) {
+	f.StringToStringVar(&o.Registries, "image-registry-override", nil, "rewrite the container images of the rendered manifests from a registry, optionally followed by a repository path, to another, e.g. docker.io=mirror.example.com/dockerhub (can specify multiple or separate values with commas)")
+	f.StringToStringVar(&o.Digests, "image-digest", nil, "pin a container image of the rendered manifests to a digest, e.g. nginx:1.27=sha256:... (can specify multiple or separate values with commas)")
+}
+
+func addExclusionFlags(f *pflag.FlagSet, e *release.ResourceExclusions) {
+	f.StringSliceVar(&e.Kinds, "skip-kinds", nil, "neither apply nor track the rendered resources of these kinds, e.g. PodSecurityPolicy (can specify multiple or separate values with commas)")
+	f.StringSliceVar(&e.Resources, "skip-resources", nil, "neither apply nor track these rendered resources, given as KIND/NAME, e.g. Secret/credentials (can specify multiple or separate values with commas)")
+	f.StringVar(&e.Selector, "skip-selector", "", "neither apply nor track the rendered resources matching this label selector, e.g. app.kubernetes.io/component=legacy")
 }
 
 func addChartPathOptionsFlags(f *pflag.FlagSet, c *action.ChartPathOptions) {
@@ -64,6 +113,22 @@ func addChartPathOptionsFlags(f *pflag.FlagSet, c *action.ChartPathOptions) {
 	f.BoolVar(&c.PlainHTTP, "plain-http", false, "use insecure HTTP connections for the chart download")
 	f.StringVar(&c.CaFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
 	f.BoolVar(&c.PassCredentialsAll, "pass-credentials", false, "pass credentials to all domains")
+	addHTTPAuthFlags(f, &c.Headers, &c.BearerToken)
+}
+
+// addHTTPAuthFlags adds the flags of the headers and the bearer token sent to
+// HTTP chart repositories.
+func addHTTPAuthFlags(f *pflag.FlagSet, headers *http.Header, token *string) {
+	f.Var(&headerValue{headers}, "header", "add an HTTP header to the requests of the chart repository: 'Name: value' (can specify multiple)")
+	f.StringVar(token, "bearer-token", "", "bearer token of the chart repository")
+	f.Var(&envTokenValue{token}, "bearer-token-env", "read the bearer token of the chart repository from this environment variable")
+}
+
+// addTimeoutFlag adds the --timeout flag of the operations waiting for
+// Kubernetes, accepting human-friendly durations and absolute deadlines.
+func addTimeoutFlag(f *pflag.FlagSet, timeout *time.Duration) {
+	*timeout = 300 * time.Second
+	f.Var(&timeoutValue{timeout: timeout}, "timeout", "time to wait for any individual Kubernetes operation (like Jobs for hooks), as a duration such as 5m or 1d, or an RFC 3339 deadline")
 }
 
 // bindOutputFlag will add the output flag to the given command and bind the
@@ -121,6 +186,73 @@ func bindPostRenderFlag(cmd *cobra.Command, varRef *postrender.PostRenderer) {
 	cmd.Flags().Var(&postRendererArgsSlice{p}, postRenderArgsFlag, "an argument to the post-renderer (can specify multiple)")
 }
 
+func bindRedactSecretsFlag(cmd *cobra.Command, varRef *[]redact.Redactor) {
+	cmd.Flags().Var(&redactSecretsValue{redactors: varRef}, redactSecretsFlag,
+		fmt.Sprintf("redact Kubernetes Secret data in the output. One of: %s (omit the Secret), %s (replace values with their SHA-256), %s (show keys only)", redact.ModeHide, redact.ModeHash, redact.ModeKeys))
+	err := cmd.RegisterFlagCompletionFunc(redactSecretsFlag, func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{string(redact.ModeHide), string(redact.ModeHash), string(redact.ModeKeys)}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+type redactSecretsValue struct {
+	redactors *[]redact.Redactor
+	mode      string
+}
+
+func (r *redactSecretsValue) String() string {
+	return r.mode
+}
+
+func (r *redactSecretsValue) Type() string {
+	return "mode"
+}
+
+func (r *redactSecretsValue) Set(val string) error {
+	mode, err := redact.ParseMode(val)
+	if err != nil {
+		return err
+	}
+	r.mode = string(mode)
+	*r.redactors = []redact.Redactor{redact.NewSecretRedactor(mode)}
+	return nil
+}
+
+func bindManifestFormatFlag(cmd *cobra.Command, varRef *releaseutil.ManifestFormat) {
+	cmd.Flags().Var((*manifestFormatValue)(varRef), manifestFormatFlag,
+		"the format of the rendered manifests, which the post-renderer receives. One of: rendered (as the templates rendered them), yaml (normalized YAML with sorted keys), json (one JSON document per resource)")
+	err := cmd.RegisterFlagCompletionFunc(manifestFormatFlag, func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"rendered", string(releaseutil.ManifestFormatYAML), string(releaseutil.ManifestFormatJSON)}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+type manifestFormatValue releaseutil.ManifestFormat
+
+func (m *manifestFormatValue) String() string {
+	if *m == manifestFormatValue(releaseutil.ManifestFormatRendered) {
+		return "rendered"
+	}
+	return string(*m)
+}
+
+func (m *manifestFormatValue) Type() string {
+	return "format"
+}
+
+func (m *manifestFormatValue) Set(val string) error {
+	format, err := releaseutil.ParseManifestFormat(val)
+	if err != nil {
+		return err
+	}
+	*m = manifestFormatValue(format)
+	return nil
+}
+
 type postRendererOptions struct {
 	renderer   *postrender.PostRenderer
 	binaryPath string
@@ -250,3 +382,103 @@ func addKlogFlags(fs *pflag.FlagSet) {
 func normalize(s string) string {
 	return strings.ReplaceAll(s, "_", "-")
 }
+
+// headerValue is the pflag.Value of --header, which adds HTTP headers.
+type headerValue struct {
+	headers *http.Header
+}
+
+// String only lists the names of the headers, as their values may be secrets.
+func (h *headerValue) String() string {
+	var names []string
+	for name := range *h.headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return "[" + strings.Join(names, ",") + "]"
+}
+
+func (h *headerValue) Set(s string) error {
+	name, value, ok := strings.Cut(s, ":")
+	if name = strings.TrimSpace(name); !ok || name == "" {
+		return fmt.Errorf("invalid header %q, expected Name: value", s)
+	}
+	if *h.headers == nil {
+		*h.headers = http.Header{}
+	}
+	h.headers.Add(name, strings.TrimSpace(value))
+	return nil
+}
+
+func (h *headerValue) Type() string {
+	return "stringArray"
+}
+
+// nameGeneratorValue is the pflag.Value of --name-generator, which selects a
+// built-in name generator.
+type nameGeneratorValue struct {
+	name      string
+	generator *action.NameGenerator
+}
+
+func (n *nameGeneratorValue) String() string {
+	return n.name
+}
+
+func (n *nameGeneratorValue) Set(s string) error {
+	g, ok := action.NameGenerators[s]
+	if !ok {
+		return fmt.Errorf("invalid name generator %q, expected one of: %s", s, strings.Join(action.NameGeneratorNames(), ", "))
+	}
+	n.name = s
+	*n.generator = g
+	return nil
+}
+
+func (n *nameGeneratorValue) Type() string {
+	return "string"
+}
+
+type timeoutValue struct {
+	timeout *time.Duration
+}
+
+func (t *timeoutValue) String() string {
+	return t.timeout.String()
+}
+
+func (t *timeoutValue) Set(s string) error {
+	d, err := helmtime.ParseTimeout(s, time.Now())
+	if err != nil {
+		return err
+	}
+	*t.timeout = d
+	return nil
+}
+
+func (t *timeoutValue) Type() string {
+	return "duration"
+}
+
+// envTokenValue is the pflag.Value of --bearer-token-env, which sets the
+// token to the value of the environment variable it names.
+type envTokenValue struct {
+	token *string
+}
+
+func (e *envTokenValue) String() string {
+	return ""
+}
+
+func (e *envTokenValue) Set(name string) error {
+	token, ok := os.LookupEnv(name)
+	if !ok || token == "" {
+		return fmt.Errorf("environment variable %s is not set", name)
+	}
+	*e.token = token
+	return nil
+}
+
+func (e *envTokenValue) Type() string {
+	return "string"
+}
@@ -19,6 +19,9 @@ package main
 import (
 	"fmt"
 	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
 
 	"helm.sh/helm/v4/pkg/chart"
 	"helm.sh/helm/v4/pkg/release"
@@ -93,3 +96,31 @@ func outputFlagCompletionTest(t *testing.T, cmdName string) {
 	}}
 	runTestCmd(t, tests)
 }
+
+func TestTimeoutFlag(t *testing.T) {
+	var timeout time.Duration
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	addTimeoutFlag(f, &timeout)
+	if timeout != 5*time.Minute || f.Lookup("timeout").DefValue != "5m0s" {
+		t.Errorf("expected a 5m0s default, got %s", f.Lookup("timeout").DefValue)
+	}
+
+	if err := f.Parse([]string{"--timeout", "1d12h"}); err != nil {
+		t.Fatal(err)
+	}
+	if timeout != 36*time.Hour {
+		t.Errorf("expected a 36h timeout, got %s", timeout)
+	}
+
+	deadline := time.Now().Add(2 * time.Hour).UTC().Format(time.RFC3339)
+	if err := f.Parse([]string{"--timeout", deadline}); err != nil {
+		t.Fatal(err)
+	}
+	if timeout <= time.Hour || timeout > 2*time.Hour {
+		t.Errorf("expected a timeout of about 2h until %s, got %s", deadline, timeout)
+	}
+
+	if err := f.Parse([]string{"--timeout", "2020-01-01T00:00:00Z"}); err == nil {
+		t.Error("expected an error for a deadline that has passed")
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"fmt"
 	"io"
 	"log"
 
@@ -30,11 +31,18 @@ import (
 var getAllHelp = `
 This command prints a human readable collection of information about the
 notes, hooks, supplied values, and generated manifest file of the given release.
+
+'--revision' takes a single revision, a range of revisions such as '2-5' or
+'3-', which ends at the latest revision, or 'all'. With '--output-dir', the
+selected revisions are written to NAME/REVISION/ in the directory instead, with
+the metadata, the chart metadata, the supplied and computed values, the
+manifest, the hooks and the notes of every revision in separate files. An
+output directory ending in '.tgz' or '.tar.gz' is written as an archive.
 `
 
 func newGetAllCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
-	var template string
-	client := action.NewGet(cfg)
+	var template, revisions, outputDir string
+	client := action.NewExportRelease(cfg)
 
 	cmd := &cobra.Command{
 		Use:   "all RELEASE_NAME",
@@ -48,27 +56,48 @@ func newGetAllCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			return compListReleases(toComplete, args, cfg)
 		},
 		RunE: func(_ *cobra.Command, args []string) error {
-			res, err := client.Run(args[0])
+			client.Revisions = revisions
+			if outputDir != "" {
+				rels, err := client.Run(args[0], outputDir)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "Exported %d revision(s) of %s to %s\n", len(rels), args[0], outputDir)
+				return nil
+			}
+
+			rels, err := client.Releases(args[0])
 			if err != nil {
 				return err
 			}
-			if template != "" {
-				data := map[string]interface{}{
-					"Release": res,
+			for _, res := range rels {
+				if template != "" {
+					data := map[string]interface{}{
+						"Release": res,
+					}
+					if err := tpl(template, data, out); err != nil {
+						return err
+					}
+					continue
+				}
+				err := output.Table.Write(out, &statusPrinter{
+					release:      res,
+					debug:        true,
+					showMetadata: true,
+					hideNotes:    false,
+				})
+				if err != nil {
+					return err
 				}
-				return tpl(template, data, out)
 			}
-			return output.Table.Write(out, &statusPrinter{
-				release:      res,
-				debug:        true,
-				showMetadata: true,
-				hideNotes:    false,
-			})
+			return nil
 		},
 	}
 
 	f := cmd.Flags()
-	f.IntVar(&client.Version, "revision", 0, "get the named release with revision")
+	f.StringVar(&revisions, "revision", "", "get the named release with revision, a range of revisions such as 2-5 or 3-, or all revisions")
+	f.StringVar(&outputDir, "output-dir", "", "write the revisions to files in the directory, or to a .tgz or .tar.gz archive")
+	bindRedactSecretsFlag(cmd, &client.Redactors)
 	err := cmd.RegisterFlagCompletionFunc("revision", func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 1 {
 			return compListRevisions(toComplete, cfg, args[0])
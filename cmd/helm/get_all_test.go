@@ -33,6 +33,21 @@ func TestGetCmd(t *testing.T) {
 		cmd:    "get all elevated-turkey --template {{.Release.Chart.Metadata.Version}}",
 		golden: "output/get-release-template.txt",
 		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "elevated-turkey"})},
+	}, {
+		name:   "get all with a range of revisions",
+		cmd:    "get all thomas-guide --revision 2- --template {{.Release.Version}}",
+		golden: "output/get-release-range.txt",
+		rels: []*release.Release{
+			release.Mock(&release.MockReleaseOptions{Name: "thomas-guide", Version: 1}),
+			release.Mock(&release.MockReleaseOptions{Name: "thomas-guide", Version: 2}),
+			release.Mock(&release.MockReleaseOptions{Name: "thomas-guide", Version: 3}),
+		},
+	}, {
+		name:      "get all with an invalid range of revisions",
+		cmd:       "get all thomas-guide --revision 3-2",
+		golden:    "output/get-release-invalid-range.txt",
+		rels:      []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})},
+		wantError: true,
 	}, {
 		name:      "get all requires release name arg",
 		cmd:       "get all",
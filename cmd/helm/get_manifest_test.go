@@ -28,6 +28,11 @@ func TestGetManifest(t *testing.T) {
 		cmd:    "get manifest juno",
 		golden: "output/get-manifest.txt",
 		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "juno"})},
+	}, {
+		name:   "get manifest with secrets hidden",
+		cmd:    "get manifest juno --redact-secrets hide",
+		golden: "output/get-manifest-redacted.txt",
+		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "juno"})},
 	}, {
 		name:      "get manifest without args",
 		cmd:       "get manifest",
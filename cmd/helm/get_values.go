@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -35,6 +36,9 @@ This command downloads a values file for a given release.
 type valuesWriter struct {
 	vals      map[string]interface{}
 	allValues bool
+	// raw is the values file as written by the user, when it was stored
+	// with the release.
+	raw string
 }
 
 func newGetValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
@@ -53,11 +57,11 @@ func newGetValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			return compListReleases(toComplete, args, cfg)
 		},
 		RunE: func(_ *cobra.Command, args []string) error {
-			vals, err := client.Run(args[0])
+			vals, raw, err := client.RunRaw(args[0])
 			if err != nil {
 				return err
 			}
-			return outfmt.Write(out, &valuesWriter{vals, client.AllValues})
+			return outfmt.Write(out, &valuesWriter{vals, client.AllValues, raw})
 		},
 	}
 
@@ -86,7 +90,7 @@ func (v valuesWriter) WriteTable(out io.Writer) error {
 	} else {
 		fmt.Fprintln(out, "USER-SUPPLIED VALUES:")
 	}
-	return output.EncodeYAML(out, v.vals)
+	return v.WriteYAML(out)
 }
 
 func (v valuesWriter) WriteJSON(out io.Writer) error {
@@ -94,5 +98,9 @@ func (v valuesWriter) WriteJSON(out io.Writer) error {
 }
 
 func (v valuesWriter) WriteYAML(out io.Writer) error {
+	if v.raw != "" {
+		_, err := io.WriteString(out, strings.TrimSuffix(v.raw, "\n")+"\n")
+		return err
+	}
 	return output.EncodeYAML(out, v.vals)
 }
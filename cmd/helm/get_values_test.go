@@ -49,10 +49,27 @@ func TestGetValuesCmd(t *testing.T) {
 		cmd:    "get values thomas-guide --output yaml",
 		golden: "output/values.yaml",
 		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})},
+	}, {
+		name:   "get values with preserved anchors",
+		cmd:    "get values thomas-guide",
+		golden: "output/get-values-raw.txt",
+		rels:   []*release.Release{mockReleaseWithRawValues()},
+	}, {
+		name:   "get values with preserved anchors to json",
+		cmd:    "get values thomas-guide --output json",
+		golden: "output/get-values-raw.json",
+		rels:   []*release.Release{mockReleaseWithRawValues()},
 	}}
 	runTestCmd(t, tests)
 }
 
+func mockReleaseWithRawValues() *release.Release {
+	rel := release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})
+	rel.Config = map[string]interface{}{"name": "anchored", "alias": "anchored"}
+	rel.ConfigRaw = "# the user's values\nname: &name anchored\nalias: *name\n"
+	return rel
+}
+
 func TestGetValuesCompletion(t *testing.T) {
 	checkReleaseCompletion(t, "get values", false)
 }
@@ -65,6 +65,8 @@ func main() {
 	kube.ManagedFieldsManager = "helm"
 
 	actionConfig := new(action.Configuration)
+	actionConfig.CacheIncludes = settings.CacheIncludes
+	actionConfig.RenderParallelism = settings.RenderParallelism
 	cmd, err := newRootCmd(actionConfig, os.Stdout, os.Args[1:])
 	if err != nil {
 		warning("%+v", err)
@@ -74,6 +76,9 @@ func main() {
 	// run when each command's execute method is called
 	cobra.OnInitialize(func() {
 		helmDriver := os.Getenv("HELM_DRIVER")
+		if _, err := settings.Impersonation(); err != nil {
+			log.Fatal(err)
+		}
 		if err := actionConfig.Init(settings.RESTClientGetter(), settings.Namespace(), helmDriver, debug); err != nil {
 			log.Fatal(err)
 		}
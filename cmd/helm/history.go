@@ -17,17 +17,21 @@ limitations under the License.
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gosuri/uitable"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/cmd/helm/require"
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chartutil"
 	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/release"
 	"helm.sh/helm/v4/pkg/releaseutil"
@@ -48,11 +52,33 @@ The historical release set is printed as a formatted table, e.g:
     2           Mon Oct 3 10:15:13 2016     superseded      alpine-0.1.0      1.0             Upgraded successfully
     3           Mon Oct 3 10:15:13 2016     superseded      alpine-0.1.0      1.0             Rolled back to 2
     4           Mon Oct 3 10:15:13 2016     deployed        alpine-0.1.0      1.0             Upgraded successfully
+
+Two revisions can be compared with '--diff'. This shows changes to the chart
+version, the user-supplied values (or all computed values with '--all-values')
+and a unified diff for every resource whose manifest changed:
+
+    $ helm history angry-bird --diff 2,4
+
+The data of the Secrets is shown as its SHA-256 in the diff, unless another
+mode is set with '--redact-secrets'.
+
+With '--output json' or '--output yaml', every revision also includes the user
+or service account ('operator') and the Helm version ('client_version') that
+created it, for revisions that recorded them.
+
+A changelog of the release can be generated with '--changelog'. For every
+revision it lists the chart and app version transitions, the paths of the
+values that changed, the user or service account that made the change (when it
+was recorded) and the description:
+
+    $ helm history angry-bird --changelog --max 10
 `
 
 func newHistoryCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewHistory(cfg)
 	var outfmt output.Format
+	var diff []int
+	var changelog bool
 
 	cmd := &cobra.Command{
 		Use:     "history RELEASE_NAME",
@@ -67,6 +93,24 @@ func newHistoryCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			return compListReleases(toComplete, args, cfg)
 		},
 		RunE: func(_ *cobra.Command, args []string) error {
+			if len(diff) > 0 {
+				if len(diff) != 2 {
+					return errors.New("--diff requires exactly two revisions, e.g. --diff 2,3")
+				}
+				d, err := client.Diff(args[0], diff[0], diff[1])
+				if err != nil {
+					return err
+				}
+				return outfmt.Write(out, &revisionDiffWriter{d})
+			}
+			if changelog {
+				entries, err := client.Changelog(args[0])
+				if err != nil {
+					return err
+				}
+				return outfmt.Write(out, changelogWriter(entries))
+			}
+
 			history, err := getHistory(client, args[0])
 			if err != nil {
 				return err
@@ -78,18 +122,25 @@ func newHistoryCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 
 	f := cmd.Flags()
 	f.IntVar(&client.Max, "max", 256, "maximum number of revision to include in history")
+	f.IntSliceVar(&diff, "diff", nil, "compare two revisions of the release, e.g. --diff 2,3")
+	bindRedactSecretsFlag(cmd, &client.Redactors)
+	f.BoolVar(&changelog, "changelog", false, "print a changelog of the release, comparing every revision with the previous one")
+	f.BoolVar(&client.AllValues, "all-values", false, "when used with --diff or --changelog, compare all computed values instead of only user-supplied values")
 	bindOutputFlag(cmd, &outfmt)
 
 	return cmd
 }
 
 type releaseInfo struct {
-	Revision    int           `json:"revision"`
-	Updated     helmtime.Time `json:"updated"`
-	Status      string        `json:"status"`
-	Chart       string        `json:"chart"`
-	AppVersion  string        `json:"app_version"`
-	Description string        `json:"description"`
+	Revision      int           `json:"revision"`
+	Updated       helmtime.Time `json:"updated"`
+	Status        string        `json:"status"`
+	Chart         string        `json:"chart"`
+	AppVersion    string        `json:"app_version"`
+	Description   string        `json:"description"`
+	Operator      string        `json:"operator,omitempty"`
+	ClientVersion string        `json:"client_version,omitempty"`
+	Reason        string        `json:"reason,omitempty"`
 }
 
 type releaseHistory []releaseInfo
@@ -111,6 +162,130 @@ func (r releaseHistory) WriteTable(out io.Writer) error {
 	return output.EncodeTable(out, tbl)
 }
 
+type revisionDiffWriter struct {
+	diff *action.RevisionDiff
+}
+
+func (w *revisionDiffWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.diff)
+}
+
+func (w *revisionDiffWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.diff)
+}
+
+func (w *revisionDiffWriter) WriteTable(out io.Writer) error {
+	d := w.diff
+	fmt.Fprintf(out, "RELEASE: %s\n", d.Name)
+	fmt.Fprintf(out, "REVISIONS: %d -> %d\n", d.From, d.To)
+	if d.Chart != nil {
+		fmt.Fprintf(out, "CHART: %s -> %s\n", d.Chart.From, d.Chart.To)
+	} else {
+		fmt.Fprintln(out, "CHART: unchanged")
+	}
+	if d.AppVersion != nil {
+		fmt.Fprintf(out, "APP VERSION: %s -> %s\n", d.AppVersion.From, d.AppVersion.To)
+	} else {
+		fmt.Fprintln(out, "APP VERSION: unchanged")
+	}
+
+	fmt.Fprintln(out, "\nVALUES:")
+	if len(d.Values) == 0 {
+		fmt.Fprintln(out, "  unchanged")
+	}
+	for _, v := range d.Values {
+		switch v.Type {
+		case chartutil.DiffAdded:
+			fmt.Fprintf(out, "  + %s: %v\n", v.Path, formatDiffValue(v.New))
+		case chartutil.DiffRemoved:
+			fmt.Fprintf(out, "  - %s: %v\n", v.Path, formatDiffValue(v.Old))
+		default:
+			fmt.Fprintf(out, "  ~ %s: %v -> %v\n", v.Path, formatDiffValue(v.Old), formatDiffValue(v.New))
+		}
+	}
+
+	if len(d.ValuesSources) > 0 {
+		fmt.Fprintln(out, "\nVALUES SOURCES:")
+		for _, s := range d.ValuesSources {
+			sign := "+"
+			if s.Type == chartutil.DiffRemoved {
+				sign = "-"
+			}
+			fmt.Fprintf(out, "  %s %s\n", sign, s.Source)
+		}
+	}
+
+	fmt.Fprintln(out, "\nMANIFESTS:")
+	if len(d.Manifests) == 0 {
+		fmt.Fprintln(out, "  unchanged")
+	}
+	for _, m := range d.Manifests {
+		fmt.Fprintf(out, "%s (%s)\n%s", m.Resource, m.Type, m.Diff)
+	}
+	return nil
+}
+
+type changelogWriter []*action.ChangelogEntry
+
+func (w changelogWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w)
+}
+
+func (w changelogWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w)
+}
+
+func (w changelogWriter) WriteTable(out io.Writer) error {
+	for i, e := range w {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "## Revision %d", e.Revision)
+		if !e.Updated.IsZero() {
+			fmt.Fprintf(out, " (%s)", e.Updated.Format(time.ANSIC))
+		}
+		fmt.Fprintf(out, "\n\n- Status: %s\n", e.Status)
+		if e.Operator != "" {
+			fmt.Fprintf(out, "- Operator: %s\n", e.Operator)
+		}
+		if e.ClientVersion != "" {
+			fmt.Fprintf(out, "- Helm version: %s\n", e.ClientVersion)
+		}
+		if e.Chart != nil {
+			fmt.Fprintf(out, "- Chart: %s\n", formatChange(e.Chart))
+		}
+		if e.AppVersion != nil {
+			fmt.Fprintf(out, "- App version: %s\n", formatChange(e.AppVersion))
+		}
+		if len(e.Values) > 0 {
+			paths := make([]string, 0, len(e.Values))
+			for _, v := range e.Values {
+				paths = append(paths, fmt.Sprintf("%s (%s)", v.Path, v.Type))
+			}
+			fmt.Fprintf(out, "- Values changed: %s\n", strings.Join(paths, ", "))
+		}
+		if e.Description != "" {
+			fmt.Fprintf(out, "- Description: %s\n", e.Description)
+		}
+	}
+	return nil
+}
+
+func formatChange(c *action.StringChange) string {
+	if c.From == "" {
+		return c.To
+	}
+	return fmt.Sprintf("%s -> %s", c.From, c.To)
+}
+
+func formatDiffValue(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
 func getHistory(client *action.History, name string) (releaseHistory, error) {
 	hist, err := client.Run(name)
 	if err != nil {
@@ -143,11 +318,14 @@ func getReleaseHistory(rls []*release.Release) (history releaseHistory) {
 		a := formatAppVersion(r.Chart)
 
 		rInfo := releaseInfo{
-			Revision:    v,
-			Status:      s,
-			Chart:       c,
-			AppVersion:  a,
-			Description: d,
+			Revision:      v,
+			Status:        s,
+			Chart:         c,
+			AppVersion:    a,
+			Description:   d,
+			Operator:      r.Info.Operator,
+			ClientVersion: r.Info.ClientVersion,
+			Reason:        r.Info.Reason.String(),
 		}
 		if !r.Info.LastDeployed.IsZero() {
 			rInfo.Updated = r.Info.LastDeployed
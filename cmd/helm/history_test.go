@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"testing"
 
+	"helm.sh/helm/v4/pkg/chart"
 	"helm.sh/helm/v4/pkg/release"
 )
 
@@ -70,6 +71,100 @@ func TestHistoryCmd(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func TestHistoryDiffCmd(t *testing.T) {
+	mk := func(vers int, tag string, manifest string) *release.Release {
+		rel := release.Mock(&release.MockReleaseOptions{
+			Name:    "angry-bird",
+			Version: vers,
+			Status:  release.StatusSuperseded,
+		})
+		rel.Config = map[string]interface{}{"image": map[string]interface{}{"tag": tag}}
+		rel.Manifest = manifest
+		return rel
+	}
+	rels := []*release.Release{
+		mk(1, "1.0", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cfg\ndata:\n  a: b\n"),
+		mk(2, "1.1", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cfg\ndata:\n  a: c\n"),
+	}
+
+	tests := []cmdTestCase{{
+		name:   "diff two revisions",
+		cmd:    "history angry-bird --diff 1,2",
+		rels:   rels,
+		golden: "output/history-diff.txt",
+	}, {
+		name:   "diff two revisions with json output",
+		cmd:    "history angry-bird --diff 1,2 --output json",
+		rels:   rels,
+		golden: "output/history-diff.json",
+	}, {
+		name:      "diff with a single revision",
+		cmd:       "history angry-bird --diff 1",
+		rels:      rels,
+		wantError: true,
+	}, {
+		name: "diff two revisions with recorded values sources",
+		cmd:  "history angry-bird --diff 1,2",
+		rels: []*release.Release{
+			withValuesSources(mk(1, "1.0", ""), &release.ValuesSource{Flag: "values", Value: "prod.yaml", Digest: "sha256:aaaa"}),
+			withValuesSources(mk(2, "1.1", ""), &release.ValuesSource{Flag: "values", Value: "prod.yaml", Digest: "sha256:bbbb"}, &release.ValuesSource{Flag: "set", Value: "image.tag=1.1"}),
+		},
+		golden: "output/history-diff-sources.txt",
+	}}
+	runTestCmd(t, tests)
+}
+
+func withValuesSources(rel *release.Release, sources ...*release.ValuesSource) *release.Release {
+	rel.ValuesSources = sources
+	return rel
+}
+
+func TestHistoryChangelogCmd(t *testing.T) {
+	mk := func(vers int, version, tag, operator string) *release.Release {
+		ch := &chart.Chart{Metadata: &chart.Metadata{Name: "foo", Version: version, AppVersion: "1.0"}}
+		rel := release.Mock(&release.MockReleaseOptions{
+			Name:    "angry-bird",
+			Version: vers,
+			Chart:   ch,
+			Status:  release.StatusSuperseded,
+		})
+		rel.Config = map[string]interface{}{"image": map[string]interface{}{"tag": tag}}
+		if operator != "" {
+			rel.Info.Operator = operator
+			rel.Info.ClientVersion = "v4.0.0"
+		}
+		return rel
+	}
+	rels := []*release.Release{
+		mk(1, "0.1.0", "1.0", ""),
+		mk(2, "0.1.0", "1.1", "jane"),
+		mk(3, "0.2.0", "1.1", "system:serviceaccount:ci:deployer"),
+	}
+
+	tests := []cmdTestCase{{
+		name:   "changelog",
+		cmd:    "history angry-bird --changelog",
+		rels:   rels,
+		golden: "output/history-changelog.txt",
+	}, {
+		name:   "changelog with max",
+		cmd:    "history angry-bird --changelog --max 1",
+		rels:   rels,
+		golden: "output/history-changelog-max.txt",
+	}, {
+		name:   "changelog with json output",
+		cmd:    "history angry-bird --changelog --output json",
+		rels:   rels,
+		golden: "output/history-changelog.json",
+	}, {
+		name:   "history with the recorded operators",
+		cmd:    "history angry-bird --output json",
+		rels:   rels,
+		golden: "output/history-operator.json",
+	}}
+	runTestCmd(t, tests)
+}
+
 func TestHistoryOutputCompletion(t *testing.T) {
 	outputFlagCompletionTest(t, "history")
 }
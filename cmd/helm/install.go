@@ -23,9 +23,11 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/gosuri/uitable"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -79,6 +81,14 @@ contained a key called 'Test', the value set in override.yaml would take precede
 
     $ helm install -f myvalues.yaml -f override.yaml  myredis ./redis
 
+Values files can also be fetched from URLs, including values stored as OCI
+artifacts. Use '--values-header' to send headers, e.g. credentials, to the
+servers of values files with a URL prefix:
+
+    $ helm install -f oci://registry.example.com/values/redis:prod myredis ./redis
+    $ helm install -f https://config.example.com/prod/redis.yaml \
+        --values-header "https://config.example.com/=Authorization: Bearer $TOKEN" myredis ./redis
+
 You can specify the '--set' flag multiple times. The priority will be given to the
 last (right-most) set specified. For example, if both 'bar' and 'newbar' values are
 set for a key called 'foo', the 'newbar' value would take precedence:
@@ -98,7 +108,9 @@ the --debug and --dry-run flags can be combined.
 
 The --dry-run flag will output all generated chart manifests, including Secrets
 which can contain sensitive values. To hide Kubernetes Secrets use the
---hide-secret flag. Please carefully consider how and when these flags are used.
+--hide-secret flag. To keep the Secrets but mask their data, use
+'--redact-secrets=hash' or '--redact-secrets=keys'. Please carefully consider
+how and when these flags are used.
 
 If --verify is set, the chart MUST have a provenance file, and the provenance
 file MUST pass all verification steps.
@@ -130,6 +142,7 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewInstall(cfg)
 	valueOpts := &values.Options{}
 	var outfmt output.Format
+	var progress bool
 
 	cmd := &cobra.Command{
 		Use:   "install [NAME] [CHART]",
@@ -139,7 +152,7 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			return compInstall(args, toComplete, client)
 		},
-		RunE: func(_ *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			registryClient, err := newRegistryClient(client.CertFile, client.KeyFile, client.CaFile,
 				client.InsecureSkipTLSverify, client.PlainHTTP, client.Username, client.Password)
 			if err != nil {
@@ -153,9 +166,12 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			if client.DryRunOption == "" {
 				client.DryRunOption = "none"
 			}
+			if progress {
+				client.EventHandler = progressPrinter(os.Stderr)
+			}
 			rel, err := runInstall(args, client, valueOpts, out)
 			if err != nil {
-				return errors.Wrap(err, "INSTALLATION FAILED")
+				return errors.Wrap(reportResourceConflicts(cmd.ErrOrStderr(), err), "INSTALLATION FAILED")
 			}
 
 			return outfmt.Write(out, &statusPrinter{
@@ -163,6 +179,7 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				debug:        settings.Debug,
 				showMetadata: false,
 				hideNotes:    client.HideNotes,
+				effective:    client.EffectiveObjects,
 			})
 		},
 	}
@@ -172,8 +189,14 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	// it is added separately
 	f := cmd.Flags()
 	f.BoolVar(&client.HideSecret, "hide-secret", false, "hide Kubernetes Secrets when also using the --dry-run flag")
+	f.BoolVar(&progress, "progress", false, "print the progress of the install to stderr while it runs")
+	f.IntVar(&client.MaxHistory, "history-max", settings.MaxHistory, "limit the maximum number of revisions saved per release when it is replaced. Use 0 for no limit")
+	f.BoolVar(&client.ComputeEffective, "show-effective", false, "show the objects the cluster would store after defaulting and mutating admission webhooks. Requires --dry-run=server")
+	f.BoolVar(&client.SaveConfig, "save-config", false, "maintain the kubectl.kubernetes.io/last-applied-configuration annotation of the resources like 'kubectl apply', and use it as the original configuration of their updates")
+	bindRedactSecretsFlag(cmd, &client.Redactors)
 	bindOutputFlag(cmd, &outfmt)
 	bindPostRenderFlag(cmd, &client.PostRenderer)
+	bindManifestFormatFlag(cmd, &client.ManifestFormat)
 
 	return cmd
 }
@@ -190,11 +213,13 @@ func addInstallFlags(cmd *cobra.Command, f *pflag.FlagSet, client *action.Instal
 	f.BoolVar(&client.Force, "force", false, "force resource updates through a replacement strategy")
 	f.BoolVar(&client.DisableHooks, "no-hooks", false, "prevent hooks from running during install")
 	f.BoolVar(&client.Replace, "replace", false, "reuse the given name, only if that name is a deleted release which remains in the history. This is unsafe in production")
-	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
+	addTimeoutFlag(f, &client.Timeout)
+	f.DurationVar(&client.ResourceTimeout, "resource-timeout", 0, "time to wait for any request to create or update a single resource. 0 means no limit")
 	f.BoolVar(&client.Wait, "wait", false, "if set, will wait until all Pods, PVCs, Services, and minimum number of Pods of a Deployment, StatefulSet, or ReplicaSet are in a ready state before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVarP(&client.GenerateName, "generate-name", "g", false, "generate the name (and omit the NAME parameter)")
-	f.StringVar(&client.NameTemplate, "name-template", "", "specify template used to name the release")
+	f.Var(&nameGeneratorValue{generator: &client.NameGenerator}, "name-generator", fmt.Sprintf("strategy generating the name with --generate-name, retried while the name is taken. Allowed values: %s", strings.Join(action.NameGeneratorNames(), ", ")))
+	f.StringVar(&client.NameTemplate, "name-template", "", "specify template used to name the release, which can reference .Chart, .Values and .Timestamp")
 	f.StringVar(&client.Description, "description", "", "add a custom description")
 	f.BoolVar(&client.Devel, "devel", false, "use development versions, too. Equivalent to version '>0.0.0-0'. If --version is set, this is ignored")
 	f.BoolVar(&client.DependencyUpdate, "dependency-update", false, "update dependencies if they are missing before installing the chart")
@@ -205,12 +230,31 @@ func addInstallFlags(cmd *cobra.Command, f *pflag.FlagSet, client *action.Instal
 	f.BoolVar(&client.SkipSchemaValidation, "skip-schema-validation", false, "if set, disables JSON schema validation")
 	f.StringToStringVarP(&client.Labels, "labels", "l", nil, "Labels that would be added to release metadata. Should be divided by comma.")
 	f.BoolVar(&client.EnableDNS, "enable-dns", false, "enable DNS lookups when rendering templates")
+	f.BoolVar(&client.EnableClusterConfig, "enable-cluster-config", false, "allow templates to read cluster configuration (clusterDomain, serverVersion, ingressClasses, defaultIngressClass) when rendering")
 	f.BoolVar(&client.HideNotes, "hide-notes", false, "if set, do not show notes in install output. Does not affect presence in chart metadata")
 	f.BoolVar(&client.TakeOwnership, "take-ownership", false, "if set, install will ignore the check for helm annotations and take ownership of the existing resources")
+	f.BoolVar(&client.VerifyDependencies, "verify-dependencies", false, "verify the dependencies in charts/ against the digests recorded in Chart.lock before installing the chart")
+	f.BoolVar(&client.SkipRequirementChecks, "skip-requirement-checks", false, "if set, the cluster requirements declared in Chart.yaml are not checked before installing")
+	f.BoolVar(&client.FailOnDeprecated, "fail-on-deprecated", false, "fail instead of warning when the chart, or one of its dependencies, is deprecated or past its supportedUntil date")
+	f.BoolVar(&client.CheckPermissions, "check-permissions", false, "check that you are allowed to create the resources and hooks of the release before installing, and report all the missing permissions")
+	addValuesFromFlags(f, &client.ValuesFromOptions)
+	addImageOverridesFlags(f, &client.ImageOverrides)
+	addExclusionFlags(f, &client.Exclusions)
+	f.StringVar(&client.Subchart, "subchart", "", "only render and install the dependency subtree at this path of dependency names or aliases (e.g. 'database' or 'backend.cache')")
+	addStrictnessFlags(f, &client.Strictness)
+	f.BoolVar(&client.ReportAllErrors, "all-errors", false, "report the errors of all the templates that fail to render instead of stopping at the first one")
 	addValueOptionsFlags(f, valueOpts)
+	addReleaseValuesFlags(f, valueOpts)
 	addChartPathOptionsFlags(f, &client.ChartPathOptions)
 
-	err := cmd.RegisterFlagCompletionFunc("version", func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	err := cmd.RegisterFlagCompletionFunc("name-generator", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return action.NameGeneratorNames(), cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("version", func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		requiredArgs := 2
 		if client.GenerateName {
 			requiredArgs = 1
@@ -246,10 +290,14 @@ func runInstall(args []string, client *action.Install, valueOpts *values.Options
 	debug("CHART PATH: %s\n", cp)
 
 	p := getter.All(settings)
-	vals, err := valueOpts.MergeValues(p)
+	merged, err := valueOpts.Merge(p)
 	if err != nil {
 		return nil, err
 	}
+	vals := merged.Values
+	client.ValuesRaw = merged.Raw
+	client.ValuesSources = merged.Sources
+	client.ValuesGetters = p
 
 	// Check chart dependencies to make sure all are present in /charts
 	chartRequested, err := loader.Load(cp)
@@ -261,8 +309,8 @@ func runInstall(args []string, client *action.Install, valueOpts *values.Options
 		return nil, err
 	}
 
-	if chartRequested.Metadata.Deprecated {
-		warning("This chart is deprecated")
+	for _, w := range action.SupportWarnings(chartRequested, time.Now()) {
+		warning("%s", w)
 	}
 
 	if req := chartRequested.Metadata.Dependencies; req != nil {
@@ -273,15 +321,18 @@ func runInstall(args []string, client *action.Install, valueOpts *values.Options
 			err = errors.Wrap(err, "An error occurred while checking for chart dependencies. You may need to run `helm dependency build` to fetch missing dependencies")
 			if client.DependencyUpdate {
 				man := &downloader.Manager{
-					Out:              out,
-					ChartPath:        cp,
-					Keyring:          client.ChartPathOptions.Keyring,
-					SkipUpdate:       false,
-					Getters:          p,
-					RepositoryConfig: settings.RepositoryConfig,
-					RepositoryCache:  settings.RepositoryCache,
-					Debug:            settings.Debug,
-					RegistryClient:   client.GetRegistryClient(),
+					Out:                 out,
+					ChartPath:           cp,
+					Keyring:             client.ChartPathOptions.Keyring,
+					SkipUpdate:          false,
+					Getters:             p,
+					RepositoryConfig:    settings.RepositoryConfig,
+					RepositoryConfigDir: settings.RepositoryConfigDir,
+					RepositoryCache:     settings.RepositoryCache,
+					Cache:               action.ContentCache(settings),
+					Debug:               settings.Debug,
+					RegistryClient:      client.GetRegistryClient(),
+					RepositoryOptions:   client.ChartPathOptions.RepositoryOptions(),
 				}
 				if err := man.Update(); err != nil {
 					return nil, err
@@ -359,3 +410,44 @@ func validateDryRunOptionFlag(dryRunOptionFlagValue string) error {
 	}
 	return nil
 }
+
+// progressPrinter returns an event handler that prints the progress of an
+// install as it happens.
+func progressPrinter(out io.Writer) action.EventHandler {
+	return func(e action.Event) {
+		switch e.Type {
+		case action.EventPhaseStarted:
+			fmt.Fprintf(out, "==> %s\n", e.Phase)
+		case action.EventPhaseFailed:
+			fmt.Fprintf(out, "==> %s failed: %s\n", e.Phase, e.Err)
+		case action.EventResource:
+			fmt.Fprintf(out, "    %s %s %q in namespace %q\n", e.Action, e.Kind, e.Name, e.Namespace)
+		}
+	}
+}
+
+// reportResourceConflicts prints the existing resources that prevented an
+// install or upgrade because of their ownership metadata, and returns an error
+// that does not repeat them. Other errors are returned as is.
+func reportResourceConflicts(out io.Writer, err error) error {
+	var cerr *action.ConflictError
+	if !errors.As(err, &cerr) {
+		return err
+	}
+	tbl := uitable.New()
+	tbl.AddRow("KIND", "NAME", "NAMESPACE", "KEY", "CURRENT", "EXPECTED", "REASON")
+	resources := 0
+	for i, c := range cerr.Conflicts {
+		current := c.Current
+		if current == "" {
+			current = "<missing>"
+		}
+		tbl.AddRow(c.Kind, c.Name, c.Namespace, c.Key, current, c.Expected, c.Reason)
+		if i == 0 || c.Kind != cerr.Conflicts[i-1].Kind || c.Name != cerr.Conflicts[i-1].Name || c.Namespace != cerr.Conflicts[i-1].Namespace {
+			resources++
+		}
+	}
+	fmt.Fprintln(out, "The following existing resources cannot be imported into the release:")
+	output.EncodeTable(out, tbl)
+	return errors.Errorf("%d existing resource(s) cannot be imported into the release because of their ownership metadata", resources)
+}
@@ -17,12 +17,16 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
 	"testing"
 
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/repo/repotest"
 )
 
@@ -132,6 +136,17 @@ func TestInstall(t *testing.T) {
 			cmd:    "install testdata/testcharts/empty --name-template '{{ \"foobar\"}}'",
 			golden: "output/install-name-template.txt",
 		},
+		// Install, using a name generator
+		{
+			name:   "install with name-generator",
+			cmd:    "install testdata/testcharts/empty --generate-name --name-generator sequential",
+			golden: "output/install-name-generator.txt",
+		},
+		{
+			name:      "install with an invalid name-generator",
+			cmd:       "install testdata/testcharts/empty --generate-name --name-generator bogus",
+			wantError: true,
+		},
 		// Install, perform chart verification along the way.
 		{
 			name:      "install with verification, missing provenance",
@@ -274,12 +289,33 @@ func TestInstall(t *testing.T) {
 			cmd:    "install secrets testdata/testcharts/chart-with-secret --dry-run --hide-secret",
 			golden: "output/install-dry-run-with-secret-hidden.txt",
 		},
+		{
+			name:   "dry-run redacting secret keys",
+			cmd:    "install secrets testdata/testcharts/chart-with-secret --dry-run --redact-secrets keys",
+			golden: "output/install-dry-run-with-secret-redacted.txt",
+		},
+		{
+			name:      "redact-secrets error with unknown mode",
+			cmd:       "install secrets testdata/testcharts/chart-with-secret --dry-run --redact-secrets encrypt",
+			wantError: true,
+		},
 		{
 			name:      "hide-secret error without dry-run",
 			cmd:       "install secrets testdata/testcharts/chart-with-secret --hide-secret",
 			wantError: true,
 			golden:    "output/install-hide-secret.txt",
 		},
+		{
+			name:   "show effective objects with a server-side dry run",
+			cmd:    "install aeneas testdata/testcharts/empty --dry-run=server --show-effective -o json",
+			golden: "output/install-show-effective.json",
+		},
+		{
+			name:      "show-effective error without a server-side dry run",
+			cmd:       "install aeneas testdata/testcharts/empty --dry-run --show-effective",
+			wantError: true,
+			golden:    "output/install-show-effective-client.txt",
+		},
 	}
 
 	runTestCmd(t, tests)
@@ -329,3 +365,45 @@ func TestInstallFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "install myname", true)
 	checkFileCompletion(t, "install myname mychart", false)
 }
+
+func TestReportResourceConflicts(t *testing.T) {
+	var b bytes.Buffer
+	unrelated := errors.New("unrelated")
+	if err := reportResourceConflicts(&b, unrelated); err != unrelated {
+		t.Errorf("expected an unrelated error to be returned as is, got %v", err)
+	}
+	if b.Len() != 0 {
+		t.Errorf("expected no output for an unrelated error, got %q", b.String())
+	}
+
+	err := errors.Wrap(&action.ConflictError{Conflicts: []action.ResourceConflict{{
+		Kind:       "Deployment",
+		Name:       "web",
+		Namespace:  "default",
+		Key:        "meta.helm.sh/release-name",
+		Annotation: true,
+		Current:    "other",
+		Expected:   "web",
+		Reason:     action.ConflictOwnedByOtherRelease,
+	}, {
+		Kind:       "Deployment",
+		Name:       "web",
+		Namespace:  "default",
+		Key:        "meta.helm.sh/release-namespace",
+		Annotation: true,
+		Current:    "other",
+		Expected:   "default",
+		Reason:     action.ConflictOwnedByOtherRelease,
+	}}}, "Unable to continue with install")
+	err = reportResourceConflicts(&b, err)
+	expect := "The following existing resources cannot be imported into the release:\n" +
+		"KIND      \tNAME\tNAMESPACE\tKEY                           \tCURRENT\tEXPECTED\tREASON             \n" +
+		"Deployment\tweb \tdefault  \tmeta.helm.sh/release-name     \tother  \tweb     \tOwnedByOtherRelease\n" +
+		"Deployment\tweb \tdefault  \tmeta.helm.sh/release-namespace\tother  \tdefault \tOwnedByOtherRelease\n"
+	if b.String() != expect {
+		t.Errorf("expected:\n%q\ngot:\n%q", expect, b.String())
+	}
+	if want := "1 existing resource(s) cannot be imported into the release because of their ownership metadata"; err == nil || err.Error() != want {
+		t.Errorf("expected the error %q, got %v", want, err)
+	}
+}
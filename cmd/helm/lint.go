@@ -21,15 +21,19 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/chartutil"
 	"helm.sh/helm/v4/pkg/cli/values"
 	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/helmpath"
+	"helm.sh/helm/v4/pkg/lint/rules"
 	"helm.sh/helm/v4/pkg/lint/support"
 )
 
@@ -40,18 +44,73 @@ the chart is well-formed.
 If the linter encounters things that will cause the chart to fail installation,
 it will emit [ERROR] messages. If it encounters issues that break with convention
 or recommendation, it will emit [WARNING] messages.
+
+To lint the charts with several combinations of values, e.g. the values used in
+production and development, list them in a file passed to '--values-matrix':
+
+    - name: prod
+      values: [ci/prod-values.yaml]
+    - name: minimal
+      set: [ingress.enabled=false, replicaCount=1]
+
+Every combination accepts the keys 'values', 'set', 'set-string', 'set-json',
+'set-file' and 'set-literal', which are added to the values given with the
+flags of the same names. The charts are linted once per combination and the
+failures are reported per combination.
+
+To find values that are no longer used, use '--unused-values'. It reports the
+values in values.yaml which no template of the chart or of its dependencies
+references. Values only used through 'tpl' or computed keys may be reported.
+To check that the import-values of the dependencies refer to existing values,
+use '--import-values'.
+
+To enforce the metadata an organization requires of its charts, e.g. for an
+internal catalog, pass a policy file to '--metadata-policy':
+
+    severity: error            # error, warning (default) or info
+    required: [home, icon, license, maintainers, sources]
+    maintainerEmail: true      # every maintainer needs an email
+    spdxLicense: true          # the license is a valid SPDX expression
+    allowedLicenses: [Apache-2.0, MIT]
+    checkURLs: true            # the URLs of Chart.yaml are reachable
+
+The license is read from the 'artifacthub.io/license' annotation of Chart.yaml,
+or from the annotation set with 'licenseAnnotation'. Checking the URLs
+requires network access; 'urlTimeout' sets the timeout of every URL (10s).
+When '--metadata-policy' is not set, the policy file is read from the
+$HELM_METADATA_POLICY environment variable or, when it exists, from
+'metadata-policy.yaml' in the Helm config directory, so that an organization
+can enforce its policy on every lint. Pass '--metadata-policy=""' to lint
+without it.
 `
 
+// metadataPolicyFile is the default metadata policy file in the Helm config
+// directory.
+const metadataPolicyFile = "metadata-policy.yaml"
+
+// lintCombination is an entry of the file passed to --values-matrix.
+type lintCombination struct {
+	Name          string   `json:"name"`
+	ValueFiles    []string `json:"values"`
+	Values        []string `json:"set"`
+	StringValues  []string `json:"set-string"`
+	JSONValues    []string `json:"set-json"`
+	FileValues    []string `json:"set-file"`
+	LiteralValues []string `json:"set-literal"`
+}
+
 func newLintCmd(out io.Writer) *cobra.Command {
 	client := action.NewLint()
 	valueOpts := &values.Options{}
 	var kubeVersion string
+	var valuesMatrix string
+	var metadataPolicy string
 
 	cmd := &cobra.Command{
 		Use:   "lint PATH",
 		Short: "examine a chart for possible issues",
 		Long:  longLintHelp,
-		RunE: func(_ *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			paths := []string{"."}
 			if len(args) > 0 {
 				paths = args
@@ -80,8 +139,19 @@ func newLintCmd(out io.Writer) *cobra.Command {
 				}
 			}
 
+			if !cmd.Flags().Changed("metadata-policy") {
+				metadataPolicy = defaultMetadataPolicy()
+			}
+			if metadataPolicy != "" {
+				policy, err := rules.LoadMetadataPolicy(metadataPolicy)
+				if err != nil {
+					return err
+				}
+				client.MetadataPolicy = policy
+			}
+
 			client.Namespace = settings.Namespace()
-			vals, err := valueOpts.MergeValues(getter.All(settings))
+			matrix, err := lintValuesMatrix(valueOpts, valuesMatrix)
 			if err != nil {
 				return err
 			}
@@ -89,51 +159,65 @@ func newLintCmd(out io.Writer) *cobra.Command {
 			var message strings.Builder
 			failed := 0
 			errorsOrWarnings := 0
+			var failedCombinations []string
 
 			for _, path := range paths {
-				result := client.Run([]string{path}, vals)
+				for _, result := range client.RunMatrix([]string{path}, matrix) {
+					// If there is no errors/warnings and quiet flag is set
+					// go to the next chart
+					hasWarningsOrErrors := action.HasWarningsOrErrors(result)
+					if hasWarningsOrErrors {
+						errorsOrWarnings++
+					}
+					if client.Quiet && !hasWarningsOrErrors {
+						continue
+					}
 
-				// If there is no errors/warnings and quiet flag is set
-				// go to the next chart
-				hasWarningsOrErrors := action.HasWarningsOrErrors(result)
-				if hasWarningsOrErrors {
-					errorsOrWarnings++
-				}
-				if client.Quiet && !hasWarningsOrErrors {
-					continue
-				}
+					if result.Combination != "" {
+						fmt.Fprintf(&message, "==> Linting %s with values combination %q\n", path, result.Combination)
+					} else {
+						fmt.Fprintf(&message, "==> Linting %s\n", path)
+					}
 
-				fmt.Fprintf(&message, "==> Linting %s\n", path)
+					// All the Errors that are generated by a chart
+					// that failed a lint will be included in the
+					// results.Messages so we only need to print
+					// the Errors if there are no Messages.
+					if len(result.Messages) == 0 {
+						for _, err := range result.Errors {
+							fmt.Fprintf(&message, "Error %s\n", err)
+						}
+					}
 
-				// All the Errors that are generated by a chart
-				// that failed a lint will be included in the
-				// results.Messages so we only need to print
-				// the Errors if there are no Messages.
-				if len(result.Messages) == 0 {
-					for _, err := range result.Errors {
-						fmt.Fprintf(&message, "Error %s\n", err)
+					for _, msg := range result.Messages {
+						if !client.Quiet || msg.Severity > support.InfoSev {
+							fmt.Fprintf(&message, "%s\n", msg)
+						}
 					}
-				}
 
-				for _, msg := range result.Messages {
-					if !client.Quiet || msg.Severity > support.InfoSev {
-						fmt.Fprintf(&message, "%s\n", msg)
+					if len(result.Errors) != 0 {
+						failed++
+						if result.Combination != "" && !slices.Contains(failedCombinations, result.Combination) {
+							failedCombinations = append(failedCombinations, result.Combination)
+						}
 					}
-				}
 
-				if len(result.Errors) != 0 {
-					failed++
+					// Adding extra new line here to break up the
+					// results, stops this from being a big wall of
+					// text and makes it easier to follow.
+					fmt.Fprint(&message, "\n")
 				}
-
-				// Adding extra new line here to break up the
-				// results, stops this from being a big wall of
-				// text and makes it easier to follow.
-				fmt.Fprint(&message, "\n")
 			}
 
 			fmt.Fprint(out, message.String())
 
 			summary := fmt.Sprintf("%d chart(s) linted, %d chart(s) failed", len(paths), failed)
+			if valuesMatrix != "" {
+				summary = fmt.Sprintf("%d chart(s) linted with %d values combination(s), %d lint(s) failed", len(paths), len(matrix), failed)
+				if len(failedCombinations) > 0 {
+					summary += fmt.Sprintf("\nfailed values combinations: %s", strings.Join(failedCombinations, ", "))
+				}
+			}
 			if failed > 0 {
 				return errors.New(summary)
 			}
@@ -149,8 +233,79 @@ func newLintCmd(out io.Writer) *cobra.Command {
 	f.BoolVar(&client.WithSubcharts, "with-subcharts", false, "lint dependent charts")
 	f.BoolVar(&client.Quiet, "quiet", false, "print only warnings and errors")
 	f.BoolVar(&client.SkipSchemaValidation, "skip-schema-validation", false, "if set, disables JSON schema validation")
+	f.BoolVar(&client.ImportValues, "import-values", false, "report the import-values of the dependencies that refer to missing values")
+	f.BoolVar(&client.UnusedValues, "unused-values", false, "report the values in values.yaml that no template references")
 	f.StringVar(&kubeVersion, "kube-version", "", "Kubernetes version used for capabilities and deprecation checks")
+	f.StringVar(&valuesMatrix, "values-matrix", "", "lint the charts with every values combination listed in a YAML file")
+	f.StringVar(&metadataPolicy, "metadata-policy", "", "check the metadata of the charts against the policy in a YAML file")
+	addStrictnessFlags(f, &client.Strictness)
 	addValueOptionsFlags(f, valueOpts)
 
 	return cmd
 }
+
+// defaultMetadataPolicy returns the metadata policy file of the organization,
+// if any: the file of $HELM_METADATA_POLICY, or metadata-policy.yaml in the
+// Helm config directory when it exists.
+func defaultMetadataPolicy() string {
+	if path, ok := os.LookupEnv("HELM_METADATA_POLICY"); ok {
+		return path
+	}
+	path := helmpath.ConfigPath(metadataPolicyFile)
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// lintValuesMatrix merges the values of every combination in the matrix file
+// with the values given on the command line. Without a matrix file the charts
+// are linted with the values of the command line only.
+func lintValuesMatrix(valueOpts *values.Options, path string) ([]action.LintCombination, error) {
+	if path == "" {
+		vals, err := valueOpts.MergeValues(getter.All(settings))
+		if err != nil {
+			return nil, err
+		}
+		return []action.LintCombination{{Values: vals}}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the values matrix")
+	}
+	var combinations []lintCombination
+	if err := yaml.UnmarshalStrict(data, &combinations); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the values matrix %s", path)
+	}
+	if len(combinations) == 0 {
+		return nil, errors.Errorf("the values matrix %s has no combinations", path)
+	}
+
+	matrix := make([]action.LintCombination, 0, len(combinations))
+	seen := map[string]bool{}
+	for i, c := range combinations {
+		if c.Name == "" {
+			c.Name = fmt.Sprintf("combination-%d", i+1)
+		}
+		if seen[c.Name] {
+			return nil, errors.Errorf("values combination %q is listed more than once in %s", c.Name, path)
+		}
+		seen[c.Name] = true
+
+		opts := &values.Options{
+			ValueFiles:    append(slices.Clone(valueOpts.ValueFiles), c.ValueFiles...),
+			Values:        append(slices.Clone(valueOpts.Values), c.Values...),
+			StringValues:  append(slices.Clone(valueOpts.StringValues), c.StringValues...),
+			JSONValues:    append(slices.Clone(valueOpts.JSONValues), c.JSONValues...),
+			FileValues:    append(slices.Clone(valueOpts.FileValues), c.FileValues...),
+			LiteralValues: append(slices.Clone(valueOpts.LiteralValues), c.LiteralValues...),
+		}
+		vals, err := opts.MergeValues(getter.All(settings))
+		if err != nil {
+			return nil, errors.Wrapf(err, "values combination %q", c.Name)
+		}
+		matrix = append(matrix, action.LintCombination{Name: c.Name, Values: vals})
+	}
+	return matrix, nil
+}
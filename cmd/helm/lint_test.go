@@ -18,7 +18,12 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"helm.sh/helm/v4/pkg/helmpath"
 )
 
 func TestLintCmdWithSubchartsFlag(t *testing.T) {
@@ -91,7 +96,85 @@ func TestLintCmdWithKubeVersionFlag(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func TestLintCmdWithValuesMatrix(t *testing.T) {
+	testChart := "testdata/testcharts/chart-with-schema"
+	tests := []cmdTestCase{{
+		name:      "lint chart with a values matrix",
+		cmd:       fmt.Sprintf("lint --values-matrix testdata/lint/values-matrix.yaml %s", testChart),
+		golden:    "output/lint-values-matrix.txt",
+		wantError: true,
+	}, {
+		name:      "lint chart with a values matrix using --quiet flag",
+		cmd:       fmt.Sprintf("lint --quiet --values-matrix testdata/lint/values-matrix.yaml %s", testChart),
+		golden:    "output/lint-values-matrix-quiet.txt",
+		wantError: true,
+	}, {
+		name:      "lint chart with duplicate values combinations",
+		cmd:       fmt.Sprintf("lint --values-matrix testdata/lint/values-matrix-duplicate.yaml %s", testChart),
+		golden:    "output/lint-values-matrix-duplicate.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
 func TestLintFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "lint", true)
 	checkFileCompletion(t, "lint mypath", true) // Multiple paths can be given
 }
+
+func TestLintCmdWithUnusedValues(t *testing.T) {
+	_, out, err := executeActionCommand("lint --unused-values testdata/testcharts/chart-with-schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "[INFO] values.yaml: values are not referenced by any template: addresses, age, employmentInfo, firstname, lastname, likesCoffee, phoneNumbers"
+	if !strings.Contains(out, expect) {
+		t.Errorf("Expected %q in the output, got:\n%s", expect, out)
+	}
+}
+
+func TestLintCmdWithMetadataPolicy(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:      "lint chart against a metadata policy",
+		cmd:       "lint --metadata-policy testdata/lint/metadata-policy.yaml testdata/testcharts/alpine",
+		golden:    "output/lint-metadata-policy.txt",
+		wantError: true,
+	}, {
+		name:      "lint chart against an invalid metadata policy",
+		cmd:       "lint --metadata-policy testdata/lint/metadata-policy-invalid.yaml testdata/testcharts/alpine",
+		golden:    "output/lint-metadata-policy-invalid.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithDefaultMetadataPolicy(t *testing.T) {
+	policy, err := os.ReadFile("testdata/lint/metadata-policy.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	configHome := t.TempDir()
+	t.Setenv(helmpath.ConfigHomeEnvVar, configHome)
+	if err := os.WriteFile(filepath.Join(configHome, metadataPolicyFile), policy, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []cmdTestCase{{
+		name:      "lint chart against the policy of the config directory",
+		cmd:       "lint testdata/testcharts/alpine",
+		golden:    "output/lint-metadata-policy.txt",
+		wantError: true,
+	}, {
+		name: "lint chart without the policy of the config directory",
+		cmd:  `lint --metadata-policy="" testdata/testcharts/alpine`,
+	}}
+	runTestCmd(t, tests)
+
+	t.Setenv("HELM_METADATA_POLICY", "testdata/lint/metadata-policy-invalid.yaml")
+	runTestCmd(t, []cmdTestCase{{
+		name:      "lint chart against the policy of $HELM_METADATA_POLICY",
+		cmd:       "lint testdata/testcharts/alpine",
+		golden:    "output/lint-metadata-policy-invalid.txt",
+		wantError: true,
+	}})
+}
@@ -56,10 +56,15 @@ By default, up to 256 items may be returned. To limit this, use the '--max' flag
 Setting '--max' to 0 will not return all results. Rather, it will return the
 server's default, which may be much higher than 256. Pairing the '--max'
 flag with the '--offset' flag allows you to page through results.
+
+With '--output json' or '--output yaml', every release also includes the user
+or service account ('operator') and the Helm version ('client_version') that
+created its latest revision, for releases that recorded them.
 `
 
 func newListCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewList(cfg)
+	client.MetadataOnly = true
 	var outfmt output.Format
 
 	cmd := &cobra.Command{
@@ -133,13 +138,15 @@ func newListCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 }
 
 type releaseElement struct {
-	Name       string `json:"name"`
-	Namespace  string `json:"namespace"`
-	Revision   string `json:"revision"`
-	Updated    string `json:"updated"`
-	Status     string `json:"status"`
-	Chart      string `json:"chart"`
-	AppVersion string `json:"app_version"`
+	Name          string `json:"name"`
+	Namespace     string `json:"namespace"`
+	Revision      string `json:"revision"`
+	Updated       string `json:"updated"`
+	Status        string `json:"status"`
+	Chart         string `json:"chart"`
+	AppVersion    string `json:"app_version"`
+	Operator      string `json:"operator,omitempty"`
+	ClientVersion string `json:"client_version,omitempty"`
 }
 
 type releaseListWriter struct {
@@ -152,12 +159,14 @@ func newReleaseListWriter(releases []*release.Release, timeFormat string, noHead
 	elements := make([]releaseElement, 0, len(releases))
 	for _, r := range releases {
 		element := releaseElement{
-			Name:       r.Name,
-			Namespace:  r.Namespace,
-			Revision:   strconv.Itoa(r.Version),
-			Status:     r.Info.Status.String(),
-			Chart:      formatChartName(r.Chart),
-			AppVersion: formatAppVersion(r.Chart),
+			Name:          r.Name,
+			Namespace:     r.Namespace,
+			Revision:      strconv.Itoa(r.Version),
+			Status:        r.Info.Status.String(),
+			Chart:         formatChartName(r.Chart),
+			AppVersion:    formatAppVersion(r.Chart),
+			Operator:      r.Info.Operator,
+			ClientVersion: r.Info.ClientVersion,
 		}
 
 		t := "-"
@@ -223,6 +232,7 @@ func compListReleases(toComplete string, ignoredReleaseNames []string, cfg *acti
 	cobra.CompDebugln(fmt.Sprintf("compListReleases with toComplete %s", toComplete), settings.Debug)
 
 	client := action.NewList(cfg)
+	client.MetadataOnly = true
 	client.All = true
 	client.Limit = 0
 	// Do not filter so as to get the entire list of releases.
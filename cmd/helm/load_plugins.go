@@ -32,6 +32,7 @@ import (
 	"sigs.k8s.io/yaml"
 
 	"helm.sh/helm/v4/pkg/plugin"
+	"helm.sh/helm/v4/pkg/registry"
 )
 
 const (
@@ -61,11 +62,19 @@ func loadPlugins(baseCmd *cobra.Command, out io.Writer) {
 		fmt.Fprintf(os.Stderr, "failed to load plugins: %s\n", err)
 		return
 	}
+	found, err = plugin.ResolveDependencies(found)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "some plugins were not loaded: %s\n", err)
+	}
 
 	// Now we create commands for all of these.
 	for _, plug := range found {
 		plug := plug
 		md := plug.Metadata
+		if md.Type == plugin.TypeAuth {
+			// Auth plugins do not provide a command.
+			continue
+		}
 		if md.Usage == "" {
 			md.Usage = fmt.Sprintf("the %q plugin", md.Name)
 		}
@@ -112,6 +121,28 @@ func loadPlugins(baseCmd *cobra.Command, out io.Writer) {
 	}
 }
 
+// authPluginOptions returns the registry client options that make the
+// credentials of auth/v1 plugins available to the registry client.
+func authPluginOptions() []registry.ClientOption {
+	if os.Getenv("HELM_NO_PLUGINS") == "1" {
+		return nil
+	}
+
+	found, err := plugin.FindPlugins(settings.PluginsDirectory)
+	if err != nil {
+		// loadPlugins reports this error.
+		return nil
+	}
+	// loadPlugins reports the plugins left out because of their dependencies.
+	found, _ = plugin.ResolveDependencies(found)
+
+	auth := plugin.NewAuthProvider(found, plugin.DefaultExecLimits)
+	if auth.Empty() {
+		return nil
+	}
+	return []registry.ClientOption{registry.ClientOptCredentialsProvider(auth)}
+}
+
 func processParent(cmd *cobra.Command, args []string) ([]string, error) {
 	k, u := manuallyProcessArgs(args)
 	if err := cmd.Parent().ParseFlags(k); err != nil {
@@ -154,7 +185,7 @@ func callPluginExecutable(pluginName string, main string, argv []string, out io.
 func manuallyProcessArgs(args []string) ([]string, []string) {
 	known := []string{}
 	unknown := []string{}
-	kvargs := []string{"--kube-context", "--namespace", "-n", "--kubeconfig", "--kube-apiserver", "--kube-token", "--kube-as-user", "--kube-as-group", "--kube-ca-file", "--registry-config", "--repository-cache", "--repository-config", "--kube-insecure-skip-tls-verify", "--kube-tls-server-name"}
+	kvargs := []string{"--kube-context", "--namespace", "-n", "--kubeconfig", "--kube-apiserver", "--kube-token", "--kube-as-user", "--kube-as-group", "--kube-as-service-account", "--kube-ca-file", "--registry-config", "--repository-cache", "--repository-config", "--kube-insecure-skip-tls-verify", "--kube-tls-server-name"}
 	knownArg := func(a string) bool {
 		for _, pre := range kvargs {
 			if strings.HasPrefix(a, pre+"=") {
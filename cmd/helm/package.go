@@ -45,6 +45,13 @@ provide '--keyring path/to/secret/keys' and '--key keyname'.
 
 If '--keyring' is not specified, Helm usually defaults to the public keyring
 unless your environment is otherwise configured.
+
+To sign a chart with a cosign key instead, provide '--cosign-key'. The signature
+is written to a '.sig' file next to the archive, which 'cosign verify-blob' can
+verify:
+
+  $ helm package --sign ./mychart --cosign-key cosign.key
+  $ cosign verify-blob --key cosign.pub --signature mychart-0.1.0.tgz.sig mychart-0.1.0.tgz
 `
 
 func newPackageCmd(out io.Writer) *cobra.Command {
@@ -59,7 +66,7 @@ func newPackageCmd(out io.Writer) *cobra.Command {
 			if len(args) == 0 {
 				return errors.Errorf("need at least one argument, the path to the chart")
 			}
-			if client.Sign {
+			if client.Sign && client.CosignKey == "" {
 				if client.Key == "" {
 					return errors.New("--key is required for signing a package")
 				}
@@ -92,14 +99,16 @@ func newPackageCmd(out io.Writer) *cobra.Command {
 
 				if client.DependencyUpdate {
 					downloadManager := &downloader.Manager{
-						Out:              io.Discard,
-						ChartPath:        path,
-						Keyring:          client.Keyring,
-						Getters:          p,
-						Debug:            settings.Debug,
-						RegistryClient:   registryClient,
-						RepositoryConfig: settings.RepositoryConfig,
-						RepositoryCache:  settings.RepositoryCache,
+						Out:                 io.Discard,
+						ChartPath:           path,
+						Keyring:             client.Keyring,
+						Getters:             p,
+						Debug:               settings.Debug,
+						RegistryClient:      registryClient,
+						RepositoryConfig:    settings.RepositoryConfig,
+						RepositoryConfigDir: settings.RepositoryConfigDir,
+						RepositoryCache:     settings.RepositoryCache,
+						Cache:               action.ContentCache(settings),
 					}
 
 					if err := downloadManager.Update(); err != nil {
@@ -120,6 +129,7 @@ func newPackageCmd(out io.Writer) *cobra.Command {
 	f.BoolVar(&client.Sign, "sign", false, "use a PGP private key to sign this package")
 	f.StringVar(&client.Key, "key", "", "name of the key to use when signing. Used if --sign is true")
 	f.StringVar(&client.Keyring, "keyring", defaultKeyring(), "location of a public keyring")
+	f.StringVar(&client.CosignKey, "cosign-key", "", "location of a cosign private key to sign the package with, instead of a PGP key. Used if --sign is true")
 	f.StringVar(&client.PassphraseFile, "passphrase-file", "", `location of a file which contains the passphrase for the signing key. Use "-" in order to read from stdin.`)
 	f.StringVar(&client.Version, "version", "", "set the version on the chart to this semver version")
 	f.StringVar(&client.AppVersion, "app-version", "", "set the appVersion on the chart to this version")
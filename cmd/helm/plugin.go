@@ -17,10 +17,7 @@ package main
 
 import (
 	"io"
-	"os"
-	"os/exec"
 
-	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/pkg/plugin"
@@ -47,34 +44,6 @@ func newPluginCmd(out io.Writer) *cobra.Command {
 
 // runHook will execute a plugin hook.
 func runHook(p *plugin.Plugin, event string) error {
-	plugin.SetupPluginEnv(settings, p.Metadata.Name, p.Dir)
-
-	cmds := p.Metadata.PlatformHooks[event]
-	expandArgs := true
-	if len(cmds) == 0 && len(p.Metadata.Hooks) > 0 {
-		cmd := p.Metadata.Hooks[event]
-		if len(cmd) > 0 {
-			cmds = []plugin.PlatformCommand{{Command: "sh", Args: []string{"-c", cmd}}}
-			expandArgs = false
-		}
-	}
-
-	main, argv, err := plugin.PrepareCommands(cmds, expandArgs, []string{})
-	if err != nil {
-		return nil
-	}
-
-	prog := exec.Command(main, argv...)
-
-	debug("running %s hook: %s", event, prog)
-
-	prog.Stdout, prog.Stderr = os.Stdout, os.Stderr
-	if err := prog.Run(); err != nil {
-		if eerr, ok := err.(*exec.ExitError); ok {
-			os.Stderr.Write(eerr.Stderr)
-			return errors.Errorf("plugin %s hook for %q exited with error", event, p.Metadata.Name)
-		}
-		return err
-	}
-	return nil
+	debug("running %s hook for %s", event, p.Metadata.Name)
+	return plugin.RunHook(settings, p, event)
 }
@@ -19,21 +19,27 @@ import (
 	"fmt"
 	"io"
 
-	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/cmd/helm/require"
-	"helm.sh/helm/v4/pkg/plugin"
+	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/plugin/installer"
 )
 
 type pluginInstallOptions struct {
-	source  string
-	version string
+	source   string
+	version  string
+	verify   bool
+	keyring  string
+	checksum string
 }
 
 const pluginInstallDesc = `
 This command allows you to install a plugin from a url to a VCS repo or a local path.
+
+Plugins installed from an archive URL can be checked before they are installed.
+Use '--checksum' to pin the SHA-256 of the archive, and '--verify' to check its
+signature against the provenance file published next to it (<url>.prov).
 `
 
 func newPluginInstallCmd(out io.Writer) *cobra.Command {
@@ -60,6 +66,9 @@ func newPluginInstallCmd(out io.Writer) *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVar(&o.version, "version", "", "specify a version constraint. If this is not specified, the latest version is installed")
+	cmd.Flags().BoolVar(&o.verify, "verify", false, "verify the signature of the plugin archive before installing it")
+	cmd.Flags().StringVar(&o.keyring, "keyring", defaultKeyring(), "location of public keys used for verification")
+	cmd.Flags().StringVar(&o.checksum, "checksum", "", "expected SHA-256 of the plugin archive, as sha256:<hex>")
 	return cmd
 }
 
@@ -71,21 +80,14 @@ func (o *pluginInstallOptions) complete(args []string) error {
 func (o *pluginInstallOptions) run(out io.Writer) error {
 	installer.Debug = settings.Debug
 
-	i, err := installer.NewForSource(o.source, o.version)
-	if err != nil {
-		return err
-	}
-	if err := installer.Install(i); err != nil {
-		return err
-	}
+	client := action.NewPluginInstall(settings)
+	client.Version = o.version
+	client.Verify = o.verify
+	client.Keyring = o.keyring
+	client.Checksum = o.checksum
 
-	debug("loading plugin from %s", i.Path())
-	p, err := plugin.LoadDir(i.Path())
+	p, err := client.Run(o.source)
 	if err != nil {
-		return errors.Wrap(err, "plugin is installed but unusable")
-	}
-
-	if err := runHook(p, plugin.Install); err != nil {
 		return err
 	}
 
@@ -18,12 +18,12 @@ package main
 import (
 	"fmt"
 	"io"
-	"path/filepath"
 	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/plugin"
 	"helm.sh/helm/v4/pkg/plugin/installer"
 )
@@ -69,9 +69,10 @@ func (o *pluginUpdateOptions) run(out io.Writer) error {
 	}
 	var errorPlugins []string
 
+	client := action.NewPluginUpdate(settings)
 	for _, name := range o.names {
 		if found := findPlugin(plugins, name); found != nil {
-			if err := updatePlugin(found); err != nil {
+			if _, err := client.Run(name); err != nil {
 				errorPlugins = append(errorPlugins, fmt.Sprintf("Failed to update plugin %s, got error (%v)", name, err))
 			} else {
 				fmt.Fprintf(out, "Updated plugin: %s\n", name)
@@ -85,30 +86,3 @@ func (o *pluginUpdateOptions) run(out io.Writer) error {
 	}
 	return nil
 }
-
-func updatePlugin(p *plugin.Plugin) error {
-	exactLocation, err := filepath.EvalSymlinks(p.Dir)
-	if err != nil {
-		return err
-	}
-	absExactLocation, err := filepath.Abs(exactLocation)
-	if err != nil {
-		return err
-	}
-
-	i, err := installer.FindSource(absExactLocation)
-	if err != nil {
-		return err
-	}
-	if err := installer.Update(i); err != nil {
-		return err
-	}
-
-	debug("loading plugin from %s", i.Path())
-	updatedPlugin, err := plugin.LoadDir(i.Path())
-	if err != nil {
-		return err
-	}
-
-	return runHook(updatedPlugin, plugin.Update)
-}
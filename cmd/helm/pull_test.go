@@ -22,6 +22,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 
 	"helm.sh/helm/v4/pkg/repo/repotest"
@@ -365,6 +366,90 @@ func TestPullWithCredentialsCmd(t *testing.T) {
 	}
 }
 
+func TestPullWithHeadersCmd(t *testing.T) {
+	srv, err := repotest.NewTempServerWithCleanup(t, "testdata/testcharts/*.tgz*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	srv.WithMiddleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		if auth, tenant := r.Header.Get("Authorization"), r.Header.Get("X-Tenant"); auth != "Bearer secret" || tenant != "acme" {
+			t.Errorf("Expected request to have the bearer token and the X-Tenant header, got %q, %q", auth, tenant)
+		}
+	}))
+
+	if err := srv.LinkIndices(); err != nil {
+		t.Fatal(err)
+	}
+
+	// An auth/v1 plugin providing the bearer token of the server.
+	pluginsDir := t.TempDir()
+	authDir := filepath.Join(pluginsDir, "auth")
+	if err := os.MkdirAll(authDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	authPlugin := map[string]string{
+		"plugin.yaml": "name: auth\nversion: 0.1.0\ntype: auth/v1\ncommand: sh $HELM_PLUGIN_DIR/auth.sh\nauthHosts: [\"127.0.0.1:*\"]\n",
+		"auth.sh":     "#!/bin/sh\nread -r request\necho '{\"token\":\"secret\"}'\n",
+	}
+	for name, content := range authPlugin {
+		if err := os.WriteFile(filepath.Join(authDir, name), []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		args       string
+		env        string
+		pluginsDir string
+	}{
+		{
+			name: "bearer token from flag",
+			args: "--header 'X-Tenant: acme' --bearer-token secret",
+		},
+		{
+			name: "bearer token from environment",
+			args: "--header 'X-Tenant: acme' --bearer-token-env CHART_TOKEN",
+			env:  "secret",
+		},
+		{
+			name:       "bearer token from auth plugin",
+			args:       "--header 'X-Tenant: acme'",
+			pluginsDir: pluginsDir,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.pluginsDir != "" {
+				if runtime.GOOS == "windows" {
+					t.Skip("the test plugin requires a POSIX shell")
+				}
+				defer func(dir string) { settings.PluginsDirectory = dir }(settings.PluginsDirectory)
+				settings.PluginsDirectory = tt.pluginsDir
+			}
+			t.Setenv("CHART_TOKEN", tt.env)
+			outdir := t.TempDir()
+			cmd := fmt.Sprintf("pull signtest --repo %s %s -d '%s' --repository-config %s --repository-cache %s --registry-config %s",
+				srv.URL(),
+				tt.args,
+				outdir,
+				filepath.Join(srv.Root(), "repositories.yaml"),
+				srv.Root(),
+				filepath.Join(outdir, "config.json"),
+			)
+			if _, _, err := executeActionCommand(cmd); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(filepath.Join(outdir, "signtest-0.1.0.tgz")); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
 func TestPullVersionCompletion(t *testing.T) {
 	repoFile := "testdata/helmhome/helm/repositories.yaml"
 	repoCache := "testdata/helmhome/helm/repository"
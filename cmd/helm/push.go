@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io"
 
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/cmd/helm/require"
@@ -32,6 +33,17 @@ Upload a chart to a registry.
 
 If the chart has an associated provenance file,
 it will also be uploaded.
+
+The chart can also be a chart directory, which is packaged before being
+uploaded. Its dependencies must be present in charts/.
+
+Annotations can be added to the manifest of the uploaded chart, e.g. for the
+policies of the registry, with '--annotation'. The standard annotations of the
+source, revision and licenses of the chart have dedicated flags:
+
+    $ helm push mychart oci://registry.example.com/charts \
+        --source https://github.com/example/charts --revision $(git rev-parse HEAD) \
+        --licenses Apache-2.0
 `
 
 type registryPushOptions struct {
@@ -42,6 +54,11 @@ type registryPushOptions struct {
 	plainHTTP             bool
 	password              string
 	username              string
+	annotations           map[string]string
+	artifactType          string
+	source                string
+	revision              string
+	licenses              string
 }
 
 func newPushCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
@@ -84,7 +101,9 @@ func newPushCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				action.WithTLSClientConfig(o.certFile, o.keyFile, o.caFile),
 				action.WithInsecureSkipTLSVerify(o.insecureSkipTLSverify),
 				action.WithPlainHTTP(o.plainHTTP),
-				action.WithPushOptWriter(out))
+				action.WithPushOptWriter(out),
+				action.WithPushAnnotations(o.manifestAnnotations()),
+				action.WithPushArtifactType(o.artifactType))
 			client.Settings = settings
 			output, err := client.Run(chartRef, remote)
 			if err != nil {
@@ -103,6 +122,29 @@ func newPushCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVar(&o.plainHTTP, "plain-http", false, "use insecure HTTP connections for the chart upload")
 	f.StringVar(&o.username, "username", "", "chart repository username where to locate the requested chart")
 	f.StringVar(&o.password, "password", "", "chart repository password where to locate the requested chart")
+	f.StringToStringVar(&o.annotations, "annotation", nil, "annotation to add to the manifest of the chart, as key=value (can specify multiple or separate values with commas)")
+	f.StringVar(&o.artifactType, "artifact-type", "", "artifactType of the manifest of the chart")
+	f.StringVar(&o.source, "source", "", "URL of the source of the chart, set as the "+ocispec.AnnotationSource+" annotation")
+	f.StringVar(&o.revision, "revision", "", "revision of the source of the chart, set as the "+ocispec.AnnotationRevision+" annotation")
+	f.StringVar(&o.licenses, "licenses", "", "SPDX license expression of the chart, set as the "+ocispec.AnnotationLicenses+" annotation")
 
 	return cmd
 }
+
+// manifestAnnotations returns the annotations set by the flags.
+func (o *registryPushOptions) manifestAnnotations() map[string]string {
+	annotations := map[string]string{}
+	for k, v := range o.annotations {
+		annotations[k] = v
+	}
+	for k, v := range map[string]string{
+		ocispec.AnnotationSource:   o.source,
+		ocispec.AnnotationRevision: o.revision,
+		ocispec.AnnotationLicenses: o.licenses,
+	} {
+		if v != "" {
+			annotations[k] = v
+		}
+	}
+	return annotations
+}
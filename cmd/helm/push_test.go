@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -25,3 +26,19 @@ func TestPushFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "push package.tgz", false)
 	checkFileCompletion(t, "push package.tgz oci://localhost:5000", false)
 }
+
+func TestPushManifestAnnotations(t *testing.T) {
+	o := &registryPushOptions{
+		annotations: map[string]string{"com.example/team": "platform", "org.opencontainers.image.revision": "old"},
+		source:      "https://github.com/example/charts",
+		revision:    "abc123",
+	}
+	expected := map[string]string{
+		"com.example/team":                  "platform",
+		"org.opencontainers.image.source":   "https://github.com/example/charts",
+		"org.opencontainers.image.revision": "abc123",
+	}
+	if got := o.manifestAnnotations(); !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
@@ -33,6 +33,11 @@ import (
 
 const registryLoginDesc = `
 Authenticate to a remote registry.
+
+The proxy and the minimum TLS version given on login are saved for the
+registry, and are used by the following commands that access it, e.g. 'helm
+pull' or 'helm push'. The TLS files and --insecure are only saved with
+--save-tls.
 `
 
 type registryLoginOptions struct {
@@ -43,6 +48,9 @@ type registryLoginOptions struct {
 	keyFile              string
 	caFile               string
 	insecure             bool
+	saveTLS              bool
+	proxy                string
+	minTLSVersion        string
 }
 
 func newRegistryLoginCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
@@ -66,7 +74,10 @@ func newRegistryLoginCmd(cfg *action.Configuration, out io.Writer) *cobra.Comman
 				action.WithCertFile(o.certFile),
 				action.WithKeyFile(o.keyFile),
 				action.WithCAFile(o.caFile),
-				action.WithInsecure(o.insecure))
+				action.WithInsecure(o.insecure),
+				action.WithSaveTLS(o.saveTLS),
+				action.WithProxy(o.proxy),
+				action.WithMinTLSVersion(o.minTLSVersion))
 		},
 	}
 
@@ -78,6 +89,9 @@ func newRegistryLoginCmd(cfg *action.Configuration, out io.Writer) *cobra.Comman
 	f.StringVar(&o.certFile, "cert-file", "", "identify registry client using this SSL certificate file")
 	f.StringVar(&o.keyFile, "key-file", "", "identify registry client using this SSL key file")
 	f.StringVar(&o.caFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
+	f.BoolVar(&o.saveTLS, "save-tls", false, "save the TLS settings (--cert-file, --key-file, --ca-file and --insecure) for the registry, to be used after login")
+	f.StringVar(&o.proxy, "proxy", "", "URL of the proxy used to reach the registry, saved for use after login (http, https, socks5 or socks5h). Defaults to the proxy environment variables")
+	f.StringVar(&o.minTLSVersion, "min-tls-version", "", "minimum TLS version used with the registry, saved for use after login (1.0, 1.1, 1.2 or 1.3)")
 
 	return cmd
 }
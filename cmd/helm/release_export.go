@@ -0,0 +1,104 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/cmd/helm/require"
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+)
+
+var releaseExportHelp = `
+This command writes the resources of the deployed revision of a release as
+plain manifests, one file per resource, to a directory or to a .tgz or .tar.gz
+archive. It is meant for migrating a release out of Helm, to manage its
+resources with kubectl or kustomize.
+
+The 'app.kubernetes.io/managed-by: Helm' label is removed from the manifests,
+unless '--keep-ownership' is set, which sets the ownership metadata that Helm
+sets on the live resources instead. '--kustomize' also writes a
+kustomization.yaml listing the manifests. The hooks of the release are not
+exported.
+
+The manifests only set the namespace where the chart does, apply them to the
+namespace of the release:
+
+	$ helm release export shop ./shop --kustomize
+	$ kubectl apply -k ./shop --namespace shop
+`
+
+func newReleaseExportCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewExportManifests(cfg)
+	var outfmt output.Format
+
+	cmd := &cobra.Command{
+		Use:   "export RELEASE_NAME DEST",
+		Short: "write the resources of a release as plain manifests",
+		Long:  releaseExportHelp,
+		Args:  require.ExactArgs(2),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return compListReleases(toComplete, args, cfg)
+			}
+			if len(args) == 1 {
+				return nil, cobra.ShellCompDirectiveFilterDirs
+			}
+			return noMoreArgsComp()
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			files, err := client.Run(args[0], args[1])
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, &exportedManifestsWriter{files})
+		},
+	}
+
+	f := cmd.Flags()
+	f.IntVar(&client.Version, "revision", 0, "export the revision instead of the deployed one")
+	f.BoolVar(&client.KeepOwnership, "keep-ownership", false, "keep the ownership metadata of the release on the manifests")
+	f.BoolVar(&client.Kustomize, "kustomize", false, "also write a kustomization.yaml listing the manifests")
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+type exportedManifestsWriter struct {
+	files []*action.ExportedManifest
+}
+
+func (w *exportedManifestsWriter) WriteTable(out io.Writer) error {
+	tbl := uitable.New()
+	tbl.AddRow("FILE", "KIND", "NAME", "NAMESPACE")
+	for _, f := range w.files {
+		tbl.AddRow(f.File, f.Kind, f.Name, f.Namespace)
+	}
+	return output.EncodeTable(out, tbl)
+}
+
+func (w *exportedManifestsWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.files)
+}
+
+func (w *exportedManifestsWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.files)
+}
@@ -0,0 +1,51 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/release"
+)
+
+func TestReleaseExportCmd(t *testing.T) {
+	dir := t.TempDir()
+	rels := []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "shop"})}
+	tests := []cmdTestCase{{
+		name:   "export manifests with a kustomization",
+		cmd:    "release export shop " + dir + " --kustomize",
+		rels:   rels,
+		golden: "output/release-export.txt",
+	}, {
+		name:   "export manifests as json",
+		cmd:    "release export shop " + filepath.Join(dir, "shop.tgz") + " -o json",
+		rels:   rels,
+		golden: "output/release-export.json",
+	}, {
+		name:      "release not found",
+		cmd:       "release export shop " + dir,
+		golden:    "output/release-export-not-found.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+
+	if _, err := os.Stat(filepath.Join(dir, "kustomization.yaml")); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/cmd/helm/require"
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+)
+
+var fixOwnershipHelp = `
+This command checks the ownership metadata of the resources of a release
+and repairs it.
+
+Helm marks the resources of a release with the 'app.kubernetes.io/managed-by'
+label and the 'meta.helm.sh/release-name' and 'meta.helm.sh/release-namespace'
+annotations. Upgrades fail when these do not match the release, e.g. after a
+cluster restore or a manual edit. This command patches the mismatched labels
+and annotations of the live resources, leaving the rest of the resources
+untouched.
+
+Use '--dry-run' to only report what would change. Resources annotated as owned
+by another release are only repaired with '--force'.
+`
+
+func newReleaseFixOwnershipCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewFixOwnership(cfg)
+	var outfmt output.Format
+
+	cmd := &cobra.Command{
+		Use:   "fix-ownership RELEASE_NAME",
+		Short: "repair the ownership metadata of the resources of a release",
+		Long:  fixOwnershipHelp,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return noMoreArgsComp()
+			}
+			return compListReleases(toComplete, args, cfg)
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			mismatches, err := client.Run(args[0])
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, &ownershipWriter{mismatches, client.DryRun, client.Force})
+		},
+	}
+
+	f := cmd.Flags()
+	f.IntVar(&client.Version, "revision", 0, "check the resources of the release with this revision instead of the latest one")
+	f.BoolVar(&client.DryRun, "dry-run", false, "report the mismatches without repairing them")
+	f.BoolVar(&client.Force, "force", false, "also repair resources annotated as owned by another release")
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+type ownershipWriter struct {
+	mismatches []*action.OwnershipMismatch
+	dryRun     bool
+	force      bool
+}
+
+func (w *ownershipWriter) WriteTable(out io.Writer) error {
+	if len(w.mismatches) == 0 {
+		_, err := fmt.Fprintln(out, "The ownership metadata of all resources matches the release.")
+		return err
+	}
+
+	tbl := uitable.New()
+	tbl.AddRow("KIND", "NAME", "NAMESPACE", "KEY", "CURRENT", "EXPECTED", "STATUS")
+	for _, m := range w.mismatches {
+		current := m.Current
+		if current == "" {
+			current = "<missing>"
+		}
+		status := "repaired"
+		switch {
+		case w.dryRun && m.Conflict && !w.force:
+			status = "would skip (owned by another release)"
+		case w.dryRun:
+			status = "would repair"
+		case !m.Repaired:
+			status = "skipped (owned by another release)"
+		}
+		tbl.AddRow(m.Kind, m.Name, m.Namespace, m.Key, current, m.Expected, status)
+	}
+	return output.EncodeTable(out, tbl)
+}
+
+func (w *ownershipWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.mismatches)
+}
+
+func (w *ownershipWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.mismatches)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/cmd/helm/require"
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+)
+
+var releaseGCHelp = `
+This command finds and deletes orphaned release records, i.e. the Secrets or
+ConfigMaps in which Helm stores the revisions of the releases in the namespace.
+
+A record is orphaned when
+ - it cannot be decoded into a release, or holds another revision than its name
+   says (Corrupted),
+ - the namespace of the release no longer exists (NamespaceDeleted),
+ - the revision is still pending although a later revision exists, e.g. after
+   an interrupted upgrade (Interrupted),
+ - the revision is beyond '--history-max' (HistoryLimit). The deployed and the
+   latest revision of a release are always kept.
+
+Use '--dry-run' to only report the orphaned records.
+`
+
+func newReleaseGCCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewGarbageCollect(cfg)
+	var outfmt output.Format
+
+	cmd := &cobra.Command{
+		Use:               "gc",
+		Short:             "delete orphaned release records",
+		Long:              releaseGCHelp,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			orphans, err := client.Run()
+			if err != nil && orphans == nil {
+				return err
+			}
+			if werr := outfmt.Write(out, &orphanWriter{orphans, client.DryRun}); werr != nil {
+				return werr
+			}
+			return err
+		},
+	}
+
+	f := cmd.Flags()
+	f.IntVar(&client.MaxHistory, "history-max", settings.MaxHistory, "maximum number of revisions kept per release. Use 0 for no limit")
+	f.BoolVar(&client.DryRun, "dry-run", false, "report the orphaned records without deleting them")
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+type orphanWriter struct {
+	orphans []*action.OrphanedRecord
+	dryRun  bool
+}
+
+func (w *orphanWriter) WriteTable(out io.Writer) error {
+	if len(w.orphans) == 0 {
+		_, err := fmt.Fprintln(out, "No orphaned release records found.")
+		return err
+	}
+
+	tbl := uitable.New()
+	tbl.AddRow("KEY", "REASON", "MESSAGE", "STATUS")
+	for _, o := range w.orphans {
+		status := "deleted"
+		switch {
+		case w.dryRun:
+			status = "would delete"
+		case !o.Deleted:
+			status = "failed"
+		}
+		tbl.AddRow(o.Key, o.Reason, o.Message, status)
+	}
+	return output.EncodeTable(out, tbl)
+}
+
+func (w *orphanWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.orphans)
+}
+
+func (w *orphanWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.orphans)
+}
@@ -0,0 +1,57 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"helm.sh/helm/v4/pkg/release"
+)
+
+func TestReleaseGCCmd(t *testing.T) {
+	mk := func(vers int, status release.Status) *release.Release {
+		return release.Mock(&release.MockReleaseOptions{
+			Name:    "angry-bird",
+			Version: vers,
+			Status:  status,
+		})
+	}
+	rels := []*release.Release{
+		mk(1, release.StatusSuperseded),
+		mk(2, release.StatusPendingUpgrade),
+		mk(3, release.StatusSuperseded),
+		mk(4, release.StatusDeployed),
+	}
+
+	tests := []cmdTestCase{{
+		name:   "dry run",
+		cmd:    "release gc --history-max 2 --dry-run",
+		rels:   rels,
+		golden: "output/release-gc-dry-run.txt",
+	}, {
+		name:   "delete orphaned records",
+		cmd:    "release gc --history-max 2 --output json",
+		rels:   rels,
+		golden: "output/release-gc.json",
+	}, {
+		name:   "no orphaned records",
+		cmd:    "release gc --history-max 0",
+		rels:   rels[2:],
+		golden: "output/release-gc-none.txt",
+	}}
+	runTestCmd(t, tests)
+}
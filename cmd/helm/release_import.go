@@ -0,0 +1,119 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/cmd/helm/require"
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/loader"
+	"helm.sh/helm/v4/pkg/cli/output"
+)
+
+var releaseImportHelp = `
+This command adopts existing resources of the cluster into a new release, e.g.
+to migrate resources applied with kubectl or Terraform to Helm.
+
+The resources are those of the manifests given with '--filename', or those
+matching the label selector given with '--selector' among the kinds given with
+'--kinds'. Their live state is stored as the first revision of the release, and
+they are annotated as owned by the release. Resources owned by another release
+are only imported with '--force'.
+
+A chart, given as a path, is stored with the release for reference. Upgrade
+the release with its chart to manage the resources with Helm from then on:
+fields of the live state that the chart does not render are removed by the
+upgrade.
+
+	$ helm release import shop --selector app=shop --kinds deployments,services
+	$ helm upgrade shop ./shop
+`
+
+func newReleaseImportCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewImport(cfg)
+	var filename string
+	var outfmt output.Format
+
+	cmd := &cobra.Command{
+		Use:   "import RELEASE_NAME [CHART]",
+		Short: "adopt existing resources into a new release",
+		Long:  releaseImportHelp,
+		Args:  require.MinimumNArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 1 {
+				return nil, cobra.ShellCompDirectiveDefault
+			}
+			return noMoreArgsComp()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 2 {
+				return errors.Errorf("%q accepts at most 2 arguments", "helm release import")
+			}
+			if (filename == "") == (client.Selector == "") {
+				return errors.New("either --filename or --selector is required")
+			}
+			client.Namespace = settings.Namespace()
+
+			var chrt *chart.Chart
+			if len(args) == 2 {
+				var err error
+				if chrt, err = loader.Load(args[1]); err != nil {
+					return err
+				}
+			}
+			var manifests io.Reader
+			switch filename {
+			case "":
+			case "-":
+				manifests = os.Stdin
+			default:
+				f, err := os.Open(filename)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				manifests = f
+			}
+
+			rel, err := client.Run(args[0], chrt, manifests)
+			if err != nil {
+				return reportResourceConflicts(cmd.ErrOrStderr(), err)
+			}
+			return outfmt.Write(out, &statusPrinter{
+				release: rel,
+				debug:   settings.Debug,
+			})
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&filename, "filename", "f", "", "manifests of the resources to import, '-' to read them from stdin")
+	f.StringVarP(&client.Selector, "selector", "l", "", "label selector of the resources to import, e.g. app=shop")
+	f.StringSliceVar(&client.Kinds, "kinds", client.Kinds, "kinds of the resources matched by --selector")
+	f.BoolVar(&client.Force, "force", false, "also import resources annotated as owned by another release")
+	f.BoolVar(&client.DryRun, "dry-run", false, "print the release without storing it or annotating the resources")
+	f.StringVar(&client.Description, "description", "", "add a custom description")
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
@@ -0,0 +1,44 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"helm.sh/helm/v4/pkg/release"
+)
+
+func TestReleaseImportCmd(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:      "neither manifests nor selector",
+		cmd:       "release import shop",
+		golden:    "output/release-import-no-resources.txt",
+		wantError: true,
+	}, {
+		name:      "both manifests and selector",
+		cmd:       "release import shop -f testdata/testcharts/empty/templates/empty.yaml -l app=shop",
+		golden:    "output/release-import-no-resources.txt",
+		wantError: true,
+	}, {
+		name:      "existing release",
+		cmd:       "release import shop -f testdata/testcharts/empty/templates/empty.yaml",
+		rels:      []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "shop"})},
+		golden:    "output/release-import-exists.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
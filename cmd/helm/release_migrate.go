@@ -0,0 +1,122 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/gosuri/uitable"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/cmd/helm/require"
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+)
+
+var releaseMigrateHelp = `
+This command copies the release records of the namespace from the storage
+driver set by $HELM_DRIVER to the driver given with '--to', e.g. to move the
+releases from Secrets to a SQL database:
+
+    $ export HELM_DRIVER_SQL_CONNECTION_STRING=postgresql://...
+    $ helm release migrate --to sql
+
+Every copy is read back and compared with the source record. The source records
+are not modified, so the migration can be verified before switching
+$HELM_DRIVER to the new driver and deleting the old records.
+
+Records that the destination already holds are skipped, so an interrupted
+migration is resumed by running the command again. Use '--label' to set custom
+labels on the copied releases, and '--dry-run' to only report the records that
+would be copied.
+`
+
+func newReleaseMigrateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewMigrateStorage(cfg)
+	var outfmt output.Format
+	var to string
+
+	cmd := &cobra.Command{
+		Use:               "migrate --to DRIVER",
+		Short:             "copy release records to another storage driver",
+		Long:              releaseMigrateHelp,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if to == "" {
+				return errors.New("the destination driver must be set with --to")
+			}
+			dest := new(action.Configuration)
+			if err := dest.Init(settings.RESTClientGetter(), settings.Namespace(), to, debug); err != nil {
+				return err
+			}
+
+			records, err := client.Run(cfg.Releases.Driver, dest.Releases.Driver)
+			if err != nil && records == nil {
+				return err
+			}
+			if werr := outfmt.Write(out, &migrationWriter{records}); werr != nil {
+				return werr
+			}
+			return err
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&to, "to", "", "the storage driver to copy the releases to. Values are: configmap, secret, memory, sql, oci")
+	f.StringToStringVarP(&client.Labels, "label", "l", nil, "labels to set on the copied releases. An empty value removes the label. Can be specified multiple times")
+	f.BoolVar(&client.DryRun, "dry-run", false, "report the records that would be copied without copying them")
+	bindOutputFlag(cmd, &outfmt)
+
+	err := cmd.RegisterFlagCompletionFunc("to", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"configmap", "secret", "memory", "sql", "oci"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return cmd
+}
+
+type migrationWriter struct {
+	records []*action.MigratedRecord
+}
+
+func (w *migrationWriter) WriteTable(out io.Writer) error {
+	if len(w.records) == 0 {
+		_, err := fmt.Fprintln(out, "No release records found.")
+		return err
+	}
+
+	tbl := uitable.New()
+	tbl.AddRow("KEY", "NAMESPACE", "STATUS", "MESSAGE")
+	for _, r := range w.records {
+		tbl.AddRow(r.Key, r.Namespace, r.Status, r.Message)
+	}
+	return output.EncodeTable(out, tbl)
+}
+
+func (w *migrationWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.records)
+}
+
+func (w *migrationWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.records)
+}
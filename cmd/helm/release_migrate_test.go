@@ -0,0 +1,53 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"helm.sh/helm/v4/pkg/release"
+)
+
+func TestReleaseMigrateCmd(t *testing.T) {
+	rels := []*release.Release{
+		release.Mock(&release.MockReleaseOptions{Name: "angry-bird", Version: 1, Status: release.StatusSuperseded}),
+		release.Mock(&release.MockReleaseOptions{Name: "angry-bird", Version: 2, Status: release.StatusDeployed}),
+	}
+
+	tests := []cmdTestCase{{
+		name:   "dry run",
+		cmd:    "release migrate --to memory --dry-run",
+		rels:   rels,
+		golden: "output/release-migrate-dry-run.txt",
+	}, {
+		name:   "copy records",
+		cmd:    "release migrate --to memory --label team=a --output json",
+		rels:   rels,
+		golden: "output/release-migrate.json",
+	}, {
+		name:      "missing destination",
+		cmd:       "release migrate",
+		golden:    "output/release-migrate-no-driver.txt",
+		wantError: true,
+	}, {
+		name:      "unknown destination",
+		cmd:       "release migrate --to etcd",
+		golden:    "output/release-migrate-unknown-driver.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
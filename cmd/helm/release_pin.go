@@ -0,0 +1,170 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/cmd/helm/require"
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cli/values"
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+var releasePinHelp = `
+This command consists of multiple subcommands to view and edit the values
+pinned to a release.
+
+Pinned values belong to the release rather than to one of its revisions. They
+are merged into the values of every upgrade, over the values reused from the
+previous revision but under the values passed to 'helm upgrade', and are kept
+by rollbacks. Editing them does not create a revision: they are applied by the
+next upgrade.
+`
+
+var releasePinSetHelp = `
+This command pins values to a release, merging them into the values already
+pinned. Use '--replace' to replace all the pinned values instead. A null value,
+e.g. '--set image.tag=null', unpins the key.
+
+	$ helm release pin set myrelease --set replicaCount=3 -f overrides.yaml
+`
+
+var releasePinUnsetHelp = `
+This command unpins values from a release. A key is the dotted path of a value.
+
+	$ helm release pin unset myrelease image.tag replicaCount
+`
+
+func newReleasePinCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pin",
+		Short: "view and edit the values pinned to a release",
+		Long:  releasePinHelp,
+		Args:  require.NoArgs,
+	}
+
+	cmd.AddCommand(newReleasePinGetCmd(cfg, out))
+	cmd.AddCommand(newReleasePinSetCmd(cfg, out))
+	cmd.AddCommand(newReleasePinUnsetCmd(cfg, out))
+
+	return cmd
+}
+
+func newReleasePinGetCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	var outfmt output.Format
+	client := action.NewPinnedValues(cfg)
+
+	cmd := &cobra.Command{
+		Use:               "get RELEASE_NAME",
+		Short:             "show the values pinned to a release",
+		Args:              require.ExactArgs(1),
+		ValidArgsFunction: compReleasePinArgs(cfg),
+		RunE: func(_ *cobra.Command, args []string) error {
+			vals, err := client.Get(args[0])
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, pinnedValuesWriter(vals))
+		},
+	}
+
+	bindOutputFlag(cmd, &outfmt)
+	return cmd
+}
+
+func newReleasePinSetCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	var outfmt output.Format
+	client := action.NewPinnedValues(cfg)
+	valueOpts := &values.Options{}
+
+	cmd := &cobra.Command{
+		Use:               "set RELEASE_NAME",
+		Short:             "pin values to a release",
+		Long:              releasePinSetHelp,
+		Args:              require.ExactArgs(1),
+		ValidArgsFunction: compReleasePinArgs(cfg),
+		RunE: func(_ *cobra.Command, args []string) error {
+			vals, err := valueOpts.MergeValues(getter.All(settings))
+			if err != nil {
+				return err
+			}
+			pinned, err := client.Set(args[0], vals)
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, pinnedValuesWriter(pinned))
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&client.Replace, "replace", false, "replace all the pinned values instead of merging into them")
+	addValueOptionsFlags(f, valueOpts)
+	bindOutputFlag(cmd, &outfmt)
+	return cmd
+}
+
+func newReleasePinUnsetCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	var outfmt output.Format
+	client := action.NewPinnedValues(cfg)
+
+	cmd := &cobra.Command{
+		Use:               "unset RELEASE_NAME KEY [KEY...]",
+		Short:             "unpin values from a release",
+		Long:              releasePinUnsetHelp,
+		Args:              require.MinimumNArgs(2),
+		ValidArgsFunction: compReleasePinArgs(cfg),
+		RunE: func(_ *cobra.Command, args []string) error {
+			pinned, err := client.Unset(args[0], args[1:]...)
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, pinnedValuesWriter(pinned))
+		},
+	}
+
+	bindOutputFlag(cmd, &outfmt)
+	return cmd
+}
+
+func compReleasePinArgs(cfg *action.Configuration) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return compListReleases(toComplete, args, cfg)
+	}
+}
+
+type pinnedValuesWriter map[string]interface{}
+
+func (v pinnedValuesWriter) WriteTable(out io.Writer) error {
+	fmt.Fprintln(out, "PINNED VALUES:")
+	return output.EncodeYAML(out, map[string]interface{}(v))
+}
+
+func (v pinnedValuesWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, map[string]interface{}(v))
+}
+
+func (v pinnedValuesWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, map[string]interface{}(v))
+}
@@ -0,0 +1,63 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"helm.sh/helm/v4/pkg/release"
+)
+
+func TestReleasePinCmd(t *testing.T) {
+	pinnedRelease := func() []*release.Release {
+		rel := release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})
+		rel.PinnedValues = map[string]interface{}{
+			"image":    map[string]interface{}{"tag": "1.2.3"},
+			"replicas": 3,
+		}
+		return []*release.Release{rel}
+	}
+
+	tests := []cmdTestCase{{
+		name:   "get pinned values",
+		cmd:    "release pin get thomas-guide",
+		golden: "output/release-pin-get.txt",
+		rels:   pinnedRelease(),
+	}, {
+		name:   "pin values",
+		cmd:    "release pin set thomas-guide --set image.tag=1.2.4,cpu=12m",
+		golden: "output/release-pin-set.txt",
+		rels:   pinnedRelease(),
+	}, {
+		name:   "replace pinned values",
+		cmd:    "release pin set thomas-guide --set cpu=12m --replace --output json",
+		golden: "output/release-pin-set-replace.json",
+		rels:   pinnedRelease(),
+	}, {
+		name:   "unpin values",
+		cmd:    "release pin unset thomas-guide image.tag",
+		golden: "output/release-pin-unset.txt",
+		rels:   pinnedRelease(),
+	}, {
+		name:      "unpin values requires a key",
+		cmd:       "release pin unset thomas-guide",
+		golden:    "output/release-pin-unset-args.txt",
+		rels:      pinnedRelease(),
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
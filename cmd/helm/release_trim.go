@@ -0,0 +1,120 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/release"
+)
+
+var releaseTrimHelp = `
+This command deletes the oldest revisions of the releases whose history holds
+more revisions than '--history-max', e.g. histories that grew before the limit
+was set. The deployed and the latest revision of a release are always kept.
+
+The histories of all the releases in the namespace are trimmed when no release
+is given. Use '--dry-run' to only report the revisions beyond the limit.
+
+	$ helm release trim web --history-max 5
+`
+
+func newReleaseTrimCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewTrimHistory(cfg)
+	var outfmt output.Format
+
+	cmd := &cobra.Command{
+		Use:   "trim [RELEASE_NAME...]",
+		Short: "delete the revisions of releases beyond the history limit",
+		Long:  releaseTrimHelp,
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return compListReleases(toComplete, args, cfg)
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			trimmed, err := client.Run(args...)
+			if err != nil && trimmed == nil {
+				return err
+			}
+			if werr := outfmt.Write(out, newTrimmedWriter(trimmed, client.DryRun)); werr != nil {
+				return werr
+			}
+			return err
+		},
+	}
+
+	f := cmd.Flags()
+	f.IntVar(&client.MaxHistory, "history-max", settings.MaxHistory, "maximum number of revisions kept per release")
+	f.BoolVar(&client.DryRun, "dry-run", false, "report the revisions beyond the limit without deleting them")
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+type trimmedRevision struct {
+	Name     string         `json:"name"`
+	Revision int            `json:"revision"`
+	Status   release.Status `json:"status"`
+	Deleted  bool           `json:"deleted"`
+}
+
+type trimmedWriter struct {
+	revisions []trimmedRevision
+}
+
+func newTrimmedWriter(rels []*release.Release, dryRun bool) *trimmedWriter {
+	revisions := make([]trimmedRevision, 0, len(rels))
+	for _, r := range rels {
+		rev := trimmedRevision{Name: r.Name, Revision: r.Version, Deleted: !dryRun}
+		if r.Info != nil {
+			rev.Status = r.Info.Status
+		}
+		revisions = append(revisions, rev)
+	}
+	return &trimmedWriter{revisions}
+}
+
+func (w *trimmedWriter) WriteTable(out io.Writer) error {
+	if len(w.revisions) == 0 {
+		_, err := fmt.Fprintln(out, "No revisions beyond the history limit found.")
+		return err
+	}
+
+	tbl := uitable.New()
+	tbl.AddRow("NAME", "REVISION", "STATUS", "ACTION")
+	for _, r := range w.revisions {
+		op := "deleted"
+		if !r.Deleted {
+			op = "would delete"
+		}
+		tbl.AddRow(r.Name, r.Revision, r.Status, op)
+	}
+	return output.EncodeTable(out, tbl)
+}
+
+func (w *trimmedWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.revisions)
+}
+
+func (w *trimmedWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.revisions)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"helm.sh/helm/v4/pkg/release"
+)
+
+func TestReleaseTrimCmd(t *testing.T) {
+	mk := func(vers int, status release.Status) *release.Release {
+		return release.Mock(&release.MockReleaseOptions{
+			Name:    "angry-bird",
+			Version: vers,
+			Status:  status,
+		})
+	}
+	rels := []*release.Release{
+		mk(1, release.StatusSuperseded),
+		mk(2, release.StatusDeployed),
+		mk(3, release.StatusSuperseded),
+		mk(4, release.StatusFailed),
+	}
+
+	tests := []cmdTestCase{{
+		name:   "dry run",
+		cmd:    "release trim --history-max 2 --dry-run",
+		rels:   rels,
+		golden: "output/release-trim-dry-run.txt",
+	}, {
+		name:   "trim a release",
+		cmd:    "release trim angry-bird --history-max 2 --output json",
+		rels:   rels,
+		golden: "output/release-trim.json",
+	}, {
+		name:   "no revisions beyond the limit",
+		cmd:    "release trim --history-max 10",
+		rels:   rels,
+		golden: "output/release-trim-none.txt",
+	}, {
+		name:      "invalid limit",
+		cmd:       "release trim --history-max 0",
+		rels:      rels,
+		golden:    "output/release-trim-invalid.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/cmd/helm/require"
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+)
+
+var releaseWhoOwnsHelp = `
+This command finds the release, and the revision, that manages a live resource
+in the namespace.
+
+The release is read from the ownership annotations of the resource, then the
+revision from the release records: the latest revision whose manifest, or
+hooks, hold the resource. The revision is unknown when no revision holds it,
+e.g. when the release was uninstalled and the resource kept.
+
+	$ helm release who-owns apps/v1 Deployment web -n shop
+`
+
+func newReleaseWhoOwnsCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewWhoOwns(cfg)
+	var outfmt output.Format
+
+	cmd := &cobra.Command{
+		Use:               "who-owns APIVERSION KIND NAME",
+		Short:             "find the release that manages a resource",
+		Long:              releaseWhoOwnsHelp,
+		Args:              require.ExactArgs(3),
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(_ *cobra.Command, args []string) error {
+			owner, err := client.Run(action.ResourceReference{
+				APIVersion: args[0],
+				Kind:       args[1],
+				Namespace:  settings.Namespace(),
+				Name:       args[2],
+			})
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, &ownerWriter{owner})
+		},
+	}
+
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+type ownerWriter struct {
+	owner *action.ResourceOwner
+}
+
+func (w *ownerWriter) WriteTable(out io.Writer) error {
+	res := w.owner.Resource
+	if w.owner.ReleaseName == "" {
+		_, _ = fmt.Fprintf(out, "%s %q is not managed by a release\n", res.Kind, res.Name)
+		return nil
+	}
+	revision, status := "unknown", ""
+	if w.owner.Revision > 0 {
+		revision, status = fmt.Sprint(w.owner.Revision), w.owner.Status.String()
+	}
+	tbl := uitable.New()
+	tbl.AddRow("RELEASE", "NAMESPACE", "REVISION", "STATUS", "HOOK", "MANAGED BY HELM")
+	tbl.AddRow(w.owner.ReleaseName, w.owner.ReleaseNamespace, revision, status, w.owner.Hook, w.owner.ManagedByHelm)
+	return output.EncodeTable(out, tbl)
+}
+
+func (w *ownerWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.owner)
+}
+
+func (w *ownerWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.owner)
+}
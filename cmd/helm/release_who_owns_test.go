@@ -0,0 +1,62 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/release"
+)
+
+func TestReleaseWhoOwnsCmd(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:      "who-owns requires the api version, kind and name",
+		cmd:       "release who-owns apps/v1 Deployment",
+		golden:    "output/release-who-owns-args.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestOwnerWriter(t *testing.T) {
+	owner := &action.ResourceOwner{
+		Resource:         action.ResourceReference{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "shop", Name: "web"},
+		ManagedByHelm:    true,
+		ReleaseName:      "web",
+		ReleaseNamespace: "shop",
+		Revision:         3,
+		Status:           release.StatusDeployed,
+	}
+	var buf bytes.Buffer
+	if err := (&ownerWriter{owner}).WriteTable(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expect := "RELEASE\tNAMESPACE\tREVISION\tSTATUS  \tHOOK \tMANAGED BY HELM\nweb    \tshop     \t3       \tdeployed\tfalse\ttrue           \n"
+	if buf.String() != expect {
+		t.Errorf("expected %q, got %q", expect, buf.String())
+	}
+
+	buf.Reset()
+	if err := (&ownerWriter{&action.ResourceOwner{Resource: owner.Resource}}).WriteTable(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if expect := "Deployment \"web\" is not managed by a release\n"; buf.String() != expect {
+		t.Errorf("expected %q, got %q", expect, buf.String())
+	}
+}
@@ -17,31 +17,19 @@ limitations under the License.
 package main
 
 import (
-	"context"
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
 	"strings"
-	"time"
 
-	"github.com/gofrs/flock"
-	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
-	"sigs.k8s.io/yaml"
 
 	"helm.sh/helm/v4/cmd/helm/require"
-	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/repo"
 )
 
-// Repositories that have been permanently deleted and no longer work
-var deprecatedRepos = map[string]string{
-	"//kubernetes-charts.storage.googleapis.com":           "https://charts.helm.sh/stable",
-	"//kubernetes-charts-incubator.storage.googleapis.com": "https://charts.helm.sh/incubator",
-}
-
 type repoAddOptions struct {
 	name                 string
 	url                  string
@@ -56,6 +44,8 @@ type repoAddOptions struct {
 	keyFile               string
 	caFile                string
 	insecureSkipTLSverify bool
+	proxy                 string
+	minTLSVersion         string
 
 	repoFile  string
 	repoCache string
@@ -93,6 +83,8 @@ func newRepoAddCmd(out io.Writer) *cobra.Command {
 	f.StringVar(&o.keyFile, "key-file", "", "identify HTTPS client using this SSL key file")
 	f.StringVar(&o.caFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
 	f.BoolVar(&o.insecureSkipTLSverify, "insecure-skip-tls-verify", false, "skip tls certificate checks for the repository")
+	f.StringVar(&o.proxy, "proxy", "", "URL of the proxy used to reach the repository (http, https, socks5 or socks5h). Defaults to the proxy environment variables")
+	f.StringVar(&o.minTLSVersion, "min-tls-version", "", "minimum TLS version used with the repository (1.0, 1.1, 1.2 or 1.3)")
 	f.BoolVar(&o.allowDeprecatedRepos, "allow-deprecated-repos", false, "by default, this command will not allow adding official repos that have been permanently deleted. This disables that behavior")
 	f.BoolVar(&o.passCredentialsAll, "pass-credentials", false, "pass credentials to all domains")
 
@@ -100,50 +92,6 @@ func newRepoAddCmd(out io.Writer) *cobra.Command {
 }
 
 func (o *repoAddOptions) run(out io.Writer) error {
-	// Block deprecated repos
-	if !o.allowDeprecatedRepos {
-		for oldURL, newURL := range deprecatedRepos {
-			if strings.Contains(o.url, oldURL) {
-				return fmt.Errorf("repo %q is no longer available; try %q instead", o.url, newURL)
-			}
-		}
-	}
-
-	// Ensure the file directory exists as it is required for file locking
-	err := os.MkdirAll(filepath.Dir(o.repoFile), os.ModePerm)
-	if err != nil && !os.IsExist(err) {
-		return err
-	}
-
-	// Acquire a file lock for process synchronization
-	repoFileExt := filepath.Ext(o.repoFile)
-	var lockPath string
-	if len(repoFileExt) > 0 && len(repoFileExt) < len(o.repoFile) {
-		lockPath = strings.TrimSuffix(o.repoFile, repoFileExt) + ".lock"
-	} else {
-		lockPath = o.repoFile + ".lock"
-	}
-	fileLock := flock.New(lockPath)
-	lockCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	locked, err := fileLock.TryLockContext(lockCtx, time.Second)
-	if err == nil && locked {
-		defer fileLock.Unlock()
-	}
-	if err != nil {
-		return err
-	}
-
-	b, err := os.ReadFile(o.repoFile)
-	if err != nil && !os.IsNotExist(err) {
-		return err
-	}
-
-	var f repo.File
-	if err := yaml.Unmarshal(b, &f); err != nil {
-		return err
-	}
-
 	if o.username != "" && o.password == "" {
 		if o.passwordFromStdinOpt {
 			passwordFromStdin, err := io.ReadAll(os.Stdin)
@@ -175,47 +123,24 @@ func (o *repoAddOptions) run(out io.Writer) error {
 		KeyFile:               o.keyFile,
 		CAFile:                o.caFile,
 		InsecureSkipTLSverify: o.insecureSkipTLSverify,
+		Proxy:                 o.proxy,
+		MinTLSVersion:         o.minTLSVersion,
 	}
 
-	// Check if the repo name is legal
-	if strings.Contains(o.name, "/") {
-		return errors.Errorf("repository name (%s) contains '/', please specify a different name without '/'", o.name)
+	client := action.NewRepoAdd(settings)
+	client.RepositoryConfig = o.repoFile
+	client.RepositoryCache = o.repoCache
+	client.ForceUpdate = o.forceUpdate
+	client.AllowDeprecatedRepos = o.allowDeprecatedRepos
+	added, err := client.Run(&c)
+	if err != nil {
+		return err
 	}
-
-	// If the repo exists do one of two things:
-	// 1. If the configuration for the name is the same continue without error
-	// 2. When the config is different require --force-update
-	if !o.forceUpdate && f.Has(o.name) {
-		existing := f.Get(o.name)
-		if c != *existing {
-
-			// The input coming in for the name is different from what is already
-			// configured. Return an error.
-			return errors.Errorf("repository name (%s) already exists, please specify a different name", o.name)
-		}
-
+	if !added {
 		// The add is idempotent so do nothing
 		fmt.Fprintf(out, "%q already exists with the same configuration, skipping\n", o.name)
 		return nil
 	}
-
-	r, err := repo.NewChartRepository(&c, getter.All(settings))
-	if err != nil {
-		return err
-	}
-
-	if o.repoCache != "" {
-		r.CachePath = o.repoCache
-	}
-	if _, err := r.DownloadIndexFile(); err != nil {
-		return errors.Wrapf(err, "looks like %q is not a valid chart repository or cannot be reached", o.url)
-	}
-
-	f.Update(&c)
-
-	if err := f.WriteFile(o.repoFile, 0600); err != nil {
-		return err
-	}
 	fmt.Fprintf(out, "%q has been added to your repositories\n", o.name)
 	return nil
 }
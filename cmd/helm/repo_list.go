@@ -38,7 +38,7 @@ func newRepoListCmd(out io.Writer) *cobra.Command {
 		Args:              require.NoArgs,
 		ValidArgsFunction: noMoreArgsCompFunc,
 		RunE: func(_ *cobra.Command, _ []string) error {
-			f, _ := repo.LoadFile(settings.RepositoryConfig)
+			f, _ := repo.LoadFiles(settings.RepositoryConfig, settings.RepositoryConfigDir)
 			if len(f.Repositories) == 0 && !(outfmt == output.JSON || outfmt == output.YAML) {
 				return errors.New("no repositories to show")
 			}
@@ -126,7 +126,7 @@ func filterRepos(repos []*repo.Entry, ignoredRepoNames []string) []*repo.Entry {
 func compListRepos(_ string, ignoredRepoNames []string) []string {
 	var rNames []string
 
-	f, err := repo.LoadFile(settings.RepositoryConfig)
+	f, err := repo.LoadFiles(settings.RepositoryConfig, settings.RepositoryConfigDir)
 	if err == nil && len(f.Repositories) > 0 {
 		filteredRepos := filterRepos(f.Repositories, ignoredRepoNames)
 		for _, repo := range filteredRepos {
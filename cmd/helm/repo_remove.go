@@ -19,15 +19,11 @@ package main
 import (
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
 
-	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/cmd/helm/require"
-	"helm.sh/helm/v4/pkg/helmpath"
-	"helm.sh/helm/v4/pkg/repo"
+	"helm.sh/helm/v4/pkg/action"
 )
 
 type repoRemoveOptions struct {
@@ -58,39 +54,12 @@ func newRepoRemoveCmd(out io.Writer) *cobra.Command {
 }
 
 func (o *repoRemoveOptions) run(out io.Writer) error {
-	r, err := repo.LoadFile(o.repoFile)
-	if isNotExist(err) || len(r.Repositories) == 0 {
-		return errors.New("no repositories configured")
-	}
-
-	for _, name := range o.names {
-		if !r.Remove(name) {
-			return errors.Errorf("no repo named %q found", name)
-		}
-		if err := r.WriteFile(o.repoFile, 0600); err != nil {
-			return err
-		}
-
-		if err := removeRepoCache(o.repoCache, name); err != nil {
-			return err
-		}
+	client := action.NewRepoRemove(settings)
+	client.RepositoryConfig = o.repoFile
+	client.RepositoryCache = o.repoCache
+	removed, err := client.Run(o.names...)
+	for _, name := range removed {
 		fmt.Fprintf(out, "%q has been removed from your repositories\n", name)
 	}
-
-	return nil
-}
-
-func removeRepoCache(root, name string) error {
-	idx := filepath.Join(root, helmpath.CacheChartsFile(name))
-	if _, err := os.Stat(idx); err == nil {
-		os.Remove(idx)
-	}
-
-	idx = filepath.Join(root, helmpath.CacheIndexFile(name))
-	if _, err := os.Stat(idx); os.IsNotExist(err) {
-		return nil
-	} else if err != nil {
-		return errors.Wrapf(err, "can't remove index file %s", idx)
-	}
-	return os.Remove(idx)
+	return err
 }
@@ -19,14 +19,12 @@ package main
 import (
 	"fmt"
 	"io"
-	"slices"
-	"sync"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/cmd/helm/require"
-	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/repo"
 )
 
@@ -79,37 +77,14 @@ func newRepoUpdateCmd(out io.Writer) *cobra.Command {
 }
 
 func (o *repoUpdateOptions) run(out io.Writer) error {
-	f, err := repo.LoadFile(o.repoFile)
-	switch {
-	case isNotExist(err):
+	client := action.NewRepoUpdate(settings)
+	client.RepositoryConfig = o.repoFile
+	client.RepositoryCache = o.repoCache
+	repos, err := client.Repositories(o.names...)
+	if errors.Is(err, action.ErrNoRepositories) {
 		return errNoRepositories
-	case err != nil:
-		return errors.Wrapf(err, "failed loading file: %s", o.repoFile)
-	case len(f.Repositories) == 0:
-		return errNoRepositories
-	}
-
-	var repos []*repo.ChartRepository
-	updateAllRepos := len(o.names) == 0
-
-	if !updateAllRepos {
-		// Fail early if the user specified an invalid repo to update
-		if err := checkRequestedRepos(o.names, f.Repositories); err != nil {
-			return err
-		}
-	}
-
-	for _, cfg := range f.Repositories {
-		if updateAllRepos || isRepoRequested(cfg.Name, o.names) {
-			r, err := repo.NewChartRepository(cfg, getter.All(settings))
-			if err != nil {
-				return err
-			}
-			if o.repoCache != "" {
-				r.CachePath = o.repoCache
-			}
-			repos = append(repos, r)
-		}
+	} else if err != nil {
+		return err
 	}
 
 	return o.update(repos, out, o.failOnRepoUpdateFail)
@@ -117,21 +92,15 @@ func (o *repoUpdateOptions) run(out io.Writer) error {
 
 func updateCharts(repos []*repo.ChartRepository, out io.Writer, failOnRepoUpdateFail bool) error {
 	fmt.Fprintln(out, "Hang tight while we grab the latest from your chart repositories...")
-	var wg sync.WaitGroup
 	var repoFailList []string
-	for _, re := range repos {
-		wg.Add(1)
-		go func(re *repo.ChartRepository) {
-			defer wg.Done()
-			if _, err := re.DownloadIndexFile(); err != nil {
-				fmt.Fprintf(out, "...Unable to get an update from the %q chart repository (%s):\n\t%s\n", re.Config.Name, re.Config.URL, err)
-				repoFailList = append(repoFailList, re.Config.URL)
-			} else {
-				fmt.Fprintf(out, "...Successfully got an update from the %q chart repository\n", re.Config.Name)
-			}
-		}(re)
+	for _, result := range action.UpdateRepositories(repos) {
+		if result.Err != nil {
+			fmt.Fprintf(out, "...Unable to get an update from the %q chart repository (%s):\n\t%s\n", result.Name, result.URL, result.Err)
+			repoFailList = append(repoFailList, result.URL)
+		} else {
+			fmt.Fprintf(out, "...Successfully got an update from the %q chart repository\n", result.Name)
+		}
 	}
-	wg.Wait()
 
 	if len(repoFailList) > 0 && failOnRepoUpdateFail {
 		return fmt.Errorf("Failed to update the following repositories: %s",
@@ -141,23 +110,3 @@ func updateCharts(repos []*repo.ChartRepository, out io.Writer, failOnRepoUpdate
 	fmt.Fprintln(out, "Update Complete. ⎈Happy Helming!⎈")
 	return nil
 }
-
-func checkRequestedRepos(requestedRepos []string, validRepos []*repo.Entry) error {
-	for _, requestedRepo := range requestedRepos {
-		found := false
-		for _, repo := range validRepos {
-			if requestedRepo == repo.Name {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return errors.Errorf("no repositories found matching '%s'.  Nothing will be updated", requestedRepo)
-		}
-	}
-	return nil
-}
-
-func isRepoRequested(repoName string, requestedRepos []string) bool {
-	return slices.Contains(requestedRepos, repoName)
-}
@@ -19,8 +19,8 @@ package main
 import (
 	"fmt"
 	"io"
+	"log"
 	"strconv"
-	"time"
 
 	"github.com/spf13/cobra"
 
@@ -36,6 +36,12 @@ second is a revision (version) number. If this argument is omitted or set to
 0, it will roll back to the previous release.
 
 To see revision numbers, run 'helm history RELEASE'.
+
+By default the whole revision is restored, replaying its manifest. Use
+'--mode values' to restore only the values of the revision, re-rendering the
+current chart with them, or '--mode chart' to restore only the chart of the
+revision, re-rendering it with the current values. The description of the new
+revision, shown by 'helm history', records what was rolled back.
 `
 
 func newRollbackCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
@@ -80,11 +86,41 @@ func newRollbackCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVar(&client.Recreate, "recreate-pods", false, "performs pods restart for the resource if applicable")
 	f.BoolVar(&client.Force, "force", false, "force resource update through delete/recreate if needed")
 	f.BoolVar(&client.DisableHooks, "no-hooks", false, "prevent hooks from running during rollback")
-	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
+	addTimeoutFlag(f, &client.Timeout)
 	f.BoolVar(&client.Wait, "wait", false, "if set, will wait until all Pods, PVCs, Services, and minimum number of Pods of a Deployment, StatefulSet, or ReplicaSet are in a ready state before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVar(&client.CleanupOnFail, "cleanup-on-fail", false, "allow deletion of new resources created in this rollback when rollback fails")
 	f.IntVar(&client.MaxHistory, "history-max", settings.MaxHistory, "limit the maximum number of revisions saved per release. Use 0 for no limit")
+	f.BoolVar(&client.SaveConfig, "save-config", false, "maintain the kubectl.kubernetes.io/last-applied-configuration annotation of the resources like 'kubectl apply', and use it as the original configuration of their updates")
+	f.Var((*rollbackModeValue)(&client.Mode), "mode", "what to restore from the revision. One of: all (the whole revision), values (its values, with the current chart), chart (its chart, with the current values)")
+	err := cmd.RegisterFlagCompletionFunc("mode", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"all", string(action.RollbackValues), string(action.RollbackChart)}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	return cmd
 }
+
+type rollbackModeValue action.RollbackMode
+
+func (m *rollbackModeValue) String() string {
+	if *m == rollbackModeValue(action.RollbackAll) {
+		return "all"
+	}
+	return string(*m)
+}
+
+func (m *rollbackModeValue) Type() string {
+	return "mode"
+}
+
+func (m *rollbackModeValue) Set(val string) error {
+	mode, err := action.ParseRollbackMode(val)
+	if err != nil {
+		return err
+	}
+	*m = rollbackModeValue(mode)
+	return nil
+}
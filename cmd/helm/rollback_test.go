@@ -72,6 +72,20 @@ func TestRollbackCmd(t *testing.T) {
 		golden:    "output/rollback-non-existent-version.txt",
 		rels:      rels,
 		wantError: true,
+	}, {
+		name:   "rollback the values of a release",
+		cmd:    "rollback funny-honey 1 --mode values",
+		golden: "output/rollback.txt",
+		rels: []*release.Release{
+			release.Mock(&release.MockReleaseOptions{Name: "funny-honey", Version: 1, Status: release.StatusSuperseded}),
+			release.Mock(&release.MockReleaseOptions{Name: "funny-honey", Version: 2}),
+		},
+	}, {
+		name:      "rollback a release with an invalid mode",
+		cmd:       "rollback funny-honey 1 --mode everything",
+		golden:    "output/rollback-invalid-mode.txt",
+		rels:      rels,
+		wantError: true,
 	}, {
 		name:      "rollback a release without release name",
 		cmd:       "rollback",
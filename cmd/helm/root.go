@@ -21,7 +21,6 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"net/http"
 	"os"
 	"strings"
 
@@ -30,7 +29,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/clientcmd"
 
-	"helm.sh/helm/v4/internal/tlsutil"
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/registry"
 	"helm.sh/helm/v4/pkg/repo"
@@ -50,23 +48,30 @@ Environment variables:
 | Name                               | Description                                                                                                |
 |------------------------------------|------------------------------------------------------------------------------------------------------------|
 | $HELM_CACHE_HOME                   | set an alternative location for storing cached files.                                                      |
+| $HELM_CACHE_INCLUDES               | cache the output of identical includes while rendering. Set HELM_CACHE_INCLUDES=1 to enable it.            |
 | $HELM_CONFIG_HOME                  | set an alternative location for storing Helm configuration.                                                |
+| $HELM_CONTENT_CACHE                | set the path to the directory of the downloaded charts and plugins, stored by digest.                      |
+| $HELM_CONTENT_CACHE_MAX_SIZE       | set the maximum size of the content cache, e.g. 1Gi (default 0, no limit).                                 |
 | $HELM_DATA_HOME                    | set an alternative location for storing Helm data.                                                         |
 | $HELM_DEBUG                        | indicate whether or not Helm is running in Debug mode                                                      |
-| $HELM_DRIVER                       | set the backend storage driver. Values are: configmap, secret, memory, sql.                                |
+| $HELM_DRIVER                       | set the backend storage driver. Values are: configmap, secret, memory, sql, oci.                           |
+| $HELM_DRIVER_OCI_REPOSITORY        | set the repository below which the OCI storage driver stores the releases of each namespace.               |
 | $HELM_DRIVER_SQL_CONNECTION_STRING | set the connection string the SQL storage driver should use.                                               |
 | $HELM_MAX_HISTORY                  | set the maximum number of helm release history.                                                            |
 | $HELM_NAMESPACE                    | set the namespace used for the helm operations.                                                            |
 | $HELM_NO_PLUGINS                   | disable plugins. Set HELM_NO_PLUGINS=1 to disable plugins.                                                 |
 | $HELM_PLUGINS                      | set the path to the plugins directory                                                                      |
 | $HELM_REGISTRY_CONFIG              | set the path to the registry config file.                                                                  |
+| $HELM_RENDER_PARALLELISM           | set the number of workers rendering the subcharts of a chart in parallel (default 1, rendering sequentially). |
 | $HELM_REPOSITORY_CACHE             | set the path to the repository cache directory                                                             |
 | $HELM_REPOSITORY_CONFIG            | set the path to the repositories file.                                                                     |
+| $HELM_REPOSITORY_CONFIG_DIR        | set the path to the directory of read-only repository files merged with the repositories file.             |
 | $KUBECONFIG                        | set an alternative Kubernetes configuration file (default "~/.kube/config")                                |
 | $HELM_KUBEAPISERVER                | set the Kubernetes API Server Endpoint for authentication                                                  |
 | $HELM_KUBECAFILE                   | set the Kubernetes certificate authority file.                                                             |
 | $HELM_KUBEASGROUPS                 | set the Groups to use for impersonation using a comma-separated list.                                      |
 | $HELM_KUBEASUSER                   | set the Username to impersonate for the operation.                                                         |
+| $HELM_KUBEASSERVICEACCOUNT         | set the service account to impersonate for the operation, as <namespace>:<name>.                           |
 | $HELM_KUBECONTEXT                  | set the name of the kubeconfig context.                                                                    |
 | $HELM_KUBETOKEN                    | set the Bearer KubeToken used for authentication.                                                          |
 | $HELM_KUBEINSECURE_SKIP_TLS_VERIFY | indicate if the Kubernetes API server's certificate validation should be skipped (insecure)                |
@@ -169,9 +174,12 @@ func newRootCmd(actionConfig *action.Configuration, out io.Writer, args []string
 		newPullCmd(actionConfig, out),
 		newShowCmd(actionConfig, out),
 		newLintCmd(out),
+		newUnitTestCmd(out),
+		newSnapshotCmd(out),
 		newPackageCmd(out),
 		newRepoCmd(out),
-		newSearchCmd(out),
+		newCacheCmd(out),
+		newSearchCmd(actionConfig, out),
 		newVerifyCmd(out),
 
 		// release commands
@@ -179,6 +187,7 @@ func newRootCmd(actionConfig *action.Configuration, out io.Writer, args []string
 		newHistoryCmd(actionConfig, out),
 		newInstallCmd(actionConfig, out),
 		newListCmd(actionConfig, out),
+		newReleaseCmd(actionConfig, out),
 		newReleaseTestCmd(actionConfig, out),
 		newRollbackCmd(actionConfig, out),
 		newStatusCmd(actionConfig, out),
@@ -188,6 +197,7 @@ func newRootCmd(actionConfig *action.Configuration, out io.Writer, args []string
 
 		newCompletionCmd(out),
 		newEnvCmd(out),
+		newDoctorCmd(actionConfig, out),
 		newPluginCmd(out),
 		newVersionCmd(out),
 
@@ -198,6 +208,7 @@ func newRootCmd(actionConfig *action.Configuration, out io.Writer, args []string
 	cmd.AddCommand(
 		newRegistryCmd(actionConfig, out),
 		newPushCmd(actionConfig, out),
+		newBundleCmd(actionConfig, out),
 	)
 
 	// Find and add plugins
@@ -261,6 +272,8 @@ func newRegistryClient(
 	certFile, keyFile, caFile string, insecureSkipTLSverify, plainHTTP bool, username, password string,
 ) (*registry.Client, error) {
 	if certFile != "" && keyFile != "" || caFile != "" || insecureSkipTLSverify {
+		// The TLS flags apply to every registry, and take precedence over
+		// the TLS settings saved for the registries on login.
 		registryClient, err := newRegistryClientWithTLS(certFile, keyFile, caFile, insecureSkipTLSverify, username, password)
 		if err != nil {
 			return nil, err
@@ -280,11 +293,13 @@ func newDefaultRegistryClient(plainHTTP bool, username, password string) (*regis
 		registry.ClientOptEnableCache(true),
 		registry.ClientOptWriter(os.Stderr),
 		registry.ClientOptCredentialsFile(settings.RegistryConfig),
+		registry.ClientOptHostsFile(registry.HostsFilePath(settings.RegistryConfig)),
 		registry.ClientOptBasicAuth(username, password),
 	}
 	if plainHTTP {
 		opts = append(opts, registry.ClientOptPlainHTTP())
 	}
+	opts = append(opts, authPluginOptions()...)
 
 	// Create a new registry client
 	registryClient, err := registry.NewClient(opts...)
@@ -297,24 +312,19 @@ func newDefaultRegistryClient(plainHTTP bool, username, password string) (*regis
 func newRegistryClientWithTLS(
 	certFile, keyFile, caFile string, insecureSkipTLSverify bool, username, password string,
 ) (*registry.Client, error) {
-	tlsConf, err := tlsutil.NewClientTLS(certFile, keyFile, caFile, insecureSkipTLSverify)
-	if err != nil {
-		return nil, fmt.Errorf("can't create TLS config for client: %w", err)
-	}
-
-	// Create a new registry client
-	registryClient, err := registry.NewClient(
+	opts := []registry.ClientOption{
 		registry.ClientOptDebug(settings.Debug),
 		registry.ClientOptEnableCache(true),
 		registry.ClientOptWriter(os.Stderr),
 		registry.ClientOptCredentialsFile(settings.RegistryConfig),
-		registry.ClientOptHTTPClient(&http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: tlsConf,
-			},
-		}),
+		registry.ClientOptHostsFile(registry.HostsFilePath(settings.RegistryConfig)),
+		registry.ClientOptTLSClientConfig(certFile, keyFile, caFile, insecureSkipTLSverify),
 		registry.ClientOptBasicAuth(username, password),
-	)
+	}
+	opts = append(opts, authPluginOptions()...)
+
+	// Create a new registry client
+	registryClient, err := registry.NewClient(opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -20,15 +20,18 @@ import (
 	"io"
 
 	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
 )
 
 const searchDesc = `
 Search provides the ability to search for Helm charts in the various places
-they can be stored including the Artifact Hub and repositories you have added.
+they can be stored including the Artifact Hub, repositories you have added and
+OCI registries.
 Use search subcommands to search different locations for charts.
 `
 
-func newSearchCmd(out io.Writer) *cobra.Command {
+func newSearchCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:   "search [keyword]",
@@ -38,6 +41,7 @@ func newSearchCmd(out io.Writer) *cobra.Command {
 
 	cmd.AddCommand(newSearchHubCmd(out))
 	cmd.AddCommand(newSearchRepoCmd(out))
+	cmd.AddCommand(newSearchOCICmd(cfg, out))
 
 	return cmd
 }
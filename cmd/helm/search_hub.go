@@ -22,10 +22,9 @@ import (
 	"strings"
 
 	"github.com/gosuri/uitable"
-	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
-	"helm.sh/helm/v4/internal/monocular"
+	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/cli/output"
 )
 
@@ -47,10 +46,14 @@ Monocular search API. Similarly, when setting the 'endpoint' flag, the specified
 endpoint must also be implement a Monocular compatible search API endpoint.
 Note that when specifying a Monocular instance as the 'endpoint', rich queries
 are not supported. For API details, see https://github.com/helm/monocular
+
+Other chart catalogs can be searched with the '--provider' flag, using the
+search providers available to this Helm build. The Artifact Hub provider
+supports the 'repo' filter, e.g. '--filter repo=bitnami'.
 `
 
 type searchHubOptions struct {
-	searchEndpoint string
+	client         *action.SearchHub
 	maxColWidth    uint
 	outputFormat   output.Format
 	listRepoURL    bool
@@ -58,7 +61,7 @@ type searchHubOptions struct {
 }
 
 func newSearchHubCmd(out io.Writer) *cobra.Command {
-	o := &searchHubOptions{}
+	o := &searchHubOptions{client: action.NewSearchHub()}
 
 	cmd := &cobra.Command{
 		Use:   "hub [KEYWORD]",
@@ -70,7 +73,11 @@ func newSearchHubCmd(out io.Writer) *cobra.Command {
 	}
 
 	f := cmd.Flags()
-	f.StringVar(&o.searchEndpoint, "endpoint", "https://hub.helm.sh", "Hub instance to query for charts")
+	f.StringVar(&o.client.Endpoint, "endpoint", action.DefaultHubEndpoint, "Hub instance to query for charts")
+	f.StringVar(&o.client.Provider, "provider", action.ArtifactHubProviderName, "search provider used to query the hub instance")
+	f.StringToStringVar(&o.client.Filters, "filter", nil, "filter the results by key=value, as supported by the search provider (can specify multiple or separate values with commas)")
+	f.IntVar(&o.client.Offset, "offset", 0, "number of results to skip")
+	f.IntVar(&o.client.MaxResults, "max-results", 0, "maximum number of results to return, 0 for all")
 	f.UintVar(&o.maxColWidth, "max-col-width", 50, "maximum column width for output table")
 	f.BoolVar(&o.listRepoURL, "list-repo-url", false, "print charts repository URL")
 	f.BoolVar(&o.failOnNoResult, "fail-on-no-result", false, "search fails if no results are found")
@@ -81,19 +88,12 @@ func newSearchHubCmd(out io.Writer) *cobra.Command {
 }
 
 func (o *searchHubOptions) run(out io.Writer, args []string) error {
-	c, err := monocular.New(o.searchEndpoint)
-	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("unable to create connection to %q", o.searchEndpoint))
-	}
-
-	q := strings.Join(args, " ")
-	results, err := c.Search(q)
+	results, err := o.client.Run(strings.Join(args, " "))
 	if err != nil {
-		debug("%s", err)
-		return fmt.Errorf("unable to perform search against %q", o.searchEndpoint)
+		return err
 	}
 
-	return o.outputFormat.Write(out, newHubSearchWriter(results, o.searchEndpoint, o.maxColWidth, o.listRepoURL, o.failOnNoResult))
+	return o.outputFormat.Write(out, newHubSearchWriter(results.Results, o.maxColWidth, o.listRepoURL, o.failOnNoResult))
 }
 
 type hubChartRepo struct {
@@ -116,18 +116,10 @@ type hubSearchWriter struct {
 	failOnNoResult bool
 }
 
-func newHubSearchWriter(results []monocular.SearchResult, endpoint string, columnWidth uint, listRepoURL, failOnNoResult bool) *hubSearchWriter {
+func newHubSearchWriter(results []action.SearchResult, columnWidth uint, listRepoURL, failOnNoResult bool) *hubSearchWriter {
 	var elements []hubChartElement
 	for _, r := range results {
-		// Backwards compatibility for Monocular
-		url := endpoint + "/charts/" + r.ID
-
-		// Check for artifactHub compatibility
-		if r.ArtifactHub.PackageURL != "" {
-			url = r.ArtifactHub.PackageURL
-		}
-
-		elements = append(elements, hubChartElement{url, r.Relationships.LatestChartVersion.Data.Version, r.Relationships.LatestChartVersion.Data.AppVersion, r.Attributes.Description, hubChartRepo{URL: r.Attributes.Repo.URL, Name: r.Attributes.Repo.Name}})
+		elements = append(elements, hubChartElement{r.URL, r.Version, r.AppVersion, r.Description, hubChartRepo{URL: r.Repository.URL, Name: r.Repository.Name}})
 	}
 	return &hubSearchWriter{elements, columnWidth, listRepoURL, failOnNoResult}
 }
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -83,6 +84,29 @@ func TestSearchHubListRepoCmd(t *testing.T) {
 	}
 }
 
+func TestSearchHubFilterCmd(t *testing.T) {
+	var searchResult = `{"data":[{"id":"stable/mariadb","attributes":{"name":"mariadb","repo":{"name":"stable"},"description":"MariaDB"}},{"id":"bitnami/mariadb","attributes":{"name":"mariadb","repo":{"name":"bitnami"},"description":"MariaDB"}},{"id":"bitnami/mariadb-galera","attributes":{"name":"mariadb-galera","repo":{"name":"bitnami"},"description":"MariaDB Galera"}}]}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, searchResult)
+	}))
+	defer ts.Close()
+
+	testcmd := "search hub --filter repo=bitnami --max-results 1 --output json --endpoint " + ts.URL + " maria"
+	_, out, err := executeActionCommandC(storageFixture(), testcmd)
+	if err != nil {
+		t.Errorf("unexpected error, %s", err)
+	}
+	expected := fmt.Sprintf(`[{"url":"%s/charts/bitnami/mariadb","version":"","app_version":"","description":"MariaDB","repository":{"url":"","name":"bitnami"}}]`+"\n", ts.URL)
+	if out != expected {
+		t.Errorf("expected and actual output did not match\nexpected: %q\nactual  : %q", expected, out)
+	}
+
+	_, _, err = executeActionCommandC(storageFixture(), "search hub --provider missing maria")
+	if err == nil || !strings.Contains(err.Error(), `unknown search provider "missing"`) {
+		t.Errorf("expected an unknown provider error, got %v", err)
+	}
+}
+
 func TestSearchHubOutputCompletion(t *testing.T) {
 	outputFlagCompletionTest(t, "search hub")
 }
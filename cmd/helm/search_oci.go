@@ -0,0 +1,125 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/cmd/helm/require"
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/registry"
+)
+
+const searchOCIDesc = `
+List the versions of a chart in an OCI registry, from the latest to the oldest.
+
+The tags of the repository which are not semantic versions are ignored. Use
+'--version' to filter the versions with a constraint, and '--offset' and
+'--max-results' to page through them:
+
+    $ helm search oci oci://registry.example.com/charts/mychart --version '>=1.2 <2'
+`
+
+type searchOCIOptions struct {
+	registryPushOptions
+	client         *action.SearchOCI
+	outputFormat   output.Format
+	failOnNoResult bool
+}
+
+func newSearchOCICmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	o := &searchOCIOptions{client: action.NewSearchOCI(cfg)}
+
+	cmd := &cobra.Command{
+		Use:   "oci [REF]",
+		Short: "list the versions of a chart in an OCI registry",
+		Long:  searchOCIDesc,
+		Args:  require.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			registryClient, err := newRegistryClient(
+				o.certFile, o.keyFile, o.caFile, o.insecureSkipTLSverify, o.plainHTTP, o.username, o.password,
+			)
+			if err != nil {
+				return fmt.Errorf("missing registry client: %w", err)
+			}
+			cfg.RegistryClient = registryClient
+			res, err := o.client.Run(args[0])
+			if err != nil {
+				return err
+			}
+			return o.outputFormat.Write(out, &ociSearchWriter{res, o.failOnNoResult})
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&o.client.Version, "version", "", "search using semantic versioning constraints")
+	f.BoolVar(&o.client.Devel, "devel", false, "use development versions (alpha, beta, and release candidate releases), too. Equivalent to version '>0.0.0-0'. If --version is set, this is ignored")
+	f.IntVar(&o.client.Offset, "offset", 0, "number of versions to skip")
+	f.IntVar(&o.client.MaxResults, "max-results", 0, "maximum number of versions to return, 0 for all")
+	f.BoolVar(&o.failOnNoResult, "fail-on-no-result", false, "search fails if no results are found")
+	f.StringVar(&o.certFile, "cert-file", "", "identify registry client using this SSL certificate file")
+	f.StringVar(&o.keyFile, "key-file", "", "identify registry client using this SSL key file")
+	f.StringVar(&o.caFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
+	f.BoolVar(&o.insecureSkipTLSverify, "insecure-skip-tls-verify", false, "skip tls certificate checks for the registry")
+	f.BoolVar(&o.plainHTTP, "plain-http", false, "use insecure HTTP connections for the registry")
+	f.StringVar(&o.username, "username", "", "registry username")
+	f.StringVar(&o.password, "password", "", "registry password")
+
+	bindOutputFlag(cmd, &o.outputFormat)
+
+	return cmd
+}
+
+type ociSearchWriter struct {
+	result         *registry.VersionsResult
+	failOnNoResult bool
+}
+
+func (w *ociSearchWriter) WriteTable(out io.Writer) error {
+	if len(w.result.Versions) == 0 {
+		if w.failOnNoResult {
+			return fmt.Errorf("no results found")
+		}
+		_, err := out.Write([]byte("No results found\n"))
+		return err
+	}
+	table := uitable.New()
+	table.AddRow("VERSION")
+	for _, v := range w.result.Versions {
+		table.AddRow(v)
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (w *ociSearchWriter) WriteJSON(out io.Writer) error {
+	if len(w.result.Versions) == 0 && w.failOnNoResult {
+		return fmt.Errorf("no results found")
+	}
+	return output.EncodeJSON(out, w.result)
+}
+
+func (w *ociSearchWriter) WriteYAML(out io.Writer) error {
+	if len(w.result.Versions) == 0 && w.failOnNoResult {
+		return fmt.Errorf("no results found")
+	}
+	return output.EncodeYAML(out, w.result)
+}
@@ -0,0 +1,57 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/registry"
+)
+
+func TestSearchOCIWriter(t *testing.T) {
+	w := &ociSearchWriter{result: &registry.VersionsResult{Versions: []string{"1.5.0", "1.0.0"}, Total: 3}}
+
+	var out bytes.Buffer
+	if err := w.WriteTable(&out); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "VERSION\n1.5.0  \n1.0.0  \n"; out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+
+	out.Reset()
+	if err := w.WriteJSON(&out); err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"versions":["1.5.0","1.0.0"],"total":3}`; strings.TrimSpace(out.String()) != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+
+	w = &ociSearchWriter{result: &registry.VersionsResult{Versions: []string{}}, failOnNoResult: true}
+	if err := w.WriteTable(&out); err == nil || err.Error() != "no results found" {
+		t.Errorf("expected no results to fail, got %v", err)
+	}
+}
+
+func TestSearchOCICmdNotOCI(t *testing.T) {
+	_, _, err := executeActionCommand("search oci https://example.com/charts/mychart")
+	if err == nil || !strings.Contains(err.Error(), "is not an OCI reference") {
+		t.Errorf("expected a non-OCI reference to be rejected, got %v", err)
+	}
+}
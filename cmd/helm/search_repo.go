@@ -24,6 +24,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/gosuri/uitable"
@@ -31,6 +32,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/cmd/helm/search"
+	"helm.sh/helm/v4/pkg/chart"
 	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/helmpath"
 	"helm.sh/helm/v4/pkg/repo"
@@ -69,6 +71,7 @@ type searchRepoOptions struct {
 	version        string
 	maxColWidth    uint
 	repoFile       string
+	repoConfigDir  string
 	repoCacheDir   string
 	outputFormat   output.Format
 	failOnNoResult bool
@@ -83,6 +86,7 @@ func newSearchRepoCmd(out io.Writer) *cobra.Command {
 		Long:  searchRepoDesc,
 		RunE: func(_ *cobra.Command, args []string) error {
 			o.repoFile = settings.RepositoryConfig
+			o.repoConfigDir = settings.RepositoryConfigDir
 			o.repoCacheDir = settings.RepositoryCache
 			return o.run(out, args)
 		},
@@ -178,7 +182,7 @@ func (o *searchRepoOptions) applyConstraint(res []*search.Result) ([]*search.Res
 
 func (o *searchRepoOptions) buildIndex() (*search.Index, error) {
 	// Load the repositories.yaml
-	rf, err := repo.LoadFile(o.repoFile)
+	rf, err := repo.LoadFiles(o.repoFile, o.repoConfigDir)
 	if isNotExist(err) || len(rf.Repositories) == 0 {
 		return nil, errors.New("no repositories configured")
 	}
@@ -204,6 +208,10 @@ type repoChartElement struct {
 	Version     string `json:"version"`
 	AppVersion  string `json:"app_version"`
 	Description string `json:"description"`
+
+	Deprecated     bool   `json:"deprecated,omitempty"`
+	SupportedUntil string `json:"supported_until,omitempty"`
+	EndOfLife      bool   `json:"end_of_life,omitempty"`
 }
 
 type repoSearchWriter struct {
@@ -229,7 +237,7 @@ func (r *repoSearchWriter) WriteTable(out io.Writer) error {
 	table.MaxColWidth = r.columnWidth
 	table.AddRow("NAME", "CHART VERSION", "APP VERSION", "DESCRIPTION")
 	for _, r := range r.results {
-		table.AddRow(r.Name, r.Chart.Version, r.Chart.AppVersion, r.Chart.Description)
+		table.AddRow(r.Name, r.Chart.Version, r.Chart.AppVersion, supportStatus(r.Chart.Metadata)+r.Chart.Description)
 	}
 	return output.EncodeTable(out, table)
 }
@@ -252,7 +260,15 @@ func (r *repoSearchWriter) encodeByFormat(out io.Writer, format output.Format) e
 	chartList := make([]repoChartElement, 0, len(r.results))
 
 	for _, r := range r.results {
-		chartList = append(chartList, repoChartElement{r.Name, r.Chart.Version, r.Chart.AppVersion, r.Chart.Description})
+		chartList = append(chartList, repoChartElement{
+			Name:           r.Name,
+			Version:        r.Chart.Version,
+			AppVersion:     r.Chart.AppVersion,
+			Description:    r.Chart.Description,
+			Deprecated:     r.Chart.Deprecated,
+			SupportedUntil: r.Chart.SupportedUntil,
+			EndOfLife:      r.Chart.EndOfLife(time.Now()),
+		})
 	}
 
 	switch format {
@@ -267,6 +283,18 @@ func (r *repoSearchWriter) encodeByFormat(out io.Writer, format output.Format) e
 	return nil
 }
 
+// supportStatus returns the prefix of the description of a chart that is
+// deprecated or past the end of its support.
+func supportStatus(md *chart.Metadata) string {
+	switch {
+	case md.EndOfLife(time.Now()):
+		return fmt.Sprintf("(unsupported since %s) ", md.SupportedUntil)
+	case md.Deprecated:
+		return "(deprecated) "
+	}
+	return ""
+}
+
 // Provides the list of charts that are part of the specified repo, and that starts with 'prefix'.
 func compListChartsOfRepo(repoName string, prefix string) []string {
 	var charts []string
@@ -0,0 +1,116 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/cmd/helm/require"
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/cli/values"
+	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/unittest"
+)
+
+var snapshotHelp = `
+This command renders a chart like 'helm template' and compares the manifests
+with a snapshot stored with the chart in 'tests/__snapshot__/<name>.yaml'.
+When they differ, the diff is printed and the command fails.
+
+Use '--update' to write the rendered manifests to the snapshot, and commit the
+snapshot with the chart. Use '--name' to keep snapshots for several values, e.g.
+
+    $ helm snapshot ./mychart --name prod -f ci/prod-values.yaml --update
+
+Timestamps are normalized before the manifests are compared. Normalize other
+volatile values, e.g. generated passwords, with '--normalize-key' or
+'--normalize-pattern'.
+`
+
+func newSnapshotCmd(out io.Writer) *cobra.Command {
+	client := action.NewSnapshot()
+	valueOpts := &values.Options{}
+	var kubeVersion string
+	var normalizeKeys, normalizePatterns []string
+
+	cmd := &cobra.Command{
+		Use:   "snapshot [CHART]",
+		Short: "compare the rendered manifests of a chart with a snapshot",
+		Long:  snapshotHelp,
+		Args:  require.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			if kubeVersion != "" {
+				parsedKubeVersion, err := chartutil.ParseKubeVersion(kubeVersion)
+				if err != nil {
+					return fmt.Errorf("invalid kube version '%s': %s", kubeVersion, err)
+				}
+				client.KubeVersion = parsedKubeVersion
+			}
+			for _, key := range normalizeKeys {
+				client.Rules = append(client.Rules, unittest.NormalizeKey(key))
+			}
+			for _, pattern := range normalizePatterns {
+				rule, err := unittest.NormalizePattern(pattern)
+				if err != nil {
+					return errors.Wrapf(err, "invalid --normalize-pattern %q", pattern)
+				}
+				client.Rules = append(client.Rules, rule)
+			}
+
+			vals, err := valueOpts.MergeValues(getter.All(settings))
+			if err != nil {
+				return err
+			}
+			result, err := client.Run(path, vals)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case result.Updated:
+				fmt.Fprintf(out, "Snapshot %s updated.\n", result.Path)
+			case result.Matched:
+				fmt.Fprintf(out, "Snapshot %s matches the rendered manifests.\n", result.Path)
+			default:
+				fmt.Fprint(out, result.Diff)
+				return errors.Errorf("snapshot %s does not match the rendered manifests, run with --update to update it", result.Path)
+			}
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&client.Name, "name", client.Name, "name of the snapshot")
+	f.BoolVar(&client.Update, "update", false, "write the rendered manifests to the snapshot")
+	f.StringVar(&client.ReleaseName, "release-name", client.ReleaseName, "release name used to render the chart")
+	f.StringVar(&kubeVersion, "kube-version", "", "Kubernetes version used for Capabilities.KubeVersion")
+	f.StringSliceVar(&normalizeKeys, "normalize-key", nil, "replace the values of a YAML key before comparing, e.g. a generated password (can specify multiple)")
+	f.StringArrayVar(&normalizePatterns, "normalize-pattern", nil, "replace the matches of a regular expression before comparing (can specify multiple)")
+	addValueOptionsFlags(f, valueOpts)
+
+	return cmd
+}
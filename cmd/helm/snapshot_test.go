@@ -0,0 +1,47 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSnapshotCmd(t *testing.T) {
+	testChart := "testdata/testcharts/chart-with-snapshot"
+	tests := []cmdTestCase{{
+		name:   "compare a chart with its snapshot",
+		cmd:    fmt.Sprintf("snapshot %s --normalize-key password", testChart),
+		golden: "output/snapshot-match.txt",
+	}, {
+		name:      "compare a chart with changed values with its snapshot",
+		cmd:       fmt.Sprintf("snapshot %s --normalize-key password --set greeting=hi", testChart),
+		golden:    "output/snapshot-mismatch.txt",
+		wantError: true,
+	}, {
+		name:      "compare a chart with a missing snapshot",
+		cmd:       fmt.Sprintf("snapshot %s --normalize-pattern 'password: .*' --name missing", testChart),
+		golden:    "output/snapshot-missing.txt",
+		wantError: true,
+	}, {
+		name:      "compare a chart archive with a snapshot",
+		cmd:       "snapshot testdata/testcharts/compressedchart-0.1.0.tgz",
+		golden:    "output/snapshot-archive.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
@@ -21,9 +21,11 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
 
 	"k8s.io/kubectl/pkg/cmd/get"
@@ -45,13 +47,22 @@ The status consists of:
 - revision of the release
 - description of the release (can be completion message or error message)
 - list of resources that this release consists of
+- outputs of the resources annotated with 'helm.sh/status-field', a JSONPath
+  evaluated against the live resource, e.g. '.status.loadBalancer.ingress[0].ip'
 - details on last test suite run, if applicable
 - additional notes provided by the chart
+
+Use '--hooks' to also show the runs of the hooks in all the revisions of the
+release, or in the revision given by '--revision': when each hook ran, for which
+event, its outcome and, for the Pod and Job hooks, the exit code and the end of
+the logs of its pod. '--hook' limits them to the runs of a hook.
 `
 
 func newStatusCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewStatus(cfg)
+	hookClient := action.NewHookHistory(cfg)
 	var outfmt output.Format
+	var showHooks bool
 
 	cmd := &cobra.Command{
 		Use:   "status RELEASE_NAME",
@@ -79,11 +90,20 @@ func newStatusCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			// strip chart metadata from the output
 			rel.Chart = nil
 
+			var hookRuns []*action.HookRun
+			if showHooks || hookClient.Hook != "" {
+				hookClient.Revision = client.Version
+				if hookRuns, err = hookClient.Run(args[0]); err != nil {
+					return err
+				}
+			}
+
 			return outfmt.Write(out, &statusPrinter{
 				release:      rel,
 				debug:        false,
 				showMetadata: false,
 				hideNotes:    false,
+				hookRuns:     hookRuns,
 			})
 		},
 	}
@@ -102,6 +122,10 @@ func newStatusCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 		log.Fatal(err)
 	}
 
+	f.BoolVar(&showHooks, "hooks", false, "if set, display the runs of the hooks of the release")
+	f.StringVar(&hookClient.Hook, "hook", "", "display the runs of the hook with this name. Implies --hooks")
+
+	bindRedactSecretsFlag(cmd, &client.Redactors)
 	bindOutputFlag(cmd, &outfmt)
 
 	return cmd
@@ -112,14 +136,42 @@ type statusPrinter struct {
 	debug        bool
 	showMetadata bool
 	hideNotes    bool
+	// hookRuns are displayed when set, see 'helm status --hooks'.
+	hookRuns []*action.HookRun
+	// effective are displayed when set, see 'helm install --show-effective'.
+	effective []*action.EffectiveObject
+}
+
+// releaseWithHookRuns is the JSON and YAML output of a release with the runs
+// of its hooks.
+type releaseWithHookRuns struct {
+	*release.Release
+	HookRuns []*action.HookRun `json:"hook_runs"`
+}
+
+// releaseWithEffectiveObjects is the JSON and YAML output of a dry run with
+// the effective objects of its resources.
+type releaseWithEffectiveObjects struct {
+	*release.Release
+	EffectiveObjects []*action.EffectiveObject `json:"effective_objects"`
+}
+
+func (s statusPrinter) object() interface{} {
+	switch {
+	case s.hookRuns != nil:
+		return &releaseWithHookRuns{Release: s.release, HookRuns: s.hookRuns}
+	case s.effective != nil:
+		return &releaseWithEffectiveObjects{Release: s.release, EffectiveObjects: s.effective}
+	}
+	return s.release
 }
 
 func (s statusPrinter) WriteJSON(out io.Writer) error {
-	return output.EncodeJSON(out, s.release)
+	return output.EncodeJSON(out, s.object())
 }
 
 func (s statusPrinter) WriteYAML(out io.Writer) error {
-	return output.EncodeYAML(out, s.release)
+	return output.EncodeYAML(out, s.object())
 }
 
 func (s statusPrinter) WriteTable(out io.Writer) error {
@@ -167,6 +219,12 @@ func (s statusPrinter) WriteTable(out io.Writer) error {
 		_, _ = fmt.Fprintf(out, "RESOURCES:\n%s\n", buf.String())
 	}
 
+	if len(s.release.Info.Outputs) > 0 {
+		if err := writeStatusOutputs(out, s.release.Info.Outputs); err != nil {
+			return err
+		}
+	}
+
 	executions := executionsByHookEvent(s.release)
 	if tests, ok := executions[release.HookTest]; !ok || len(tests) == 0 {
 		_, _ = fmt.Fprintln(out, "TEST SUITE: None")
@@ -185,6 +243,12 @@ func (s statusPrinter) WriteTable(out io.Writer) error {
 		}
 	}
 
+	if s.hookRuns != nil {
+		if err := writeHookRuns(out, s.hookRuns); err != nil {
+			return err
+		}
+	}
+
 	if s.debug {
 		_, _ = fmt.Fprintln(out, "USER-SUPPLIED VALUES:")
 		err := output.EncodeYAML(out, s.release.Config)
@@ -216,6 +280,12 @@ func (s statusPrinter) WriteTable(out io.Writer) error {
 		_, _ = fmt.Fprintf(out, "MANIFEST:\n%s\n", s.release.Manifest)
 	}
 
+	if s.effective != nil {
+		if err := writeEffectiveObjects(out, s.effective); err != nil {
+			return err
+		}
+	}
+
 	// Hide notes from output - option in install and upgrades
 	if !s.hideNotes && len(s.release.Info.Notes) > 0 {
 		fmt.Fprintf(out, "NOTES:\n%s\n", strings.TrimSpace(s.release.Info.Notes))
@@ -223,6 +293,28 @@ func (s statusPrinter) WriteTable(out io.Writer) error {
 	return nil
 }
 
+// writeEffectiveObjects writes the effective objects of a server-side dry run
+// as YAML documents, and the resources rejected by the cluster as comments.
+func writeEffectiveObjects(out io.Writer, objects []*action.EffectiveObject) error {
+	_, _ = fmt.Fprintln(out, "EFFECTIVE OBJECTS:")
+	for _, o := range objects {
+		_, _ = fmt.Fprintf(out, "---\n# %s %s %q: ", o.Operation, o.Kind, o.Name)
+		switch {
+		case o.Error != "":
+			_, _ = fmt.Fprintf(out, "rejected: %s\n", o.Error)
+		case o.Object == nil:
+			_, _ = fmt.Fprintln(out, "hidden")
+		default:
+			_, _ = fmt.Fprintln(out, "accepted")
+			if err := output.EncodeYAML(out, o.Object); err != nil {
+				return err
+			}
+		}
+	}
+	_, _ = fmt.Fprintln(out)
+	return nil
+}
+
 func executionsByHookEvent(rel *release.Release) map[release.HookEvent][]*release.Hook {
 	result := make(map[release.HookEvent][]*release.Hook)
 	for _, h := range rel.Hooks {
@@ -236,3 +328,61 @@ func executionsByHookEvent(rel *release.Release) map[release.HookEvent][]*releas
 	}
 	return result
 }
+
+// writeStatusOutputs writes the status fields declared by the chart, such as
+// the addresses of the services.
+func writeStatusOutputs(out io.Writer, outputs []*release.Output) error {
+	_, _ = fmt.Fprintln(out, "OUTPUTS:")
+	tbl := uitable.New()
+	tbl.AddRow("RESOURCE", "VALUE")
+	for _, o := range outputs {
+		value := o.Value
+		switch {
+		case o.Error != "":
+			value = fmt.Sprintf("<error: %s>", o.Error)
+		case value == "":
+			value = "<none>"
+		}
+		tbl.AddRow(o.Resource, value)
+	}
+	if err := output.EncodeTable(out, tbl); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(out)
+	return nil
+}
+
+// writeHookRuns writes a table of the hook runs, followed by the error and the
+// end of the logs of the runs that failed.
+func writeHookRuns(out io.Writer, runs []*action.HookRun) error {
+	if len(runs) == 0 {
+		_, _ = fmt.Fprintln(out, "HOOK RUNS: None")
+		return nil
+	}
+	_, _ = fmt.Fprintln(out, "HOOK RUNS:")
+	tbl := uitable.New()
+	tbl.AddRow("REVISION", "HOOK", "KIND", "EVENT", "PHASE", "ATTEMPTS", "EXIT CODE", "STARTED", "DURATION")
+	for _, r := range runs {
+		exitCode, duration := "", ""
+		if r.LastRun.ExitCode != nil {
+			exitCode = strconv.Itoa(int(*r.LastRun.ExitCode))
+		}
+		if !r.LastRun.CompletedAt.IsZero() {
+			duration = r.LastRun.CompletedAt.Sub(r.LastRun.StartedAt).Round(time.Second).String()
+		}
+		tbl.AddRow(r.Revision, r.Name, r.Kind, r.LastRun.Event, r.LastRun.Phase, r.LastRun.Attempts, exitCode, r.LastRun.StartedAt.Format(time.ANSIC), duration)
+	}
+	if err := output.EncodeTable(out, tbl); err != nil {
+		return err
+	}
+	for _, r := range runs {
+		if r.LastRun.Phase != release.HookPhaseFailed {
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "==> hook %s failed in revision %d: %s\n", r.Name, r.Revision, r.LastRun.Message)
+		if r.LastRun.LogTail != "" {
+			_, _ = fmt.Fprintf(out, "%s\n", strings.TrimRight(r.LastRun.LogTail, "\n"))
+		}
+	}
+	return nil
+}
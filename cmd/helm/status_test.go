@@ -17,9 +17,11 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
 	"testing"
 	"time"
 
+	"helm.sh/helm/v4/internal/test"
 	"helm.sh/helm/v4/pkg/chart"
 	"helm.sh/helm/v4/pkg/release"
 	helmtime "helm.sh/helm/v4/pkg/time"
@@ -126,15 +128,77 @@ func TestStatusCmd(t *testing.T) {
 				},
 			},
 		),
+	}, {
+		name:   "get status of a deployed release with hook runs",
+		cmd:    "status flummoxed-chickadee --hooks",
+		golden: "output/status-with-hooks.txt",
+		rels:   releasesMockWithHookRuns(),
+	}, {
+		name:   "get status of a deployed release with the runs of a hook in json",
+		cmd:    "status flummoxed-chickadee --hook db-migrate -o json",
+		golden: "output/status-with-hooks.json",
+		rels:   releasesMockWithHookRuns(),
 	}}
 	runTestCmd(t, tests)
 }
 
+func releasesMockWithHookRuns() []*release.Release {
+	exitCode := int32(1)
+	return []*release.Release{{
+		Name:      "flummoxed-chickadee",
+		Namespace: "default",
+		Version:   7,
+		Info:      &release.Info{Status: release.StatusDeployed, LastDeployed: helmtime.Unix(1452902400, 0).UTC()},
+		Chart:     &chart.Chart{Metadata: &chart.Metadata{Name: "name", Version: "1.2.3", AppVersion: "3.2.1"}},
+		Hooks: []*release.Hook{{
+			Name:   "db-migrate",
+			Kind:   "Job",
+			Path:   "name/templates/migrate.yaml",
+			Events: []release.HookEvent{release.HookPreUpgrade},
+			LastRun: release.HookExecution{
+				StartedAt:   mustParseTime("2006-01-02T15:00:05Z"),
+				CompletedAt: mustParseTime("2006-01-02T15:01:07Z"),
+				Phase:       release.HookPhaseFailed,
+				Event:       release.HookPreUpgrade,
+				Attempts:    1,
+				Message:     "job failed: BackoffLimitExceeded",
+				ExitCode:    &exitCode,
+				LogTail:     "migrating schema\nerror: relation \"users\" already exists\n",
+			},
+		}, {
+			Name:   "notify",
+			Kind:   "ConfigMap",
+			Path:   "name/templates/notify.yaml",
+			Events: []release.HookEvent{release.HookPostUpgrade},
+		}},
+	}}
+}
+
 func mustParseTime(t string) helmtime.Time {
 	res, _ := helmtime.Parse(time.RFC3339, t)
 	return res
 }
 
+func TestStatusPrinterOutputs(t *testing.T) {
+	rel := &release.Release{
+		Name:      "flummoxed-chickadee",
+		Namespace: "default",
+		Info: &release.Info{
+			Status: release.StatusDeployed,
+			Outputs: []*release.Output{
+				{Resource: "Service/web", Expression: ".status.loadBalancer.ingress[0].ip", Value: "203.0.113.7"},
+				{Resource: "Service/api", Expression: ".status.loadBalancer.ingress[0].ip"},
+				{Resource: "Ingress/web", Expression: "{.spec", Error: "unclosed action"},
+			},
+		},
+	}
+	var out bytes.Buffer
+	if err := (&statusPrinter{release: rel}).WriteTable(&out); err != nil {
+		t.Fatal(err)
+	}
+	test.AssertGoldenString(t, out.String(), "output/status-with-outputs.txt")
+}
+
 func TestStatusCompletion(t *testing.T) {
 	rels := []*release.Release{
 		{
@@ -24,6 +24,8 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"runtime/pprof"
 	"slices"
 	"sort"
 	"strings"
@@ -31,11 +33,13 @@ import (
 	"helm.sh/helm/v4/pkg/release"
 
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 
 	"helm.sh/helm/v4/cmd/helm/require"
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/chartutil"
 	"helm.sh/helm/v4/pkg/cli/values"
+	"helm.sh/helm/v4/pkg/engine"
 	"helm.sh/helm/v4/pkg/releaseutil"
 )
 
@@ -56,6 +60,8 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	var kubeVersion string
 	var extraAPIs []string
 	var showFiles []string
+	var debugDir string
+	var profileDir string
 
 	cmd := &cobra.Command{
 		Use:   "template [NAME] [CHART]",
@@ -93,7 +99,32 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			client.ClientOnly = !validate
 			client.APIVersions = chartutil.VersionSet(extraAPIs)
 			client.IncludeCRDs = includeCrds
+			if debugDir != "" {
+				cfg.RenderDebug = &engine.RenderDebug{}
+			}
+			var profile *renderProfile
+			if profileDir != "" {
+				cfg.RenderStats = &engine.RenderStats{}
+				if profile, err = startRenderProfile(profileDir); err != nil {
+					return err
+				}
+			}
 			rel, err := runInstall(args, client, valueOpts, out)
+			if profile != nil {
+				stats := cfg.RenderStats
+				cfg.RenderStats = nil
+				if err := profile.stop(stats); err != nil {
+					return err
+				}
+			}
+			if debugDir != "" {
+				// The debugging information is most useful when rendering fails.
+				debug := cfg.RenderDebug
+				cfg.RenderDebug = nil
+				if err := writeRenderDebug(debugDir, debug); err != nil {
+					return err
+				}
+			}
 
 			if err != nil && !settings.Debug {
 				if rel != nil {
@@ -139,12 +170,7 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				if len(showFiles) > 0 {
 					// This is necessary to ensure consistent manifest ordering when using --show-only
 					// with globs or directory names.
-					splitManifests := releaseutil.SplitManifests(manifests.String())
-					manifestsKeys := make([]string, 0, len(splitManifests))
-					for k := range splitManifests {
-						manifestsKeys = append(manifestsKeys, k)
-					}
-					sort.Sort(releaseutil.BySplitManifestsOrder(manifestsKeys))
+					splitManifests := releaseutil.SplitDocuments(manifests.String())
 
 					manifestNameRegex := regexp.MustCompile("# Source: [^/]+/(.+)")
 					var manifestsToRender []string
@@ -152,8 +178,7 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 						missing := true
 						// Use linux-style filepath separators to unify user's input path
 						f = filepath.ToSlash(f)
-						for _, manifestKey := range manifestsKeys {
-							manifest := splitManifests[manifestKey]
+						for _, manifest := range splitManifests {
 							submatch := manifestNameRegex.FindStringSubmatch(manifest)
 							if len(submatch) == 0 {
 								continue
@@ -201,7 +226,11 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.StringVar(&kubeVersion, "kube-version", "", "Kubernetes version used for Capabilities.KubeVersion")
 	f.StringSliceVarP(&extraAPIs, "api-versions", "a", []string{}, "Kubernetes api versions used for Capabilities.APIVersions")
 	f.BoolVar(&client.UseReleaseName, "release-name", false, "use release name in the output-dir path.")
+	f.StringVar(&debugDir, "debug-dir", "", "writes the values, the render duration and the included templates of every executed template to files in debug-dir")
+	f.StringVar(&profileDir, "profile", "", "writes the render duration, the output size and the number of includes and lookups of every chart and template, and CPU and heap pprof profiles, to files in the given directory")
 	bindPostRenderFlag(cmd, &client.PostRenderer)
+	bindRedactSecretsFlag(cmd, &client.Redactors)
+	bindManifestFormatFlag(cmd, &client.ManifestFormat)
 
 	return cmd
 }
@@ -210,6 +239,79 @@ func isTestHook(h *release.Hook) bool {
 	return slices.Contains(h.Events, release.HookTest)
 }
 
+// writeRenderDebug writes the debugging information of every executed
+// template to a YAML file in dir, named after the template.
+func writeRenderDebug(dir string, debug *engine.RenderDebug) error {
+	for _, td := range debug.Templates {
+		data, err := yaml.Marshal(td)
+		if err != nil {
+			return err
+		}
+		filename := filepath.Join(dir, filepath.FromSlash(td.Name)+".debug.yaml")
+		if err := ensureDirectoryForFile(filename); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filename, data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderProfile profiles the CPU and the heap while a chart is rendered, and
+// writes the profiles and the render stats to files in dir.
+type renderProfile struct {
+	dir string
+	cpu *os.File
+}
+
+func startRenderProfile(dir string) (*renderProfile, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	cpu, err := os.Create(filepath.Join(dir, "cpu.pprof"))
+	if err != nil {
+		return nil, err
+	}
+	if err := pprof.StartCPUProfile(cpu); err != nil {
+		cpu.Close()
+		return nil, fmt.Errorf("unable to profile the render: %w", err)
+	}
+	return &renderProfile{dir: dir, cpu: cpu}, nil
+}
+
+// stop stops the profiling and writes the stats, with the slowest charts and
+// templates first.
+func (p *renderProfile) stop(stats *engine.RenderStats) error {
+	pprof.StopCPUProfile()
+	if err := p.cpu.Close(); err != nil {
+		return err
+	}
+
+	heap, err := os.Create(filepath.Join(p.dir, "heap.pprof"))
+	if err != nil {
+		return err
+	}
+	defer heap.Close()
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(heap); err != nil {
+		return fmt.Errorf("unable to profile the render: %w", err)
+	}
+
+	charts := stats.Charts()
+	sort.SliceStable(charts, func(i, j int) bool { return charts[i].Duration.Duration > charts[j].Duration.Duration })
+	templates := slices.Clone(stats.Templates)
+	sort.SliceStable(templates, func(i, j int) bool { return templates[i].Duration.Duration > templates[j].Duration.Duration })
+	data, err := yaml.Marshal(map[string]interface{}{
+		"charts":    charts,
+		"templates": templates,
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(p.dir, "render-stats.yaml"), data, 0644)
+}
+
 // The following functions (writeToFile, createOrOpenFile, and ensureDirectoryForFile)
 // are copied from the actions package. This is part of a change to correct a
 // bug introduced by #8156. As part of the todo to refactor renderResources
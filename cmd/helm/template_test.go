@@ -18,7 +18,9 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -45,6 +47,11 @@ func TestTemplateCmd(t *testing.T) {
 			cmd:    fmt.Sprintf("template '%s'", chartPath),
 			golden: "output/template.txt",
 		},
+		{
+			name:   "check redacting secrets",
+			cmd:    "template secrets testdata/testcharts/chart-with-secret --redact-secrets hash",
+			golden: "output/template-redacted-secrets.txt",
+		},
 		{
 			name:   "check set name",
 			cmd:    fmt.Sprintf("template '%s' --set service.name=apache", chartPath),
@@ -113,6 +120,22 @@ func TestTemplateCmd(t *testing.T) {
 			cmd:    fmt.Sprintf("template '%s' --show-only templates/service.yaml --show-only charts/subcharta/templates/service.yaml", chartPath),
 			golden: "output/template-show-only-multiple.txt",
 		},
+		{
+			name:   "template with json manifest format",
+			cmd:    fmt.Sprintf("template '%s' --show-only templates/service.yaml --manifest-format json", chartPath),
+			golden: "output/template-manifest-format-json.txt",
+		},
+		{
+			name:   "template with normalized yaml manifest format",
+			cmd:    fmt.Sprintf("template '%s' --show-only templates/service.yaml --manifest-format yaml", chartPath),
+			golden: "output/template-manifest-format-yaml.txt",
+		},
+		{
+			name:      "template with invalid manifest format",
+			cmd:       fmt.Sprintf("template '%s' --manifest-format xml", chartPath),
+			wantError: true,
+			golden:    "output/template-manifest-format-invalid.txt",
+		},
 		{
 			name:   "template with show-only glob",
 			cmd:    fmt.Sprintf("template '%s' --show-only templates/subdir/role*", chartPath),
@@ -213,3 +236,47 @@ func TestTemplateFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "template myname", true)
 	checkFileCompletion(t, "template myname mychart", false)
 }
+
+func TestTemplateCmdDebugDir(t *testing.T) {
+	dir := t.TempDir()
+	_, _, err := executeActionCommand(fmt.Sprintf("template '%s' --debug-dir '%s'", chartPath, dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "subchart", "templates", "service.yaml.debug.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{"name: subchart/templates/service.yaml", "duration:", "values:"} {
+		if !strings.Contains(string(data), expect) {
+			t.Errorf("Expected %q in the debugging information, got:\n%s", expect, data)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "subchart", "charts", "subcharta", "templates", "service.yaml.debug.yaml")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTemplateCmdProfile(t *testing.T) {
+	dir := t.TempDir()
+	_, _, err := executeActionCommand(fmt.Sprintf("template '%s' --profile '%s'", chartPath, dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "render-stats.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{"chart: subchart/charts/subcharta", "name: subchart/templates/service.yaml", "outputBytes:", "includes:", "lookups:"} {
+		if !strings.Contains(string(data), expect) {
+			t.Errorf("Expected %q in the render stats, got:\n%s", expect, data)
+		}
+	}
+	for _, name := range []string{"cpu.pprof", "heap.pprof"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+{{- define "chart-with-unittests.fullname" -}}
+{{- printf "%s-%s" .Release.Name .Chart.Name | trunc 63 | trimSuffix "-" }}
+{{- end }}
@@ -0,0 +1,157 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gosuri/uitable"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/cmd/helm/require"
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/unittest"
+)
+
+var unitTestHelp = `
+This command runs the unit tests of a chart. Unlike 'helm test', it does not
+need a cluster: the templates are rendered locally and checked with the
+assertions of the tests.
+
+The tests are defined in test suites in the 'tests/' directory of the chart,
+in files named '*_test.yaml':
+
+    suite: deployment
+    templates:
+      - templates/deployment.yaml
+    tests:
+      - it: uses the production values
+        values:
+          - tests/values/prod.yaml
+        set:
+          image.tag: "1.27"
+        asserts:
+          - isKind:
+              of: Deployment
+          - equal:
+              path: spec.replicas
+              value: 3
+          - matchRegex:
+              path: spec.template.spec.containers[0].image
+              pattern: :1\.27$
+
+The assertions are 'equal', 'contains', 'matchRegex', 'exists', 'isKind',
+'hasDocuments' and 'failedTemplate'. Every assertion can be inverted with
+'not: true' and limited with 'template' and 'documentIndex'.
+
+Add 'tests/' to the .helmignore file of the chart to leave the tests out of the
+packaged chart.
+`
+
+func newUnitTestCmd(out io.Writer) *cobra.Command {
+	client := action.NewUnitTest()
+	var outfmt output.Format
+	var kubeVersion string
+
+	cmd := &cobra.Command{
+		Use:   "unittest [CHART]",
+		Short: "run the unit tests of a chart",
+		Long:  unitTestHelp,
+		Args:  require.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			if kubeVersion != "" {
+				parsedKubeVersion, err := chartutil.ParseKubeVersion(kubeVersion)
+				if err != nil {
+					return fmt.Errorf("invalid kube version '%s': %s", kubeVersion, err)
+				}
+				client.KubeVersion = parsedKubeVersion
+			}
+
+			result, err := client.Run(path)
+			if err != nil {
+				return err
+			}
+			if err := outfmt.Write(out, &unitTestWriter{result}); err != nil {
+				return err
+			}
+			if passed, failed := result.Counts(); failed > 0 {
+				return errors.Errorf("%d of %d test(s) failed", failed, passed+failed)
+			}
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&kubeVersion, "kube-version", "", "Kubernetes version used for Capabilities.KubeVersion unless a test suite sets one")
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+type unitTestWriter struct {
+	result *unittest.Result
+}
+
+func (w *unitTestWriter) WriteTable(out io.Writer) error {
+	tbl := uitable.New()
+	tbl.AddRow("SUITE", "TEST", "RESULT")
+	for _, s := range w.result.Suites {
+		for _, t := range s.Tests {
+			status := "PASS"
+			if !t.Passed {
+				status = "FAIL"
+			}
+			tbl.AddRow(s.Name, t.Name, status)
+		}
+	}
+	if err := output.EncodeTable(out, tbl); err != nil {
+		return err
+	}
+
+	for _, s := range w.result.Suites {
+		for _, t := range s.Tests {
+			if t.Passed {
+				continue
+			}
+			fmt.Fprintf(out, "\n%s: %s\n", s.Name, t.Name)
+			for _, failure := range t.Failures {
+				fmt.Fprintf(out, "  - %s\n", failure)
+			}
+		}
+	}
+
+	passed, failed := w.result.Counts()
+	_, err := fmt.Fprintf(out, "\n%d test(s) passed, %d test(s) failed\n", passed, failed)
+	return err
+}
+
+func (w *unitTestWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.result)
+}
+
+func (w *unitTestWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.result)
+}
@@ -0,0 +1,46 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestUnitTestCmd(t *testing.T) {
+	testChart := "testdata/testcharts/chart-with-unittests"
+	tests := []cmdTestCase{{
+		name:   "run the unit tests of a chart",
+		cmd:    fmt.Sprintf("unittest --kube-version 1.30.0 %s", testChart),
+		golden: "output/unittest.txt",
+	}, {
+		name:   "run the unit tests of a chart with json output",
+		cmd:    fmt.Sprintf("unittest --kube-version 1.30.0 %s -o json", testChart),
+		golden: "output/unittest.json",
+	}, {
+		name:      "run the unit tests of a chart with a failing test",
+		cmd:       fmt.Sprintf("unittest --kube-version 1.20.0 %s", testChart),
+		golden:    "output/unittest-failure.txt",
+		wantError: true,
+	}, {
+		name:      "run the unit tests of a chart without tests",
+		cmd:       "unittest testdata/testcharts/empty",
+		golden:    "output/unittest-no-suites.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
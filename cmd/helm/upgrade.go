@@ -36,8 +36,6 @@ import (
 	"helm.sh/helm/v4/pkg/cli/values"
 	"helm.sh/helm/v4/pkg/downloader"
 	"helm.sh/helm/v4/pkg/getter"
-	"helm.sh/helm/v4/pkg/release"
-	"helm.sh/helm/v4/pkg/storage/driver"
 )
 
 const upgradeDesc = `
@@ -99,7 +97,7 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			}
 			return noMoreArgsComp()
 		},
-		RunE: func(_ *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			client.Namespace = settings.Namespace()
 
 			registryClient, err := newRegistryClient(client.CertFile, client.KeyFile, client.CaFile,
@@ -115,63 +113,6 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			if client.DryRunOption == "" {
 				client.DryRunOption = "none"
 			}
-			// Fixes #7002 - Support reading values from STDIN for `upgrade` command
-			// Must load values AFTER determining if we have to call install so that values loaded from stdin are not read twice
-			if client.Install {
-				// If a release does not exist, install it.
-				histClient := action.NewHistory(cfg)
-				histClient.Max = 1
-				versions, err := histClient.Run(args[0])
-				if err == driver.ErrReleaseNotFound || isReleaseUninstalled(versions) {
-					// Only print this to stdout for table output
-					if outfmt == output.Table {
-						fmt.Fprintf(out, "Release %q does not exist. Installing it now.\n", args[0])
-					}
-					instClient := action.NewInstall(cfg)
-					instClient.CreateNamespace = createNamespace
-					instClient.ChartPathOptions = client.ChartPathOptions
-					instClient.Force = client.Force
-					instClient.DryRun = client.DryRun
-					instClient.DryRunOption = client.DryRunOption
-					instClient.DisableHooks = client.DisableHooks
-					instClient.SkipCRDs = client.SkipCRDs
-					instClient.Timeout = client.Timeout
-					instClient.Wait = client.Wait
-					instClient.WaitForJobs = client.WaitForJobs
-					instClient.Devel = client.Devel
-					instClient.Namespace = client.Namespace
-					instClient.Atomic = client.Atomic
-					instClient.PostRenderer = client.PostRenderer
-					instClient.DisableOpenAPIValidation = client.DisableOpenAPIValidation
-					instClient.SubNotes = client.SubNotes
-					instClient.HideNotes = client.HideNotes
-					instClient.SkipSchemaValidation = client.SkipSchemaValidation
-					instClient.Description = client.Description
-					instClient.DependencyUpdate = client.DependencyUpdate
-					instClient.Labels = client.Labels
-					instClient.EnableDNS = client.EnableDNS
-					instClient.HideSecret = client.HideSecret
-					instClient.TakeOwnership = client.TakeOwnership
-
-					if isReleaseUninstalled(versions) {
-						instClient.Replace = true
-					}
-
-					rel, err := runInstall(args, instClient, valueOpts, out)
-					if err != nil {
-						return err
-					}
-					return outfmt.Write(out, &statusPrinter{
-						release:      rel,
-						debug:        settings.Debug,
-						showMetadata: false,
-						hideNotes:    instClient.HideNotes,
-					})
-				} else if err != nil {
-					return err
-				}
-			}
-
 			if client.Version == "" && client.Devel {
 				debug("setting version to >0.0.0-0")
 				client.Version = ">0.0.0-0"
@@ -187,10 +128,14 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			}
 
 			p := getter.All(settings)
-			vals, err := valueOpts.MergeValues(p)
+			merged, err := valueOpts.Merge(p)
 			if err != nil {
 				return err
 			}
+			vals := merged.Values
+			client.ValuesRaw = merged.Raw
+			client.ValuesSources = merged.Sources
+			client.ValuesGetters = p
 
 			// Check chart dependencies to make sure all are present in /charts
 			ch, err := loader.Load(chartPath)
@@ -202,14 +147,17 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 					err = errors.Wrap(err, "An error occurred while checking for chart dependencies. You may need to run `helm dependency build` to fetch missing dependencies")
 					if client.DependencyUpdate {
 						man := &downloader.Manager{
-							Out:              out,
-							ChartPath:        chartPath,
-							Keyring:          client.ChartPathOptions.Keyring,
-							SkipUpdate:       false,
-							Getters:          p,
-							RepositoryConfig: settings.RepositoryConfig,
-							RepositoryCache:  settings.RepositoryCache,
-							Debug:            settings.Debug,
+							Out:                 out,
+							ChartPath:           chartPath,
+							Keyring:             client.ChartPathOptions.Keyring,
+							SkipUpdate:          false,
+							Getters:             p,
+							RepositoryConfig:    settings.RepositoryConfig,
+							RepositoryConfigDir: settings.RepositoryConfigDir,
+							RepositoryCache:     settings.RepositoryCache,
+							Cache:               action.ContentCache(settings),
+							Debug:               settings.Debug,
+							RepositoryOptions:   client.ChartPathOptions.RepositoryOptions(),
 						}
 						if err := man.Update(); err != nil {
 							return err
@@ -224,8 +172,8 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				}
 			}
 
-			if ch.Metadata.Deprecated {
-				warning("This chart is deprecated")
+			for _, w := range action.SupportWarnings(ch, time.Now()) {
+				warning("%s", w)
 			}
 
 			// Create context and prepare the handle of SIGTERM
@@ -243,9 +191,35 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				cancel()
 			}()
 
+			if client.Install {
+				instClient := &action.InstallOrUpgrade{Upgrade: client, CreateNamespace: createNamespace}
+				rel, op, err := instClient.RunWithContext(ctx, args[0], ch, vals)
+				// Only print this to stdout for table output
+				if op == action.OperationInstall && outfmt == output.Table {
+					fmt.Fprintf(out, "Release %q does not exist. Installing it now.\n", args[0])
+				}
+				if err != nil {
+					err = reportResourceConflicts(cmd.ErrOrStderr(), err)
+					if op == action.OperationInstall {
+						return err
+					}
+					return errors.Wrap(err, "UPGRADE FAILED")
+				}
+				if op == action.OperationUpgrade && outfmt == output.Table {
+					fmt.Fprintf(out, "Release %q has been upgraded. Happy Helming!\n", args[0])
+				}
+				return outfmt.Write(out, &statusPrinter{
+					release:      rel,
+					debug:        settings.Debug,
+					showMetadata: false,
+					hideNotes:    client.HideNotes,
+					effective:    instClient.EffectiveObjects,
+				})
+			}
+
 			rel, err := client.RunWithContext(ctx, args[0], ch, vals)
 			if err != nil {
-				return errors.Wrap(err, "UPGRADE FAILED")
+				return errors.Wrap(reportResourceConflicts(cmd.ErrOrStderr(), err), "UPGRADE FAILED")
 			}
 
 			if outfmt == output.Table {
@@ -257,6 +231,7 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				debug:        settings.Debug,
 				showMetadata: false,
 				hideNotes:    client.HideNotes,
+				effective:    client.EffectiveObjects,
 			})
 		},
 	}
@@ -268,13 +243,16 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.StringVar(&client.DryRunOption, "dry-run", "", "simulate an install. If --dry-run is set with no option being specified or as '--dry-run=client', it will not attempt cluster connections. Setting '--dry-run=server' allows attempting cluster connections.")
 	f.BoolVar(&client.HideSecret, "hide-secret", false, "hide Kubernetes Secrets when also using the --dry-run flag")
 	f.Lookup("dry-run").NoOptDefVal = "client"
+	f.BoolVar(&client.ComputeEffective, "show-effective", false, "show the objects the cluster would store after defaulting and mutating admission webhooks. Requires --dry-run=server")
 	f.BoolVar(&client.Recreate, "recreate-pods", false, "performs pods restart for the resource if applicable")
 	f.MarkDeprecated("recreate-pods", "functionality will no longer be updated. Consult the documentation for other methods to recreate pods")
+	f.BoolVar(&client.RestartOnConfigChange, "restart-on-config-change", false, "restart the pods of workloads whose ConfigMaps or Secrets in the release changed, by annotating their pod templates with a checksum of them")
 	f.BoolVar(&client.Force, "force", false, "force resource updates through a replacement strategy")
 	f.BoolVar(&client.DisableHooks, "no-hooks", false, "disable pre/post upgrade hooks")
 	f.BoolVar(&client.DisableOpenAPIValidation, "disable-openapi-validation", false, "if set, the upgrade process will not validate rendered templates against the Kubernetes OpenAPI Schema")
 	f.BoolVar(&client.SkipCRDs, "skip-crds", false, "if set, no CRDs will be installed when an upgrade is performed with install flag enabled. By default, CRDs are installed if not already present, when an upgrade is performed with install flag enabled")
-	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
+	addTimeoutFlag(f, &client.Timeout)
+	f.DurationVar(&client.ResourceTimeout, "resource-timeout", 0, "time to wait for any request to create or update a single resource. 0 means no limit")
 	f.BoolVar(&client.ResetValues, "reset-values", false, "when upgrading, reset the values to the ones built into the chart")
 	f.BoolVar(&client.ReuseValues, "reuse-values", false, "when upgrading, reuse the last release's values and merge in any overrides from the command line via --set and -f. If '--reset-values' is specified, this is ignored")
 	f.BoolVar(&client.ResetThenReuseValues, "reset-then-reuse-values", false, "when upgrading, reset the values to the ones built into the chart, apply the last release's values and merge in any overrides from the command line via --set and -f. If '--reset-values' or '--reuse-values' is specified, this is ignored")
@@ -282,6 +260,7 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVar(&client.Atomic, "atomic", false, "if set, upgrade process rolls back changes made in case of failed upgrade. The --wait flag will be set automatically if --atomic is used")
 	f.IntVar(&client.MaxHistory, "history-max", settings.MaxHistory, "limit the maximum number of revisions saved per release. Use 0 for no limit")
+	f.BoolVar(&client.SaveConfig, "save-config", false, "maintain the kubectl.kubernetes.io/last-applied-configuration annotation of the resources like 'kubectl apply', and use it as the original configuration of their updates")
 	f.BoolVar(&client.CleanupOnFail, "cleanup-on-fail", false, "allow deletion of new resources created in this upgrade when upgrade fails")
 	f.BoolVar(&client.SubNotes, "render-subchart-notes", false, "if set, render subchart notes along with the parent")
 	f.BoolVar(&client.HideNotes, "hide-notes", false, "if set, do not show notes in upgrade output. Does not affect presence in chart metadata")
@@ -290,11 +269,23 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.StringVar(&client.Description, "description", "", "add a custom description")
 	f.BoolVar(&client.DependencyUpdate, "dependency-update", false, "update dependencies if they are missing before installing the chart")
 	f.BoolVar(&client.EnableDNS, "enable-dns", false, "enable DNS lookups when rendering templates")
+	f.BoolVar(&client.EnableClusterConfig, "enable-cluster-config", false, "allow templates to read cluster configuration (clusterDomain, serverVersion, ingressClasses, defaultIngressClass) when rendering")
 	f.BoolVar(&client.TakeOwnership, "take-ownership", false, "if set, upgrade will ignore the check for helm annotations and take ownership of the existing resources")
+	f.BoolVar(&client.SkipRequirementChecks, "skip-requirement-checks", false, "if set, the cluster requirements declared in Chart.yaml are not checked before upgrading")
+	f.BoolVar(&client.FailOnDeprecated, "fail-on-deprecated", false, "fail instead of warning when the chart, or one of its dependencies, is deprecated or past its supportedUntil date")
+	f.BoolVar(&client.CheckPermissions, "check-permissions", false, "check that you are allowed to apply the changes and run the hooks of the upgrade before upgrading, and report all the missing permissions")
+	addValuesFromFlags(f, &client.ValuesFromOptions)
+	addImageOverridesFlags(f, &client.ImageOverrides)
+	addExclusionFlags(f, &client.Exclusions)
 	addChartPathOptionsFlags(f, &client.ChartPathOptions)
+	addStrictnessFlags(f, &client.Strictness)
+	f.BoolVar(&client.ReportAllErrors, "all-errors", false, "report the errors of all the templates that fail to render instead of stopping at the first one")
 	addValueOptionsFlags(f, valueOpts)
+	addReleaseValuesFlags(f, valueOpts)
+	bindRedactSecretsFlag(cmd, &client.Redactors)
 	bindOutputFlag(cmd, &outfmt)
 	bindPostRenderFlag(cmd, &client.PostRenderer)
+	bindManifestFormatFlag(cmd, &client.ManifestFormat)
 
 	err := cmd.RegisterFlagCompletionFunc("version", func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) != 2 {
@@ -308,7 +299,3 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 
 	return cmd
 }
-
-func isReleaseUninstalled(versions []*release.Release) bool {
-	return len(versions) > 0 && versions[len(versions)-1].Info.Status == release.StatusUninstalled
-}
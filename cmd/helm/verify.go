@@ -23,6 +23,8 @@ import (
 
 	"helm.sh/helm/v4/cmd/helm/require"
 	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/registry"
 )
 
 const verifyDesc = `
@@ -34,10 +36,30 @@ tampered with, and was packaged by a trusted provider.
 This command can be used to verify a local chart. Several other commands provide
 '--verify' flags that run the same validation. To generate a signed package, use
 the 'helm package --sign' command.
+
+A chart in an OCI registry can be verified without pulling it to disk by giving
+its reference, e.g. 'oci://registry.example.com/charts/mychart:1.2.3'. The
+digest of the chart layer of the manifest is checked as well as the provenance
+layer pushed with the chart.
+
+With '--output json' or '--output yaml', a report of the verification is
+written, even when the verification fails, e.g. for admission pipelines.
 `
 
+type verifyOptions struct {
+	certFile              string
+	keyFile               string
+	caFile                string
+	insecureSkipTLSverify bool
+	plainHTTP             bool
+	username              string
+	password              string
+	outfmt                output.Format
+}
+
 func newVerifyCmd(out io.Writer) *cobra.Command {
 	client := action.NewVerify()
+	o := &verifyOptions{}
 
 	cmd := &cobra.Command{
 		Use:   "verify PATH",
@@ -53,18 +75,58 @@ func newVerifyCmd(out io.Writer) *cobra.Command {
 			return noMoreArgsComp()
 		},
 		RunE: func(_ *cobra.Command, args []string) error {
-			err := client.Run(args[0])
-			if err != nil {
-				return err
+			if registry.IsOCI(args[0]) {
+				registryClient, err := newRegistryClient(
+					o.certFile, o.keyFile, o.caFile, o.insecureSkipTLSverify, o.plainHTTP, o.username, o.password,
+				)
+				if err != nil {
+					return fmt.Errorf("missing registry client: %w", err)
+				}
+				client.SetRegistryClient(registryClient)
 			}
 
-			fmt.Fprint(out, client.Out)
+			if o.outfmt == output.Table {
+				if err := client.Run(args[0]); err != nil {
+					return err
+				}
+				fmt.Fprint(out, client.Out)
+				return nil
+			}
 
-			return nil
+			report, err := client.Report(args[0])
+			if werr := o.outfmt.Write(out, &verifyWriter{report}); werr != nil {
+				return werr
+			}
+			return err
 		},
 	}
 
-	cmd.Flags().StringVar(&client.Keyring, "keyring", defaultKeyring(), "keyring containing public keys")
+	f := cmd.Flags()
+	f.StringVar(&client.Keyring, "keyring", defaultKeyring(), "keyring containing public keys")
+	f.StringVar(&o.certFile, "cert-file", "", "identify registry client using this SSL certificate file")
+	f.StringVar(&o.keyFile, "key-file", "", "identify registry client using this SSL key file")
+	f.StringVar(&o.caFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
+	f.BoolVar(&o.insecureSkipTLSverify, "insecure-skip-tls-verify", false, "skip tls certificate checks for the chart download")
+	f.BoolVar(&o.plainHTTP, "plain-http", false, "use insecure HTTP connections for the chart download")
+	f.StringVar(&o.username, "username", "", "chart repository username where to locate the requested chart")
+	f.StringVar(&o.password, "password", "", "chart repository password where to locate the requested chart")
+	bindOutputFlag(cmd, &o.outfmt)
 
 	return cmd
 }
+
+type verifyWriter struct {
+	report *action.VerifyReport
+}
+
+func (w *verifyWriter) WriteTable(out io.Writer) error {
+	return output.EncodeYAML(out, w.report)
+}
+
+func (w *verifyWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.report)
+}
+
+func (w *verifyWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.report)
+}
@@ -149,13 +149,13 @@ func (r *Resolver) Resolve(reqs []*chart.Dependency, repoNames map[string]string
 			} else {
 				// Retrieve list of tags for repository
 				ref := fmt.Sprintf("%s/%s", strings.TrimPrefix(d.Repository, fmt.Sprintf("%s://", registry.OCIScheme)), d.Name)
-				tags, err := r.registryClient.Tags(ref)
+				res, err := r.registryClient.Versions(ref, registry.VersionsOptions{Constraint: d.Version})
 				if err != nil {
 					return nil, errors.Wrapf(err, "could not retrieve list of tags for repository %s", d.Repository)
 				}
 
-				vs = make(repo.ChartVersions, len(tags))
-				for ti, t := range tags {
+				vs = make(repo.ChartVersions, len(res.Versions))
+				for ti, t := range res.Versions {
 					// Mock chart version objects
 					version := &repo.ChartVersion{
 						Metadata: &chart.Metadata{
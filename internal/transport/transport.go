@@ -0,0 +1,131 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package transport configures the HTTP transports used to talk to chart
+// repositories and OCI registries, so that proxy and TLS settings are applied
+// the same way everywhere.
+package transport
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/internal/tlsutil"
+)
+
+// Options are the proxy and TLS settings of a transport.
+type Options struct {
+	// Proxy is the URL of the proxy to use, with the http, https, socks5 or
+	// socks5h scheme. When empty, the proxy is taken from the HTTP_PROXY,
+	// HTTPS_PROXY and NO_PROXY environment variables.
+	Proxy string
+	// CAFile is a PEM file with the certificate authorities to trust instead
+	// of the system pool.
+	CAFile string
+	// CertFile and KeyFile are the client certificate and key.
+	CertFile string
+	KeyFile  string
+	// Certificates are additional client certificates.
+	Certificates []tls.Certificate
+	// InsecureSkipTLSVerify disables the verification of the server
+	// certificate.
+	InsecureSkipTLSVerify bool
+	// MinTLSVersion is the minimum TLS version, e.g. "1.2" or "1.3".
+	MinTLSVersion string
+	// ServerName is used to verify the server certificate when set.
+	ServerName string
+}
+
+// hasTLS reports whether the options require a custom TLS configuration.
+func (o Options) hasTLS() bool {
+	return (o.CertFile != "" && o.KeyFile != "") || o.CAFile != "" || o.InsecureSkipTLSVerify || o.MinTLSVersion != "" || len(o.Certificates) > 0
+}
+
+// Apply configures t with the options.
+func Apply(t *http.Transport, o Options) error {
+	proxy, err := ProxyFunc(o.Proxy)
+	if err != nil {
+		return err
+	}
+	t.Proxy = proxy
+
+	if !o.hasTLS() {
+		return nil
+	}
+	tlsConf, err := tlsutil.NewClientTLS(o.CertFile, o.KeyFile, o.CAFile, o.InsecureSkipTLSVerify)
+	if err != nil {
+		return errors.Wrap(err, "can't create TLS config for client")
+	}
+	if o.MinTLSVersion != "" {
+		if tlsConf.MinVersion, err = ParseTLSVersion(o.MinTLSVersion); err != nil {
+			return err
+		}
+	}
+	tlsConf.Certificates = append(tlsConf.Certificates, o.Certificates...)
+	tlsConf.ServerName = o.ServerName
+	t.TLSClientConfig = tlsConf
+	return nil
+}
+
+// New returns a transport based on http.DefaultTransport configured with the
+// options.
+func New(o Options) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if err := Apply(t, o); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// ProxyFunc returns the proxy function of a transport for the given proxy
+// URL. An empty URL uses the environment.
+func ProxyFunc(proxy string) (func(*http.Request) (*url.URL, error), error) {
+	if proxy == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid proxy URL %q", proxy)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, errors.Errorf("invalid proxy URL %q: the scheme must be one of http, https, socks5 or socks5h", proxy)
+	}
+	if u.Host == "" {
+		return nil, errors.Errorf("invalid proxy URL %q: missing host", proxy)
+	}
+	return http.ProxyURL(u), nil
+}
+
+// ParseTLSVersion converts a TLS version such as "1.2" to its tls constant.
+func ParseTLSVersion(v string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToLower(v), "tls") {
+	case "1.0", "10":
+		return tls.VersionTLS10, nil
+	case "1.1", "11":
+		return tls.VersionTLS11, nil
+	case "1.2", "12":
+		return tls.VersionTLS12, nil
+	case "1.3", "13":
+		return tls.VersionTLS13, nil
+	}
+	return 0, errors.Errorf("invalid TLS version %q: must be one of 1.0, 1.1, 1.2 or 1.3", v)
+}
@@ -37,6 +37,7 @@ import (
 	"helm.sh/helm/v4/pkg/engine"
 	"helm.sh/helm/v4/pkg/kube"
 	"helm.sh/helm/v4/pkg/postrender"
+	"helm.sh/helm/v4/pkg/redact"
 	"helm.sh/helm/v4/pkg/registry"
 	"helm.sh/helm/v4/pkg/release"
 	"helm.sh/helm/v4/pkg/releaseutil"
@@ -60,6 +61,8 @@ var (
 	errInvalidRevision = errors.New("invalid release revision")
 	// errPending indicates that another instance of Helm is already applying an operation on a release.
 	errPending = errors.New("another operation (install/upgrade/rollback) is in progress")
+	// errNameInUse indicates that an install was attempted for a release which already exists.
+	errNameInUse = errors.New("cannot reuse a name that is still in use")
 )
 
 // ValidName is a regular expression for resource names.
@@ -85,7 +88,8 @@ type Configuration struct {
 	// Releases stores records of releases.
 	Releases *storage.Storage
 
-	// KubeClient is a Kubernetes API client.
+	// KubeClient is a Kubernetes API client. A client implementing only
+	// kube.LegacyInterface can be set with kube.AdaptInterface.
 	KubeClient kube.Interface
 
 	// RegistryClient is a client for working with registries
@@ -94,7 +98,52 @@ type Configuration struct {
 	// Capabilities describes the capabilities of the Kubernetes cluster.
 	Capabilities *chartutil.Capabilities
 
+	// CapabilitiesCache, if set, shares the capabilities discovered from the
+	// cluster with the other Configurations of the cluster.
+	CapabilitiesCache *CapabilitiesCache
+
+	// CacheIncludes memoizes identical includes when rendering charts, see
+	// engine.Engine.
+	CacheIncludes bool
+	// RenderParallelism is the number of workers rendering the subcharts of
+	// a chart in parallel, see engine.Engine.
+	RenderParallelism int
+	// RenderDebug, if set, collects the debugging information of the
+	// templates when rendering charts, see engine.Engine.
+	RenderDebug *engine.RenderDebug
+	// RenderStats, if set, collects the resource usage of the templates when
+	// rendering charts, see engine.Engine.
+	RenderStats *engine.RenderStats
+
 	Log func(string, ...interface{})
+
+	// namespace is the namespace of the releases, set by Init.
+	namespace string
+}
+
+// renderOptions are the options of renderResources.
+type renderOptions struct {
+	// releaseName is the directory of the files in outputDir, with
+	// useReleaseName.
+	releaseName    string
+	outputDir      string
+	useReleaseName bool
+	// subNotes includes the notes of the subcharts.
+	subNotes    bool
+	includeCRDs bool
+	// postRenderer and images are applied to the rendered manifest.
+	postRenderer postrender.PostRenderer
+	images       *postrender.ImageOverrides
+	// interactWithRemote renders the templates against the cluster, e.g.
+	// for lookup.
+	interactWithRemote  bool
+	enableDNS           bool
+	enableClusterConfig bool
+	strictness          engine.Strictness
+	reportAllErrors     bool
+	// redactors are applied to the files written to outputDir.
+	redactors []redact.Redactor
+	format    releaseutil.ManifestFormat
 }
 
 // renderResources renders the templates in a chart
@@ -103,7 +152,7 @@ type Configuration struct {
 // TODO: As part of the refactor the duplicate code in cmd/helm/template.go should be removed
 //
 //	This code has to do with writing files to disk.
-func (cfg *Configuration) renderResources(ch *chart.Chart, values chartutil.Values, releaseName, outputDir string, subNotes, useReleaseName, includeCrds bool, pr postrender.PostRenderer, interactWithRemote, enableDNS, hideSecret bool) ([]*release.Hook, *bytes.Buffer, string, error) {
+func (cfg *Configuration) renderResources(ch *chart.Chart, values chartutil.Values, opts renderOptions) ([]*release.Hook, *bytes.Buffer, string, error) {
 	hs := []*release.Hook{}
 	b := bytes.NewBuffer(nil)
 
@@ -124,17 +173,30 @@ func (cfg *Configuration) renderResources(ch *chart.Chart, values chartutil.Valu
 	// A `helm template` should not talk to the remote cluster. However, commands with the flag
 	//`--dry-run` with the value of `false`, `none`, or `server` should try to interact with the cluster.
 	// It may break in interesting and exotic ways because other data (e.g. discovery) is mocked.
-	if interactWithRemote && cfg.RESTClientGetter != nil {
+	if opts.interactWithRemote && cfg.RESTClientGetter != nil {
 		restConfig, err := cfg.RESTClientGetter.ToRESTConfig()
 		if err != nil {
 			return hs, b, "", err
 		}
 		e := engine.New(restConfig)
-		e.EnableDNS = enableDNS
+		e.EnableDNS = opts.enableDNS
+		e.EnableClusterConfig = opts.enableClusterConfig
+		e.Strictness = opts.strictness
+		e.ReportAllErrors = opts.reportAllErrors
+		e.CacheIncludes = cfg.CacheIncludes
+		e.Parallelism = cfg.RenderParallelism
+		e.Debug = cfg.RenderDebug
+		e.Stats = cfg.RenderStats
 		files, err2 = e.Render(ch, values)
 	} else {
 		var e engine.Engine
-		e.EnableDNS = enableDNS
+		e.EnableDNS = opts.enableDNS
+		e.Strictness = opts.strictness
+		e.ReportAllErrors = opts.reportAllErrors
+		e.CacheIncludes = cfg.CacheIncludes
+		e.Parallelism = cfg.RenderParallelism
+		e.Debug = cfg.RenderDebug
+		e.Stats = cfg.RenderStats
 		files, err2 = e.Render(ch, values)
 	}
 
@@ -150,7 +212,7 @@ func (cfg *Configuration) renderResources(ch *chart.Chart, values chartutil.Valu
 	var notesBuffer bytes.Buffer
 	for k, v := range files {
 		if strings.HasSuffix(k, notesFileSuffix) {
-			if subNotes || (k == path.Join(ch.Name(), "templates", notesFileSuffix)) {
+			if opts.subNotes || (k == path.Join(ch.Name(), "templates", notesFileSuffix)) {
 				// If buffer contains data, add newline before adding more
 				if notesBuffer.Len() > 0 {
 					notesBuffer.WriteString("\n")
@@ -181,15 +243,25 @@ func (cfg *Configuration) renderResources(ch *chart.Chart, values chartutil.Valu
 		return hs, b, "", err
 	}
 
+	for _, h := range hs {
+		if h.Manifest, err = releaseutil.FormatManifest(h.Manifest, opts.format); err != nil {
+			return hs, b, "", errors.Wrapf(err, "unable to format %s", h.Path)
+		}
+	}
+
 	// Aggregate all valid manifests into one big doc.
 	fileWritten := make(map[string]bool)
 
-	if includeCrds {
+	if opts.includeCRDs {
 		for _, crd := range ch.CRDObjects() {
-			if outputDir == "" {
-				fmt.Fprintf(b, "---\n# Source: %s\n%s\n", crd.Filename, string(crd.File.Data[:]))
+			content, err := releaseutil.FormatManifest(string(crd.File.Data[:]), opts.format)
+			if err != nil {
+				return hs, b, "", errors.Wrapf(err, "unable to format %s", crd.Filename)
+			}
+			if opts.outputDir == "" {
+				fmt.Fprintf(b, "---\n# Source: %s\n%s\n", crd.Filename, content)
 			} else {
-				err = writeToFile(outputDir, crd.Filename, string(crd.File.Data[:]), fileWritten[crd.Filename])
+				err = writeToFile(opts.outputDir, crd.Filename, content, fileWritten[crd.Filename])
 				if err != nil {
 					return hs, b, "", err
 				}
@@ -199,22 +271,36 @@ func (cfg *Configuration) renderResources(ch *chart.Chart, values chartutil.Valu
 	}
 
 	for _, m := range manifests {
-		if outputDir == "" {
-			if hideSecret && m.Head.Kind == "Secret" && m.Head.Version == "v1" {
-				fmt.Fprintf(b, "---\n# Source: %s\n# HIDDEN: The Secret output has been suppressed\n", m.Name)
-			} else {
-				fmt.Fprintf(b, "---\n# Source: %s\n%s\n", m.Name, m.Content)
+		if opts.outputDir == "" {
+			content, err := releaseutil.FormatManifest(m.Content, opts.format)
+			if err != nil {
+				return hs, b, "", errors.Wrapf(err, "unable to format %s", m.Name)
 			}
+			fmt.Fprintf(b, "---\n# Source: %s\n%s\n", m.Name, content)
 		} else {
-			newDir := outputDir
-			if useReleaseName {
-				newDir = filepath.Join(outputDir, releaseName)
+			newDir := opts.outputDir
+			if opts.useReleaseName {
+				newDir = filepath.Join(opts.outputDir, opts.releaseName)
 			}
 			// NOTE: We do not have to worry about the post-renderer because
 			// output dir is only used by `helm template`. In the next major
 			// release, we should move this logic to template only as it is not
-			// used by install or upgrade
-			err = writeToFile(newDir, m.Name, m.Content, fileWritten[m.Name])
+			// used by install or upgrade. The files are not part of the
+			// release, so they are redacted here, once the images are
+			// overridden like in the manifest.
+			content, err := opts.images.Document(m.Content)
+			if err != nil {
+				return hs, b, "", errors.Wrapf(err, "unable to override images of %s", m.Name)
+			}
+			content, err = redact.Manifest(content, opts.redactors...)
+			if err != nil {
+				return hs, b, "", err
+			}
+			content, err = releaseutil.FormatManifest(content, opts.format)
+			if err != nil {
+				return hs, b, "", errors.Wrapf(err, "unable to format %s", m.Name)
+			}
+			err = writeToFile(newDir, m.Name, content, fileWritten[m.Name])
 			if err != nil {
 				return hs, b, "", err
 			}
@@ -222,16 +308,40 @@ func (cfg *Configuration) renderResources(ch *chart.Chart, values chartutil.Valu
 		}
 	}
 
-	if pr != nil {
-		b, err = pr.Run(b)
+	if opts.postRenderer != nil {
+		b, err = opts.postRenderer.Run(b)
 		if err != nil {
 			return hs, b, notes, errors.Wrap(err, "error while running post render on files")
 		}
 	}
 
+	if !opts.images.IsZero() {
+		if b, err = opts.images.Run(b); err != nil {
+			return hs, b, notes, errors.Wrap(err, "unable to override images")
+		}
+		for _, h := range hs {
+			if h.Manifest, err = opts.images.Document(h.Manifest); err != nil {
+				return hs, b, notes, errors.Wrapf(err, "unable to override images of %s", h.Path)
+			}
+		}
+	}
+
 	return hs, b, notes, nil
 }
 
+// dryRunRedactors returns the redactors to apply to the release returned by
+// a dry run, and to the files written to the output directory. The manifest
+// is only redacted once the resources are built and post-rendered, so that
+// the redactors do not change what is validated against the cluster.
+//
+// HideSecret is kept for compatibility and is equivalent to hiding Secrets.
+func dryRunRedactors(hideSecret bool, redactors []redact.Redactor) []redact.Redactor {
+	if !hideSecret {
+		return redactors
+	}
+	return append([]redact.Redactor{redact.NewSecretRedactor(redact.ModeHide)}, redactors...)
+}
+
 // RESTClientGetter gets the rest client
 type RESTClientGetter interface {
 	ToRESTConfig() (*rest.Config, error)
@@ -247,6 +357,32 @@ func (cfg *Configuration) getCapabilities() (*chartutil.Capabilities, error) {
 	if cfg.Capabilities != nil {
 		return cfg.Capabilities, nil
 	}
+	discover := cfg.discoverCapabilities
+	if cfg.CapabilitiesCache != nil {
+		discover = func() (*chartutil.Capabilities, error) {
+			return cfg.CapabilitiesCache.Get(cfg.discoverCapabilities)
+		}
+	}
+	caps, err := discover()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Capabilities = caps
+	return cfg.Capabilities, nil
+}
+
+// InvalidateCapabilities drops the capabilities of the configuration and its
+// CapabilitiesCache, so that they are discovered again on the next action.
+func (cfg *Configuration) InvalidateCapabilities() {
+	cfg.Capabilities = nil
+	if cfg.CapabilitiesCache != nil {
+		cfg.CapabilitiesCache.Invalidate()
+	}
+}
+
+// discoverCapabilities queries the cluster for its capabilities without
+// caching the result on the configuration.
+func (cfg *Configuration) discoverCapabilities() (*chartutil.Capabilities, error) {
 	dc, err := cfg.RESTClientGetter.ToDiscoveryClient()
 	if err != nil {
 		return nil, errors.Wrap(err, "could not get Kubernetes discovery client")
@@ -272,16 +408,22 @@ func (cfg *Configuration) getCapabilities() (*chartutil.Capabilities, error) {
 		}
 	}
 
-	cfg.Capabilities = &chartutil.Capabilities{
-		APIVersions: apiVersions,
-		KubeVersion: chartutil.KubeVersion{
-			Version: kubeVersion.GitVersion,
-			Major:   kubeVersion.Major,
-			Minor:   kubeVersion.Minor,
-		},
-		HelmVersion: chartutil.DefaultCapabilities.HelmVersion,
+	// The discovery documents are cached since GetVersionSet, and orphaned
+	// API services have been reported above
+	apiResources, _ := GetAPIResources(dc)
+
+	kv := chartutil.KubeVersion{
+		Version: kubeVersion.GitVersion,
+		Major:   kubeVersion.Major,
+		Minor:   kubeVersion.Minor,
 	}
-	return cfg.Capabilities, nil
+	return &chartutil.Capabilities{
+		APIVersions:  apiVersions,
+		KubeVersion:  kv,
+		HelmVersion:  chartutil.DefaultCapabilities.HelmVersion,
+		APIResources: apiResources,
+		Features:     chartutil.DetectFeatures(kv, apiResources),
+	}, nil
 }
 
 // KubernetesClientSet creates a new kubernetes ClientSet based on the configuration
@@ -302,6 +444,20 @@ func (cfg *Configuration) Now() time.Time {
 	return Timestamper()
 }
 
+// operator returns the user or service account the Kubernetes client
+// authenticates as, which is recorded with the revisions of a release. It is
+// empty when the client cannot tell.
+func (cfg *Configuration) operator() string {
+	identity, err := cfg.KubeClient.Identity()
+	if errors.Is(err, kube.ErrNotSupported) {
+		return ""
+	} else if err != nil {
+		cfg.Log("unable to determine the identity of the Kubernetes client: %s", err)
+		return ""
+	}
+	return identity
+}
+
 func (cfg *Configuration) releaseContent(name string, version int) (*release.Release, error) {
 	if err := chartutil.ValidateReleaseName(name); err != nil {
 		return nil, errors.Errorf("releaseContent: Release name is invalid: %s", name)
@@ -362,6 +518,34 @@ func GetVersionSet(client discovery.ServerResourcesInterface) (chartutil.Version
 	return chartutil.VersionSet(versions), nil
 }
 
+// GetAPIResources retrieves the resource kinds served by the cluster, without
+// their subresources. Like GetVersionSet, it returns the resources of the
+// groups that could be discovered when the discovery of other groups failed.
+func GetAPIResources(client discovery.ServerResourcesInterface) (chartutil.APIResources, error) {
+	_, lists, err := client.ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, errors.Wrap(err, "could not get API resources from Kubernetes")
+	}
+
+	var resources chartutil.APIResources
+	for _, list := range lists {
+		for _, r := range list.APIResources {
+			if strings.Contains(r.Name, "/") {
+				continue
+			}
+			resources = append(resources, chartutil.APIResource{
+				GroupVersion: list.GroupVersion,
+				Kind:         r.Kind,
+				Name:         r.Name,
+				Namespaced:   r.Namespaced,
+				Verbs:        r.Verbs,
+				ShortNames:   r.ShortNames,
+			})
+		}
+	}
+	return resources, err
+}
+
 // recordRelease with an update operation in case reuse has been set.
 func (cfg *Configuration) recordRelease(r *release.Release) {
 	if err := cfg.Releases.Update(r); err != nil {
@@ -369,7 +553,10 @@ func (cfg *Configuration) recordRelease(r *release.Release) {
 	}
 }
 
-// Init initializes the action configuration
+// Init initializes the action configuration. It does not connect to the
+// cluster nor to the storage of the releases: the connections are established
+// by the first action needing them, or explicitly by Connect, so that the
+// client-only actions, e.g. rendering templates, never touch the network.
 func (cfg *Configuration) Init(getter genericclioptions.RESTClientGetter, namespace, helmDriver string, log DebugLog) error {
 	kc := kube.New(getter)
 	kc.Log = log
@@ -405,23 +592,56 @@ func (cfg *Configuration) Init(getter genericclioptions.RESTClientGetter, namesp
 		d.SetNamespace(namespace)
 		store = storage.Init(d)
 	case "sql":
-		d, err := driver.NewSQL(
-			os.Getenv("HELM_DRIVER_SQL_CONNECTION_STRING"),
-			log,
-			namespace,
-		)
-		if err != nil {
-			return errors.Wrap(err, "unable to instantiate SQL driver")
-		}
-		store = storage.Init(d)
+		store = storage.Init(&lazyDriver{
+			name: driver.SQLDriverName,
+			driverFn: func() (driver.Driver, error) {
+				d, err := driver.NewSQL(
+					os.Getenv("HELM_DRIVER_SQL_CONNECTION_STRING"),
+					log,
+					namespace,
+				)
+				if err != nil {
+					return nil, errors.Wrap(err, "unable to instantiate SQL driver")
+				}
+				return d, nil
+			},
+		})
+	case "oci":
+		client := cfg.RegistryClient
+		store = storage.Init(&lazyDriver{
+			name: driver.OCIDriverName,
+			driverFn: func() (driver.Driver, error) {
+				if client == nil {
+					var err error
+					if client, err = registry.NewClient(); err != nil {
+						return nil, errors.Wrap(err, "unable to create the registry client of the OCI driver")
+					}
+				}
+				d, err := driver.NewOCI(client, os.Getenv("HELM_DRIVER_OCI_REPOSITORY"), namespace)
+				if err != nil {
+					return nil, errors.Wrap(err, "unable to instantiate OCI driver")
+				}
+				d.Log = log
+				return d, nil
+			},
+		})
 	default:
-		return errors.Errorf("unknown driver %q", helmDriver)
+		return &InitError{Reason: InitReasonDriver, Err: errors.Errorf("unknown driver %q", helmDriver)}
 	}
 
 	cfg.RESTClientGetter = getter
 	cfg.KubeClient = kc
 	cfg.Releases = store
 	cfg.Log = log
+	cfg.namespace = namespace
 
 	return nil
 }
+
+// InitAs initializes the action configuration like Init, with Kubernetes
+// clients impersonating the identity, e.g. to install releases on behalf of a
+// tenant. The release records are stored with the impersonated identity too,
+// which must thus be allowed to manage them in the namespace.
+func (cfg *Configuration) InitAs(getter genericclioptions.RESTClientGetter, as kube.Impersonation, namespace, helmDriver string, log DebugLog) error {
+	return cfg.Init(kube.NewImpersonatingRESTClientGetter(getter, as), namespace, helmDriver, log)
+}
@@ -22,6 +22,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	fakeclientset "k8s.io/client-go/kubernetes/fake"
 
 	"helm.sh/helm/v4/pkg/chart"
@@ -233,6 +235,12 @@ func withKube(version string) chartOption {
 	}
 }
 
+func withHelmVersion(version string) chartOption {
+	return func(opts *chartOptions) {
+		opts.Metadata.HelmVersion = version
+	}
+}
+
 // releaseStub creates a release stub, complete with the chartStub as its chart.
 func releaseStub() *release.Release {
 	return namedReleaseStub("angry-panda", release.StatusDeployed)
@@ -314,10 +322,9 @@ func TestConfiguration_Init(t *testing.T) {
 			expectedDriverType: &driver.Memory{},
 		},
 		{
-			name:       "Test sql driver",
-			helmDriver: "sql",
-			expectErr:  true,
-			errMsg:     "unable to instantiate SQL driver",
+			name:               "Test sql driver",
+			helmDriver:         "sql",
+			expectedDriverType: &lazyDriver{},
 		},
 		{
 			name:       "Test unknown driver",
@@ -358,3 +365,27 @@ func TestGetVersionSet(t *testing.T) {
 		t.Error("Non-existent version is reported found.")
 	}
 }
+
+func TestGetAPIResources(t *testing.T) {
+	client := fakeclientset.NewSimpleClientset()
+	client.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Namespaced: true, Verbs: []string{"get", "patch"}, ShortNames: []string{"deploy"}},
+				{Name: "deployments/scale", Kind: "Scale", Namespaced: true},
+			},
+		},
+	}
+
+	resources, err := GetAPIResources(client.Discovery())
+	require.NoError(t, err)
+	assert.Equal(t, chartutil.APIResources{{
+		GroupVersion: "apps/v1",
+		Kind:         "Deployment",
+		Name:         "deployments",
+		Namespaced:   true,
+		Verbs:        []string{"get", "patch"},
+		ShortNames:   []string{"deploy"},
+	}}, resources)
+}
@@ -0,0 +1,76 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+// createResources creates the resources. ctx cancels the requests to the
+// cluster. When the Kubernetes client supports it, every request for a single
+// resource is limited to the timeout, zero means no limit, and the timings of
+// the resources are logged on failure. opts are passed on to the client.
+func (cfg *Configuration) createResources(ctx context.Context, resources kube.ResourceList, timeout time.Duration, opts ...kube.ApplyOption) (*kube.Result, error) {
+	opts = append([]kube.ApplyOption{kube.ResourceTimeout(timeout)}, opts...)
+	result, err := cfg.KubeClient.CreateContext(ctx, resources, opts...)
+	if err != nil {
+		cfg.logResourceTimings(result)
+	}
+	return result, err
+}
+
+// updateResources updates the resources like createResources creates them.
+func (cfg *Configuration) updateResources(ctx context.Context, original, target kube.ResourceList, force bool, timeout time.Duration, opts ...kube.ApplyOption) (*kube.Result, error) {
+	opts = append([]kube.ApplyOption{kube.ResourceTimeout(timeout)}, opts...)
+	result, err := cfg.KubeClient.UpdateContext(ctx, original, target, force, opts...)
+	if err != nil {
+		cfg.logResourceTimings(result)
+	}
+	return result, err
+}
+
+// saveConfigOptions returns the options maintaining the last applied
+// configuration of the resources when saveConfig is set, see kube.SaveConfig.
+func saveConfigOptions(saveConfig bool) []kube.ApplyOption {
+	if !saveConfig {
+		return nil
+	}
+	return []kube.ApplyOption{kube.SaveConfig()}
+}
+
+// logResourceTimings logs the timings of the resources, slowest first.
+func (cfg *Configuration) logResourceTimings(result *kube.Result) {
+	if result == nil || len(result.Timings) == 0 {
+		return
+	}
+	timings := append([]kube.ResourceTiming(nil), result.Timings...)
+	sort.SliceStable(timings, func(i, j int) bool {
+		return timings[i].Duration > timings[j].Duration
+	})
+	cfg.Log("timings of the %d resource(s) that were applied:", len(timings))
+	for _, t := range timings {
+		status := ""
+		if t.TimedOut {
+			status = " (timed out)"
+		}
+		cfg.Log("  %s %s %q in namespace %q took %s%s", t.Operation, t.Kind, t.Name, t.Namespace, t.Duration, status)
+	}
+}
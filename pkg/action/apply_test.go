@@ -0,0 +1,74 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+)
+
+// timingKubeClient is a fake client that fails to create resources with the
+// given timings.
+type timingKubeClient struct {
+	*kubefake.FailingKubeClient
+	timings []kube.ResourceTiming
+}
+
+func (c *timingKubeClient) CreateContext(_ context.Context, _ kube.ResourceList, _ ...kube.ApplyOption) (*kube.Result, error) {
+	return &kube.Result{Timings: c.timings}, errors.New("creation failed")
+}
+
+func (c *timingKubeClient) UpdateContext(_ context.Context, _, _ kube.ResourceList, _ bool, _ ...kube.ApplyOption) (*kube.Result, error) {
+	return &kube.Result{Timings: c.timings}, nil
+}
+
+func TestCreateResourcesLogsTimings(t *testing.T) {
+	config := actionConfigFixture(t)
+	client := &timingKubeClient{
+		FailingKubeClient: config.KubeClient.(*kubefake.FailingKubeClient),
+		timings: []kube.ResourceTiming{
+			{Kind: "ConfigMap", Name: "config", Namespace: "spaced", Operation: "create", Duration: time.Second},
+			{Kind: "Service", Name: "web", Namespace: "spaced", Operation: "create", Duration: 10 * time.Second, TimedOut: true},
+		},
+	}
+	config.KubeClient = client
+	var logs []string
+	config.Log = func(format string, v ...interface{}) {
+		logs = append(logs, fmt.Sprintf(format, v...))
+	}
+
+	_, err := config.createResources(context.Background(), nil, 10*time.Second)
+	assert.EqualError(t, err, "creation failed")
+	assert.Equal(t, []string{
+		"timings of the 2 resource(s) that were applied:",
+		`  create Service "web" in namespace "spaced" took 10s (timed out)`,
+		`  create ConfigMap "config" in namespace "spaced" took 1s`,
+	}, logs)
+
+	logs = nil
+	_, err = config.updateResources(context.Background(), nil, nil, false, time.Minute)
+	assert.NoError(t, err)
+	assert.Empty(t, logs, "expected the timings to be logged only on failure")
+}
@@ -0,0 +1,475 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/loader"
+	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/downloader"
+	"helm.sh/helm/v4/pkg/engine"
+	"helm.sh/helm/v4/pkg/registry"
+	"helm.sh/helm/v4/pkg/releaseutil"
+)
+
+// BundleAPIVersion is the version of the bundle format.
+const BundleAPIVersion = "v1"
+
+// Names of the files in a bundle.
+const (
+	bundleManifestName = "bundle.yaml"
+	bundleImagesName   = "images.txt"
+	bundleChartDir     = "chart"
+)
+
+// bundleEntry is a file written to a bundle.
+type bundleEntry struct {
+	name string
+	data []byte
+}
+
+// maxBundleEntrySize bounds the size of a single file read from a bundle.
+const maxBundleEntrySize = 256 << 20
+
+// BundleManifest describes the content of a bundle.
+type BundleManifest struct {
+	APIVersion string      `json:"apiVersion"`
+	Created    string      `json:"created"`
+	Chart      BundleChart `json:"chart"`
+	// Images are the container images referenced by the chart, sorted.
+	Images []string `json:"images"`
+}
+
+// BundleChart describes the chart archive stored in a bundle.
+type BundleChart struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	AppVersion string `json:"appVersion,omitempty"`
+	// File is the path of the chart archive in the bundle.
+	File string `json:"file"`
+	// Digest is the SHA-256 of the chart archive.
+	Digest string `json:"digest"`
+	// Provenance is the path of the provenance file in the bundle, if any.
+	Provenance string `json:"provenance,omitempty"`
+	// ProvenanceDigest is the SHA-256 of the provenance file.
+	ProvenanceDigest string `json:"provenanceDigest,omitempty"`
+}
+
+// BundleExport is the action for exporting a chart to a bundle.
+//
+// It provides the implementation of 'helm bundle export'.
+type BundleExport struct {
+	// Destination is the directory the bundle is written to.
+	Destination string
+	// Images are additional images to list in the bundle, e.g. images that
+	// are pulled at runtime and do not appear in the manifests.
+	Images []string
+}
+
+// NewBundleExport creates a new BundleExport object.
+func NewBundleExport() *BundleExport {
+	return &BundleExport{}
+}
+
+// Run exports the chart at the given path, either a directory or a chart
+// archive, and returns the path to the bundle.
+//
+// The dependencies of the chart must already be present in its charts/
+// directory. The images are extracted from the manifests rendered with the
+// given values. When the chart is an archive, a provenance file next to it is
+// included in the bundle.
+func (b *BundleExport) Run(chartPath string, vals map[string]interface{}) (string, error) {
+	fi, err := os.Stat(chartPath)
+	if err != nil {
+		return "", err
+	}
+
+	ch, err := loader.Load(chartPath)
+	if err != nil {
+		return "", err
+	}
+	if reqs := ch.Metadata.Dependencies; reqs != nil {
+		if err := CheckDependencies(ch, reqs); err != nil {
+			return "", err
+		}
+	}
+
+	var archive, prov []byte
+	if fi.IsDir() {
+		tmp, err := os.MkdirTemp("", "helm-bundle-")
+		if err != nil {
+			return "", err
+		}
+		defer os.RemoveAll(tmp)
+		name, err := chartutil.Save(ch, tmp)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to package chart")
+		}
+		if archive, err = os.ReadFile(name); err != nil {
+			return "", err
+		}
+	} else {
+		if archive, err = os.ReadFile(chartPath); err != nil {
+			return "", err
+		}
+		if prov, err = os.ReadFile(chartPath + ".prov"); err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+
+	images, err := chartImages(ch, vals)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to extract the images of the chart")
+	}
+	images = uniqueSorted(append(images, b.Images...))
+
+	base := fmt.Sprintf("%s-%s.tgz", ch.Name(), ch.Metadata.Version)
+	manifest := BundleManifest{
+		APIVersion: BundleAPIVersion,
+		Created:    time.Now().UTC().Format(time.RFC3339),
+		Chart: BundleChart{
+			Name:       ch.Name(),
+			Version:    ch.Metadata.Version,
+			AppVersion: ch.Metadata.AppVersion,
+			File:       path.Join(bundleChartDir, base),
+			Digest:     sha256Digest(archive),
+		},
+		Images: images,
+	}
+	if prov != nil {
+		manifest.Chart.Provenance = manifest.Chart.File + ".prov"
+		manifest.Chart.ProvenanceDigest = sha256Digest(prov)
+	}
+	manifestData, err := yaml.Marshal(manifest)
+	if err != nil {
+		return "", err
+	}
+
+	dest := b.Destination
+	if dest == "" {
+		dest = "."
+	}
+	filename := filepath.Join(dest, fmt.Sprintf("%s-%s-bundle.tgz", ch.Name(), ch.Metadata.Version))
+	f, err := os.Create(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	zw := gzip.NewWriter(f)
+	tw := tar.NewWriter(zw)
+	files := []bundleEntry{
+		{bundleManifestName, manifestData},
+		{manifest.Chart.File, archive},
+		{bundleImagesName, []byte(strings.Join(images, "\n") + "\n")},
+	}
+	if prov != nil {
+		files = append(files, bundleEntry{manifest.Chart.Provenance, prov})
+	}
+	for _, file := range files {
+		if err := writeTarFile(tw, file.name, file.data); err != nil {
+			return "", err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return filename, f.Close()
+}
+
+// BundleImport is the action for importing a bundle.
+//
+// It provides the implementation of 'helm bundle import'.
+type BundleImport struct {
+	cfg *Configuration
+
+	// Verify checks the provenance of the chart before importing it.
+	Verify bool
+	// Keyring is the keyring used to verify the provenance.
+	Keyring string
+}
+
+// BundleImportResult describes an imported bundle.
+type BundleImportResult struct {
+	Manifest *BundleManifest
+	// Ref is the reference of the chart in the registry, or its path when the
+	// chart was imported to a directory.
+	Ref string
+}
+
+// NewBundleImport creates a new BundleImport object with the given configuration.
+func NewBundleImport(cfg *Configuration) *BundleImport {
+	return &BundleImport{cfg: cfg}
+}
+
+// Run imports the bundle to the destination, either an OCI registry
+// (oci://host/path) or a local directory such as the repository cache.
+//
+// The digests of the bundle are checked before anything is imported.
+func (b *BundleImport) Run(bundlePath, dest string) (*BundleImportResult, error) {
+	manifest, files, err := LoadBundle(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	archive := files[manifest.Chart.File]
+	prov := files[manifest.Chart.Provenance]
+
+	if b.Verify {
+		if prov == nil {
+			return nil, errors.Errorf("bundle %s does not contain a provenance file", bundlePath)
+		}
+		if err := verifyBundleChart(path.Base(manifest.Chart.File), archive, prov, b.Keyring); err != nil {
+			return nil, err
+		}
+	}
+
+	res := &BundleImportResult{Manifest: manifest}
+
+	if registry.IsOCI(dest) {
+		if b.cfg == nil || b.cfg.RegistryClient == nil {
+			return nil, errors.New("a registry client is required to import a bundle to a registry")
+		}
+		ref := fmt.Sprintf("%s:%s",
+			path.Join(strings.TrimPrefix(dest, fmt.Sprintf("%s://", registry.OCIScheme)), manifest.Chart.Name),
+			manifest.Chart.Version)
+		var opts []registry.PushOption
+		if prov != nil {
+			opts = append(opts, registry.PushOptProvData(prov))
+		}
+		if manifest.Created != "" {
+			opts = append(opts, registry.PushOptCreationTime(manifest.Created))
+		}
+		pushed, err := b.cfg.RegistryClient.Push(archive, ref, opts...)
+		if err != nil {
+			return nil, err
+		}
+		res.Ref = pushed.Ref
+		return res, nil
+	}
+
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return nil, err
+	}
+	name := filepath.Join(dest, path.Base(manifest.Chart.File))
+	if err := os.WriteFile(name, archive, 0644); err != nil {
+		return nil, err
+	}
+	if prov != nil {
+		if err := os.WriteFile(name+".prov", prov, 0644); err != nil {
+			return nil, err
+		}
+	}
+	res.Ref = name
+	return res, nil
+}
+
+// LoadBundle reads a bundle and checks its digests. It returns the manifest
+// and the content of the files of the bundle, keyed by path.
+func LoadBundle(bundlePath string) (*BundleManifest, map[string][]byte, error) {
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "%s is not a valid bundle", bundlePath)
+	}
+	defer zr.Close()
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "%s is not a valid bundle", bundlePath)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if hdr.Size > maxBundleEntrySize {
+			return nil, nil, errors.Errorf("file %s in bundle %s exceeds the maximum size of %d bytes", hdr.Name, bundlePath, maxBundleEntrySize)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxBundleEntrySize))
+		if err != nil {
+			return nil, nil, err
+		}
+		files[path.Clean(hdr.Name)] = data
+	}
+
+	data, ok := files[bundleManifestName]
+	if !ok {
+		return nil, nil, errors.Errorf("bundle %s has no %s", bundlePath, bundleManifestName)
+	}
+	manifest := &BundleManifest{}
+	if err := yaml.UnmarshalStrict(data, manifest); err != nil {
+		return nil, nil, errors.Wrapf(err, "invalid %s in bundle %s", bundleManifestName, bundlePath)
+	}
+	if manifest.APIVersion != BundleAPIVersion {
+		return nil, nil, errors.Errorf("bundle %s has unsupported apiVersion %q", bundlePath, manifest.APIVersion)
+	}
+
+	archive, ok := files[manifest.Chart.File]
+	if !ok {
+		return nil, nil, errors.Errorf("bundle %s does not contain the chart %s", bundlePath, manifest.Chart.File)
+	}
+	if sha256Digest(archive) != manifest.Chart.Digest {
+		return nil, nil, errors.Errorf("digest mismatch for %s in bundle %s", manifest.Chart.File, bundlePath)
+	}
+	if manifest.Chart.Provenance != "" {
+		prov, ok := files[manifest.Chart.Provenance]
+		if !ok {
+			return nil, nil, errors.Errorf("bundle %s does not contain the provenance file %s", bundlePath, manifest.Chart.Provenance)
+		}
+		if sha256Digest(prov) != manifest.Chart.ProvenanceDigest {
+			return nil, nil, errors.Errorf("digest mismatch for %s in bundle %s", manifest.Chart.Provenance, bundlePath)
+		}
+	}
+	return manifest, files, nil
+}
+
+// verifyBundleChart verifies the provenance of a chart archive read from a
+// bundle.
+func verifyBundleChart(name string, archive, prov []byte, keyring string) error {
+	tmp, err := os.MkdirTemp("", "helm-bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	chartFile := filepath.Join(tmp, name)
+	if err := os.WriteFile(chartFile, archive, 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(chartFile+".prov", prov, 0644); err != nil {
+		return err
+	}
+	_, err = downloader.VerifyChart(chartFile, keyring)
+	return err
+}
+
+// chartImages renders the chart with the given values and returns the images
+// referenced by the rendered manifests.
+func chartImages(ch *chart.Chart, vals map[string]interface{}) ([]string, error) {
+	if err := chartutil.ProcessDependencies(ch, vals); err != nil {
+		return nil, err
+	}
+	options := chartutil.ReleaseOptions{
+		Name:      "bundle",
+		Namespace: "default",
+		IsInstall: true,
+	}
+	values, err := chartutil.ToRenderValues(ch, vals, options, chartutil.DefaultCapabilities.Copy())
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := engine.Render(ch, values)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []string
+	for name, content := range rendered {
+		if !strings.HasSuffix(name, ".yaml") && !strings.HasSuffix(name, ".yml") {
+			continue
+		}
+		for _, doc := range releaseutil.SplitManifests(content) {
+			var obj interface{}
+			if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+				continue
+			}
+			images = collectImages(obj, images)
+		}
+	}
+	return images, nil
+}
+
+// collectImages appends the string values of the "image" keys found in obj.
+func collectImages(obj interface{}, images []string) []string {
+	switch v := obj.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			if s, ok := val.(string); ok && k == "image" && strings.TrimSpace(s) != "" {
+				images = append(images, strings.TrimSpace(s))
+				continue
+			}
+			images = collectImages(val, images)
+		}
+	case []interface{}:
+		for _, val := range v {
+			images = collectImages(val, images)
+		}
+	}
+	return images
+}
+
+func uniqueSorted(in []string) []string {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, s := range in {
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		out = append(out, s)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func sha256Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, bytes.NewReader(data))
+	return err
+}
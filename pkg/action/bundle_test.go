@@ -0,0 +1,128 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/loader"
+)
+
+func TestBundleExportImport(t *testing.T) {
+	dir := t.TempDir()
+
+	export := NewBundleExport()
+	export.Destination = dir
+	export.Images = []string{"busybox:1.36"}
+	vals := map[string]interface{}{
+		"mariadb": map[string]interface{}{
+			"metrics": map[string]interface{}{"enabled": true},
+		},
+	}
+	bundle, err := export.Run("testdata/charts/chart-with-uncompressed-dependencies", vals)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "chart-with-uncompressed-dependencies-2.1.8-bundle.tgz"), bundle)
+
+	manifest, files, err := LoadBundle(bundle)
+	require.NoError(t, err)
+	assert.Equal(t, "chart-with-uncompressed-dependencies", manifest.Chart.Name)
+	assert.Equal(t, "2.1.8", manifest.Chart.Version)
+	assert.Equal(t, "chart/chart-with-uncompressed-dependencies-2.1.8.tgz", manifest.Chart.File)
+	assert.Empty(t, manifest.Chart.Provenance)
+	assert.Equal(t, []string{
+		"busybox:1.36",
+		"dduportal/bats:0.4.0",
+		"docker.io/bitnami/mariadb:10.1.34-debian-9",
+		"docker.io/prom/mysqld-exporter:v0.10.0",
+	}, manifest.Images)
+	assert.Equal(t, "busybox:1.36\ndduportal/bats:0.4.0\ndocker.io/bitnami/mariadb:10.1.34-debian-9\ndocker.io/prom/mysqld-exporter:v0.10.0\n", string(files["images.txt"]))
+
+	imported := filepath.Join(dir, "cache")
+	res, err := NewBundleImport(nil).Run(bundle, imported)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(imported, "chart-with-uncompressed-dependencies-2.1.8.tgz"), res.Ref)
+
+	ch, err := loader.Load(res.Ref)
+	require.NoError(t, err)
+	assert.Len(t, ch.Dependencies(), 1, "expected the dependencies to be bundled")
+}
+
+func TestBundleImportVerify(t *testing.T) {
+	dir := t.TempDir()
+
+	export := NewBundleExport()
+	export.Destination = dir
+	bundle, err := export.Run("../downloader/testdata/signtest-0.1.0.tgz", nil)
+	require.NoError(t, err)
+
+	manifest, _, err := LoadBundle(bundle)
+	require.NoError(t, err)
+	assert.Equal(t, "chart/signtest-0.1.0.tgz.prov", manifest.Chart.Provenance)
+
+	imp := NewBundleImport(nil)
+	imp.Verify = true
+	imp.Keyring = "../downloader/testdata/helm-test-key.pub"
+	res, err := imp.Run(bundle, filepath.Join(dir, "cache"))
+	require.NoError(t, err)
+	assert.FileExists(t, res.Ref+".prov")
+
+	// A bundle without a provenance file cannot be verified.
+	unsigned, err := export.Run("testdata/charts/compressedchart-0.1.0.tgz", nil)
+	require.NoError(t, err)
+	_, err = imp.Run(unsigned, filepath.Join(dir, "cache"))
+	assert.ErrorContains(t, err, "does not contain a provenance file")
+}
+
+func TestLoadBundleDigestMismatch(t *testing.T) {
+	dir := t.TempDir()
+
+	export := NewBundleExport()
+	export.Destination = dir
+	bundle, err := export.Run("testdata/charts/compressedchart-0.1.0.tgz", nil)
+	require.NoError(t, err)
+
+	manifest, files, err := LoadBundle(bundle)
+	require.NoError(t, err)
+
+	// Rewrite the bundle with a tampered chart archive.
+	files[manifest.Chart.File] = append(files[manifest.Chart.File], 0)
+	tampered := filepath.Join(dir, "tampered.tgz")
+	f, err := os.Create(tampered)
+	require.NoError(t, err)
+	zw := gzip.NewWriter(f)
+	tw := tar.NewWriter(zw)
+	for name, data := range files {
+		require.NoError(t, writeTarFile(tw, name, data))
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+
+	_, _, err = LoadBundle(tampered)
+	assert.ErrorContains(t, err, "digest mismatch")
+
+	_, err = NewBundleImport(nil).Run(tampered, filepath.Join(dir, "cache"))
+	assert.ErrorContains(t, err, "digest mismatch")
+	assert.NoDirExists(t, filepath.Join(dir, "cache"))
+}
@@ -0,0 +1,96 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/downloader"
+)
+
+// ContentCache returns the content cache configured by the settings, which
+// is shared by chart pulls, dependency builds and plugin downloads. It returns
+// nil when the settings do not configure a content cache.
+func ContentCache(settings *cli.EnvSettings) downloader.Cache {
+	if settings == nil || settings.ContentCache == "" {
+		return nil
+	}
+	return newDiskCache(settings)
+}
+
+func newDiskCache(settings *cli.EnvSettings) *downloader.DiskCache {
+	return &downloader.DiskCache{
+		Root:    settings.ContentCache,
+		MaxSize: settings.ContentCacheMaxSize,
+	}
+}
+
+// CacheList is the action for listing the content cache.
+//
+// It provides the implementation of 'helm cache list'.
+type CacheList struct {
+	Settings *cli.EnvSettings
+}
+
+// NewCacheList creates a new CacheList object with the given settings.
+func NewCacheList(settings *cli.EnvSettings) *CacheList {
+	return &CacheList{Settings: settings}
+}
+
+// Run returns the content of the cache, most recently used first.
+func (c *CacheList) Run() ([]downloader.CacheEntry, error) {
+	return newDiskCache(c.Settings).Entries()
+}
+
+// CachePrune is the action for pruning the content cache.
+//
+// It provides the implementation of 'helm cache prune'.
+type CachePrune struct {
+	Settings *cli.EnvSettings
+	// MaxSize is the size in bytes to which the least recently used content
+	// is evicted. Zero only removes the corrupted content.
+	MaxSize int64
+	// All removes all the content.
+	All bool
+}
+
+// NewCachePrune creates a new CachePrune object evicting the content to the
+// maximum size of the settings.
+func NewCachePrune(settings *cli.EnvSettings) *CachePrune {
+	return &CachePrune{
+		Settings: settings,
+		MaxSize:  settings.ContentCacheMaxSize,
+	}
+}
+
+// Run removes the content not matching its digest, then evicts the least
+// recently used content, and returns the removed entries.
+func (c *CachePrune) Run() ([]downloader.CacheEntry, error) {
+	cache := newDiskCache(c.Settings)
+	removed, err := cache.Verify()
+	if err != nil {
+		return removed, err
+	}
+
+	maxSize := c.MaxSize
+	if c.All {
+		maxSize = 0
+	} else if maxSize <= 0 {
+		return removed, nil
+	}
+	evicted, err := cache.Prune(maxSize)
+	return append(removed, evicted...), err
+}
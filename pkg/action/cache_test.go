@@ -0,0 +1,72 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"crypto/sha256"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/downloader"
+)
+
+func TestContentCache(t *testing.T) {
+	assert.Nil(t, ContentCache(nil))
+	assert.Nil(t, ContentCache(&cli.EnvSettings{}))
+	assert.NotNil(t, ContentCache(&cli.EnvSettings{ContentCache: t.TempDir()}))
+}
+
+func TestCachePrune(t *testing.T) {
+	settings := &cli.EnvSettings{ContentCache: t.TempDir()}
+	cache := ContentCache(settings)
+	for _, content := range []string{"first", "second", "third"} {
+		_, err := cache.Put(sha256.Sum256([]byte(content)), []byte(content), downloader.CacheChart)
+		require.NoError(t, err)
+	}
+	corrupted, err := cache.Get(sha256.Sum256([]byte("first")), downloader.CacheChart)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(corrupted, []byte("tampered"), 0644))
+
+	entries, err := NewCacheList(settings).Run()
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+
+	// Without a maximum size, only the corrupted content is removed.
+	removed, err := NewCachePrune(settings).Run()
+	require.NoError(t, err)
+	require.Len(t, removed, 1)
+	assert.Equal(t, corrupted, removed[0].Path)
+
+	prune := NewCachePrune(settings)
+	prune.MaxSize = 6
+	removed, err = prune.Run()
+	require.NoError(t, err)
+	assert.Len(t, removed, 1)
+
+	prune.All = true
+	removed, err = prune.Run()
+	require.NoError(t, err)
+	assert.Len(t, removed, 1)
+
+	entries, err = NewCacheList(settings).Run()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
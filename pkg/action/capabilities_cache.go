@@ -0,0 +1,76 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"sync"
+	"time"
+
+	"helm.sh/helm/v4/pkg/chartutil"
+)
+
+// DefaultCapabilitiesTTL is the time for which a CapabilitiesCache created by
+// a ConfigurationPool keeps the discovered capabilities.
+const DefaultCapabilitiesTTL = 5 * time.Minute
+
+// CapabilitiesCache keeps the capabilities discovered from a cluster for a
+// limited time, so that the Configurations sharing it do not query the
+// discovery API of the cluster for every action.
+//
+// A cache must only be shared by Configurations of the same cluster. It is
+// safe for concurrent use.
+type CapabilitiesCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mtx     sync.Mutex
+	caps    *chartutil.Capabilities
+	expires time.Time
+}
+
+// NewCapabilitiesCache creates an empty cache that keeps the capabilities for
+// the TTL. A TTL of zero or less keeps them until the cache is invalidated.
+func NewCapabilitiesCache(ttl time.Duration) *CapabilitiesCache {
+	return &CapabilitiesCache{
+		ttl: ttl,
+		now: time.Now,
+	}
+}
+
+// Get returns a copy of the cached capabilities. When the cache is empty or
+// has expired, the capabilities are discovered and cached first.
+func (c *CapabilitiesCache) Get(discover func() (*chartutil.Capabilities, error)) (*chartutil.Capabilities, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.caps == nil || (c.ttl > 0 && !c.now().Before(c.expires)) {
+		caps, err := discover()
+		if err != nil {
+			return nil, err
+		}
+		c.caps = caps
+		c.expires = c.now().Add(c.ttl)
+	}
+	return c.caps.Copy(), nil
+}
+
+// Invalidate empties the cache, so that the capabilities are discovered again
+// on the next Get, e.g. after CRDs have been installed.
+func (c *CapabilitiesCache) Invalidate() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.caps = nil
+}
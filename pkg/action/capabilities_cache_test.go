@@ -0,0 +1,89 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chartutil"
+)
+
+func TestCapabilitiesCache(t *testing.T) {
+	now := time.Now()
+	cache := NewCapabilitiesCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	discoveries := 0
+	discover := func() (*chartutil.Capabilities, error) {
+		discoveries++
+		return chartutil.DefaultCapabilities.Copy(), nil
+	}
+
+	caps, err := cache.Get(discover)
+	require.NoError(t, err)
+	assert.Equal(t, chartutil.DefaultCapabilities.KubeVersion, caps.KubeVersion)
+	caps.KubeVersion.Version = "v0.0.1"
+
+	now = now.Add(30 * time.Second)
+	caps, err = cache.Get(discover)
+	require.NoError(t, err)
+	assert.Equal(t, 1, discoveries, "expected the capabilities to be cached")
+	assert.Equal(t, chartutil.DefaultCapabilities.KubeVersion, caps.KubeVersion, "expected a copy of the capabilities")
+
+	now = now.Add(time.Minute)
+	_, err = cache.Get(discover)
+	require.NoError(t, err)
+	assert.Equal(t, 2, discoveries, "expected the capabilities to expire")
+
+	cache.Invalidate()
+	_, err = cache.Get(discover)
+	require.NoError(t, err)
+	assert.Equal(t, 3, discoveries, "expected the capabilities to be invalidated")
+
+	cache.Invalidate()
+	_, err = cache.Get(func() (*chartutil.Capabilities, error) { return nil, errors.New("unreachable") })
+	assert.EqualError(t, err, "unreachable")
+	_, err = cache.Get(discover)
+	require.NoError(t, err)
+	assert.Equal(t, 4, discoveries, "expected errors not to be cached")
+}
+
+func TestConfigurationCapabilitiesCache(t *testing.T) {
+	cache := NewCapabilitiesCache(0)
+	_, err := cache.Get(func() (*chartutil.Capabilities, error) {
+		caps := chartutil.DefaultCapabilities.Copy()
+		caps.KubeVersion.Version = "v1.30.0"
+		return caps, nil
+	})
+	require.NoError(t, err)
+
+	// The cached capabilities are used without discovering them, which
+	// would fail without a RESTClientGetter
+	cfg := &Configuration{CapabilitiesCache: cache}
+	caps, err := cfg.getCapabilities()
+	require.NoError(t, err)
+	assert.Equal(t, "v1.30.0", caps.KubeVersion.Version)
+
+	cfg.InvalidateCapabilities()
+	assert.Nil(t, cfg.Capabilities)
+	assert.Nil(t, cache.caps, "expected the cache to be invalidated")
+}
@@ -0,0 +1,111 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// InitErrorReason tells why the action configuration could not be initialized
+// or connected.
+type InitErrorReason string
+
+const (
+	// InitReasonKubeConfig is the reason of the errors loading the kubeconfig,
+	// e.g. a missing file or an unknown context.
+	InitReasonKubeConfig InitErrorReason = "KubeConfig"
+	// InitReasonUnauthorized is the reason of the errors of the credentials
+	// rejected by the cluster, and of the permissions to store the releases
+	// denied by RBAC.
+	InitReasonUnauthorized InitErrorReason = "Unauthorized"
+	// InitReasonUnreachable is the reason of the errors reaching the cluster,
+	// e.g. a refused connection or a timeout.
+	InitReasonUnreachable InitErrorReason = "Unreachable"
+	// InitReasonDriver is the reason of the errors of the storage driver, e.g.
+	// an unknown driver or an unreachable database.
+	InitReasonDriver InitErrorReason = "Driver"
+)
+
+// InitError is returned by Init and Connect when the action configuration
+// cannot be initialized or connected. Use errors.As to tell the reason.
+type InitError struct {
+	Reason InitErrorReason
+	Err    error
+}
+
+func (e *InitError) Error() string {
+	switch e.Reason {
+	case InitReasonKubeConfig:
+		return fmt.Sprintf("invalid kubeconfig: %s", e.Err)
+	case InitReasonUnauthorized:
+		return fmt.Sprintf("access to the Kubernetes cluster denied: %s", e.Err)
+	case InitReasonUnreachable:
+		return fmt.Sprintf("Kubernetes cluster unreachable: %s", e.Err)
+	default:
+		return e.Err.Error()
+	}
+}
+
+func (e *InitError) Unwrap() error { return e.Err }
+
+// kubeInitError returns the error of a request to the cluster as an
+// *InitError, telling a rejection of the cluster from a network error.
+func kubeInitError(err error) error {
+	if apierrors.IsUnauthorized(err) || apierrors.IsForbidden(err) {
+		return &InitError{Reason: InitReasonUnauthorized, Err: err}
+	}
+	return &InitError{Reason: InitReasonUnreachable, Err: err}
+}
+
+// Connect establishes the connections that Init defers to the first action
+// needing them: it loads the kubeconfig, checks that the cluster is reachable
+// and that the user may store releases in the namespace, and connects the
+// storage driver. The actions call it implicitly; call it explicitly to fail
+// early, with an *InitError telling why.
+func (cfg *Configuration) Connect(ctx context.Context) error {
+	if cfg.RESTClientGetter == nil {
+		return &InitError{Reason: InitReasonKubeConfig, Err: errors.New("no Kubernetes configuration")}
+	}
+	client, err := cfg.KubernetesClientSet()
+	if err != nil {
+		return &InitError{Reason: InitReasonKubeConfig, Err: err}
+	}
+	if _, err := client.Discovery().ServerVersion(); err != nil {
+		return kubeInitError(err)
+	}
+
+	if cfg.Releases == nil {
+		return nil
+	}
+	perms := permissionSet{}
+	cfg.addStoragePermissions(perms, cfg.namespace)
+	missing, err := missingPermissions(ctx, client, perms.sorted())
+	if err != nil {
+		return kubeInitError(err)
+	}
+	if len(missing) > 0 {
+		return &InitError{Reason: InitReasonUnauthorized, Err: &MissingPermissionsError{Missing: missing}}
+	}
+	if d, ok := cfg.Releases.Driver.(*lazyDriver); ok {
+		return d.init()
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+
+	"helm.sh/helm/v4/pkg/storage"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// restConfigGetter is a RESTClientGetter of a fixed configuration.
+type restConfigGetter struct {
+	config *rest.Config
+	err    error
+}
+
+func (g *restConfigGetter) ToRESTConfig() (*rest.Config, error) { return g.config, g.err }
+
+func (g *restConfigGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (g *restConfigGetter) ToRESTMapper() (meta.RESTMapper, error) {
+	return nil, errors.New("not implemented")
+}
+
+// clusterServer serves the version of the cluster with the status, and the
+// access reviews with the verdict.
+func clusterServer(t *testing.T, status int, allowed bool) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/version":
+			w.WriteHeader(status)
+			if status == http.StatusOK {
+				w.Write([]byte(`{"major": "1", "minor": "30", "gitVersion": "v1.30.0"}`))
+			} else {
+				w.Write([]byte(`{"kind": "Status", "apiVersion": "v1", "status": "Failure", "reason": "Unauthorized", "code": 401}`))
+			}
+		case strings.HasSuffix(r.URL.Path, "/selfsubjectaccessreviews"):
+			w.WriteHeader(http.StatusCreated)
+			if allowed {
+				w.Write([]byte(`{"status": {"allowed": true}}`))
+			} else {
+				w.Write([]byte(`{"status": {"allowed": false}}`))
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func connectConfig(getter RESTClientGetter, d driver.Driver) *Configuration {
+	return &Configuration{RESTClientGetter: getter, Releases: storage.Init(d), namespace: "shop"}
+}
+
+func assertInitError(t *testing.T, err error, reason InitErrorReason) {
+	t.Helper()
+	var initErr *InitError
+	require.True(t, errors.As(err, &initErr), "expected an *InitError, got %v", err)
+	assert.Equal(t, reason, initErr.Reason)
+}
+
+func TestConnect(t *testing.T) {
+	srv := clusterServer(t, http.StatusOK, true)
+	cfg := connectConfig(&restConfigGetter{config: &rest.Config{Host: srv.URL}}, driver.NewSecrets(nil))
+	assert.NoError(t, cfg.Connect(context.Background()))
+}
+
+func TestConnectKubeConfig(t *testing.T) {
+	cfg := connectConfig(&restConfigGetter{err: errors.New(`context "missing" does not exist`)}, driver.NewMemory())
+	err := cfg.Connect(context.Background())
+	assertInitError(t, err, InitReasonKubeConfig)
+	assert.Contains(t, err.Error(), `invalid kubeconfig: unable to generate config for kubernetes client: context "missing" does not exist`)
+
+	assertInitError(t, (&Configuration{}).Connect(context.Background()), InitReasonKubeConfig)
+}
+
+func TestConnectUnauthorized(t *testing.T) {
+	srv := clusterServer(t, http.StatusUnauthorized, true)
+	cfg := connectConfig(&restConfigGetter{config: &rest.Config{Host: srv.URL}}, driver.NewSecrets(nil))
+	assertInitError(t, cfg.Connect(context.Background()), InitReasonUnauthorized)
+
+	srv = clusterServer(t, http.StatusOK, false)
+	cfg = connectConfig(&restConfigGetter{config: &rest.Config{Host: srv.URL}}, driver.NewSecrets(nil))
+	err := cfg.Connect(context.Background())
+	assertInitError(t, err, InitReasonUnauthorized)
+	var missing *MissingPermissionsError
+	require.True(t, errors.As(err, &missing))
+	assert.Len(t, missing.Missing, 3, "list, create and update secrets")
+
+	// The memory driver needs no permissions.
+	cfg = connectConfig(&restConfigGetter{config: &rest.Config{Host: srv.URL}}, driver.NewMemory())
+	assert.NoError(t, cfg.Connect(context.Background()))
+}
+
+func TestConnectUnreachable(t *testing.T) {
+	srv := clusterServer(t, http.StatusOK, true)
+	srv.Close()
+	cfg := connectConfig(&restConfigGetter{config: &rest.Config{Host: srv.URL}}, driver.NewMemory())
+	err := cfg.Connect(context.Background())
+	assertInitError(t, err, InitReasonUnreachable)
+	assert.True(t, strings.HasPrefix(err.Error(), "Kubernetes cluster unreachable: "), err.Error())
+}
+
+func TestInitLazyDriver(t *testing.T) {
+	t.Setenv("HELM_DRIVER_SQL_CONNECTION_STRING", "host=127.0.0.1 port=1 connect_timeout=1 sslmode=disable")
+	cfg := &Configuration{}
+	require.NoError(t, cfg.Init(nil, "default", "sql", nil), "Init must not connect to the database")
+	assert.Equal(t, driver.SQLDriverName, cfg.Releases.Name())
+
+	_, err := cfg.Releases.Get("release", 1)
+	assertInitError(t, err, InitReasonDriver)
+	assert.Contains(t, err.Error(), "unable to instantiate SQL driver")
+
+	srv := clusterServer(t, http.StatusOK, true)
+	cfg.RESTClientGetter = &restConfigGetter{config: &rest.Config{Host: srv.URL}}
+	assertInitError(t, cfg.Connect(context.Background()), InitReasonDriver)
+
+	err = (&Configuration{}).Init(nil, "default", "someDriver", nil)
+	assertInitError(t, err, InitReasonDriver)
+}
@@ -19,15 +19,19 @@ package action
 import (
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/gosuri/uitable"
+	"github.com/pkg/errors"
 
 	"helm.sh/helm/v4/pkg/chart"
 	"helm.sh/helm/v4/pkg/chart/loader"
+	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/getter"
 )
 
 // Dependency is the action for building a given chart's dependency tree.
@@ -45,6 +49,15 @@ type Dependency struct {
 	CaFile                string
 	InsecureSkipTLSverify bool
 	PlainHTTP             bool
+	// RepoURL is the URL of the chart repository the headers and the bearer
+	// token are sent to.
+	RepoURL string
+	// Headers are sent with the HTTP requests for the index file and the
+	// dependencies of the repository of RepoURL.
+	Headers http.Header
+	// BearerToken authorizes the HTTP requests to the repository of RepoURL,
+	// see ChartPathOptions.BearerToken.
+	BearerToken string
 }
 
 // NewDependency creates a new Dependency object with the given configuration.
@@ -54,6 +67,22 @@ func NewDependency() *Dependency {
 	}
 }
 
+// RepositoryOptions returns the headers and the bearer token of the action as
+// the downloader.Manager RepositoryOptions of RepoURL. It fails when they are
+// set without RepoURL.
+func (d *Dependency) RepositoryOptions() (map[string][]getter.Option, error) {
+	if len(d.Headers) == 0 && d.BearerToken == "" {
+		return nil, nil
+	}
+	if d.RepoURL == "" {
+		return nil, errors.New("the URL of the chart repository is required to send headers or a bearer token")
+	}
+	return map[string][]getter.Option{d.RepoURL: {
+		getter.WithHeaders(d.Headers),
+		getter.WithBearerToken(d.BearerToken),
+	}}, nil
+}
+
 // List executes 'helm dependency list'.
 func (d *Dependency) List(chartpath string, out io.Writer) error {
 	c, err := loader.Load(chartpath)
@@ -72,6 +101,17 @@ func (d *Dependency) List(chartpath string, out io.Writer) error {
 	return nil
 }
 
+// Graph builds the dependency graph of the chart at chartpath, evaluating the
+// conditions of its dependencies against the values, for
+// 'helm dependency graph'.
+func (d *Dependency) Graph(chartpath string, vals map[string]interface{}) (*chartutil.DependencyNode, error) {
+	c, err := loader.Load(chartpath)
+	if err != nil {
+		return nil, err
+	}
+	return chartutil.BuildDependencyGraph(c, vals)
+}
+
 // dependencyStatus returns a string describing the status of a dependency viz a viz the parent chart.
 func (d *Dependency) dependencyStatus(chartpath string, dep *chart.Dependency, parent *chart.Chart) string {
 	filename := fmt.Sprintf("%s-%s.tgz", dep.Name, "*")
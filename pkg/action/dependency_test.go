@@ -150,3 +150,21 @@ func TestStatArchiveForStatus(t *testing.T) {
 	}
 	is.Equal("ok", statArchiveForStatus(where, dep))
 }
+
+func TestDependencyRepositoryOptions(t *testing.T) {
+	is := assert.New(t)
+	d := NewDependency()
+
+	opts, err := d.RepositoryOptions()
+	is.NoError(err)
+	is.Nil(opts)
+
+	d.BearerToken = "secret"
+	_, err = d.RepositoryOptions()
+	is.Error(err, "expected the bearer token to require a repository")
+
+	d.RepoURL = "https://charts.example.com"
+	opts, err = d.RepositoryOptions()
+	is.NoError(err)
+	is.Len(opts["https://charts.example.com"], 2)
+}
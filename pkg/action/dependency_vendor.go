@@ -0,0 +1,131 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/loader"
+	"helm.sh/helm/v4/pkg/chartutil"
+)
+
+// Vendor writes a flattened copy of the chart at chartpath to a directory
+// named after the chart in dest, for 'helm dependency vendor', and returns the
+// path of the copy.
+//
+// The copy does not need any dependency to be fetched to be installed:
+//   - the dependencies enabled by the values are expanded as directories in
+//     charts/, recursively, and the disabled ones are left out;
+//   - aliases are applied to the names of the dependencies;
+//   - the values.yaml file contains the resolved values of the chart and its
+//     dependencies, including the imported values, merged with vals;
+//   - the Chart.lock files are kept to record where the dependencies came from.
+//
+// The dependencies must be present in charts/, see 'helm dependency build'.
+func (d *Dependency) Vendor(chartpath, dest string, vals map[string]interface{}) (string, error) {
+	c, err := loader.Load(chartpath)
+	if err != nil {
+		return "", err
+	}
+	if req := c.Metadata.Dependencies; req != nil {
+		if err := CheckDependencies(c, req); err != nil {
+			return "", errors.Wrap(err, "You may need to run `helm dependency build` to fetch missing dependencies")
+		}
+	}
+
+	outdir := filepath.Join(dest, c.Name())
+	if _, err := os.Stat(outdir); err == nil {
+		return "", errors.Errorf("%s already exists", outdir)
+	}
+
+	if vals == nil {
+		vals = map[string]interface{}{}
+	}
+	if err := chartutil.ProcessDependencies(c, vals); err != nil {
+		return "", err
+	}
+	resolved, err := chartutil.CoalesceValues(c, vals)
+	if err != nil {
+		return "", err
+	}
+	data, err := yaml.Marshal(resolved)
+	if err != nil {
+		return "", err
+	}
+	setValuesFile(c, data)
+
+	return outdir, saveVendored(c, dest)
+}
+
+// saveVendored saves the chart to a directory in dest, with its dependencies
+// expanded as directories.
+func saveVendored(c *chart.Chart, dest string) error {
+	deps := c.Dependencies()
+	// The imported values are part of the resolved values already.
+	for _, dep := range c.Metadata.Dependencies {
+		dep.ImportValues = nil
+	}
+
+	c.SetDependencies()
+	err := chartutil.SaveDir(c, dest)
+	c.SetDependencies(deps...)
+	if err != nil {
+		return err
+	}
+
+	outdir := filepath.Join(dest, c.Name())
+	for _, dep := range deps {
+		if err := saveVendored(dep, filepath.Join(outdir, chartutil.ChartsDir)); err != nil {
+			return errors.Wrapf(err, "saving %s", dep.ChartFullPath())
+		}
+	}
+	if c.Lock != nil {
+		// The vendored dependencies differ from the fetched ones, so their
+		// digests are those of the saved copies.
+		if c.Lock.Digests != nil {
+			saved, err := loader.LoadDir(outdir)
+			if err != nil {
+				return err
+			}
+			c.Lock.Digests = chartutil.DependencyDigests(saved)
+		}
+		data, err := yaml.Marshal(c.Lock)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(outdir, "Chart.lock"), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setValuesFile replaces the values.yaml file of the chart.
+func setValuesFile(c *chart.Chart, data []byte) {
+	for _, f := range c.Raw {
+		if f.Name == chartutil.ValuesfileName {
+			f.Data = data
+			return
+		}
+	}
+	c.Raw = append(c.Raw, &chart.File{Name: chartutil.ValuesfileName, Data: data})
+}
@@ -0,0 +1,76 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/loader"
+	"helm.sh/helm/v4/pkg/chartutil"
+)
+
+func TestVendor(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+	chartpath := "../chartutil/testdata/subpop"
+	dest := t.TempDir()
+
+	out, err := NewDependency().Vendor(chartpath, dest, nil)
+	req.NoError(err)
+	is.Equal(filepath.Join(dest, "parentchart"), out)
+
+	// The dependencies are expanded as directories, and disabled ones are left out.
+	is.DirExists(filepath.Join(out, "charts", "subchart1", "charts", "subcharta"))
+	is.NoDirExists(filepath.Join(out, "charts", "subchart2"))
+	entries, err := os.ReadDir(filepath.Join(out, "charts"))
+	req.NoError(err)
+	is.Len(entries, 1)
+
+	// The imported values are part of the values.
+	vendored, err := loader.Load(out)
+	req.NoError(err)
+	vals := chartutil.Values(vendored.Values)
+	v, err := vals.PathValue("imported-chart1.SC1string")
+	req.NoError(err)
+	is.Equal("dollywood", v)
+
+	// The vendored chart renders as the original one.
+	render := func(path string) string {
+		instAction := installAction(t)
+		instAction.DryRun = true
+		instAction.ClientOnly = true
+		c, err := loader.Load(path)
+		req.NoError(err)
+		rel, err := instAction.Run(c, map[string]interface{}{})
+		req.NoError(err)
+		return rel.Manifest
+	}
+	is.Equal(render(chartpath), render(out))
+
+	_, err = NewDependency().Vendor(chartpath, dest, nil)
+	is.ErrorContains(err, "already exists")
+}
+
+func TestVendor_MissingDependencies(t *testing.T) {
+	_, err := NewDependency().Vendor("testdata/charts/chart-missing-deps", t.TempDir(), nil)
+	assert.ErrorContains(t, err, "helm dependency build")
+}
@@ -0,0 +1,144 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"time"
+
+	"helm.sh/helm/v4/pkg/engine"
+	"helm.sh/helm/v4/pkg/postrender"
+	"helm.sh/helm/v4/pkg/redact"
+	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/releaseutil"
+)
+
+// DeployOptions are the options shared by the Install and Upgrade actions,
+// which InstallOrUpgrade passes on to the install of a release.
+type DeployOptions struct {
+	// Namespace is the namespace in which this operation should be performed.
+	Namespace string
+	// Devel uses the development versions of the charts, too.
+	Devel bool
+	// DependencyUpdate updates the missing dependencies of the chart.
+	DependencyUpdate bool
+	// Force will, if set to `true`, ignore certain warnings and perform the
+	// operation anyway.
+	//
+	// This should be used with caution.
+	Force bool
+	// DryRun controls whether the operation is prepared, but not executed.
+	DryRun bool
+	// DryRunOption controls whether the operation is prepared, but not
+	// executed with options on whether or not to interact with the remote
+	// cluster.
+	DryRunOption string
+	// HideSecret can be set to true when DryRun is enabled in order to hide
+	// Kubernetes Secrets in the output. It cannot be used outside of DryRun.
+	HideSecret bool
+	// Redactors are applied to the returned release when DryRun is enabled,
+	// e.g. to hide credentials in Secrets or custom resources. They cannot be
+	// used outside of DryRun.
+	Redactors []redact.Redactor
+	// DisableHooks disables hook processing if set to true.
+	DisableHooks bool
+	// SkipCRDs skips installing the CRDs of the chart.
+	SkipCRDs bool
+	// Timeout is the timeout for this operation
+	Timeout time.Duration
+	// ResourceTimeout limits every request for a single resource while the
+	// resources are applied, so that a slow resource, e.g. one behind an
+	// admission webhook, is reported instead of using up Timeout. Zero means
+	// no limit.
+	ResourceTimeout time.Duration
+	// Wait determines whether the resources are waited for once applied.
+	Wait bool
+	// WaitForJobs determines whether the Jobs are waited for as well.
+	WaitForJobs bool
+	// Atomic, if true, will roll back or uninstall the release on failure.
+	Atomic bool
+	// MaxHistory limits the maximum number of revisions saved per release
+	MaxHistory int
+	// Description is the description of this operation
+	Description string
+	Labels      map[string]string
+	// SubNotes determines whether sub-notes are rendered in the chart.
+	SubNotes bool
+	// HideNotes determines whether notes are output during the operation
+	HideNotes bool
+	// SkipSchemaValidation determines if JSON schema validation is disabled.
+	SkipSchemaValidation bool
+	// DisableOpenAPIValidation controls whether OpenAPI validation is enforced.
+	DisableOpenAPIValidation bool
+	// Enable DNS lookups when rendering templates
+	EnableDNS bool
+	// EnableClusterConfig allows templates to query cluster configuration such
+	// as the cluster DNS domain and IngressClasses
+	EnableClusterConfig bool
+	// Strictness selects the template problems that make rendering fail
+	Strictness engine.Strictness
+	// ReportAllErrors reports the errors of all the templates that fail to
+	// render instead of stopping at the first one
+	ReportAllErrors bool
+	// TakeOwnership will skip the check for helm annotations and adopt all
+	// existing resources.
+	TakeOwnership bool
+	// SkipRequirementChecks disables the pre-flight checks of the cluster
+	// requirements declared in Chart.yaml.
+	SkipRequirementChecks bool
+	// FailOnDeprecated refuses to deploy a chart that is deprecated or past
+	// the end of its support, or that has such dependencies, with an
+	// UnsupportedChartError instead of warning about it.
+	FailOnDeprecated bool
+	// CheckPermissions checks that the user may apply the resources, run the
+	// hooks and store the release, before anything is applied, so that the
+	// missing permissions are reported at once rather than by an operation
+	// failing halfway. The check is also run by server-side dry runs.
+	CheckPermissions bool
+	// ManifestFormat is the format of the rendered manifests, which the
+	// post-renderer receives and the release stores.
+	ManifestFormat releaseutil.ManifestFormat
+	// PostRenderer is an optional post-renderer
+	//
+	// If this is non-nil, then after templates are rendered, they will be sent to the
+	// post renderer before sending to the Kubernetes API server.
+	PostRenderer postrender.PostRenderer
+	// ImageOverrides rewrite the container images of the rendered manifests
+	// and hooks after post-rendering.
+	ImageOverrides postrender.ImageOverrides
+	// ValuesRaw is the values file the values passed to Run were parsed
+	// from, see values.Options.MergeValuesRaw. When the values are the file
+	// with its anchors expanded, the file is stored with the release as
+	// written, for 'helm get values' to return. An upgrade does not store it
+	// when the values are merged with reused values.
+	ValuesRaw []byte
+	// ValuesSources are the recorded inputs of the values passed to Run,
+	// stored with the release, see values.Options.Merge. With
+	// Upgrade.ReuseValues, the values of the release also hold the values of
+	// the previous revision.
+	ValuesSources []*release.ValuesSource
+	// Exclusions select rendered resources that are neither applied nor
+	// tracked by the release. On upgrade, the resources they select in the
+	// previous revision are not deleted either.
+	Exclusions release.ResourceExclusions
+	// ComputeEffective runs the server-side dry run of the resources and
+	// records the objects the cluster would store in EffectiveObjects. It
+	// requires the "server" DryRunOption.
+	ComputeEffective bool
+	// SaveConfig maintains the kubectl.kubernetes.io/last-applied-configuration
+	// annotation of the resources like kubectl apply, see kube.SaveConfig.
+	SaveConfig bool
+}
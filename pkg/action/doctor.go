@@ -0,0 +1,325 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/helmpath"
+	"helm.sh/helm/v4/pkg/plugin"
+	"helm.sh/helm/v4/pkg/registry"
+	"helm.sh/helm/v4/pkg/repo"
+)
+
+// DiagnosticStatus is the outcome of a check of 'helm doctor'.
+type DiagnosticStatus string
+
+const (
+	// DiagnosticOK reports a check that passed.
+	DiagnosticOK DiagnosticStatus = "OK"
+	// DiagnosticWarning reports a problem that does not prevent Helm from
+	// working, e.g. a stale cache.
+	DiagnosticWarning DiagnosticStatus = "Warning"
+	// DiagnosticFailed reports a problem that makes some commands fail.
+	DiagnosticFailed DiagnosticStatus = "Failed"
+	// DiagnosticSkipped reports a check that could not be run, e.g. the checks
+	// of an unreachable cluster.
+	DiagnosticSkipped DiagnosticStatus = "Skipped"
+)
+
+// Diagnostic is the result of a check of 'helm doctor'.
+type Diagnostic struct {
+	// Check is the name of the check, e.g. "repositories".
+	Check   string           `json:"check"`
+	Status  DiagnosticStatus `json:"status"`
+	Message string           `json:"message"`
+	// Details are the individual problems found, e.g. one per repository.
+	Details []string `json:"details,omitempty"`
+}
+
+// DoctorReport is the report of 'helm doctor', in the order of the checks.
+type DoctorReport struct {
+	Diagnostics []*Diagnostic `json:"diagnostics"`
+}
+
+// Failed returns the diagnostics of the failed checks.
+func (r *DoctorReport) Failed() []*Diagnostic {
+	var failed []*Diagnostic
+	for _, d := range r.Diagnostics {
+		if d.Status == DiagnosticFailed {
+			failed = append(failed, d)
+		}
+	}
+	return failed
+}
+
+func (r *DoctorReport) add(check string, status DiagnosticStatus, details []string, format string, args ...interface{}) {
+	r.Diagnostics = append(r.Diagnostics, &Diagnostic{
+		Check:   check,
+		Status:  status,
+		Message: fmt.Sprintf(format, args...),
+		Details: details,
+	})
+}
+
+// Doctor is the action for diagnosing the environment of Helm.
+//
+// It provides the implementation of 'helm doctor'.
+type Doctor struct {
+	cfg *Configuration
+
+	Settings *cli.EnvSettings
+	// ClientOnly skips the checks of the cluster.
+	ClientOnly bool
+	// Timeout limits the check of each registry.
+	Timeout time.Duration
+}
+
+// NewDoctor creates a new Doctor object with the given configuration.
+func NewDoctor(cfg *Configuration, settings *cli.EnvSettings) *Doctor {
+	return &Doctor{
+		cfg:      cfg,
+		Settings: settings,
+		Timeout:  5 * time.Second,
+	}
+}
+
+// Run checks the local environment, i.e. the repositories, the caches, the
+// plugins and the registries Helm is logged in to, then the cluster, i.e. the
+// storage of the releases and the permissions to manage them in the
+// namespace of the settings. The checks do not modify anything: a problem is
+// reported in the diagnostics of its check with the way to fix it, if known.
+func (d *Doctor) Run(ctx context.Context) *DoctorReport {
+	r := &DoctorReport{}
+	d.checkRepositories(r)
+	d.checkContentCache(r)
+	d.checkPlugins(r)
+	d.checkRegistries(ctx, r)
+	if !d.ClientOnly {
+		d.checkCluster(ctx, r)
+	}
+	return r
+}
+
+// checkRepositories checks that the repositories files can be read and that
+// the index of every repository is cached and valid.
+func (d *Doctor) checkRepositories(r *DoctorReport) {
+	s := d.Settings
+	f, err := repo.LoadFiles(s.RepositoryConfig, s.RepositoryConfigDir)
+	if err != nil {
+		r.add("repositories", DiagnosticFailed, nil, "invalid repositories file: %s", err)
+		return
+	}
+	if len(f.Repositories) == 0 {
+		r.add("repositories", DiagnosticOK, nil, "no repositories configured")
+		return
+	}
+
+	var missing, invalid []string
+	for _, e := range f.Repositories {
+		if e == nil {
+			continue
+		}
+		path := filepath.Join(s.RepositoryCache, helmpath.CacheIndexFile(e.Name))
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			missing = append(missing, fmt.Sprintf("%s: the index is not cached", e.Name))
+		} else if _, err := repo.LoadIndexFile(path); err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %s", e.Name, err))
+		}
+	}
+	switch {
+	case len(invalid) > 0:
+		r.add("repositories", DiagnosticFailed, append(invalid, missing...), "%d of %d repositories have an invalid index, run 'helm repo update'", len(invalid), len(f.Repositories))
+	case len(missing) > 0:
+		r.add("repositories", DiagnosticWarning, missing, "%d of %d repositories have no cached index, run 'helm repo update'", len(missing), len(f.Repositories))
+	default:
+		r.add("repositories", DiagnosticOK, nil, "%d repositories configured", len(f.Repositories))
+	}
+}
+
+// checkContentCache checks the content of the content cache against its
+// digests, without removing the corrupted content like 'helm cache prune'.
+func (d *Doctor) checkContentCache(r *DoctorReport) {
+	if d.Settings.ContentCache == "" {
+		r.add("content cache", DiagnosticSkipped, nil, "no content cache configured")
+		return
+	}
+	entries, err := newDiskCache(d.Settings).Entries()
+	if err != nil {
+		r.add("content cache", DiagnosticFailed, nil, "unable to read the content cache: %s", err)
+		return
+	}
+
+	var size int64
+	var corrupted []string
+	for _, e := range entries {
+		size += e.Size
+		data, err := os.ReadFile(e.Path)
+		if err != nil {
+			corrupted = append(corrupted, fmt.Sprintf("%s: %s", e.Path, err))
+		} else if fmt.Sprintf("sha256:%x", sha256.Sum256(data)) != e.Digest {
+			corrupted = append(corrupted, fmt.Sprintf("%s: the content does not match its digest", e.Path))
+		}
+	}
+	switch maxSize := d.Settings.ContentCacheMaxSize; {
+	case len(corrupted) > 0:
+		r.add("content cache", DiagnosticWarning, corrupted, "%d of %d entries are corrupted, run 'helm cache prune'", len(corrupted), len(entries))
+	case maxSize > 0 && size > maxSize:
+		r.add("content cache", DiagnosticWarning, nil, "%d entries use %d bytes, more than the maximum size of %d bytes, run 'helm cache prune'", len(entries), size, maxSize)
+	default:
+		r.add("content cache", DiagnosticOK, nil, "%d entries use %d bytes", len(entries), size)
+	}
+}
+
+// checkPlugins checks that the installed plugins can be loaded and that
+// their dependencies are installed.
+func (d *Doctor) checkPlugins(r *DoctorReport) {
+	plugins, err := plugin.FindPlugins(d.Settings.PluginsDirectory)
+	if err != nil {
+		r.add("plugins", DiagnosticFailed, nil, "unable to load the plugins: %s", err)
+		return
+	}
+
+	var broken []string
+	for _, p := range plugins {
+		if err := plugin.CheckDependencies(p, plugins); err != nil {
+			broken = append(broken, fmt.Sprintf("%s: %s", p.Metadata.Name, err))
+		}
+	}
+	if len(broken) > 0 {
+		r.add("plugins", DiagnosticFailed, broken, "%d of %d plugins have unsatisfied dependencies", len(broken), len(plugins))
+		return
+	}
+	r.add("plugins", DiagnosticOK, nil, "%d plugins installed", len(plugins))
+}
+
+// checkRegistries checks that the registries Helm is logged in to are
+// reachable, with the proxy, TLS and plain HTTP settings of their hosts file.
+func (d *Doctor) checkRegistries(ctx context.Context, r *DoctorReport) {
+	hosts, err := registryHosts(d.Settings.RegistryConfig)
+	if err != nil {
+		r.add("registries", DiagnosticFailed, nil, "invalid registry configuration: %s", err)
+		return
+	}
+	if len(hosts) == 0 {
+		r.add("registries", DiagnosticOK, nil, "not logged in to any registry")
+		return
+	}
+	client, err := registry.NewClient(
+		registry.ClientOptCredentialsFile(d.Settings.RegistryConfig),
+		registry.ClientOptHostsFile(registry.HostsFilePath(d.Settings.RegistryConfig)),
+	)
+	if err != nil {
+		r.add("registries", DiagnosticFailed, nil, "invalid registry configuration: %s", err)
+		return
+	}
+
+	var unreachable []string
+	for _, host := range hosts {
+		if err := d.pingRegistry(ctx, client, host); err != nil {
+			unreachable = append(unreachable, fmt.Sprintf("%s: %s", host, err))
+		}
+	}
+	if len(unreachable) > 0 {
+		r.add("registries", DiagnosticWarning, unreachable, "%d of %d registries are unreachable", len(unreachable), len(hosts))
+		return
+	}
+	r.add("registries", DiagnosticOK, nil, "%d registries reachable", len(hosts))
+}
+
+// registryHosts returns the hosts of the credentials of the registry
+// configuration file, sorted.
+func registryHosts(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var config struct {
+		Auths map[string]json.RawMessage `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse %s", path)
+	}
+	hosts := make([]string, 0, len(config.Auths))
+	for host := range config.Auths {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts, nil
+}
+
+// pingRegistry requests the base endpoint of the OCI distribution API of a
+// registry within the timeout of the check.
+func (d *Doctor) pingRegistry(ctx context.Context, client *registry.Client, host string) error {
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout)
+	defer cancel()
+	return client.Ping(ctx, host)
+}
+
+// checkCluster checks that the cluster is reachable, that the releases can be
+// read from the storage and that the user may manage releases in the
+// namespace.
+func (d *Doctor) checkCluster(ctx context.Context, r *DoctorReport) {
+	if err := d.cfg.KubeClient.IsReachable(); err != nil {
+		r.add("cluster", DiagnosticFailed, nil, "the cluster is unreachable: %s", err)
+		r.add("storage", DiagnosticSkipped, nil, "the cluster is unreachable")
+		r.add("permissions", DiagnosticSkipped, nil, "the cluster is unreachable")
+		return
+	}
+	r.add("cluster", DiagnosticOK, nil, "the cluster is reachable")
+
+	namespace := d.Settings.Namespace()
+	releases, err := d.cfg.Releases.ListReleases()
+	if err != nil {
+		r.add("storage", DiagnosticFailed, nil, "unable to list the releases with the %s driver: %s", d.cfg.Releases.Name(), err)
+	} else {
+		r.add("storage", DiagnosticOK, nil, "%d release revisions stored by the %s driver", len(releases), d.cfg.Releases.Name())
+	}
+
+	perms := permissionSet{}
+	d.cfg.addStoragePermissions(perms, namespace)
+	if len(perms) == 0 {
+		r.add("permissions", DiagnosticSkipped, nil, "the %s driver does not store the releases in the cluster", d.cfg.Releases.Name())
+		return
+	}
+	err = d.cfg.checkPermissions(ctx, perms)
+	var missing *MissingPermissionsError
+	switch {
+	case errors.As(err, &missing):
+		details := make([]string, len(missing.Missing))
+		for i, p := range missing.Missing {
+			details[i] = p.String()
+		}
+		r.add("permissions", DiagnosticFailed, details, "missing %d permissions to manage releases in namespace %q", len(missing.Missing), namespace)
+	case err != nil:
+		r.add("permissions", DiagnosticWarning, nil, "%s", err)
+	default:
+		r.add("permissions", DiagnosticOK, nil, "allowed to manage releases in namespace %q", namespace)
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/cli"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	"helm.sh/helm/v4/pkg/registry"
+)
+
+func doctorSettings(t *testing.T) *cli.EnvSettings {
+	t.Helper()
+	dir := t.TempDir()
+	settings := cli.New()
+	settings.RepositoryConfig = filepath.Join(dir, "repositories.yaml")
+	settings.RepositoryConfigDir = filepath.Join(dir, "repositories.d")
+	settings.RepositoryCache = filepath.Join(dir, "repository")
+	settings.PluginsDirectory = filepath.Join(dir, "plugins")
+	settings.RegistryConfig = filepath.Join(dir, "registry", "config.json")
+	return settings
+}
+
+func diagnostic(t *testing.T, r *DoctorReport, check string) *Diagnostic {
+	t.Helper()
+	for _, d := range r.Diagnostics {
+		if d.Check == check {
+			return d
+		}
+	}
+	t.Fatalf("no diagnostic of check %q", check)
+	return nil
+}
+
+func TestDoctor(t *testing.T) {
+	settings := doctorSettings(t)
+	client := NewDoctor(actionConfigFixture(t), settings)
+	r := client.Run(context.Background())
+
+	checks := make([]string, len(r.Diagnostics))
+	for i, d := range r.Diagnostics {
+		checks[i] = d.Check
+		if d.Status == DiagnosticFailed || d.Status == DiagnosticWarning {
+			t.Errorf("unexpected diagnostic of an empty environment: %+v", d)
+		}
+	}
+	assert.Equal(t, []string{"repositories", "content cache", "plugins", "registries", "cluster", "storage", "permissions"}, checks)
+	assert.Empty(t, r.Failed())
+	assert.Equal(t, DiagnosticSkipped, diagnostic(t, r, "permissions").Status, "the memory driver needs no permissions")
+
+	client.ClientOnly = true
+	assert.Len(t, client.Run(context.Background()).Diagnostics, 4)
+}
+
+func TestDoctorRepositories(t *testing.T) {
+	settings := doctorSettings(t)
+	require.NoError(t, os.WriteFile(settings.RepositoryConfig, []byte(`
+repositories:
+- name: cached
+  url: https://example.com/cached
+- name: missing
+  url: https://example.com/missing
+- name: corrupted
+  url: https://example.com/corrupted
+`), 0644))
+	require.NoError(t, os.MkdirAll(settings.RepositoryCache, 0755))
+	index, err := os.ReadFile("../repo/testdata/local-index.yaml")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(settings.RepositoryCache, "cached-index.yaml"), index, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(settings.RepositoryCache, "corrupted-index.yaml"), []byte("entries: ["), 0644))
+
+	r := NewDoctor(actionConfigFixture(t), settings).Run(context.Background())
+	d := diagnostic(t, r, "repositories")
+	assert.Equal(t, DiagnosticFailed, d.Status)
+	assert.Equal(t, "1 of 3 repositories have an invalid index, run 'helm repo update'", d.Message)
+	require.Len(t, d.Details, 2)
+	assert.True(t, strings.HasPrefix(d.Details[0], "corrupted: "))
+	assert.Equal(t, "missing: the index is not cached", d.Details[1])
+	assert.Equal(t, []*Diagnostic{d}, r.Failed())
+}
+
+func TestDoctorContentCache(t *testing.T) {
+	settings := doctorSettings(t)
+	settings.ContentCache = filepath.Join(t.TempDir(), "content")
+	content := []byte("content")
+	path, err := newDiskCache(settings).Put(sha256.Sum256(content), content, ".tgz")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte("corrupted"), 0644))
+
+	d := diagnostic(t, NewDoctor(actionConfigFixture(t), settings).Run(context.Background()), "content cache")
+	assert.Equal(t, DiagnosticWarning, d.Status)
+	assert.Equal(t, []string{path + ": the content does not match its digest"}, d.Details)
+	assert.FileExists(t, path, "doctor must not remove the corrupted content")
+}
+
+func TestDoctorRegistries(t *testing.T) {
+	reachable := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer reachable.Close()
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer plain.Close()
+	broken := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer broken.Close()
+
+	settings := doctorSettings(t)
+	dir := filepath.Dir(settings.RegistryConfig)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	reachableHost := strings.TrimPrefix(reachable.URL, "https://")
+	plainHost := strings.TrimPrefix(plain.URL, "http://")
+	brokenHost := strings.TrimPrefix(broken.URL, "https://")
+	config := fmt.Sprintf(`{"auths": {%q: {"auth": "dXNlcjpwYXNz"}, %q: {"auth": "dXNlcjpwYXNz"}, %q: {"auth": "dXNlcjpwYXNz"}}}`, reachableHost, plainHost, brokenHost)
+	require.NoError(t, os.WriteFile(settings.RegistryConfig, []byte(config), 0600))
+
+	// The registries are checked with the CA and plain HTTP settings of the
+	// hosts file. Both TLS servers share the certificate of the test server.
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: reachable.Certificate().Raw}), 0600))
+	hosts := &registry.HostsFile{Hosts: map[string]registry.HostConfig{
+		reachableHost: {CAFile: caFile},
+		plainHost:     {PlainHTTP: true},
+		brokenHost:    {CAFile: caFile},
+	}}
+	require.NoError(t, hosts.WriteFile(registry.HostsFilePath(settings.RegistryConfig), 0600))
+
+	client := NewDoctor(actionConfigFixture(t), settings)
+	d := diagnostic(t, client.Run(context.Background()), "registries")
+	assert.Equal(t, DiagnosticWarning, d.Status)
+	assert.Equal(t, "1 of 3 registries are unreachable", d.Message)
+	assert.Equal(t, []string{brokenHost + ": unexpected status 502 Bad Gateway"}, d.Details)
+
+	// Without the hosts file, the certificate of the test server is unknown
+	// and the plain HTTP registry is requested over HTTPS.
+	require.NoError(t, os.Remove(registry.HostsFilePath(settings.RegistryConfig)))
+	d = diagnostic(t, client.Run(context.Background()), "registries")
+	assert.Equal(t, "3 of 3 registries are unreachable", d.Message)
+}
+
+func TestDoctorUnreachableCluster(t *testing.T) {
+	config := actionConfigFixture(t)
+	config.KubeClient = &kubefake.FailingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}, IsReachableError: errors.New("connection refused")}
+
+	r := NewDoctor(config, doctorSettings(t)).Run(context.Background())
+	assert.Equal(t, DiagnosticFailed, diagnostic(t, r, "cluster").Status)
+	assert.Equal(t, "the cluster is unreachable: connection refused", diagnostic(t, r, "cluster").Message)
+	assert.Equal(t, DiagnosticSkipped, diagnostic(t, r, "storage").Status)
+	assert.Equal(t, DiagnosticSkipped, diagnostic(t, r, "permissions").Status)
+}
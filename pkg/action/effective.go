@@ -0,0 +1,111 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/kube"
+	"helm.sh/helm/v4/pkg/redact"
+)
+
+// errEffectiveRequiresServerDryRun is returned when the effective objects are
+// requested without a server-side dry run.
+var errEffectiveRequiresServerDryRun = errors.New("computing the effective objects requires a server-side dry run")
+
+// EffectiveObject is a resource of a release as the cluster would store it,
+// after defaulting and the mutating admission webhooks, computed by a
+// server-side dry run.
+type EffectiveObject struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Operation is "create" for a resource missing from the cluster, and
+	// "update" otherwise.
+	Operation string `json:"operation"`
+	// Object is the effective object, without its managed fields. It is nil
+	// when the cluster rejected the resource.
+	Object map[string]interface{} `json:"object,omitempty"`
+	// Error is the reason the cluster rejected the resource, e.g. the denial
+	// of a validating admission webhook.
+	Error string `json:"error,omitempty"`
+}
+
+// effectiveObjects runs the server-side dry run of the target resources, and
+// returns the objects the cluster would store, redacted by the redactors.
+func (cfg *Configuration) effectiveObjects(original, target kube.ResourceList, redactors []redact.Redactor) ([]*EffectiveObject, error) {
+	results := cfg.KubeClient.DryRun(original, target)
+	objects := make([]*EffectiveObject, 0, len(results))
+	for _, r := range results {
+		o := &EffectiveObject{Name: r.Info.Name, Namespace: r.Info.Namespace, Operation: r.Operation}
+		if r.Info.Mapping != nil {
+			o.Kind = r.Info.Mapping.GroupVersionKind.Kind
+		} else if r.Info.Object != nil {
+			o.Kind = r.Info.Object.GetObjectKind().GroupVersionKind().Kind
+		}
+		if errors.Is(r.Err, kube.ErrNotSupported) {
+			return nil, errors.New("the Kubernetes client does not support computing the effective objects")
+		}
+		if r.Err != nil {
+			o.Error = r.Err.Error()
+			objects = append(objects, o)
+			continue
+		}
+		if r.Object != nil {
+			obj, err := effectiveObject(r.Object, redactors)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to convert the effective object of %s %q", o.Kind, o.Name)
+			}
+			o.Object = obj
+		}
+		objects = append(objects, o)
+	}
+	return objects, nil
+}
+
+// effectiveObject converts an object returned by the cluster, dropping its
+// managed fields, which only tell the history of the object, and redacts it.
+func effectiveObject(obj runtime.Object, redactors []redact.Redactor) (map[string]interface{}, error) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	unstructured.RemoveNestedField(u, "metadata", "managedFields")
+	if len(redactors) == 0 {
+		return u, nil
+	}
+
+	doc, err := yaml.Marshal(u)
+	if err != nil {
+		return nil, err
+	}
+	redacted := string(doc)
+	for _, r := range redactors {
+		if redacted, err = r.Redact(redacted); err != nil {
+			return nil, err
+		}
+	}
+	// An object hidden by a redactor unmarshals to nil.
+	var out map[string]interface{}
+	if err := yaml.Unmarshal([]byte(redacted), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
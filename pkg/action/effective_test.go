@@ -0,0 +1,107 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	"helm.sh/helm/v4/pkg/release"
+)
+
+func newMissingSecret(name, namespace string) *resource.Info {
+	return &resource.Info{
+		Name:      name,
+		Namespace: namespace,
+		Mapping: &meta.RESTMapping{
+			Resource:         schema.GroupVersionResource{Version: "v1", Resource: "secrets"},
+			GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Secret"},
+			Scope:            meta.RESTScopeNamespace,
+		},
+		Object: &corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			StringData: map[string]string{"password": "hunter2"},
+		},
+		Client: fakeClientWith(http.StatusNotFound, corev1.SchemeGroupVersion, ""),
+	}
+}
+
+func TestInstallRelease_ComputeEffective(t *testing.T) {
+	instAction := installAction(t)
+	failer := instAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+	web := newMissingDeployment("web", "spaced")
+	web.Object.(metav1.Object).SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "helm"}})
+	instAction.cfg.KubeClient = &resourcesKubeClient{failer, kube.ResourceList{web, newMissingSecret("credentials", "spaced")}}
+	instAction.ComputeEffective = true
+
+	_, err := instAction.Run(buildChart(), map[string]interface{}{})
+	assert.Equal(t, errEffectiveRequiresServerDryRun, err)
+
+	instAction.DryRunOption = "server"
+	instAction.HideSecret = true
+	res, err := instAction.Run(buildChart(), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, release.ReasonDryRun, res.Info.Reason)
+	require.Len(t, instAction.EffectiveObjects, 2)
+
+	o := instAction.EffectiveObjects[0]
+	assert.Equal(t, EffectiveObject{Kind: "Deployment", Name: "web", Namespace: "spaced", Operation: "create", Object: o.Object}, *o)
+	assert.Equal(t, "web", o.Object["metadata"].(map[string]interface{})["name"])
+	assert.NotContains(t, o.Object["metadata"], "managedFields")
+	assert.Nil(t, instAction.EffectiveObjects[1].Object, "the secret must be hidden")
+
+	failer.DryRunError = errors.New(`admission webhook "deny.example.com" denied the request`)
+	_, err = instAction.Run(buildChart(), map[string]interface{}{})
+	require.NoError(t, err)
+	o = instAction.EffectiveObjects[0]
+	assert.Nil(t, o.Object)
+	assert.Equal(t, `admission webhook "deny.example.com" denied the request`, o.Error)
+}
+
+func TestUpgradeRelease_ComputeEffective(t *testing.T) {
+	upAction := upgradeAction(t)
+	failer := upAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+	upAction.cfg.KubeClient = &resourcesKubeClient{failer, kube.ResourceList{newMissingDeployment("web", "spaced")}}
+	rel := releaseStub()
+	rel.Info.Status = release.StatusDeployed
+	require.NoError(t, upAction.cfg.Releases.Create(rel))
+
+	upAction.ComputeEffective = true
+	upAction.DryRunOption = "client"
+	_, err := upAction.RunWithContext(context.Background(), rel.Name, buildChart(), map[string]interface{}{})
+	assert.Equal(t, errEffectiveRequiresServerDryRun, err)
+
+	upAction.DryRunOption = "server"
+	_, err = upAction.RunWithContext(context.Background(), rel.Name, buildChart(), map[string]interface{}{})
+	require.NoError(t, err)
+	require.Len(t, upAction.EffectiveObjects, 1)
+	assert.Equal(t, "Deployment", upAction.EffectiveObjects[0].Kind)
+	assert.NotNil(t, upAction.EffectiveObjects[0].Object)
+}
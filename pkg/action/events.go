@@ -0,0 +1,107 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+// Phase is a step of an install.
+type Phase string
+
+const (
+	// PhaseRender renders the chart templates.
+	PhaseRender Phase = "render"
+	// PhaseCRDs installs the CRDs of the crds/ directories.
+	PhaseCRDs Phase = "crds"
+	// PhasePreInstallHooks runs the pre-install hooks.
+	PhasePreInstallHooks Phase = "pre-install-hooks"
+	// PhaseApply creates or adopts the resources of the release.
+	PhaseApply Phase = "apply"
+	// PhaseWait waits for the resources to be ready.
+	PhaseWait Phase = "wait"
+	// PhasePostInstallHooks runs the post-install hooks.
+	PhasePostInstallHooks Phase = "post-install-hooks"
+)
+
+// EventType is the type of an Event.
+type EventType string
+
+const (
+	// EventPhaseStarted is sent when a phase starts.
+	EventPhaseStarted EventType = "PhaseStarted"
+	// EventPhaseSucceeded is sent when a phase succeeds.
+	EventPhaseSucceeded EventType = "PhaseSucceeded"
+	// EventPhaseFailed is sent when a phase fails. Err is the error.
+	EventPhaseFailed EventType = "PhaseFailed"
+	// EventResource is sent for every resource applied in PhaseApply, as soon
+	// as the Kubernetes client has applied it.
+	EventResource EventType = "Resource"
+)
+
+// Event reports the progress of an install while it runs.
+type Event struct {
+	Type  EventType
+	Phase Phase
+	// Err is set for EventPhaseFailed.
+	Err error
+	// Kind, Name, Namespace and Action are set for EventResource. Action is
+	// one of "created", "updated" or "deleted".
+	Kind      string
+	Name      string
+	Namespace string
+	Action    string
+}
+
+// EventHandler receives the events of an action. It is called synchronously,
+// never concurrently, and possibly from another goroutine than the one that
+// runs the action, so it should return quickly.
+type EventHandler func(Event)
+
+func (h EventHandler) emit(e Event) {
+	if h != nil {
+		h(e)
+	}
+}
+
+// phase runs f as the phase p, sending the events of the phase.
+func (h EventHandler) phase(p Phase, f func() error) error {
+	h.emit(Event{Type: EventPhaseStarted, Phase: p})
+	if err := f(); err != nil {
+		h.emit(Event{Type: EventPhaseFailed, Phase: p, Err: err})
+		return err
+	}
+	h.emit(Event{Type: EventPhaseSucceeded, Phase: p})
+	return nil
+}
+
+// resourceOptions returns the options of the kube client sending an
+// EventResource as soon as a resource is created, updated or deleted.
+func (h EventHandler) resourceOptions(p Phase) []kube.ApplyOption {
+	if h == nil {
+		return nil
+	}
+	return []kube.ApplyOption{kube.OnApplied(func(action string, info *resource.Info) {
+		e := Event{Type: EventResource, Phase: p, Name: info.Name, Namespace: info.Namespace, Action: action}
+		if info.Mapping != nil {
+			e.Kind = info.Mapping.GroupVersionKind.Kind
+		}
+		h(e)
+	})}
+}
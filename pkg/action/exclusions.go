@@ -0,0 +1,119 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/releaseutil"
+)
+
+// exclusionMatcher matches resources against release.ResourceExclusions.
+type exclusionMatcher struct {
+	kinds     map[string]bool
+	resources map[string]bool
+	selector  labels.Selector
+}
+
+func newExclusionMatcher(ex *release.ResourceExclusions) (*exclusionMatcher, error) {
+	m := &exclusionMatcher{kinds: map[string]bool{}, resources: map[string]bool{}}
+	for _, kind := range ex.Kinds {
+		m.kinds[strings.ToLower(kind)] = true
+	}
+	for _, res := range ex.Resources {
+		kind, name, ok := strings.Cut(res, "/")
+		if !ok || kind == "" || name == "" {
+			return nil, errors.Errorf("invalid excluded resource %q, expected KIND/NAME", res)
+		}
+		m.resources[strings.ToLower(kind)+"/"+name] = true
+	}
+	if ex.Selector != "" {
+		var err error
+		if m.selector, err = labels.Parse(ex.Selector); err != nil {
+			return nil, errors.Wrap(err, "invalid selector of excluded resources")
+		}
+	}
+	return m, nil
+}
+
+// match reports whether a document is excluded, and returns the excluded
+// resource as "KIND/NAME". Documents that cannot be parsed are not excluded,
+// they fail to build later.
+func (m *exclusionMatcher) match(doc string) (string, bool) {
+	var head struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Name   string            `json:"name"`
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+	}
+	if err := yaml.Unmarshal([]byte(doc), &head); err != nil || head.Kind == "" {
+		return "", false
+	}
+	kind := strings.ToLower(head.Kind)
+	if m.kinds[kind] || m.resources[kind+"/"+head.Metadata.Name] ||
+		(m.selector != nil && m.selector.Matches(labels.Set(head.Metadata.Labels))) {
+		return head.Kind + "/" + head.Metadata.Name, true
+	}
+	return "", false
+}
+
+// excludeResources removes the resources selected by the exclusions from the
+// manifest and the hooks of a revision. It returns the exclusions to record
+// on the revision, with the excluded resources, or nil when nothing is
+// excluded.
+func excludeResources(ex *release.ResourceExclusions, manifest string, hooks []*release.Hook) (string, []*release.Hook, *release.ResourceExclusions, error) {
+	if ex.IsZero() {
+		return manifest, hooks, nil, nil
+	}
+	m, err := newExclusionMatcher(ex)
+	if err != nil {
+		return manifest, hooks, nil, err
+	}
+
+	recorded := &release.ResourceExclusions{Kinds: ex.Kinds, Resources: ex.Resources, Selector: ex.Selector}
+	var kept []string
+	docs := releaseutil.SplitDocuments(manifest)
+	for _, doc := range docs {
+		if res, ok := m.match(doc); ok {
+			recorded.Excluded = append(recorded.Excluded, res)
+			continue
+		}
+		kept = append(kept, doc)
+	}
+	if len(kept) < len(docs) {
+		manifest = ""
+		if len(kept) > 0 {
+			manifest = "---\n" + strings.Join(kept, "\n---\n") + "\n"
+		}
+	}
+
+	keptHooks := hooks[:0:0]
+	for _, h := range hooks {
+		if res, ok := m.match(h.Manifest); ok {
+			recorded.Excluded = append(recorded.Excluded, res)
+			continue
+		}
+		keptHooks = append(keptHooks, h)
+	}
+	return manifest, keptHooks, recorded, nil
+}
@@ -0,0 +1,147 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	"helm.sh/helm/v4/pkg/release"
+)
+
+const exclusionsManifest = `---
+# Source: hello/templates/web.yaml
+kind: Deployment
+metadata:
+  name: web
+---
+# Source: hello/templates/psp.yaml
+kind: PodSecurityPolicy
+metadata:
+  name: restricted
+---
+# Source: hello/templates/secret.yaml
+kind: Secret
+metadata:
+  name: credentials
+---
+# Source: hello/templates/legacy.yaml
+kind: ConfigMap
+metadata:
+  name: legacy
+  labels:
+    component: legacy
+`
+
+func TestExcludeResources(t *testing.T) {
+	hooks := []*release.Hook{
+		{Name: "migrate", Kind: "Job", Manifest: "kind: Job\nmetadata:\n  name: migrate\n  labels:\n    component: legacy\n"},
+		{Name: "test-cm", Kind: "ConfigMap", Manifest: manifestWithHook},
+	}
+	ex := &release.ResourceExclusions{
+		Kinds:     []string{"podsecuritypolicy"},
+		Resources: []string{"Secret/credentials"},
+		Selector:  "component=legacy",
+	}
+
+	manifest, kept, recorded, err := excludeResources(ex, exclusionsManifest, hooks)
+	require.NoError(t, err)
+	assert.Equal(t, "---\n# Source: hello/templates/web.yaml\nkind: Deployment\nmetadata:\n  name: web\n", manifest)
+	assert.Equal(t, []*release.Hook{hooks[1]}, kept)
+	assert.Equal(t, ex.Kinds, recorded.Kinds)
+	assert.Equal(t, ex.Resources, recorded.Resources)
+	assert.Equal(t, ex.Selector, recorded.Selector)
+	assert.Equal(t, []string{"PodSecurityPolicy/restricted", "Secret/credentials", "ConfigMap/legacy", "Job/migrate"}, recorded.Excluded)
+
+	manifest, kept, recorded, err = excludeResources(&release.ResourceExclusions{}, exclusionsManifest, hooks)
+	require.NoError(t, err)
+	assert.Equal(t, exclusionsManifest, manifest)
+	assert.Equal(t, hooks, kept)
+	assert.Nil(t, recorded)
+
+	_, _, _, err = excludeResources(&release.ResourceExclusions{Resources: []string{"credentials"}}, exclusionsManifest, nil)
+	assert.EqualError(t, err, `invalid excluded resource "credentials", expected KIND/NAME`)
+	_, _, _, err = excludeResources(&release.ResourceExclusions{Selector: "component in legacy"}, exclusionsManifest, nil)
+	assert.ErrorContains(t, err, "invalid selector of excluded resources")
+}
+
+func TestInstallRelease_Exclusions(t *testing.T) {
+	instAction := installAction(t)
+	instAction.Exclusions = release.ResourceExclusions{Kinds: []string{"RoleBinding"}, Resources: []string{"ConfigMap/test-cm"}}
+
+	res, err := instAction.Run(buildChart(withMultipleManifestTemplate()), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Contains(t, res.Manifest, "kind: Role\n")
+	assert.NotContains(t, res.Manifest, "kind: RoleBinding")
+	assert.Empty(t, res.Hooks)
+	assert.Equal(t, []string{"RoleBinding/schedule-agents", "ConfigMap/test-cm"}, res.Exclusions.Excluded)
+
+	stored, err := instAction.cfg.Releases.Get(res.Name, res.Version)
+	require.NoError(t, err)
+	assert.Equal(t, res.Exclusions, stored.Exclusions)
+}
+
+// unservedKindKubeClient is a fake client of a cluster that no longer serves
+// a kind.
+type unservedKindKubeClient struct {
+	*kubefake.FailingKubeClient
+	kind string
+}
+
+func (c *unservedKindKubeClient) Build(r io.Reader, validate bool) (kube.ResourceList, error) {
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	if strings.Contains(buf.String(), "kind: "+c.kind+"\n") {
+		return nil, errors.Errorf("unable to recognize \"\": no matches for kind %q", c.kind)
+	}
+	return c.FailingKubeClient.Build(&buf, validate)
+}
+
+func (c *unservedKindKubeClient) BuildContext(_ context.Context, r io.Reader, validate bool) (kube.ResourceList, error) {
+	return c.Build(r, validate)
+}
+
+func TestUpgradeRelease_Exclusions(t *testing.T) {
+	upAction := upgradeAction(t)
+	failer := upAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+	upAction.cfg.KubeClient = &unservedKindKubeClient{failer, "PodSecurityPolicy"}
+
+	rel := releaseStub()
+	rel.Manifest = exclusionsManifest
+	require.NoError(t, upAction.cfg.Releases.Create(rel))
+
+	_, err := upAction.RunWithContext(context.Background(), rel.Name, buildChart(), map[string]interface{}{})
+	require.ErrorContains(t, err, "current release manifest contains removed kubernetes api(s)")
+
+	// The excluded resources of the previous revision need not be served.
+	upAction.Exclusions = release.ResourceExclusions{Kinds: []string{"PodSecurityPolicy"}}
+	res, err := upAction.RunWithContext(context.Background(), rel.Name, buildChart(), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, release.StatusDeployed, res.Info.Status)
+	assert.Empty(t, res.Exclusions.Excluded, "the chart renders no excluded resources")
+}
@@ -0,0 +1,269 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/redact"
+	"helm.sh/helm/v4/pkg/release"
+)
+
+// ExportRelease is the action for retrieving several revisions of a release
+// at once and writing everything about them to a directory or an archive,
+// e.g. for audits.
+//
+// It provides the implementation of 'helm get all' with a revision range or
+// '--output-dir'.
+type ExportRelease struct {
+	cfg *Configuration
+
+	// Revisions selects the revisions: "all", a single revision such as "3",
+	// or a range such as "2-5" or "3-", which ends at the latest revision.
+	// Empty or "0" selects the latest revision.
+	Revisions string
+	// Redactors are applied to the manifests and hooks of the revisions.
+	Redactors []redact.Redactor
+}
+
+// NewExportRelease creates a new ExportRelease object with the given
+// configuration.
+func NewExportRelease(cfg *Configuration) *ExportRelease {
+	return &ExportRelease{
+		cfg: cfg,
+	}
+}
+
+// Releases returns the selected revisions of the release, oldest first.
+func (e *ExportRelease) Releases(name string) ([]*release.Release, error) {
+	if err := e.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	latest := e.Revisions == "" || e.Revisions == "0"
+	from, to, err := ParseRevisionRange(e.Revisions)
+	if err != nil && !latest {
+		return nil, err
+	}
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, errors.Errorf("release name is invalid: %s", name)
+	}
+
+	history, err := e.cfg.Releases.History(name)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(history, func(i, j int) bool { return history[i].Version < history[j].Version })
+	if latest {
+		history = history[len(history)-1:]
+	}
+
+	var rels []*release.Release
+	for _, rel := range history {
+		if rel.Version < from || (to > 0 && rel.Version > to) {
+			continue
+		}
+		redacted, err := redactRelease(rel, e.Redactors)
+		if err != nil {
+			return nil, err
+		}
+		rels = append(rels, redacted)
+	}
+	if len(rels) == 0 {
+		return nil, errors.Errorf("release %q has no revision in %q", name, e.Revisions)
+	}
+	return rels, nil
+}
+
+// Run writes the selected revisions of the release to dest and returns them,
+// oldest first. A dest ending in .tgz or .tar.gz is written as a gzipped tar
+// archive, any other dest as a directory. The files of a revision are written
+// to NAME/REVISION/:
+//
+//	metadata.yaml         the release metadata, as printed by 'helm get metadata'
+//	chart.yaml            the metadata of the chart
+//	values.yaml           the user-supplied values
+//	computed-values.yaml  the values after merging the chart's default values
+//	manifest.yaml         the rendered manifest
+//	hooks.yaml            the rendered hooks
+//	notes.txt             the rendered notes, if the chart has notes
+func (e *ExportRelease) Run(name, dest string) ([]*release.Release, error) {
+	rels, err := e.Releases(name)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := newExportWriter(dest)
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range rels {
+		if err := writeRevision(w, rel); err != nil {
+			w.Close()
+			return nil, errors.Wrapf(err, "failed to export revision %d", rel.Version)
+		}
+	}
+	return rels, w.Close()
+}
+
+// ParseRevisionRange parses a revision selector of ExportRelease into the
+// first and the last revision. The last revision is 0 when the range ends at
+// the latest revision.
+func ParseRevisionRange(s string) (int, int, error) {
+	if s == "" || s == "all" {
+		return 0, 0, nil
+	}
+	first, last, isRange := strings.Cut(s, "-")
+	from, err := strconv.Atoi(first)
+	if err != nil || from < 1 {
+		return 0, 0, errors.Errorf("invalid revision range %q: expected a revision, a range such as 2-5 or 3-, or all", s)
+	}
+	if !isRange {
+		return from, from, nil
+	}
+	if last == "" {
+		return from, 0, nil
+	}
+	to, err := strconv.Atoi(last)
+	if err != nil || to < from {
+		return 0, 0, errors.Errorf("invalid revision range %q: expected a revision, a range such as 2-5 or 3-, or all", s)
+	}
+	return from, to, nil
+}
+
+func writeRevision(w exportWriter, rel *release.Release) error {
+	dir := path.Join(rel.Name, strconv.Itoa(rel.Version))
+
+	computed, err := chartutil.CoalesceValues(rel.Chart, rel.Config)
+	if err != nil {
+		return err
+	}
+	var hooks bytes.Buffer
+	for _, h := range rel.Hooks {
+		fmt.Fprintf(&hooks, "---\n# Source: %s\n%s\n", h.Path, h.Manifest)
+	}
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"metadata.yaml", newMetadata(rel)},
+		{"chart.yaml", rel.Chart.Metadata},
+		{"values.yaml", rel.Config},
+		{"computed-values.yaml", computed},
+	}
+	for _, f := range files {
+		data, err := yaml.Marshal(f.data)
+		if err != nil {
+			return err
+		}
+		if err := w.WriteFile(path.Join(dir, f.name), data); err != nil {
+			return err
+		}
+	}
+	if err := w.WriteFile(path.Join(dir, "manifest.yaml"), []byte(rel.Manifest)); err != nil {
+		return err
+	}
+	if err := w.WriteFile(path.Join(dir, "hooks.yaml"), hooks.Bytes()); err != nil {
+		return err
+	}
+	if rel.Info.Notes != "" {
+		return w.WriteFile(path.Join(dir, "notes.txt"), []byte(rel.Info.Notes))
+	}
+	return nil
+}
+
+// exportWriter writes the files of an export below its destination.
+type exportWriter interface {
+	WriteFile(name string, data []byte) error
+	Close() error
+}
+
+func newExportWriter(dest string) (exportWriter, error) {
+	if !strings.HasSuffix(dest, ".tgz") && !strings.HasSuffix(dest, ".tar.gz") {
+		return dirWriter(dest), nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(dest)
+	if err != nil {
+		return nil, err
+	}
+	zw := gzip.NewWriter(f)
+	return &archiveWriter{f: f, zw: zw, tw: tar.NewWriter(zw), modTime: time.Now()}, nil
+}
+
+// dirWriter writes the files to a directory.
+type dirWriter string
+
+func (d dirWriter) WriteFile(name string, data []byte) error {
+	p := filepath.Join(string(d), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0644)
+}
+
+func (d dirWriter) Close() error { return nil }
+
+// archiveWriter writes the files to a gzipped tar archive.
+type archiveWriter struct {
+	f       *os.File
+	zw      *gzip.Writer
+	tw      *tar.Writer
+	modTime time.Time
+}
+
+func (a *archiveWriter) WriteFile(name string, data []byte) error {
+	err := a.tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: a.modTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = a.tw.Write(data)
+	return err
+}
+
+func (a *archiveWriter) Close() error {
+	if err := a.tw.Close(); err != nil {
+		a.f.Close()
+		return err
+	}
+	if err := a.zw.Close(); err != nil {
+		a.f.Close()
+		return err
+	}
+	return a.f.Close()
+}
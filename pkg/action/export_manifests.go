@@ -0,0 +1,218 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/releaseutil"
+)
+
+// kustomizationFile is the name of the kustomization written by
+// ExportManifests.
+const kustomizationFile = "kustomization.yaml"
+
+// ExportManifests is the action for writing the resources of a release as
+// plain manifests, one file per resource, e.g. to migrate the release out of
+// Helm and manage its resources with kubectl or kustomize.
+//
+// It provides the implementation of 'helm release export'.
+type ExportManifests struct {
+	cfg *Configuration
+
+	// Version is the revision to export. Zero means the deployed revision.
+	Version int
+	// KeepOwnership sets the ownership metadata of the release on the
+	// manifests, like Helm does on the live resources, instead of removing
+	// the 'app.kubernetes.io/managed-by: Helm' label from them.
+	KeepOwnership bool
+	// Kustomize also writes a kustomization.yaml listing the manifests.
+	Kustomize bool
+}
+
+// ExportedManifest is a manifest written by ExportManifests.
+type ExportedManifest struct {
+	File      string `json:"file"`
+	Kind      string `json:"kind,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// NewExportManifests creates a new ExportManifests object with the given
+// configuration.
+func NewExportManifests(cfg *Configuration) *ExportManifests {
+	return &ExportManifests{
+		cfg: cfg,
+	}
+}
+
+// Run writes the resources of the revision of the release to dest, a
+// directory or a .tgz or .tar.gz archive like ExportRelease, and returns the
+// files written, in the order of the manifest of the revision. The hooks of
+// the release are not exported. The namespace is only set on the resources
+// that set it in the chart: apply the manifests to the namespace of the
+// release.
+func (e *ExportManifests) Run(name, dest string) ([]*ExportedManifest, error) {
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, errors.Errorf("release name is invalid: %s", name)
+	}
+	var rel *release.Release
+	var err error
+	if e.Version > 0 {
+		rel, err = e.cfg.Releases.Get(name, e.Version)
+	} else {
+		rel, err = e.cfg.Releases.Deployed(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*ExportedManifest
+	var contents [][]byte
+	seen := map[string]bool{}
+	for _, doc := range releaseutil.SplitDocuments(rel.Manifest) {
+		exported, data, err := e.exportManifest(rel, doc)
+		if err != nil {
+			return nil, err
+		}
+		if exported == nil {
+			continue
+		}
+		// Resources of the same kind and name may live in different
+		// namespaces.
+		exported.File = strings.ToLower(exported.Kind) + "-" + exported.Name + ".yaml"
+		if seen[exported.File] {
+			exported.File = strings.ToLower(exported.Kind) + "-" + exported.Namespace + "-" + exported.Name + ".yaml"
+		}
+		if seen[exported.File] {
+			return nil, errors.Errorf("the manifest holds %s %q twice", exported.Kind, exported.Name)
+		}
+		seen[exported.File] = true
+		files = append(files, exported)
+		contents = append(contents, data)
+	}
+	if len(files) == 0 {
+		return nil, errors.Errorf("revision %d of release %q has no resources", rel.Version, rel.Name)
+	}
+
+	w, err := newExportWriter(dest)
+	if err != nil {
+		return nil, err
+	}
+	for i, f := range files {
+		if err := w.WriteFile(f.File, contents[i]); err != nil {
+			w.Close()
+			return nil, err
+		}
+	}
+	if e.Kustomize {
+		if err := w.WriteFile(kustomizationFile, kustomization(files)); err != nil {
+			w.Close()
+			return nil, err
+		}
+	}
+	return files, w.Close()
+}
+
+// exportManifest returns the manifest of a document of the release, with the
+// ownership metadata set or removed. It returns nil for documents without a
+// resource.
+func (e *ExportManifests) exportManifest(rel *release.Release, doc string) (*ExportedManifest, []byte, error) {
+	var source string
+	if first, _, _ := strings.Cut(doc, "\n"); strings.HasPrefix(first, "# Source: ") {
+		source = first + "\n"
+	}
+	var obj map[string]interface{}
+	if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+		return nil, nil, errors.Wrapf(err, "unable to parse the manifest of revision %d", rel.Version)
+	}
+	kind, _ := obj["kind"].(string)
+	if kind == "" {
+		return nil, nil, nil
+	}
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+		obj["metadata"] = metadata
+	}
+	exported := &ExportedManifest{Kind: kind}
+	exported.Name, _ = metadata["name"].(string)
+	exported.Namespace, _ = metadata["namespace"].(string)
+	// The kind, the name and the namespace make the name of the file.
+	if exported.Name == "" {
+		return nil, nil, errors.Errorf("a %s of revision %d has no name", kind, rel.Version)
+	}
+	for _, part := range []string{kind, exported.Name, exported.Namespace} {
+		if strings.ContainsAny(part, `/\`) || strings.Contains(part, "..") {
+			return nil, nil, errors.Errorf("%s %q of revision %d cannot be exported to a file", kind, exported.Name, rel.Version)
+		}
+	}
+
+	labels, _ := metadata["labels"].(map[string]interface{})
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+	if e.KeepOwnership {
+		if labels == nil {
+			labels = map[string]interface{}{}
+		}
+		if annotations == nil {
+			annotations = map[string]interface{}{}
+		}
+		labels[appManagedByLabel] = appManagedByHelm
+		annotations[helmReleaseNameAnnotation] = rel.Name
+		annotations[helmReleaseNamespaceAnnotation] = rel.Namespace
+	} else {
+		if labels[appManagedByLabel] == appManagedByHelm {
+			delete(labels, appManagedByLabel)
+		}
+		delete(annotations, helmReleaseNameAnnotation)
+		delete(annotations, helmReleaseNamespaceAnnotation)
+	}
+	setOrDelete(metadata, "labels", labels)
+	setOrDelete(metadata, "annotations", annotations)
+
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return nil, nil, err
+	}
+	return exported, append([]byte(source), data...), nil
+}
+
+// setOrDelete sets the field of the object to a map, or deletes it when the
+// map is empty.
+func setOrDelete(obj map[string]interface{}, field string, m map[string]interface{}) {
+	if len(m) == 0 {
+		delete(obj, field)
+		return
+	}
+	obj[field] = m
+}
+
+// kustomization returns a kustomization listing the manifests.
+func kustomization(files []*ExportedManifest) []byte {
+	var b strings.Builder
+	b.WriteString("apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n")
+	for _, f := range files {
+		fmt.Fprintf(&b, "- %s\n", f.File)
+	}
+	return []byte(b.String())
+}
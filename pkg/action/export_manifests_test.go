@@ -0,0 +1,137 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/release"
+)
+
+const exportedManifest = `---
+# Source: hello/templates/config.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  labels:
+    app.kubernetes.io/managed-by: Helm
+    app.kubernetes.io/name: hello
+data:
+  color: blue
+---
+# Source: hello/templates/empty.yaml
+# Nothing is rendered when the feature is disabled.
+---
+# Source: hello/templates/web.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: shop
+`
+
+func exportManifestsConfig(t *testing.T) *Configuration {
+	t.Helper()
+	config := actionConfigFixture(t)
+	rel := releaseStub()
+	rel.Info.Status = release.StatusDeployed
+	rel.Manifest = exportedManifest
+	require.NoError(t, config.Releases.Create(rel))
+	return config
+}
+
+func TestExportManifests(t *testing.T) {
+	client := NewExportManifests(exportManifestsConfig(t))
+	client.Kustomize = true
+	dir := t.TempDir()
+
+	files, err := client.Run("angry-panda", dir)
+	require.NoError(t, err)
+	assert.Equal(t, []*ExportedManifest{
+		{File: "configmap-settings.yaml", Kind: "ConfigMap", Name: "settings"},
+		{File: "deployment-web.yaml", Kind: "Deployment", Name: "web", Namespace: "shop"},
+	}, files)
+
+	data, err := os.ReadFile(filepath.Join(dir, "configmap-settings.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `# Source: hello/templates/config.yaml
+apiVersion: v1
+data:
+  color: blue
+kind: ConfigMap
+metadata:
+  labels:
+    app.kubernetes.io/name: hello
+  name: settings
+`, string(data))
+
+	data, err = os.ReadFile(filepath.Join(dir, "kustomization.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- configmap-settings.yaml
+- deployment-web.yaml
+`, string(data))
+}
+
+func TestExportManifestsKeepOwnership(t *testing.T) {
+	client := NewExportManifests(exportManifestsConfig(t))
+	client.KeepOwnership = true
+	dir := t.TempDir()
+
+	_, err := client.Run("angry-panda", dir)
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(dir, "deployment-web.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "meta.helm.sh/release-name: angry-panda")
+	assert.Contains(t, string(data), "app.kubernetes.io/managed-by: Helm")
+	assert.NoFileExists(t, filepath.Join(dir, "kustomization.yaml"))
+
+	client.Version = 2
+	_, err = client.Run("angry-panda", dir)
+	assert.Error(t, err)
+}
+
+func TestExportManifestsInvalidFileNames(t *testing.T) {
+	for name, manifest := range map[string]string{
+		"no name":   "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  generateName: settings-\n",
+		"separator": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: ../../settings\n",
+		"dots":      "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n  namespace: ..\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := actionConfigFixture(t)
+			rel := releaseStub()
+			rel.Info.Status = release.StatusDeployed
+			rel.Manifest = manifest
+			require.NoError(t, config.Releases.Create(rel))
+
+			dir := t.TempDir()
+			_, err := NewExportManifests(config).Run(rel.Name, filepath.Join(dir, "out"))
+			assert.Error(t, err)
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/release"
+)
+
+func exportFixture(t *testing.T) *Configuration {
+	t.Helper()
+	config := actionConfigFixture(t)
+	for v := 1; v <= 4; v++ {
+		rel := releaseStub()
+		rel.Version = v
+		rel.Config = map[string]interface{}{"revision": v}
+		rel.Info.Notes = "notes"
+		rel.Info.Status = release.StatusSuperseded
+		require.NoError(t, config.Releases.Create(rel))
+	}
+	return config
+}
+
+func TestExportReleaseReleases(t *testing.T) {
+	config := exportFixture(t)
+
+	tests := map[string][]int{
+		"":    {4},
+		"0":   {4},
+		"all": {1, 2, 3, 4},
+		"2":   {2},
+		"2-3": {2, 3},
+		"3-":  {3, 4},
+		"3-9": {3, 4},
+	}
+	for revisions, want := range tests {
+		client := NewExportRelease(config)
+		client.Revisions = revisions
+		rels, err := client.Releases("angry-panda")
+		require.NoError(t, err, revisions)
+		var got []int
+		for _, rel := range rels {
+			got = append(got, rel.Version)
+		}
+		assert.Equal(t, want, got, revisions)
+	}
+
+	client := NewExportRelease(config)
+	client.Revisions = "7-"
+	_, err := client.Releases("angry-panda")
+	assert.ErrorContains(t, err, `has no revision in "7-"`)
+
+	_, err = client.Releases("missing")
+	assert.Error(t, err)
+}
+
+func TestParseRevisionRange(t *testing.T) {
+	for _, s := range []string{"0", "-3", "x", "3-2", "1-x", "1-2-3"} {
+		_, _, err := ParseRevisionRange(s)
+		assert.Error(t, err, s)
+	}
+	from, to, err := ParseRevisionRange("3-")
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 0}, []int{from, to})
+}
+
+func TestExportReleaseRun(t *testing.T) {
+	config := exportFixture(t)
+	client := NewExportRelease(config)
+	client.Revisions = "3-"
+
+	dir := t.TempDir()
+	rels, err := client.Run("angry-panda", dir)
+	require.NoError(t, err)
+	assert.Len(t, rels, 2)
+
+	files := []string{"metadata.yaml", "chart.yaml", "values.yaml", "computed-values.yaml", "manifest.yaml", "hooks.yaml", "notes.txt"}
+	for _, rev := range []string{"3", "4"} {
+		for _, f := range files {
+			assert.FileExists(t, filepath.Join(dir, "angry-panda", rev, f))
+		}
+	}
+	assert.NoDirExists(t, filepath.Join(dir, "angry-panda", "2"))
+
+	values, err := os.ReadFile(filepath.Join(dir, "angry-panda", "3", "values.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "revision: 3\n", string(values))
+	metadata, err := os.ReadFile(filepath.Join(dir, "angry-panda", "4", "metadata.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(metadata), "revision: 4\n")
+	hooks, err := os.ReadFile(filepath.Join(dir, "angry-panda", "4", "hooks.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(hooks), "# Source: test-cm\n")
+
+	archive := filepath.Join(dir, "export", "angry-panda.tgz")
+	_, err = client.Run("angry-panda", archive)
+	require.NoError(t, err)
+
+	f, err := os.Open(archive)
+	require.NoError(t, err)
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	tr := tar.NewReader(zr)
+	var names []string
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, h.Name)
+	}
+	assert.Len(t, names, 2*len(files))
+	assert.Contains(t, names, "angry-panda/4/manifest.yaml")
+}
@@ -0,0 +1,147 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+// FixOwnership is the action for repairing the ownership metadata of the
+// resources of a release.
+//
+// It provides the implementation of 'helm release fix-ownership'.
+type FixOwnership struct {
+	cfg *Configuration
+
+	// Version is the revision of the release whose resources are checked.
+	// Zero means the latest revision.
+	Version int
+	// DryRun only reports the mismatches without repairing them.
+	DryRun bool
+	// Force also repairs resources that are annotated as owned by another
+	// release.
+	Force bool
+}
+
+// OwnershipMismatch is a label or annotation of a live resource that does not
+// match the release the resource belongs to.
+type OwnershipMismatch struct {
+	ResourceConflict
+	// Conflict reports that the resource is annotated as owned by another
+	// release. Such resources are only repaired with Force.
+	Conflict bool `json:"conflict,omitempty"`
+	// Repaired reports whether the mismatch was repaired.
+	Repaired bool `json:"repaired"`
+}
+
+// NewFixOwnership creates a new FixOwnership object with the given configuration.
+func NewFixOwnership(cfg *Configuration) *FixOwnership {
+	return &FixOwnership{
+		cfg: cfg,
+	}
+}
+
+// Run checks the ownership metadata of the live resources of the release and
+// repairs the mismatches, unless DryRun is set. Resources of the release that
+// do not exist in the cluster are ignored.
+func (f *FixOwnership) Run(name string) ([]*OwnershipMismatch, error) {
+	if err := f.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+
+	rel, err := f.cfg.releaseContent(name, f.Version)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := f.cfg.KubeClient.Build(bytes.NewBufferString(rel.Manifest), false)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to build kubernetes objects from release manifest")
+	}
+	return fixOwnership(resources, rel.Name, rel.Namespace, f.DryRun, f.Force)
+}
+
+// fixOwnership compares the ownership metadata of the live resources with the
+// release and patches the mismatched labels and annotations.
+func fixOwnership(resources kube.ResourceList, releaseName, releaseNamespace string, dryRun, force bool) ([]*OwnershipMismatch, error) {
+	var mismatches []*OwnershipMismatch
+
+	err := resources.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+
+		helper := resource.NewHelper(info.Client, info.Mapping)
+		existing, err := helper.Get(info.Namespace, info.Name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			return errors.Wrapf(err, "could not get information about the resource %s", resourceString(info))
+		}
+		conflicts, err := ownershipConflicts(info, existing, releaseName, releaseNamespace)
+		if err != nil {
+			return err
+		}
+		conflict := false
+		for _, c := range conflicts {
+			conflict = conflict || c.Reason == ConflictOwnedByOtherRelease
+		}
+		var found []*OwnershipMismatch
+		for _, c := range conflicts {
+			found = append(found, &OwnershipMismatch{ResourceConflict: c, Conflict: conflict})
+		}
+		mismatches = append(mismatches, found...)
+
+		if len(found) == 0 || dryRun || (conflict && !force) {
+			return nil
+		}
+		if _, err := helper.Patch(info.Namespace, info.Name, types.MergePatchType, ownershipPatch(releaseName, releaseNamespace), nil); err != nil {
+			return errors.Wrapf(err, "could not repair the ownership metadata of %s", resourceString(info))
+		}
+		for _, m := range found {
+			m.Repaired = true
+		}
+		return nil
+	})
+
+	return mismatches, err
+}
+
+// ownershipPatch returns a JSON merge patch that sets the ownership metadata,
+// leaving the other labels and annotations untouched.
+func ownershipPatch(releaseName, releaseNamespace string) []byte {
+	patch, _ := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]string{
+				appManagedByLabel: appManagedByHelm,
+			},
+			"annotations": map[string]string{
+				helmReleaseNameAnnotation:      releaseName,
+				helmReleaseNamespaceAnnotation: releaseNamespace,
+			},
+		},
+	})
+	return patch
+}
@@ -0,0 +1,132 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest/fake"
+
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+// newPatchableDeployment returns a live deployment whose patches are recorded.
+func newPatchableDeployment(name string, labels, annotations map[string]string, patches *[]string) *resource.Info {
+	obj := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        name,
+			Namespace:   "ns-a",
+			Labels:      labels,
+			Annotations: annotations,
+		},
+	}
+	body := runtime.EncodeOrDie(appsv1Codec, obj)
+	return &resource.Info{
+		Name:      name,
+		Namespace: "ns-a",
+		Mapping: &meta.RESTMapping{
+			Resource:         schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployment"},
+			GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Scope:            meta.RESTScopeNamespace,
+		},
+		Object: obj,
+		Client: &fake.RESTClient{
+			GroupVersion:         appsV1GV,
+			NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+			Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+				if req.Method == http.MethodPatch {
+					data, _ := io.ReadAll(req.Body)
+					*patches = append(*patches, name+" "+req.Header.Get("Content-Type")+" "+string(data))
+				}
+				header := http.Header{}
+				header.Set("Content-Type", runtime.ContentTypeJSON)
+				return &http.Response{StatusCode: http.StatusOK, Header: header, Body: stringBody(body)}, nil
+			}),
+		},
+	}
+}
+
+func TestFixOwnership(t *testing.T) {
+	var patches []string
+	owned := map[string]string{
+		helmReleaseNameAnnotation:      "rel",
+		helmReleaseNamespaceAnnotation: "rel-ns",
+	}
+	resources := kube.ResourceList{
+		newMissingDeployment("missing", "ns-a"),
+		newPatchableDeployment("ok", map[string]string{appManagedByLabel: appManagedByHelm}, owned, &patches),
+		newPatchableDeployment("unlabeled", map[string]string{"app": "web"}, owned, &patches),
+		newPatchableDeployment("restored", nil, nil, &patches),
+		newPatchableDeployment("other", map[string]string{appManagedByLabel: appManagedByHelm}, map[string]string{
+			helmReleaseNameAnnotation:      "other",
+			helmReleaseNamespaceAnnotation: "rel-ns",
+		}, &patches),
+	}
+
+	mismatches, err := fixOwnership(resources, "rel", "rel-ns", true, false)
+	require.NoError(t, err)
+	assert.Empty(t, patches, "expected no patches in a dry run")
+
+	type row struct {
+		name, key, current string
+		conflict           bool
+	}
+	var rows []row
+	for _, m := range mismatches {
+		assert.False(t, m.Repaired)
+		rows = append(rows, row{m.Name, m.Key, m.Current, m.Conflict})
+	}
+	assert.Equal(t, []row{
+		{"unlabeled", appManagedByLabel, "", false},
+		{"restored", appManagedByLabel, "", false},
+		{"restored", helmReleaseNameAnnotation, "", false},
+		{"restored", helmReleaseNamespaceAnnotation, "", false},
+		{"other", helmReleaseNameAnnotation, "other", true},
+	}, rows)
+
+	mismatches, err = fixOwnership(resources, "rel", "rel-ns", false, false)
+	require.NoError(t, err)
+	patch := `{"metadata":{"annotations":{"meta.helm.sh/release-name":"rel","meta.helm.sh/release-namespace":"rel-ns"},"labels":{"app.kubernetes.io/managed-by":"Helm"}}}`
+	assert.Equal(t, []string{
+		"unlabeled application/merge-patch+json " + patch,
+		"restored application/merge-patch+json " + patch,
+	}, patches, "expected resources owned by another release to be skipped")
+	for _, m := range mismatches {
+		assert.Equal(t, !m.Conflict, m.Repaired, "%s %s", m.Name, m.Key)
+	}
+
+	patches = nil
+	_, err = fixOwnership(resources, "rel", "rel-ns", false, true)
+	require.NoError(t, err)
+	assert.Len(t, patches, 3)
+}
+
+func TestFixOwnershipReleaseNotFound(t *testing.T) {
+	_, err := NewFixOwnership(actionConfigFixture(t)).Run("missing")
+	assert.Error(t, err)
+}
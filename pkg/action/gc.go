@@ -0,0 +1,269 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/pkg/kube"
+	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/storage"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// OrphanReason is the reason why a storage record is orphaned.
+type OrphanReason string
+
+const (
+	// OrphanCorrupted means that the record cannot be decoded into a release,
+	// or that the release does not match the record.
+	OrphanCorrupted OrphanReason = "Corrupted"
+	// OrphanNamespaceDeleted means that the namespace of the release no
+	// longer exists.
+	OrphanNamespaceDeleted OrphanReason = "NamespaceDeleted"
+	// OrphanInterrupted means that the revision is still pending although a
+	// later revision exists, e.g. because the operation was interrupted.
+	OrphanInterrupted OrphanReason = "Interrupted"
+	// OrphanHistoryLimit means that the revision is beyond the maximum number
+	// of revisions kept for a release.
+	OrphanHistoryLimit OrphanReason = "HistoryLimit"
+)
+
+// GarbageCollect is the action for finding and deleting orphaned release
+// records in the storage backend.
+//
+// It provides the implementation of 'helm release gc'.
+type GarbageCollect struct {
+	cfg *Configuration
+
+	// MaxHistory is the maximum number of revisions kept for a release. The
+	// oldest revisions beyond it are orphaned, except the deployed revision.
+	// Zero means no limit.
+	MaxHistory int
+	// DryRun only reports the orphaned records without deleting them.
+	DryRun bool
+}
+
+// OrphanedRecord is a storage record that no longer belongs to a usable
+// revision of a release.
+type OrphanedRecord struct {
+	// Key is the name of the record in the storage backend, e.g. the name of
+	// the Secret.
+	Key       string         `json:"key"`
+	Name      string         `json:"name,omitempty"`
+	Namespace string         `json:"namespace,omitempty"`
+	Revision  int            `json:"revision,omitempty"`
+	Status    release.Status `json:"status,omitempty"`
+	Reason    OrphanReason   `json:"reason"`
+	Message   string         `json:"message"`
+	// Deleted reports whether the record was deleted.
+	Deleted bool `json:"deleted"`
+}
+
+// NewGarbageCollect creates a new GarbageCollect object with the given
+// configuration.
+func NewGarbageCollect(cfg *Configuration) *GarbageCollect {
+	return &GarbageCollect{
+		cfg: cfg,
+	}
+}
+
+// Run scans the storage backend for orphaned records and deletes them, unless
+// DryRun is set.
+//
+// Records that cannot be decoded are only found with storage drivers that
+// implement driver.Scanner, such as the Secret and ConfigMap drivers.
+func (g *GarbageCollect) Run() ([]*OrphanedRecord, error) {
+	if err := g.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+
+	records, err := scanRecords(g.cfg.Releases.Driver)
+	if err != nil {
+		return nil, err
+	}
+	orphans := g.findOrphans(records)
+	if g.DryRun {
+		return orphans, nil
+	}
+
+	var errs []error
+	for _, o := range orphans {
+		if err := deleteRecord(g.cfg.Releases.Driver, o.Key); err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+			errs = append(errs, errors.Wrapf(err, "failed to delete %s", o.Key))
+			continue
+		}
+		g.cfg.Log("deleted orphaned record %s: %s", o.Key, o.Message)
+		o.Deleted = true
+	}
+	if len(errs) > 0 {
+		return orphans, errors.Errorf("garbage collection completed with %d error(s): %s", len(errs), joinErrors(errs))
+	}
+	return orphans, nil
+}
+
+// findOrphans classifies the records. Corrupted records and the records of
+// releases in deleted namespaces are orphaned as a whole, the others are
+// checked per release.
+func (g *GarbageCollect) findOrphans(records []*driver.Record) []*OrphanedRecord {
+	var orphans []*OrphanedRecord
+	namespaces := map[string]bool{}
+	history := map[string][]*driver.Record{}
+
+	for _, rec := range records {
+		if rec.Err != nil {
+			o := &OrphanedRecord{
+				Key:     rec.Key,
+				Name:    rec.Labels["name"],
+				Reason:  OrphanCorrupted,
+				Message: fmt.Sprintf("the release cannot be decoded: %s", rec.Err),
+			}
+			o.Revision, _ = strconv.Atoi(rec.Labels["version"])
+			orphans = append(orphans, o)
+			continue
+		}
+
+		rel := rec.Release
+		switch {
+		case rel.Info == nil:
+			orphans = append(orphans, newOrphanedRecord(rec, OrphanCorrupted, "the release has no info"))
+		case rec.Key != recordKey(rel.Name, rel.Version):
+			orphans = append(orphans, newOrphanedRecord(rec, OrphanCorrupted,
+				fmt.Sprintf("the record holds revision %d of release %q", rel.Version, rel.Name)))
+		case !g.namespaceExists(namespaces, rel.Namespace):
+			orphans = append(orphans, newOrphanedRecord(rec, OrphanNamespaceDeleted,
+				fmt.Sprintf("namespace %q no longer exists", rel.Namespace)))
+		default:
+			id := rel.Namespace + "/" + rel.Name
+			history[id] = append(history[id], rec)
+		}
+	}
+
+	for _, h := range history {
+		orphans = append(orphans, g.findOrphanedRevisions(h)...)
+	}
+
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i].Key < orphans[j].Key })
+	return orphans
+}
+
+// findOrphanedRevisions finds the interrupted revisions of a release and the
+// revisions beyond MaxHistory. The latest and the deployed revisions are kept.
+func (g *GarbageCollect) findOrphanedRevisions(h []*driver.Record) []*OrphanedRecord {
+	sort.Slice(h, func(i, j int) bool { return h[i].Release.Version < h[j].Release.Version })
+
+	latest := h[len(h)-1].Release.Version
+	deployed := 0
+	for _, rec := range h {
+		if rec.Release.Info.Status == release.StatusDeployed {
+			deployed = rec.Release.Version
+		}
+	}
+
+	var orphans []*OrphanedRecord
+	var remaining []*release.Release
+	byVersion := map[int]*driver.Record{}
+	for _, rec := range h {
+		rel := rec.Release
+		if rel.Version != latest && rel.Version != deployed && rel.Info.Status.IsPending() {
+			orphans = append(orphans, newOrphanedRecord(rec, OrphanInterrupted,
+				fmt.Sprintf("the revision is %s although revision %d exists", rel.Info.Status, latest)))
+			continue
+		}
+		remaining = append(remaining, rel)
+		byVersion[rel.Version] = rec
+	}
+	for _, rel := range storage.ExcessRevisions(remaining, g.MaxHistory) {
+		orphans = append(orphans, newOrphanedRecord(byVersion[rel.Version], OrphanHistoryLimit,
+			fmt.Sprintf("the release keeps at most %d revision(s)", g.MaxHistory)))
+	}
+	return orphans
+}
+
+// namespaceExists reports whether a namespace exists, caching the result.
+// Namespaces are assumed to exist when the Kubernetes client cannot tell.
+func (g *GarbageCollect) namespaceExists(cache map[string]bool, namespace string) bool {
+	if namespace == "" {
+		return true
+	}
+	if exists, ok := cache[namespace]; ok {
+		return exists
+	}
+	exists, err := g.cfg.KubeClient.NamespaceExists(namespace)
+	if err != nil {
+		if !errors.Is(err, kube.ErrNotSupported) {
+			g.cfg.Log("unable to check namespace %q: %s", namespace, err)
+		}
+		exists = true
+	}
+	cache[namespace] = exists
+	return exists
+}
+
+func newOrphanedRecord(rec *driver.Record, reason OrphanReason, message string) *OrphanedRecord {
+	o := &OrphanedRecord{
+		Key:       rec.Key,
+		Name:      rec.Release.Name,
+		Namespace: rec.Release.Namespace,
+		Revision:  rec.Release.Version,
+		Reason:    reason,
+		Message:   message,
+	}
+	if rec.Release.Info != nil {
+		o.Status = rec.Release.Info.Status
+	}
+	return o
+}
+
+// scanRecords lists all records of the storage driver. Drivers that do not
+// implement driver.Scanner only return the records that can be decoded.
+func scanRecords(d driver.Driver) ([]*driver.Record, error) {
+	if s, ok := d.(driver.Scanner); ok {
+		return s.Scan()
+	}
+	rels, err := d.List(func(*release.Release) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*driver.Record, 0, len(rels))
+	for _, rel := range rels {
+		records = append(records, &driver.Record{
+			Key:     recordKey(rel.Name, rel.Version),
+			Labels:  rel.Labels,
+			Release: rel,
+		})
+	}
+	return records, nil
+}
+
+func deleteRecord(d driver.Driver, key string) error {
+	if s, ok := d.(driver.Scanner); ok {
+		return s.DeleteRecord(key)
+	}
+	_, err := d.Delete(key)
+	return err
+}
+
+// recordKey returns the key of the record of a release revision, which is
+// the same for all storage drivers.
+func recordKey(name string, version int) string {
+	return fmt.Sprintf("%s.%s.v%d", storage.HelmStorageType, name, version)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+func TestGarbageCollect(t *testing.T) {
+	is := assert.New(t)
+	config := actionConfigFixture(t)
+	config.KubeClient.(*kubefake.FailingKubeClient).MissingNamespaces = []string{"gone"}
+
+	create := func(name, namespace string, version int, status release.Status) *release.Release {
+		rel := namedReleaseStub(name, status)
+		rel.Namespace = namespace
+		rel.Version = version
+		require.NoError(t, config.Releases.Create(rel))
+		return rel
+	}
+	for v := 1; v <= 4; v++ {
+		create("kept", "default", v, release.StatusSuperseded)
+	}
+	create("kept", "default", 5, release.StatusDeployed)
+	create("kept", "default", 6, release.StatusPendingUpgrade)
+	create("stuck", "default", 1, release.StatusDeployed)
+	create("stuck", "default", 2, release.StatusPendingUpgrade)
+	create("stuck", "default", 3, release.StatusFailed)
+	create("abandoned", "gone", 1, release.StatusDeployed)
+	// the memory driver keeps the release, so this corrupts the stored record
+	create("broken", "default", 1, release.StatusDeployed).Info = nil
+
+	misplaced := namedReleaseStub("misplaced", release.StatusDeployed)
+	require.NoError(t, config.Releases.Driver.Create("sh.helm.release.v1.other.v1", misplaced))
+	// scan all namespaces
+	config.Releases.Driver.(*driver.Memory).SetNamespace("")
+
+	client := NewGarbageCollect(config)
+	client.MaxHistory = 3
+	client.DryRun = true
+	orphans, err := client.Run()
+	require.NoError(t, err)
+
+	reasons := map[string]OrphanReason{}
+	for _, o := range orphans {
+		is.False(o.Deleted)
+		reasons[o.Key] = o.Reason
+	}
+	is.Equal(map[string]OrphanReason{
+		"sh.helm.release.v1.abandoned.v1": OrphanNamespaceDeleted,
+		"sh.helm.release.v1.broken.v1":    OrphanCorrupted,
+		"sh.helm.release.v1.kept.v1":      OrphanHistoryLimit,
+		"sh.helm.release.v1.kept.v2":      OrphanHistoryLimit,
+		"sh.helm.release.v1.kept.v3":      OrphanHistoryLimit,
+		"sh.helm.release.v1.other.v1":     OrphanCorrupted,
+		"sh.helm.release.v1.stuck.v2":     OrphanInterrupted,
+	}, reasons)
+
+	rels, err := config.Releases.ListReleases()
+	require.NoError(t, err)
+	is.Len(rels, 12, "dry run must not delete records")
+
+	client.DryRun = false
+	orphans, err = client.Run()
+	require.NoError(t, err)
+	is.Len(orphans, 7)
+	for _, o := range orphans {
+		is.True(o.Deleted, o.Key)
+	}
+
+	rels, err = config.Releases.ListReleases()
+	require.NoError(t, err)
+	var kept []string
+	for _, rel := range rels {
+		kept = append(kept, recordKey(rel.Name, rel.Version))
+	}
+	is.ElementsMatch([]string{
+		"sh.helm.release.v1.kept.v4",
+		"sh.helm.release.v1.kept.v5",
+		"sh.helm.release.v1.kept.v6",
+		"sh.helm.release.v1.stuck.v1",
+		"sh.helm.release.v1.stuck.v3",
+	}, kept)
+
+	orphans, err = client.Run()
+	require.NoError(t, err)
+	is.Empty(orphans)
+}
@@ -17,6 +17,13 @@ limitations under the License.
 package action
 
 import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/redact"
 	"helm.sh/helm/v4/pkg/release"
 )
 
@@ -28,6 +35,8 @@ type Get struct {
 
 	// Initializing Version to 0 will get the latest revision of the release.
 	Version int
+	// Redactors are applied to the manifest and hooks of the returned release.
+	Redactors []redact.Redactor
 }
 
 // NewGet creates a new Get object with the given configuration.
@@ -43,5 +52,81 @@ func (g *Get) Run(name string) (*release.Release, error) {
 		return nil, err
 	}
 
-	return g.cfg.releaseContent(name, g.Version)
+	rel, err := g.cfg.releaseContent(name, g.Version)
+	if err != nil {
+		return nil, err
+	}
+	return redactRelease(rel, g.Redactors)
+}
+
+// redactRelease returns a copy of the release with the redactors applied to
+// its manifest, its hooks and the resources fetched from the cluster. The
+// stored release is left untouched.
+func redactRelease(rel *release.Release, redactors []redact.Redactor) (*release.Release, error) {
+	if len(redactors) == 0 {
+		return rel, nil
+	}
+
+	out := *rel
+	manifest, err := redact.Manifest(rel.Manifest, redactors...)
+	if err != nil {
+		return nil, err
+	}
+	out.Manifest = manifest
+
+	out.Hooks = make([]*release.Hook, len(rel.Hooks))
+	for i, h := range rel.Hooks {
+		hook := *h
+		if hook.Manifest, err = redact.Manifest(h.Manifest, redactors...); err != nil {
+			return nil, err
+		}
+		out.Hooks[i] = &hook
+	}
+
+	if rel.Info != nil && rel.Info.Resources != nil {
+		info := *rel.Info
+		info.Resources = make(map[string][]runtime.Object, len(rel.Info.Resources))
+		for kind, objs := range rel.Info.Resources {
+			var redacted []runtime.Object
+			for _, obj := range objs {
+				r, err := redactObject(obj, redactors)
+				if err != nil {
+					return nil, err
+				}
+				if r != nil {
+					redacted = append(redacted, r)
+				}
+			}
+			info.Resources[kind] = redacted
+		}
+		out.Info = &info
+	}
+	return &out, nil
+}
+
+// redactObject applies the redactors to an object fetched from the cluster.
+// It returns the object as is when the redactors leave it untouched, and nil
+// when a redactor hides it.
+func redactObject(obj runtime.Object, redactors []redact.Redactor) (runtime.Object, error) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	b, err := yaml.Marshal(u)
+	if err != nil {
+		return nil, err
+	}
+	doc := strings.TrimSuffix(string(b), "\n")
+	redacted, err := redact.Document(doc, redactors...)
+	if err != nil || redacted == doc {
+		return obj, err
+	}
+	var out map[string]interface{}
+	if err := yaml.Unmarshal([]byte(redacted), &out); err != nil {
+		return nil, err
+	}
+	if out == nil {
+		return nil, nil
+	}
+	return &unstructured.Unstructured{Object: out}, nil
 }
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/release"
 )
 
 // GetMetadata is the action for checking a given release's metadata.
@@ -64,6 +65,10 @@ func (g *GetMetadata) Run(name string) (*Metadata, error) {
 		return nil, err
 	}
 
+	return newMetadata(rel), nil
+}
+
+func newMetadata(rel *release.Release) *Metadata {
 	return &Metadata{
 		Name:         rel.Name,
 		Chart:        rel.Chart.Metadata.Name,
@@ -75,7 +80,7 @@ func (g *GetMetadata) Run(name string) (*Metadata, error) {
 		Revision:     rel.Version,
 		Status:       rel.Info.Status.String(),
 		DeployedAt:   rel.Info.LastDeployed.Format(time.RFC3339),
-	}, nil
+	}
 }
 
 // FormattedDepNames formats metadata.dependencies names into a comma-separated list.
@@ -0,0 +1,64 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"helm.sh/helm/v4/pkg/redact"
+)
+
+func TestRedactReleaseResources(t *testing.T) {
+	secret := &v1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: "creds"},
+		Data:       map[string][]byte{"password": []byte("hunter2")},
+	}
+	cm := &v1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: "config"},
+		Data:       map[string]string{"password": "not-a-secret"},
+	}
+	rel := releaseStub()
+	rel.Hooks = nil
+	rel.Info.Resources = map[string][]runtime.Object{
+		"v1/Secret":    {secret},
+		"v1/ConfigMap": {cm},
+	}
+
+	redacted, err := redactRelease(rel, []redact.Redactor{redact.NewSecretRedactor(redact.ModeKeys)})
+	require.NoError(t, err)
+	require.Len(t, redacted.Info.Resources["v1/Secret"], 1)
+	obj, ok := redacted.Info.Resources["v1/Secret"][0].(*unstructured.Unstructured)
+	require.True(t, ok, "expected the redacted Secret to be unstructured")
+	data, _, _ := unstructured.NestedStringMap(obj.Object, "data")
+	assert.Equal(t, map[string]string{"password": redact.RedactedValue}, data)
+	assert.Same(t, cm, redacted.Info.Resources["v1/ConfigMap"][0], "objects the redactors do not change must be kept")
+	assert.Same(t, secret, rel.Info.Resources["v1/Secret"][0], "the release must not be modified")
+
+	redacted, err = redactRelease(rel, []redact.Redactor{redact.NewSecretRedactor(redact.ModeHide)})
+	require.NoError(t, err)
+	assert.Empty(t, redacted.Info.Resources["v1/Secret"])
+	assert.Len(t, redacted.Info.Resources["v1/ConfigMap"], 1)
+}
@@ -17,6 +17,10 @@ limitations under the License.
 package action
 
 import (
+	"reflect"
+
+	"sigs.k8s.io/yaml"
+
 	"helm.sh/helm/v4/pkg/chartutil"
 )
 
@@ -39,22 +43,44 @@ func NewGetValues(cfg *Configuration) *GetValues {
 
 // Run executes 'helm get values' against the given release.
 func (g *GetValues) Run(name string) (map[string]interface{}, error) {
+	vals, _, err := g.RunRaw(name)
+	return vals, err
+}
+
+// RunRaw executes 'helm get values' like Run, and also returns the values
+// file supplied by the user as written, with its anchors, when it was stored
+// with the release. The raw values are empty otherwise and for AllValues.
+func (g *GetValues) RunRaw(name string) (map[string]interface{}, string, error) {
 	if err := g.cfg.KubeClient.IsReachable(); err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	rel, err := g.cfg.releaseContent(name, g.Version)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// If the user wants all values, compute the values and return.
 	if g.AllValues {
 		cfg, err := chartutil.CoalesceValues(rel.Chart, rel.Config)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
-		return cfg, nil
+		return cfg, "", nil
+	}
+	return rel.Config, rel.ConfigRaw, nil
+}
+
+// rawValues returns the raw values file to store with a release whose values
+// are vals: the file when it parses to vals, or nothing when the values were
+// merged with other values the file does not hold.
+func rawValues(raw []byte, vals map[string]interface{}) string {
+	if len(raw) == 0 || len(vals) == 0 {
+		return ""
+	}
+	parsed := map[string]interface{}{}
+	if err := yaml.Unmarshal(raw, &parsed); err != nil || !reflect.DeepEqual(parsed, vals) {
+		return ""
 	}
-	return rel.Config, nil
+	return string(raw)
 }
@@ -17,10 +17,20 @@ limitations under the License.
 package action
 
 import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
 	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
 
+	"helm.sh/helm/v4/pkg/chart"
 	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/redact"
 	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/releaseutil"
+	helmtime "helm.sh/helm/v4/pkg/time"
 )
 
 // History is the action for checking the release's ledger.
@@ -34,6 +44,78 @@ type History struct {
 
 	Max     int
 	Version int
+	// AllValues compares the computed values of each revision in Diff rather
+	// than only the user-supplied values.
+	AllValues bool
+	// Redactors are applied to the manifests compared by Diff. When none is
+	// set, the data of the Secrets is replaced with its SHA-256, so that its
+	// changes are shown without revealing it.
+	Redactors []redact.Redactor
+}
+
+// StringChange holds the old and new value of a scalar release attribute.
+type StringChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ManifestChange describes the difference of a single rendered resource
+// between two revisions.
+type ManifestChange struct {
+	// Resource identifies the object as Kind/name or Kind/namespace/name.
+	Resource string             `json:"resource"`
+	Type     chartutil.DiffType `json:"type"`
+	// Diff is a unified diff of the resource manifest.
+	Diff string `json:"diff"`
+}
+
+// RevisionDiff is the result of comparing two revisions of a release.
+type RevisionDiff struct {
+	Name string `json:"name"`
+	From int    `json:"from"`
+	To   int    `json:"to"`
+	// Chart is set when the chart name or version differs.
+	Chart *StringChange `json:"chart,omitempty"`
+	// AppVersion is set when the chart app version differs.
+	AppVersion *StringChange `json:"app_version,omitempty"`
+	// Values lists the changed value paths. Sensitive keys are redacted.
+	Values    []chartutil.ValueDiff `json:"values,omitempty"`
+	Manifests []ManifestChange      `json:"manifests,omitempty"`
+	// ValuesSources lists the recorded inputs of the values that only one
+	// of the revisions was given, see release.ValuesSource.
+	ValuesSources []ValuesSourceChange `json:"values_sources,omitempty"`
+}
+
+// ValuesSourceChange is an input of the values, e.g. a values file, that was
+// added or removed between two revisions.
+type ValuesSourceChange struct {
+	// Source is the input as a command line argument, with the digest of a
+	// values file.
+	Source string             `json:"source"`
+	Type   chartutil.DiffType `json:"type"`
+}
+
+// ChangelogEntry describes what changed in a revision of a release compared
+// with the previous revision.
+type ChangelogEntry struct {
+	Revision int            `json:"revision"`
+	Updated  helmtime.Time  `json:"updated"`
+	Status   release.Status `json:"status"`
+	// Chart is set when the chart name or version changed. For the first
+	// revision, From is empty.
+	Chart *StringChange `json:"chart,omitempty"`
+	// AppVersion is set when the chart app version changed.
+	AppVersion *StringChange `json:"app_version,omitempty"`
+	// Values lists the changed value paths. The values themselves are left
+	// out, so that the changelog can be shared.
+	Values []chartutil.ValueDiff `json:"values,omitempty"`
+	// Operator is the user or service account that created the revision, if
+	// it was recorded.
+	Operator string `json:"operator,omitempty"`
+	// ClientVersion is the version of the Helm client that created the
+	// revision, if it was recorded.
+	ClientVersion string `json:"client_version,omitempty"`
+	Description   string `json:"description,omitempty"`
 }
 
 // NewHistory creates a new History object with the given configuration.
@@ -45,6 +127,12 @@ func NewHistory(cfg *Configuration) *History {
 
 // Run executes 'helm history' against the given release.
 func (h *History) Run(name string) ([]*release.Release, error) {
+	return h.RunWithContext(context.Background(), name)
+}
+
+// RunWithContext is like Run, but ctx cancels the requests to the storage
+// backend.
+func (h *History) RunWithContext(ctx context.Context, name string) ([]*release.Release, error) {
 	if err := h.cfg.KubeClient.IsReachable(); err != nil {
 		return nil, err
 	}
@@ -54,5 +142,248 @@ func (h *History) Run(name string) ([]*release.Release, error) {
 	}
 
 	h.cfg.Log("getting history for release %s", name)
-	return h.cfg.Releases.History(name)
+	return h.cfg.Releases.HistoryContext(ctx, name)
+}
+
+// Diff compares two revisions of the given release.
+//
+// It reports changes to the chart version, a structured diff of the values
+// and a unified diff for each resource in the manifests that differs.
+func (h *History) Diff(name string, from, to int) (*RevisionDiff, error) {
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, errors.Errorf("release name is invalid: %s", name)
+	}
+	if from <= 0 || to <= 0 {
+		return nil, errInvalidRevision
+	}
+
+	h.cfg.Log("comparing revisions %d and %d of release %s", from, to, name)
+	older, err := h.cfg.Releases.Get(name, from)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get revision %d", from)
+	}
+	newer, err := h.cfg.Releases.Get(name, to)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get revision %d", to)
+	}
+
+	d := &RevisionDiff{Name: name, From: from, To: to}
+
+	if a, b := chartRef(older.Chart), chartRef(newer.Chart); a != b {
+		d.Chart = &StringChange{From: a, To: b}
+	}
+	if a, b := appVersion(older.Chart), appVersion(newer.Chart); a != b {
+		d.AppVersion = &StringChange{From: a, To: b}
+	}
+
+	oldVals, newVals := older.Config, newer.Config
+	if h.AllValues {
+		if oldVals, err = chartutil.CoalesceValues(older.Chart, older.Config); err != nil {
+			return nil, err
+		}
+		if newVals, err = chartutil.CoalesceValues(newer.Chart, newer.Config); err != nil {
+			return nil, err
+		}
+	}
+	d.Values = chartutil.DiffValues(oldVals, newVals)
+	d.ValuesSources = diffValuesSources(older.ValuesSources, newer.ValuesSources)
+
+	redactors := h.Redactors
+	if len(redactors) == 0 {
+		redactors = []redact.Redactor{redact.NewSecretRedactor(redact.ModeHash)}
+	}
+	oldManifest, err := redact.Manifest(older.Manifest, redactors...)
+	if err != nil {
+		return nil, err
+	}
+	newManifest, err := redact.Manifest(newer.Manifest, redactors...)
+	if err != nil {
+		return nil, err
+	}
+	d.Manifests, err = diffManifests(oldManifest, newManifest, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Changelog generates a changelog of the given release from its history,
+// newest revision first. Every entry is compared with the previous revision.
+// At most Max entries are returned when Max is set.
+func (h *History) Changelog(name string) ([]*ChangelogEntry, error) {
+	hist, err := h.Run(name)
+	if err != nil {
+		return nil, err
+	}
+	releaseutil.SortByRevision(hist)
+
+	entries := make([]*ChangelogEntry, 0, len(hist))
+	var previous *release.Release
+	for _, r := range hist {
+		e := &ChangelogEntry{
+			Revision:      r.Version,
+			Updated:       r.Info.LastDeployed,
+			Status:        r.Info.Status,
+			Operator:      r.Info.Operator,
+			ClientVersion: r.Info.ClientVersion,
+			Description:   r.Info.Description,
+		}
+
+		var oldChart *chart.Chart
+		var oldVals map[string]interface{}
+		if previous != nil {
+			oldChart, oldVals = previous.Chart, previous.Config
+		}
+		if a, b := chartRef(oldChart), chartRef(r.Chart); a != b {
+			e.Chart = &StringChange{From: a, To: b}
+		}
+		if a, b := appVersion(oldChart), appVersion(r.Chart); a != b {
+			e.AppVersion = &StringChange{From: a, To: b}
+		}
+
+		newVals := r.Config
+		if h.AllValues {
+			if previous != nil {
+				if oldVals, err = chartutil.CoalesceValues(previous.Chart, previous.Config); err != nil {
+					return nil, err
+				}
+			}
+			if newVals, err = chartutil.CoalesceValues(r.Chart, r.Config); err != nil {
+				return nil, err
+			}
+		}
+		for _, v := range chartutil.DiffValues(oldVals, newVals) {
+			e.Values = append(e.Values, chartutil.ValueDiff{Path: v.Path, Type: v.Type})
+		}
+
+		entries = append(entries, e)
+		previous = r
+	}
+
+	slices.Reverse(entries)
+	if h.Max > 0 && len(entries) > h.Max {
+		entries = entries[:h.Max]
+	}
+	return entries, nil
+}
+
+// diffValuesSources returns the inputs of the values only in a or in b. A
+// values file whose content changed is both removed and added, as its digest
+// changed.
+func diffValuesSources(a, b []*release.ValuesSource) []ValuesSourceChange {
+	count := map[string]int{}
+	for _, s := range a {
+		count[s.String()]++
+	}
+	var changes []ValuesSourceChange
+	for _, s := range b {
+		if count[s.String()] > 0 {
+			count[s.String()]--
+			continue
+		}
+		changes = append(changes, ValuesSourceChange{Source: s.String(), Type: chartutil.DiffAdded})
+	}
+	for _, s := range a {
+		if count[s.String()] > 0 {
+			count[s.String()]--
+			changes = append(changes, ValuesSourceChange{Source: s.String(), Type: chartutil.DiffRemoved})
+		}
+	}
+	return changes
+}
+
+func chartRef(c *chart.Chart) string {
+	if c == nil || c.Metadata == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s-%s", c.Name(), c.Metadata.Version)
+}
+
+func appVersion(c *chart.Chart) string {
+	if c == nil {
+		return ""
+	}
+	return c.AppVersion()
+}
+
+// diffManifests splits both manifests into resources and produces a unified
+// diff for every resource that was added, removed or changed.
+func diffManifests(a, b string, from, to int) ([]ManifestChange, error) {
+	oldRes, err := manifestsByResource(a)
+	if err != nil {
+		return nil, err
+	}
+	newRes, err := manifestsByResource(b)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[string]struct{}{}
+	for k := range oldRes {
+		keys[k] = struct{}{}
+	}
+	for k := range newRes {
+		keys[k] = struct{}{}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var changes []ManifestChange
+	for _, k := range sorted {
+		o, inOld := oldRes[k]
+		n, inNew := newRes[k]
+		if o == n {
+			continue
+		}
+		ct := chartutil.DiffChanged
+		switch {
+		case !inOld:
+			ct = chartutil.DiffAdded
+		case !inNew:
+			ct = chartutil.DiffRemoved
+		}
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitDiffLines(o),
+			B:        splitDiffLines(n),
+			FromFile: fmt.Sprintf("revision %d", from),
+			ToFile:   fmt.Sprintf("revision %d", to),
+			Context:  3,
+		})
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, ManifestChange{Resource: k, Type: ct, Diff: diff})
+	}
+	return changes, nil
+}
+
+func splitDiffLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return difflib.SplitLines(s)
+}
+
+// manifestsByResource indexes the documents of a manifest by resource identity.
+func manifestsByResource(manifest string) (map[string]string, error) {
+	manifests, err := releaseutil.ParseManifests(manifest)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse release manifest")
+	}
+	res := map[string]string{}
+	for _, m := range manifests {
+		kind, name, namespace := m.Head.Kind, m.Head.ObjectName(), m.Head.ObjectNamespace()
+		if kind == "" && name == "" {
+			continue
+		}
+		key := kind + "/" + name
+		if namespace != "" {
+			key = kind + "/" + namespace + "/" + name
+		}
+		res[key] = m.Content
+	}
+	return res, nil
 }
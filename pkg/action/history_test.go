@@ -0,0 +1,190 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chartutil"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	"helm.sh/helm/v4/pkg/redact"
+	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+func TestHistoryDiff(t *testing.T) {
+	is := assert.New(t)
+	config := actionConfigFixture(t)
+
+	rel1 := releaseStub()
+	rel1.Name = "diffy"
+	rel1.Version = 1
+	rel1.Info.Status = release.StatusSuperseded
+	rel1.Config = map[string]interface{}{
+		"image":   map[string]interface{}{"tag": "1.0"},
+		"debug":   true,
+		"service": map[string]interface{}{"port": 80},
+	}
+	rel1.Manifest = "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cfg\ndata:\n  a: b\n---\napiVersion: v1\nkind: Service\nmetadata:\n  name: svc\n"
+	rel1.ValuesSources = []*release.ValuesSource{
+		{Flag: "values", Value: "values.yaml", Digest: "sha256:aaaa"},
+		{Flag: "set", Value: "debug=true"},
+	}
+
+	rel2 := releaseStub()
+	rel2.Name = "diffy"
+	rel2.Version = 2
+	rel2.Chart = buildChart(withName("hello"))
+	rel2.Chart.Metadata.Version = "0.2.0"
+	rel2.Config = map[string]interface{}{
+		"image":    map[string]interface{}{"tag": "1.1"},
+		"replicas": 3,
+		"service":  map[string]interface{}{"port": 80},
+	}
+	rel2.Manifest = "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cfg\ndata:\n  a: c\n---\napiVersion: v1\nkind: Secret\nmetadata:\n  name: sec\n  namespace: other\ndata:\n  password: c2VjcmV0\n"
+	rel2.ValuesSources = []*release.ValuesSource{
+		{Flag: "values", Value: "values.yaml", Digest: "sha256:bbbb"},
+		{Flag: "set", Value: "replicas=3"},
+	}
+
+	require.NoError(t, config.Releases.Create(rel1))
+	require.NoError(t, config.Releases.Create(rel2))
+
+	client := NewHistory(config)
+	d, err := client.Diff("diffy", 1, 2)
+	require.NoError(t, err)
+
+	is.Equal(&StringChange{From: "hello-0.1.0", To: "hello-0.2.0"}, d.Chart)
+	is.Nil(d.AppVersion)
+	is.Equal([]chartutil.ValueDiff{
+		{Path: "debug", Type: chartutil.DiffRemoved, Old: true},
+		{Path: "image.tag", Type: chartutil.DiffChanged, Old: "1.0", New: "1.1"},
+		{Path: "replicas", Type: chartutil.DiffAdded, New: 3},
+	}, d.Values)
+
+	require.Len(t, d.Manifests, 3)
+	is.Equal("ConfigMap/cfg", d.Manifests[0].Resource)
+	is.Equal(chartutil.DiffChanged, d.Manifests[0].Type)
+	is.Contains(d.Manifests[0].Diff, "-  a: b\n+  a: c\n")
+	is.Equal("Secret/other/sec", d.Manifests[1].Resource)
+	is.Equal(chartutil.DiffAdded, d.Manifests[1].Type)
+	is.Contains(d.Manifests[1].Diff, "+  password: ")
+	is.NotContains(d.Manifests[1].Diff, "c2VjcmV0", "the Secret data is hashed by default")
+	is.Equal("Service/svc", d.Manifests[2].Resource)
+	is.Equal(chartutil.DiffRemoved, d.Manifests[2].Type)
+
+	is.Equal([]ValuesSourceChange{
+		{Source: "--values values.yaml (sha256:bbbb)", Type: chartutil.DiffAdded},
+		{Source: "--set replicas=3", Type: chartutil.DiffAdded},
+		{Source: "--values values.yaml (sha256:aaaa)", Type: chartutil.DiffRemoved},
+		{Source: "--set debug=true", Type: chartutil.DiffRemoved},
+	}, d.ValuesSources)
+
+	client.Redactors = []redact.Redactor{redact.NewSecretRedactor(redact.ModeKeys)}
+	d, err = client.Diff("diffy", 1, 2)
+	require.NoError(t, err)
+	is.Contains(d.Manifests[1].Diff, "+  password: "+redact.RedactedValue)
+	client.Redactors = nil
+
+	_, err = client.Diff("diffy", 1, 3)
+	is.Error(err)
+	_, err = client.Diff("diffy", 0, 1)
+	is.Error(err)
+
+	// Comparing revisions only reads the storage.
+	config.KubeClient = &kubefake.FailingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}, IsReachableError: errors.New("unreachable")}
+	_, err = client.Diff("diffy", 1, 2)
+	is.NoError(err)
+}
+
+func TestHistoryChangelog(t *testing.T) {
+	is := assert.New(t)
+	config := actionConfigFixture(t)
+
+	for i, tag := range []string{"1.0", "1.1", "1.1"} {
+		rel := releaseStub()
+		rel.Name = "logged"
+		rel.Version = i + 1
+		rel.Info.Status = release.StatusSuperseded
+		rel.Info.Operator = "jane"
+		rel.Info.Description = fmt.Sprintf("revision %d", i+1)
+		rel.Config = map[string]interface{}{"image": map[string]interface{}{"tag": tag}}
+		if i == 2 {
+			rel.Chart.Metadata.Version = "0.2.0"
+			rel.Info.Status = release.StatusDeployed
+			rel.Info.Operator = "system:serviceaccount:ci:deployer"
+		}
+		require.NoError(t, config.Releases.Create(rel))
+	}
+
+	client := NewHistory(config)
+	entries, err := client.Changelog("logged")
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	is.Equal(3, entries[0].Revision)
+	is.Equal(release.StatusDeployed, entries[0].Status)
+	is.Equal("system:serviceaccount:ci:deployer", entries[0].Operator)
+	is.Equal(&StringChange{From: "hello-0.1.0", To: "hello-0.2.0"}, entries[0].Chart)
+	is.Empty(entries[0].Values)
+
+	is.Equal(2, entries[1].Revision)
+	is.Nil(entries[1].Chart)
+	is.Equal([]chartutil.ValueDiff{{Path: "image.tag", Type: chartutil.DiffChanged}}, entries[1].Values)
+	is.Equal("revision 2", entries[1].Description)
+
+	is.Equal(1, entries[2].Revision)
+	is.Equal(&StringChange{To: "hello-0.1.0"}, entries[2].Chart)
+	is.Equal([]chartutil.ValueDiff{{Path: "image.tag", Type: chartutil.DiffAdded}}, entries[2].Values)
+
+	client.Max = 1
+	entries, err = client.Changelog("logged")
+	require.NoError(t, err)
+	is.Len(entries, 1)
+	is.Equal(3, entries[0].Revision)
+}
+
+func TestHistoryRunWithContext(t *testing.T) {
+	is := assert.New(t)
+	config := actionConfigFixture(t)
+	for i := 1; i <= 3; i++ {
+		rel := releaseStub()
+		rel.Name = "paged"
+		rel.Version = i
+		require.NoError(t, config.Releases.Create(rel))
+	}
+	client := NewHistory(config)
+
+	hist, err := client.RunWithContext(context.Background(), "paged")
+	is.NoError(err)
+	is.Len(hist, 3)
+
+	_, err = client.RunWithContext(context.Background(), "missing")
+	is.ErrorIs(err, driver.ErrReleaseNotFound)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.RunWithContext(ctx, "paged")
+	is.ErrorIs(err, context.Canceled)
+}
@@ -0,0 +1,110 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/release"
+)
+
+// HookRun is the last run of a hook in a revision of a release.
+type HookRun struct {
+	Revision int                   `json:"revision"`
+	Name     string                `json:"name"`
+	Kind     string                `json:"kind"`
+	Path     string                `json:"path"`
+	Events   []release.HookEvent   `json:"events"`
+	LastRun  release.HookExecution `json:"last_run"`
+}
+
+// HookHistory is the action for listing the hook runs of the revisions of a
+// release, e.g. to find out whether a migration hook ran in a revision.
+//
+// It provides the implementation of 'helm status --hooks'.
+type HookHistory struct {
+	cfg *Configuration
+
+	// Revision limits the runs to a revision, 0 for all revisions.
+	Revision int
+	// Hook limits the runs to the hooks with this name.
+	Hook string
+	// IncludeNotRun includes the hooks that were not run in their revision.
+	IncludeNotRun bool
+}
+
+// NewHookHistory creates a new HookHistory object with the given configuration.
+func NewHookHistory(cfg *Configuration) *HookHistory {
+	return &HookHistory{
+		cfg: cfg,
+	}
+}
+
+// Run returns the hook runs of the release, sorted by revision then by start
+// time.
+func (h *HookHistory) Run(name string) ([]*HookRun, error) {
+	if err := h.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, errors.Errorf("hook history: Release name is invalid: %s", name)
+	}
+
+	var rels []*release.Release
+	if h.Revision > 0 {
+		rel, err := h.cfg.Releases.Get(name, h.Revision)
+		if err != nil {
+			return nil, err
+		}
+		rels = []*release.Release{rel}
+	} else {
+		var err error
+		if rels, err = h.cfg.Releases.History(name); err != nil {
+			return nil, err
+		}
+	}
+
+	runs := []*HookRun{}
+	for _, rel := range rels {
+		for _, hook := range rel.Hooks {
+			if h.Hook != "" && hook.Name != h.Hook {
+				continue
+			}
+			if !h.IncludeNotRun && hook.LastRun.StartedAt.IsZero() {
+				continue
+			}
+			runs = append(runs, &HookRun{
+				Revision: rel.Version,
+				Name:     hook.Name,
+				Kind:     hook.Kind,
+				Path:     hook.Path,
+				Events:   hook.Events,
+				LastRun:  hook.LastRun,
+			})
+		}
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		if runs[i].Revision != runs[j].Revision {
+			return runs[i].Revision < runs[j].Revision
+		}
+		return runs[i].LastRun.StartedAt.Before(runs[j].LastRun.StartedAt)
+	})
+	return runs, nil
+}
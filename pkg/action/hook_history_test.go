@@ -0,0 +1,118 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	"helm.sh/helm/v4/pkg/release"
+	helmtime "helm.sh/helm/v4/pkg/time"
+)
+
+// podOutputKubeClient reports the same output for all the pods of hooks.
+type podOutputKubeClient struct {
+	*kubefake.FailingKubeClient
+	output *kube.PodOutput
+}
+
+func (c *podOutputKubeClient) PodOutput(_ *resource.Info, _ int64) (*kube.PodOutput, error) {
+	return c.output, nil
+}
+
+func TestExecHookRecordsRun(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	exitCode := int32(2)
+	kubeClient := &podOutputKubeClient{
+		FailingKubeClient: &kubefake.FailingKubeClient{
+			PrintingKubeClient:   kubefake.PrintingKubeClient{Out: io.Discard},
+			BuildDummy:           true,
+			WatchUntilReadyError: errors.New("job failed: BackoffLimitExceeded"),
+		},
+		output: &kube.PodOutput{Pod: "migrate-x1", ExitCode: &exitCode, Logs: strings.Repeat("progress\n", 1000) + "error: table exists\n"},
+	}
+	cfg.KubeClient = kubeClient
+
+	rel := releaseStub()
+	hook := &release.Hook{Name: "migrate", Kind: "Job", Path: "migrate", Events: []release.HookEvent{release.HookPreUpgrade}}
+	rel.Hooks = []*release.Hook{hook}
+
+	require.Error(t, cfg.execHook(rel, release.HookPreUpgrade, time.Minute))
+	require.Error(t, cfg.execHook(rel, release.HookPreUpgrade, time.Minute))
+
+	assert.Equal(t, release.HookPhaseFailed, hook.LastRun.Phase)
+	assert.Equal(t, release.HookPreUpgrade, hook.LastRun.Event)
+	assert.Equal(t, 2, hook.LastRun.Attempts)
+	assert.Equal(t, "job failed: BackoffLimitExceeded", hook.LastRun.Message)
+	assert.Equal(t, &exitCode, hook.LastRun.ExitCode)
+	assert.LessOrEqual(t, len(hook.LastRun.LogTail), hookLogTailBytes)
+	assert.True(t, strings.HasPrefix(hook.LastRun.LogTail, "progress\n"), "expected the log tail to start with a whole line")
+	assert.True(t, strings.HasSuffix(hook.LastRun.LogTail, "error: table exists\n"))
+}
+
+func TestHookHistory(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	for version, hooks := range map[int][]*release.Hook{
+		1: {
+			{Name: "migrate", Kind: "Job", LastRun: release.HookExecution{StartedAt: helmtime.Unix(1704067200, 0), Phase: release.HookPhaseSucceeded}},
+			{Name: "notify", Kind: "ConfigMap", LastRun: release.HookExecution{StartedAt: helmtime.Unix(1704067140, 0), Phase: release.HookPhaseSucceeded}},
+		},
+		2: {
+			{Name: "migrate", Kind: "Job"},
+			{Name: "notify", Kind: "ConfigMap", LastRun: release.HookExecution{StartedAt: helmtime.Unix(1704153600, 0), Phase: release.HookPhaseFailed}},
+		},
+	} {
+		rel := namedReleaseStub("hooked", release.StatusSuperseded)
+		rel.Version = version
+		rel.Hooks = hooks
+		require.NoError(t, cfg.Releases.Create(rel))
+	}
+
+	runName := func(r *HookRun) string { return r.Name }
+	client := NewHookHistory(cfg)
+	runs, err := client.Run("hooked")
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+	assert.Equal(t, []int{1, 1, 2}, []int{runs[0].Revision, runs[1].Revision, runs[2].Revision})
+	assert.Equal(t, []string{"notify", "migrate", "notify"}, []string{runName(runs[0]), runName(runs[1]), runName(runs[2])})
+
+	client.Hook = "migrate"
+	runs, err = client.Run("hooked")
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, 1, runs[0].Revision)
+
+	client.Revision = 2
+	runs, err = client.Run("hooked")
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+
+	client.IncludeNotRun = true
+	runs, err = client.Run("hooked")
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.True(t, runs[0].LastRun.StartedAt.IsZero())
+}
@@ -18,6 +18,8 @@ package action
 import (
 	"bytes"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -29,6 +31,12 @@ import (
 
 // execHook executes all of the hooks for the given hook event.
 func (cfg *Configuration) execHook(rl *release.Release, hook release.HookEvent, timeout time.Duration) error {
+	return cfg.execHookTracked(rl, hook, timeout, nil)
+}
+
+// execHookTracked executes the hooks like execHook, recording the resources
+// of the hook being run in the tracker, if any.
+func (cfg *Configuration) execHookTracked(rl *release.Release, hook release.HookEvent, timeout time.Duration, tracker *hookTracker) error {
 	executingHooks := []*release.Hook{}
 
 	for _, h := range rl.Hooks {
@@ -61,10 +69,18 @@ func (cfg *Configuration) execHook(rl *release.Release, hook release.HookEvent,
 			return errors.Wrapf(err, "unable to build kubernetes object for %s hook %s", hook, h.Path)
 		}
 
+		// Track the hook before creating it, so that stopping the tracker
+		// either prevents the hook or sees its resources.
+		if !tracker.start(h, resources) {
+			return errors.Errorf("%s hook %s was not run as the operation was stopped", hook, h.Path)
+		}
+
 		// Record the time at which the hook was applied to the cluster
 		h.LastRun = release.HookExecution{
 			StartedAt: helmtime.Now(),
 			Phase:     release.HookPhaseRunning,
+			Event:     hook,
+			Attempts:  h.LastRun.Attempts + 1,
 		}
 		cfg.recordRelease(rl)
 
@@ -75,18 +91,28 @@ func (cfg *Configuration) execHook(rl *release.Release, hook release.HookEvent,
 
 		// Create hook resources
 		if _, err := cfg.KubeClient.Create(resources); err != nil {
+			tracker.done(h)
 			h.LastRun.CompletedAt = helmtime.Now()
 			h.LastRun.Phase = release.HookPhaseFailed
+			h.LastRun.Message = err.Error()
 			return errors.Wrapf(err, "warning: Hook %s %s failed", hook, h.Path)
 		}
 
 		// Watch hook resources until they have completed
-		err = cfg.KubeClient.WatchUntilReady(resources, timeout)
+		hookTimeout := timeout
+		if h.Timeout > 0 {
+			hookTimeout = h.Timeout
+		}
+		err = cfg.KubeClient.WatchUntilReady(resources, hookTimeout)
+		tracker.done(h)
 		// Note the time of success/failure
 		h.LastRun.CompletedAt = helmtime.Now()
+		// Record the outcome of the hook pod before a delete policy removes it
+		cfg.recordHookOutput(h, resources)
 		// Mark hook as succeeded or failed
 		if err != nil {
 			h.LastRun.Phase = release.HookPhaseFailed
+			h.LastRun.Message = err.Error()
 			// If a hook is failed, check the annotation of the hook to determine whether the hook should be deleted
 			// under failed condition. If so, then clear the corresponding resource object in the hook
 			if err := cfg.deleteHookByPolicy(h, release.HookFailed, timeout); err != nil {
@@ -109,6 +135,100 @@ func (cfg *Configuration) execHook(rl *release.Release, hook release.HookEvent,
 	return nil
 }
 
+// hookLogTailLines and hookLogTailBytes bound the logs of a hook pod kept
+// in the record of the hook run, which is stored with the release.
+const (
+	hookLogTailLines = 20
+	hookLogTailBytes = 4096
+)
+
+// recordHookOutput records the exit code and the end of the logs of the pod
+// run by the hook, if any. Failing to read them does not fail the hook.
+func (cfg *Configuration) recordHookOutput(h *release.Hook, resources kube.ResourceList) {
+	for _, info := range resources {
+		out, err := cfg.KubeClient.PodOutput(info, hookLogTailLines)
+		if errors.Is(err, kube.ErrNotSupported) {
+			return
+		}
+		if out == nil {
+			if err != nil {
+				cfg.Log("warning: unable to get the output of hook %s: %s", h.Path, err)
+			}
+			continue
+		}
+		if err != nil {
+			cfg.Log("warning: unable to get the logs of hook %s: %s", h.Path, err)
+		}
+		logs := out.Logs
+		if len(logs) > hookLogTailBytes {
+			// keep whole lines only
+			logs = logs[len(logs)-hookLogTailBytes:]
+			if i := strings.IndexByte(logs, '\n'); i >= 0 {
+				logs = logs[i+1:]
+			}
+		}
+		h.LastRun.ExitCode = out.ExitCode
+		h.LastRun.LogTail = logs
+		return
+	}
+}
+
+// hookTracker records the resources of the hooks being run, so that the
+// failure path of an operation, which may run concurrently with them, can stop
+// them. The methods of a nil tracker do nothing.
+type hookTracker struct {
+	mu      sync.Mutex
+	running map[*release.Hook]kube.ResourceList
+	stopped bool
+}
+
+// start records a hook before its resources are created. It returns false
+// once the tracker is stopped, in which case the hook must not be run.
+func (t *hookTracker) start(h *release.Hook, resources kube.ResourceList) bool {
+	if t == nil {
+		return true
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopped {
+		return false
+	}
+	if t.running == nil {
+		t.running = make(map[*release.Hook]kube.ResourceList)
+	}
+	t.running[h] = resources
+	return true
+}
+
+func (t *hookTracker) done(h *release.Hook) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.running, h)
+}
+
+// stop prevents the hooks from starting, and returns the names and the
+// resources of the hooks being run. As no hook starts once it returns, these
+// are all the hooks left to stop.
+func (t *hookTracker) stop() ([]string, kube.ResourceList) {
+	if t == nil {
+		return nil, nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopped = true
+	var names []string
+	var resources kube.ResourceList
+	for h, r := range t.running {
+		names = append(names, h.Path)
+		resources = append(resources, r...)
+	}
+	sort.Strings(names)
+	return names, resources
+}
+
 // hookByWeight is a sorter for hooks
 type hookByWeight []*release.Hook
 
@@ -0,0 +1,192 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v4/pkg/annotations"
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+// RecreateOnImmutableChangeAnnotation is the annotation that allows an upgrade
+// to delete and recreate a resource whose immutable fields are changed.
+const RecreateOnImmutableChangeAnnotation = annotations.RecreateOnImmutableChange
+
+// immutableFields are the fields of the built-in kinds that cannot be changed
+// once a resource is created.
+var immutableFields = map[schema.GroupKind][]string{
+	{Group: "apps", Kind: "Deployment"}:                              {"spec.selector"},
+	{Group: "apps", Kind: "ReplicaSet"}:                              {"spec.selector"},
+	{Group: "apps", Kind: "DaemonSet"}:                               {"spec.selector"},
+	{Group: "apps", Kind: "StatefulSet"}:                             {"spec.selector", "spec.serviceName", "spec.podManagementPolicy"},
+	{Group: "batch", Kind: "Job"}:                                    {"spec.selector"},
+	{Kind: "PersistentVolumeClaim"}:                                  {"spec.storageClassName", "spec.accessModes", "spec.volumeMode", "spec.volumeName"},
+	{Kind: "Service"}:                                                {"spec.clusterIP"},
+	{Kind: "Secret"}:                                                 {"type"},
+	{Group: "rbac.authorization.k8s.io", Kind: "RoleBinding"}:        {"roleRef"},
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"}: {"roleRef"},
+}
+
+// ImmutableFieldChange is a change of an immutable field of an existing
+// resource.
+type ImmutableFieldChange struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Field is the path of the field, e.g. "spec.selector".
+	Field string `json:"field"`
+	// Current and Desired are the JSON encoded values of the field.
+	Current string `json:"current"`
+	Desired string `json:"desired"`
+	// Recreate reports that the resource is annotated with
+	// RecreateOnImmutableChangeAnnotation.
+	Recreate bool `json:"recreate,omitempty"`
+}
+
+// ImmutableFieldError reports the changes of immutable fields that would make
+// an upgrade fail.
+type ImmutableFieldError struct {
+	Changes []ImmutableFieldChange
+}
+
+func (e *ImmutableFieldError) Error() string {
+	lines := []string{"cannot change immutable fields of existing resources:"}
+	for _, c := range e.Changes {
+		lines = append(lines, fmt.Sprintf("- %s %q in namespace %q: field %q cannot be changed from %s to %s", c.Kind, c.Name, c.Namespace, c.Field, c.Current, c.Desired))
+	}
+	lines = append(lines, fmt.Sprintf("annotate the resources with %q: \"true\" to delete and recreate them during the upgrade", RecreateOnImmutableChangeAnnotation))
+	return strings.Join(lines, "\n")
+}
+
+// immutableFieldChanges compares the immutable fields of the resources with
+// their live objects. Resources that do not exist in the cluster yet are
+// ignored.
+//
+// It returns the changed resources that are annotated to be recreated, and an
+// *ImmutableFieldError listing the changes of the other resources.
+func immutableFieldChanges(resources kube.ResourceList) (kube.ResourceList, []ImmutableFieldChange, error) {
+	var toBeRecreated kube.ResourceList
+	var changes []ImmutableFieldChange
+	var blocked []ImmutableFieldChange
+
+	err := resources.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		fields := immutableFields[info.Mapping.GroupVersionKind.GroupKind()]
+		if len(fields) == 0 {
+			return nil
+		}
+
+		helper := resource.NewHelper(info.Client, info.Mapping)
+		existing, err := helper.Get(info.Namespace, info.Name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			return errors.Wrapf(err, "could not get information about the resource %s", resourceString(info))
+		}
+
+		found, err := fieldChanges(info, existing, fields)
+		if err != nil {
+			return err
+		}
+		if len(found) == 0 {
+			return nil
+		}
+		changes = append(changes, found...)
+		if found[0].Recreate {
+			toBeRecreated.Append(info)
+		} else {
+			blocked = append(blocked, found...)
+		}
+		return nil
+	})
+	if err != nil {
+		return toBeRecreated, changes, err
+	}
+	if len(blocked) > 0 {
+		return toBeRecreated, changes, &ImmutableFieldError{Changes: blocked}
+	}
+	return toBeRecreated, changes, nil
+}
+
+// fieldChanges returns the fields whose desired values differ from the live
+// object. Fields that are not set in the desired object are left to the
+// defaults of the cluster and are not compared.
+func fieldChanges(info *resource.Info, live runtime.Object, fields []string) ([]ImmutableFieldChange, error) {
+	desired, err := runtime.DefaultUnstructuredConverter.ToUnstructured(info.Object)
+	if err != nil {
+		return nil, err
+	}
+	current, err := runtime.DefaultUnstructuredConverter.ToUnstructured(live)
+	if err != nil {
+		return nil, err
+	}
+	annos, err := accessor.Annotations(info.Object)
+	if err != nil {
+		return nil, err
+	}
+	recreate := annotations.ParseRecreateOnImmutableChange(annos)
+
+	_, kind := info.Mapping.GroupVersionKind.ToAPIVersionAndKind()
+	var changes []ImmutableFieldChange
+	for _, field := range fields {
+		path := strings.Split(field, ".")
+		want, ok, _ := unstructured.NestedFieldNoCopy(desired, path...)
+		if !ok || isEmptyValue(want) {
+			continue
+		}
+		got, ok, _ := unstructured.NestedFieldNoCopy(current, path...)
+		if !ok || reflect.DeepEqual(want, got) {
+			continue
+		}
+		changes = append(changes, ImmutableFieldChange{
+			Kind:      kind,
+			Name:      info.Name,
+			Namespace: info.Namespace,
+			Field:     field,
+			Current:   jsonValue(got),
+			Desired:   jsonValue(want),
+			Recreate:  recreate,
+		})
+	}
+	return changes, nil
+}
+
+func isEmptyValue(v interface{}) bool {
+	return v == nil || reflect.ValueOf(v).IsZero()
+}
+
+func jsonValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+var (
+	coreV1GV    = schema.GroupVersion{Version: "v1"}
+	corev1Codec = scheme.Codecs.LegacyCodec(coreV1GV)
+)
+
+// newSelectorDeployment returns a deployment with the desired selector whose
+// live object has the current selector, or does not exist when current is nil.
+func newSelectorDeployment(name string, current, desired map[string]string, annotations map[string]string) *resource.Info {
+	obj := func(selector map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "ns-a", Annotations: annotations},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &v1.LabelSelector{MatchLabels: selector},
+			},
+		}
+	}
+	client := fakeClientWith(http.StatusNotFound, appsV1GV, "")
+	if current != nil {
+		client = fakeClientWith(http.StatusOK, appsV1GV, runtime.EncodeOrDie(appsv1Codec, obj(current)))
+	}
+	return &resource.Info{
+		Name:      name,
+		Namespace: "ns-a",
+		Mapping: &meta.RESTMapping{
+			Resource:         schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployment"},
+			GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Scope:            meta.RESTScopeNamespace,
+		},
+		Object: obj(desired),
+		Client: client,
+	}
+}
+
+func newClaim(name, current, desired string) *resource.Info {
+	obj := func(class string) *corev1.PersistentVolumeClaim {
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "ns-a"},
+		}
+		if class != "" {
+			pvc.Spec.StorageClassName = &class
+		}
+		return pvc
+	}
+	return &resource.Info{
+		Name:      name,
+		Namespace: "ns-a",
+		Mapping: &meta.RESTMapping{
+			Resource:         schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"},
+			GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "PersistentVolumeClaim"},
+			Scope:            meta.RESTScopeNamespace,
+		},
+		Object: obj(desired),
+		Client: fakeClientWith(http.StatusOK, coreV1GV, runtime.EncodeOrDie(corev1Codec, obj(current))),
+	}
+}
+
+func TestImmutableFieldChanges(t *testing.T) {
+	web := map[string]string{"app": "web"}
+	api := map[string]string{"app": "api"}
+	recreate := map[string]string{RecreateOnImmutableChangeAnnotation: "true"}
+
+	resources := kube.ResourceList{
+		newSelectorDeployment("unchanged", web, web, nil),
+		newSelectorDeployment("missing", nil, api, nil),
+		newSelectorDeployment("changed", web, api, nil),
+		newSelectorDeployment("recreated", web, api, recreate),
+		newClaim("defaulted", "standard", ""),
+		newClaim("claim", "standard", "fast"),
+	}
+
+	toBeRecreated, changes, err := immutableFieldChanges(resources)
+	require.Error(t, err)
+	ierr, ok := err.(*ImmutableFieldError)
+	require.True(t, ok, "expected an *ImmutableFieldError, got %v", err)
+	assert.Equal(t, []ImmutableFieldChange{
+		{Kind: "Deployment", Name: "changed", Namespace: "ns-a", Field: "spec.selector", Current: `{"matchLabels":{"app":"web"}}`, Desired: `{"matchLabels":{"app":"api"}}`},
+		{Kind: "PersistentVolumeClaim", Name: "claim", Namespace: "ns-a", Field: "spec.storageClassName", Current: `"standard"`, Desired: `"fast"`},
+	}, ierr.Changes)
+	assert.Len(t, changes, 3)
+
+	require.Len(t, toBeRecreated, 1)
+	assert.Equal(t, "recreated", toBeRecreated[0].Name)
+
+	assert.Contains(t, err.Error(), `Deployment "changed" in namespace "ns-a": field "spec.selector" cannot be changed from {"matchLabels":{"app":"web"}} to {"matchLabels":{"app":"api"}}`)
+	assert.Contains(t, err.Error(), RecreateOnImmutableChangeAnnotation)
+
+	toBeRecreated, changes, err = immutableFieldChanges(kube.ResourceList{resources[0], resources[3]})
+	assert.NoError(t, err)
+	assert.Len(t, changes, 1)
+	assert.Len(t, toBeRecreated, 1)
+}
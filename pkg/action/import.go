@@ -0,0 +1,240 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/internal/version"
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/kube"
+	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// Import is the action for adopting existing resources of the cluster into a
+// new release, e.g. to migrate resources applied with kubectl to Helm.
+//
+// The live state of the resources is captured as the manifest of the first
+// revision, and the resources are annotated as owned by the release. Later
+// upgrades patch the resources from that state.
+//
+// It provides the implementation of 'helm release import'.
+type Import struct {
+	cfg *Configuration
+
+	// Namespace is the namespace of the release.
+	Namespace string
+	// Selector is the label selector of the resources to import, among the
+	// resources of Kinds in the namespace. It is only used when Run is given
+	// no manifests.
+	Selector string
+	// Kinds are the kinds of the resources selected by Selector, e.g.
+	// "deployments" or "all".
+	Kinds []string
+	// Force imports resources that are annotated as owned by another release.
+	Force bool
+	// DryRun returns the release without storing it or annotating the
+	// resources.
+	DryRun bool
+	// Description is the description of the first revision.
+	Description string
+	Labels      map[string]string
+}
+
+// NewImport creates a new Import object with the given configuration.
+func NewImport(cfg *Configuration) *Import {
+	return &Import{
+		cfg:   cfg,
+		Kinds: []string{"all"},
+	}
+}
+
+// Run imports the resources into a new release. The resources are those of
+// the manifests when given, whose live state is read from the cluster, or the
+// resources matching Selector otherwise. The chart, when given, is associated
+// with the release for the future upgrades; a chart without templates is
+// recorded otherwise.
+//
+// A *ConflictError is returned when a resource is owned by another release.
+func (i *Import) Run(name string, chrt *chart.Chart, manifests io.Reader) (*release.Release, error) {
+	if err := i.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, errors.Errorf("release name is invalid: %s", name)
+	}
+	if h, err := i.cfg.Releases.History(name); err == nil && len(h) > 0 {
+		return nil, errors.Wrapf(errNameInUse, "release %q exists", name)
+	} else if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil, err
+	}
+
+	resources, err := i.resources(manifests)
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) == 0 {
+		return nil, errors.New("no resources to import")
+	}
+	if err := i.checkOwnership(resources, name); err != nil {
+		return nil, err
+	}
+
+	if chrt == nil {
+		chrt = &chart.Chart{Metadata: &chart.Metadata{
+			APIVersion:  chart.APIVersionV2,
+			Name:        name,
+			Version:     "0.0.0",
+			Description: "Resources imported into the release",
+		}}
+	}
+	ts := i.cfg.Now()
+	rel := &release.Release{
+		Name:      name,
+		Namespace: i.Namespace,
+		Chart:     chrt,
+		Info: &release.Info{
+			FirstDeployed: ts,
+			LastDeployed:  ts,
+			Status:        release.StatusDeployed,
+			Reason:        release.ReasonImported,
+			Description:   "Import complete",
+			Operator:      i.cfg.operator(),
+			ClientVersion: version.GetVersion(),
+		},
+		Version: 1,
+		Labels:  i.Labels,
+	}
+	if i.Description != "" {
+		rel.Info.Description = i.Description
+	}
+	if rel.Manifest, err = importedManifest(resources, name, i.Namespace); err != nil {
+		return nil, err
+	}
+	if i.DryRun {
+		rel.Info.Reason = release.ReasonDryRun
+		return rel, nil
+	}
+
+	for _, info := range resources {
+		helper := resource.NewHelper(info.Client, info.Mapping)
+		if _, err := helper.Patch(info.Namespace, info.Name, types.MergePatchType, ownershipPatch(name, i.Namespace), nil); err != nil {
+			return nil, errors.Wrapf(err, "could not set the ownership metadata of %s", resourceString(info))
+		}
+	}
+	if err := i.cfg.Releases.Create(rel); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
+
+// resources returns the resources to import, with their live objects.
+func (i *Import) resources(manifests io.Reader) (kube.ResourceList, error) {
+	if manifests == nil {
+		if i.Selector == "" {
+			return nil, errors.New("either manifests or a label selector of the resources to import is required")
+		}
+		resources, err := i.cfg.KubeClient.Select(i.Kinds, i.Selector)
+		return resources, errors.Wrap(err, "unable to select the resources to import")
+	}
+
+	resources, err := i.cfg.KubeClient.Build(manifests, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to build kubernetes objects from the manifests")
+	}
+	for _, info := range resources {
+		if err := info.Get(); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, errors.Errorf("%s does not exist", resourceString(info))
+			}
+			return nil, errors.Wrapf(err, "could not get information about the resource %s", resourceString(info))
+		}
+	}
+	return resources, nil
+}
+
+// checkOwnership returns a *ConflictError listing the resources owned by
+// another release, unless Force is set.
+func (i *Import) checkOwnership(resources kube.ResourceList, name string) error {
+	if i.Force {
+		return nil
+	}
+	var conflicts []ResourceConflict
+	for _, info := range resources {
+		found, err := ownershipConflicts(info, info.Object, name, i.Namespace)
+		if err != nil {
+			return err
+		}
+		for _, c := range found {
+			if c.Reason == ConflictOwnedByOtherRelease {
+				conflicts = append(conflicts, found...)
+				break
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		return &ConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+// importedManifest returns the manifest of the live objects of the resources
+// with the ownership metadata of the release, without their status and the
+// metadata set by the cluster.
+func importedManifest(resources kube.ResourceList, name, namespace string) (string, error) {
+	var b strings.Builder
+	for _, info := range resources {
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(info.Object)
+		if err != nil {
+			return "", errors.Wrapf(err, "unable to convert %s", resourceString(info))
+		}
+		u := &unstructured.Unstructured{Object: obj}
+		u.SetGroupVersionKind(info.Mapping.GroupVersionKind)
+		for _, field := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "selfLink"} {
+			unstructured.RemoveNestedField(u.Object, "metadata", field)
+		}
+		unstructured.RemoveNestedField(u.Object, "status")
+		if err := mergeLabels(u, map[string]string{appManagedByLabel: appManagedByHelm}); err != nil {
+			return "", err
+		}
+		if err := mergeAnnotations(u, map[string]string{
+			helmReleaseNameAnnotation:      name,
+			helmReleaseNamespaceAnnotation: namespace,
+		}); err != nil {
+			return "", err
+		}
+
+		doc, err := yaml.Marshal(u.Object)
+		if err != nil {
+			return "", errors.Wrapf(err, "unable to marshal %s", resourceString(info))
+		}
+		b.WriteString("---\n")
+		b.Write(doc)
+	}
+	return b.String(), nil
+}
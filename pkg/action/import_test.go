@@ -0,0 +1,117 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	"helm.sh/helm/v4/pkg/release"
+)
+
+// selectKubeClient is a fake client that selects and builds the given
+// resources.
+type selectKubeClient struct {
+	resourcesKubeClient
+	selector string
+}
+
+func (c *selectKubeClient) Select(_ []string, selector string) (kube.ResourceList, error) {
+	c.selector = selector
+	return c.resources, nil
+}
+
+func importAction(t *testing.T, resources ...string) *Import {
+	t.Helper()
+	config := actionConfigFixture(t)
+	failer := config.KubeClient.(*kubefake.FailingKubeClient)
+	var list kube.ResourceList
+	for _, name := range resources {
+		list = append(list, newDeploymentWithOwner(name, "spaced", nil, nil))
+	}
+	config.KubeClient = &selectKubeClient{resourcesKubeClient: resourcesKubeClient{failer, list}}
+	client := NewImport(config)
+	client.Namespace = "spaced"
+	return client
+}
+
+func TestImport(t *testing.T) {
+	client := importAction(t, "web", "api")
+	client.Selector = "app=shop"
+
+	rel, err := client.Run("shop", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "app=shop", client.cfg.KubeClient.(*selectKubeClient).selector)
+	assert.Equal(t, 1, rel.Version)
+	assert.Equal(t, release.StatusDeployed, rel.Info.Status)
+	assert.Equal(t, release.ReasonImported, rel.Info.Reason)
+	assert.Equal(t, "shop", rel.Chart.Name())
+	assert.Equal(t, 2, strings.Count(rel.Manifest, "kind: Deployment\n"))
+	assert.Contains(t, rel.Manifest, "app.kubernetes.io/managed-by: Helm")
+	assert.Contains(t, rel.Manifest, "meta.helm.sh/release-name: shop")
+	assert.NotContains(t, rel.Manifest, "status:")
+
+	stored, err := client.cfg.Releases.Deployed("shop")
+	require.NoError(t, err)
+	assert.Equal(t, rel.Manifest, stored.Manifest)
+
+	_, err = client.Run("shop", nil, nil)
+	assert.ErrorIs(t, err, errNameInUse)
+}
+
+func TestImportManifests(t *testing.T) {
+	client := importAction(t, "web")
+	client.DryRun = true
+
+	rel, err := client.Run("shop", buildChart(), strings.NewReader("kind: Deployment\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", rel.Chart.Name())
+	assert.Contains(t, rel.Manifest, "name: web")
+	_, err = client.cfg.Releases.Get("shop", 1)
+	assert.Error(t, err, "a dry run must not store the release")
+
+	_, err = client.Run("shop", nil, nil)
+	assert.EqualError(t, err, "either manifests or a label selector of the resources to import is required")
+}
+
+func TestImportConflicts(t *testing.T) {
+	client := importAction(t, "web")
+	owned := newDeploymentWithOwner("api", "spaced", map[string]string{appManagedByLabel: appManagedByHelm}, map[string]string{
+		helmReleaseNameAnnotation:      "billing",
+		helmReleaseNamespaceAnnotation: "spaced",
+	})
+	kubeClient := client.cfg.KubeClient.(*selectKubeClient)
+	kubeClient.resources = append(kubeClient.resources, owned)
+	client.Selector = "app=shop"
+
+	_, err := client.Run("shop", nil, nil)
+	var cerr *ConflictError
+	require.True(t, errors.As(err, &cerr), "expected a *ConflictError, got %v", err)
+	require.Len(t, cerr.Conflicts, 1)
+	assert.Equal(t, "api", cerr.Conflicts[0].Name)
+	assert.Equal(t, ConflictOwnedByOtherRelease, cerr.Conflicts[0].Reason)
+
+	client.Force = true
+	_, err = client.Run("shop", nil, nil)
+	assert.NoError(t, err)
+}
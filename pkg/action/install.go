@@ -21,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
 	"os"
 	"path"
@@ -37,8 +38,10 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/yaml"
 
+	"helm.sh/helm/v4/internal/version"
 	"helm.sh/helm/v4/pkg/chart"
 	"helm.sh/helm/v4/pkg/chartutil"
 	"helm.sh/helm/v4/pkg/cli"
@@ -46,7 +49,6 @@ import (
 	"helm.sh/helm/v4/pkg/getter"
 	"helm.sh/helm/v4/pkg/kube"
 	kubefake "helm.sh/helm/v4/pkg/kube/fake"
-	"helm.sh/helm/v4/pkg/postrender"
 	"helm.sh/helm/v4/pkg/registry"
 	"helm.sh/helm/v4/pkg/release"
 	"helm.sh/helm/v4/pkg/releaseutil"
@@ -68,36 +70,17 @@ type Install struct {
 	cfg *Configuration
 
 	ChartPathOptions
+	ValuesFromOptions
+	DeployOptions
 
 	ClientOnly      bool
-	Force           bool
 	CreateNamespace bool
-	DryRun          bool
-	DryRunOption    string
-	// HideSecret can be set to true when DryRun is enabled in order to hide
-	// Kubernetes Secrets in the output. It cannot be used outside of DryRun.
-	HideSecret               bool
-	DisableHooks             bool
-	Replace                  bool
-	Wait                     bool
-	WaitForJobs              bool
-	Devel                    bool
-	DependencyUpdate         bool
-	Timeout                  time.Duration
-	Namespace                string
-	ReleaseName              string
-	GenerateName             bool
-	NameTemplate             string
-	Description              string
-	OutputDir                string
-	Atomic                   bool
-	SkipCRDs                 bool
-	SubNotes                 bool
-	HideNotes                bool
-	SkipSchemaValidation     bool
-	DisableOpenAPIValidation bool
-	IncludeCRDs              bool
-	Labels                   map[string]string
+	Replace         bool
+	ReleaseName     string
+	GenerateName    bool
+	NameTemplate    string
+	OutputDir       string
+	IncludeCRDs     bool
 	// KubeVersion allows specifying a custom kubernetes version to use and
 	// APIVersions allows a manual set of supported API Versions to be passed
 	// (for things like templating). These are ignored if ClientOnly is false
@@ -105,14 +88,30 @@ type Install struct {
 	APIVersions chartutil.VersionSet
 	// Used by helm template to render charts with .Release.IsUpgrade. Ignored if Dry-Run is false
 	IsUpgrade bool
-	// Enable DNS lookups when rendering templates
-	EnableDNS bool
 	// Used by helm template to add the release as part of OutputDir path
 	// OutputDir/<ReleaseName>
 	UseReleaseName bool
-	// TakeOwnership will ignore the check for helm annotations and take ownership of the resources.
-	TakeOwnership bool
-	PostRenderer  postrender.PostRenderer
+	// NameGenerator generates the release name when GenerateName is set,
+	// retrying while the name is taken. When nil, the name is the base name of
+	// the chart reference followed by the current Unix time.
+	NameGenerator NameGenerator
+	// Subchart, when set, restricts rendering and installation to the
+	// dependency subtree at this dot-separated path of names or aliases.
+	// The subchart receives the values it would have been given by its parents.
+	Subchart string
+	// SupportWarnings are the deprecation warnings of the chart of the last
+	// Run, see SupportWarnings.
+	SupportWarnings []*SupportWarning
+	// VerifyDependencies verifies the dependencies in charts/ against the
+	// digests recorded in Chart.lock by 'helm dependency update', failing
+	// when they were modified.
+	VerifyDependencies bool
+	// EventHandler, when set, receives the phase transitions and the applied
+	// resources of the install while it runs.
+	EventHandler EventHandler
+	// EffectiveObjects are the effective objects of the resources of the
+	// last Run, see ComputeEffective.
+	EffectiveObjects []*EffectiveObject
 	// Lock to control raceconditions when the process receives a SIGTERM
 	Lock sync.Mutex
 }
@@ -131,6 +130,14 @@ type ChartPathOptions struct {
 	Username              string // --username
 	Verify                bool   // --verify
 	Version               string // --version
+	// Headers are sent with the HTTP requests for the chart and the index
+	// file of its repository, e.g. to reach a chart server behind a single
+	// sign-on proxy.
+	Headers http.Header // --header
+	// BearerToken authorizes the HTTP requests for the chart and the index
+	// file of its repository. When empty, an auth/v1 plugin serving the host
+	// of the repository may provide the token.
+	BearerToken string // --bearer-token
 
 	// registryClient provides a registry client but is not added with
 	// options from a flag
@@ -160,6 +167,7 @@ func (i *Install) GetRegistryClient() *registry.Client {
 func (i *Install) installCRDs(crds []chart.CRD) error {
 	// We do these one file at a time in the order they were read.
 	totalItems := []*resource.Info{}
+	var created []chart.CRD
 	for _, obj := range crds {
 		// Read in the resources
 		res, err := i.cfg.KubeClient.Build(bytes.NewBuffer(obj.File.Data), false)
@@ -178,6 +186,7 @@ func (i *Install) installCRDs(crds []chart.CRD) error {
 			return errors.Wrapf(err, "failed to install CRD %s", obj.Name)
 		}
 		totalItems = append(totalItems, res...)
+		created = append(created, obj)
 	}
 	if len(totalItems) > 0 {
 		// Give time for the CRD to be recognized.
@@ -189,8 +198,9 @@ func (i *Install) installCRDs(crds []chart.CRD) error {
 		// the cache so that the new CRDs are recognized. This should only be
 		// the case when an action configuration is reused for multiple actions,
 		// as otherwise it is later loaded by ourselves when getCapabilities
-		// is called later on in the installation process.
-		if i.cfg.Capabilities != nil {
+		// is called later on in the installation process. Capabilities that
+		// already include the API versions of the new CRDs are kept.
+		if i.cfg.Capabilities != nil && !servesCRDs(i.cfg.Capabilities, created) {
 			discoveryClient, err := i.cfg.RESTClientGetter.ToDiscoveryClient()
 			if err != nil {
 				return err
@@ -200,6 +210,10 @@ func (i *Install) installCRDs(crds []chart.CRD) error {
 			discoveryClient.Invalidate()
 
 			_, _ = discoveryClient.ServerGroups()
+
+			// Discover the capabilities again, so that they include the
+			// API versions and resources of the new CRDs
+			i.cfg.InvalidateCapabilities()
 		}
 
 		// Invalidate the REST mapper, since it will not have the new CRDs
@@ -216,6 +230,21 @@ func (i *Install) installCRDs(crds []chart.CRD) error {
 	return nil
 }
 
+// servesCRDs reports whether the capabilities include every API version of
+// the CRDs.
+func servesCRDs(caps *chartutil.Capabilities, crds []chart.CRD) bool {
+	versions, err := crdAPIVersions(crds)
+	if err != nil || len(versions) == 0 {
+		return false
+	}
+	for _, v := range versions {
+		if !caps.APIVersions.Has(v) {
+			return false
+		}
+	}
+	return true
+}
+
 // Run executes the installation
 //
 // If DryRun is set to true, this will prepare the release, but not install it
@@ -243,29 +272,93 @@ func (i *Install) RunWithContext(ctx context.Context, chrt *chart.Chart, vals ma
 		i.cfg.Log("ERROR: Hiding Kubernetes secrets requires a dry-run mode")
 		return nil, errors.New("Hiding Kubernetes secrets requires a dry-run mode")
 	}
+	if !i.isDryRun() && len(i.Redactors) > 0 {
+		i.cfg.Log("ERROR: Redacting manifests requires a dry-run mode")
+		return nil, errors.New("Redacting manifests requires a dry-run mode")
+	}
+	if i.ComputeEffective && i.DryRunOption != "server" {
+		return nil, errEffectiveRequiresServerDryRun
+	}
+	i.EffectiveObjects = nil
+
+	if err := chartutil.CheckHelmVersion(chrt, ""); err != nil {
+		i.cfg.Log(fmt.Sprintf("ERROR: Helm version check failed: %v", err))
+		return nil, err
+	}
+
+	if i.ReleaseName == "" && i.NameTemplate != "" {
+		name, err := i.templateName(chrt, vals)
+		if err != nil {
+			i.cfg.Log(fmt.Sprintf("ERROR: Release name template failed: %v", err))
+			return nil, err
+		}
+		i.ReleaseName = name
+	}
+	if i.ReleaseName == "" && i.GenerateName && i.NameGenerator != nil {
+		name, err := i.generateName(chrt, vals)
+		if err != nil {
+			i.cfg.Log(fmt.Sprintf("ERROR: Release name generation failed: %v", err))
+			return nil, err
+		}
+		i.ReleaseName = name
+	}
 
 	if err := i.availableName(); err != nil {
 		i.cfg.Log(fmt.Sprintf("ERROR: Release name check failed: %v", err))
 		return nil, errors.Wrap(err, "release name check failed")
 	}
 
+	if i.VerifyDependencies {
+		if err := chartutil.VerifyDependencyDigests(chrt); err != nil {
+			i.cfg.Log(fmt.Sprintf("ERROR: Chart dependencies verification failed: %v", err))
+			return nil, errors.Wrap(err, "chart dependencies verification failed")
+		}
+	}
+
 	if err := chartutil.ProcessDependencies(chrt, vals); err != nil {
 		i.cfg.Log(fmt.Sprintf("ERROR: Processing chart dependencies failed: %v", err))
 		return nil, errors.Wrap(err, "chart dependencies processing failed")
 	}
 
+	if i.Subchart != "" {
+		var err error
+		chrt, vals, err = chartutil.ScopeToSubchart(chrt, vals, i.Subchart)
+		if err != nil {
+			i.cfg.Log(fmt.Sprintf("ERROR: Scoping chart to subchart failed: %v", err))
+			return nil, errors.Wrap(err, "chart subchart scoping failed")
+		}
+	}
+
+	var err error
+	if i.SupportWarnings, err = i.cfg.checkSupport(chrt, i.FailOnDeprecated); err != nil {
+		return nil, err
+	}
+
 	var interactWithRemote bool
 	if !i.isDryRun() || i.DryRunOption == "server" || i.DryRunOption == "none" || i.DryRunOption == "false" {
 		interactWithRemote = true
 	}
 
+	// Verify the cluster meets the chart's requirements before anything,
+	// including CRDs, is created. The APIs of the CRDs that are installed
+	// below are not required to be served yet.
+	if !i.ClientOnly && interactWithRemote && !i.SkipRequirementChecks {
+		var crds []chart.CRD
+		if !i.SkipCRDs && !i.isDryRun() {
+			crds = chrt.CRDObjects()
+		}
+		if err := i.cfg.checkRequirements(chrt, crds); err != nil {
+			return nil, err
+		}
+	}
+
 	// Pre-install anything in the crd/ directory. We do this before Helm
 	// contacts the upstream server and builds the capabilities object.
 	if crds := chrt.CRDObjects(); !i.ClientOnly && !i.SkipCRDs && len(crds) > 0 {
 		// On dry run, bail here
 		if i.isDryRun() {
 			i.cfg.Log("WARNING: This chart or one of its subcharts contains CRDs. Rendering may fail or contain inaccuracies.")
-		} else if err := i.installCRDs(crds); err != nil {
+		} else if err := i.EventHandler.phase(PhaseCRDs, func() error { return i.installCRDs(crds) }); err != nil {
 			return nil, err
 		}
 	}
@@ -305,7 +398,15 @@ func (i *Install) RunWithContext(ctx context.Context, chrt *chart.Chart, vals ma
 		IsInstall: !isUpgrade,
 		IsUpgrade: isUpgrade,
 	}
-	valuesToRender, err := chartutil.ToRenderValuesWithSchemaValidation(chrt, vals, options, caps, i.SkipSchemaValidation)
+	var clientFn func() (kubernetes.Interface, error)
+	if !i.ClientOnly && interactWithRemote {
+		clientFn = i.cfg.KubernetesClientSet
+	}
+	renderVals, err := i.cfg.mergeValuesFrom(i.ValuesFromOptions, chrt, i.Namespace, vals, clientFn)
+	if err != nil {
+		return nil, err
+	}
+	valuesToRender, err := chartutil.ToRenderValuesWithSchemaValidation(chrt, renderVals, options, caps, i.SkipSchemaValidation)
 	if err != nil {
 		return nil, err
 	}
@@ -315,25 +416,57 @@ func (i *Install) RunWithContext(ctx context.Context, chrt *chart.Chart, vals ma
 	}
 
 	rel := i.createRelease(chrt, vals, i.Labels)
+	rel.ConfigRaw = rawValues(i.ValuesRaw, rel.Config)
+	rel.ValuesSources = i.ValuesSources
 
 	var manifestDoc *bytes.Buffer
-	rel.Hooks, manifestDoc, rel.Info.Notes, err = i.cfg.renderResources(chrt, valuesToRender, i.ReleaseName, i.OutputDir, i.SubNotes, i.UseReleaseName, i.IncludeCRDs, i.PostRenderer, interactWithRemote, i.EnableDNS, i.HideSecret)
+	err = i.EventHandler.phase(PhaseRender, func() (err error) {
+		rel.Hooks, manifestDoc, rel.Info.Notes, err = i.cfg.renderResources(chrt, valuesToRender, renderOptions{
+			releaseName:         i.ReleaseName,
+			outputDir:           i.OutputDir,
+			useReleaseName:      i.UseReleaseName,
+			subNotes:            i.SubNotes,
+			includeCRDs:         i.IncludeCRDs,
+			postRenderer:        i.PostRenderer,
+			images:              &i.ImageOverrides,
+			interactWithRemote:  interactWithRemote,
+			enableDNS:           i.EnableDNS,
+			enableClusterConfig: i.EnableClusterConfig,
+			strictness:          i.Strictness,
+			reportAllErrors:     i.ReportAllErrors,
+			redactors:           dryRunRedactors(i.HideSecret, i.Redactors),
+			format:              i.ManifestFormat,
+		})
+		return err
+	})
 	// Even for errors, attach this if available
 	if manifestDoc != nil {
 		rel.Manifest = manifestDoc.String()
 	}
 	// Check error from render
 	if err != nil {
-		rel.SetStatus(release.StatusFailed, fmt.Sprintf("failed to render resource: %s", err.Error()))
+		rel.SetFailed(release.ReasonRenderFailed, err, fmt.Sprintf("failed to render resource: %s", err.Error()))
 		// Return a release with partial data so that the client can show debugging information.
+		if i.isDryRun() {
+			if redacted, rerr := redactRelease(rel, dryRunRedactors(i.HideSecret, i.Redactors)); rerr == nil {
+				return redacted, err
+			}
+			// Do not show a manifest that cannot be redacted.
+			rel.Manifest, rel.Hooks = "", nil
+		}
 		return rel, err
 	}
+	if rel.Manifest, rel.Hooks, rel.Exclusions, err = excludeResources(&i.Exclusions, rel.Manifest, rel.Hooks); err != nil {
+		return nil, err
+	}
 
 	// Mark this release as in-progress
 	rel.SetStatus(release.StatusPendingInstall, "Initial install underway")
+	rel.Info.Reason = release.ReasonPending
+	rel.Info.OperatorMessage = i.Description
 
 	var toBeAdopted kube.ResourceList
-	resources, err := i.cfg.KubeClient.Build(bytes.NewBufferString(rel.Manifest), !i.DisableOpenAPIValidation)
+	resources, err := i.cfg.KubeClient.BuildContext(ctx, bytes.NewBufferString(rel.Manifest), !i.DisableOpenAPIValidation)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to build kubernetes objects from release manifest")
 	}
@@ -361,10 +494,22 @@ func (i *Install) RunWithContext(ctx context.Context, chrt *chart.Chart, vals ma
 		}
 	}
 
+	if i.CheckPermissions && !i.ClientOnly && interactWithRemote {
+		if err := i.checkPermissions(ctx, rel, resources, toBeAdopted); err != nil {
+			return nil, err
+		}
+	}
+
 	// Bail out here if it is a dry run
 	if i.isDryRun() {
+		if i.ComputeEffective {
+			if i.EffectiveObjects, err = i.cfg.effectiveObjects(toBeAdopted, resources, dryRunRedactors(i.HideSecret, i.Redactors)); err != nil {
+				return rel, err
+			}
+		}
 		rel.Info.Description = "Dry run complete"
-		return rel, nil
+		rel.Info.Reason = release.ReasonDryRun
+		return redactRelease(rel, dryRunRedactors(i.HideSecret, i.Redactors))
 	}
 
 	if i.CreateNamespace {
@@ -400,6 +545,8 @@ func (i *Install) RunWithContext(ctx context.Context, chrt *chart.Chart, vals ma
 		}
 	}
 
+	i.cfg.Releases.MaxHistory = i.MaxHistory
+
 	// Store the release in history before continuing (new in Helm 3). We always know
 	// that this is a create operation.
 	if err := i.cfg.Releases.Create(rel); err != nil {
@@ -424,7 +571,7 @@ func (i *Install) performInstallCtx(ctx context.Context, rel *release.Release, t
 	resultChan := make(chan Msg, 1)
 
 	go func() {
-		rel, err := i.performInstall(rel, toBeAdopted, resources)
+		rel, err := i.performInstall(ctx, rel, toBeAdopted, resources)
 		resultChan <- Msg{rel, err}
 	}()
 	select {
@@ -436,6 +583,42 @@ func (i *Install) performInstallCtx(ctx context.Context, rel *release.Release, t
 	}
 }
 
+// checkPermissions checks the permissions needed to install the release,
+// see CheckPermissions.
+func (i *Install) checkPermissions(ctx context.Context, rel *release.Release, resources, toBeAdopted kube.ResourceList) error {
+	perms, err := i.requiredPermissions(rel, resources, toBeAdopted)
+	if err != nil {
+		return err
+	}
+	return i.cfg.checkPermissions(ctx, perms)
+}
+
+// requiredPermissions returns the permissions needed to install the release.
+func (i *Install) requiredPermissions(rel *release.Release, resources, toBeAdopted kube.ResourceList) (permissionSet, error) {
+	perms := permissionSet{}
+	if i.CreateNamespace {
+		perms[ResourcePermission{Verb: "create", Resource: "namespaces"}] = true
+	}
+	adopted := map[string]bool{}
+	for _, r := range toBeAdopted {
+		adopted[objectKey(r)] = true
+	}
+	for _, r := range resources {
+		if adopted[objectKey(r)] {
+			perms[resourcePermission("patch", r)] = true
+		} else {
+			perms[resourcePermission("create", r)] = true
+		}
+	}
+	if !i.DisableHooks {
+		if err := i.cfg.addHookPermissions(perms, rel.Hooks); err != nil {
+			return nil, err
+		}
+	}
+	i.cfg.addStoragePermissions(perms, rel.Namespace)
+	return perms, nil
+}
+
 // isDryRun returns true if Upgrade is set to run as a DryRun
 func (i *Install) isDryRun() bool {
 	if i.DryRun || i.DryRunOption == "client" || i.DryRunOption == "server" || i.DryRunOption == "true" {
@@ -444,41 +627,50 @@ func (i *Install) isDryRun() bool {
 	return false
 }
 
-func (i *Install) performInstall(rel *release.Release, toBeAdopted kube.ResourceList, resources kube.ResourceList) (*release.Release, error) {
-	var err error
+func (i *Install) performInstall(ctx context.Context, rel *release.Release, toBeAdopted kube.ResourceList, resources kube.ResourceList) (*release.Release, error) {
 	// pre-install hooks
 	if !i.DisableHooks {
-		if err := i.cfg.execHook(rel, release.HookPreInstall, i.Timeout); err != nil {
-			return rel, fmt.Errorf("failed pre-install: %s", err)
+		if err := i.EventHandler.phase(PhasePreInstallHooks, func() error {
+			return i.cfg.execHook(rel, release.HookPreInstall, i.Timeout)
+		}); err != nil {
+			return rel, withReason(release.ReasonHookFailed, fmt.Errorf("failed pre-install: %s", err))
 		}
 	}
 
 	// At this point, we can do the install. Note that before we were detecting whether to
 	// do an update, but it's not clear whether we WANT to do an update if the reuse is set
 	// to true, since that is basically an upgrade operation.
-	if len(toBeAdopted) == 0 && len(resources) > 0 {
-		_, err = i.cfg.KubeClient.Create(resources)
-	} else if len(resources) > 0 {
-		_, err = i.cfg.KubeClient.Update(toBeAdopted, resources, i.Force)
-	}
+	err := i.EventHandler.phase(PhaseApply, func() error {
+		opts := append(saveConfigOptions(i.SaveConfig), i.EventHandler.resourceOptions(PhaseApply)...)
+		var err error
+		if len(toBeAdopted) == 0 && len(resources) > 0 {
+			_, err = i.cfg.createResources(ctx, resources, i.ResourceTimeout, opts...)
+		} else if len(resources) > 0 {
+			_, err = i.cfg.updateResources(ctx, toBeAdopted, resources, i.Force, i.ResourceTimeout, opts...)
+		}
+		return err
+	})
 	if err != nil {
-		return rel, err
+		return rel, withReason(release.ReasonApplyFailed, err)
 	}
 
 	if i.Wait {
-		if i.WaitForJobs {
-			err = i.cfg.KubeClient.WaitWithJobs(resources, i.Timeout)
-		} else {
-			err = i.cfg.KubeClient.Wait(resources, i.Timeout)
-		}
+		err = i.EventHandler.phase(PhaseWait, func() error {
+			if i.WaitForJobs {
+				return i.cfg.KubeClient.WaitWithJobs(resources, i.Timeout)
+			}
+			return i.cfg.KubeClient.Wait(resources, i.Timeout)
+		})
 		if err != nil {
-			return rel, err
+			return rel, withReason(release.ReasonWaitFailed, err)
 		}
 	}
 
 	if !i.DisableHooks {
-		if err := i.cfg.execHook(rel, release.HookPostInstall, i.Timeout); err != nil {
-			return rel, fmt.Errorf("failed post-install: %s", err)
+		if err := i.EventHandler.phase(PhasePostInstallHooks, func() error {
+			return i.cfg.execHook(rel, release.HookPostInstall, i.Timeout)
+		}); err != nil {
+			return rel, withReason(release.ReasonHookFailed, fmt.Errorf("failed post-install: %s", err))
 		}
 	}
 
@@ -487,6 +679,7 @@ func (i *Install) performInstall(rel *release.Release, toBeAdopted kube.Resource
 	} else {
 		rel.SetStatus(release.StatusDeployed, "Install complete")
 	}
+	rel.Info.Reason = release.ReasonInstalled
 
 	// This is a tricky case. The release has been created, but the result
 	// cannot be recorded. The truest thing to tell the user is that the
@@ -503,7 +696,7 @@ func (i *Install) performInstall(rel *release.Release, toBeAdopted kube.Resource
 }
 
 func (i *Install) failRelease(rel *release.Release, err error) (*release.Release, error) {
-	rel.SetStatus(release.StatusFailed, fmt.Sprintf("Release %q failed: %s", i.ReleaseName, err.Error()))
+	rel.SetFailed(failureReason(err), err, fmt.Sprintf("Release %q failed: %s", i.ReleaseName, err.Error()))
 	if i.Atomic {
 		i.cfg.Log("Install failed and atomic is set, uninstalling release")
 		uninstall := NewUninstall(i.cfg)
@@ -548,7 +741,7 @@ func (i *Install) availableName() error {
 	if st := rel.Info.Status; i.Replace && (st == release.StatusUninstalled || st == release.StatusFailed) {
 		return nil
 	}
-	return errors.New("cannot reuse a name that is still in use")
+	return errNameInUse
 }
 
 // createRelease creates a new release object
@@ -563,6 +756,8 @@ func (i *Install) createRelease(chrt *chart.Chart, rawVals map[string]interface{
 			FirstDeployed: ts,
 			LastDeployed:  ts,
 			Status:        release.StatusUnknown,
+			Operator:      i.cfg.operator(),
+			ClientVersion: version.GetVersion(),
 		},
 		Version: 1,
 		Labels:  labels,
@@ -648,7 +843,9 @@ func ensureDirectoryForFile(file string) error {
 
 // NameAndChart returns the name and chart that should be used.
 //
-// This will read the flags and handle name generation if necessary.
+// This will read the flags and handle name generation if necessary. The name is
+// empty when NameTemplate or NameGenerator is set, since the name is then
+// rendered or generated by Run.
 func (i *Install) NameAndChart(args []string) (string, string, error) {
 	flagsNotSet := func() error {
 		if i.GenerateName {
@@ -669,8 +866,8 @@ func (i *Install) NameAndChart(args []string) (string, string, error) {
 	}
 
 	if i.NameTemplate != "" {
-		name, err := TemplateName(i.NameTemplate)
-		return name, args[0], err
+		// The name template is rendered by Run, with the chart and the values.
+		return "", args[0], nil
 	}
 
 	if i.ReleaseName != "" {
@@ -681,6 +878,11 @@ func (i *Install) NameAndChart(args []string) (string, string, error) {
 		return "", args[0], errors.New("must either provide a name or specify --generate-name")
 	}
 
+	if i.NameGenerator != nil {
+		// The name is generated by Run, with the chart and the values.
+		return "", args[0], nil
+	}
+
 	base := filepath.Base(args[0])
 	if base == "." || base == "" {
 		base = "chart"
@@ -693,24 +895,69 @@ func (i *Install) NameAndChart(args []string) (string, string, error) {
 	return fmt.Sprintf("%s-%d", base, time.Now().Unix()), args[0], nil
 }
 
+// NameTemplateContext is the data available to a release name template.
+type NameTemplateContext struct {
+	// Chart is the metadata of the chart, e.g. {{ .Chart.Name }}.
+	Chart *chart.Metadata
+	// Values are the values of the release, including the defaults of the
+	// chart, e.g. {{ .Values.env }}.
+	Values chartutil.Values
+	// Timestamp is the time of the operation, e.g.
+	// {{ .Timestamp | date "20060102" }}.
+	Timestamp time.Time
+}
+
 // TemplateName renders a name template, returning the name or an error.
 func TemplateName(nameTemplate string) (string, error) {
+	return TemplateNameWithContext(nameTemplate, nil)
+}
+
+// TemplateNameWithContext renders a name template with the given context,
+// returning the name or an error. Referencing a missing value is an error.
+func TemplateNameWithContext(nameTemplate string, data *NameTemplateContext) (string, error) {
 	if nameTemplate == "" {
 		return "", nil
 	}
 
-	t, err := template.New("name-template").Funcs(sprig.TxtFuncMap()).Parse(nameTemplate)
+	t, err := template.New("name-template").Funcs(sprig.TxtFuncMap()).Option("missingkey=error").Parse(nameTemplate)
 	if err != nil {
 		return "", err
 	}
 	var b bytes.Buffer
-	if err := t.Execute(&b, nil); err != nil {
+	if data == nil {
+		err = t.Execute(&b, nil)
+	} else {
+		err = t.Execute(&b, data)
+	}
+	if err != nil {
 		return "", err
 	}
 
 	return b.String(), nil
 }
 
+// templateName renders the name template of the install with the chart and
+// the values, and validates the name.
+func (i *Install) templateName(chrt *chart.Chart, vals map[string]interface{}) (string, error) {
+	data := &NameTemplateContext{Timestamp: i.cfg.Now().Time}
+	if chrt != nil {
+		data.Chart = chrt.Metadata
+		v, err := chartutil.CoalesceValues(chrt, vals)
+		if err != nil {
+			return "", errors.Wrap(err, "name template")
+		}
+		data.Values = v
+	}
+	name, err := TemplateNameWithContext(i.NameTemplate, data)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot render name template %q", i.NameTemplate)
+	}
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return "", errors.Wrapf(err, "name template %q rendered the release name %q", i.NameTemplate, name)
+	}
+	return name, nil
+}
+
 // CheckDependencies checks the dependencies for a chart.
 func CheckDependencies(ch *chart.Chart, reqs []*chart.Dependency) error {
 	var missing []string
@@ -776,9 +1023,11 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 			getter.WithPlainHTTP(c.PlainHTTP),
 			getter.WithBasicAuth(c.Username, c.Password),
 		},
-		RepositoryConfig: settings.RepositoryConfig,
-		RepositoryCache:  settings.RepositoryCache,
-		RegistryClient:   c.registryClient,
+		RepositoryConfig:    settings.RepositoryConfig,
+		RepositoryConfigDir: settings.RepositoryConfigDir,
+		RepositoryCache:     settings.RepositoryCache,
+		RegistryClient:      c.registryClient,
+		Cache:               ContentCache(settings),
 	}
 
 	if registry.IsOCI(name) {
@@ -789,8 +1038,9 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 		dl.Verify = downloader.VerifyAlways
 	}
 	if c.RepoURL != "" {
-		chartURL, err := repo.FindChartInAuthAndTLSAndPassRepoURL(c.RepoURL, c.Username, c.Password, name, version,
-			c.CertFile, c.KeyFile, c.CaFile, c.InsecureSkipTLSverify, c.PassCredentialsAll, getter.All(settings))
+		chartURL, err := repo.FindChartInRepoURLWithOptions(c.RepoURL, c.Username, c.Password, name, version,
+			c.CertFile, c.KeyFile, c.CaFile, c.InsecureSkipTLSverify, c.PassCredentialsAll, getter.All(settings),
+			c.HTTPOptions()...)
 		if err != nil {
 			return "", err
 		}
@@ -812,11 +1062,13 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 		// services on different ports.
 		if c.PassCredentialsAll || (u1.Scheme == u2.Scheme && u1.Host == u2.Host) {
 			dl.Options = append(dl.Options, getter.WithBasicAuth(c.Username, c.Password))
+			dl.Options = append(dl.Options, c.HTTPOptions()...)
 		} else {
 			dl.Options = append(dl.Options, getter.WithBasicAuth("", ""))
 		}
 	} else {
 		dl.Options = append(dl.Options, getter.WithBasicAuth(c.Username, c.Password))
+		dl.Options = append(dl.Options, c.HTTPOptions()...)
 	}
 
 	if err := os.MkdirAll(settings.RepositoryCache, 0755); err != nil {
@@ -834,3 +1086,37 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 	}
 	return lname, nil
 }
+
+// HTTPOptions returns the getter options sending the headers and the bearer
+// token of the options. The getters only send them to the host of their URL.
+func (c *ChartPathOptions) HTTPOptions() []getter.Option {
+	return []getter.Option{
+		getter.WithHeaders(c.Headers),
+		getter.WithBearerToken(c.BearerToken),
+	}
+}
+
+// sameHost reports whether two URLs have the same scheme and host, including
+// the port.
+func sameHost(u1, u2 string) bool {
+	a, err := url.Parse(u1)
+	if err != nil {
+		return false
+	}
+	b, err := url.Parse(u2)
+	if err != nil {
+		return false
+	}
+	return a.Scheme == b.Scheme && a.Host == b.Host
+}
+
+// RepositoryOptions returns the headers and the bearer token of the options
+// as the downloader.Manager RepositoryOptions of RepoURL, so that they are
+// only sent to the repository they are given for when the dependencies of the
+// chart are updated.
+func (c *ChartPathOptions) RepositoryOptions() map[string][]getter.Option {
+	if c.RepoURL == "" || (len(c.Headers) == 0 && c.BearerToken == "") {
+		return nil
+	}
+	return map[string][]getter.Option{c.RepoURL: c.HTTPOptions()}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/releaseutil"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// Operation is the operation performed by an InstallOrUpgrade.
+type Operation string
+
+const (
+	// OperationInstall indicates that the release was installed.
+	OperationInstall Operation = "install"
+	// OperationUpgrade indicates that the release was upgraded.
+	OperationUpgrade Operation = "upgrade"
+)
+
+// InstallOrUpgrade is the action for installing a release when it does not
+// exist, and upgrading it otherwise.
+//
+// It provides the implementation of 'helm upgrade --install'. The options of
+// the embedded Upgrade apply to both operations.
+type InstallOrUpgrade struct {
+	*Upgrade
+
+	// CreateNamespace creates the release namespace when the release is
+	// installed.
+	CreateNamespace bool
+}
+
+// NewInstallOrUpgrade creates a new InstallOrUpgrade object with the given
+// configuration.
+func NewInstallOrUpgrade(cfg *Configuration) *InstallOrUpgrade {
+	u := NewUpgrade(cfg)
+	u.Install = true
+	return &InstallOrUpgrade{Upgrade: u}
+}
+
+// Run installs or upgrades the release.
+func (a *InstallOrUpgrade) Run(name string, chart *chart.Chart, vals map[string]interface{}) (*release.Release, Operation, error) {
+	return a.RunWithContext(context.Background(), name, chart, vals)
+}
+
+// RunWithContext installs the release when it has no history or was
+// uninstalled, and upgrades it otherwise. It returns the operation which was
+// performed, which is also returned on failure once it has been determined.
+//
+// A release with an operation in progress is neither installed nor upgraded.
+// When another client installs the release between the check of its history
+// and the creation of the first revision, the release is upgraded instead.
+func (a *InstallOrUpgrade) RunWithContext(ctx context.Context, name string, chart *chart.Chart, vals map[string]interface{}) (*release.Release, Operation, error) {
+	if err := a.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, "", err
+	}
+
+	op, replace, err := a.operation(name)
+	if err != nil {
+		return nil, op, err
+	}
+	if op == OperationUpgrade {
+		rel, err := a.Upgrade.RunWithContext(ctx, name, chart, vals)
+		return rel, op, err
+	}
+
+	install := a.newInstall(name, replace)
+	rel, err := install.RunWithContext(ctx, chart, vals)
+	a.SupportWarnings = install.SupportWarnings
+	a.EffectiveObjects = install.EffectiveObjects
+	if errors.Is(err, errNameInUse) || errors.Is(err, driver.ErrReleaseExists) {
+		// The release was created concurrently, before the first revision was
+		// stored, so nothing was installed.
+		a.cfg.Log("release %q was created concurrently, upgrading it", name)
+		rel, err = a.Upgrade.RunWithContext(ctx, name, chart, vals)
+		return rel, OperationUpgrade, err
+	}
+	return rel, op, err
+}
+
+// operation determines the operation from the history of the release, and
+// whether an install replaces an uninstalled release.
+func (a *InstallOrUpgrade) operation(name string) (Operation, bool, error) {
+	h, err := a.cfg.Releases.History(name)
+	if errors.Is(err, driver.ErrReleaseNotFound) || (err == nil && len(h) == 0) {
+		return OperationInstall, false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	releaseutil.Reverse(h, releaseutil.SortByRevision)
+	switch last := h[0]; {
+	case last.Info.Status == release.StatusUninstalled:
+		return OperationInstall, true, nil
+	case last.Info.Status.IsPending():
+		return OperationUpgrade, false, errors.Wrapf(errPending, "release %q is %s", name, last.Info.Status)
+	}
+	return OperationUpgrade, false, nil
+}
+
+// newInstall creates the Install of the release with the options of the
+// Upgrade.
+func (a *InstallOrUpgrade) newInstall(name string, replace bool) *Install {
+	u := a.Upgrade
+	i := NewInstall(u.cfg)
+	i.ChartPathOptions = u.ChartPathOptions
+	i.ValuesFromOptions = u.ValuesFromOptions
+	i.DeployOptions = u.DeployOptions
+	i.ReleaseName = name
+	i.CreateNamespace = a.CreateNamespace
+	i.Replace = replace
+	return i
+}
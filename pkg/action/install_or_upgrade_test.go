@@ -0,0 +1,117 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/storage"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+func installOrUpgradeAction(t *testing.T) *InstallOrUpgrade {
+	a := NewInstallOrUpgrade(actionConfigFixture(t))
+	a.Namespace = "spaced"
+	return a
+}
+
+func TestInstallOrUpgrade(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+	a := installOrUpgradeAction(t)
+
+	rel, op, err := a.Run("ahoy", buildChart(), map[string]interface{}{})
+	req.NoError(err)
+	is.Equal(OperationInstall, op)
+	is.Equal(1, rel.Version)
+	is.Equal("Install complete", rel.Info.Description)
+
+	rel, op, err = a.Run("ahoy", buildChart(), map[string]interface{}{})
+	req.NoError(err)
+	is.Equal(OperationUpgrade, op)
+	is.Equal(2, rel.Version)
+	is.Equal("Upgrade complete", rel.Info.Description)
+}
+
+func TestInstallOrUpgrade_Uninstalled(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+	a := installOrUpgradeAction(t)
+
+	old := releaseStub()
+	old.Info.Status = release.StatusUninstalled
+	req.NoError(a.cfg.Releases.Create(old))
+
+	rel, op, err := a.Run(old.Name, buildChart(), map[string]interface{}{})
+	req.NoError(err)
+	is.Equal(OperationInstall, op)
+	is.Equal(old.Version+1, rel.Version)
+	is.Equal(release.StatusDeployed, rel.Info.Status)
+}
+
+func TestInstallOrUpgrade_Pending(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+	a := installOrUpgradeAction(t)
+
+	pending := releaseStub()
+	pending.Info.Status = release.StatusPendingInstall
+	req.NoError(a.cfg.Releases.Create(pending))
+
+	_, op, err := a.Run(pending.Name, buildChart(), map[string]interface{}{})
+	is.ErrorIs(err, errPending)
+	is.Equal(OperationUpgrade, op)
+
+	last, err := a.cfg.Releases.Last(pending.Name)
+	req.NoError(err)
+	is.Equal(pending.Version, last.Version)
+}
+
+// staleDriver reports no release for its first query, as if the release was
+// created concurrently right after it was queried.
+type staleDriver struct {
+	*driver.Memory
+	stale bool
+}
+
+func (d *staleDriver) Query(labels map[string]string) ([]*release.Release, error) {
+	if d.stale {
+		d.stale = false
+		return nil, driver.ErrReleaseNotFound
+	}
+	return d.Memory.Query(labels)
+}
+
+func TestInstallOrUpgrade_CreatedConcurrently(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+	a := installOrUpgradeAction(t)
+
+	existing := releaseStub()
+	existing.Info.Status = release.StatusDeployed
+	req.NoError(a.cfg.Releases.Create(existing))
+	a.cfg.Releases = storage.Init(&staleDriver{Memory: a.cfg.Releases.Driver.(*driver.Memory), stale: true})
+
+	rel, op, err := a.Run(existing.Name, buildChart(), map[string]interface{}{})
+	req.NoError(err)
+	is.Equal(OperationUpgrade, op)
+	is.Equal(existing.Version+1, rel.Version)
+}
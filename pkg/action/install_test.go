@@ -32,9 +32,12 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"helm.sh/helm/v4/internal/test"
+	"helm.sh/helm/v4/internal/version"
 	"helm.sh/helm/v4/pkg/chart"
 	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/kube"
 	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	"helm.sh/helm/v4/pkg/postrender"
 	"helm.sh/helm/v4/pkg/release"
 	"helm.sh/helm/v4/pkg/storage/driver"
 	helmtime "helm.sh/helm/v4/pkg/time"
@@ -81,6 +84,7 @@ func TestInstallRelease(t *testing.T) {
 	is.NotEqual(len(rel.Manifest), 0)
 	is.Contains(rel.Manifest, "---\n# Source: hello/templates/hello\nhello: world")
 	is.Equal(rel.Info.Description, "Install complete")
+	is.Equal(release.ReasonInstalled, rel.Info.Reason)
 
 	// Detecting previous bug where context termination after successful release
 	// caused release to fail.
@@ -232,6 +236,37 @@ func TestInstallRelease_WithChartAndDependencyAllNotes(t *testing.T) {
 	is.Equal(rel.Info.Description, "Install complete")
 }
 
+func TestInstallRelease_Subchart(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.ReleaseName = "only-child"
+	instAction.Subchart = "child"
+
+	subchart := func(opts *chartOptions) {
+		opts.Templates = []*chart.File{
+			{Name: "templates/config", Data: []byte("color: {{ .Values.color }}")},
+		}
+	}
+	chrt := buildChart(
+		withValues(map[string]interface{}{"child": map[string]interface{}{"color": "blue"}}),
+		withDependency(withName("child"), subchart),
+	)
+	res, err := instAction.Run(chrt, map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+
+	is.Equal("child", res.Chart.Name())
+	is.Contains(res.Manifest, "# Source: child/templates/config\ncolor: blue")
+	is.NotContains(res.Manifest, "hello/templates/hello")
+
+	instAction = installAction(t)
+	instAction.Subchart = "missing"
+	_, err = instAction.Run(buildChart(withDependency(withName("child"))), map[string]interface{}{})
+	is.Error(err)
+	is.Contains(err.Error(), "subchart \"missing\" not found")
+}
+
 func TestInstallRelease_DryRun(t *testing.T) {
 	is := assert.New(t)
 	instAction := installAction(t)
@@ -360,6 +395,7 @@ func TestInstallRelease_FailedHooks(t *testing.T) {
 	is.Error(err)
 	is.Contains(res.Info.Description, "failed post-install")
 	is.Equal(release.StatusFailed, res.Info.Status)
+	is.Equal(release.ReasonHookFailed, res.Info.Reason)
 }
 
 func TestInstallRelease_ReplaceRelease(t *testing.T) {
@@ -385,6 +421,33 @@ func TestInstallRelease_ReplaceRelease(t *testing.T) {
 	is.Equal(getres.Info.Status, release.StatusDeployed)
 }
 
+func TestInstallRelease_ReplaceReleaseHistoryMax(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.Replace = true
+	instAction.MaxHistory = 2
+
+	for v := 1; v <= 3; v++ {
+		rel := releaseStub()
+		rel.Namespace = instAction.Namespace
+		rel.Version = v
+		rel.Info.Status = release.StatusSuperseded
+		if v == 3 {
+			rel.Info.Status = release.StatusUninstalled
+		}
+		is.NoError(instAction.cfg.Releases.Create(rel))
+		instAction.ReleaseName = rel.Name
+	}
+
+	res, err := instAction.Run(buildChart(), map[string]interface{}{})
+	is.NoError(err)
+	is.Equal(4, res.Version)
+
+	h, err := instAction.cfg.Releases.History(res.Name)
+	is.NoError(err)
+	is.Len(h, 2)
+}
+
 func TestInstallRelease_KubeVersion(t *testing.T) {
 	is := assert.New(t)
 	instAction := installAction(t)
@@ -400,6 +463,59 @@ func TestInstallRelease_KubeVersion(t *testing.T) {
 	is.Contains(err.Error(), "chart requires kubeVersion")
 }
 
+func TestInstallRelease_HelmVersion(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	_, err := instAction.Run(buildChart(withHelmVersion(">=3.0.0")), map[string]interface{}{})
+	is.NoError(err)
+
+	instAction.ReleaseName = "should-fail"
+	_, err = instAction.Run(buildChart(withHelmVersion(">=99.0.0")), map[string]interface{}{})
+	is.ErrorAs(err, &chartutil.ErrIncompatibleHelmVersion{})
+	_, err = instAction.cfg.Releases.Get("should-fail", 1)
+	is.Error(err, "the release must not be recorded")
+}
+
+func TestInstallRelease_ValuesRaw(t *testing.T) {
+	is := assert.New(t)
+	raw := []byte("name: &name anchored\nalias: *name\n")
+	vals := map[string]interface{}{"name": "anchored", "alias": "anchored"}
+
+	instAction := installAction(t)
+	instAction.ValuesRaw = raw
+	res, err := instAction.Run(buildChart(), vals)
+	is.NoError(err)
+	is.Equal(string(raw), res.ConfigRaw)
+	is.Equal(vals, res.Config)
+
+	// The file does not hold values that were not parsed from it.
+	instAction = installAction(t)
+	instAction.ValuesRaw = raw
+	res, err = instAction.Run(buildChart(), map[string]interface{}{"name": "anchored", "alias": "set"})
+	is.NoError(err)
+	is.Empty(res.ConfigRaw)
+}
+
+func TestInstallRelease_Operator(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	failer := instAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+	failer.IdentityName = "system:serviceaccount:ci:deployer"
+
+	res, err := instAction.Run(buildChart(), map[string]interface{}{})
+	is.NoError(err)
+	is.Equal("system:serviceaccount:ci:deployer", res.Info.Operator)
+	is.Equal(version.GetVersion(), res.Info.ClientVersion)
+
+	instAction = installAction(t)
+	failer = instAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+	failer.IdentityError = fmt.Errorf("forbidden")
+
+	res, err = instAction.Run(buildChart(), map[string]interface{}{})
+	is.NoError(err)
+	is.Empty(res.Info.Operator)
+}
+
 func TestInstallRelease_Wait(t *testing.T) {
 	is := assert.New(t)
 	instAction := installAction(t)
@@ -416,6 +532,8 @@ func TestInstallRelease_Wait(t *testing.T) {
 	is.Error(err)
 	is.Contains(res.Info.Description, "I timed out")
 	is.Equal(res.Info.Status, release.StatusFailed)
+	is.Equal(release.ReasonWaitFailed, res.Info.Reason)
+	is.Equal("I timed out", res.Info.ErrorDetail)
 
 	is.Equal(goroutines, runtime.NumGoroutine())
 }
@@ -665,6 +783,54 @@ func TestInstallOutputDirWithReleaseName(t *testing.T) {
 	is.True(os.IsNotExist(err))
 }
 
+func TestInstallRelease_NameTemplate(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.ReleaseName = ""
+	instAction.NameTemplate = `{{ .Chart.Name }}-{{ .Values.env }}-{{ .Timestamp | date "2006" }}`
+
+	vals := map[string]interface{}{"env": "prod"}
+	res, err := instAction.Run(buildChart(), vals)
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	expected := fmt.Sprintf("hello-prod-%d", instAction.cfg.Now().Year())
+	is.Equal(expected, res.Name)
+	is.Equal(expected, instAction.ReleaseName)
+
+	instAction = installAction(t)
+	instAction.ReleaseName = ""
+	instAction.NameTemplate = "{{ .Values.missing }}"
+	_, err = instAction.Run(buildChart(), vals)
+	is.ErrorContains(err, "cannot render name template")
+
+	instAction = installAction(t)
+	instAction.ReleaseName = ""
+	instAction.NameTemplate = "{{ .Values.env | upper }}"
+	_, err = instAction.Run(buildChart(), vals)
+	is.ErrorContains(err, `name template "{{ .Values.env | upper }}" rendered the release name "PROD"`)
+}
+
+func TestInstallRelease_VerifyDependencies(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.VerifyDependencies = true
+
+	chrt := buildChart(withDependency())
+	chrt.Lock = &chart.Lock{Digests: chartutil.DependencyDigests(chrt)}
+	_, err := instAction.Run(chrt, map[string]interface{}{})
+	is.NoError(err)
+
+	instAction = installAction(t)
+	instAction.VerifyDependencies = true
+	chrt = buildChart(withDependency())
+	chrt.Lock = &chart.Lock{Digests: chartutil.DependencyDigests(chrt)}
+	dep := chrt.Dependencies()[0]
+	dep.Raw = append(dep.Raw, &chart.File{Name: "templates/extra.yaml", Data: []byte("kind: Secret")})
+	_, err = instAction.Run(chrt, map[string]interface{}{})
+	is.ErrorContains(err, "chart dependencies verification failed: dependency hello-0.1.0 of chart hello was modified")
+}
+
 func TestNameAndChart(t *testing.T) {
 	is := assert.New(t)
 	instAction := installAction(t)
@@ -798,3 +964,84 @@ func TestInstallWithSystemLabels(t *testing.T) {
 
 	is.Equal(fmt.Errorf("user supplied labels contains system reserved label name. System labels: %+v", driver.GetSystemLabels()), err)
 }
+
+// resourcesKubeClient is a fake client that builds the given resources.
+type resourcesKubeClient struct {
+	*kubefake.FailingKubeClient
+	resources kube.ResourceList
+}
+
+func (c *resourcesKubeClient) Build(_ io.Reader, _ bool) (kube.ResourceList, error) {
+	return c.resources, nil
+}
+
+func (c *resourcesKubeClient) BuildContext(_ context.Context, r io.Reader, validate bool) (kube.ResourceList, error) {
+	return c.Build(r, validate)
+}
+
+func TestInstallReleaseEvents(t *testing.T) {
+	instAction := installAction(t)
+	failer := instAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+	instAction.cfg.KubeClient = &resourcesKubeClient{failer, kube.ResourceList{newMissingDeployment("web", "spaced")}}
+	instAction.Wait = true
+
+	var events []Event
+	instAction.EventHandler = func(e Event) { events = append(events, e) }
+	_, err := instAction.Run(buildChart(), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, []Event{
+		{Type: EventPhaseStarted, Phase: PhaseRender},
+		{Type: EventPhaseSucceeded, Phase: PhaseRender},
+		{Type: EventPhaseStarted, Phase: PhasePreInstallHooks},
+		{Type: EventPhaseSucceeded, Phase: PhasePreInstallHooks},
+		{Type: EventPhaseStarted, Phase: PhaseApply},
+		{Type: EventResource, Phase: PhaseApply, Kind: "Deployment", Name: "web", Namespace: "spaced", Action: "created"},
+		{Type: EventPhaseSucceeded, Phase: PhaseApply},
+		{Type: EventPhaseStarted, Phase: PhaseWait},
+		{Type: EventPhaseSucceeded, Phase: PhaseWait},
+		{Type: EventPhaseStarted, Phase: PhasePostInstallHooks},
+		{Type: EventPhaseSucceeded, Phase: PhasePostInstallHooks},
+	}, events)
+
+	events = nil
+	failer.WaitError = fmt.Errorf("I timed out")
+	instAction.ReleaseName = "come-fail-away"
+	_, err = instAction.Run(buildChart(), map[string]interface{}{})
+	require.Error(t, err)
+	last := events[len(events)-1]
+	assert.Equal(t, EventPhaseFailed, last.Type)
+	assert.Equal(t, PhaseWait, last.Phase)
+	assert.EqualError(t, last.Err, "I timed out")
+}
+
+func TestInstallRelease_ImageOverrides(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.ImageOverrides = postrender.ImageOverrides{
+		Registries: map[string]string{"docker.io": "mirror.example.com"},
+	}
+	ch := buildChart()
+	ch.Templates = []*chart.File{
+		{Name: "templates/pod", Data: []byte("apiVersion: v1\nkind: Pod\nmetadata:\n  name: web\nspec:\n  containers:\n  - name: web\n    image: nginx:1.27\n")},
+		{Name: "templates/hook", Data: []byte("apiVersion: v1\nkind: Pod\nmetadata:\n  name: migrate\n  annotations:\n    \"helm.sh/hook\": pre-install\nspec:\n  containers:\n  - name: migrate\n    image: busybox\n")},
+	}
+
+	res, err := instAction.Run(ch, map[string]interface{}{})
+	require.NoError(t, err)
+	is.Contains(res.Manifest, "image: mirror.example.com/library/nginx:1.27")
+	is.NotContains(res.Manifest, "image: nginx:1.27")
+	is.Len(res.Hooks, 1)
+	is.Contains(res.Hooks[0].Manifest, "image: mirror.example.com/library/busybox")
+
+	// The files written to the output directory have their images overridden.
+	instAction = installAction(t)
+	instAction.ImageOverrides = postrender.ImageOverrides{
+		Registries: map[string]string{"docker.io": "mirror.example.com"},
+	}
+	instAction.OutputDir = t.TempDir()
+	_, err = instAction.Run(ch, map[string]interface{}{})
+	require.NoError(t, err)
+	b, err := os.ReadFile(filepath.Join(instAction.OutputDir, ch.Name(), "templates/pod"))
+	require.NoError(t, err)
+	is.Contains(string(b), "image: mirror.example.com/library/nginx:1.27")
+}
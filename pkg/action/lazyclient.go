@@ -48,6 +48,9 @@ type lazyClient struct {
 func (s *lazyClient) init() error {
 	s.initClient.Do(func() {
 		s.client, s.clientErr = s.clientFn()
+		if s.clientErr != nil {
+			s.clientErr = &InitError{Reason: InitReasonKubeConfig, Err: s.clientErr}
+		}
 	})
 	return s.clientErr
 }
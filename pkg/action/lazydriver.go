@@ -0,0 +1,108 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"sync"
+
+	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// lazyDriver defers the creation of a storage driver connecting to a remote
+// backend, such as a database, to its first use, so that the actions not
+// storing releases never touch the network.
+type lazyDriver struct {
+	// name is the name of the driver, known before it is created
+	name string
+
+	initDriver sync.Once
+	driver     driver.Driver
+	driverErr  error
+
+	// driverFn creates the driver
+	driverFn func() (driver.Driver, error)
+}
+
+var _ driver.Driver = (*lazyDriver)(nil)
+var _ driver.MetadataLister = (*lazyDriver)(nil)
+
+func (d *lazyDriver) init() error {
+	d.initDriver.Do(func() {
+		d.driver, d.driverErr = d.driverFn()
+		if d.driverErr != nil {
+			d.driverErr = &InitError{Reason: InitReasonDriver, Err: d.driverErr}
+		}
+	})
+	return d.driverErr
+}
+
+func (d *lazyDriver) Name() string {
+	return d.name
+}
+
+func (d *lazyDriver) Create(key string, rls *release.Release) error {
+	if err := d.init(); err != nil {
+		return err
+	}
+	return d.driver.Create(key, rls)
+}
+
+func (d *lazyDriver) Update(key string, rls *release.Release) error {
+	if err := d.init(); err != nil {
+		return err
+	}
+	return d.driver.Update(key, rls)
+}
+
+func (d *lazyDriver) Delete(key string) (*release.Release, error) {
+	if err := d.init(); err != nil {
+		return nil, err
+	}
+	return d.driver.Delete(key)
+}
+
+func (d *lazyDriver) Get(key string) (*release.Release, error) {
+	if err := d.init(); err != nil {
+		return nil, err
+	}
+	return d.driver.Get(key)
+}
+
+func (d *lazyDriver) List(filter func(*release.Release) bool) ([]*release.Release, error) {
+	if err := d.init(); err != nil {
+		return nil, err
+	}
+	return d.driver.List(filter)
+}
+
+func (d *lazyDriver) ListMetadata(filter func(*release.Release) bool) ([]*release.Release, error) {
+	if err := d.init(); err != nil {
+		return nil, err
+	}
+	if ml, ok := d.driver.(driver.MetadataLister); ok {
+		return ml.ListMetadata(filter)
+	}
+	return d.driver.List(filter)
+}
+
+func (d *lazyDriver) Query(labels map[string]string) ([]*release.Release, error) {
+	if err := d.init(); err != nil {
+		return nil, err
+	}
+	return d.driver.Query(labels)
+}
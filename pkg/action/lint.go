@@ -24,7 +24,9 @@ import (
 	"github.com/pkg/errors"
 
 	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/engine"
 	"helm.sh/helm/v4/pkg/lint"
+	"helm.sh/helm/v4/pkg/lint/rules"
 	"helm.sh/helm/v4/pkg/lint/support"
 )
 
@@ -38,6 +40,16 @@ type Lint struct {
 	Quiet                bool
 	SkipSchemaValidation bool
 	KubeVersion          *chartutil.KubeVersion
+	// Strictness selects the template problems that are reported as errors
+	Strictness engine.Strictness
+	// ImportValues reports the import-values of the dependencies that refer
+	// to values which do not exist.
+	ImportValues bool
+	// UnusedValues reports the values that no template references.
+	UnusedValues bool
+	// MetadataPolicy is the policy the metadata of the charts is checked
+	// against, if set.
+	MetadataPolicy *rules.MetadataPolicy
 }
 
 // LintResult is the result of Lint
@@ -45,6 +57,16 @@ type LintResult struct {
 	TotalChartsLinted int
 	Messages          []support.Message
 	Errors            []error
+	// Combination is the name of the values combination the charts were
+	// linted with, see RunMatrix.
+	Combination string
+}
+
+// LintCombination is a named combination of values that charts are linted
+// with, e.g. the values of a production or a minimal deployment.
+type LintCombination struct {
+	Name   string
+	Values map[string]interface{}
 }
 
 // NewLint creates a new Lint object with the given configuration.
@@ -60,7 +82,7 @@ func (l *Lint) Run(paths []string, vals map[string]interface{}) *LintResult {
 	}
 	result := &LintResult{}
 	for _, path := range paths {
-		linter, err := lintChart(path, vals, l.Namespace, l.KubeVersion, l.SkipSchemaValidation)
+		linter, err := lintChart(path, vals, l.Namespace, l.KubeVersion, l.SkipSchemaValidation, lint.WithStrictness(l.Strictness), lint.WithImportValues(l.ImportValues), lint.WithUnusedValues(l.UnusedValues), lint.WithMetadataPolicy(l.MetadataPolicy))
 		if err != nil {
 			result.Errors = append(result.Errors, err)
 			continue
@@ -77,6 +99,19 @@ func (l *Lint) Run(paths []string, vals map[string]interface{}) *LintResult {
 	return result
 }
 
+// RunMatrix lints the charts once for every combination of values, so that
+// problems that only occur with some values are found. The results are
+// returned in the order of the combinations.
+func (l *Lint) RunMatrix(paths []string, matrix []LintCombination) []*LintResult {
+	results := make([]*LintResult, 0, len(matrix))
+	for _, c := range matrix {
+		result := l.Run(paths, c.Values)
+		result.Combination = c.Name
+		results = append(results, result)
+	}
+	return results
+}
+
 // HasWarningsOrErrors checks is LintResult has any warnings or errors
 func HasWarningsOrErrors(result *LintResult) bool {
 	for _, msg := range result.Messages {
@@ -87,7 +122,7 @@ func HasWarningsOrErrors(result *LintResult) bool {
 	return len(result.Errors) > 0
 }
 
-func lintChart(path string, vals map[string]interface{}, namespace string, kubeVersion *chartutil.KubeVersion, skipSchemaValidation bool) (support.Linter, error) {
+func lintChart(path string, vals map[string]interface{}, namespace string, kubeVersion *chartutil.KubeVersion, skipSchemaValidation bool, options ...lint.LinterOption) (support.Linter, error) {
 	var chartPath string
 	linter := support.Linter{}
 
@@ -126,11 +161,9 @@ func lintChart(path string, vals map[string]interface{}, namespace string, kubeV
 		return linter, errors.Wrap(err, "unable to check Chart.yaml file in chart")
 	}
 
-	return lint.RunAll(
-		chartPath,
-		vals,
-		namespace,
+	options = append([]lint.LinterOption{
 		lint.WithKubeVersion(kubeVersion),
 		lint.WithSkipSchemaValidation(skipSchemaValidation),
-	), nil
+	}, options...)
+	return lint.RunAll(chartPath, vals, namespace, options...), nil
 }
@@ -163,3 +163,30 @@ func TestLint_ChartWithWarnings(t *testing.T) {
 		}
 	})
 }
+
+func TestLint_RunMatrix(t *testing.T) {
+	testCharts := []string{"testdata/charts/chart-with-schema"}
+	matrix := []LintCombination{
+		{Name: "default"},
+		{Name: "negative-age", Values: map[string]interface{}{"age": -5}},
+	}
+
+	results := NewLint().RunMatrix(testCharts, matrix)
+	if len(results) != len(matrix) {
+		t.Fatalf("expected %d results, got %d", len(matrix), len(results))
+	}
+	for i, result := range results {
+		if result.Combination != matrix[i].Name {
+			t.Errorf("expected result %d for combination %q, got %q", i, matrix[i].Name, result.Combination)
+		}
+		if result.TotalChartsLinted != 1 {
+			t.Errorf("%s: expected 1 chart to be linted, got %d", result.Combination, result.TotalChartsLinted)
+		}
+	}
+	if len(results[0].Errors) != 0 {
+		t.Errorf("expected no errors with the default values, got %v", results[0].Errors)
+	}
+	if len(results[1].Errors) == 0 {
+		t.Error("expected the negative age to fail the schema validation")
+	}
+}
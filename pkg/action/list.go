@@ -17,6 +17,7 @@ limitations under the License.
 package action
 
 import (
+	"context"
 	"path"
 	"regexp"
 
@@ -24,6 +25,7 @@ import (
 
 	"helm.sh/helm/v4/pkg/release"
 	"helm.sh/helm/v4/pkg/releaseutil"
+	"helm.sh/helm/v4/pkg/storage/driver"
 )
 
 // ListStates represents zero or more status codes that a list item may have set
@@ -134,6 +136,11 @@ type List struct {
 	Failed       bool
 	Pending      bool
 	Selector     string
+	// MetadataOnly only decodes the metadata of the releases, which is much
+	// faster for large releases. The charts of the returned releases only
+	// hold the chart metadata, and their values, manifests and hooks are not
+	// set.
+	MetadataOnly bool
 }
 
 // NewList constructs a new *List
@@ -144,8 +151,18 @@ func NewList(cfg *Configuration) *List {
 	}
 }
 
+// listPageSize is the number of releases fetched per request from the storage
+// backend.
+const listPageSize = 500
+
 // Run executes the list command, returning a set of matches.
 func (l *List) Run() ([]*release.Release, error) {
+	return l.RunWithContext(context.Background())
+}
+
+// RunWithContext is like Run, but ctx cancels the requests to the storage
+// backend. Releases are fetched in pages of listPageSize.
+func (l *List) RunWithContext(ctx context.Context) ([]*release.Release, error) {
 	if err := l.cfg.KubeClient.IsReachable(); err != nil {
 		return nil, err
 	}
@@ -159,17 +176,23 @@ func (l *List) Run() ([]*release.Release, error) {
 		}
 	}
 
-	results, err := l.cfg.Releases.List(func(rel *release.Release) bool {
-		// Skip anything that doesn't match the filter.
-		if filter != nil && !filter.MatchString(rel.Name) {
-			return false
+	var results []*release.Release
+	opts := driver.ListOptions{Limit: listPageSize, MetadataOnly: l.MetadataOnly}
+	for {
+		page, err := l.cfg.Releases.ListContext(ctx, opts)
+		if err != nil {
+			return nil, err
 		}
-
-		return true
-	})
-
-	if err != nil {
-		return nil, err
+		for _, rel := range page.Releases {
+			// Skip anything that doesn't match the filter.
+			if filter == nil || filter.MatchString(rel.Name) {
+				results = append(results, rel)
+			}
+		}
+		if page.Continue == "" {
+			break
+		}
+		opts.Continue = page.Continue
 	}
 
 	if results == nil {
@@ -17,12 +17,15 @@ limitations under the License.
 package action
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
 
 	"helm.sh/helm/v4/pkg/release"
 	"helm.sh/helm/v4/pkg/storage"
+	"helm.sh/helm/v4/pkg/storage/driver"
 )
 
 func TestListStates(t *testing.T) {
@@ -87,6 +90,25 @@ func TestList_AllNamespaces(t *testing.T) {
 	is.Len(list, 3)
 }
 
+func TestList_MetadataOnly(t *testing.T) {
+	is := assert.New(t)
+	lister := newListFixture(t)
+	lister.cfg.Releases = storage.Init(driver.NewSecrets(fakeclientset.NewSimpleClientset().CoreV1().Secrets("default")))
+	makeMeSomeReleases(lister.cfg.Releases, t)
+	lister.MetadataOnly = true
+	list, err := lister.Run()
+	is.NoError(err)
+	is.Len(list, 3)
+	for _, rel := range list {
+		is.Equal("default", rel.Namespace)
+		is.Equal(release.StatusDeployed, rel.Info.Status)
+		is.Equal("hello", rel.Chart.Metadata.Name)
+		is.Empty(rel.Chart.Templates)
+		is.Empty(rel.Config)
+		is.Empty(rel.Hooks)
+	}
+}
+
 func TestList_Sort(t *testing.T) {
 	is := assert.New(t)
 	lister := newListFixture(t)
@@ -366,3 +388,12 @@ func TestSelectorList(t *testing.T) {
 		assert.ElementsMatch(t, expectedFilteredList, res)
 	})
 }
+
+func TestList_RunWithContextCanceled(t *testing.T) {
+	lister := newListFixture(t)
+	makeMeSomeReleases(lister.cfg.Releases, t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := lister.RunWithContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,253 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// MigrationStatus is the outcome of copying a release record to another
+// storage backend.
+type MigrationStatus string
+
+const (
+	// MigrationCopied means that the record was copied and verified.
+	MigrationCopied MigrationStatus = "Copied"
+	// MigrationSkipped means that the destination already holds the same
+	// release, e.g. because it was copied by an earlier, interrupted run.
+	MigrationSkipped MigrationStatus = "Skipped"
+	// MigrationPending means that the record would be copied, but DryRun is
+	// set.
+	MigrationPending MigrationStatus = "Pending"
+	// MigrationConflict means that the destination holds a different release
+	// under the key of the record.
+	MigrationConflict MigrationStatus = "Conflict"
+	// MigrationFailed means that the record cannot be decoded, or that it
+	// could not be copied or verified.
+	MigrationFailed MigrationStatus = "Failed"
+)
+
+// MigrateStorage is the action for copying the release records of one storage
+// backend to another, e.g. to move from the Secret driver to the SQL driver.
+//
+// It provides the implementation of 'helm release migrate'.
+type MigrateStorage struct {
+	cfg *Configuration
+
+	// Labels are set on every copied release, replacing the custom labels of
+	// the same name. A label with an empty value is removed. System labels
+	// such as "owner" and "status" are managed by the drivers and cannot be
+	// set.
+	Labels map[string]string
+	// DryRun only reports the records that would be copied.
+	DryRun bool
+}
+
+// MigratedRecord is a release record copied by MigrateStorage.
+type MigratedRecord struct {
+	// Key is the name of the record in both storage backends.
+	Key       string          `json:"key"`
+	Name      string          `json:"name,omitempty"`
+	Namespace string          `json:"namespace,omitempty"`
+	Revision  int             `json:"revision,omitempty"`
+	Status    MigrationStatus `json:"status"`
+	Message   string          `json:"message,omitempty"`
+}
+
+// NewMigrateStorage creates a new MigrateStorage object with the given
+// configuration.
+func NewMigrateStorage(cfg *Configuration) *MigrateStorage {
+	return &MigrateStorage{
+		cfg: cfg,
+	}
+}
+
+// Run copies all release records of the source to the destination, oldest
+// revision first, and reads every copy back to verify it. The source is not
+// modified.
+//
+// Records that the destination already holds with the same content are
+// skipped, so an interrupted migration is resumed by running it again. A copy
+// that fails verification is deleted from the destination. The records are
+// returned together with an error when any of them could not be copied.
+func (m *MigrateStorage) Run(source, destination driver.Driver) ([]*MigratedRecord, error) {
+	if driver.ContainsSystemLabels(m.Labels) {
+		return nil, errors.Errorf("user-defined labels must not contain the system labels %v", driver.GetSystemLabels())
+	}
+
+	records, err := scanRecords(source)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the releases of the %s driver", source.Name())
+	}
+	sort.Slice(records, func(i, j int) bool { return lessRecord(records[i], records[j]) })
+
+	migrated := make([]*MigratedRecord, 0, len(records))
+	var errs []error
+	for _, rec := range records {
+		r := m.migrate(rec, destination)
+		migrated = append(migrated, r)
+		switch r.Status {
+		case MigrationConflict, MigrationFailed:
+			errs = append(errs, errors.Errorf("%s: %s", r.Key, r.Message))
+		case MigrationCopied:
+			m.cfg.Log("copied %s to the %s driver", r.Key, destination.Name())
+		}
+	}
+	if len(errs) > 0 {
+		return migrated, errors.Errorf("migration completed with %d error(s): %s", len(errs), joinErrors(errs))
+	}
+	return migrated, nil
+}
+
+func (m *MigrateStorage) migrate(rec *driver.Record, destination driver.Driver) *MigratedRecord {
+	r := &MigratedRecord{Key: rec.Key, Name: rec.Labels["name"]}
+	if rec.Err != nil {
+		r.Revision, _ = strconv.Atoi(rec.Labels["version"])
+		r.Status, r.Message = MigrationFailed, fmt.Sprintf("the release cannot be decoded: %s", rec.Err)
+		return r
+	}
+
+	rel := *rec.Release
+	rel.Labels = m.releaseLabels(rec.Release.Labels)
+	r.Name, r.Namespace, r.Revision = rel.Name, rel.Namespace, rel.Version
+
+	existing, err := destination.Get(rec.Key)
+	switch {
+	case err == nil:
+		if ok, err := sameRelease(&rel, existing); err != nil {
+			r.Status, r.Message = MigrationFailed, err.Error()
+		} else if ok {
+			r.Status, r.Message = MigrationSkipped, "the destination already holds the release"
+		} else {
+			r.Status, r.Message = MigrationConflict, "the destination holds a different release under the same key"
+		}
+		return r
+	case !errors.Is(err, driver.ErrReleaseNotFound):
+		r.Status, r.Message = MigrationFailed, fmt.Sprintf("failed to check the destination: %s", err)
+		return r
+	}
+
+	if m.DryRun {
+		r.Status = MigrationPending
+		return r
+	}
+	if err := destination.Create(rec.Key, &rel); err != nil {
+		r.Status, r.Message = MigrationFailed, fmt.Sprintf("failed to create the record: %s", err)
+		return r
+	}
+	if err := verifyRecord(destination, rec.Key, &rel); err != nil {
+		if _, derr := destination.Delete(rec.Key); derr != nil {
+			m.cfg.Log("failed to delete the unverified copy of %s: %s", rec.Key, derr)
+		}
+		r.Status, r.Message = MigrationFailed, err.Error()
+		return r
+	}
+	r.Status = MigrationCopied
+	return r
+}
+
+// releaseLabels returns the custom labels of a copied release.
+func (m *MigrateStorage) releaseLabels(labels map[string]string) map[string]string {
+	out := customLabels(labels)
+	for k, v := range m.Labels {
+		if v == "" {
+			delete(out, k)
+		} else {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// verifyRecord reads a copied release back from the destination and compares
+// it with the release that was written.
+func verifyRecord(d driver.Driver, key string, want *release.Release) error {
+	got, err := d.Get(key)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the copy back")
+	}
+	ok, err := sameRelease(want, got)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("the copy differs from the source release")
+	}
+	return nil
+}
+
+// sameRelease reports whether two releases have the same encoding and custom
+// labels.
+func sameRelease(a, b *release.Release) (bool, error) {
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to encode the release")
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to encode the release")
+	}
+	return bytes.Equal(ja, jb) && maps.Equal(customLabels(a.Labels), customLabels(b.Labels)), nil
+}
+
+func customLabels(labels map[string]string) map[string]string {
+	out := map[string]string{}
+	for k, v := range labels {
+		if !isSystemLabel(k) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func isSystemLabel(key string) bool {
+	for _, l := range driver.GetSystemLabels() {
+		if key == l {
+			return true
+		}
+	}
+	return false
+}
+
+// lessRecord orders records by namespace, release name and revision. Records
+// that cannot be decoded are ordered by key after the others.
+func lessRecord(a, b *driver.Record) bool {
+	if (a.Release == nil) != (b.Release == nil) {
+		return b.Release == nil
+	}
+	if a.Release == nil {
+		return a.Key < b.Key
+	}
+	ra, rb := a.Release, b.Release
+	if ra.Namespace != rb.Namespace {
+		return ra.Namespace < rb.Namespace
+	}
+	if ra.Name != rb.Name {
+		return ra.Name < rb.Name
+	}
+	return ra.Version < rb.Version
+}
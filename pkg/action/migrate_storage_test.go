@@ -0,0 +1,114 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+func TestMigrateStorage(t *testing.T) {
+	is := assert.New(t)
+	config := actionConfigFixture(t)
+
+	for v := 1; v <= 3; v++ {
+		rel := namedReleaseStub("web", release.StatusSuperseded)
+		rel.Version = v
+		rel.Labels = map[string]string{"team": "a", "tier": "frontend"}
+		require.NoError(t, config.Releases.Create(rel))
+	}
+	db := namedReleaseStub("db", release.StatusDeployed)
+	require.NoError(t, config.Releases.Create(db))
+
+	dest := driver.NewSecrets(fake.NewSimpleClientset().CoreV1().Secrets("default"))
+	client := NewMigrateStorage(config)
+	client.Labels = map[string]string{"team": "b", "tier": ""}
+
+	client.DryRun = true
+	records, err := client.Run(config.Releases.Driver, dest)
+	require.NoError(t, err)
+	is.Len(records, 4)
+	for _, r := range records {
+		is.Equal(MigrationPending, r.Status)
+	}
+	_, err = dest.Get("sh.helm.release.v1.web.v1")
+	is.ErrorIs(err, driver.ErrReleaseNotFound, "dry run must not copy records")
+
+	// copy one record to resume from
+	client.DryRun = false
+	rel, err := config.Releases.Get("web", 1)
+	require.NoError(t, err)
+	copied := *rel
+	copied.Labels = map[string]string{"team": "b"}
+	require.NoError(t, dest.Create("sh.helm.release.v1.web.v1", &copied))
+
+	records, err = client.Run(config.Releases.Driver, dest)
+	require.NoError(t, err)
+	var keys []string
+	statuses := map[string]MigrationStatus{}
+	for _, r := range records {
+		keys = append(keys, r.Key)
+		statuses[r.Key] = r.Status
+	}
+	is.Equal([]string{
+		"sh.helm.release.v1.db.v1",
+		"sh.helm.release.v1.web.v1",
+		"sh.helm.release.v1.web.v2",
+		"sh.helm.release.v1.web.v3",
+	}, keys)
+	is.Equal(map[string]MigrationStatus{
+		"sh.helm.release.v1.db.v1":  MigrationCopied,
+		"sh.helm.release.v1.web.v1": MigrationSkipped,
+		"sh.helm.release.v1.web.v2": MigrationCopied,
+		"sh.helm.release.v1.web.v3": MigrationCopied,
+	}, statuses)
+
+	got, err := dest.Get("sh.helm.release.v1.web.v3")
+	require.NoError(t, err)
+	is.Equal(map[string]string{"team": "b"}, got.Labels)
+	is.Equal(3, got.Version)
+
+	src, err := config.Releases.Get("web", 3)
+	require.NoError(t, err)
+	is.Equal(map[string]string{"team": "a", "tier": "frontend"}, src.Labels, "the source must not be modified")
+}
+
+func TestMigrateStorageConflict(t *testing.T) {
+	config := actionConfigFixture(t)
+	require.NoError(t, config.Releases.Create(releaseStub()))
+
+	dest := driver.NewMemory()
+	other := releaseStub()
+	other.Manifest = "different"
+	require.NoError(t, dest.Create("sh.helm.release.v1.angry-panda.v1", other))
+
+	records, err := NewMigrateStorage(config).Run(config.Releases.Driver, dest)
+	require.Len(t, records, 1)
+	assert.Equal(t, MigrationConflict, records[0].Status)
+	assert.ErrorContains(t, err, "migration completed with 1 error(s)")
+
+	client := NewMigrateStorage(config)
+	client.Labels = map[string]string{"owner": "someone"}
+	_, err = client.Run(config.Releases.Driver, dest)
+	assert.ErrorContains(t, err, "must not contain the system labels")
+}
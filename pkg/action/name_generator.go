@@ -0,0 +1,186 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// maxNameAttempts is the number of names an Install generates before giving up
+// when the generated names are taken.
+const maxNameAttempts = 100
+
+// maxGeneratedNameLen is the maximum length of a release name.
+const maxGeneratedNameLen = 53
+
+// NameGenerator generates release names for an Install with GenerateName set.
+type NameGenerator interface {
+	// GenerateName returns a release name for the chart and the values. The
+	// attempt is 0 for the first name, and is incremented each time the
+	// previous name is taken by an existing release.
+	GenerateName(chrt *chart.Chart, vals map[string]interface{}, attempt int) (string, error)
+}
+
+// NameGenerators are the built-in name generators by name.
+var NameGenerators = map[string]NameGenerator{
+	"timestamp":   TimestampNameGenerator{},
+	"petname":     PetNameGenerator{},
+	"values-hash": ValuesHashNameGenerator{},
+	"sequential":  SequentialNameGenerator{},
+}
+
+// NameGeneratorNames returns the names of the built-in name generators.
+func NameGeneratorNames() []string {
+	names := make([]string, 0, len(NameGenerators))
+	for name := range NameGenerators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TimestampNameGenerator names releases after the chart and the current Unix
+// time, e.g. "nginx-1700000000".
+type TimestampNameGenerator struct{}
+
+// GenerateName implements NameGenerator.
+func (TimestampNameGenerator) GenerateName(chrt *chart.Chart, _ map[string]interface{}, attempt int) (string, error) {
+	return nameWithSuffix(chartBaseName(chrt), fmt.Sprint(time.Now().Unix()+int64(attempt))), nil
+}
+
+// PetNameGenerator names releases with a random adjective and animal, e.g.
+// "wobbly-panda".
+type PetNameGenerator struct{}
+
+// GenerateName implements NameGenerator.
+func (PetNameGenerator) GenerateName(_ *chart.Chart, _ map[string]interface{}, attempt int) (string, error) {
+	name := petAdjectives[rand.IntN(len(petAdjectives))] + "-" + petAnimals[rand.IntN(len(petAnimals))]
+	if attempt > 0 {
+		// Only a few thousand names exist, so a number is added once taken.
+		name = fmt.Sprintf("%s-%d", name, rand.IntN(1000))
+	}
+	return name, nil
+}
+
+// ValuesHashNameGenerator names releases after the chart and a hash of the
+// values, e.g. "nginx-3f2a9c1b", so that installing a chart with the same
+// values generates the same name.
+type ValuesHashNameGenerator struct{}
+
+// GenerateName implements NameGenerator.
+func (ValuesHashNameGenerator) GenerateName(chrt *chart.Chart, vals map[string]interface{}, attempt int) (string, error) {
+	base := chartBaseName(chrt)
+	// Maps are encoded with sorted keys, so the encoding is stable.
+	data, err := json.Marshal(vals)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot hash the values")
+	}
+	suffix := fmt.Sprintf("%x", sha256.Sum256(append([]byte(base+"\n"), data...)))[:8]
+	if attempt > 0 {
+		suffix = fmt.Sprintf("%s-%d", suffix, attempt+1)
+	}
+	return nameWithSuffix(base, suffix), nil
+}
+
+// SequentialNameGenerator names releases after the chart and the first free
+// number, e.g. "nginx-1", then "nginx-2".
+type SequentialNameGenerator struct{}
+
+// GenerateName implements NameGenerator.
+func (SequentialNameGenerator) GenerateName(chrt *chart.Chart, _ map[string]interface{}, attempt int) (string, error) {
+	return nameWithSuffix(chartBaseName(chrt), fmt.Sprint(attempt+1)), nil
+}
+
+// generateName generates a release name which is not taken by an existing
+// release.
+func (i *Install) generateName(chrt *chart.Chart, vals map[string]interface{}) (string, error) {
+	for attempt := 0; attempt < maxNameAttempts; attempt++ {
+		name, err := i.NameGenerator.GenerateName(chrt, vals, attempt)
+		if err != nil {
+			return "", errors.Wrap(err, "cannot generate a release name")
+		}
+		if err := chartutil.ValidateReleaseName(name); err != nil {
+			return "", errors.Wrapf(err, "generated release name %q", name)
+		}
+		h, err := i.cfg.Releases.History(name)
+		if errors.Is(err, driver.ErrReleaseNotFound) || (err == nil && len(h) == 0) {
+			return name, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		i.cfg.Log("release name %q is taken, generating another one", name)
+	}
+	return "", errors.Errorf("cannot generate a release name which is not taken after %d attempts", maxNameAttempts)
+}
+
+// chartBaseName returns the name of the chart made valid for a release name.
+func chartBaseName(chrt *chart.Chart) string {
+	if chrt == nil || chrt.Metadata == nil {
+		return "chart"
+	}
+	base := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		}
+		return '-'
+	}, chrt.Name())
+	if base = strings.Trim(base, "-"); base == "" {
+		return "chart"
+	}
+	return base
+}
+
+// nameWithSuffix joins the base and the suffix of a name, truncating the base
+// to the maximum length of a release name.
+func nameWithSuffix(base, suffix string) string {
+	if n := maxGeneratedNameLen - len(suffix) - 1; len(base) > n {
+		base = strings.TrimRight(base[:n], "-")
+	}
+	return base + "-" + suffix
+}
+
+var petAdjectives = []string{
+	"agile", "amber", "bold", "brave", "bright", "calm", "clever", "cosmic",
+	"crimson", "curious", "dapper", "eager", "fancy", "fluffy", "gentle", "giddy",
+	"golden", "happy", "hasty", "jolly", "keen", "lucky", "mellow", "nimble",
+	"plucky", "quiet", "rusty", "silly", "snappy", "sunny", "swift", "tender",
+	"vivid", "wandering", "witty", "wobbly", "zany", "zealous",
+}
+
+var petAnimals = []string{
+	"albatross", "badger", "beaver", "bison", "camel", "cheetah", "crab", "dingo",
+	"dolphin", "eagle", "ferret", "gecko", "gopher", "heron", "ibis", "jackal",
+	"koala", "lemur", "llama", "lynx", "marmot", "narwhal", "newt", "octopus",
+	"otter", "panda", "pelican", "penguin", "puffin", "quokka", "rabbit", "salmon",
+	"seal", "sloth", "tapir", "turtle", "walrus", "wombat", "yak", "zebra",
+}
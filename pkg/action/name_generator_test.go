@@ -0,0 +1,82 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chartutil"
+)
+
+func TestNameGenerators(t *testing.T) {
+	chrt := &chart.Chart{Metadata: &chart.Metadata{Name: "My_Chart"}}
+	vals := map[string]interface{}{"env": "prod", "replicas": 3}
+
+	for name, g := range NameGenerators {
+		t.Run(name, func(t *testing.T) {
+			for attempt := 0; attempt < 3; attempt++ {
+				n, err := g.GenerateName(chrt, vals, attempt)
+				require.NoError(t, err)
+				assert.NoError(t, chartutil.ValidateReleaseName(n), n)
+			}
+		})
+	}
+
+	n, err := SequentialNameGenerator{}.GenerateName(chrt, vals, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "my-chart-2", n)
+
+	first, err := ValuesHashNameGenerator{}.GenerateName(chrt, vals, 0)
+	require.NoError(t, err)
+	second, err := ValuesHashNameGenerator{}.GenerateName(chrt, map[string]interface{}{"replicas": 3, "env": "prod"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	other, err := ValuesHashNameGenerator{}.GenerateName(chrt, map[string]interface{}{"env": "dev"}, 0)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, other)
+
+	long := &chart.Chart{Metadata: &chart.Metadata{Name: strings.Repeat("a", 60)}}
+	n, err = SequentialNameGenerator{}.GenerateName(long, nil, 0)
+	require.NoError(t, err)
+	assert.NoError(t, chartutil.ValidateReleaseName(n), n)
+}
+
+func TestInstallRelease_NameGenerator(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+	config := actionConfigFixture(t)
+
+	for _, expected := range []string{"hello-1", "hello-2"} {
+		instAction := NewInstall(config)
+		instAction.Namespace = "spaced"
+		instAction.GenerateName = true
+		instAction.NameGenerator = SequentialNameGenerator{}
+
+		name, _, err := instAction.NameAndChart([]string{"./hello"})
+		req.NoError(err)
+		is.Empty(name)
+
+		res, err := instAction.Run(buildChart(), map[string]interface{}{})
+		req.NoError(err)
+		is.Equal(expected, res.Name)
+	}
+}
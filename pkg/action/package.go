@@ -53,6 +53,13 @@ type Package struct {
 	KeyFile               string
 	CaFile                string
 	InsecureSkipTLSverify bool
+
+	// CosignKey is the private key signing the chart with a cosign signature
+	// instead of a PGP provenance file, when Signer is nil.
+	CosignKey string
+	// Signer signs the chart when set, instead of the PGP key or the cosign
+	// key.
+	Signer provenance.Signer
 }
 
 // NewPackage creates a new Package object with the given configuration.
@@ -104,7 +111,7 @@ func (p *Package) Run(path string, _ map[string]interface{}) (string, error) {
 	}
 
 	if p.Sign {
-		err = p.Clearsign(name)
+		err = p.signChart(name)
 	}
 
 	return name, err
@@ -118,6 +125,29 @@ func validateVersion(ver string) error {
 	return nil
 }
 
+// signChart signs a chart with the Signer, the cosign key or the PGP key.
+func (p *Package) signChart(filename string) error {
+	signer := p.Signer
+	if signer == nil && p.CosignKey == "" {
+		return p.Clearsign(filename)
+	}
+	if signer == nil {
+		passphraseFetcher, err := p.passphraseFetcher()
+		if err != nil {
+			return err
+		}
+		if signer, err = provenance.NewCosignSignerFromKeyFile(p.CosignKey, passphraseFetcher); err != nil {
+			return err
+		}
+	}
+
+	sig, err := signer.Sign(filename)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename+signer.Extension(), sig, 0644)
+}
+
 // Clearsign signs a chart
 func (p *Package) Clearsign(filename string) error {
 	// Load keyring
@@ -126,12 +156,9 @@ func (p *Package) Clearsign(filename string) error {
 		return err
 	}
 
-	passphraseFetcher := promptUser
-	if p.PassphraseFile != "" {
-		passphraseFetcher, err = passphraseFileFetcher(p.PassphraseFile, os.Stdin)
-		if err != nil {
-			return err
-		}
+	passphraseFetcher, err := p.passphraseFetcher()
+	if err != nil {
+		return err
 	}
 
 	if err := signer.DecryptKey(passphraseFetcher); err != nil {
@@ -146,6 +173,15 @@ func (p *Package) Clearsign(filename string) error {
 	return os.WriteFile(filename+".prov", []byte(sig), 0644)
 }
 
+// passphraseFetcher returns the provenance.PassphraseFetcher reading the
+// passphrase file, or prompting the user when there is none.
+func (p *Package) passphraseFetcher() (provenance.PassphraseFetcher, error) {
+	if p.PassphraseFile != "" {
+		return passphraseFileFetcher(p.PassphraseFile, os.Stdin)
+	}
+	return promptUser, nil
+}
+
 // promptUser implements provenance.PassphraseFetcher
 func promptUser(name string) ([]byte, error) {
 	fmt.Printf("Password for key %q >  ", name)
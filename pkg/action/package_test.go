@@ -19,6 +19,7 @@ package action
 import (
 	"os"
 	"path"
+	"path/filepath"
 	"testing"
 
 	"github.com/Masterminds/semver/v3"
@@ -119,3 +120,36 @@ func TestValidateVersion(t *testing.T) {
 		})
 	}
 }
+
+// fakeSigner signs charts with their file name.
+type fakeSigner struct{}
+
+func (fakeSigner) Sign(chartpath string) ([]byte, error) {
+	return []byte("signed " + filepath.Base(chartpath)), nil
+}
+
+func (fakeSigner) Extension() string {
+	return ".fake"
+}
+
+func TestPackageSigner(t *testing.T) {
+	client := NewPackage()
+	client.Destination = t.TempDir()
+	client.Sign = true
+	client.Signer = fakeSigner{}
+
+	name, err := client.Run("testdata/charts/chart-with-schema", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := os.ReadFile(name + ".fake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "signed " + filepath.Base(name); string(sig) != expect {
+		t.Errorf("Expected %q, got %q", expect, sig)
+	}
+	if _, err := os.Stat(name + ".prov"); !os.IsNotExist(err) {
+		t.Errorf("Expected no provenance file, got %v", err)
+	}
+}
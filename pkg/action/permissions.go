@@ -0,0 +1,205 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes"
+
+	"helm.sh/helm/v4/pkg/kube"
+	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// ResourcePermission is the permission to perform a verb on a resource, as
+// checked with a SelfSubjectAccessReview.
+type ResourcePermission struct {
+	Verb     string `json:"verb"`
+	Group    string `json:"group,omitempty"`
+	Resource string `json:"resource"`
+	// Namespace is empty for cluster-scoped resources.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+func (p ResourcePermission) String() string {
+	res := p.Resource
+	if p.Group != "" {
+		res += "." + p.Group
+	}
+	if p.Namespace == "" {
+		return fmt.Sprintf("%s %s (cluster-scoped)", p.Verb, res)
+	}
+	return fmt.Sprintf("%s %s in namespace %q", p.Verb, res, p.Namespace)
+}
+
+// MissingPermissionsError reports the permissions that the user lacks to
+// perform an operation, found before anything was applied.
+type MissingPermissionsError struct {
+	// Missing are sorted by namespace, resource and verb.
+	Missing []ResourcePermission
+}
+
+func (e *MissingPermissionsError) Error() string {
+	lines := make([]string, len(e.Missing))
+	for i, p := range e.Missing {
+		lines[i] = p.String()
+	}
+	return fmt.Sprintf("missing %d permission(s):\n- %s", len(e.Missing), strings.Join(lines, "\n- "))
+}
+
+// permissionSet is the set of permissions needed by an operation.
+type permissionSet map[ResourcePermission]bool
+
+// addResources adds the verbs on the resources of the list.
+func (s permissionSet) addResources(resources kube.ResourceList, verbs ...string) {
+	for _, info := range resources {
+		for _, verb := range verbs {
+			s[resourcePermission(verb, info)] = true
+		}
+	}
+}
+
+func resourcePermission(verb string, info *resource.Info) ResourcePermission {
+	p := ResourcePermission{
+		Verb:     verb,
+		Group:    info.Mapping.Resource.Group,
+		Resource: info.Mapping.Resource.Resource,
+	}
+	if info.Mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		p.Namespace = info.Namespace
+	}
+	return p
+}
+
+func (s permissionSet) sorted() []ResourcePermission {
+	perms := make([]ResourcePermission, 0, len(s))
+	for p := range s {
+		perms = append(perms, p)
+	}
+	sort.Slice(perms, func(i, j int) bool {
+		a, b := perms[i], perms[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		return a.Verb < b.Verb
+	})
+	return perms
+}
+
+// addHookPermissions adds the permissions to create the resources of the
+// hooks of the release, and to delete them for their delete policies. The
+// test hooks are left out as they are run by 'helm test'.
+func (cfg *Configuration) addHookPermissions(perms permissionSet, hooks []*release.Hook) error {
+	for _, h := range hooks {
+		if isTestHook(h) {
+			continue
+		}
+		resources, err := cfg.KubeClient.Build(bytes.NewBufferString(h.Manifest), false)
+		if err != nil {
+			return errors.Wrapf(err, "unable to build kubernetes object for hook %s", h.Path)
+		}
+		perms.addResources(resources, "create", "delete")
+	}
+	return nil
+}
+
+func isTestHook(h *release.Hook) bool {
+	for _, e := range h.Events {
+		if e == release.HookTest {
+			return true
+		}
+	}
+	return false
+}
+
+// addStoragePermissions adds the permissions to record the release in the
+// namespace, for the drivers storing releases in the cluster.
+func (cfg *Configuration) addStoragePermissions(perms permissionSet, namespace string) {
+	var res string
+	switch cfg.Releases.Name() {
+	case driver.SecretsDriverName:
+		res = "secrets"
+	case driver.ConfigMapsDriverName:
+		res = "configmaps"
+	default:
+		return
+	}
+	for _, verb := range []string{"list", "create", "update"} {
+		perms[ResourcePermission{Verb: verb, Resource: res, Namespace: namespace}] = true
+	}
+}
+
+// checkPermissions checks that the user has all the permissions, returning a
+// *MissingPermissionsError listing the ones it lacks.
+func (cfg *Configuration) checkPermissions(ctx context.Context, perms permissionSet) error {
+	if len(perms) == 0 {
+		return nil
+	}
+	client, err := cfg.KubernetesClientSet()
+	if err != nil {
+		return errors.Wrap(err, "unable to check permissions")
+	}
+	missing, err := missingPermissions(ctx, client, perms.sorted())
+	if err != nil {
+		return errors.Wrap(err, "unable to check permissions")
+	}
+	if len(missing) > 0 {
+		return &MissingPermissionsError{Missing: missing}
+	}
+	return nil
+}
+
+// missingPermissions returns the permissions that the user lacks, in order.
+func missingPermissions(ctx context.Context, client kubernetes.Interface, perms []ResourcePermission) ([]ResourcePermission, error) {
+	var missing []ResourcePermission
+	for _, p := range perms {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: p.Namespace,
+					Verb:      p.Verb,
+					Group:     p.Group,
+					Resource:  p.Resource,
+				},
+			},
+		}
+		res, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Status.Allowed {
+			missing = append(missing, p)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"helm.sh/helm/v4/pkg/kube"
+	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/storage"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+func permissionTestResource(group, res, name string, namespaced bool) *resource.Info {
+	scope := meta.RESTScopeRoot
+	if namespaced {
+		scope = meta.RESTScopeNamespace
+	}
+	// The kind only needs to be distinct for the resources to be told apart.
+	gvk := schema.GroupVersionKind{Group: group, Version: "v1", Kind: res}
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	return &resource.Info{
+		Name:      name,
+		Namespace: "shop",
+		Mapping: &meta.RESTMapping{
+			Resource:         gvk.GroupVersion().WithResource(res),
+			GroupVersionKind: gvk,
+			Scope:            scope,
+		},
+		Object: obj,
+	}
+}
+
+func TestUpgradeRequiredPermissions(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	cfg.Releases = storage.Init(driver.NewSecrets(nil))
+	upAction := NewUpgrade(cfg)
+
+	deployment := permissionTestResource("apps", "deployments", "web", true)
+	service := permissionTestResource("", "services", "web", true)
+	role := permissionTestResource("rbac.authorization.k8s.io", "clusterroles", "web", false)
+	job := permissionTestResource("batch", "jobs", "migrate", true)
+
+	perms, err := upAction.requiredPermissions(
+		&release.Release{Namespace: "shop"},
+		kube.ResourceList{deployment, service},
+		kube.ResourceList{deployment, role, job},
+		kube.ResourceList{job},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []ResourcePermission{
+		{Verb: "create", Group: "rbac.authorization.k8s.io", Resource: "clusterroles"},
+		{Verb: "create", Resource: "secrets", Namespace: "shop"},
+		{Verb: "list", Resource: "secrets", Namespace: "shop"},
+		{Verb: "update", Resource: "secrets", Namespace: "shop"},
+		{Verb: "delete", Resource: "services", Namespace: "shop"},
+		{Verb: "patch", Group: "apps", Resource: "deployments", Namespace: "shop"},
+		{Verb: "create", Group: "batch", Resource: "jobs", Namespace: "shop"},
+		{Verb: "delete", Group: "batch", Resource: "jobs", Namespace: "shop"},
+	}, perms.sorted())
+}
+
+func TestInstallRequiredPermissions(t *testing.T) {
+	instAction := installAction(t)
+	instAction.CreateNamespace = true
+
+	deployment := permissionTestResource("apps", "deployments", "web", true)
+	service := permissionTestResource("", "services", "web", true)
+	perms, err := instAction.requiredPermissions(
+		&release.Release{Namespace: "shop"},
+		kube.ResourceList{deployment, service},
+		kube.ResourceList{service},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []ResourcePermission{
+		{Verb: "create", Resource: "namespaces"},
+		{Verb: "patch", Resource: "services", Namespace: "shop"},
+		{Verb: "create", Group: "apps", Resource: "deployments", Namespace: "shop"},
+	}, perms.sorted())
+}
+
+func TestMissingPermissions(t *testing.T) {
+	client := fakeclientset.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = review.Spec.ResourceAttributes.Verb != "delete"
+		return true, review, nil
+	})
+
+	missing, err := missingPermissions(context.Background(), client, []ResourcePermission{
+		{Verb: "create", Group: "apps", Resource: "deployments", Namespace: "shop"},
+		{Verb: "delete", Group: "apps", Resource: "deployments", Namespace: "shop"},
+		{Verb: "delete", Group: "rbac.authorization.k8s.io", Resource: "clusterroles"},
+	})
+	require.NoError(t, err)
+	err = &MissingPermissionsError{Missing: missing}
+	assert.EqualError(t, err, `missing 2 permission(s):
+- delete deployments.apps in namespace "shop"
+- delete clusterroles.rbac.authorization.k8s.io (cluster-scoped)`)
+}
@@ -0,0 +1,193 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"strings"
+
+	"github.com/mitchellh/copystructure"
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/pkg/chartutil"
+)
+
+// PinnedValues is the action for viewing and editing the values pinned to a
+// release.
+//
+// Pinned values belong to the release rather than to one of its revisions:
+// they are merged into the values every upgrade renders with, over the values
+// reused from the previous revision but under the values supplied to the
+// upgrade, and are kept by rollbacks. They are not stored in the values of the
+// revisions, so unpinned values are not reused. Editing them does not create a
+// revision.
+//
+// It provides the implementation of 'helm release pin'.
+type PinnedValues struct {
+	cfg *Configuration
+
+	// Replace makes Set replace all the pinned values instead of merging
+	// into them.
+	Replace bool
+}
+
+// NewPinnedValues creates a new PinnedValues object with the given configuration.
+func NewPinnedValues(cfg *Configuration) *PinnedValues {
+	return &PinnedValues{
+		cfg: cfg,
+	}
+}
+
+// Get returns the values pinned to the release.
+func (p *PinnedValues) Get(name string) (map[string]interface{}, error) {
+	if err := p.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	rel, err := p.cfg.Releases.Last(name)
+	if err != nil {
+		return nil, err
+	}
+	return rel.PinnedValues, nil
+}
+
+// Set pins the values to the release, merging them into the values already
+// pinned unless Replace is set. A null value unpins the key. It returns the
+// pinned values.
+func (p *PinnedValues) Set(name string, vals map[string]interface{}) (map[string]interface{}, error) {
+	return p.update(name, func(pinned map[string]interface{}) (map[string]interface{}, error) {
+		if p.Replace {
+			pinned = map[string]interface{}{}
+		}
+		v, err := copystructure.Copy(vals)
+		if err != nil {
+			return nil, err
+		}
+		// The null values are kept by the merge to unpin the keys below.
+		merged := chartutil.MergeTables(v.(map[string]interface{}), pinned)
+		removeNullValues(merged)
+		return merged, nil
+	})
+}
+
+// Unset unpins the keys from the release. A key is a dotted path, e.g.
+// "image.tag". It returns the pinned values.
+func (p *PinnedValues) Unset(name string, keys ...string) (map[string]interface{}, error) {
+	return p.update(name, func(pinned map[string]interface{}) (map[string]interface{}, error) {
+		for _, key := range keys {
+			if !unsetValue(pinned, strings.Split(key, ".")) {
+				return nil, errors.Errorf("value %q is not pinned", key)
+			}
+		}
+		return pinned, nil
+	})
+}
+
+// update edits the pinned values in the latest revision of the release, in
+// place.
+func (p *PinnedValues) update(name string, edit func(map[string]interface{}) (map[string]interface{}, error)) (map[string]interface{}, error) {
+	if err := p.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, errors.Errorf("pin values: Release name is invalid: %s", name)
+	}
+	rel, err := p.cfg.Releases.Last(name)
+	if err != nil {
+		return nil, err
+	}
+	// An operation in progress would not see the edit, and would store its
+	// revision with the former pinned values.
+	if rel.Info.Status.IsPending() {
+		return nil, errPending
+	}
+
+	v, err := copystructure.Copy(rel.PinnedValues)
+	if err != nil {
+		return nil, err
+	}
+	pinned, _ := v.(map[string]interface{})
+	if pinned == nil {
+		pinned = map[string]interface{}{}
+	}
+	if pinned, err = edit(pinned); err != nil {
+		return nil, err
+	}
+	if len(pinned) == 0 {
+		pinned = nil
+	}
+
+	rel.PinnedValues = pinned
+	if err := p.cfg.Releases.Update(rel); err != nil {
+		return nil, errors.Wrapf(err, "failed to pin values to release %q", name)
+	}
+	return pinned, nil
+}
+
+// withPinnedValues returns the values to render an upgrade with: the values
+// supplied to the upgrade override the pinned values, which override the
+// values reused from the previous revision.
+func withPinnedValues(reused, supplied, pinned map[string]interface{}) (map[string]interface{}, error) {
+	if len(pinned) == 0 {
+		return reused, nil
+	}
+	v, err := copystructure.Copy([]map[string]interface{}{reused, supplied, pinned})
+	if err != nil {
+		return nil, err
+	}
+	copies := v.([]map[string]interface{})
+	reused, supplied, pinned = copies[0], copies[1], copies[2]
+	if supplied == nil {
+		supplied = map[string]interface{}{}
+	}
+	return chartutil.CoalesceTables(supplied, chartutil.CoalesceTables(pinned, reused)), nil
+}
+
+// removeNullValues removes the null values, and the tables left empty, from
+// the values.
+func removeNullValues(vals map[string]interface{}) {
+	for k, v := range vals {
+		switch v := v.(type) {
+		case nil:
+			delete(vals, k)
+		case map[string]interface{}:
+			removeNullValues(v)
+			if len(v) == 0 {
+				delete(vals, k)
+			}
+		}
+	}
+}
+
+// unsetValue removes the value at the path, and the tables left empty by
+// it. It reports whether the value was set.
+func unsetValue(vals map[string]interface{}, path []string) bool {
+	v, ok := vals[path[0]]
+	if !ok {
+		return false
+	}
+	if len(path) == 1 {
+		delete(vals, path[0])
+		return true
+	}
+	table, ok := v.(map[string]interface{})
+	if !ok || !unsetValue(table, path[1:]) {
+		return false
+	}
+	if len(table) == 0 {
+		delete(vals, path[0])
+	}
+	return true
+}
@@ -0,0 +1,154 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/release"
+)
+
+func TestPinnedValues(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	rel := releaseStub()
+	require.NoError(t, cfg.Releases.Create(rel))
+
+	client := NewPinnedValues(cfg)
+	pinned, err := client.Set(rel.Name, map[string]interface{}{
+		"replicas": 3,
+		"image":    map[string]interface{}{"tag": "1.2.3", "pullPolicy": "Always"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"replicas": 3,
+		"image":    map[string]interface{}{"tag": "1.2.3", "pullPolicy": "Always"},
+	}, pinned)
+
+	pinned, err = client.Set(rel.Name, map[string]interface{}{
+		"replicas": nil,
+		"image":    map[string]interface{}{"tag": "1.2.4"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"image": map[string]interface{}{"tag": "1.2.4", "pullPolicy": "Always"},
+	}, pinned)
+
+	pinned, err = client.Unset(rel.Name, "image.pullPolicy")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"image": map[string]interface{}{"tag": "1.2.4"},
+	}, pinned)
+
+	_, err = client.Unset(rel.Name, "image.registry")
+	assert.EqualError(t, err, `value "image.registry" is not pinned`)
+
+	// Editing the pinned values does not create a revision.
+	stored, err := client.Get(rel.Name)
+	require.NoError(t, err)
+	assert.Equal(t, pinned, stored)
+	history, err := cfg.Releases.History(rel.Name)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+
+	client.Replace = true
+	pinned, err = client.Set(rel.Name, map[string]interface{}{"replicas": 5})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"replicas": 5}, pinned)
+
+	pinned, err = client.Unset(rel.Name, "replicas")
+	require.NoError(t, err)
+	assert.Nil(t, pinned)
+}
+
+func TestPinnedValuesPendingRelease(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	rel := namedReleaseStub("pending", release.StatusPendingUpgrade)
+	require.NoError(t, cfg.Releases.Create(rel))
+
+	_, err := NewPinnedValues(cfg).Set(rel.Name, map[string]interface{}{"replicas": 3})
+	assert.ErrorIs(t, err, errPending)
+}
+
+func TestUpgradeReleaseWithPinnedValues(t *testing.T) {
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Config = map[string]interface{}{"replicas": 1, "name": "value"}
+	require.NoError(t, upAction.cfg.Releases.Create(rel))
+	chrt := buildChart(withNotes("replicas={{ .Values.replicas }} cpu={{ .Values.cpu }} name={{ .Values.name }}"))
+
+	_, err := NewPinnedValues(upAction.cfg).Set(rel.Name, map[string]interface{}{"replicas": 3, "cpu": "12m"})
+	require.NoError(t, err)
+
+	// The pinned values override the reused values, but are not stored in
+	// the values of the release.
+	res, err := upAction.Run(rel.Name, chrt, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, "replicas=3 cpu=12m name=value", res.Info.Notes)
+	assert.Equal(t, map[string]interface{}{"replicas": 1, "name": "value"}, res.Config)
+	assert.Equal(t, map[string]interface{}{"replicas": 3, "cpu": "12m"}, res.PinnedValues)
+
+	// The supplied values override the pinned values.
+	upAction.ReuseValues = true
+	res, err = upAction.Run(rel.Name, chrt, map[string]interface{}{"cpu": "50m"})
+	require.NoError(t, err)
+	assert.Equal(t, "replicas=3 cpu=50m name=value", res.Info.Notes)
+	assert.Equal(t, map[string]interface{}{"replicas": 1, "cpu": "50m", "name": "value"}, res.Config)
+	assert.Equal(t, map[string]interface{}{"replicas": 3, "cpu": "12m"}, res.PinnedValues)
+
+	// A rollback keeps the pinned values of the release.
+	_, err = NewPinnedValues(upAction.cfg).Set(rel.Name, map[string]interface{}{"replicas": 4})
+	require.NoError(t, err)
+	rollAction := NewRollback(upAction.cfg)
+	rollAction.Version = 1
+	require.NoError(t, rollAction.Run(rel.Name))
+	rolledBack, err := upAction.cfg.Releases.Last(rel.Name)
+	require.NoError(t, err)
+	assert.Equal(t, 4, rolledBack.Version)
+	assert.Equal(t, map[string]interface{}{"replicas": 4, "cpu": "12m"}, rolledBack.PinnedValues)
+	assert.Equal(t, map[string]interface{}{"replicas": 1, "name": "value"}, rolledBack.Config)
+}
+
+func TestUpgradeReleaseWithUnpinnedValues(t *testing.T) {
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Config = map[string]interface{}{"name": "value"}
+	require.NoError(t, upAction.cfg.Releases.Create(rel))
+	chrt := buildChart(withNotes("replicas={{ .Values.replicas }} name={{ .Values.name }}"))
+
+	_, err := NewPinnedValues(upAction.cfg).Set(rel.Name, map[string]interface{}{"replicas": 3})
+	require.NoError(t, err)
+	res, err := upAction.Run(rel.Name, chrt, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, "replicas=3 name=value", res.Info.Notes)
+
+	// Once unpinned, a value is not reused by the following upgrades.
+	_, err = NewPinnedValues(upAction.cfg).Unset(rel.Name, "replicas")
+	require.NoError(t, err)
+	res, err = upAction.Run(rel.Name, chrt, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, "replicas= name=value", res.Info.Notes)
+	assert.Equal(t, map[string]interface{}{"name": "value"}, res.Config)
+
+	upAction.ReuseValues = true
+	res, err = upAction.Run(rel.Name, chrt, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, "replicas= name=value", res.Info.Notes)
+	assert.Equal(t, map[string]interface{}{"name": "value"}, res.Config)
+}
@@ -0,0 +1,187 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/internal/third_party/dep/fs"
+	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/plugin"
+	"helm.sh/helm/v4/pkg/plugin/installer"
+)
+
+// PluginInstall is the action for installing a plugin.
+//
+// It provides the implementation of 'helm plugin install'.
+type PluginInstall struct {
+	Settings *cli.EnvSettings
+
+	// Version is a version constraint for plugins installed from a VCS
+	// repository.
+	Version string
+	// Verify requires a plugin archive to be signed, see
+	// installer.HTTPInstaller.
+	Verify bool
+	// Keyring is the keyring used to verify the plugin.
+	Keyring string
+	// Checksum pins the SHA-256 of a plugin archive.
+	Checksum string
+}
+
+// NewPluginInstall creates a new PluginInstall object with the given settings.
+func NewPluginInstall(settings *cli.EnvSettings) *PluginInstall {
+	return &PluginInstall{Settings: settings}
+}
+
+// Run installs the plugin from the source, a local directory, the URL of a
+// plugin archive or a VCS repository, and runs its install hook.
+//
+// The plugin is removed again when its dependencies are not satisfied by the
+// installed plugins.
+func (p *PluginInstall) Run(source string) (*plugin.Plugin, error) {
+	i, err := installer.NewForSource(source, p.Version)
+	if err != nil {
+		return nil, err
+	}
+	if hi, ok := i.(*installer.HTTPInstaller); ok {
+		hi.Verify = p.Verify
+		hi.Keyring = p.Keyring
+		hi.Checksum = p.Checksum
+		hi.Cache = ContentCache(p.Settings)
+	} else if p.Verify || p.Checksum != "" {
+		return nil, errors.New("verification and checksums are only supported for plugins installed from an archive URL")
+	}
+
+	if err := installer.Install(i); err != nil {
+		return nil, err
+	}
+
+	pl, err := plugin.LoadDir(i.Path())
+	if err != nil {
+		return nil, errors.Wrap(err, "plugin is installed but unusable")
+	}
+
+	installed, err := plugin.FindPlugins(p.Settings.PluginsDirectory)
+	if err != nil {
+		return nil, err
+	}
+	if err := plugin.CheckDependencies(pl, installed); err != nil {
+		os.RemoveAll(i.Path())
+		return nil, errors.Wrap(err, "plugin was not installed")
+	}
+
+	if err := plugin.RunHook(p.Settings, pl, plugin.Install); err != nil {
+		return nil, err
+	}
+	return pl, nil
+}
+
+// PluginUpdate is the action for updating a plugin.
+//
+// It provides the implementation of 'helm plugin update'.
+type PluginUpdate struct {
+	Settings *cli.EnvSettings
+}
+
+// NewPluginUpdate creates a new PluginUpdate object with the given settings.
+func NewPluginUpdate(settings *cli.EnvSettings) *PluginUpdate {
+	return &PluginUpdate{Settings: settings}
+}
+
+// Run updates the installed plugin with the given name and runs its update
+// hook.
+//
+// The plugin is restored to its previous state when the update, the checks of
+// the updated plugin or its update hook fail.
+func (u *PluginUpdate) Run(name string) (*plugin.Plugin, error) {
+	plugins, err := plugin.FindPlugins(u.Settings.PluginsDirectory)
+	if err != nil {
+		return nil, err
+	}
+	var found *plugin.Plugin
+	for _, p := range plugins {
+		if p.Metadata.Name == name {
+			found = p
+			break
+		}
+	}
+	if found == nil {
+		return nil, errors.Errorf("plugin %q not found", name)
+	}
+
+	exactLocation, err := filepath.EvalSymlinks(found.Dir)
+	if err != nil {
+		return nil, err
+	}
+	absExactLocation, err := filepath.Abs(exactLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	i, err := installer.FindSource(absExactLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.MkdirTemp("", "helm-plugin-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	backup := filepath.Join(tmp, "backup")
+	if err := fs.CopyDir(absExactLocation, backup); err != nil {
+		return nil, errors.Wrapf(err, "failed to back up plugin %q", name)
+	}
+
+	updated, err := u.update(i, plugins)
+	if err != nil {
+		if rerr := restorePlugin(backup, absExactLocation); rerr != nil {
+			return nil, errors.Wrapf(err, "plugin %q could not be restored to its previous version (%s)", name, rerr)
+		}
+		return nil, errors.Wrapf(err, "plugin %q was restored to its previous version", name)
+	}
+	return updated, nil
+}
+
+func (u *PluginUpdate) update(i installer.Installer, plugins []*plugin.Plugin) (*plugin.Plugin, error) {
+	if err := installer.Update(i); err != nil {
+		return nil, err
+	}
+	updated, err := plugin.LoadDir(i.Path())
+	if err != nil {
+		return nil, err
+	}
+	if err := plugin.CheckDependencies(updated, plugins); err != nil {
+		return nil, err
+	}
+	if err := plugin.RunHook(u.Settings, updated, plugin.Update); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// restorePlugin replaces the plugin directory with its backup.
+func restorePlugin(backup, dir string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return fs.CopyDir(backup, dir)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/cli"
+)
+
+func writeTestPlugin(t *testing.T, dir, metadata string) string {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "plugin.yaml"), []byte(metadata), 0644))
+	return dir
+}
+
+func TestPluginInstall(t *testing.T) {
+	t.Setenv("HELM_PLUGINS", t.TempDir())
+	settings := cli.New()
+	src := t.TempDir()
+
+	getter := writeTestPlugin(t, filepath.Join(src, "getter"), `name: getter
+version: 1.2.0
+hooks:
+  install: touch "$HELM_PLUGIN_DIR/installed"
+`)
+	downloader := writeTestPlugin(t, filepath.Join(src, "downloader"), `name: downloader
+version: 0.1.0
+dependencies:
+- name: getter
+  version: ">=2.0.0"
+`)
+
+	client := NewPluginInstall(settings)
+	p, err := client.Run(getter)
+	require.NoError(t, err)
+	assert.Equal(t, "getter", p.Metadata.Name)
+	assert.FileExists(t, filepath.Join(getter, "installed"), "expected the install hook to run")
+
+	_, err = client.Run(downloader)
+	assert.ErrorContains(t, err, `plugin "downloader" requires plugin "getter" >=2.0.0: installed version is 1.2.0`)
+	assert.NoFileExists(t, filepath.Join(settings.PluginsDirectory, "downloader"), "expected the plugin to be removed")
+
+	client.Verify = true
+	_, err = client.Run(downloader)
+	assert.ErrorContains(t, err, "only supported for plugins installed from an archive URL")
+}
+
+func TestPluginUpdateNotFound(t *testing.T) {
+	t.Setenv("HELM_PLUGINS", t.TempDir())
+
+	_, err := NewPluginUpdate(cli.New()).Run("missing")
+	assert.ErrorContains(t, err, `plugin "missing" not found`)
+}
+
+func TestRestorePlugin(t *testing.T) {
+	dir := t.TempDir()
+	plugin := writeTestPlugin(t, filepath.Join(dir, "plugin"), "name: plugin\nversion: 1.0.0\n")
+	backup := filepath.Join(dir, "backup")
+	require.NoError(t, os.Rename(plugin, backup))
+	writeTestPlugin(t, plugin, "name: plugin\nversion: 2.0.0\n")
+	require.NoError(t, os.WriteFile(filepath.Join(plugin, "new-file"), nil, 0644))
+
+	require.NoError(t, restorePlugin(backup, plugin))
+	data, err := os.ReadFile(filepath.Join(plugin, "plugin.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "version: 1.0.0")
+	assert.NoFileExists(t, filepath.Join(plugin, "new-file"))
+}
@@ -0,0 +1,170 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+// ConfigurationPool creates and caches the Configurations of several clusters,
+// for tools that operate on fleets of clusters.
+//
+// Every cluster is added with its own RESTClientGetter, e.g. one created with
+// cli.NewRESTClientGetter. The Configurations of a cluster share its getter,
+// and thus its discovery cache and its rate limits, whatever their namespace.
+//
+// The Configurations of a cluster also share a CapabilitiesCache, which keeps
+// the discovered capabilities for DefaultCapabilitiesTTL.
+//
+// A ConfigurationPool is safe for concurrent use.
+type ConfigurationPool struct {
+	helmDriver string
+	log        DebugLog
+
+	mtx     sync.Mutex
+	getters map[string]genericclioptions.RESTClientGetter
+	caches  map[string]*CapabilitiesCache
+	configs map[poolKey]*Configuration
+}
+
+type poolKey struct {
+	cluster   string
+	namespace string
+	identity  string
+}
+
+// NewConfigurationPool creates an empty pool whose Configurations use the
+// storage driver, see Configuration.Init.
+func NewConfigurationPool(helmDriver string, log DebugLog) *ConfigurationPool {
+	return &ConfigurationPool{
+		helmDriver: helmDriver,
+		log:        log,
+		getters:    map[string]genericclioptions.RESTClientGetter{},
+		caches:     map[string]*CapabilitiesCache{},
+		configs:    map[poolKey]*Configuration{},
+	}
+}
+
+// Add adds a cluster to the pool. Adding a cluster again replaces its getter
+// and drops its cached Configurations and capabilities.
+func (p *ConfigurationPool) Add(cluster string, getter genericclioptions.RESTClientGetter) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.getters[cluster] = getter
+	p.caches[cluster] = NewCapabilitiesCache(DefaultCapabilitiesTTL)
+	for key := range p.configs {
+		if key.cluster == cluster {
+			delete(p.configs, key)
+		}
+	}
+}
+
+// Clusters returns the sorted names of the clusters in the pool.
+func (p *ConfigurationPool) Clusters() []string {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	clusters := make([]string, 0, len(p.getters))
+	for cluster := range p.getters {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+	return clusters
+}
+
+// Get returns the Configuration of the namespace of a cluster. It is created
+// on first use and cached afterwards.
+func (p *ConfigurationPool) Get(cluster, namespace string) (*Configuration, error) {
+	return p.GetAs(cluster, namespace, kube.Impersonation{})
+}
+
+// GetAs returns the Configuration of the namespace of a cluster whose clients
+// impersonate the identity, see Configuration.InitAs. It is created on first
+// use and cached afterwards.
+func (p *ConfigurationPool) GetAs(cluster, namespace string, as kube.Impersonation) (*Configuration, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	key := poolKey{cluster: cluster, namespace: namespace, identity: as.String()}
+	if cfg, ok := p.configs[key]; ok {
+		return cfg, nil
+	}
+	getter, ok := p.getters[cluster]
+	if !ok {
+		return nil, errors.Errorf("cluster %q is not in the pool", cluster)
+	}
+
+	cfg := new(Configuration)
+	if err := cfg.InitAs(getter, as, namespace, p.helmDriver, p.log); err != nil {
+		return nil, errors.Wrapf(err, "unable to initialize the configuration of cluster %q", cluster)
+	}
+	cfg.CapabilitiesCache = p.caches[cluster]
+	// The namespace of the getter is shared by all namespaces of the cluster
+	if kc, ok := cfg.KubeClient.(*kube.Client); ok {
+		kc.Namespace = namespace
+	}
+	p.configs[key] = cfg
+	return cfg, nil
+}
+
+// ForEach calls fn with the Configuration of the namespace of every cluster in
+// the pool. At most parallelism calls run at the same time, zero or less runs
+// all of them at once. The errors of the clusters are returned together.
+func (p *ConfigurationPool) ForEach(namespace string, parallelism int, fn func(cluster string, cfg *Configuration) error) error {
+	clusters := p.Clusters()
+	if parallelism <= 0 || parallelism > len(clusters) {
+		parallelism = len(clusters)
+	}
+
+	errs := make([]error, len(clusters))
+	sem := make(chan struct{}, max(parallelism, 1))
+	var wg sync.WaitGroup
+	for i, cluster := range clusters {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			cfg, err := p.Get(cluster, namespace)
+			if err == nil {
+				err = fn(cluster, cfg)
+			}
+			if err != nil {
+				errs[i] = fmt.Errorf("cluster %q: %w", cluster, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("%d of %d cluster(s) failed: %s", len(failed), len(clusters), joinErrors(failed))
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+func TestConfigurationPool(t *testing.T) {
+	pool := NewConfigurationPool("memory", func(_ string, _ ...interface{}) {})
+	pool.Add("west", cli.NewRESTClientGetter(cli.ClusterOptions{KubeContext: "west"}))
+	pool.Add("east", cli.NewRESTClientGetter(cli.ClusterOptions{KubeContext: "east"}))
+	assert.Equal(t, []string{"east", "west"}, pool.Clusters())
+
+	cfg, err := pool.Get("east", "shop")
+	require.NoError(t, err)
+	assert.Equal(t, "shop", cfg.KubeClient.(*kube.Client).Namespace)
+	again, err := pool.Get("east", "shop")
+	require.NoError(t, err)
+	assert.Same(t, cfg, again, "expected the configuration to be cached")
+	other, err := pool.Get("east", "billing")
+	require.NoError(t, err)
+	assert.NotSame(t, cfg, other)
+	assert.Same(t, cfg.RESTClientGetter, other.RESTClientGetter, "expected the namespaces of a cluster to share its getter")
+	assert.Same(t, cfg.CapabilitiesCache, other.CapabilitiesCache, "expected the namespaces of a cluster to share its capabilities")
+
+	pool.Add("east", cli.NewRESTClientGetter(cli.ClusterOptions{KubeContext: "east"}))
+	replaced, err := pool.Get("east", "shop")
+	require.NoError(t, err)
+	assert.NotSame(t, cfg, replaced, "expected adding a cluster again to drop its configurations")
+
+	_, err = pool.Get("north", "shop")
+	assert.ErrorContains(t, err, `cluster "north" is not in the pool`)
+}
+
+func TestConfigurationPoolGetAs(t *testing.T) {
+	pool := NewConfigurationPool("memory", func(_ string, _ ...interface{}) {})
+	pool.Add("east", cli.NewRESTClientGetter(cli.ClusterOptions{KubeContext: "east"}))
+
+	cfg, err := pool.Get("east", "shop")
+	require.NoError(t, err)
+	tenant, err := pool.GetAs("east", "shop", kube.ServiceAccountImpersonation("shop", "deployer"))
+	require.NoError(t, err)
+	assert.NotSame(t, cfg, tenant, "expected a configuration per identity")
+	assert.NotSame(t, cfg.RESTClientGetter, tenant.RESTClientGetter)
+	assert.Same(t, cfg.CapabilitiesCache, tenant.CapabilitiesCache, "expected the identities to share the capabilities of the cluster")
+
+	again, err := pool.GetAs("east", "shop", kube.ServiceAccountImpersonation("shop", "deployer"))
+	require.NoError(t, err)
+	assert.Same(t, tenant, again, "expected the configuration to be cached")
+	zero, err := pool.GetAs("east", "shop", kube.Impersonation{})
+	require.NoError(t, err)
+	assert.Same(t, cfg, zero)
+}
+
+func TestConfigurationPoolForEach(t *testing.T) {
+	pool := NewConfigurationPool("memory", func(_ string, _ ...interface{}) {})
+	for _, cluster := range []string{"a", "b", "c"} {
+		pool.Add(cluster, cli.NewRESTClientGetter(cli.ClusterOptions{KubeContext: cluster}))
+	}
+
+	var mtx sync.Mutex
+	visited := map[string]string{}
+	err := pool.ForEach("shop", 2, func(cluster string, cfg *Configuration) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		visited[cluster] = cfg.KubeClient.(*kube.Client).Namespace
+		if cluster == "b" {
+			return errors.New("unreachable")
+		}
+		return nil
+	})
+	assert.EqualError(t, err, `1 of 3 cluster(s) failed: cluster "b": unreachable`)
+	assert.Equal(t, map[string]string{"a": "shop", "b": "shop", "c": "shop"}, visited)
+}
@@ -0,0 +1,205 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chartutil"
+)
+
+// Annotations used by Kubernetes to mark the default StorageClass.
+const (
+	defaultStorageClassAnnotation     = "storageclass.kubernetes.io/is-default-class"
+	betaDefaultStorageClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
+)
+
+// checkRequirements verifies the cluster requirements declared by a chart and
+// its enabled subcharts against the live cluster. The APIs provided by crds,
+// the CRDs installed before the chart, satisfy the requirements as well.
+func (cfg *Configuration) checkRequirements(ch *chart.Chart, crds []chart.CRD) error {
+	reqs := collectRequirements(ch)
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	caps := cfg.Capabilities
+	if caps == nil {
+		var err error
+		if caps, err = cfg.discoverCapabilities(); err != nil {
+			return errors.Wrap(err, "unable to check chart requirements")
+		}
+	}
+	if provided, err := crdAPIVersions(crds); err != nil {
+		return err
+	} else if len(provided) > 0 {
+		caps = caps.Copy()
+		caps.APIVersions = append(append(chartutil.VersionSet{}, caps.APIVersions...), provided...)
+	}
+
+	return checkClusterRequirements(reqs, caps, func() (kubernetes.Interface, error) {
+		return cfg.KubernetesClientSet()
+	})
+}
+
+// crdAPIVersions returns the API versions served by the definitions of the
+// CRDs.
+func crdAPIVersions(crds []chart.CRD) ([]string, error) {
+	var versions []string
+	for _, crd := range crds {
+		defs, err := crd.Definitions()
+		if err != nil {
+			return nil, err
+		}
+		for _, def := range defs {
+			versions = append(versions, def.APIVersions()...)
+		}
+	}
+	return versions, nil
+}
+
+// chartRequirements associates requirements with the chart declaring them.
+type chartRequirements struct {
+	chart        string
+	requirements *chart.Requirements
+}
+
+func collectRequirements(ch *chart.Chart) []chartRequirements {
+	var reqs []chartRequirements
+	if ch.Metadata != nil && ch.Metadata.Requirements != nil {
+		reqs = append(reqs, chartRequirements{chart: ch.ChartPath(), requirements: ch.Metadata.Requirements})
+	}
+	for _, dep := range ch.Dependencies() {
+		reqs = append(reqs, collectRequirements(dep)...)
+	}
+	return reqs
+}
+
+// checkClusterRequirements evaluates every requirement and reports all the
+// unmet ones at once so that they can be fixed together.
+func checkClusterRequirements(reqs []chartRequirements, caps *chartutil.Capabilities, clientFn func() (kubernetes.Interface, error)) error {
+	var problems []string
+
+	var client kubernetes.Interface
+	getClient := func() (kubernetes.Interface, error) {
+		if client != nil {
+			return client, nil
+		}
+		var err error
+		client, err = clientFn()
+		return client, err
+	}
+
+	for _, r := range reqs {
+		req := r.requirements
+
+		for _, api := range req.APIs {
+			if !caps.APIVersions.Has(api) {
+				problems = append(problems, fmt.Sprintf("chart %q requires API %q, which is not served by the cluster. Install the CRDs or API service that provide it", r.chart, api))
+			}
+		}
+
+		if req.ServerVersion != "" {
+			c, err := semver.NewConstraint(req.ServerVersion)
+			if err != nil {
+				return errors.Wrapf(err, "chart %q has an invalid requirements.serverVersion", r.chart)
+			}
+			v, err := semver.NewVersion(caps.KubeVersion.Version)
+			if err != nil {
+				return errors.Wrapf(err, "unable to parse Kubernetes server version %q", caps.KubeVersion.Version)
+			}
+			if !c.Check(v) {
+				problems = append(problems, fmt.Sprintf("chart %q requires Kubernetes server version %s, but the cluster runs %s", r.chart, req.ServerVersion, caps.KubeVersion.Version))
+			}
+		}
+
+		if req.MinNodes > 0 {
+			kc, err := getClient()
+			if err != nil {
+				return errors.Wrap(err, "unable to check chart requirements")
+			}
+			ready, err := readyNodeCount(kc)
+			if err != nil {
+				return errors.Wrap(err, "unable to list nodes to check chart requirements")
+			}
+			if ready < req.MinNodes {
+				problems = append(problems, fmt.Sprintf("chart %q requires at least %d ready schedulable nodes, but the cluster has %d", r.chart, req.MinNodes, ready))
+			}
+		}
+
+		if req.DefaultStorageClass || len(req.StorageClasses) > 0 {
+			kc, err := getClient()
+			if err != nil {
+				return errors.Wrap(err, "unable to check chart requirements")
+			}
+			classes, err := kc.StorageV1().StorageClasses().List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				return errors.Wrap(err, "unable to list storage classes to check chart requirements")
+			}
+			existing := map[string]bool{}
+			hasDefault := false
+			for _, sc := range classes.Items {
+				existing[sc.Name] = true
+				if sc.Annotations[defaultStorageClassAnnotation] == "true" || sc.Annotations[betaDefaultStorageClassAnnotation] == "true" {
+					hasDefault = true
+				}
+			}
+			if req.DefaultStorageClass && !hasDefault {
+				problems = append(problems, fmt.Sprintf("chart %q requires a default StorageClass. Mark one with the %q annotation", r.chart, defaultStorageClassAnnotation))
+			}
+			for _, name := range req.StorageClasses {
+				if !existing[name] {
+					problems = append(problems, fmt.Sprintf("chart %q requires StorageClass %q, which does not exist", r.chart, name))
+				}
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.Errorf("cluster does not meet chart requirements:\n- %s", strings.Join(problems, "\n- "))
+	}
+	return nil
+}
+
+func readyNodeCount(kc kubernetes.Interface) (int, error) {
+	nodes, err := kc.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, n := range nodes.Items {
+		if n.Spec.Unschedulable {
+			continue
+		}
+		for _, c := range n.Status.Conditions {
+			if c.Type == v1.NodeReady && c.Status == v1.ConditionTrue {
+				count++
+				break
+			}
+		}
+	}
+	return count, nil
+}
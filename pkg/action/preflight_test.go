@@ -0,0 +1,134 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chartutil"
+)
+
+func readyNode(name string, unschedulable bool) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1.NodeSpec{Unschedulable: unschedulable},
+		Status: v1.NodeStatus{Conditions: []v1.NodeCondition{
+			{Type: v1.NodeReady, Status: v1.ConditionTrue},
+		}},
+	}
+}
+
+func TestCheckClusterRequirements(t *testing.T) {
+	caps := chartutil.DefaultCapabilities.Copy()
+	client := fakeclientset.NewSimpleClientset(
+		readyNode("a", false),
+		readyNode("b", true),
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{
+			Name:        "standard",
+			Annotations: map[string]string{defaultStorageClassAnnotation: "true"},
+		}},
+	)
+	clientFn := func() (kubernetes.Interface, error) { return client, nil }
+
+	met := []chartRequirements{{chart: "parent", requirements: &chart.Requirements{
+		APIs:                []string{"v1", "apps/v1"},
+		ServerVersion:       ">=1.20.0",
+		MinNodes:            1,
+		DefaultStorageClass: true,
+		StorageClasses:      []string{"standard"},
+	}}}
+	assert.NoError(t, checkClusterRequirements(met, caps, clientFn))
+
+	unmet := []chartRequirements{{chart: "parent.child", requirements: &chart.Requirements{
+		APIs:           []string{"monitoring.coreos.com/v1/ServiceMonitor"},
+		ServerVersion:  ">=99.0.0",
+		MinNodes:       2,
+		StorageClasses: []string{"fast"},
+	}}}
+	err := checkClusterRequirements(unmet, caps, clientFn)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `chart "parent.child" requires API "monitoring.coreos.com/v1/ServiceMonitor"`)
+		assert.Contains(t, err.Error(), "requires Kubernetes server version >=99.0.0")
+		assert.Contains(t, err.Error(), "requires at least 2 ready schedulable nodes, but the cluster has 1")
+		assert.Contains(t, err.Error(), `requires StorageClass "fast", which does not exist`)
+	}
+
+	noDefault := fakeclientset.NewSimpleClientset(&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "standard"}})
+	err = checkClusterRequirements(
+		[]chartRequirements{{chart: "parent", requirements: &chart.Requirements{DefaultStorageClass: true}}},
+		caps,
+		func() (kubernetes.Interface, error) { return noDefault, nil },
+	)
+	assert.ErrorContains(t, err, "requires a default StorageClass")
+}
+
+func TestInstallRelease_UnmetRequirements(t *testing.T) {
+	instAction := installAction(t)
+	chrt := buildChart(withDependency(withName("child")))
+	chrt.Dependencies()[0].Metadata.Requirements = &chart.Requirements{
+		APIs: []string{"monitoring.coreos.com/v1/ServiceMonitor"},
+	}
+
+	_, err := instAction.Run(chrt, map[string]interface{}{})
+	assert.ErrorContains(t, err, `chart "hello.child" requires API "monitoring.coreos.com/v1/ServiceMonitor"`)
+
+	instAction = installAction(t)
+	instAction.SkipRequirementChecks = true
+	_, err = instAction.Run(chrt, map[string]interface{}{})
+	assert.NoError(t, err)
+}
+
+const serviceMonitorCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: servicemonitors.monitoring.coreos.com
+spec:
+  group: monitoring.coreos.com
+  names:
+    kind: ServiceMonitor
+    plural: servicemonitors
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+`
+
+func TestInstallRelease_RequirementsProvidedByCRDs(t *testing.T) {
+	chrt := buildChart(withDependency(withName("child")))
+	chrt.Dependencies()[0].Metadata.Requirements = &chart.Requirements{
+		APIs: []string{"monitoring.coreos.com/v1/ServiceMonitor"},
+	}
+	chrt.Files = append(chrt.Files, &chart.File{Name: "crds/servicemonitor.yaml", Data: []byte(serviceMonitorCRD)})
+
+	instAction := installAction(t)
+	_, err := instAction.Run(chrt, map[string]interface{}{})
+	assert.NoError(t, err)
+
+	instAction = installAction(t)
+	instAction.SkipCRDs = true
+	_, err = instAction.Run(chrt, map[string]interface{}{})
+	assert.ErrorContains(t, err, `chart "hello.child" requires API "monitoring.coreos.com/v1/ServiceMonitor"`)
+}
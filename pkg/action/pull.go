@@ -19,11 +19,14 @@ package action
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
 	"github.com/pkg/errors"
 
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/loader"
 	"helm.sh/helm/v4/pkg/chartutil"
 	"helm.sh/helm/v4/pkg/cli"
 	"helm.sh/helm/v4/pkg/downloader"
@@ -46,6 +49,10 @@ type Pull struct {
 	UntarDir    string
 	DestDir     string
 	cfg         *Configuration
+
+	// UntarFiles limits the files extracted by Untar to the ones matching
+	// these patterns, see ExtractFiles. Chart.yaml is always extracted.
+	UntarFiles []string
 }
 
 type PullOpt func(*Pull)
@@ -75,35 +82,6 @@ func (p *Pull) SetRegistryClient(client *registry.Client) {
 func (p *Pull) Run(chartRef string) (string, error) {
 	var out strings.Builder
 
-	c := downloader.ChartDownloader{
-		Out:     &out,
-		Keyring: p.Keyring,
-		Verify:  downloader.VerifyNever,
-		Getters: getter.All(p.Settings),
-		Options: []getter.Option{
-			getter.WithBasicAuth(p.Username, p.Password),
-			getter.WithPassCredentialsAll(p.PassCredentialsAll),
-			getter.WithTLSClientConfig(p.CertFile, p.KeyFile, p.CaFile),
-			getter.WithInsecureSkipVerifyTLS(p.InsecureSkipTLSverify),
-			getter.WithPlainHTTP(p.PlainHTTP),
-		},
-		RegistryClient:   p.cfg.RegistryClient,
-		RepositoryConfig: p.Settings.RepositoryConfig,
-		RepositoryCache:  p.Settings.RepositoryCache,
-	}
-
-	if registry.IsOCI(chartRef) {
-		c.Options = append(c.Options,
-			getter.WithRegistryClient(p.cfg.RegistryClient))
-		c.RegistryClient = p.cfg.RegistryClient
-	}
-
-	if p.Verify {
-		c.Verify = downloader.VerifyAlways
-	} else if p.VerifyLater {
-		c.Verify = downloader.VerifyLater
-	}
-
 	// If untar is set, we fetch to a tempdir, then untar and copy after
 	// verification.
 	dest := p.DestDir
@@ -116,27 +94,11 @@ func (p *Pull) Run(chartRef string) (string, error) {
 		defer os.RemoveAll(dest)
 	}
 
-	if p.RepoURL != "" {
-		chartURL, err := repo.FindChartInAuthAndTLSAndPassRepoURL(p.RepoURL, p.Username, p.Password, chartRef, p.Version, p.CertFile, p.KeyFile, p.CaFile, p.InsecureSkipTLSverify, p.PassCredentialsAll, getter.All(p.Settings))
-		if err != nil {
-			return out.String(), err
-		}
-		chartRef = chartURL
-	}
-
-	saved, v, err := c.DownloadTo(chartRef, p.Version, dest)
+	saved, chartRef, err := p.download(&out, chartRef, dest)
 	if err != nil {
 		return out.String(), err
 	}
 
-	if p.Verify {
-		for name := range v.SignedBy.Identities {
-			fmt.Fprintf(&out, "Signed by: %v\n", name)
-		}
-		fmt.Fprintf(&out, "Using Key With Fingerprint: %X\n", v.SignedBy.PrimaryKey.Fingerprint)
-		fmt.Fprintf(&out, "Chart Hash Verified: %s\n", v.FileHash)
-	}
-
 	// After verification, untar the chart into the requested directory.
 	if p.Untar {
 		ud := p.UntarDir
@@ -161,7 +123,140 @@ func (p *Pull) Run(chartRef string) (string, error) {
 			return out.String(), errors.Errorf("failed to untar: a file or directory with the name %s already exists", udCheck)
 		}
 
+		if len(p.UntarFiles) > 0 {
+			return out.String(), chartutil.ExpandFileMatching(ud, saved, matchFilePatterns(p.UntarFiles))
+		}
 		return out.String(), chartutil.ExpandFile(ud, saved)
 	}
 	return out.String(), nil
 }
+
+// ExtractFiles downloads and verifies the chart like Run, but instead of saving
+// it returns the files of the chart matching the patterns in memory, without
+// expanding the rest of the archive. It is meant for tooling that only needs a
+// few files, such as the metadata, of large charts.
+//
+// A pattern is either a path within the chart, e.g. "Chart.yaml", a directory
+// ending with a slash, e.g. "crds/", matching all the files below it, or a
+// path.Match glob, e.g. "values*.yaml". All files are returned when no
+// pattern is given. The messages of the download, e.g. the signature of a
+// verified chart, are returned as the string.
+func (p *Pull) ExtractFiles(chartRef string, patterns ...string) ([]*chart.File, string, error) {
+	var out strings.Builder
+
+	dest, err := os.MkdirTemp("", "helm-")
+	if err != nil {
+		return nil, out.String(), errors.Wrap(err, "failed to extract files")
+	}
+	defer os.RemoveAll(dest)
+
+	saved, _, err := p.download(&out, chartRef, dest)
+	if err != nil {
+		return nil, out.String(), err
+	}
+
+	f, err := os.Open(saved)
+	if err != nil {
+		return nil, out.String(), errors.Wrap(err, "failed to extract files")
+	}
+	defer f.Close()
+
+	var match func(string) bool
+	if len(patterns) > 0 {
+		match = matchFilePatterns(patterns)
+	}
+	buffered, err := loader.LoadArchiveFilesMatching(f, match)
+	if err != nil {
+		return nil, out.String(), err
+	}
+	files := make([]*chart.File, len(buffered))
+	for i, bf := range buffered {
+		files[i] = &chart.File{Name: bf.Name, Data: bf.Data}
+	}
+	return files, out.String(), nil
+}
+
+// download fetches the chart to dest, verifying it as requested. It returns
+// the path of the saved archive and the reference it was downloaded from,
+// which is the URL of the chart when RepoURL is set.
+func (p *Pull) download(out *strings.Builder, chartRef, dest string) (string, string, error) {
+	c := downloader.ChartDownloader{
+		Out:     out,
+		Keyring: p.Keyring,
+		Verify:  downloader.VerifyNever,
+		Getters: getter.All(p.Settings),
+		Options: []getter.Option{
+			getter.WithBasicAuth(p.Username, p.Password),
+			getter.WithPassCredentialsAll(p.PassCredentialsAll),
+			getter.WithTLSClientConfig(p.CertFile, p.KeyFile, p.CaFile),
+			getter.WithInsecureSkipVerifyTLS(p.InsecureSkipTLSverify),
+			getter.WithPlainHTTP(p.PlainHTTP),
+		},
+		RegistryClient:      p.cfg.RegistryClient,
+		RepositoryConfig:    p.Settings.RepositoryConfig,
+		RepositoryConfigDir: p.Settings.RepositoryConfigDir,
+		RepositoryCache:     p.Settings.RepositoryCache,
+		Cache:               ContentCache(p.Settings),
+	}
+	if registry.IsOCI(chartRef) {
+		c.Options = append(c.Options,
+			getter.WithRegistryClient(p.cfg.RegistryClient))
+		c.RegistryClient = p.cfg.RegistryClient
+	}
+
+	if p.Verify {
+		c.Verify = downloader.VerifyAlways
+	} else if p.VerifyLater {
+		c.Verify = downloader.VerifyLater
+	}
+
+	if p.RepoURL != "" {
+		chartURL, err := repo.FindChartInRepoURLWithOptions(p.RepoURL, p.Username, p.Password, chartRef, p.Version, p.CertFile, p.KeyFile, p.CaFile, p.InsecureSkipTLSverify, p.PassCredentialsAll, getter.All(p.Settings), p.HTTPOptions()...)
+		if err != nil {
+			return "", chartRef, err
+		}
+		// The headers and the bearer token are only sent along when the
+		// chart is hosted by the repository, like in LocateChart.
+		if p.PassCredentialsAll || sameHost(p.RepoURL, chartURL) {
+			c.Options = append(c.Options, p.HTTPOptions()...)
+		}
+		chartRef = chartURL
+	} else {
+		c.Options = append(c.Options, p.HTTPOptions()...)
+	}
+
+	saved, v, err := c.DownloadTo(chartRef, p.Version, dest)
+	if err != nil {
+		return "", chartRef, err
+	}
+
+	if p.Verify {
+		for name := range v.SignedBy.Identities {
+			fmt.Fprintf(out, "Signed by: %v\n", name)
+		}
+		fmt.Fprintf(out, "Using Key With Fingerprint: %X\n", v.SignedBy.PrimaryKey.Fingerprint)
+		fmt.Fprintf(out, "Chart Hash Verified: %s\n", v.FileHash)
+	}
+	return saved, chartRef, nil
+}
+
+// matchFilePatterns returns a matcher for the file patterns of ExtractFiles.
+func matchFilePatterns(patterns []string) func(name string) bool {
+	return func(name string) bool {
+		for _, pattern := range patterns {
+			if strings.HasSuffix(pattern, "/") {
+				if strings.HasPrefix(name, pattern) {
+					return true
+				}
+				continue
+			}
+			if name == pattern {
+				return true
+			}
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/repo/repotest"
+)
+
+func TestPullExtractFiles(t *testing.T) {
+	srv, err := repotest.NewTempServerWithCleanup(t, "../downloader/testdata/signtest-0.1.0.tgz")
+	require.NoError(t, err)
+	defer srv.Stop()
+
+	client := NewPull(WithConfig(actionConfigFixture(t)))
+	client.Settings = repoSettings(t)
+	client.RepoURL = srv.URL()
+
+	files, _, err := client.ExtractFiles("signtest", "Chart.yaml", "alpine/", "*/values.yaml")
+	require.NoError(t, err)
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name)
+		assert.NotEmpty(t, f.Data, f.Name)
+	}
+	assert.ElementsMatch(t, []string{
+		"Chart.yaml",
+		"alpine/Chart.yaml",
+		"alpine/README.md",
+		"alpine/templates/alpine-pod.yaml",
+		"alpine/values.yaml",
+	}, names)
+
+	files, _, err = client.ExtractFiles("signtest", "crds/")
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestPullUntarFiles(t *testing.T) {
+	srv, err := repotest.NewTempServerWithCleanup(t, "../downloader/testdata/signtest-0.1.0.tgz")
+	require.NoError(t, err)
+	defer srv.Stop()
+
+	client := NewPull(WithConfig(actionConfigFixture(t)))
+	client.Settings = repoSettings(t)
+	client.RepoURL = srv.URL()
+	client.Untar = true
+	client.UntarDir = "."
+	client.DestDir = t.TempDir()
+	client.UntarFiles = []string{"values.yaml"}
+
+	_, err = client.Run("signtest")
+	require.NoError(t, err)
+
+	chartDir := filepath.Join(client.DestDir, "signtest")
+	assert.FileExists(t, filepath.Join(chartDir, "Chart.yaml"))
+	assert.FileExists(t, filepath.Join(chartDir, "values.yaml"))
+	_, err = os.Stat(filepath.Join(chartDir, "templates"))
+	assert.True(t, os.IsNotExist(err), "expected the templates not to be extracted")
+	_, err = os.Stat(filepath.Join(chartDir, "alpine"))
+	assert.True(t, os.IsNotExist(err), "expected the subchart not to be extracted")
+}
@@ -18,8 +18,11 @@ package action
 
 import (
 	"io"
+	"os"
 	"strings"
 
+	"github.com/pkg/errors"
+
 	"helm.sh/helm/v4/pkg/cli"
 	"helm.sh/helm/v4/pkg/pusher"
 	"helm.sh/helm/v4/pkg/registry"
@@ -38,6 +41,9 @@ type Push struct {
 	insecureSkipTLSverify bool
 	plainHTTP             bool
 	out                   io.Writer
+	annotations           map[string]string
+	artifactType          string
+	pkg                   *Package
 }
 
 // PushOpt is a type of function that sets options for a push action.
@@ -80,6 +86,31 @@ func WithPushOptWriter(out io.Writer) PushOpt {
 	}
 }
 
+// WithPushAnnotations sets the annotations added to the manifest of the
+// pushed chart, e.g. ocispec.AnnotationRevision.
+func WithPushAnnotations(annotations map[string]string) PushOpt {
+	return func(p *Push) {
+		p.annotations = annotations
+	}
+}
+
+// WithPushArtifactType sets the artifactType of the manifest of the pushed
+// chart.
+func WithPushArtifactType(artifactType string) PushOpt {
+	return func(p *Push) {
+		p.artifactType = artifactType
+	}
+}
+
+// WithPushPackage sets the Package action packaging the charts pushed from a
+// directory, e.g. to set their version or sign them. Its Destination is
+// ignored.
+func WithPushPackage(pkg *Package) PushOpt {
+	return func(p *Push) {
+		p.pkg = pkg
+	}
+}
+
 // NewPushWithOpts creates a new push, with configuration options.
 func NewPushWithOpts(opts ...PushOpt) *Push {
 	p := &Push{}
@@ -89,10 +120,22 @@ func NewPushWithOpts(opts ...PushOpt) *Push {
 	return p
 }
 
-// Run executes 'helm push' against the given chart archive.
+// Run executes 'helm push' against the given chart archive, or chart
+// directory which is packaged first.
 func (p *Push) Run(chartRef string, remote string) (string, error) {
 	var out strings.Builder
 
+	if fi, err := os.Stat(chartRef); err == nil && fi.IsDir() {
+		dir, err := os.MkdirTemp("", "helm-push-")
+		if err != nil {
+			return "", err
+		}
+		defer os.RemoveAll(dir)
+		if chartRef, err = p.packageChart(chartRef, dir); err != nil {
+			return "", errors.Wrap(err, "unable to package the chart")
+		}
+	}
+
 	c := uploader.ChartUploader{
 		Out:     &out,
 		Pushers: pusher.All(p.Settings),
@@ -100,6 +143,8 @@ func (p *Push) Run(chartRef string, remote string) (string, error) {
 			pusher.WithTLSClientConfig(p.certFile, p.keyFile, p.caFile),
 			pusher.WithInsecureSkipTLSVerify(p.insecureSkipTLSverify),
 			pusher.WithPlainHTTP(p.plainHTTP),
+			pusher.WithAnnotations(p.annotations),
+			pusher.WithArtifactType(p.artifactType),
 		},
 	}
 
@@ -110,3 +155,15 @@ func (p *Push) Run(chartRef string, remote string) (string, error) {
 
 	return out.String(), c.UploadTo(chartRef, remote)
 }
+
+// packageChart packages the chart directory to dest, returning the path of
+// the archive.
+func (p *Push) packageChart(path, dest string) (string, error) {
+	pkg := NewPackage()
+	if p.pkg != nil {
+		copied := *p.pkg
+		pkg = &copied
+	}
+	pkg.Destination = dest
+	return pkg.Run(path, nil)
+}
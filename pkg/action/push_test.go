@@ -0,0 +1,50 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/loader"
+	"helm.sh/helm/v4/pkg/cli"
+)
+
+func TestPushPackagesDirectory(t *testing.T) {
+	pkg := &Package{Version: "1.2.3", Destination: "ignored"}
+	client := NewPushWithOpts(WithPushPackage(pkg))
+
+	dest := t.TempDir()
+	archive, err := client.packageChart("testdata/charts/chart-with-schema", dest)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dest, "empty-1.2.3.tgz"), archive)
+	assert.Equal(t, "ignored", pkg.Destination, "expected the package action not to be modified")
+
+	c, err := loader.Load(archive)
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3", c.Metadata.Version)
+}
+
+func TestPushDirectoryUnsupportedScheme(t *testing.T) {
+	client := NewPushWithOpts()
+	client.Settings = cli.New()
+	_, err := client.Run("testdata/charts/chart-with-schema", "https://example.com/charts")
+	assert.ErrorContains(t, err, `scheme "https" not supported`)
+}
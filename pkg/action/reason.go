@@ -0,0 +1,57 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/pkg/release"
+)
+
+// reasonError attaches the reason of the failure of a revision to an error,
+// leaving its message unchanged.
+type reasonError struct {
+	reason release.Reason
+	err    error
+}
+
+func (e *reasonError) Error() string { return e.err.Error() }
+
+func (e *reasonError) Unwrap() error { return e.err }
+
+// withReason attaches the reason to err, if any.
+func withReason(reason release.Reason, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &reasonError{reason: reason, err: err}
+}
+
+// failureReason returns the reason attached to err, ReasonInterrupted for the
+// cancelled operations and ReasonFailed otherwise.
+func failureReason(err error) release.Reason {
+	var re *reasonError
+	if errors.As(err, &re) {
+		return re.reason
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return release.ReasonInterrupted
+	}
+	return release.ReasonFailed
+}
@@ -0,0 +1,37 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v4/pkg/release"
+)
+
+func TestFailureReason(t *testing.T) {
+	err := withReason(release.ReasonHookFailed, fmt.Errorf("job failed"))
+	assert.Equal(t, "job failed", err.Error())
+	assert.Equal(t, release.ReasonHookFailed, failureReason(errors.Wrap(err, "release failed")))
+	assert.Equal(t, release.ReasonInterrupted, failureReason(errors.Wrap(context.Canceled, "release failed")))
+	assert.Equal(t, release.ReasonFailed, failureReason(fmt.Errorf("unexpected")))
+	assert.NoError(t, withReason(release.ReasonHookFailed, nil))
+}
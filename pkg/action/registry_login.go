@@ -29,6 +29,9 @@ type RegistryLogin struct {
 	keyFile  string
 	caFile   string
 	insecure bool
+	saveTLS  bool
+	proxy    string
+	minTLS   string
 }
 
 type RegistryLoginOpt func(*RegistryLogin) error
@@ -65,6 +68,32 @@ func WithCAFile(caFile string) RegistryLoginOpt {
 	}
 }
 
+// WithSaveTLS specifies whether the TLS settings are saved for the registry, to
+// be used after the login.
+func WithSaveTLS(save bool) RegistryLoginOpt {
+	return func(r *RegistryLogin) error {
+		r.saveTLS = save
+		return nil
+	}
+}
+
+// WithProxy specifies the proxy used for the login and saved for the registry.
+func WithProxy(proxy string) RegistryLoginOpt {
+	return func(r *RegistryLogin) error {
+		r.proxy = proxy
+		return nil
+	}
+}
+
+// WithMinTLSVersion specifies the minimum TLS version used for the login and
+// saved for the registry.
+func WithMinTLSVersion(version string) RegistryLoginOpt {
+	return func(r *RegistryLogin) error {
+		r.minTLS = version
+		return nil
+	}
+}
+
 // NewRegistryLogin creates a new RegistryLogin object with the given configuration.
 func NewRegistryLogin(cfg *Configuration) *RegistryLogin {
 	return &RegistryLogin{
@@ -84,5 +113,8 @@ func (a *RegistryLogin) Run(_ io.Writer, hostname string, username string, passw
 		hostname,
 		registry.LoginOptBasicAuth(username, password),
 		registry.LoginOptInsecure(a.insecure),
-		registry.LoginOptTLSClientConfig(a.certFile, a.keyFile, a.caFile))
+		registry.LoginOptTLSClientConfig(a.certFile, a.keyFile, a.caFile),
+		registry.LoginOptSaveTLS(a.saveTLS),
+		registry.LoginOptProxy(a.proxy),
+		registry.LoginOptMinTLSVersion(a.minTLS))
 }
@@ -0,0 +1,410 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/internal/transport"
+	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/helmpath"
+	"helm.sh/helm/v4/pkg/repo"
+)
+
+// repoLockTimeout is the time for which the repository actions wait for the
+// lock of the repositories file.
+const repoLockTimeout = 30 * time.Second
+
+// ErrNoRepositories is returned by the repository actions when no repository
+// is configured.
+var ErrNoRepositories = errors.New("no repositories configured")
+
+// Repositories that have been permanently deleted and no longer work
+var deprecatedRepos = map[string]string{
+	"//kubernetes-charts.storage.googleapis.com":           "https://charts.helm.sh/stable",
+	"//kubernetes-charts-incubator.storage.googleapis.com": "https://charts.helm.sh/incubator",
+}
+
+// RepoErrorReason is the reason of a RepoError.
+type RepoErrorReason string
+
+const (
+	// RepoInvalid reports an invalid name or configuration of a repository.
+	RepoInvalid RepoErrorReason = "Invalid"
+	// RepoDeprecated reports a repository that has been permanently deleted.
+	RepoDeprecated RepoErrorReason = "Deprecated"
+	// RepoExists reports a repository whose name is taken by a repository
+	// with a different configuration.
+	RepoExists RepoErrorReason = "Exists"
+	// RepoNotFound reports a repository that is not configured.
+	RepoNotFound RepoErrorReason = "NotFound"
+	// RepoUnreachable reports a repository whose index cannot be downloaded.
+	RepoUnreachable RepoErrorReason = "Unreachable"
+	// RepoReadOnly reports a repository defined by a read-only fragment file,
+	// see repo.LoadFiles.
+	RepoReadOnly RepoErrorReason = "ReadOnly"
+)
+
+// RepoError reports why an operation on a chart repository failed.
+type RepoError struct {
+	Name   string
+	Reason RepoErrorReason
+	// Err is the underlying error, if any.
+	Err error
+
+	msg string
+}
+
+func (e *RepoError) Error() string {
+	switch {
+	case e.msg == "" && e.Err != nil:
+		return e.Err.Error()
+	case e.Err != nil:
+		return e.msg + ": " + e.Err.Error()
+	default:
+		return e.msg
+	}
+}
+
+func (e *RepoError) Unwrap() error {
+	return e.Err
+}
+
+// RepoAdd is the action for adding a chart repository.
+//
+// It provides the implementation of 'helm repo add'.
+type RepoAdd struct {
+	// RepositoryConfig is the path to the repositories file.
+	RepositoryConfig string
+	// RepositoryConfigDir is the path to the directory of read-only
+	// repository fragment files. Their repositories cannot be replaced.
+	RepositoryConfigDir string
+	// RepositoryCache is the path to the repository cache directory. The
+	// default cache directory is used if it is empty.
+	RepositoryCache string
+	// Getters download the index of the repository.
+	Getters getter.Providers
+
+	// ForceUpdate replaces a repository with the same name and a different
+	// configuration.
+	ForceUpdate bool
+	// AllowDeprecatedRepos allows adding official repositories that have been
+	// permanently deleted.
+	AllowDeprecatedRepos bool
+}
+
+// NewRepoAdd creates a new RepoAdd object with the repository settings.
+func NewRepoAdd(settings *cli.EnvSettings) *RepoAdd {
+	return &RepoAdd{
+		RepositoryConfig:    settings.RepositoryConfig,
+		RepositoryConfigDir: settings.RepositoryConfigDir,
+		RepositoryCache:     settings.RepositoryCache,
+		Getters:             getter.All(settings),
+	}
+}
+
+// Run validates the repository, downloads its index and adds it to the
+// repositories file. It returns false if the repository already exists with
+// the same configuration, which leaves the repositories file unchanged.
+//
+// Errors about the repository are of type *RepoError.
+func (a *RepoAdd) Run(entry *repo.Entry) (bool, error) {
+	if err := a.validate(entry); err != nil {
+		return false, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), repoLockTimeout)
+	defer cancel()
+	unlock, err := repo.LockFile(ctx, a.RepositoryConfig)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	f, err := repo.LoadFile(a.RepositoryConfig)
+	if err != nil && !isNotExist(err) {
+		return false, err
+	}
+	fragments, err := repo.LoadFragments(a.RepositoryConfigDir)
+	if err != nil {
+		return false, err
+	}
+	if source := fragments.Source(entry.Name); source != "" {
+		return false, readOnlyError(entry.Name, source)
+	}
+
+	// If the repo exists do one of two things:
+	// 1. If the configuration for the name is the same continue without error
+	// 2. When the config is different require ForceUpdate
+	if existing := f.Get(entry.Name); existing != nil && !a.ForceUpdate {
+		if *entry != *existing {
+			return false, &RepoError{Name: entry.Name, Reason: RepoExists,
+				msg: fmt.Sprintf("repository name (%s) already exists, please specify a different name", entry.Name)}
+		}
+		return false, nil
+	}
+
+	r, err := repo.NewChartRepository(entry, a.Getters)
+	if err != nil {
+		return false, &RepoError{Name: entry.Name, Reason: RepoInvalid, Err: err}
+	}
+	if a.RepositoryCache != "" {
+		r.CachePath = a.RepositoryCache
+	}
+	if _, err := r.DownloadIndexFile(); err != nil {
+		return false, &RepoError{Name: entry.Name, Reason: RepoUnreachable, Err: err,
+			msg: fmt.Sprintf("looks like %q is not a valid chart repository or cannot be reached", entry.URL)}
+	}
+
+	f.Update(entry)
+	return true, f.WriteFile(a.RepositoryConfig, 0600)
+}
+
+func (a *RepoAdd) validate(entry *repo.Entry) error {
+	// Check if the repo name is legal
+	if strings.Contains(entry.Name, "/") {
+		return &RepoError{Name: entry.Name, Reason: RepoInvalid,
+			msg: fmt.Sprintf("repository name (%s) contains '/', please specify a different name without '/'", entry.Name)}
+	}
+	if u, err := url.Parse(entry.URL); err != nil || u.Scheme == "" {
+		return &RepoError{Name: entry.Name, Reason: RepoInvalid, Err: err,
+			msg: fmt.Sprintf("invalid chart repository URL %q", entry.URL)}
+	}
+	// Block deprecated repos
+	if !a.AllowDeprecatedRepos {
+		for oldURL, newURL := range deprecatedRepos {
+			if strings.Contains(entry.URL, oldURL) {
+				return &RepoError{Name: entry.Name, Reason: RepoDeprecated,
+					msg: fmt.Sprintf("repo %q is no longer available; try %q instead", entry.URL, newURL)}
+			}
+		}
+	}
+	if _, err := transport.ProxyFunc(entry.Proxy); err != nil {
+		return &RepoError{Name: entry.Name, Reason: RepoInvalid, Err: err}
+	}
+	if entry.MinTLSVersion != "" {
+		if _, err := transport.ParseTLSVersion(entry.MinTLSVersion); err != nil {
+			return &RepoError{Name: entry.Name, Reason: RepoInvalid, Err: err}
+		}
+	}
+	return nil
+}
+
+// RepoRemove is the action for removing chart repositories.
+//
+// It provides the implementation of 'helm repo remove'.
+type RepoRemove struct {
+	// RepositoryConfig is the path to the repositories file.
+	RepositoryConfig string
+	// RepositoryConfigDir is the path to the directory of read-only
+	// repository fragment files. Their repositories cannot be removed.
+	RepositoryConfigDir string
+	// RepositoryCache is the path to the repository cache directory, from
+	// which the cached indexes of the repositories are removed.
+	RepositoryCache string
+}
+
+// NewRepoRemove creates a new RepoRemove object with the repository settings.
+func NewRepoRemove(settings *cli.EnvSettings) *RepoRemove {
+	return &RepoRemove{
+		RepositoryConfig:    settings.RepositoryConfig,
+		RepositoryConfigDir: settings.RepositoryConfigDir,
+		RepositoryCache:     settings.RepositoryCache,
+	}
+}
+
+// Run removes the named repositories and their cached indexes, and returns
+// the names of the removed repositories. It stops at the first repository
+// that is not configured, with a *RepoError.
+func (r *RepoRemove) Run(names ...string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), repoLockTimeout)
+	defer cancel()
+	unlock, err := repo.LockFile(ctx, r.RepositoryConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	f, err := repo.LoadFile(r.RepositoryConfig)
+	if err != nil && !isNotExist(err) {
+		return nil, err
+	}
+	fragments, err := repo.LoadFragments(r.RepositoryConfigDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Repositories) == 0 && len(fragments.Repositories) == 0 {
+		return nil, ErrNoRepositories
+	}
+
+	var removed []string
+	for _, name := range names {
+		if source := fragments.Source(name); source != "" {
+			return removed, readOnlyError(name, source)
+		}
+		if !f.Remove(name) {
+			return removed, &RepoError{Name: name, Reason: RepoNotFound, msg: fmt.Sprintf("no repo named %q found", name)}
+		}
+		if err := f.WriteFile(r.RepositoryConfig, 0600); err != nil {
+			return removed, err
+		}
+		if err := removeRepoCache(r.RepositoryCache, name); err != nil {
+			return removed, err
+		}
+		removed = append(removed, name)
+	}
+	return removed, nil
+}
+
+func removeRepoCache(root, name string) error {
+	idx := filepath.Join(root, helmpath.CacheChartsFile(name))
+	if _, err := os.Stat(idx); err == nil {
+		os.Remove(idx)
+	}
+
+	idx = filepath.Join(root, helmpath.CacheIndexFile(name))
+	if _, err := os.Stat(idx); os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(err, "can't remove index file %s", idx)
+	}
+	return os.Remove(idx)
+}
+
+// RepoUpdate is the action for updating the cached indexes of chart
+// repositories.
+//
+// It provides the implementation of 'helm repo update'.
+type RepoUpdate struct {
+	// RepositoryConfig is the path to the repositories file.
+	RepositoryConfig string
+	// RepositoryConfigDir is the path to the directory of repository
+	// fragment files, whose repositories are updated too.
+	RepositoryConfigDir string
+	// RepositoryCache is the path to the repository cache directory. The
+	// default cache directory is used if it is empty.
+	RepositoryCache string
+	// Getters download the indexes of the repositories.
+	Getters getter.Providers
+}
+
+// RepoUpdateResult is the result of updating a repository. Err is a
+// *RepoError if the index of the repository could not be downloaded.
+type RepoUpdateResult struct {
+	Name string
+	URL  string
+	Err  error
+}
+
+// NewRepoUpdate creates a new RepoUpdate object with the repository settings.
+func NewRepoUpdate(settings *cli.EnvSettings) *RepoUpdate {
+	return &RepoUpdate{
+		RepositoryConfig:    settings.RepositoryConfig,
+		RepositoryConfigDir: settings.RepositoryConfigDir,
+		RepositoryCache:     settings.RepositoryCache,
+		Getters:             getter.All(settings),
+	}
+}
+
+// Run downloads the indexes of the named repositories, or of all
+// repositories if no name is given, in parallel. The failures of single
+// repositories are reported in their results.
+func (u *RepoUpdate) Run(names ...string) ([]RepoUpdateResult, error) {
+	repos, err := u.Repositories(names...)
+	if err != nil {
+		return nil, err
+	}
+	return UpdateRepositories(repos), nil
+}
+
+// Repositories returns the named repositories, or all repositories if no
+// name is given. It fails with a *RepoError if a named repository is not
+// configured.
+func (u *RepoUpdate) Repositories(names ...string) ([]*repo.ChartRepository, error) {
+	// The repositories file is written atomically, so that it can be read
+	// without holding its lock
+	f, err := repo.LoadFiles(u.RepositoryConfig, u.RepositoryConfigDir)
+	switch {
+	case err != nil:
+		return nil, errors.Wrapf(err, "failed loading file: %s", u.RepositoryConfig)
+	case len(f.Repositories) == 0:
+		return nil, ErrNoRepositories
+	}
+
+	// Fail early if the user specified an invalid repo to update
+	for _, name := range names {
+		if !f.Has(name) {
+			return nil, &RepoError{Name: name, Reason: RepoNotFound,
+				msg: fmt.Sprintf("no repositories found matching '%s'.  Nothing will be updated", name)}
+		}
+	}
+
+	var repos []*repo.ChartRepository
+	for _, cfg := range f.Repositories {
+		if len(names) > 0 && !slices.Contains(names, cfg.Name) {
+			continue
+		}
+		r, err := repo.NewChartRepository(cfg, u.Getters)
+		if err != nil {
+			return nil, &RepoError{Name: cfg.Name, Reason: RepoInvalid, Err: err}
+		}
+		if u.RepositoryCache != "" {
+			r.CachePath = u.RepositoryCache
+		}
+		repos = append(repos, r)
+	}
+	return repos, nil
+}
+
+// UpdateRepositories downloads the indexes of the repositories in parallel
+// and returns their results in the order of the repositories.
+func UpdateRepositories(repos []*repo.ChartRepository) []RepoUpdateResult {
+	results := make([]RepoUpdateResult, len(repos))
+	var wg sync.WaitGroup
+	for i, r := range repos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = RepoUpdateResult{Name: r.Config.Name, URL: r.Config.URL}
+			if _, err := r.DownloadIndexFile(); err != nil {
+				results[i].Err = &RepoError{Name: r.Config.Name, Reason: RepoUnreachable, Err: err}
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func readOnlyError(name, source string) error {
+	return &RepoError{Name: name, Reason: RepoReadOnly,
+		msg: fmt.Sprintf("repository %q is defined in the read-only file %s", name, source)}
+}
+
+func isNotExist(err error) bool {
+	return os.IsNotExist(errors.Cause(err))
+}
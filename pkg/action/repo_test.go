@@ -0,0 +1,177 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/helmpath"
+	"helm.sh/helm/v4/pkg/repo"
+	"helm.sh/helm/v4/pkg/repo/repotest"
+)
+
+func repoSettings(t *testing.T) *cli.EnvSettings {
+	t.Helper()
+	settings := cli.New()
+	settings.RepositoryConfig = filepath.Join(t.TempDir(), "repositories.yaml")
+	settings.RepositoryCache = t.TempDir()
+	return settings
+}
+
+func TestRepoAdd(t *testing.T) {
+	srv, err := repotest.NewTempServerWithCleanup(t, "../repo/testdata/server/*.*")
+	require.NoError(t, err)
+	defer srv.Stop()
+	settings := repoSettings(t)
+
+	client := NewRepoAdd(settings)
+	added, err := client.Run(&repo.Entry{Name: "test", URL: srv.URL()})
+	require.NoError(t, err)
+	assert.True(t, added)
+	assert.FileExists(t, filepath.Join(settings.RepositoryCache, helmpath.CacheIndexFile("test")))
+
+	added, err = client.Run(&repo.Entry{Name: "test", URL: srv.URL()})
+	require.NoError(t, err)
+	assert.False(t, added, "expected adding the same repository to be skipped")
+
+	_, err = client.Run(&repo.Entry{Name: "test", URL: srv.URL() + "/other"})
+	var repoErr *RepoError
+	require.ErrorAs(t, err, &repoErr)
+	assert.Equal(t, RepoExists, repoErr.Reason)
+
+	tests := map[string]struct {
+		entry  repo.Entry
+		reason RepoErrorReason
+	}{
+		"slash in name":    {repo.Entry{Name: "a/b", URL: srv.URL()}, RepoInvalid},
+		"invalid URL":      {repo.Entry{Name: "a", URL: "::"}, RepoInvalid},
+		"relative URL":     {repo.Entry{Name: "a", URL: "charts.example.com"}, RepoInvalid},
+		"deprecated":       {repo.Entry{Name: "a", URL: "https://kubernetes-charts.storage.googleapis.com"}, RepoDeprecated},
+		"invalid TLS":      {repo.Entry{Name: "a", URL: srv.URL(), MinTLSVersion: "0.9"}, RepoInvalid},
+		"not a chart repo": {repo.Entry{Name: "a", URL: srv.URL() + "/missing"}, RepoUnreachable},
+	}
+	for name, tt := range tests {
+		_, err := client.Run(&tt.entry)
+		if assert.ErrorAs(t, err, &repoErr, name) {
+			assert.Equal(t, tt.reason, repoErr.Reason, name)
+		}
+	}
+
+	f, err := repo.LoadFile(settings.RepositoryConfig)
+	require.NoError(t, err)
+	assert.Len(t, f.Repositories, 1)
+}
+
+func TestRepoAddConcurrent(t *testing.T) {
+	srv, err := repotest.NewTempServerWithCleanup(t, "../repo/testdata/server/*.*")
+	require.NoError(t, err)
+	defer srv.Stop()
+	settings := repoSettings(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := NewRepoAdd(settings).Run(&repo.Entry{Name: fmt.Sprintf("test-%d", i), URL: srv.URL()})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	f, err := repo.LoadFile(settings.RepositoryConfig)
+	require.NoError(t, err)
+	assert.Len(t, f.Repositories, 3)
+}
+
+func TestRepoRemoveAndUpdate(t *testing.T) {
+	srv, err := repotest.NewTempServerWithCleanup(t, "../repo/testdata/server/*.*")
+	require.NoError(t, err)
+	defer srv.Stop()
+	settings := repoSettings(t)
+
+	_, err = NewRepoUpdate(settings).Run()
+	assert.ErrorIs(t, err, ErrNoRepositories)
+	_, err = NewRepoRemove(settings).Run("test")
+	assert.ErrorIs(t, err, ErrNoRepositories)
+
+	for _, name := range []string{"a", "b"} {
+		_, err := NewRepoAdd(settings).Run(&repo.Entry{Name: name, URL: srv.URL()})
+		require.NoError(t, err)
+	}
+
+	results, err := NewRepoUpdate(settings).Run("b")
+	require.NoError(t, err)
+	assert.Equal(t, []RepoUpdateResult{{Name: "b", URL: srv.URL()}}, results)
+	_, err = NewRepoUpdate(settings).Run("c")
+	assert.EqualError(t, err, "no repositories found matching 'c'.  Nothing will be updated")
+
+	removed, err := NewRepoRemove(settings).Run("a", "c", "b")
+	assert.Equal(t, []string{"a"}, removed)
+	var repoErr *RepoError
+	require.True(t, errors.As(err, &repoErr))
+	assert.Equal(t, RepoNotFound, repoErr.Reason)
+	assert.NoFileExists(t, filepath.Join(settings.RepositoryCache, helmpath.CacheIndexFile("a")))
+
+	srv.Stop()
+	results, err = NewRepoUpdate(settings).Run()
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.ErrorAs(t, results[0].Err, &repoErr)
+	assert.Equal(t, RepoUnreachable, repoErr.Reason)
+}
+
+func TestRepoReadOnlyFragments(t *testing.T) {
+	srv, err := repotest.NewTempServerWithCleanup(t, "../repo/testdata/server/*.*")
+	require.NoError(t, err)
+	defer srv.Stop()
+	settings := repoSettings(t)
+	settings.RepositoryConfigDir = t.TempDir()
+
+	managed := repo.NewFile()
+	managed.Add(&repo.Entry{Name: "managed", URL: srv.URL()})
+	source := filepath.Join(settings.RepositoryConfigDir, "managed.yaml")
+	require.NoError(t, managed.WriteFile(source, 0644))
+
+	var repoErr *RepoError
+	_, err = NewRepoAdd(settings).Run(&repo.Entry{Name: "managed", URL: srv.URL() + "/other"})
+	require.ErrorAs(t, err, &repoErr)
+	assert.Equal(t, RepoReadOnly, repoErr.Reason)
+	assert.EqualError(t, err, fmt.Sprintf("repository \"managed\" is defined in the read-only file %s", source))
+
+	_, err = NewRepoRemove(settings).Run("managed")
+	require.ErrorAs(t, err, &repoErr)
+	assert.Equal(t, RepoReadOnly, repoErr.Reason)
+
+	_, err = NewRepoAdd(settings).Run(&repo.Entry{Name: "mine", URL: srv.URL()})
+	require.NoError(t, err)
+	results, err := NewRepoUpdate(settings).Run()
+	require.NoError(t, err)
+	assert.Equal(t, []RepoUpdateResult{{Name: "mine", URL: srv.URL()}, {Name: "managed", URL: srv.URL()}}, results)
+
+	f, err := repo.LoadFile(settings.RepositoryConfig)
+	require.NoError(t, err)
+	assert.Len(t, f.Repositories, 1, "expected the managed repository not to be written to the repositories file")
+}
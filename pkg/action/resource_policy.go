@@ -17,30 +17,22 @@ limitations under the License.
 package action
 
 import (
-	"strings"
-
-	"helm.sh/helm/v4/pkg/kube"
+	"helm.sh/helm/v4/pkg/annotations"
 	"helm.sh/helm/v4/pkg/releaseutil"
 )
 
 func filterManifestsToKeep(manifests []releaseutil.Manifest) (keep, remaining []releaseutil.Manifest) {
 	for _, m := range manifests {
-		if m.Head.Metadata == nil || m.Head.Metadata.Annotations == nil || len(m.Head.Metadata.Annotations) == 0 {
-			remaining = append(remaining, m)
-			continue
+		var policy string
+		if m.Head.Metadata != nil {
+			policy = annotations.ParseResourcePolicy(m.Head.Metadata.Annotations)
 		}
-
-		resourcePolicyType, ok := m.Head.Metadata.Annotations[kube.ResourcePolicyAnno]
-		if !ok {
+		switch policy {
+		case "":
 			remaining = append(remaining, m)
-			continue
-		}
-
-		resourcePolicyType = strings.ToLower(strings.TrimSpace(resourcePolicyType))
-		if resourcePolicyType == kube.KeepPolicy {
+		case annotations.KeepPolicy:
 			keep = append(keep, m)
 		}
-
 	}
 	return keep, remaining
 }
@@ -0,0 +1,178 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+// ConfigChecksumAnnotation is the pod template annotation that holds the
+// checksum of the ConfigMaps and Secrets of a release that a workload
+// references. A change of the checksum rolls out new pods.
+const ConfigChecksumAnnotation = "helm.sh/config-checksum"
+
+// restartableKinds are the workloads whose pods are replaced when their pod
+// template changes.
+var restartableKinds = map[schema.GroupKind]bool{
+	{Group: "apps", Kind: "Deployment"}:  true,
+	{Group: "apps", Kind: "StatefulSet"}: true,
+	{Group: "apps", Kind: "DaemonSet"}:   true,
+	{Group: "apps", Kind: "ReplicaSet"}:  true,
+}
+
+// configChecksumVisitor sets ConfigChecksumAnnotation on the pod templates of
+// the workloads that reference ConfigMaps or Secrets of the given resources.
+// Workloads that only reference ConfigMaps and Secrets of other releases are
+// left untouched.
+func configChecksumVisitor(resources kube.ResourceList) (resource.VisitorFunc, error) {
+	checksums := map[string]string{}
+	err := resources.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		gk := info.Mapping.GroupVersionKind.GroupKind()
+		if gk.Group != "" || (gk.Kind != "ConfigMap" && gk.Kind != "Secret") {
+			return nil
+		}
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(info.Object)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal([]interface{}{obj["data"], obj["binaryData"], obj["stringData"]})
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		checksums[configKey(gk.Kind, info.Namespace, info.Name)] = hex.EncodeToString(sum[:])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		if !restartableKinds[info.Mapping.GroupVersionKind.GroupKind()] {
+			return nil
+		}
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(info.Object)
+		if err != nil {
+			return err
+		}
+		podSpec, ok, _ := unstructured.NestedMap(obj, "spec", "template", "spec")
+		if !ok {
+			return nil
+		}
+
+		var sums []string
+		for _, ref := range configReferences(podSpec) {
+			if sum, ok := checksums[configKey(ref.kind, info.Namespace, ref.name)]; ok {
+				sums = append(sums, ref.kind+"/"+ref.name+"="+sum)
+			}
+		}
+		if len(sums) == 0 {
+			return nil
+		}
+		sort.Strings(sums)
+		sum := sha256.Sum256([]byte(fmt.Sprint(sums)))
+
+		annotations, _, _ := unstructured.NestedStringMap(obj, "spec", "template", "metadata", "annotations")
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[ConfigChecksumAnnotation] = hex.EncodeToString(sum[:])
+		if err := unstructured.SetNestedStringMap(obj, annotations, "spec", "template", "metadata", "annotations"); err != nil {
+			return err
+		}
+
+		if u, ok := info.Object.(*unstructured.Unstructured); ok {
+			u.Object = obj
+			return nil
+		}
+		return runtime.DefaultUnstructuredConverter.FromUnstructured(obj, info.Object)
+	}, nil
+}
+
+type configReference struct {
+	kind, name string
+}
+
+// configReferences returns the ConfigMaps and Secrets that a pod spec
+// references in volumes and in the environment of its containers.
+func configReferences(podSpec map[string]interface{}) []configReference {
+	var refs []configReference
+	add := func(kind string, obj interface{}, fields ...string) {
+		m, ok := obj.(map[string]interface{})
+		if !ok {
+			return
+		}
+		if name, ok, _ := unstructured.NestedString(m, fields...); ok && name != "" {
+			refs = append(refs, configReference{kind: kind, name: name})
+		}
+	}
+
+	volumes, _, _ := unstructured.NestedSlice(podSpec, "volumes")
+	for _, v := range volumes {
+		add("ConfigMap", v, "configMap", "name")
+		add("Secret", v, "secret", "secretName")
+		if m, ok := v.(map[string]interface{}); ok {
+			sources, _, _ := unstructured.NestedSlice(m, "projected", "sources")
+			for _, s := range sources {
+				add("ConfigMap", s, "configMap", "name")
+				add("Secret", s, "secret", "name")
+			}
+		}
+	}
+
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, _, _ := unstructured.NestedSlice(podSpec, field)
+		for _, c := range containers {
+			m, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			env, _, _ := unstructured.NestedSlice(m, "env")
+			for _, e := range env {
+				add("ConfigMap", e, "valueFrom", "configMapKeyRef", "name")
+				add("Secret", e, "valueFrom", "secretKeyRef", "name")
+			}
+			envFrom, _, _ := unstructured.NestedSlice(m, "envFrom")
+			for _, e := range envFrom {
+				add("ConfigMap", e, "configMapRef", "name")
+				add("Secret", e, "secretRef", "name")
+			}
+		}
+	}
+	return refs
+}
+
+func configKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
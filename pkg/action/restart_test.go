@@ -0,0 +1,161 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+func unstructuredInfo(gvk schema.GroupVersionKind, obj map[string]interface{}) *resource.Info {
+	u := &unstructured.Unstructured{Object: obj}
+	return &resource.Info{
+		Name:      u.GetName(),
+		Namespace: "ns-a",
+		Mapping:   &meta.RESTMapping{GroupVersionKind: gvk},
+		Object:    u,
+	}
+}
+
+func configMapInfo(name, value string) *resource.Info {
+	return unstructuredInfo(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": name},
+		"data":       map[string]interface{}{"key": value},
+	})
+}
+
+func deploymentInfo(name string, podSpec map[string]interface{}) *resource.Info {
+	return unstructuredInfo(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{"annotations": map[string]interface{}{"keep": "me"}},
+				"spec":     podSpec,
+			},
+		},
+	})
+}
+
+func configChecksum(t *testing.T, info *resource.Info) (string, bool) {
+	t.Helper()
+	annotations, _, err := unstructured.NestedStringMap(info.Object.(*unstructured.Unstructured).Object, "spec", "template", "metadata", "annotations")
+	require.NoError(t, err)
+	sum, ok := annotations[ConfigChecksumAnnotation]
+	return sum, ok
+}
+
+func annotateConfigChecksums(t *testing.T, resources kube.ResourceList) {
+	t.Helper()
+	visitor, err := configChecksumVisitor(resources)
+	require.NoError(t, err)
+	require.NoError(t, resources.Visit(visitor))
+}
+
+func TestConfigChecksumVisitor(t *testing.T) {
+	render := func(value string) kube.ResourceList {
+		return kube.ResourceList{
+			configMapInfo("config", value),
+			deploymentInfo("env", map[string]interface{}{
+				"containers": []interface{}{map[string]interface{}{
+					"name":    "app",
+					"envFrom": []interface{}{map[string]interface{}{"configMapRef": map[string]interface{}{"name": "config"}}},
+				}},
+			}),
+			deploymentInfo("volume", map[string]interface{}{
+				"volumes": []interface{}{map[string]interface{}{"name": "config", "configMap": map[string]interface{}{"name": "config"}}},
+			}),
+			deploymentInfo("external", map[string]interface{}{
+				"volumes": []interface{}{map[string]interface{}{"name": "config", "configMap": map[string]interface{}{"name": "other"}}},
+			}),
+		}
+	}
+
+	first := render("a")
+	annotateConfigChecksums(t, first)
+	envSum, ok := configChecksum(t, first[1])
+	require.True(t, ok, "expected the deployment referencing the ConfigMap in its environment to be annotated")
+	volumeSum, ok := configChecksum(t, first[2])
+	require.True(t, ok, "expected the deployment mounting the ConfigMap to be annotated")
+	assert.Equal(t, envSum, volumeSum)
+	_, ok = configChecksum(t, first[3])
+	assert.False(t, ok, "expected the deployment referencing another ConfigMap to be left untouched")
+	annotations, _, _ := unstructured.NestedStringMap(first[1].Object.(*unstructured.Unstructured).Object, "spec", "template", "metadata", "annotations")
+	assert.Equal(t, "me", annotations["keep"])
+
+	same := render("a")
+	annotateConfigChecksums(t, same)
+	sum, _ := configChecksum(t, same[1])
+	assert.Equal(t, envSum, sum, "expected the checksum to be stable")
+
+	changed := render("b")
+	annotateConfigChecksums(t, changed)
+	sum, _ = configChecksum(t, changed[1])
+	assert.NotEqual(t, envSum, sum, "expected the checksum to change with the ConfigMap")
+}
+
+func TestConfigChecksumVisitorTyped(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{Name: "web", Namespace: "ns-a"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name: "app",
+						Env: []corev1.EnvVar{{
+							Name: "PASSWORD",
+							ValueFrom: &corev1.EnvVarSource{
+								SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"}, Key: "password"},
+							},
+						}},
+					}},
+				},
+			},
+		},
+	}
+	resources := kube.ResourceList{
+		{
+			Name:      "credentials",
+			Namespace: "ns-a",
+			Mapping:   &meta.RESTMapping{GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Secret"}},
+			Object:    &corev1.Secret{ObjectMeta: v1.ObjectMeta{Name: "credentials"}, StringData: map[string]string{"password": "secret"}},
+		},
+		{
+			Name:      "web",
+			Namespace: "ns-a",
+			Mapping:   &meta.RESTMapping{GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}},
+			Object:    deployment,
+		},
+	}
+
+	annotateConfigChecksums(t, resources)
+	assert.Len(t, deployment.Spec.Template.Annotations[ConfigChecksumAnnotation], 64)
+}
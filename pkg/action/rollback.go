@@ -18,17 +18,47 @@ package action
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 
+	"helm.sh/helm/v4/internal/version"
 	"helm.sh/helm/v4/pkg/chartutil"
 	"helm.sh/helm/v4/pkg/release"
 	helmtime "helm.sh/helm/v4/pkg/time"
 )
 
+// RollbackMode selects what a rollback restores from the revision it rolls
+// back to.
+type RollbackMode string
+
+const (
+	// RollbackAll restores the whole revision, replaying its stored manifest
+	// and hooks.
+	RollbackAll RollbackMode = ""
+	// RollbackValues restores the values of the revision, re-rendering the
+	// chart of the current revision with them.
+	RollbackValues RollbackMode = "values"
+	// RollbackChart restores the chart of the revision, re-rendering it with
+	// the values of the current revision.
+	RollbackChart RollbackMode = "chart"
+)
+
+// ParseRollbackMode parses the name of a rollback mode. "all" is accepted for
+// RollbackAll.
+func ParseRollbackMode(s string) (RollbackMode, error) {
+	switch m := RollbackMode(s); m {
+	case RollbackAll, RollbackValues, RollbackChart:
+		return m, nil
+	case "all":
+		return RollbackAll, nil
+	}
+	return "", errors.Errorf("invalid rollback mode %q, must be one of all, values or chart", s)
+}
+
 // Rollback is the action for rolling back to a given release.
 //
 // It provides the implementation of 'helm rollback'.
@@ -45,6 +75,14 @@ type Rollback struct {
 	Force         bool // will (if true) force resource upgrade through uninstall/recreate if needed
 	CleanupOnFail bool
 	MaxHistory    int // MaxHistory limits the maximum number of revisions saved per release
+
+	// Mode selects what is restored from the revision rolled back to. The
+	// values and chart modes re-render the release, applying the values
+	// pinned to it, instead of replaying the stored manifest.
+	Mode RollbackMode
+	// SaveConfig maintains the kubectl.kubernetes.io/last-applied-configuration
+	// annotation of the resources like kubectl apply, see kube.SaveConfig.
+	SaveConfig bool
 }
 
 // NewRollback creates a new Rollback object with the given configuration.
@@ -148,17 +186,85 @@ func (r *Rollback) prepareRollback(name string) (*release.Release, *release.Rele
 			Notes:         previousRelease.Info.Notes,
 			// Because we lose the reference to previous version elsewhere, we set the
 			// message here, and only override it later if we experience failure.
-			Description: fmt.Sprintf("Rollback to %d", previousVersion),
+			Description:   fmt.Sprintf("Rollback to %d", previousVersion),
+			Operator:      r.cfg.operator(),
+			ClientVersion: version.GetVersion(),
 		},
 		Version:  currentRelease.Version + 1,
 		Labels:   previousRelease.Labels,
 		Manifest: previousRelease.Manifest,
 		Hooks:    previousRelease.Hooks,
+		// The pinned values belong to the release, not to the revision
+		// rolled back to.
+		PinnedValues: currentRelease.PinnedValues,
+		ConfigRaw:    previousRelease.ConfigRaw,
+
+		ValuesSources: previousRelease.ValuesSources,
+	}
+	targetRelease.Info.Reason = release.ReasonPending
+
+	switch r.Mode {
+	case RollbackAll:
+		return currentRelease, targetRelease, nil
+	case RollbackValues:
+		targetRelease.Chart = currentRelease.Chart
+		targetRelease.Info.Description = fmt.Sprintf("Rollback of values to %d", previousVersion)
+	case RollbackChart:
+		targetRelease.Config = currentRelease.Config
+		targetRelease.ConfigRaw = currentRelease.ConfigRaw
+		targetRelease.ValuesSources = currentRelease.ValuesSources
+		targetRelease.Info.Description = fmt.Sprintf("Rollback of chart to %d (%s-%s)", previousVersion, previousRelease.Chart.Name(), previousRelease.Chart.Metadata.Version)
+	default:
+		return nil, nil, errors.Errorf("invalid rollback mode %q", r.Mode)
+	}
+	targetRelease.Labels = currentRelease.Labels
+	if err := r.render(targetRelease); err != nil {
+		return nil, nil, err
 	}
 
 	return currentRelease, targetRelease, nil
 }
 
+// render renders the chart of the release with its values and pinned values,
+// for the rollbacks that mix the chart and the values of different revisions.
+// The pinned values are not merged into the values of the release.
+func (r *Rollback) render(rel *release.Release) error {
+	vals, err := withPinnedValues(rel.Config, nil, rel.PinnedValues)
+	if err != nil {
+		return err
+	}
+	if err := chartutil.ProcessDependencies(rel.Chart, vals); err != nil {
+		return err
+	}
+
+	options := chartutil.ReleaseOptions{
+		Name:      rel.Name,
+		Namespace: rel.Namespace,
+		Revision:  rel.Version,
+		IsUpgrade: true,
+	}
+	caps, err := r.cfg.getCapabilities()
+	if err != nil {
+		return err
+	}
+	valuesToRender, err := chartutil.ToRenderValues(rel.Chart, vals, options, caps)
+	if err != nil {
+		return err
+	}
+
+	hooks, manifestDoc, notesTxt, err := r.cfg.renderResources(rel.Chart, valuesToRender, renderOptions{
+		interactWithRemote: !r.DryRun,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "unable to render the release for the rollback")
+	}
+
+	rel.Manifest = manifestDoc.String()
+	rel.Hooks = hooks
+	rel.Info.Notes = notesTxt
+	return validateManifest(r.cfg.KubeClient, manifestDoc.Bytes(), true)
+}
+
 func (r *Rollback) performRollback(currentRelease, targetRelease *release.Release) (*release.Release, error) {
 	if r.DryRun {
 		r.cfg.Log("dry run for %s", targetRelease.Name)
@@ -188,14 +294,13 @@ func (r *Rollback) performRollback(currentRelease, targetRelease *release.Releas
 	if err != nil {
 		return targetRelease, errors.Wrap(err, "unable to set metadata visitor from target release")
 	}
-	results, err := r.cfg.KubeClient.Update(current, target, r.Force)
+	results, err := r.cfg.updateResources(context.Background(), current, target, r.Force, 0, saveConfigOptions(r.SaveConfig)...)
 
 	if err != nil {
 		msg := fmt.Sprintf("Rollback %q failed: %s", targetRelease.Name, err)
 		r.cfg.Log("warning: %s", msg)
 		currentRelease.Info.Status = release.StatusSuperseded
-		targetRelease.Info.Status = release.StatusFailed
-		targetRelease.Info.Description = msg
+		targetRelease.SetFailed(release.ReasonApplyFailed, err, msg)
 		r.cfg.recordRelease(currentRelease)
 		r.cfg.recordRelease(targetRelease)
 		if r.CleanupOnFail {
@@ -226,14 +331,14 @@ func (r *Rollback) performRollback(currentRelease, targetRelease *release.Releas
 	if r.Wait {
 		if r.WaitForJobs {
 			if err := r.cfg.KubeClient.WaitWithJobs(target, r.Timeout); err != nil {
-				targetRelease.SetStatus(release.StatusFailed, fmt.Sprintf("Release %q failed: %s", targetRelease.Name, err.Error()))
+				targetRelease.SetFailed(release.ReasonWaitFailed, err, fmt.Sprintf("Release %q failed: %s", targetRelease.Name, err.Error()))
 				r.cfg.recordRelease(currentRelease)
 				r.cfg.recordRelease(targetRelease)
 				return targetRelease, errors.Wrapf(err, "release %s failed", targetRelease.Name)
 			}
 		} else {
 			if err := r.cfg.KubeClient.Wait(target, r.Timeout); err != nil {
-				targetRelease.SetStatus(release.StatusFailed, fmt.Sprintf("Release %q failed: %s", targetRelease.Name, err.Error()))
+				targetRelease.SetFailed(release.ReasonWaitFailed, err, fmt.Sprintf("Release %q failed: %s", targetRelease.Name, err.Error()))
 				r.cfg.recordRelease(currentRelease)
 				r.cfg.recordRelease(targetRelease)
 				return targetRelease, errors.Wrapf(err, "release %s failed", targetRelease.Name)
@@ -260,6 +365,7 @@ func (r *Rollback) performRollback(currentRelease, targetRelease *release.Releas
 	}
 
 	targetRelease.Info.Status = release.StatusDeployed
+	targetRelease.Info.Reason = release.ReasonRolledBack
 
 	return targetRelease, nil
 }
@@ -0,0 +1,118 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/release"
+)
+
+func rollbackTestRelease(version int, status release.Status, chartVersion, name string) *release.Release {
+	rel := namedReleaseStub("rolling", status)
+	rel.Version = version
+	rel.Chart = buildChart(func(opts *chartOptions) {
+		opts.Metadata.Version = chartVersion
+		opts.Templates = append(opts.Templates, &chart.File{
+			Name: "templates/config",
+			Data: []byte("name: {{ .Values.name }}\nchart: {{ .Chart.Version }}\n"),
+		})
+	})
+	rel.Config = map[string]interface{}{"name": name}
+	rel.Manifest = "name: " + name + "\nchart: " + chartVersion + "\n"
+	rel.Info.Notes = "revision notes"
+	return rel
+}
+
+func TestRollbackModes(t *testing.T) {
+	tests := []struct {
+		mode        RollbackMode
+		manifest    string
+		config      map[string]interface{}
+		description string
+	}{{
+		mode:        RollbackAll,
+		manifest:    "name: one\nchart: 0.1.0\n",
+		config:      map[string]interface{}{"name": "one"},
+		description: "Rollback to 1",
+	}, {
+		mode:        RollbackValues,
+		manifest:    "name: one\nchart: 0.2.0",
+		config:      map[string]interface{}{"name": "one"},
+		description: "Rollback of values to 1",
+	}, {
+		mode:        RollbackChart,
+		manifest:    "name: two\nchart: 0.1.0",
+		config:      map[string]interface{}{"name": "two"},
+		description: "Rollback of chart to 1 (hello-0.1.0)",
+	}}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			cfg := actionConfigFixture(t)
+			require.NoError(t, cfg.Releases.Create(rollbackTestRelease(1, release.StatusSuperseded, "0.1.0", "one")))
+			require.NoError(t, cfg.Releases.Create(rollbackTestRelease(2, release.StatusDeployed, "0.2.0", "two")))
+
+			client := NewRollback(cfg)
+			client.Version = 1
+			client.Mode = tt.mode
+			require.NoError(t, client.Run("rolling"))
+
+			rel, err := cfg.Releases.Get("rolling", 3)
+			require.NoError(t, err)
+			assert.Equal(t, release.StatusDeployed, rel.Info.Status)
+			assert.Equal(t, tt.description, rel.Info.Description)
+			assert.Contains(t, rel.Manifest, tt.manifest)
+			assert.Equal(t, tt.config, rel.Config)
+		})
+	}
+}
+
+func TestRollbackValuesWithPinnedValues(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	require.NoError(t, cfg.Releases.Create(rollbackTestRelease(1, release.StatusSuperseded, "0.1.0", "one")))
+	current := rollbackTestRelease(2, release.StatusDeployed, "0.2.0", "two")
+	current.PinnedValues = map[string]interface{}{"name": "pinned"}
+	require.NoError(t, cfg.Releases.Create(current))
+
+	client := NewRollback(cfg)
+	client.Version = 1
+	client.Mode = RollbackValues
+	require.NoError(t, client.Run("rolling"))
+
+	rel, err := cfg.Releases.Get("rolling", 3)
+	require.NoError(t, err)
+	assert.Contains(t, rel.Manifest, "name: pinned\nchart: 0.2.0")
+	assert.Equal(t, map[string]interface{}{"name": "pinned"}, rel.PinnedValues)
+}
+
+func TestParseRollbackMode(t *testing.T) {
+	mode, err := ParseRollbackMode("all")
+	require.NoError(t, err)
+	assert.Equal(t, RollbackAll, mode)
+
+	mode, err = ParseRollbackMode("values")
+	require.NoError(t, err)
+	assert.Equal(t, RollbackValues, mode)
+
+	_, err = ParseRollbackMode("everything")
+	assert.EqualError(t, err, `invalid rollback mode "everything", must be one of all, values or chart`)
+}
@@ -0,0 +1,198 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/internal/monocular"
+)
+
+const (
+	// ArtifactHubProviderName is the name of the search provider for Artifact
+	// Hub and other catalogs implementing the Monocular search API.
+	ArtifactHubProviderName = "artifacthub"
+	// DefaultHubEndpoint is the endpoint searched by default.
+	DefaultHubEndpoint = "https://hub.helm.sh"
+)
+
+// SearchQuery is a query of a SearchProvider.
+type SearchQuery struct {
+	// Keyword is the keyword, or the rich query, to search for.
+	Keyword string
+	// Filters restrict the results, e.g. "repo" to the name of a repository.
+	// Providers return an error for filters they do not support.
+	Filters map[string]string
+	// Offset is the number of results to skip.
+	Offset int
+	// Limit is the maximum number of results to return, zero for all.
+	Limit int
+}
+
+// SearchResult is a chart found by a SearchProvider.
+type SearchResult struct {
+	Name        string
+	URL         string
+	Version     string
+	AppVersion  string
+	Description string
+	Repository  SearchRepository
+}
+
+// SearchRepository is the repository of a SearchResult.
+type SearchRepository struct {
+	Name string
+	URL  string
+}
+
+// SearchResults is a page of the results of a query.
+type SearchResults struct {
+	Results []SearchResult
+	// Total is the number of results of the query before pagination.
+	Total int
+}
+
+// SearchProvider searches a chart catalog, such as Artifact Hub or an
+// internal catalog of an organization.
+type SearchProvider interface {
+	Search(query SearchQuery) (*SearchResults, error)
+}
+
+// SearchProviderConstructor creates a SearchProvider for the endpoint of a
+// catalog.
+type SearchProviderConstructor func(endpoint string) (SearchProvider, error)
+
+// SearchProviders are the search providers known to SearchHub by name. Tools
+// embedding Helm can add providers for their own catalogs before searching.
+var SearchProviders = map[string]SearchProviderConstructor{
+	ArtifactHubProviderName: NewArtifactHubProvider,
+}
+
+// SearchHub is the action for searching a chart catalog.
+//
+// It provides the implementation of 'helm search hub'.
+type SearchHub struct {
+	// Provider is the name of the provider in SearchProviders.
+	Provider string
+	// Endpoint is the URL of the catalog.
+	Endpoint string
+
+	Filters    map[string]string
+	Offset     int
+	MaxResults int
+}
+
+// NewSearchHub creates a new SearchHub object that searches Artifact Hub.
+func NewSearchHub() *SearchHub {
+	return &SearchHub{
+		Provider: ArtifactHubProviderName,
+		Endpoint: DefaultHubEndpoint,
+	}
+}
+
+// Run searches the catalog for the keyword.
+func (s *SearchHub) Run(keyword string) (*SearchResults, error) {
+	if s.Offset < 0 || s.MaxResults < 0 {
+		return nil, errors.New("the offset and the maximum number of results must not be negative")
+	}
+	newProvider, ok := SearchProviders[s.Provider]
+	if !ok {
+		names := make([]string, 0, len(SearchProviders))
+		for name := range SearchProviders {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, errors.Errorf("unknown search provider %q, expected one of %v", s.Provider, names)
+	}
+	provider, err := newProvider(s.Endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to create connection to %q", s.Endpoint)
+	}
+
+	results, err := provider.Search(SearchQuery{
+		Keyword: keyword,
+		Filters: s.Filters,
+		Offset:  s.Offset,
+		Limit:   s.MaxResults,
+	})
+	return results, errors.Wrapf(err, "unable to perform search against %q", s.Endpoint)
+}
+
+// artifactHubProvider searches a catalog implementing the Monocular search
+// API. The API neither filters nor paginates, so both are done on the client.
+type artifactHubProvider struct {
+	endpoint string
+	client   *monocular.Client
+}
+
+// NewArtifactHubProvider creates a SearchProvider for Artifact Hub or another
+// catalog implementing the Monocular search API. It supports the "repo"
+// filter.
+func NewArtifactHubProvider(endpoint string) (SearchProvider, error) {
+	client, err := monocular.New(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &artifactHubProvider{endpoint: endpoint, client: client}, nil
+}
+
+func (p *artifactHubProvider) Search(query SearchQuery) (*SearchResults, error) {
+	for name := range query.Filters {
+		if name != "repo" {
+			return nil, errors.Errorf("unsupported filter %q, the Artifact Hub provider supports: repo", name)
+		}
+	}
+	found, err := p.client.Search(query.Keyword)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []SearchResult
+	for _, r := range found {
+		if repo, ok := query.Filters["repo"]; ok && r.Attributes.Repo.Name != repo {
+			continue
+		}
+		// Backwards compatibility for Monocular
+		url := p.endpoint + "/charts/" + r.ID
+		if r.ArtifactHub.PackageURL != "" {
+			url = r.ArtifactHub.PackageURL
+		}
+		results = append(results, SearchResult{
+			Name:        r.Attributes.Name,
+			URL:         url,
+			Version:     r.Relationships.LatestChartVersion.Data.Version,
+			AppVersion:  r.Relationships.LatestChartVersion.Data.AppVersion,
+			Description: r.Attributes.Description,
+			Repository:  SearchRepository{Name: r.Attributes.Repo.Name, URL: r.Attributes.Repo.URL},
+		})
+	}
+	return &SearchResults{Results: paginateSearchResults(results, query.Offset, query.Limit), Total: len(results)}, nil
+}
+
+// paginateSearchResults returns the results of a page.
+func paginateSearchResults(results []SearchResult, offset, limit int) []SearchResult {
+	if offset >= len(results) {
+		return nil
+	}
+	results = results[offset:]
+	if limit > 0 && limit < len(results) {
+		results = results[:limit]
+	}
+	return results
+}
@@ -0,0 +1,96 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchHubArtifactHub(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "maria", r.URL.Query().Get("q"))
+		fmt.Fprint(w, `{"data":[
+			{"id":"stable/mariadb","attributes":{"name":"mariadb","repo":{"name":"stable","url":"https://charts.helm.sh/stable"}}},
+			{"id":"bitnami/mariadb","artifactHub":{"packageUrl":"https://artifacthub.io/packages/helm/bitnami/mariadb"},"attributes":{"name":"mariadb","repo":{"name":"bitnami","url":"https://charts.bitnami.com"}},"relationships":{"latestChartVersion":{"data":{"version":"20.0.0","app_version":"11.4.3"}}}},
+			{"id":"bitnami/mariadb-galera","attributes":{"name":"mariadb-galera","repo":{"name":"bitnami","url":"https://charts.bitnami.com"}}}
+		]}`)
+	}))
+	defer ts.Close()
+
+	client := NewSearchHub()
+	client.Endpoint = ts.URL
+	results, err := client.Run("maria")
+	require.NoError(t, err)
+	assert.Equal(t, 3, results.Total)
+	require.Len(t, results.Results, 3)
+	assert.Equal(t, ts.URL+"/charts/stable/mariadb", results.Results[0].URL)
+	assert.Equal(t, SearchResult{
+		Name:       "mariadb",
+		URL:        "https://artifacthub.io/packages/helm/bitnami/mariadb",
+		Version:    "20.0.0",
+		AppVersion: "11.4.3",
+		Repository: SearchRepository{Name: "bitnami", URL: "https://charts.bitnami.com"},
+	}, results.Results[1])
+
+	client.Filters = map[string]string{"repo": "bitnami"}
+	client.Offset = 1
+	client.MaxResults = 1
+	results, err = client.Run("maria")
+	require.NoError(t, err)
+	assert.Equal(t, 2, results.Total)
+	require.Len(t, results.Results, 1)
+	assert.Equal(t, "mariadb-galera", results.Results[0].Name)
+
+	client.Filters = map[string]string{"category": "database"}
+	_, err = client.Run("maria")
+	assert.ErrorContains(t, err, `unsupported filter "category"`)
+}
+
+type staticSearchProvider []SearchResult
+
+func (p staticSearchProvider) Search(query SearchQuery) (*SearchResults, error) {
+	return &SearchResults{Results: paginateSearchResults(p, query.Offset, query.Limit), Total: len(p)}, nil
+}
+
+func TestSearchHubProviders(t *testing.T) {
+	SearchProviders["internal"] = func(_ string) (SearchProvider, error) {
+		return staticSearchProvider{{Name: "web"}, {Name: "db"}}, nil
+	}
+	defer delete(SearchProviders, "internal")
+
+	client := NewSearchHub()
+	client.Provider = "internal"
+	client.MaxResults = 1
+	results, err := client.Run("")
+	require.NoError(t, err)
+	assert.Equal(t, &SearchResults{Results: []SearchResult{{Name: "web"}}, Total: 2}, results)
+
+	client.Provider = "missing"
+	_, err = client.Run("")
+	assert.EqualError(t, err, `unknown search provider "missing", expected one of [artifacthub internal]`)
+
+	client.Provider = ArtifactHubProviderName
+	client.Endpoint = "/no/host"
+	_, err = client.Run("")
+	assert.ErrorContains(t, err, `unable to create connection to "/no/host"`)
+}
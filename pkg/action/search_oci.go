@@ -0,0 +1,59 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/pkg/registry"
+)
+
+// SearchOCI is the action for listing the versions of a chart in an OCI
+// registry.
+//
+// It provides the implementation of 'helm search oci'.
+type SearchOCI struct {
+	cfg *Configuration
+
+	// Version is a semver constraint the versions must satisfy.
+	Version string
+	// Devel includes the prerelease versions when Version is empty.
+	Devel bool
+	// Offset is the number of versions to skip.
+	Offset int
+	// MaxResults is the maximum number of versions to return, zero for all.
+	MaxResults int
+}
+
+// NewSearchOCI creates a new SearchOCI object with the given configuration.
+func NewSearchOCI(cfg *Configuration) *SearchOCI {
+	return &SearchOCI{cfg: cfg}
+}
+
+// Run lists the versions of the chart at ref, e.g.
+// "oci://registry.example.com/charts/mychart", from the latest to the oldest.
+func (s *SearchOCI) Run(ref string) (*registry.VersionsResult, error) {
+	if !registry.IsOCI(ref) {
+		return nil, errors.Errorf("%s is not an OCI reference, expected oci://", ref)
+	}
+	return s.cfg.RegistryClient.Versions(ref, registry.VersionsOptions{
+		Constraint: s.Version,
+		Devel:      s.Devel,
+		Offset:     s.Offset,
+		Limit:      s.MaxResults,
+	})
+}
@@ -19,6 +19,7 @@ package action
 import (
 	"bytes"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -109,11 +110,21 @@ func (s *Show) Run(chartpath string) (string, error) {
 			}
 			printer.Execute(&out, s.chart.Values)
 		} else {
+			var valuesDir []*chart.File
 			for _, f := range s.chart.Raw {
 				if f.Name == chartutil.ValuesfileName {
 					fmt.Fprintln(&out, string(f.Data))
+				} else if loader.IsValuesDirFile(f.Name) {
+					valuesDir = append(valuesDir, f)
 				}
 			}
+			// The files of values.d follow values.yaml, in the order they
+			// are merged.
+			sort.Slice(valuesDir, func(i, j int) bool { return valuesDir[i].Name < valuesDir[j].Name })
+			for _, f := range valuesDir {
+				fmt.Fprintf(&out, "---\n# Source: %s\n", f.Name)
+				fmt.Fprintln(&out, string(f.Data))
+			}
 		}
 	}
 
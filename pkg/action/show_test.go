@@ -96,6 +96,29 @@ func TestShowValuesByJsonPathFormat(t *testing.T) {
 	}
 }
 
+func TestShowValuesDir(t *testing.T) {
+	config := actionConfigFixture(t)
+	client := NewShow(ShowValues, config)
+	client.chart = &chart.Chart{
+		Metadata: &chart.Metadata{Name: "alpine"},
+		Values:   map[string]interface{}{"replicas": 1},
+		Raw: []*chart.File{
+			{Name: "values.d/20-ingress.yaml", Data: []byte("ingress: {}\n")},
+			{Name: "values.yaml", Data: []byte("replicas: 1\n")},
+			{Name: "values.d/10-image.yaml", Data: []byte("image: {}\n")},
+		},
+	}
+
+	output, err := client.Run("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "replicas: 1\n\n---\n# Source: values.d/10-image.yaml\nimage: {}\n\n---\n# Source: values.d/20-ingress.yaml\ningress: {}\n\n"
+	if output != expect {
+		t.Errorf("Expected\n%q\nGot\n%q\n", expect, output)
+	}
+}
+
 func TestShowCRDs(t *testing.T) {
 	config := actionConfigFixture(t)
 	client := NewShow(ShowCRDs, config)
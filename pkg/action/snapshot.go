@@ -0,0 +1,157 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/pkg/chart/loader"
+	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/unittest"
+)
+
+// DefaultSnapshotName is the name of the snapshot used when none is given.
+const DefaultSnapshotName = "default"
+
+// Snapshot is the action for comparing the rendered manifests of a chart with
+// a snapshot stored with the chart.
+//
+// It provides the implementation of 'helm snapshot'. It can be used in Go tests
+// as well:
+//
+//	result, err := action.NewSnapshot().Run("./mychart", vals)
+//	if err == nil && !result.Matched {
+//		t.Error(result.Diff)
+//	}
+type Snapshot struct {
+	// Name is the name of the snapshot, which is stored in
+	// tests/__snapshot__/<name>.yaml.
+	Name string
+	// Update writes the rendered manifests to the snapshot instead of
+	// comparing them.
+	Update      bool
+	ReleaseName string
+	Namespace   string
+	KubeVersion *chartutil.KubeVersion
+	// Rules normalize the volatile parts of the manifests, in addition to
+	// unittest.DefaultNormalizeRules.
+	Rules []unittest.NormalizeRule
+}
+
+// SnapshotResult is the result of a Snapshot.
+type SnapshotResult struct {
+	// Path is the path of the snapshot file.
+	Path string `json:"path"`
+	// Matched reports that the manifests match the snapshot.
+	Matched bool `json:"matched"`
+	// Updated reports that the snapshot was written.
+	Updated bool `json:"updated,omitempty"`
+	// Diff is the unified diff between the snapshot and the manifests.
+	Diff string `json:"diff,omitempty"`
+}
+
+// NewSnapshot creates a new Snapshot object.
+func NewSnapshot() *Snapshot {
+	return &Snapshot{
+		Name:        DefaultSnapshotName,
+		ReleaseName: "release-name",
+		Namespace:   "default",
+	}
+}
+
+// Run renders the chart in the directory with the values like 'helm template'
+// and compares the normalized manifests with the snapshot. A missing snapshot
+// does not match.
+func (s *Snapshot) Run(chartPath string, vals map[string]interface{}) (*SnapshotResult, error) {
+	if fi, err := os.Stat(chartPath); err != nil || !fi.IsDir() {
+		return nil, errors.Errorf("snapshots are stored with the chart, %q is not a chart directory", chartPath)
+	}
+	if s.Name == "" || strings.ContainsAny(s.Name, `/\`) {
+		return nil, errors.Errorf("invalid snapshot name %q", s.Name)
+	}
+
+	manifests, err := s.render(chartPath, vals)
+	if err != nil {
+		return nil, err
+	}
+
+	rel := path.Join(unittest.SnapshotDir, s.Name+".yaml")
+	filename := filepath.Join(chartPath, filepath.FromSlash(rel))
+	result := &SnapshotResult{Path: rel}
+	if s.Update {
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(filename, []byte(manifests), 0644); err != nil {
+			return nil, errors.Wrapf(err, "failed to write snapshot %s", rel)
+		}
+		result.Matched, result.Updated = true, true
+		return result, nil
+	}
+
+	snapshot, err := os.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "failed to read snapshot %s", rel)
+	}
+	result.Diff, err = unittest.DiffSnapshot(rel, s.normalize(string(snapshot)), manifests)
+	if err != nil {
+		return nil, err
+	}
+	result.Matched = result.Diff == ""
+	return result, nil
+}
+
+// render renders the chart client-only like 'helm template', including the
+// CRDs and the hooks, and normalizes the manifests.
+func (s *Snapshot) render(chartPath string, vals map[string]interface{}) (string, error) {
+	chrt, err := loader.Load(chartPath)
+	if err != nil {
+		return "", err
+	}
+
+	cfg := &Configuration{Log: func(string, ...interface{}) {}}
+	client := NewInstall(cfg)
+	client.DryRun = true
+	client.DryRunOption = "client"
+	client.ClientOnly = true
+	client.Replace = true
+	client.IncludeCRDs = true
+	client.ReleaseName = s.ReleaseName
+	client.Namespace = s.Namespace
+	client.KubeVersion = s.KubeVersion
+	rel, err := client.Run(chrt, vals)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to render the chart")
+	}
+
+	var b strings.Builder
+	fmt.Fprintln(&b, strings.TrimSpace(rel.Manifest))
+	for _, h := range rel.Hooks {
+		fmt.Fprintf(&b, "---\n# Source: %s\n%s\n", h.Path, h.Manifest)
+	}
+	return s.normalize(b.String()), nil
+}
+
+func (s *Snapshot) normalize(manifests string) string {
+	return unittest.Normalize(unittest.Normalize(manifests, unittest.DefaultNormalizeRules), s.Rules)
+}
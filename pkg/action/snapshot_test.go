@@ -0,0 +1,78 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/unittest"
+)
+
+func TestSnapshot(t *testing.T) {
+	c := buildChart(withName("snapshot"))
+	c.Templates = []*chart.File{{Name: "templates/secret.yaml", Data: []byte(`apiVersion: v1
+kind: Secret
+metadata:
+  name: {{ .Release.Name }}
+  annotations:
+    created: {{ now | quote }}
+stringData:
+  token: {{ randAlphaNum 8 | quote }}
+  greeting: {{ .Values.greeting | default "hello" }}
+`)}}
+	dir := t.TempDir()
+	require.NoError(t, chartutil.SaveDir(c, dir))
+	chartPath := filepath.Join(dir, "snapshot")
+
+	client := NewSnapshot()
+	client.Rules = []unittest.NormalizeRule{unittest.NormalizeKey("token")}
+
+	result, err := client.Run(chartPath, nil)
+	require.NoError(t, err)
+	assert.False(t, result.Matched, "expected a missing snapshot not to match")
+	assert.Contains(t, result.Diff, "+  token: <normalized>")
+
+	client.Update = true
+	result, err = client.Run(chartPath, nil)
+	require.NoError(t, err)
+	assert.True(t, result.Updated)
+	assert.Equal(t, "tests/__snapshot__/default.yaml", result.Path)
+	data, err := os.ReadFile(filepath.Join(chartPath, "tests", "__snapshot__", "default.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `created: "<timestamp>"`)
+
+	client.Update = false
+	result, err = client.Run(chartPath, nil)
+	require.NoError(t, err)
+	assert.True(t, result.Matched, result.Diff)
+
+	result, err = client.Run(chartPath, map[string]interface{}{"greeting": "hi"})
+	require.NoError(t, err)
+	assert.False(t, result.Matched)
+	assert.Contains(t, result.Diff, "-  greeting: hello\n+  greeting: hi\n")
+
+	client.Name = "../escape"
+	_, err = client.Run(chartPath, nil)
+	assert.ErrorContains(t, err, "invalid snapshot name")
+}
@@ -21,6 +21,7 @@ import (
 	"errors"
 
 	"helm.sh/helm/v4/pkg/kube"
+	"helm.sh/helm/v4/pkg/redact"
 	"helm.sh/helm/v4/pkg/release"
 )
 
@@ -35,6 +36,9 @@ type Status struct {
 	// ShowResourcesTable is used with ShowResources. When true this will cause
 	// the resulting objects to be retrieved as a kind=table.
 	ShowResourcesTable bool
+
+	// Redactors are applied to the manifest and hooks of the returned release.
+	Redactors []redact.Redactor
 }
 
 // NewStatus creates a new Status object with the given configuration.
@@ -56,27 +60,31 @@ func (s *Status) Run(name string) (*release.Release, error) {
 	}
 
 	if kubeClient, ok := s.cfg.KubeClient.(kube.InterfaceResources); ok {
-		var resources kube.ResourceList
+		resources, err := s.cfg.KubeClient.Build(bytes.NewBufferString(rel.Manifest), false)
+		if err != nil {
+			return nil, err
+		}
+		shown := resources
 		if s.ShowResourcesTable {
-			resources, err = kubeClient.BuildTable(bytes.NewBufferString(rel.Manifest), false)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			resources, err = s.cfg.KubeClient.Build(bytes.NewBufferString(rel.Manifest), false)
+			shown, err = kubeClient.BuildTable(bytes.NewBufferString(rel.Manifest), false)
 			if err != nil {
 				return nil, err
 			}
 		}
 
-		resp, err := kubeClient.Get(resources, true)
+		resp, err := kubeClient.Get(shown, true)
 		if err != nil {
 			return nil, err
 		}
 
 		rel.Info.Resources = resp
 
-		return rel, nil
+		rel.Info.Outputs, err = statusOutputs(kubeClient, resources)
+		if err != nil {
+			return nil, err
+		}
+
+		return redactRelease(rel, s.Redactors)
 	}
 	return nil, errors.New("unable to get kubeClient with interface InterfaceResources")
 }
@@ -0,0 +1,106 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/util/jsonpath"
+
+	"helm.sh/helm/v4/pkg/annotations"
+	"helm.sh/helm/v4/pkg/kube"
+	"helm.sh/helm/v4/pkg/release"
+)
+
+// statusOutputs evaluates the status fields of the resources annotated with
+// annotations.StatusField against their live objects, in the order of the
+// manifest.
+func statusOutputs(kubeClient kube.InterfaceResources, resources kube.ResourceList) ([]*release.Output, error) {
+	annotated := resources.Filter(func(info *resource.Info) bool {
+		annos, err := accessor.Annotations(info.Object)
+		return err == nil && annos[annotations.StatusField] != ""
+	})
+	if len(annotated) == 0 {
+		return nil, nil
+	}
+
+	var outputs []*release.Output
+	for _, info := range annotated {
+		annos, _ := accessor.Annotations(info.Object)
+		out := &release.Output{
+			Resource:   fmt.Sprintf("%s/%s", info.Mapping.GroupVersionKind.Kind, info.Name),
+			Namespace:  info.Namespace,
+			Expression: annos[annotations.StatusField],
+		}
+		// The live objects are keyed by version and kind only, so they are
+		// fetched one resource at a time to keep the kinds of different API
+		// groups apart.
+		live, err := kubeClient.Get(kube.ResourceList{info}, false)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to get %s", out.Resource)
+		}
+		obj := findLiveObject(live, info)
+		if obj == nil {
+			out.Error = "resource not found"
+		} else if out.Value, err = evalStatusField(obj, out.Expression); err != nil {
+			out.Error = err.Error()
+		}
+		outputs = append(outputs, out)
+	}
+	return outputs, nil
+}
+
+func findLiveObject(live map[string][]runtime.Object, info *resource.Info) runtime.Object {
+	for _, objs := range live {
+		for _, obj := range objs {
+			name, _ := accessor.Name(obj)
+			namespace, _ := accessor.Namespace(obj)
+			if name == info.Name && namespace == info.Namespace {
+				return obj
+			}
+		}
+	}
+	return nil
+}
+
+// evalStatusField evaluates a status field expression against an object. The
+// expression is either a JSONPath, e.g. ".status.loadBalancer.ingress[0].ip",
+// or a JSONPath template as used by 'kubectl get -o jsonpath', e.g.
+// "http://{.spec.rules[0].host}/". Missing fields evaluate to an empty value.
+func evalStatusField(obj runtime.Object, expr string) (string, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return "", err
+	}
+	if !strings.Contains(expr, "{") {
+		expr = "{" + expr + "}"
+	}
+	jp := jsonpath.New(annotations.StatusField).AllowMissingKeys(true)
+	if err := jp.Parse(expr); err != nil {
+		return "", errors.Wrapf(err, "invalid %s annotation", annotations.StatusField)
+	}
+	var buf bytes.Buffer
+	if err := jp.Execute(&buf, content); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
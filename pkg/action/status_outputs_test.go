@@ -0,0 +1,134 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	"helm.sh/helm/v4/pkg/release"
+)
+
+// liveKubeClient returns the live objects of the resources keyed by version
+// and kind, like kube.Client.
+type liveKubeClient struct {
+	*kubefake.FailingKubeClient
+	live map[schema.GroupVersionKind][]runtime.Object
+}
+
+func (c *liveKubeClient) Get(resources kube.ResourceList, _ bool) (map[string][]runtime.Object, error) {
+	objs := map[string][]runtime.Object{}
+	for _, info := range resources {
+		gvk := info.Mapping.GroupVersionKind
+		for _, obj := range c.live[gvk] {
+			if name, _ := accessor.Name(obj); name == info.Name {
+				objs[gvk.Version+"/"+gvk.Kind] = append(objs[gvk.Version+"/"+gvk.Kind], obj)
+			}
+		}
+	}
+	return objs, nil
+}
+
+func statusFieldResource(kind, name, expr string) *resource.Info {
+	return statusFieldGroupResource(schema.GroupVersionKind{Version: "v1", Kind: kind}, name, expr)
+}
+
+func statusFieldGroupResource(gvk schema.GroupVersionKind, name, expr string) *resource.Info {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetName(name)
+	obj.SetNamespace("shop")
+	if expr != "" {
+		obj.SetAnnotations(map[string]string{"helm.sh/status-field": expr})
+	}
+	return &resource.Info{
+		Name:      name,
+		Namespace: "shop",
+		Mapping:   &meta.RESTMapping{GroupVersionKind: gvk},
+		Object:    obj,
+	}
+}
+
+func TestStatusOutputs(t *testing.T) {
+	web := statusFieldResource("Service", "web", ".status.loadBalancer.ingress[0].ip")
+	web.Object.(*unstructured.Unstructured).Object["status"] = map[string]interface{}{
+		"loadBalancer": map[string]interface{}{
+			"ingress": []interface{}{map[string]interface{}{"ip": "203.0.113.7"}},
+		},
+	}
+	api := statusFieldResource("Service", "api", "http://{.metadata.name}:{.spec.ports[0].port}/")
+	api.Object.(*unstructured.Unstructured).Object["spec"] = map[string]interface{}{
+		"ports": []interface{}{map[string]interface{}{"port": int64(8080)}},
+	}
+	pending := statusFieldResource("Service", "pending", ".status.loadBalancer.ingress[0].ip")
+	invalid := statusFieldResource("ConfigMap", "invalid", "{.data")
+	missing := statusFieldResource("Service", "missing", ".spec.clusterIP")
+	plain := statusFieldResource("ConfigMap", "plain", "")
+
+	kubeClient := &liveKubeClient{
+		FailingKubeClient: &kubefake.FailingKubeClient{},
+		live: map[schema.GroupVersionKind][]runtime.Object{
+			web.Mapping.GroupVersionKind:     {web.Object, api.Object, pending.Object},
+			invalid.Mapping.GroupVersionKind: {invalid.Object, plain.Object},
+		},
+	}
+
+	outputs, err := statusOutputs(kubeClient, kube.ResourceList{web, plain, api, pending, invalid, missing})
+	require.NoError(t, err)
+	require.Len(t, outputs, 5)
+	assert.Equal(t, &release.Output{Resource: "Service/web", Namespace: "shop", Expression: ".status.loadBalancer.ingress[0].ip", Value: "203.0.113.7"}, outputs[0])
+	assert.Equal(t, "http://api:8080/", outputs[1].Value)
+	assert.Equal(t, "", outputs[2].Value)
+	assert.Empty(t, outputs[2].Error)
+	assert.Contains(t, outputs[3].Error, "invalid helm.sh/status-field annotation")
+	assert.Equal(t, "resource not found", outputs[4].Error)
+
+	outputs, err = statusOutputs(kubeClient, kube.ResourceList{plain})
+	require.NoError(t, err)
+	assert.Nil(t, outputs)
+}
+
+func TestStatusOutputsAPIGroups(t *testing.T) {
+	deployment := statusFieldGroupResource(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, "web", ".metadata.annotations.group")
+	custom := statusFieldGroupResource(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Deployment"}, "web", ".metadata.annotations.group")
+	for info, group := range map[*resource.Info]string{deployment: "apps", custom: "example.com"} {
+		obj := info.Object.(*unstructured.Unstructured)
+		obj.SetAnnotations(map[string]string{"helm.sh/status-field": ".metadata.annotations.group", "group": group})
+	}
+
+	kubeClient := &liveKubeClient{
+		FailingKubeClient: &kubefake.FailingKubeClient{},
+		live: map[schema.GroupVersionKind][]runtime.Object{
+			deployment.Mapping.GroupVersionKind: {deployment.Object},
+			custom.Mapping.GroupVersionKind:     {custom.Object},
+		},
+	}
+	outputs, err := statusOutputs(kubeClient, kube.ResourceList{deployment, custom})
+	require.NoError(t, err)
+	require.Len(t, outputs, 2)
+	assert.Equal(t, "apps", outputs[0].Value)
+	assert.Equal(t, "example.com", outputs[1].Value)
+}
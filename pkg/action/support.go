@@ -0,0 +1,99 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"helm.sh/helm/v4/pkg/chart"
+)
+
+// SupportWarning warns that a chart, or one of its dependencies, is deprecated
+// or past the end of its support.
+type SupportWarning struct {
+	// Chart is the path of the chart, e.g. "mychart.subchart".
+	Chart   string `json:"chart"`
+	Version string `json:"version"`
+	// Deprecated is set when the chart is marked deprecated in Chart.yaml.
+	Deprecated bool `json:"deprecated,omitempty"`
+	// SupportedUntil is the last day the chart is supported, as YYYY-MM-DD.
+	SupportedUntil string `json:"supportedUntil,omitempty"`
+	// EndOfLife is set when SupportedUntil has passed.
+	EndOfLife bool `json:"endOfLife,omitempty"`
+}
+
+func (w *SupportWarning) String() string {
+	switch {
+	case w.Deprecated && w.EndOfLife:
+		return fmt.Sprintf("chart %s %s is deprecated and has not been supported since %s", w.Chart, w.Version, w.SupportedUntil)
+	case w.EndOfLife:
+		return fmt.Sprintf("chart %s %s has not been supported since %s", w.Chart, w.Version, w.SupportedUntil)
+	case w.SupportedUntil != "":
+		return fmt.Sprintf("chart %s %s is deprecated and is supported until %s", w.Chart, w.Version, w.SupportedUntil)
+	}
+	return fmt.Sprintf("chart %s %s is deprecated", w.Chart, w.Version)
+}
+
+// SupportWarnings returns the warnings of the chart and its dependencies that
+// are deprecated or past the end of their support at now.
+func SupportWarnings(ch *chart.Chart, now time.Time) []*SupportWarning {
+	var warnings []*SupportWarning
+	if md := ch.Metadata; md != nil {
+		if eol := md.EndOfLife(now); md.Deprecated || eol {
+			warnings = append(warnings, &SupportWarning{
+				Chart:          ch.ChartPath(),
+				Version:        md.Version,
+				Deprecated:     md.Deprecated,
+				SupportedUntil: md.SupportedUntil,
+				EndOfLife:      eol,
+			})
+		}
+	}
+	for _, dep := range ch.Dependencies() {
+		warnings = append(warnings, SupportWarnings(dep, now)...)
+	}
+	return warnings
+}
+
+// UnsupportedChartError is returned by the actions refusing to deploy
+// deprecated charts.
+type UnsupportedChartError struct {
+	Warnings []*SupportWarning
+}
+
+func (e *UnsupportedChartError) Error() string {
+	msgs := make([]string, len(e.Warnings))
+	for i, w := range e.Warnings {
+		msgs[i] = w.String()
+	}
+	return "refusing to deploy unsupported charts: " + strings.Join(msgs, "; ")
+}
+
+// checkSupport logs the support warnings of a chart about to be deployed, and
+// fails when failOnDeprecated is set and there are any.
+func (cfg *Configuration) checkSupport(ch *chart.Chart, failOnDeprecated bool) ([]*SupportWarning, error) {
+	warnings := SupportWarnings(ch, time.Now())
+	for _, w := range warnings {
+		cfg.Log("warning: %s", w)
+	}
+	if failOnDeprecated && len(warnings) > 0 {
+		return warnings, &UnsupportedChartError{Warnings: warnings}
+	}
+	return warnings, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withSupport(deprecated bool, supportedUntil string) chartOption {
+	return func(opts *chartOptions) {
+		opts.Metadata.Deprecated = deprecated
+		opts.Metadata.SupportedUntil = supportedUntil
+	}
+}
+
+func TestSupportWarnings(t *testing.T) {
+	now := time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC)
+	ch := buildChart(
+		withSupport(false, "2031-01-01"),
+		withDependency(withName("old"), withSupport(false, "2030-01-01")),
+		withDependency(withName("dead"), withSupport(true, "")),
+	)
+
+	warnings := SupportWarnings(ch, now)
+	require.Len(t, warnings, 2)
+	assert.Equal(t, &SupportWarning{Chart: "hello.old", Version: "0.1.0", SupportedUntil: "2030-01-01", EndOfLife: true}, warnings[0])
+	assert.Equal(t, "chart hello.old 0.1.0 has not been supported since 2030-01-01", warnings[0].String())
+	assert.Equal(t, "chart hello.dead 0.1.0 is deprecated", warnings[1].String())
+
+	assert.Empty(t, SupportWarnings(buildChart(withSupport(false, "2031-01-01")), now))
+}
+
+func TestInstallRelease_Deprecated(t *testing.T) {
+	instAction := installAction(t)
+	_, err := instAction.Run(buildChart(withSupport(true, "")), map[string]interface{}{})
+	require.NoError(t, err)
+	require.Len(t, instAction.SupportWarnings, 1)
+	assert.True(t, instAction.SupportWarnings[0].Deprecated)
+
+	instAction = installAction(t)
+	instAction.FailOnDeprecated = true
+	_, err = instAction.Run(buildChart(withSupport(false, "2000-01-01")), map[string]interface{}{})
+	var unsupported *UnsupportedChartError
+	require.True(t, errors.As(err, &unsupported))
+	assert.EqualError(t, err, "refusing to deploy unsupported charts: chart hello 0.1.0 has not been supported since 2000-01-01")
+	_, err = instAction.cfg.Releases.History(instAction.ReleaseName)
+	assert.Error(t, err, "expected no release to be stored")
+}
@@ -0,0 +1,111 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/release"
+)
+
+// TrimHistory is the action for deleting the oldest revisions of releases
+// beyond the maximum number of revisions, e.g. of histories that grew before
+// the limit was set. The deployed and the latest revision of a release are
+// always kept.
+//
+// It provides the implementation of 'helm release trim'.
+type TrimHistory struct {
+	cfg *Configuration
+
+	// MaxHistory is the maximum number of revisions kept per release.
+	MaxHistory int
+	// DryRun only reports the revisions beyond MaxHistory without deleting
+	// them.
+	DryRun bool
+}
+
+// NewTrimHistory creates a new TrimHistory object with the given
+// configuration.
+func NewTrimHistory(cfg *Configuration) *TrimHistory {
+	return &TrimHistory{
+		cfg: cfg,
+	}
+}
+
+// Run trims the histories of the named releases, or of all the releases in
+// the namespace when no name is given. It returns the revisions beyond
+// MaxHistory, which are deleted unless DryRun is set. On failure, the
+// revisions that could be deleted are returned with the error.
+func (t *TrimHistory) Run(names ...string) ([]*release.Release, error) {
+	if err := t.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	if t.MaxHistory <= 0 {
+		return nil, errors.New("the maximum number of revisions must be greater than 0")
+	}
+
+	if len(names) == 0 {
+		var err error
+		if names, err = t.releaseNames(); err != nil {
+			return nil, err
+		}
+	}
+
+	var trimmed []*release.Release
+	var errs []error
+	for _, name := range names {
+		if err := chartutil.ValidateReleaseName(name); err != nil {
+			return nil, errors.Errorf("release name is invalid: %s", name)
+		}
+		var revs []*release.Release
+		var err error
+		if t.DryRun {
+			revs, err = t.cfg.Releases.ExcessHistory(name, t.MaxHistory)
+		} else {
+			revs, err = t.cfg.Releases.TrimHistory(name, t.MaxHistory)
+		}
+		trimmed = append(trimmed, revs...)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to trim the history of %s", name))
+		}
+	}
+	if len(errs) > 0 {
+		return trimmed, errors.Errorf("trimming completed with %d error(s): %s", len(errs), joinErrors(errs))
+	}
+	return trimmed, nil
+}
+
+// releaseNames returns the names of the releases in storage, in order.
+func (t *TrimHistory) releaseNames() ([]string, error) {
+	rels, err := t.cfg.Releases.ListMetadata(func(*release.Release) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var names []string
+	for _, rel := range rels {
+		if !seen[rel.Name] {
+			seen[rel.Name] = true
+			names = append(names, rel.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
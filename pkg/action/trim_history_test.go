@@ -0,0 +1,85 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/release"
+)
+
+func trimHistoryFixture(t *testing.T) *TrimHistory {
+	t.Helper()
+	client := NewTrimHistory(actionConfigFixture(t))
+	statuses := map[string][]release.Status{
+		"web": {release.StatusSuperseded, release.StatusDeployed, release.StatusSuperseded, release.StatusFailed, release.StatusFailed},
+		"db":  {release.StatusSuperseded, release.StatusDeployed},
+	}
+	for name, h := range statuses {
+		for i, status := range h {
+			rel := namedReleaseStub(name, status)
+			rel.Version = i + 1
+			require.NoError(t, client.cfg.Releases.Create(rel))
+		}
+	}
+	return client
+}
+
+func versions(rels []*release.Release) []int {
+	vs := make([]int, len(rels))
+	for i, r := range rels {
+		vs[i] = r.Version
+	}
+	return vs
+}
+
+func TestTrimHistory(t *testing.T) {
+	client := trimHistoryFixture(t)
+	client.MaxHistory = 2
+
+	client.DryRun = true
+	trimmed, err := client.Run()
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 3, 4}, versions(trimmed), "the deployed and the latest revisions are kept")
+	h, err := client.cfg.Releases.History("web")
+	require.NoError(t, err)
+	assert.Len(t, h, 5, "a dry run deletes nothing")
+
+	client.DryRun = false
+	trimmed, err = client.Run("web")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 3, 4}, versions(trimmed))
+	h, err = client.cfg.Releases.History("web")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{2, 5}, versions(h))
+	h, err = client.cfg.Releases.History("db")
+	require.NoError(t, err)
+	assert.Len(t, h, 2)
+}
+
+func TestTrimHistoryInvalid(t *testing.T) {
+	client := trimHistoryFixture(t)
+	_, err := client.Run("web")
+	assert.EqualError(t, err, "the maximum number of revisions must be greater than 0")
+
+	client.MaxHistory = 1
+	_, err = client.Run("missing")
+	assert.ErrorContains(t, err, "failed to trim the history of missing")
+}
@@ -24,6 +24,7 @@ import (
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"helm.sh/helm/v4/internal/version"
 	"helm.sh/helm/v4/pkg/chartutil"
 	"helm.sh/helm/v4/pkg/kube"
 	"helm.sh/helm/v4/pkg/release"
@@ -103,10 +104,16 @@ func (u *Uninstall) Run(name string) (*release.UninstallReleaseResponse, error)
 	rel.Info.Status = release.StatusUninstalling
 	rel.Info.Deleted = helmtime.Now()
 	rel.Info.Description = "Deletion in progress (or silently failed)"
+	rel.Info.Reason = release.ReasonPending
+	rel.Info.ErrorDetail = ""
+	rel.Info.OperatorMessage = u.Description
+	rel.Info.Operator = u.cfg.operator()
+	rel.Info.ClientVersion = version.GetVersion()
 	res := &release.UninstallReleaseResponse{Release: rel}
 
 	if !u.DisableHooks {
 		if err := u.cfg.execHook(rel, release.HookPreDelete, u.Timeout); err != nil {
+			u.execFailedHooks(rel, err)
 			return res, err
 		}
 	} else {
@@ -122,7 +129,9 @@ func (u *Uninstall) Run(name string) (*release.UninstallReleaseResponse, error)
 	deletedResources, kept, errs := u.deleteRelease(rel)
 	if errs != nil {
 		u.cfg.Log("uninstall: Failed to delete release: %s", errs)
-		return nil, errors.Errorf("failed to delete release: %s", name)
+		err := errors.Errorf("failed to delete release: %s", name)
+		u.execFailedHooks(rel, err)
+		return nil, err
 	}
 
 	if kept != "" {
@@ -144,12 +153,17 @@ func (u *Uninstall) Run(name string) (*release.UninstallReleaseResponse, error)
 		}
 	}
 
+	if len(errs) > 0 {
+		u.execFailedHooks(rel, errs[len(errs)-1])
+	}
+
 	rel.Info.Status = release.StatusUninstalled
 	if len(u.Description) > 0 {
 		rel.Info.Description = u.Description
 	} else {
 		rel.Info.Description = "Uninstallation complete"
 	}
+	rel.Info.Reason = release.ReasonUninstalled
 
 	if !u.KeepHistory {
 		u.cfg.Log("purge requested for %s", name)
@@ -176,6 +190,19 @@ func (u *Uninstall) Run(name string) (*release.UninstallReleaseResponse, error)
 	return res, nil
 }
 
+// execFailedHooks runs the post-uninstall-failed hooks after the uninstall
+// failed with err. The failure of these hooks is only logged, so that the
+// error of the uninstall is the one reported.
+func (u *Uninstall) execFailedHooks(rel *release.Release, err error) {
+	if u.DisableHooks {
+		return
+	}
+	u.cfg.Log("uninstall: running %s hooks after: %s", release.HookPostUninstallFailed, err)
+	if err := u.cfg.execHook(rel, release.HookPostUninstallFailed, u.Timeout); err != nil {
+		u.cfg.Log("uninstall: %s hooks failed: %s", release.HookPostUninstallFailed, err)
+	}
+}
+
 func (u *Uninstall) purgeReleases(rels ...*release.Release) error {
 	for _, rel := range rels {
 		if _, err := u.cfg.Releases.Delete(rel.Name, rel.Version); err != nil {
@@ -107,6 +107,62 @@ func TestUninstallRelease_Wait(t *testing.T) {
 	is.Equal(res.Release.Info.Status, release.StatusUninstalled)
 }
 
+func TestUninstallRelease_FailedHooks(t *testing.T) {
+	is := assert.New(t)
+
+	unAction := uninstallAction(t)
+	unAction.DryRun = false
+
+	rel := releaseStub()
+	rel.Name = "come-fail-away"
+	rel.Manifest = `{
+		"apiVersion": "v1",
+		"kind": "Secret",
+		"metadata": {
+		  "name": "secret"
+		},
+		"type": "Opaque",
+		"data": {
+		  "password": "password"
+		}
+	}`
+	cleanup := &release.Hook{
+		Name:     "cleanup",
+		Kind:     "Job",
+		Path:     "cleanup",
+		Manifest: "kind: Job",
+		Events:   []release.HookEvent{release.HookPostUninstallFailed},
+	}
+	postDelete := &release.Hook{
+		Name:     "post-delete",
+		Kind:     "Job",
+		Path:     "post-delete",
+		Manifest: "kind: Job",
+		Events:   []release.HookEvent{release.HookPostDelete},
+	}
+	rel.Hooks = []*release.Hook{cleanup, postDelete}
+	unAction.cfg.Releases.Create(rel)
+
+	// a successful uninstall does not run the hooks
+	res, err := unAction.Run(rel.Name)
+	is.NoError(err)
+	is.Equal(release.HookPhaseSucceeded, res.Release.Hooks[1].LastRun.Phase)
+	is.True(res.Release.Hooks[0].LastRun.StartedAt.IsZero())
+
+	rel = releaseStub()
+	rel.Name = "come-fail-away"
+	rel.Hooks = []*release.Hook{cleanup, postDelete}
+	unAction.cfg.Releases.Create(rel)
+	failer := unAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+	failer.WatchUntilReadyError = fmt.Errorf("job failed: BackoffLimitExceeded")
+	res, err = unAction.Run(rel.Name)
+	is.Error(err)
+	is.Contains(err.Error(), "uninstallation completed with 1 error(s)")
+	is.Equal(release.HookPostDelete, res.Release.Hooks[1].LastRun.Event)
+	is.Equal(release.HookPostUninstallFailed, res.Release.Hooks[0].LastRun.Event)
+	is.Equal(release.HookPhaseFailed, res.Release.Hooks[0].LastRun.Phase)
+}
+
 func TestUninstallRelease_Cascade(t *testing.T) {
 	is := assert.New(t)
 
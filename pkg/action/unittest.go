@@ -0,0 +1,124 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/loader"
+	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/unittest"
+)
+
+// UnitTest is the action for running the unit tests of a chart.
+//
+// It provides the implementation of 'helm unittest'.
+type UnitTest struct {
+	// KubeVersion is the Kubernetes version the templates are rendered for,
+	// unless a test suite sets one.
+	KubeVersion *chartutil.KubeVersion
+}
+
+// NewUnitTest creates a new UnitTest object.
+func NewUnitTest() *UnitTest {
+	return &UnitTest{}
+}
+
+// Run runs the test suites in the tests/ directory of a chart, given as a
+// directory or an archive.
+//
+// For a chart directory the test suites are read from disk, so that they are
+// found even when they are excluded from the packaged chart by .helmignore.
+func (u *UnitTest) Run(chartPath string) (*unittest.Result, error) {
+	load := func() (*chart.Chart, error) { return loader.Load(chartPath) }
+	c, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := unitTestFiles(chartPath, c)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range files {
+		if unittest.IsSuite(name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, errors.Errorf("chart %q has no test suites matching %s/*%s", c.Name(), unittest.SuiteDir, unittest.SuiteSuffix)
+	}
+	sort.Strings(names)
+
+	suites := make([]*unittest.Suite, 0, len(names))
+	for _, name := range names {
+		s, err := unittest.ParseSuite(name, files[name])
+		if err != nil {
+			return nil, err
+		}
+		suites = append(suites, s)
+	}
+
+	runner := &unittest.Runner{Load: load, Files: files, KubeVersion: u.KubeVersion}
+	return runner.Run(c.Name(), suites), nil
+}
+
+// unitTestFiles returns the files in the tests/ directory of a chart by their
+// path in the chart.
+func unitTestFiles(chartPath string, c *chart.Chart) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	if fi, err := os.Stat(chartPath); err != nil || !fi.IsDir() {
+		for _, f := range c.Files {
+			if strings.HasPrefix(f.Name, unittest.SuiteDir+"/") {
+				files[f.Name] = f.Data
+			}
+		}
+		return files, nil
+	}
+
+	dir := filepath.Join(chartPath, unittest.SuiteDir)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(chartPath, path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	return files, errors.Wrapf(err, "failed to read the tests of chart %q", c.Name())
+}
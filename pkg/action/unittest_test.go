@@ -0,0 +1,63 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chartutil"
+)
+
+func TestUnitTestArchive(t *testing.T) {
+	c := buildChart(withName("unittest"))
+	c.Templates = []*chart.File{{Name: "templates/configmap.yaml", Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Release.Name }}\n")}}
+	c.Files = []*chart.File{
+		{Name: "tests/configmap_test.yaml", Data: []byte(`suite: configmap
+release:
+  name: example
+tests:
+- it: names the config map after the release
+  asserts:
+  - equal: {path: metadata.name, value: example}
+- it: fails
+  asserts:
+  - isKind: {of: Secret}
+`)},
+		{Name: "tests/README.md", Data: []byte("not a suite")},
+	}
+	archive, err := chartutil.Save(c, t.TempDir())
+	require.NoError(t, err)
+
+	result, err := NewUnitTest().Run(archive)
+	require.NoError(t, err)
+	assert.Equal(t, "unittest", result.Chart)
+	require.Len(t, result.Suites, 1)
+	assert.Equal(t, "tests/configmap_test.yaml", result.Suites[0].Path)
+	passed, failed := result.Counts()
+	assert.Equal(t, 1, passed)
+	assert.Equal(t, 1, failed)
+	assert.False(t, result.Suites[0].Tests[1].Passed)
+}
+
+func TestUnitTestNoSuites(t *testing.T) {
+	_, err := NewUnitTest().Run("testdata/charts/decompressedchart")
+	assert.ErrorContains(t, err, "has no test suites")
+}
@@ -22,16 +22,17 @@ import (
 	"fmt"
 	"strings"
 	"sync"
-	"time"
 
+	"github.com/mitchellh/copystructure"
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes"
 
+	"helm.sh/helm/v4/internal/version"
 	"helm.sh/helm/v4/pkg/chart"
 	"helm.sh/helm/v4/pkg/chartutil"
 	"helm.sh/helm/v4/pkg/kube"
-	"helm.sh/helm/v4/pkg/postrender"
 	"helm.sh/helm/v4/pkg/registry"
 	"helm.sh/helm/v4/pkg/release"
 	"helm.sh/helm/v4/pkg/releaseutil"
@@ -45,6 +46,8 @@ type Upgrade struct {
 	cfg *Configuration
 
 	ChartPathOptions
+	ValuesFromOptions
+	DeployOptions
 
 	// Install is a purely informative flag that indicates whether this upgrade was done in "install" mode.
 	//
@@ -53,34 +56,8 @@ type Upgrade struct {
 	// (Upgrade.Install == true).
 	//
 	// Setting this to `true` will NOT cause `Upgrade` to perform an install if the release does not exist.
-	// That process must be handled by creating an Install action directly. See cmd/upgrade.go for an
-	// example of how this flag is used.
+	// That process is handled by the InstallOrUpgrade action, which sets this flag.
 	Install bool
-	// Devel indicates that the operation is done in devel mode.
-	Devel bool
-	// Namespace is the namespace in which this operation should be performed.
-	Namespace string
-	// SkipCRDs skips installing CRDs when install flag is enabled during upgrade
-	SkipCRDs bool
-	// Timeout is the timeout for this operation
-	Timeout time.Duration
-	// Wait determines whether the wait operation should be performed after the upgrade is requested.
-	Wait bool
-	// WaitForJobs determines whether the wait operation for the Jobs should be performed after the upgrade is requested.
-	WaitForJobs bool
-	// DisableHooks disables hook processing if set to true.
-	DisableHooks bool
-	// DryRun controls whether the operation is prepared, but not executed.
-	DryRun bool
-	// DryRunOption controls whether the operation is prepared, but not executed with options on whether or not to interact with the remote cluster.
-	DryRunOption string
-	// HideSecret can be set to true when DryRun is enabled in order to hide
-	// Kubernetes Secrets in the output. It cannot be used outside of DryRun.
-	HideSecret bool
-	// Force will, if set to `true`, ignore certain warnings and perform the upgrade anyway.
-	//
-	// This should be used with caution.
-	Force bool
 	// ResetValues will reset the values to the chart's built-ins rather than merging with existing.
 	ResetValues bool
 	// ReuseValues will reuse the user's last supplied values.
@@ -89,36 +66,26 @@ type Upgrade struct {
 	ResetThenReuseValues bool
 	// Recreate will (if true) recreate pods after a rollback.
 	Recreate bool
-	// MaxHistory limits the maximum number of revisions saved per release
-	MaxHistory int
-	// Atomic, if true, will roll back on failure.
-	Atomic bool
 	// CleanupOnFail will, if true, cause the upgrade to delete newly-created resources on a failed update.
 	CleanupOnFail bool
-	// SubNotes determines whether sub-notes are rendered in the chart.
-	SubNotes bool
-	// HideNotes determines whether notes are output during upgrade
-	HideNotes bool
-	// SkipSchemaValidation determines if JSON schema validation is disabled.
-	SkipSchemaValidation bool
-	// Description is the description of this operation
-	Description string
-	Labels      map[string]string
-	// PostRenderer is an optional post-renderer
-	//
-	// If this is non-nil, then after templates are rendered, they will be sent to the
-	// post renderer before sending to the Kubernetes API server.
-	PostRenderer postrender.PostRenderer
-	// DisableOpenAPIValidation controls whether OpenAPI validation is enforced.
-	DisableOpenAPIValidation bool
-	// Get missing dependencies
-	DependencyUpdate bool
 	// Lock to control raceconditions when the process receives a SIGTERM
 	Lock sync.Mutex
-	// Enable DNS lookups when rendering templates
-	EnableDNS bool
-	// TakeOwnership will skip the check for helm annotations and adopt all existing resources.
-	TakeOwnership bool
+	// SupportWarnings are the deprecation warnings of the chart of the last
+	// Run, see SupportWarnings.
+	SupportWarnings []*SupportWarning
+	// RestartOnConfigChange annotates the pod templates of the workloads with
+	// the checksum of the ConfigMaps and Secrets of the release that they
+	// reference, so that their pods are replaced when these change.
+	RestartOnConfigChange bool
+	// EffectiveObjects are the effective objects of the resources of the
+	// last Run, see ComputeEffective.
+	EffectiveObjects []*EffectiveObject
+
+	// hooks are the hooks being run, which an atomic upgrade stops before
+	// rolling back, and releasing is closed once the upgrade of the resources
+	// returns.
+	hooks     *hookTracker
+	releasing chan struct{}
 }
 
 type resultMessage struct {
@@ -199,11 +166,21 @@ func (u *Upgrade) prepareUpgrade(name string, chart *chart.Chart, vals map[strin
 	if chart == nil {
 		return nil, nil, errMissingChart
 	}
+	if err := chartutil.CheckHelmVersion(chart, ""); err != nil {
+		return nil, nil, err
+	}
 
 	// HideSecret must be used with dry run. Otherwise, return an error.
 	if !u.isDryRun() && u.HideSecret {
 		return nil, nil, errors.New("Hiding Kubernetes secrets requires a dry-run mode")
 	}
+	if !u.isDryRun() && len(u.Redactors) > 0 {
+		return nil, nil, errors.New("Redacting manifests requires a dry-run mode")
+	}
+	if u.ComputeEffective && u.DryRunOption != "server" {
+		return nil, nil, errEffectiveRequiresServerDryRun
+	}
+	u.EffectiveObjects = nil
 
 	// finds the last non-deleted release with the given name
 	lastRelease, err := u.cfg.Releases.Last(name)
@@ -237,13 +214,29 @@ func (u *Upgrade) prepareUpgrade(name string, chart *chart.Chart, vals map[strin
 		}
 	}
 
-	// determine if values will be reused
+	// determine if values will be reused, keeping the supplied values apart as
+	// they override the pinned values
+	v, err := copystructure.Copy(vals)
+	if err != nil {
+		return nil, nil, err
+	}
+	supplied, _ := v.(map[string]interface{})
 	vals, err = u.reuseValues(chart, currentRelease, vals)
 	if err != nil {
 		return nil, nil, err
 	}
+	// the pinned values are only merged into the values to render, so that
+	// they do not remain in the values of the release once they are unpinned
+	pinnedVals, err := withPinnedValues(vals, supplied, lastRelease.PinnedValues)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := chartutil.ProcessDependencies(chart, pinnedVals); err != nil {
+		return nil, nil, err
+	}
 
-	if err := chartutil.ProcessDependencies(chart, vals); err != nil {
+	if u.SupportWarnings, err = u.cfg.checkSupport(chart, u.FailOnDeprecated); err != nil {
 		return nil, nil, err
 	}
 
@@ -262,10 +255,6 @@ func (u *Upgrade) prepareUpgrade(name string, chart *chart.Chart, vals map[strin
 	if err != nil {
 		return nil, nil, err
 	}
-	valuesToRender, err := chartutil.ToRenderValuesWithSchemaValidation(chart, vals, options, caps, u.SkipSchemaValidation)
-	if err != nil {
-		return nil, nil, err
-	}
 
 	// Determine whether or not to interact with remote
 	var interactWithRemote bool
@@ -273,7 +262,40 @@ func (u *Upgrade) prepareUpgrade(name string, chart *chart.Chart, vals map[strin
 		interactWithRemote = true
 	}
 
-	hooks, manifestDoc, notesTxt, err := u.cfg.renderResources(chart, valuesToRender, "", "", u.SubNotes, false, false, u.PostRenderer, interactWithRemote, u.EnableDNS, u.HideSecret)
+	if interactWithRemote && !u.SkipRequirementChecks {
+		if err := u.cfg.checkRequirements(chart, nil); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var clientFn func() (kubernetes.Interface, error)
+	if interactWithRemote {
+		clientFn = u.cfg.KubernetesClientSet
+	}
+	renderVals, err := u.cfg.mergeValuesFrom(u.ValuesFromOptions, chart, currentRelease.Namespace, pinnedVals, clientFn)
+	if err != nil {
+		return nil, nil, err
+	}
+	valuesToRender, err := chartutil.ToRenderValuesWithSchemaValidation(chart, renderVals, options, caps, u.SkipSchemaValidation)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hooks, manifestDoc, notesTxt, err := u.cfg.renderResources(chart, valuesToRender, renderOptions{
+		subNotes:            u.SubNotes,
+		postRenderer:        u.PostRenderer,
+		images:              &u.ImageOverrides,
+		interactWithRemote:  interactWithRemote,
+		enableDNS:           u.EnableDNS,
+		enableClusterConfig: u.EnableClusterConfig,
+		strictness:          u.Strictness,
+		reportAllErrors:     u.ReportAllErrors,
+		format:              u.ManifestFormat,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	manifest, hooks, exclusions, err := excludeResources(&u.Exclusions, manifestDoc.String(), hooks)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -293,22 +315,39 @@ func (u *Upgrade) prepareUpgrade(name string, chart *chart.Chart, vals map[strin
 			LastDeployed:  Timestamper(),
 			Status:        release.StatusPendingUpgrade,
 			Description:   "Preparing upgrade", // This should be overwritten later.
+			Operator:      u.cfg.operator(),
+			ClientVersion: version.GetVersion(),
 		},
 		Version:  revision,
-		Manifest: manifestDoc.String(),
+		Manifest: manifest,
 		Hooks:    hooks,
 		Labels:   mergeCustomLabels(lastRelease.Labels, u.Labels),
+
+		PinnedValues: lastRelease.PinnedValues,
+		ConfigRaw:    rawValues(u.ValuesRaw, vals),
+
+		ValuesSources: u.ValuesSources,
+		Exclusions:    exclusions,
 	}
+	upgradedRelease.Info.Reason = release.ReasonPending
+	upgradedRelease.Info.OperatorMessage = u.Description
 
 	if len(notesTxt) > 0 {
 		upgradedRelease.Info.Notes = notesTxt
 	}
-	err = validateManifest(u.cfg.KubeClient, manifestDoc.Bytes(), !u.DisableOpenAPIValidation)
+	err = validateManifest(u.cfg.KubeClient, []byte(manifest), !u.DisableOpenAPIValidation)
 	return currentRelease, upgradedRelease, err
 }
 
 func (u *Upgrade) performUpgrade(ctx context.Context, originalRelease, upgradedRelease *release.Release) (*release.Release, error) {
-	current, err := u.cfg.KubeClient.Build(bytes.NewBufferString(originalRelease.Manifest), false)
+	kubeClient := u.cfg.KubeClient
+	// The excluded resources of the previous revision are left alone rather
+	// than deleted, and need not be served by the cluster anymore.
+	currentManifest, _, _, err := excludeResources(&u.Exclusions, originalRelease.Manifest, nil)
+	if err != nil {
+		return upgradedRelease, err
+	}
+	current, err := kubeClient.BuildContext(ctx, bytes.NewBufferString(currentManifest), false)
 	if err != nil {
 		// Checking for removed Kubernetes API error so can provide a more informative error message to the user
 		// Ref: https://github.com/helm/helm/issues/7219
@@ -319,7 +358,7 @@ func (u *Upgrade) performUpgrade(ctx context.Context, originalRelease, upgradedR
 		}
 		return upgradedRelease, errors.Wrap(err, "unable to build kubernetes objects from current release manifest")
 	}
-	target, err := u.cfg.KubeClient.Build(bytes.NewBufferString(upgradedRelease.Manifest), !u.DisableOpenAPIValidation)
+	target, err := kubeClient.BuildContext(ctx, bytes.NewBufferString(upgradedRelease.Manifest), !u.DisableOpenAPIValidation)
 	if err != nil {
 		return upgradedRelease, errors.Wrap(err, "unable to build kubernetes objects from new release manifest")
 	}
@@ -330,6 +369,16 @@ func (u *Upgrade) performUpgrade(ctx context.Context, originalRelease, upgradedR
 		return upgradedRelease, err
 	}
 
+	if u.RestartOnConfigChange {
+		visitor, err := configChecksumVisitor(target)
+		if err != nil {
+			return upgradedRelease, err
+		}
+		if err := target.Visit(visitor); err != nil {
+			return upgradedRelease, errors.Wrap(err, "unable to annotate workloads with the checksum of their configuration")
+		}
+	}
+
 	// Do a basic diff using gvk + name to figure out what new resources are being created so we can validate they don't already exist
 	existingResources := make(map[string]bool)
 	for _, r := range current {
@@ -361,15 +410,36 @@ func (u *Upgrade) performUpgrade(ctx context.Context, originalRelease, upgradedR
 		return nil
 	})
 
+	// Check for changes of immutable fields before anything is mutated, as the update would fail halfway otherwise
+	toBeRecreated, changes, err := immutableFieldChanges(target)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to continue with update")
+	}
+	for _, c := range changes {
+		u.cfg.Log("%s %q in namespace %q will be recreated: field %q cannot be changed from %s to %s", c.Kind, c.Name, c.Namespace, c.Field, c.Current, c.Desired)
+	}
+
+	if u.CheckPermissions {
+		if err := u.checkPermissions(ctx, upgradedRelease, current, target, toBeRecreated); err != nil {
+			return upgradedRelease, err
+		}
+	}
+
 	// Run if it is a dry run
 	if u.isDryRun() {
 		u.cfg.Log("dry run for %s", upgradedRelease.Name)
+		if u.ComputeEffective {
+			if u.EffectiveObjects, err = u.cfg.effectiveObjects(current, target, dryRunRedactors(u.HideSecret, u.Redactors)); err != nil {
+				return upgradedRelease, err
+			}
+		}
 		if len(u.Description) > 0 {
 			upgradedRelease.Info.Description = u.Description
 		} else {
 			upgradedRelease.Info.Description = "Dry run complete"
 		}
-		return upgradedRelease, nil
+		upgradedRelease.Info.Reason = release.ReasonDryRun
+		return redactRelease(upgradedRelease, dryRunRedactors(u.HideSecret, u.Redactors))
 	}
 
 	u.cfg.Log("creating upgraded release for %s", upgradedRelease.Name)
@@ -380,7 +450,17 @@ func (u *Upgrade) performUpgrade(ctx context.Context, originalRelease, upgradedR
 	ctxChan := make(chan resultMessage)
 	doneChan := make(chan interface{})
 	defer close(doneChan)
-	go u.releasingUpgrade(rChan, upgradedRelease, current, target, originalRelease)
+	u.hooks = &hookTracker{}
+	u.releasing = make(chan struct{})
+	go func() {
+		rel, created, err := u.releasingUpgrade(ctx, upgradedRelease, current, target, toBeRecreated, originalRelease)
+		close(u.releasing)
+		if ctx.Err() != nil {
+			// The upgrade has already been failed by handleContext
+			return
+		}
+		u.reportToPerformUpgrade(rChan, rel, created, err)
+	}()
 	go u.handleContext(ctx, doneChan, ctxChan, upgradedRelease)
 	select {
 	case result := <-rChan:
@@ -390,6 +470,48 @@ func (u *Upgrade) performUpgrade(ctx context.Context, originalRelease, upgradedR
 	}
 }
 
+// checkPermissions checks the permissions needed to upgrade the release, see
+// CheckPermissions.
+func (u *Upgrade) checkPermissions(ctx context.Context, rel *release.Release, current, target, toBeRecreated kube.ResourceList) error {
+	perms, err := u.requiredPermissions(rel, current, target, toBeRecreated)
+	if err != nil {
+		return err
+	}
+	return u.cfg.checkPermissions(ctx, perms)
+}
+
+// requiredPermissions returns the permissions needed to upgrade the release.
+// The current resources include the adopted ones.
+func (u *Upgrade) requiredPermissions(rel *release.Release, current, target, toBeRecreated kube.ResourceList) (permissionSet, error) {
+	perms := permissionSet{}
+	existing := map[string]bool{}
+	for _, r := range current {
+		existing[objectKey(r)] = true
+	}
+	wanted := map[string]bool{}
+	for _, r := range target {
+		wanted[objectKey(r)] = true
+		if existing[objectKey(r)] {
+			perms[resourcePermission("patch", r)] = true
+		} else {
+			perms[resourcePermission("create", r)] = true
+		}
+	}
+	for _, r := range current {
+		if !wanted[objectKey(r)] {
+			perms[resourcePermission("delete", r)] = true
+		}
+	}
+	perms.addResources(toBeRecreated, "delete", "create")
+	if !u.DisableHooks {
+		if err := u.cfg.addHookPermissions(perms, rel.Hooks); err != nil {
+			return nil, err
+		}
+	}
+	u.cfg.addStoragePermissions(perms, rel.Namespace)
+	return perms, nil
+}
+
 // Function used to lock the Mutex, this is important for the case when the atomic flag is set.
 // In that case the upgrade will finish before the rollback is finished so it is necessary to wait for the rollback to finish.
 // The rollback will be trigger by the function failRelease
@@ -414,23 +536,36 @@ func (u *Upgrade) handleContext(ctx context.Context, done chan interface{}, c ch
 		return
 	}
 }
-func (u *Upgrade) releasingUpgrade(c chan<- resultMessage, upgradedRelease *release.Release, current kube.ResourceList, target kube.ResourceList, originalRelease *release.Release) {
+
+// releasingUpgrade runs the hooks and updates the resources of the upgrade. It
+// returns the release, the resources it created and the error of the upgrade.
+// Once ctx is done, it returns at the next step.
+func (u *Upgrade) releasingUpgrade(ctx context.Context, upgradedRelease *release.Release, current kube.ResourceList, target kube.ResourceList, toBeRecreated kube.ResourceList, originalRelease *release.Release) (*release.Release, kube.ResourceList, error) {
 	// pre-upgrade hooks
 
 	if !u.DisableHooks {
-		if err := u.cfg.execHook(upgradedRelease, release.HookPreUpgrade, u.Timeout); err != nil {
-			u.reportToPerformUpgrade(c, upgradedRelease, kube.ResourceList{}, fmt.Errorf("pre-upgrade hooks failed: %s", err))
-			return
+		if err := u.cfg.execHookTracked(upgradedRelease, release.HookPreUpgrade, u.Timeout, u.hooks); err != nil {
+			return upgradedRelease, kube.ResourceList{}, withReason(release.ReasonHookFailed, fmt.Errorf("pre-upgrade hooks failed: %w", err))
 		}
 	} else {
 		u.cfg.Log("upgrade hooks disabled for %s", upgradedRelease.Name)
 	}
 
-	results, err := u.cfg.KubeClient.Update(current, target, u.Force)
+	// Delete the resources whose immutable fields change, so that the update creates them again
+	if len(toBeRecreated) > 0 {
+		if err := u.deleteForRecreate(toBeRecreated); err != nil {
+			u.cfg.recordRelease(originalRelease)
+			return upgradedRelease, kube.ResourceList{}, withReason(release.ReasonApplyFailed, err)
+		}
+	}
+
+	results, err := u.cfg.updateResources(ctx, current, target, u.Force, u.ResourceTimeout, saveConfigOptions(u.SaveConfig)...)
 	if err != nil {
+		if ctx.Err() != nil {
+			return upgradedRelease, results.Created, ctx.Err()
+		}
 		u.cfg.recordRelease(originalRelease)
-		u.reportToPerformUpgrade(c, upgradedRelease, results.Created, err)
-		return
+		return upgradedRelease, results.Created, withReason(release.ReasonApplyFailed, err)
 	}
 
 	if u.Recreate {
@@ -450,26 +585,27 @@ func (u *Upgrade) releasingUpgrade(c chan<- resultMessage, upgradedRelease *rele
 		if u.WaitForJobs {
 			if err := u.cfg.KubeClient.WaitWithJobs(target, u.Timeout); err != nil {
 				u.cfg.recordRelease(originalRelease)
-				u.reportToPerformUpgrade(c, upgradedRelease, results.Created, err)
-				return
+				return upgradedRelease, results.Created, withReason(release.ReasonWaitFailed, err)
 			}
 		} else {
 			if err := u.cfg.KubeClient.Wait(target, u.Timeout); err != nil {
 				u.cfg.recordRelease(originalRelease)
-				u.reportToPerformUpgrade(c, upgradedRelease, results.Created, err)
-				return
+				return upgradedRelease, results.Created, withReason(release.ReasonWaitFailed, err)
 			}
 		}
 	}
 
 	// post-upgrade hooks
 	if !u.DisableHooks {
-		if err := u.cfg.execHook(upgradedRelease, release.HookPostUpgrade, u.Timeout); err != nil {
-			u.reportToPerformUpgrade(c, upgradedRelease, results.Created, fmt.Errorf("post-upgrade hooks failed: %s", err))
-			return
+		if err := u.cfg.execHookTracked(upgradedRelease, release.HookPostUpgrade, u.Timeout, u.hooks); err != nil {
+			return upgradedRelease, results.Created, withReason(release.ReasonHookFailed, fmt.Errorf("post-upgrade hooks failed: %w", err))
 		}
 	}
 
+	if err := ctx.Err(); err != nil {
+		return upgradedRelease, results.Created, err
+	}
+
 	originalRelease.Info.Status = release.StatusSuperseded
 	u.cfg.recordRelease(originalRelease)
 
@@ -479,15 +615,41 @@ func (u *Upgrade) releasingUpgrade(c chan<- resultMessage, upgradedRelease *rele
 	} else {
 		upgradedRelease.Info.Description = "Upgrade complete"
 	}
-	u.reportToPerformUpgrade(c, upgradedRelease, nil, nil)
+	upgradedRelease.Info.Reason = release.ReasonUpgraded
+	return upgradedRelease, nil, nil
+}
+
+// deleteForRecreate deletes the resources and waits until they are gone.
+func (u *Upgrade) deleteForRecreate(resources kube.ResourceList) error {
+	// Work on copies, waiting for the deletion refreshes the objects from the cluster.
+	var deleted kube.ResourceList
+	for _, r := range resources {
+		cp := *r
+		cp.Object = r.Object.DeepCopyObject()
+		deleted.Append(&cp)
+	}
+
+	u.cfg.Log("deleting %d resource(s) to recreate them", len(deleted))
+	if _, errs := u.cfg.KubeClient.Delete(deleted); errs != nil {
+		var errorList []string
+		for _, e := range errs {
+			errorList = append(errorList, e.Error())
+		}
+		return errors.Errorf("unable to delete resources to recreate them: %s", strings.Join(errorList, ", "))
+	}
+	if kubeClient, ok := u.cfg.KubeClient.(kube.InterfaceExt); ok {
+		if err := kubeClient.WaitForDelete(deleted, u.Timeout); err != nil {
+			return errors.Wrap(err, "resources to recreate were not deleted")
+		}
+	}
+	return nil
 }
 
 func (u *Upgrade) failRelease(rel *release.Release, created kube.ResourceList, err error) (*release.Release, error) {
 	msg := fmt.Sprintf("Upgrade %q failed: %s", rel.Name, err)
 	u.cfg.Log("warning: %s", msg)
 
-	rel.Info.Status = release.StatusFailed
-	rel.Info.Description = msg
+	rel.SetFailed(failureReason(err), err, msg)
 	u.cfg.recordRelease(rel)
 	if u.CleanupOnFail && len(created) > 0 {
 		u.cfg.Log("Cleanup on fail set, cleaning up %d resources", len(created))
@@ -524,6 +686,13 @@ func (u *Upgrade) failRelease(rel *release.Release, created kube.ResourceList, e
 
 		releaseutil.Reverse(filteredHistory, releaseutil.SortByRevision)
 
+		// An interrupted upgrade may still be running a hook, whose Job
+		// would conflict with the rollback.
+		stopped, drainErr := u.drainHooks()
+		if drainErr != nil {
+			return rel, fmt.Errorf("unable to stop the running hooks %s, the release was not rolled back. original upgrade error: %w: %w", strings.Join(stopped, ", "), err, drainErr)
+		}
+
 		rollin := NewRollback(u.cfg)
 		rollin.Version = filteredHistory[0].Version
 		rollin.Wait = true
@@ -532,15 +701,51 @@ func (u *Upgrade) failRelease(rel *release.Release, created kube.ResourceList, e
 		rollin.Recreate = u.Recreate
 		rollin.Force = u.Force
 		rollin.Timeout = u.Timeout
+		rollin.MaxHistory = u.MaxHistory
+		rollin.SaveConfig = u.SaveConfig
 		if rollErr := rollin.Run(rel.Name); rollErr != nil {
 			return rel, errors.Wrapf(rollErr, "an error occurred while rolling back the release. original upgrade error: %s", err)
 		}
+		if len(stopped) > 0 {
+			return rel, errors.Wrapf(err, "release %s failed, the running hooks %s were stopped and the release has been rolled back due to atomic being set", rel.Name, strings.Join(stopped, ", "))
+		}
 		return rel, errors.Wrapf(err, "release %s failed, and has been rolled back due to atomic being set", rel.Name)
 	}
 
 	return rel, err
 }
 
+// drainHooks stops the upgrade of the resources, if it is running, and waits
+// until it returns. The hooks can no longer start, and the resources of the
+// running ones are deleted with the foreground propagation, so that their pods
+// are deleted first, and the upgrade stops once they are gone. It returns the
+// paths of the stopped hooks.
+func (u *Upgrade) drainHooks() ([]string, error) {
+	names, resources := u.hooks.stop()
+	if len(resources) > 0 {
+		u.cfg.Log("stopping the running hooks %s before rolling back", strings.Join(names, ", "))
+
+		var errs []error
+		if kubeClient, ok := u.cfg.KubeClient.(kube.InterfaceDeletionPropagation); ok {
+			_, errs = kubeClient.DeleteWithPropagationPolicy(resources, metav1.DeletePropagationForeground)
+		} else {
+			_, errs = u.cfg.KubeClient.Delete(resources)
+		}
+		if len(errs) > 0 {
+			return names, errors.Errorf("unable to delete the hook resources: %s", joinErrors(errs))
+		}
+		if kubeClient, ok := u.cfg.KubeClient.(kube.InterfaceExt); ok {
+			if err := kubeClient.WaitForDelete(resources, u.Timeout); err != nil {
+				return names, errors.Wrap(err, "the hook resources were not deleted")
+			}
+		}
+	}
+	if u.releasing != nil {
+		<-u.releasing
+	}
+	return names, nil
+}
+
 // reuseValues copies values from the current release to a new release if the
 // new release does not have any values.
 //
@@ -29,6 +29,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"helm.sh/helm/v4/pkg/kube"
 	kubefake "helm.sh/helm/v4/pkg/kube/fake"
 	"helm.sh/helm/v4/pkg/release"
 	helmtime "helm.sh/helm/v4/pkg/time"
@@ -60,6 +61,7 @@ func TestUpgradeRelease_Success(t *testing.T) {
 	done()
 	req.NoError(err)
 	is.Equal(res.Info.Status, release.StatusDeployed)
+	is.Equal(release.ReasonUpgraded, res.Info.Reason)
 
 	// Detecting previous bug where context termination after successful release
 	// caused release to fail.
@@ -89,6 +91,8 @@ func TestUpgradeRelease_Wait(t *testing.T) {
 	req.Error(err)
 	is.Contains(res.Info.Description, "I timed out")
 	is.Equal(res.Info.Status, release.StatusFailed)
+	is.Equal(release.ReasonWaitFailed, res.Info.Reason)
+	is.Equal("I timed out", res.Info.ErrorDetail)
 }
 
 func TestUpgradeRelease_WaitForJobs(t *testing.T) {
@@ -443,6 +447,74 @@ func TestUpgradeRelease_Interrupted_Atomic(t *testing.T) {
 	is.Equal(updatedRes.Info.Status, release.StatusDeployed)
 }
 
+func TestUpgradeRelease_AtomicStopsRunningHooks(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		deleteErr error
+		expectErr string
+		rolled    bool
+	}{
+		{
+			name:      "hooks stopped before rolling back",
+			expectErr: "release hooked-release failed, the running hooks templates/migrate were stopped and the release has been rolled back due to atomic being set: context canceled",
+			rolled:    true,
+		},
+		{
+			name:      "hooks not stopped",
+			deleteErr: fmt.Errorf("jobs.batch \"migrate\" is forbidden"),
+			expectErr: "unable to stop the running hooks templates/migrate, the release was not rolled back. original upgrade error: context canceled: unable to delete the hook resources: jobs.batch \"migrate\" is forbidden",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			is := assert.New(t)
+			req := require.New(t)
+
+			upAction := upgradeAction(t)
+			upAction.Atomic = true
+			failer := upAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+			failer.DeleteWithPropagationError = tc.deleteErr
+
+			rel := releaseStub()
+			rel.Name = "hooked-release"
+			rel.Info.Status = release.StatusDeployed
+			req.NoError(upAction.cfg.Releases.Create(rel))
+
+			upgraded := releaseStub()
+			upgraded.Name = rel.Name
+			upgraded.Version = 2
+			upgraded.Info.Status = release.StatusPendingUpgrade
+			req.NoError(upAction.cfg.Releases.Create(upgraded))
+
+			// The pre-upgrade hook is still being watched when the upgrade
+			// is interrupted.
+			hook := &release.Hook{Name: "migrate", Path: "templates/migrate"}
+			job := permissionTestResource("batch", "jobs", "migrate", true)
+			upAction.hooks = &hookTracker{}
+			req.True(upAction.hooks.start(hook, kube.ResourceList{job}))
+			// The upgrade returns once its hook is stopped.
+			upAction.releasing = make(chan struct{})
+			close(upAction.releasing)
+
+			_, err := upAction.failRelease(upgraded, nil, context.Canceled)
+			req.Error(err)
+			is.Equal(tc.expectErr, err.Error())
+			is.ErrorIs(err, context.Canceled)
+			// No hook starts once the running ones are stopped.
+			is.False(upAction.hooks.start(&release.Hook{Name: "post"}, nil))
+
+			last, err := upAction.cfg.Releases.Last(rel.Name)
+			req.NoError(err)
+			if tc.rolled {
+				is.Equal(3, last.Version)
+				is.Equal(release.StatusDeployed, last.Info.Status)
+			} else {
+				is.Equal(2, last.Version)
+				is.Equal(release.StatusFailed, last.Info.Status)
+			}
+		})
+	}
+}
+
 func TestMergeCustomLabels(t *testing.T) {
 	var tests = [][3]map[string]string{
 		{nil, nil, map[string]string{}},
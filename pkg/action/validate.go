@@ -18,6 +18,7 @@ package action
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -62,8 +63,80 @@ func requireAdoption(resources kube.ResourceList) (kube.ResourceList, error) {
 	return requireUpdate, err
 }
 
+// ConflictReason is the reason why an existing resource cannot be imported
+// into a release.
+type ConflictReason string
+
+const (
+	// ConflictMissingMetadata means that an ownership label or annotation is
+	// missing, e.g. because the resource was not created by Helm.
+	ConflictMissingMetadata ConflictReason = "MissingMetadata"
+	// ConflictLabelMismatch means that the managed-by label has another
+	// value, e.g. because the resource is managed by another tool.
+	ConflictLabelMismatch ConflictReason = "LabelMismatch"
+	// ConflictOwnedByOtherRelease means that the release annotations name
+	// another release.
+	ConflictOwnedByOtherRelease ConflictReason = "OwnedByOtherRelease"
+)
+
+// ResourceConflict is an ownership label or annotation of an existing
+// resource that does not match the release.
+type ResourceConflict struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Key is the label or annotation key.
+	Key string `json:"key"`
+	// Annotation reports whether Key is an annotation rather than a label.
+	Annotation bool `json:"annotation,omitempty"`
+	// Current is the current value, empty when the key is missing.
+	Current string `json:"current,omitempty"`
+	// Expected is the value that the key should have.
+	Expected string         `json:"expected"`
+	Reason   ConflictReason `json:"reason"`
+}
+
+// ConflictError reports the existing resources that cannot be imported into
+// a release because of their ownership metadata.
+type ConflictError struct {
+	// Conflicts are ordered by resource.
+	Conflicts []ResourceConflict
+}
+
+func (e *ConflictError) Error() string {
+	var lines []string
+	for i := 0; i < len(e.Conflicts); {
+		c := e.Conflicts[i]
+		msg := fmt.Sprintf("%s %q in namespace %q exists and cannot be imported into the current release: invalid ownership metadata", c.Kind, c.Name, c.Namespace)
+		for ; i < len(e.Conflicts) && e.Conflicts[i].sameResource(c); i++ {
+			msg += "; " + e.Conflicts[i].message()
+		}
+		lines = append(lines, msg)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c ResourceConflict) sameResource(other ResourceConflict) bool {
+	return c.Kind == other.Kind && c.Name == other.Name && c.Namespace == other.Namespace
+}
+
+func (c ResourceConflict) message() string {
+	kind := "label"
+	if c.Annotation {
+		kind = "annotation"
+	}
+	if c.Reason == ConflictMissingMetadata {
+		return fmt.Sprintf("%s validation error: missing key %q: must be set to %q", kind, c.Key, c.Expected)
+	}
+	return fmt.Sprintf("%s validation error: key %q must equal %q: current value is %q", kind, c.Key, c.Expected, c.Current)
+}
+
+// existingResourceConflict returns the subset of resources that already exist
+// in the cluster and are owned by the release. It returns a *ConflictError
+// listing the existing resources that are not.
 func existingResourceConflict(resources kube.ResourceList, releaseName, releaseNamespace string) (kube.ResourceList, error) {
 	var requireUpdate kube.ResourceList
+	var conflicts []ResourceConflict
 
 	err := resources.Visit(func(info *resource.Info, err error) error {
 		if err != nil {
@@ -80,15 +153,65 @@ func existingResourceConflict(resources kube.ResourceList, releaseName, releaseN
 		}
 
 		// Allow adoption of the resource if it is managed by Helm and is annotated with correct release name and namespace.
-		if err := checkOwnership(existing, releaseName, releaseNamespace); err != nil {
-			return fmt.Errorf("%s exists and cannot be imported into the current release: %s", resourceString(info), err)
+		found, err := ownershipConflicts(info, existing, releaseName, releaseNamespace)
+		if err != nil {
+			return err
+		}
+		if len(found) > 0 {
+			conflicts = append(conflicts, found...)
+			return nil
 		}
 
 		requireUpdate.Append(info)
 		return nil
 	})
+	if err != nil {
+		return requireUpdate, err
+	}
+	if len(conflicts) > 0 {
+		return requireUpdate, &ConflictError{Conflicts: conflicts}
+	}
+	return requireUpdate, nil
+}
 
-	return requireUpdate, err
+// ownershipConflicts returns the ownership labels and annotations of the live
+// object of a resource that do not match the release.
+func ownershipConflicts(info *resource.Info, obj runtime.Object, releaseName, releaseNamespace string) ([]ResourceConflict, error) {
+	lbls, err := accessor.Labels(obj)
+	if err != nil {
+		return nil, err
+	}
+	annos, err := accessor.Annotations(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	_, kind := info.Mapping.GroupVersionKind.ToAPIVersionAndKind()
+	var conflicts []ResourceConflict
+	check := func(current map[string]string, key, expected string, annotation bool, mismatch ConflictReason) {
+		actual, ok := current[key]
+		if ok && actual == expected {
+			return
+		}
+		reason := mismatch
+		if !ok {
+			reason = ConflictMissingMetadata
+		}
+		conflicts = append(conflicts, ResourceConflict{
+			Kind:       kind,
+			Name:       info.Name,
+			Namespace:  info.Namespace,
+			Key:        key,
+			Annotation: annotation,
+			Current:    actual,
+			Expected:   expected,
+			Reason:     reason,
+		})
+	}
+	check(lbls, appManagedByLabel, appManagedByHelm, false, ConflictLabelMismatch)
+	check(annos, helmReleaseNameAnnotation, releaseName, true, ConflictOwnedByOtherRelease)
+	check(annos, helmReleaseNamespaceAnnotation, releaseNamespace, true, ConflictOwnedByOtherRelease)
+	return conflicts, nil
 }
 
 func checkOwnership(obj runtime.Object, releaseName, releaseNamespace string) error {
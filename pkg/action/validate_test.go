@@ -161,6 +161,25 @@ func TestExistingResourceConflict(t *testing.T) {
 	resources = append(resources, conflict)
 	_, err = existingResourceConflict(resources, releaseName, releaseNamespace)
 	assert.Error(t, err)
+
+	// Verify that all the conflicts are reported with their reasons
+	other := newDeploymentWithOwner("other", "ns-a", map[string]string{appManagedByLabel: "argocd"}, map[string]string{
+		helmReleaseNameAnnotation:      "other-release",
+		helmReleaseNamespaceAnnotation: releaseNamespace,
+	})
+	resources = append(resources, other)
+	_, err = existingResourceConflict(resources, releaseName, releaseNamespace)
+	var cerr *ConflictError
+	assert.ErrorAs(t, err, &cerr)
+	assert.Equal(t, []ResourceConflict{
+		{Kind: "Deployment", Name: "conflict", Namespace: "ns-a", Key: appManagedByLabel, Expected: appManagedByHelm, Reason: ConflictMissingMetadata},
+		{Kind: "Deployment", Name: "conflict", Namespace: "ns-a", Key: helmReleaseNameAnnotation, Annotation: true, Expected: releaseName, Reason: ConflictMissingMetadata},
+		{Kind: "Deployment", Name: "conflict", Namespace: "ns-a", Key: helmReleaseNamespaceAnnotation, Annotation: true, Expected: releaseNamespace, Reason: ConflictMissingMetadata},
+		{Kind: "Deployment", Name: "other", Namespace: "ns-a", Key: appManagedByLabel, Current: "argocd", Expected: appManagedByHelm, Reason: ConflictLabelMismatch},
+		{Kind: "Deployment", Name: "other", Namespace: "ns-a", Key: helmReleaseNameAnnotation, Annotation: true, Current: "other-release", Expected: releaseName, Reason: ConflictOwnedByOtherRelease},
+	}, cerr.Conflicts)
+	assert.EqualError(t, err, `Deployment "conflict" in namespace "ns-a" exists and cannot be imported into the current release: invalid ownership metadata; label validation error: missing key "app.kubernetes.io/managed-by": must be set to "Helm"; annotation validation error: missing key "meta.helm.sh/release-name": must be set to "rel-name"; annotation validation error: missing key "meta.helm.sh/release-namespace": must be set to "rel-namespace"
+Deployment "other" in namespace "ns-a" exists and cannot be imported into the current release: invalid ownership metadata; label validation error: key "app.kubernetes.io/managed-by" must equal "Helm": current value is "argocd"; annotation validation error: key "meta.helm.sh/release-name" must equal "rel-name": current value is "other-release"`)
 }
 
 func TestCheckOwnership(t *testing.T) {
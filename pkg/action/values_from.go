@@ -0,0 +1,177 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/mitchellh/copystructure"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/registry"
+)
+
+// ValuesFromOptions configure how the values sources declared by charts with
+// valuesFrom are read, see chart.ValuesSource. The sources are only read when
+// EnableValuesFrom is set, as they let a chart read ConfigMaps and Secrets of
+// the release namespace and fetch URLs.
+//
+// The values of the sources are only used for rendering. They are not stored
+// as the user-supplied values of the release, so they are read again on every
+// upgrade. Conditions and tags of dependencies are evaluated without them.
+type ValuesFromOptions struct {
+	// Environment selects the values sources of an environment. Sources
+	// restricted to other environments are skipped.
+	Environment string
+	// EnableValuesFrom reads the values sources declared by charts. They are
+	// ignored otherwise.
+	EnableValuesFrom bool
+	// ValuesGetters fetch the values sources with a URL.
+	ValuesGetters getter.Providers
+}
+
+// mergeValuesFrom reads the values sources of a chart and its enabled
+// subcharts and merges them with the user-supplied values, which take
+// precedence. vals is not modified. ConfigMap and Secret sources are read with
+// the client returned by clientFn, and skipped when clientFn is nil.
+func (cfg *Configuration) mergeValuesFrom(opts ValuesFromOptions, ch *chart.Chart, namespace string, vals map[string]interface{}, clientFn func() (kubernetes.Interface, error)) (map[string]interface{}, error) {
+	if !opts.EnableValuesFrom {
+		return vals, nil
+	}
+	sourced, err := cfg.readValuesFrom(opts, ch, namespace, clientFn)
+	if err != nil || len(sourced) == 0 {
+		return vals, err
+	}
+	v, err := copystructure.Copy(vals)
+	if err != nil {
+		return nil, err
+	}
+	userVals, _ := v.(map[string]interface{})
+	return chartutil.MergeTables(userVals, sourced), nil
+}
+
+// readValuesFrom merges the values sources of a chart in order, so that a
+// later source overrides an earlier one. The values sources of a chart
+// override the ones of its subcharts.
+func (cfg *Configuration) readValuesFrom(opts ValuesFromOptions, ch *chart.Chart, namespace string, clientFn func() (kubernetes.Interface, error)) (map[string]interface{}, error) {
+	vals := map[string]interface{}{}
+	if ch.Metadata != nil {
+		for _, src := range ch.Metadata.ValuesFrom {
+			if !src.AppliesTo(opts.Environment) {
+				continue
+			}
+			if src.URL == "" && clientFn == nil {
+				cfg.Log("skipping values from %s of chart %q: the cluster is not available", describeValuesSource(src), ch.ChartPath())
+				continue
+			}
+			v, err := readValuesSource(opts, src, namespace, clientFn)
+			if err != nil {
+				if src.FailurePolicy == chart.ValuesFailurePolicyIgnore {
+					cfg.Log("skipping values from %s of chart %q: %s", describeValuesSource(src), ch.ChartPath(), err)
+					continue
+				}
+				return nil, errors.Wrapf(err, "chart %q: unable to read values from %s", ch.ChartPath(), describeValuesSource(src))
+			}
+			vals = chartutil.MergeTables(v, vals)
+		}
+	}
+
+	for _, dep := range ch.Dependencies() {
+		sub, err := cfg.readValuesFrom(opts, dep, namespace, clientFn)
+		if err != nil {
+			return nil, err
+		}
+		if len(sub) > 0 {
+			vals = chartutil.MergeTables(vals, map[string]interface{}{dep.Name(): sub})
+		}
+	}
+	return vals, nil
+}
+
+func readValuesSource(opts ValuesFromOptions, src *chart.ValuesSource, namespace string, clientFn func() (kubernetes.Interface, error)) (chartutil.Values, error) {
+	key := src.Key
+	if key == "" {
+		key = chart.DefaultValuesKey
+	}
+
+	var data []byte
+	switch {
+	case src.ConfigMap != "":
+		client, err := clientFn()
+		if err != nil {
+			return nil, err
+		}
+		cm, err := client.CoreV1().ConfigMaps(namespace).Get(context.Background(), src.ConfigMap, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		s, ok := cm.Data[key]
+		if !ok {
+			return nil, errors.Errorf("the ConfigMap has no key %q", key)
+		}
+		data = []byte(s)
+	case src.Secret != "":
+		client, err := clientFn()
+		if err != nil {
+			return nil, err
+		}
+		secret, err := client.CoreV1().Secrets(namespace).Get(context.Background(), src.Secret, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		if data, ok = secret.Data[key]; !ok {
+			return nil, errors.Errorf("the Secret has no key %q", key)
+		}
+	default:
+		u, err := url.Parse(src.URL)
+		if err != nil {
+			return nil, err
+		}
+		g, err := opts.ValuesGetters.ByScheme(u.Scheme)
+		if err != nil {
+			return nil, err
+		}
+		getterOpts := []getter.Option{getter.WithURL(src.URL)}
+		if u.Scheme == registry.OCIScheme {
+			getterOpts = append(getterOpts, getter.WithValues())
+		}
+		buf, err := g.Get(src.URL, getterOpts...)
+		if err != nil {
+			return nil, err
+		}
+		data = buf.Bytes()
+	}
+	return chartutil.ReadValues(data)
+}
+
+func describeValuesSource(src *chart.ValuesSource) string {
+	switch {
+	case src.ConfigMap != "":
+		return fmt.Sprintf("ConfigMap %q", src.ConfigMap)
+	case src.Secret != "":
+		return fmt.Sprintf("Secret %q", src.Secret)
+	}
+	return src.URL
+}
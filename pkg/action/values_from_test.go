@@ -0,0 +1,174 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+func withValuesFrom(sources ...*chart.ValuesSource) chartOption {
+	return func(opts *chartOptions) {
+		opts.Metadata.ValuesFrom = append(opts.Metadata.ValuesFrom, sources...)
+	}
+}
+
+func valuesFromClient() func() (kubernetes.Interface, error) {
+	client := fakeclientset.NewSimpleClientset(
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "base", Namespace: "spaced"},
+			Data:       map[string]string{"values.yaml": "replicas: 1\nimage:\n  tag: base\n  pullPolicy: Always\n"},
+		},
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "sub", Namespace: "spaced"},
+			Data:       map[string]string{"sub.yaml": "color: blue\nsize: small\n"},
+		},
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "spaced"},
+			Data:       map[string][]byte{"values.yaml": []byte("replicas: 3\nimage:\n  tag: prod\n")},
+		},
+	)
+	return func() (kubernetes.Interface, error) { return client, nil }
+}
+
+func TestMergeValuesFrom(t *testing.T) {
+	is := assert.New(t)
+	config := actionConfigFixture(t)
+	ch := buildChart(
+		withValuesFrom(
+			&chart.ValuesSource{ConfigMap: "base"},
+			&chart.ValuesSource{Secret: "prod", Environments: []string{"prod"}},
+		),
+		withDependency(
+			withName("sub"),
+			withValuesFrom(&chart.ValuesSource{ConfigMap: "sub", Key: "sub.yaml"}),
+		),
+	)
+	vals := map[string]interface{}{
+		"image": map[string]interface{}{"pullPolicy": "IfNotPresent"},
+		"sub":   map[string]interface{}{"size": "large"},
+	}
+
+	got, err := config.mergeValuesFrom(ValuesFromOptions{EnableValuesFrom: true}, ch, "spaced", vals, valuesFromClient())
+	require.NoError(t, err)
+	is.Equal(map[string]interface{}{
+		"replicas": json.Number("1"),
+		"image":    map[string]interface{}{"tag": "base", "pullPolicy": "IfNotPresent"},
+		"sub":      map[string]interface{}{"color": "blue", "size": "large"},
+	}, got)
+	is.Equal(map[string]interface{}{
+		"image": map[string]interface{}{"pullPolicy": "IfNotPresent"},
+		"sub":   map[string]interface{}{"size": "large"},
+	}, vals, "the user-supplied values must not be modified")
+
+	got, err = config.mergeValuesFrom(ValuesFromOptions{EnableValuesFrom: true, Environment: "prod"}, ch, "spaced", vals, valuesFromClient())
+	require.NoError(t, err)
+	is.Equal(json.Number("3"), got["replicas"])
+	is.Equal(map[string]interface{}{"tag": "prod", "pullPolicy": "IfNotPresent"}, got["image"])
+
+	got, err = config.mergeValuesFrom(ValuesFromOptions{EnableValuesFrom: false}, ch, "spaced", vals, valuesFromClient())
+	require.NoError(t, err)
+	is.Equal(vals, got, "the values sources must only be read when enabled")
+
+	got, err = config.mergeValuesFrom(ValuesFromOptions{EnableValuesFrom: true}, ch, "spaced", vals, nil)
+	require.NoError(t, err)
+	is.Equal(vals, got, "ConfigMaps and Secrets must be skipped without a cluster")
+}
+
+func TestMergeValuesFromParentOverridesSubchart(t *testing.T) {
+	config := actionConfigFixture(t)
+	ch := buildChart(
+		withValuesFrom(&chart.ValuesSource{ConfigMap: "base"}),
+		withDependency(
+			withName("sub"),
+			withValuesFrom(&chart.ValuesSource{ConfigMap: "sub", Key: "sub.yaml"}),
+		),
+	)
+	client := valuesFromClient()
+	c, _ := client()
+	cm, err := c.CoreV1().ConfigMaps("spaced").Get(context.Background(), "base", metav1.GetOptions{})
+	require.NoError(t, err)
+	cm.Data["values.yaml"] = "sub:\n  color: red\n"
+	_, err = c.CoreV1().ConfigMaps("spaced").Update(context.Background(), cm, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	got, err := config.mergeValuesFrom(ValuesFromOptions{EnableValuesFrom: true}, ch, "spaced", map[string]interface{}{}, client)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"color": "red", "size": "small"}, got["sub"])
+}
+
+func TestMergeValuesFromFailurePolicy(t *testing.T) {
+	config := actionConfigFixture(t)
+
+	ch := buildChart(withValuesFrom(&chart.ValuesSource{ConfigMap: "missing"}))
+	_, err := config.mergeValuesFrom(ValuesFromOptions{EnableValuesFrom: true}, ch, "spaced", map[string]interface{}{}, valuesFromClient())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `chart "hello": unable to read values from ConfigMap "missing"`)
+
+	ch = buildChart(withValuesFrom(&chart.ValuesSource{ConfigMap: "base", Key: "other.yaml"}))
+	_, err = config.mergeValuesFrom(ValuesFromOptions{EnableValuesFrom: true}, ch, "spaced", map[string]interface{}{}, valuesFromClient())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `the ConfigMap has no key "other.yaml"`)
+
+	ch = buildChart(withValuesFrom(
+		&chart.ValuesSource{ConfigMap: "missing", FailurePolicy: chart.ValuesFailurePolicyIgnore},
+		&chart.ValuesSource{Secret: "prod"},
+	))
+	got, err := config.mergeValuesFrom(ValuesFromOptions{EnableValuesFrom: true}, ch, "spaced", map[string]interface{}{}, valuesFromClient())
+	require.NoError(t, err)
+	assert.Equal(t, json.Number("3"), got["replicas"])
+}
+
+func TestMergeValuesFromURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/values.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "replicas: 2\n")
+	}))
+	defer srv.Close()
+
+	config := actionConfigFixture(t)
+	opts := ValuesFromOptions{
+		EnableValuesFrom: true,
+		ValuesGetters:    getter.Providers{{Schemes: []string{"http"}, New: getter.NewHTTPGetter}},
+	}
+
+	ch := buildChart(withValuesFrom(&chart.ValuesSource{URL: srv.URL + "/values.yaml"}))
+	got, err := config.mergeValuesFrom(opts, ch, "spaced", map[string]interface{}{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"replicas": json.Number("2")}, got)
+
+	ch = buildChart(withValuesFrom(&chart.ValuesSource{URL: srv.URL + "/missing.yaml"}))
+	_, err = config.mergeValuesFrom(opts, ch, "spaced", map[string]interface{}{}, nil)
+	assert.Error(t, err)
+}
@@ -18,9 +18,15 @@ package action
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+
 	"helm.sh/helm/v4/pkg/downloader"
+	"helm.sh/helm/v4/pkg/provenance"
+	"helm.sh/helm/v4/pkg/registry"
 )
 
 // Verify is the action for building a given chart's Verify tree.
@@ -29,6 +35,33 @@ import (
 type Verify struct {
 	Keyring string
 	Out     string
+
+	registryClient *registry.Client
+}
+
+// VerifyReport is the result of the verification of a chart, e.g. for an
+// admission pipeline to decide whether the chart may be deployed.
+type VerifyReport struct {
+	// Ref is the verified chart: the path of an archive or an OCI reference.
+	Ref string `json:"ref"`
+	// Name and Version are those of the chart, when pulled from a registry.
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+	// ManifestDigest is the digest of the OCI manifest of the chart.
+	ManifestDigest string `json:"manifestDigest,omitempty"`
+	// ChartDigest is the SHA-256 digest of the chart archive, e.g.
+	// "sha256:4f1a...".
+	ChartDigest string `json:"chartDigest,omitempty"`
+	// ProvenanceDigest is the digest of the provenance layer of the chart.
+	ProvenanceDigest string `json:"provenanceDigest,omitempty"`
+	// SignedBy are the identities of the key that signed the chart.
+	SignedBy []string `json:"signedBy,omitempty"`
+	// Fingerprint is the fingerprint of the key that signed the chart.
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Verified is set when the signature and the digests are valid.
+	Verified bool `json:"verified"`
+	// Error is the reason the chart could not be verified.
+	Error string `json:"error,omitempty"`
 }
 
 // NewVerify creates a new Verify object with the given configuration.
@@ -36,19 +69,28 @@ func NewVerify() *Verify {
 	return &Verify{}
 }
 
+// SetRegistryClient sets the registry client used to verify charts in OCI
+// registries.
+func (v *Verify) SetRegistryClient(client *registry.Client) {
+	v.registryClient = client
+}
+
 // Run executes 'helm verify'.
 func (v *Verify) Run(chartfile string) error {
 	var out strings.Builder
-	p, err := downloader.VerifyChart(chartfile, v.Keyring)
+	report, err := v.Report(chartfile)
 	if err != nil {
 		return err
 	}
 
-	for name := range p.SignedBy.Identities {
+	if report.ManifestDigest != "" {
+		fmt.Fprintf(&out, "Digest: %s\n", report.ManifestDigest)
+	}
+	for _, name := range report.SignedBy {
 		fmt.Fprintf(&out, "Signed by: %v\n", name)
 	}
-	fmt.Fprintf(&out, "Using Key With Fingerprint: %X\n", p.SignedBy.PrimaryKey.Fingerprint)
-	fmt.Fprintf(&out, "Chart Hash Verified: %s\n", p.FileHash)
+	fmt.Fprintf(&out, "Using Key With Fingerprint: %s\n", report.Fingerprint)
+	fmt.Fprintf(&out, "Chart Hash Verified: %s\n", report.ChartDigest)
 
 	// TODO(mattfarina): The output is set as a property rather than returned
 	// to maintain the Go API. In Helm v4 this function should return the out
@@ -57,3 +99,94 @@ func (v *Verify) Run(chartfile string) error {
 
 	return nil
 }
+
+// Report verifies a chart archive, or a chart in an OCI registry when
+// chartRef is an oci:// reference, and returns the report of the
+// verification. When the verification fails, the report is returned with the
+// error.
+//
+// A chart in a registry is verified without writing it to disk: its manifest,
+// chart and provenance layers are pulled in memory, the digest of the chart
+// layer is checked and the provenance is verified against the keyring.
+func (v *Verify) Report(chartRef string) (*VerifyReport, error) {
+	if registry.IsOCI(chartRef) {
+		return v.reportOCI(chartRef)
+	}
+
+	report := &VerifyReport{Ref: chartRef}
+	ver, err := downloader.VerifyChart(chartRef, v.Keyring)
+	if err != nil {
+		return report.fail(err)
+	}
+	report.verified(ver)
+	return report, nil
+}
+
+func (v *Verify) reportOCI(chartRef string) (*VerifyReport, error) {
+	report := &VerifyReport{Ref: chartRef}
+	if v.registryClient == nil {
+		return report.fail(errors.New("a registry client is required to verify a chart in a registry"))
+	}
+
+	result, err := v.registryClient.Pull(strings.TrimPrefix(chartRef, fmt.Sprintf("%s://", registry.OCIScheme)),
+		registry.PullOptWithProv(true),
+		registry.PullOptIgnoreMissingProv(true))
+	if err != nil {
+		return report.fail(errors.Wrapf(err, "failed to pull %s", chartRef))
+	}
+	report.ManifestDigest = result.Manifest.Digest
+	report.ProvenanceDigest = result.Prov.Digest
+	if meta := result.Chart.Meta; meta != nil {
+		report.Name, report.Version = meta.Name, meta.Version
+	}
+
+	if err := v.verifyData(report, result.Chart.Digest, result.Chart.Data, result.Prov.Data); err != nil {
+		return report.fail(err)
+	}
+	return report, nil
+}
+
+// verifyData verifies a chart archive held in memory against the digest it
+// was fetched by and its provenance file.
+func (v *Verify) verifyData(report *VerifyReport, chartDigest string, chartData, provData []byte) error {
+	expected, err := digest.Parse(chartDigest)
+	if err != nil {
+		return errors.Wrap(err, "invalid digest of the chart layer")
+	}
+	if actual := expected.Algorithm().FromBytes(chartData); actual != expected {
+		return errors.Errorf("digest of the chart layer does not match: %s != %s", actual, expected)
+	}
+	if len(provData) == 0 {
+		return errors.Errorf("chart %s is not signed: it has no provenance layer", report.Ref)
+	}
+
+	sig, err := provenance.NewFromKeyring(v.Keyring, "")
+	if err != nil {
+		return errors.Wrap(err, "failed to load keyring")
+	}
+	ver, err := sig.VerifyData(chartData, provData, fmt.Sprintf("%s-%s.tgz", report.Name, report.Version))
+	if err != nil {
+		return err
+	}
+	report.verified(ver)
+	return nil
+}
+
+// verified records a successful verification.
+func (r *VerifyReport) verified(ver *provenance.Verification) {
+	r.SignedBy = r.SignedBy[:0]
+	for name := range ver.SignedBy.Identities {
+		r.SignedBy = append(r.SignedBy, name)
+	}
+	sort.Strings(r.SignedBy)
+	r.Fingerprint = fmt.Sprintf("%X", ver.SignedBy.PrimaryKey.Fingerprint)
+	r.ChartDigest = ver.FileHash
+	r.Verified = true
+}
+
+// fail records the error of a failed verification.
+func (r *VerifyReport) fail(err error) (*VerifyReport, error) {
+	r.Verified = false
+	r.Error = err.Error()
+	return r, err
+}
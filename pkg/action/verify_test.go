@@ -0,0 +1,82 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"os"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyReport(t *testing.T) {
+	v := NewVerify()
+	v.Keyring = "../downloader/testdata/helm-test-key.pub"
+
+	report, err := v.Report("../downloader/testdata/signtest-0.1.0.tgz")
+	require.NoError(t, err)
+	assert.True(t, report.Verified)
+	assert.Equal(t, []string{"Helm Testing (This key should only be used for testing. DO NOT TRUST.) <helm-testing@helm.sh>"}, report.SignedBy)
+	assert.NotEmpty(t, report.Fingerprint)
+	assert.Empty(t, report.Error)
+
+	report, err = v.Report("testdata/charts/compressedchart-0.1.0.tgz")
+	assert.Error(t, err)
+	assert.False(t, report.Verified)
+	assert.Equal(t, err.Error(), report.Error)
+
+	_, err = v.Report("oci://localhost:5000/charts/signtest:0.1.0")
+	assert.ErrorContains(t, err, "a registry client is required")
+}
+
+func TestVerifyData(t *testing.T) {
+	chartData, err := os.ReadFile("../downloader/testdata/signtest-0.1.0.tgz")
+	require.NoError(t, err)
+	provData, err := os.ReadFile("../downloader/testdata/signtest-0.1.0.tgz.prov")
+	require.NoError(t, err)
+	chartDigest := digest.FromBytes(chartData).String()
+
+	v := NewVerify()
+	v.Keyring = "../downloader/testdata/helm-test-key.pub"
+	newReport := func() *VerifyReport {
+		return &VerifyReport{Ref: "oci://localhost:5000/charts/signtest:0.1.0", Name: "signtest", Version: "0.1.0"}
+	}
+
+	report := newReport()
+	require.NoError(t, v.verifyData(report, chartDigest, chartData, provData))
+	assert.True(t, report.Verified)
+	assert.Equal(t, chartDigest, report.ChartDigest)
+	assert.Len(t, report.SignedBy, 1)
+
+	// The chart layer must match the digest of the manifest.
+	tampered := append(append([]byte{}, chartData...), 0)
+	err = v.verifyData(newReport(), chartDigest, tampered, provData)
+	assert.ErrorContains(t, err, "digest of the chart layer does not match")
+
+	// An unsigned chart cannot be verified.
+	err = v.verifyData(newReport(), chartDigest, chartData, nil)
+	assert.ErrorContains(t, err, "is not signed")
+
+	// The provenance must be the one of this version of the chart.
+	report = newReport()
+	report.Version = "0.2.0"
+	err = v.verifyData(report, chartDigest, chartData, provData)
+	assert.ErrorContains(t, err, `provenance does not contain a SHA for a file named "signtest-0.2.0.tgz"`)
+	assert.False(t, report.Verified)
+}
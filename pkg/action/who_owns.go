@@ -0,0 +1,200 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/releaseutil"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// ResourceReference identifies a live resource.
+type ResourceReference struct {
+	// APIVersion is the group and version of the resource, e.g. "apps/v1".
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Namespace is empty for cluster-scoped resources.
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// ResourceOwner is the release that manages a live resource.
+type ResourceOwner struct {
+	Resource ResourceReference `json:"resource"`
+	// ManagedByHelm reports whether the resource carries the label of the
+	// resources managed by Helm.
+	ManagedByHelm bool `json:"managedByHelm"`
+	// ReleaseName and ReleaseNamespace are read from the ownership
+	// annotations of the resource. They are empty when the resource is not
+	// owned by a release.
+	ReleaseName      string `json:"releaseName,omitempty"`
+	ReleaseNamespace string `json:"releaseNamespace,omitempty"`
+	// Revision is the latest revision of the release that holds the resource,
+	// in its manifest or as a hook. It is 0 when no stored revision holds it,
+	// e.g. when the release was uninstalled with the resource kept.
+	Revision int `json:"revision,omitempty"`
+	// Status is the status of that revision.
+	Status release.Status `json:"status,omitempty"`
+	// Hook reports whether the resource is a hook of that revision.
+	Hook bool `json:"hook,omitempty"`
+}
+
+// WhoOwns is the action for finding the release, and the revision, that
+// manages a live resource.
+//
+// The owner is read from the ownership annotations of the resource, then its
+// revision from the release records, which are searched in the storage of the
+// configuration: it must be initialized for the namespace of the release, or
+// for all namespaces.
+//
+// It provides the implementation of 'helm release who-owns'.
+type WhoOwns struct {
+	cfg *Configuration
+}
+
+// NewWhoOwns creates a new WhoOwns object with the given configuration.
+func NewWhoOwns(cfg *Configuration) *WhoOwns {
+	return &WhoOwns{
+		cfg: cfg,
+	}
+}
+
+// Run finds the owner of the live resource.
+func (w *WhoOwns) Run(ref ResourceReference) (*ResourceOwner, error) {
+	if err := w.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+
+	manifest, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": ref.APIVersion,
+		"kind":       ref.Kind,
+		"metadata": map[string]string{
+			"name":      ref.Name,
+			"namespace": ref.Namespace,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	resources, err := w.cfg.KubeClient.Build(bytes.NewBuffer(manifest), false)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to build the resource %s %q", ref.Kind, ref.Name)
+	}
+	if len(resources) != 1 {
+		return nil, errors.Errorf("unable to build the resource %s %q", ref.Kind, ref.Name)
+	}
+	info := resources[0]
+
+	live, err := resource.NewHelper(info.Client, info.Mapping).Get(info.Namespace, info.Name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get information about the resource %s", resourceString(info))
+	}
+	return w.RunObject(live)
+}
+
+// RunObject finds the owner of a resource already read from the cluster, such
+// as the object of an admission review.
+func (w *WhoOwns) RunObject(obj runtime.Object) (*ResourceOwner, error) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	name, err := accessor.Name(obj)
+	if err != nil {
+		return nil, err
+	}
+	namespace, err := accessor.Namespace(obj)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := accessor.Labels(obj)
+	if err != nil {
+		return nil, err
+	}
+	annotations, err := accessor.Annotations(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	apiVersion, kind := gvk.ToAPIVersionAndKind()
+	owner := &ResourceOwner{
+		Resource:         ResourceReference{APIVersion: apiVersion, Kind: kind, Namespace: namespace, Name: name},
+		ManagedByHelm:    labels[appManagedByLabel] == appManagedByHelm,
+		ReleaseName:      annotations[helmReleaseNameAnnotation],
+		ReleaseNamespace: annotations[helmReleaseNamespaceAnnotation],
+	}
+	if owner.ReleaseName == "" {
+		return owner, nil
+	}
+
+	history, err := w.cfg.Releases.History(owner.ReleaseName)
+	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil, err
+	}
+	releaseutil.Reverse(history, releaseutil.SortByRevision)
+	for _, rel := range history {
+		if owner.ReleaseNamespace != "" && rel.Namespace != owner.ReleaseNamespace {
+			continue
+		}
+		inManifest, inHooks := releaseHolds(rel, gvk.GroupKind(), namespace, name)
+		if inManifest || inHooks {
+			owner.Revision = rel.Version
+			owner.Status = rel.Info.Status
+			owner.Hook = !inManifest
+			break
+		}
+	}
+	return owner, nil
+}
+
+// releaseHolds reports whether the manifest, or the hooks, of the release
+// hold the resource. The resources without a namespace in the manifest are
+// taken to be in the namespace of the release, unless the resource is
+// cluster-scoped.
+func releaseHolds(rel *release.Release, gk schema.GroupKind, namespace, name string) (inManifest, inHooks bool) {
+	holds := func(manifest string) bool {
+		for _, doc := range releaseutil.SplitManifests(manifest) {
+			var head releaseutil.SimpleHead
+			if err := yaml.Unmarshal([]byte(doc), &head); err != nil || head.Metadata == nil {
+				continue
+			}
+			ns := head.Metadata.Namespace
+			if ns == "" && namespace != "" {
+				ns = rel.Namespace
+			}
+			if head.GroupVersionKind().GroupKind() == gk && head.Metadata.Name == name && ns == namespace {
+				return true
+			}
+		}
+		return false
+	}
+
+	if holds(rel.Manifest) {
+		return true, false
+	}
+	for _, h := range rel.Hooks {
+		if holds(h.Manifest) {
+			return false, true
+		}
+	}
+	return false, false
+}
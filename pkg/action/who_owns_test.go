@@ -0,0 +1,105 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"helm.sh/helm/v4/pkg/release"
+)
+
+func liveObject(apiVersion, kind, namespace, name string, annotations map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetLabels(map[string]string{appManagedByLabel: appManagedByHelm})
+	obj.SetAnnotations(annotations)
+	return obj
+}
+
+func TestWhoOwns(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	for _, rel := range []*release.Release{
+		{
+			Name:      "web",
+			Namespace: "shop",
+			Version:   1,
+			Info:      &release.Info{Status: release.StatusSuperseded},
+			Manifest:  "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n---\napiVersion: v1\nkind: Service\nmetadata:\n  name: legacy\n",
+		},
+		{
+			Name:      "web",
+			Namespace: "shop",
+			Version:   2,
+			Info:      &release.Info{Status: release.StatusDeployed},
+			Manifest:  "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n  namespace: shop\n---\napiVersion: rbac.authorization.k8s.io/v1\nkind: ClusterRole\nmetadata:\n  name: web\n",
+			Hooks:     []*release.Hook{{Name: "migrate", Manifest: "apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: migrate\n"}},
+		},
+	} {
+		require.NoError(t, cfg.Releases.Create(rel))
+	}
+	owned := map[string]string{
+		helmReleaseNameAnnotation:      "web",
+		helmReleaseNamespaceAnnotation: "shop",
+	}
+
+	tests := []struct {
+		name     string
+		obj      *unstructured.Unstructured
+		revision int
+		status   release.Status
+		hook     bool
+	}{
+		{"latest revision", liveObject("apps/v1", "Deployment", "shop", "web", owned), 2, release.StatusDeployed, false},
+		{"other version of the kind", liveObject("apps/v1beta1", "Deployment", "shop", "web", owned), 2, release.StatusDeployed, false},
+		{"cluster-scoped", liveObject("rbac.authorization.k8s.io/v1", "ClusterRole", "", "web", owned), 2, release.StatusDeployed, false},
+		{"hook", liveObject("batch/v1", "Job", "shop", "migrate", owned), 2, release.StatusDeployed, true},
+		{"earlier revision", liveObject("v1", "Service", "shop", "legacy", owned), 1, release.StatusSuperseded, false},
+		{"not in a revision", liveObject("v1", "ConfigMap", "shop", "web", owned), 0, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, err := NewWhoOwns(cfg).RunObject(tt.obj)
+			require.NoError(t, err)
+			assert.Equal(t, "web", owner.ReleaseName)
+			assert.Equal(t, "shop", owner.ReleaseNamespace)
+			assert.True(t, owner.ManagedByHelm)
+			assert.Equal(t, tt.revision, owner.Revision)
+			assert.Equal(t, tt.status, owner.Status)
+			assert.Equal(t, tt.hook, owner.Hook)
+		})
+	}
+
+	owner, err := NewWhoOwns(cfg).RunObject(liveObject("v1", "ConfigMap", "shop", "settings", nil))
+	require.NoError(t, err)
+	assert.Equal(t, ResourceReference{APIVersion: "v1", Kind: "ConfigMap", Namespace: "shop", Name: "settings"}, owner.Resource)
+	assert.Empty(t, owner.ReleaseName)
+
+	owner, err = NewWhoOwns(cfg).RunObject(liveObject("v1", "ConfigMap", "shop", "settings", map[string]string{
+		helmReleaseNameAnnotation:      "uninstalled",
+		helmReleaseNamespaceAnnotation: "shop",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "uninstalled", owner.ReleaseName)
+	assert.Zero(t, owner.Revision)
+}
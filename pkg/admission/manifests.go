@@ -0,0 +1,236 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// PolicyOptions configure the manifests generated by PolicyManifests.
+type PolicyOptions struct {
+	// Name of the policy and of its binding.
+	Name string
+	// Users and Groups are allowed to modify the resources, as for
+	// OwnershipValidator.
+	Users  []string
+	Groups []string
+	// Namespaces limits the protection to these namespaces. Empty protects
+	// all namespaces, as well as the cluster-scoped resources.
+	Namespaces []string
+}
+
+// PolicyManifests returns a ValidatingAdmissionPolicy, and its binding,
+// rejecting the updates and deletions of the resources managed by Helm made by
+// other users than the ones allowed, as a YAML stream.
+//
+// Unlike OwnershipValidator, the policy cannot look up the release records and
+// protects all the resources annotated with a release.
+func PolicyManifests(opts PolicyOptions) ([]byte, error) {
+	if opts.Name == "" {
+		return nil, errors.New("a policy name is required")
+	}
+	fail := admissionregistrationv1.Fail
+	policy := &admissionregistrationv1.ValidatingAdmissionPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: admissionregistrationv1.SchemeGroupVersion.String(),
+			Kind:       "ValidatingAdmissionPolicy",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: opts.Name},
+		Spec: admissionregistrationv1.ValidatingAdmissionPolicySpec{
+			FailurePolicy: &fail,
+			MatchConstraints: &admissionregistrationv1.MatchResources{
+				ObjectSelector: helmObjectSelector(),
+				ResourceRules:  helmResourceRules(),
+			},
+			Validations: []admissionregistrationv1.Validation{{
+				Expression: policyExpression(opts.Users, opts.Groups),
+				MessageExpression: fmt.Sprintf(
+					`'this resource is managed by the Helm release "' + oldObject.metadata.annotations[%s] + '" and can only be changed with Helm'`,
+					strconv.Quote(releaseNameAnnotation)),
+				Reason: ptr(metav1.StatusReasonForbidden),
+			}},
+		},
+	}
+	binding := &admissionregistrationv1.ValidatingAdmissionPolicyBinding{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: admissionregistrationv1.SchemeGroupVersion.String(),
+			Kind:       "ValidatingAdmissionPolicyBinding",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: opts.Name},
+		Spec: admissionregistrationv1.ValidatingAdmissionPolicyBindingSpec{
+			PolicyName:        opts.Name,
+			ValidationActions: []admissionregistrationv1.ValidationAction{admissionregistrationv1.Deny},
+		},
+	}
+	if len(opts.Namespaces) > 0 {
+		binding.Spec.MatchResources = &admissionregistrationv1.MatchResources{
+			NamespaceSelector: namespaceSelector(opts.Namespaces),
+		}
+	}
+	return marshalManifests(policy, binding)
+}
+
+// policyExpression returns the CEL expression allowing the requests. The
+// requests are only matched for the resources labeled as managed by Helm, and
+// not for their subresources.
+func policyExpression(users, groups []string) string {
+	clauses := []string{
+		fmt.Sprintf("!has(oldObject.metadata.annotations) || !(%s in oldObject.metadata.annotations)", strconv.Quote(releaseNameAnnotation)),
+	}
+	if len(users) > 0 {
+		clauses = append(clauses, fmt.Sprintf("request.userInfo.username in %s", celList(users)))
+	}
+	if len(groups) > 0 {
+		clauses = append(clauses, fmt.Sprintf("request.userInfo.groups.exists(g, g in %s)", celList(groups)))
+	}
+	return strings.Join(clauses, " || ")
+}
+
+func celList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// WebhookOptions configure the manifest generated by WebhookManifest.
+type WebhookOptions struct {
+	// Name of the webhook configuration.
+	Name string
+	// ServiceNamespace, ServiceName and ServicePath locate the service serving
+	// an OwnershipValidator.
+	ServiceNamespace string
+	ServiceName      string
+	ServicePath      string
+	// CABundle is the PEM encoded CA bundle validating the certificate of the
+	// service.
+	CABundle []byte
+	// Namespaces limits the protection to these namespaces. Empty protects
+	// all namespaces, as well as the cluster-scoped resources.
+	Namespaces []string
+	// FailurePolicy defaults to Ignore, so that an unavailable webhook does
+	// not block the changes to the cluster.
+	FailurePolicy admissionregistrationv1.FailurePolicyType
+}
+
+// WebhookManifest returns a ValidatingWebhookConfiguration sending the
+// updates and deletions of the resources managed by Helm to the service of an
+// OwnershipValidator, as YAML.
+func WebhookManifest(opts WebhookOptions) ([]byte, error) {
+	if opts.Name == "" {
+		return nil, errors.New("a webhook name is required")
+	}
+	if opts.ServiceNamespace == "" || opts.ServiceName == "" {
+		return nil, errors.New("the namespace and name of the webhook service are required")
+	}
+	failurePolicy := opts.FailurePolicy
+	if failurePolicy == "" {
+		failurePolicy = admissionregistrationv1.Ignore
+	}
+	service := &admissionregistrationv1.ServiceReference{
+		Namespace: opts.ServiceNamespace,
+		Name:      opts.ServiceName,
+	}
+	if opts.ServicePath != "" {
+		service.Path = &opts.ServicePath
+	}
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	webhook := admissionregistrationv1.ValidatingWebhook{
+		// Webhook names must be fully qualified.
+		Name: opts.Name + ".helm.sh",
+		ClientConfig: admissionregistrationv1.WebhookClientConfig{
+			Service:  service,
+			CABundle: opts.CABundle,
+		},
+		Rules:                   helmRules(),
+		FailurePolicy:           &failurePolicy,
+		SideEffects:             &sideEffects,
+		AdmissionReviewVersions: []string{"v1"},
+		ObjectSelector:          helmObjectSelector(),
+	}
+	if len(opts.Namespaces) > 0 {
+		webhook.NamespaceSelector = namespaceSelector(opts.Namespaces)
+	}
+	config := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: admissionregistrationv1.SchemeGroupVersion.String(),
+			Kind:       "ValidatingWebhookConfiguration",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: opts.Name},
+		Webhooks:   []admissionregistrationv1.ValidatingWebhook{webhook},
+	}
+	return marshalManifests(config)
+}
+
+func helmObjectSelector() *metav1.LabelSelector {
+	return &metav1.LabelSelector{MatchLabels: map[string]string{managedByLabel: managedByHelm}}
+}
+
+func namespaceSelector(namespaces []string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      "kubernetes.io/metadata.name",
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   namespaces,
+		}},
+	}
+}
+
+// helmRules match the updates and deletions of all resources, but not of
+// their subresources.
+func helmRules() []admissionregistrationv1.RuleWithOperations {
+	return []admissionregistrationv1.RuleWithOperations{{
+		Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Update, admissionregistrationv1.Delete},
+		Rule: admissionregistrationv1.Rule{
+			APIGroups:   []string{"*"},
+			APIVersions: []string{"*"},
+			Resources:   []string{"*"},
+		},
+	}}
+}
+
+func helmResourceRules() []admissionregistrationv1.NamedRuleWithOperations {
+	var rules []admissionregistrationv1.NamedRuleWithOperations
+	for _, r := range helmRules() {
+		rules = append(rules, admissionregistrationv1.NamedRuleWithOperations{RuleWithOperations: r})
+	}
+	return rules
+}
+
+func marshalManifests(objs ...interface{}) ([]byte, error) {
+	var docs []string
+	for _, obj := range objs {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to marshal the manifest")
+		}
+		docs = append(docs, string(data))
+	}
+	return []byte("---\n" + strings.Join(docs, "---\n")), nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,119 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"strings"
+	"testing"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func TestPolicyManifests(t *testing.T) {
+	out, err := PolicyManifests(PolicyOptions{
+		Name:       "helm-ownership",
+		Users:      []string{"system:serviceaccount:ci:deployer"},
+		Groups:     []string{"system:serviceaccounts:kube-system"},
+		Namespaces: []string{"shop"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := strings.Split(strings.TrimPrefix(string(out), "---\n"), "---\n")
+	if len(docs) != 2 {
+		t.Fatalf("expected 2 documents, got %d:\n%s", len(docs), out)
+	}
+	var policy admissionregistrationv1.ValidatingAdmissionPolicy
+	if err := yaml.Unmarshal([]byte(docs[0]), &policy); err != nil {
+		t.Fatal(err)
+	}
+	var binding admissionregistrationv1.ValidatingAdmissionPolicyBinding
+	if err := yaml.Unmarshal([]byte(docs[1]), &binding); err != nil {
+		t.Fatal(err)
+	}
+
+	if policy.Kind != "ValidatingAdmissionPolicy" || policy.Name != "helm-ownership" {
+		t.Errorf("unexpected policy %s %q", policy.Kind, policy.Name)
+	}
+	if got := policy.Spec.MatchConstraints.ObjectSelector.MatchLabels[managedByLabel]; got != managedByHelm {
+		t.Errorf("expected the policy to select the resources managed by Helm, got %q", got)
+	}
+	expression := policy.Spec.Validations[0].Expression
+	for _, expect := range []string{
+		`request.userInfo.username in ["system:serviceaccount:ci:deployer"]`,
+		`request.userInfo.groups.exists(g, g in ["system:serviceaccounts:kube-system"])`,
+	} {
+		if !strings.Contains(expression, expect) {
+			t.Errorf("expected the expression to contain %q, got %q", expect, expression)
+		}
+	}
+
+	if binding.Kind != "ValidatingAdmissionPolicyBinding" || binding.Spec.PolicyName != "helm-ownership" {
+		t.Errorf("unexpected binding %s for policy %q", binding.Kind, binding.Spec.PolicyName)
+	}
+	if len(binding.Spec.ValidationActions) != 1 || binding.Spec.ValidationActions[0] != admissionregistrationv1.Deny {
+		t.Errorf("expected the binding to deny, got %v", binding.Spec.ValidationActions)
+	}
+	if values := binding.Spec.MatchResources.NamespaceSelector.MatchExpressions[0].Values; len(values) != 1 || values[0] != "shop" {
+		t.Errorf("expected the binding to select namespace shop, got %v", values)
+	}
+
+	if _, err := PolicyManifests(PolicyOptions{}); err == nil {
+		t.Error("expected an error without a name")
+	}
+}
+
+func TestWebhookManifest(t *testing.T) {
+	out, err := WebhookManifest(WebhookOptions{
+		Name:             "helm-ownership",
+		ServiceNamespace: "helm-system",
+		ServiceName:      "ownership",
+		ServicePath:      "/validate",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config admissionregistrationv1.ValidatingWebhookConfiguration
+	if err := yaml.Unmarshal(out, &config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Kind != "ValidatingWebhookConfiguration" || len(config.Webhooks) != 1 {
+		t.Fatalf("unexpected webhook configuration:\n%s", out)
+	}
+	webhook := config.Webhooks[0]
+	if webhook.Name != "helm-ownership.helm.sh" {
+		t.Errorf("unexpected webhook name %q", webhook.Name)
+	}
+	if *webhook.FailurePolicy != admissionregistrationv1.Ignore {
+		t.Errorf("expected failure policy Ignore, got %s", *webhook.FailurePolicy)
+	}
+	if svc := webhook.ClientConfig.Service; svc.Namespace != "helm-system" || svc.Name != "ownership" || *svc.Path != "/validate" {
+		t.Errorf("unexpected service %+v", svc)
+	}
+	ops := webhook.Rules[0].Operations
+	if len(ops) != 2 || ops[0] != admissionregistrationv1.Update || ops[1] != admissionregistrationv1.Delete {
+		t.Errorf("expected UPDATE and DELETE operations, got %v", ops)
+	}
+	if webhook.NamespaceSelector != nil {
+		t.Errorf("expected no namespace selector, got %+v", webhook.NamespaceSelector)
+	}
+
+	if _, err := WebhookManifest(WebhookOptions{Name: "helm-ownership"}); err == nil {
+		t.Error("expected an error without a service")
+	}
+}
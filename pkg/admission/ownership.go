@@ -0,0 +1,148 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package admission protects the resources managed by Helm from modifications
+and deletions made out of band, i.e. not through Helm.
+
+OwnershipValidator is a validating admission webhook handler, and
+PolicyManifests generates a ValidatingAdmissionPolicy enforcing the same rules
+without a webhook, in clusters that serve them.
+*/
+package admission // import "helm.sh/helm/v4/pkg/admission"
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"helm.sh/helm/v4/pkg/action"
+)
+
+const (
+	managedByLabel             = "app.kubernetes.io/managed-by"
+	managedByHelm              = "Helm"
+	releaseNameAnnotation      = "meta.helm.sh/release-name"
+	releaseNamespaceAnnotation = "meta.helm.sh/release-namespace"
+)
+
+// OwnershipValidator rejects the updates and deletions of the resources
+// managed by Helm, i.e. labeled as managed by Helm and annotated with the
+// release that owns them, unless they are requested by the users that deploy
+// with Helm.
+//
+// The subresources, such as status and scale, are not protected as they are
+// updated by controllers. The users of the controllers that update the
+// resources, such as the garbage collector, must be allowed as well, e.g. with
+// the "system:serviceaccounts:kube-system" group.
+type OwnershipValidator struct {
+	// Users are the names of the users allowed to modify the resources, e.g.
+	// "system:serviceaccount:ci:deployer".
+	Users []string
+	// Groups are the groups of the users allowed to modify the resources.
+	Groups []string
+	// Owner looks up the release records that hold a resource, e.g.
+	// action.NewWhoOwns(cfg).RunObject. When set, the resources that no
+	// revision of their release holds, such as the resources kept by an
+	// uninstall, are not protected. Nil trusts the annotations alone.
+	Owner func(obj runtime.Object) (*action.ResourceOwner, error)
+}
+
+// Validate validates an admission request.
+func (v *OwnershipValidator) Validate(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	resp := &admissionv1.AdmissionResponse{UID: req.UID, Allowed: true}
+	if req.Operation != admissionv1.Update && req.Operation != admissionv1.Delete {
+		return resp
+	}
+	if req.SubResource != "" || v.allows(req.UserInfo) {
+		return resp
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(req.OldObject.Raw); err != nil {
+		resp.Warnings = []string{fmt.Sprintf("unable to decode the resource to check its ownership: %s", err)}
+		return resp
+	}
+	releaseName := obj.GetAnnotations()[releaseNameAnnotation]
+	if obj.GetLabels()[managedByLabel] != managedByHelm || releaseName == "" {
+		return resp
+	}
+	if v.Owner != nil {
+		owner, err := v.Owner(obj)
+		if err != nil {
+			resp.Warnings = []string{fmt.Sprintf("unable to look up the release records of the resource: %s", err)}
+		} else if owner.Revision == 0 {
+			return resp
+		}
+	}
+
+	verb := "modified"
+	if req.Operation == admissionv1.Delete {
+		verb = "deleted"
+	}
+	resp.Allowed = false
+	resp.Result = &metav1.Status{
+		Status: metav1.StatusFailure,
+		Code:   http.StatusForbidden,
+		Reason: metav1.StatusReasonForbidden,
+		Message: fmt.Sprintf("%s %q is managed by the Helm release %q in namespace %q and can only be %s with Helm",
+			obj.GetKind(), obj.GetName(), releaseName, obj.GetAnnotations()[releaseNamespaceAnnotation], verb),
+	}
+	return resp
+}
+
+func (v *OwnershipValidator) allows(user authenticationv1.UserInfo) bool {
+	if slices.Contains(v.Users, user.Username) {
+		return true
+	}
+	for _, group := range user.Groups {
+		if slices.Contains(v.Groups, group) {
+			return true
+		}
+	}
+	return false
+}
+
+// ServeHTTP serves the AdmissionReview requests of a validating webhook.
+func (v *OwnershipValidator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	review := &admissionv1.AdmissionReview{}
+	if err := json.NewDecoder(r.Body).Decode(review); err != nil {
+		http.Error(w, fmt.Sprintf("invalid admission review: %s", err), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "invalid admission review: no request", http.StatusBadRequest)
+		return
+	}
+	review.Response = v.Validate(review.Request)
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"helm.sh/helm/v4/pkg/action"
+)
+
+const managedConfigMap = `{
+  "apiVersion": "v1",
+  "kind": "ConfigMap",
+  "metadata": {
+    "name": "settings",
+    "namespace": "shop",
+    "labels": {"app.kubernetes.io/managed-by": "Helm"},
+    "annotations": {"meta.helm.sh/release-name": "web", "meta.helm.sh/release-namespace": "shop"}
+  }
+}`
+
+const unmanagedConfigMap = `{
+  "apiVersion": "v1",
+  "kind": "ConfigMap",
+  "metadata": {"name": "settings", "namespace": "shop"}
+}`
+
+func admissionRequest(op admissionv1.Operation, user string, old string) *admissionv1.AdmissionRequest {
+	return &admissionv1.AdmissionRequest{
+		UID:       "1234",
+		Operation: op,
+		UserInfo:  authenticationv1.UserInfo{Username: user, Groups: []string{"system:authenticated"}},
+		OldObject: runtime.RawExtension{Raw: []byte(old)},
+	}
+}
+
+func TestOwnershipValidatorValidate(t *testing.T) {
+	v := &OwnershipValidator{Users: []string{"deployer"}, Groups: []string{"helm-admins"}}
+
+	tests := []struct {
+		name    string
+		req     *admissionv1.AdmissionRequest
+		allowed bool
+	}{
+		{"update by another user", admissionRequest(admissionv1.Update, "alice", managedConfigMap), false},
+		{"delete by another user", admissionRequest(admissionv1.Delete, "alice", managedConfigMap), false},
+		{"update by helm user", admissionRequest(admissionv1.Update, "deployer", managedConfigMap), true},
+		{"unmanaged resource", admissionRequest(admissionv1.Update, "alice", unmanagedConfigMap), true},
+		{"create", admissionRequest(admissionv1.Create, "alice", ""), true},
+		{"invalid object", admissionRequest(admissionv1.Update, "alice", "{"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := v.Validate(tt.req)
+			if resp.UID != tt.req.UID {
+				t.Errorf("expected UID %q, got %q", tt.req.UID, resp.UID)
+			}
+			if resp.Allowed != tt.allowed {
+				t.Errorf("expected allowed to be %t, got %t", tt.allowed, resp.Allowed)
+			}
+		})
+	}
+
+	req := admissionRequest(admissionv1.Update, "alice", managedConfigMap)
+	req.UserInfo.Groups = append(req.UserInfo.Groups, "helm-admins")
+	if resp := v.Validate(req); !resp.Allowed {
+		t.Error("expected the request of a helm group to be allowed")
+	}
+
+	req = admissionRequest(admissionv1.Update, "alice", managedConfigMap)
+	req.SubResource = "status"
+	if resp := v.Validate(req); !resp.Allowed {
+		t.Error("expected the update of a subresource to be allowed")
+	}
+
+	resp := v.Validate(admissionRequest(admissionv1.Delete, "alice", managedConfigMap))
+	expect := `ConfigMap "settings" is managed by the Helm release "web" in namespace "shop" and can only be deleted with Helm`
+	if resp.Result == nil || resp.Result.Message != expect || resp.Result.Code != http.StatusForbidden {
+		t.Errorf("expected a forbidden status with message %q, got %+v", expect, resp.Result)
+	}
+}
+
+func TestOwnershipValidatorOwner(t *testing.T) {
+	var revision int
+	v := &OwnershipValidator{
+		Owner: func(obj runtime.Object) (*action.ResourceOwner, error) {
+			return &action.ResourceOwner{ManagedByHelm: true, ReleaseName: "web", Revision: revision}, nil
+		},
+	}
+
+	if resp := v.Validate(admissionRequest(admissionv1.Update, "alice", managedConfigMap)); !resp.Allowed {
+		t.Error("expected a resource held by no revision to be allowed")
+	}
+	revision = 2
+	if resp := v.Validate(admissionRequest(admissionv1.Update, "alice", managedConfigMap)); resp.Allowed {
+		t.Error("expected a resource held by a revision to be denied")
+	}
+}
+
+func TestOwnershipValidatorServeHTTP(t *testing.T) {
+	v := &OwnershipValidator{}
+	review := admissionv1.AdmissionReview{Request: admissionRequest(admissionv1.Delete, "alice", managedConfigMap)}
+	review.APIVersion = "admission.k8s.io/v1"
+	review.Kind = "AdmissionReview"
+	body, err := json.Marshal(review)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	v.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var got admissionv1.AdmissionReview
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Kind != "AdmissionReview" || got.Request != nil {
+		t.Errorf("unexpected review %+v", got)
+	}
+	if got.Response == nil || got.Response.Allowed || got.Response.UID != "1234" {
+		t.Errorf("expected the deletion to be denied, got %+v", got.Response)
+	}
+
+	rec = httptest.NewRecorder()
+	v.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader("{}")))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for a review without request, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,253 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package annotations parses the annotations that Helm reads from the resources
+of a chart.
+
+Helm uses the same functions when it sorts hooks and decides which resources
+to keep, so tools that re-implement parts of a release, such as the handling of
+hooks, can rely on them to interpret charts the way Helm does.
+*/
+package annotations // import "helm.sh/helm/v4/pkg/annotations"
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/release"
+)
+
+const (
+	// Hook is the annotation that declares the events of a hook.
+	Hook = release.HookAnnotation
+	// HookWeight is the annotation that declares the weight of a hook.
+	HookWeight = release.HookWeightAnnotation
+	// HookDeletePolicy is the annotation that declares the delete policies
+	// of a hook.
+	HookDeletePolicy = release.HookDeleteAnnotation
+	// HookTimeout is the annotation that sets the time to wait for a hook to
+	// complete.
+	HookTimeout = release.HookTimeoutAnnotation
+	// ResourcePolicy is the annotation that declares the resource policy of
+	// a resource.
+	ResourcePolicy = "helm.sh/resource-policy"
+	// RecreateOnImmutableChange is the annotation that allows an upgrade to
+	// delete and recreate a resource whose immutable fields are changed.
+	RecreateOnImmutableChange = "helm.sh/recreate-on-immutable-change"
+	// NoWait is the annotation that excludes a resource from waiting for the
+	// resources of a release to be ready.
+	NoWait = "helm.sh/no-wait"
+	// WaitTimeout is the annotation that sets the time to wait for a resource
+	// to be ready, overriding the timeout of the wait.
+	WaitTimeout = "helm.sh/wait-timeout"
+	// StatusField is the annotation that declares a JSONPath expression
+	// evaluated against the live resource and shown by 'helm status'.
+	StatusField = "helm.sh/status-field"
+)
+
+// KeepPolicy is the resource policy that keeps a resource when the release is
+// uninstalled or the resource is removed from the chart.
+const KeepPolicy = "keep"
+
+// hookEvents maps the values of the hook annotation to the hook events.
+var hookEvents = map[string]release.HookEvent{
+	release.HookPreInstall.String():   release.HookPreInstall,
+	release.HookPostInstall.String():  release.HookPostInstall,
+	release.HookPreDelete.String():    release.HookPreDelete,
+	release.HookPostDelete.String():   release.HookPostDelete,
+	release.HookPreUpgrade.String():   release.HookPreUpgrade,
+	release.HookPostUpgrade.String():  release.HookPostUpgrade,
+	release.HookPreRollback.String():  release.HookPreRollback,
+	release.HookPostRollback.String(): release.HookPostRollback,
+	release.HookTest.String():         release.HookTest,
+	// Support test-success for backward compatibility with Helm 2 tests
+	"test-success": release.HookTest,
+
+	release.HookPostUninstallFailed.String(): release.HookPostUninstallFailed,
+}
+
+// HookConfig is the hook configuration declared by the annotations of a
+// resource.
+type HookConfig struct {
+	Events         []release.HookEvent
+	Weight         int
+	DeletePolicies []release.HookDeletePolicy
+	// Timeout is 0 when the hook uses the timeout of the operation.
+	Timeout time.Duration
+}
+
+// UnknownHookError reports a hook annotation with an unknown event. Helm skips
+// such resources when it sorts the manifests of a release.
+type UnknownHookError struct {
+	// Value is the value of the hook annotation.
+	Value string
+	// Event is the first unknown event.
+	Event string
+}
+
+func (e *UnknownHookError) Error() string {
+	return fmt.Sprintf("unknown hook event %q in %q", e.Event, e.Value)
+}
+
+// Annotations are the annotations of a resource that Helm acts upon.
+type Annotations struct {
+	// Hook is the hook configuration, or nil when the resource is not a hook.
+	Hook *HookConfig
+	// ResourcePolicy is the normalized resource policy, e.g. KeepPolicy.
+	ResourcePolicy string
+	// RecreateOnImmutableChange reports that the resource is recreated when
+	// an upgrade changes its immutable fields.
+	RecreateOnImmutableChange bool
+}
+
+// Keep reports whether the resource is kept when it is no longer part of the
+// release.
+func (a *Annotations) Keep() bool {
+	return a.ResourcePolicy == KeepPolicy
+}
+
+// Parse parses the annotations of a resource.
+//
+// An *UnknownHookError is returned when the hook annotation contains an
+// unknown event, and an error when the hook timeout is not a positive
+// duration. The other annotations are parsed nonetheless.
+func Parse(annotations map[string]string) (*Annotations, error) {
+	hook, err := ParseHook(annotations)
+	return &Annotations{
+		Hook:                      hook,
+		ResourcePolicy:            ParseResourcePolicy(annotations),
+		RecreateOnImmutableChange: ParseRecreateOnImmutableChange(annotations),
+	}, err
+}
+
+// ParseManifest parses the annotations of the resource declared by a YAML
+// manifest.
+func ParseManifest(manifest string) (*Annotations, error) {
+	var head struct {
+		Metadata struct {
+			Annotations map[string]string `json:"annotations"`
+		} `json:"metadata"`
+	}
+	if err := yaml.Unmarshal([]byte(manifest), &head); err != nil {
+		return nil, err
+	}
+	return Parse(head.Metadata.Annotations)
+}
+
+// ParseHook parses the hook annotations. It returns nil when the resource is
+// not a hook.
+//
+// The events and delete policies are comma separated and case insensitive.
+// The weight defaults to 0 when it is not an integer. An error is returned
+// when the timeout is not a positive duration.
+func ParseHook(annotations map[string]string) (*HookConfig, error) {
+	value, ok := annotations[Hook]
+	if !ok {
+		return nil, nil
+	}
+
+	timeout, err := ParseHookTimeout(annotations)
+	if err != nil {
+		return nil, err
+	}
+	h := &HookConfig{
+		Events:         []release.HookEvent{},
+		Weight:         ParseHookWeight(annotations),
+		DeletePolicies: []release.HookDeletePolicy{},
+		Timeout:        timeout,
+	}
+	for _, event := range splitValues(value) {
+		e, ok := hookEvents[event]
+		if !ok {
+			return nil, &UnknownHookError{Value: value, Event: event}
+		}
+		h.Events = append(h.Events, e)
+	}
+	if policies, ok := annotations[HookDeletePolicy]; ok {
+		for _, policy := range splitValues(policies) {
+			h.DeletePolicies = append(h.DeletePolicies, release.HookDeletePolicy(policy))
+		}
+	}
+	return h, nil
+}
+
+// ParseHookWeight parses the hook weight annotation. The weight defaults to 0
+// when it is missing or not an integer.
+func ParseHookWeight(annotations map[string]string) int {
+	weight, err := strconv.Atoi(annotations[HookWeight])
+	if err != nil {
+		return 0
+	}
+	return weight
+}
+
+// ParseHookTimeout parses the hook timeout annotation. It returns 0 when the
+// hook has none, and an error when the value is not a positive duration.
+func ParseHookTimeout(annotations map[string]string) (time.Duration, error) {
+	return parseTimeout(annotations, HookTimeout)
+}
+
+// ParseResourcePolicy returns the lower-cased resource policy, or an empty
+// string when the resource has none.
+func ParseResourcePolicy(annotations map[string]string) string {
+	return strings.ToLower(strings.TrimSpace(annotations[ResourcePolicy]))
+}
+
+// ParseRecreateOnImmutableChange reports whether the resource is annotated to
+// be recreated when its immutable fields are changed.
+func ParseRecreateOnImmutableChange(annotations map[string]string) bool {
+	recreate, _ := strconv.ParseBool(annotations[RecreateOnImmutableChange])
+	return recreate
+}
+
+// ParseNoWait reports whether the resource is annotated to be excluded from
+// waiting for the resources of a release to be ready.
+func ParseNoWait(annotations map[string]string) bool {
+	noWait, _ := strconv.ParseBool(annotations[NoWait])
+	return noWait
+}
+
+// ParseWaitTimeout parses the wait timeout annotation. It returns 0 when the
+// resource has none, and an error when the value is not a positive duration.
+func ParseWaitTimeout(annotations map[string]string) (time.Duration, error) {
+	return parseTimeout(annotations, WaitTimeout)
+}
+
+func parseTimeout(annotations map[string]string, key string) (time.Duration, error) {
+	value, ok := annotations[key]
+	if !ok {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid %s annotation %q: expected a positive duration such as 10m", key, value)
+	}
+	return timeout, nil
+}
+
+// splitValues splits a comma separated annotation into lower-cased values.
+func splitValues(value string) []string {
+	values := strings.Split(value, ",")
+	for i, v := range values {
+		values[i] = strings.ToLower(strings.TrimSpace(v))
+	}
+	return values
+}
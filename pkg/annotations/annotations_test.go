@@ -0,0 +1,157 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package annotations
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"helm.sh/helm/v4/pkg/release"
+)
+
+func TestParseHook(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		annotations map[string]string
+		expect      *HookConfig
+		unknown     string
+		wantErr     string
+	}{
+		{
+			name:        "not a hook",
+			annotations: map[string]string{ResourcePolicy: KeepPolicy},
+		},
+		{
+			name: "all annotations",
+			annotations: map[string]string{
+				Hook:             "pre-install, Post-Upgrade",
+				HookWeight:       "-5",
+				HookDeletePolicy: "before-hook-creation,HOOK-SUCCEEDED",
+			},
+			expect: &HookConfig{
+				Events:         []release.HookEvent{release.HookPreInstall, release.HookPostUpgrade},
+				Weight:         -5,
+				DeletePolicies: []release.HookDeletePolicy{release.HookBeforeHookCreation, release.HookSucceeded},
+			},
+		},
+		{
+			name:        "helm 2 test hook",
+			annotations: map[string]string{Hook: "test-success", HookWeight: "heavy"},
+			expect: &HookConfig{
+				Events:         []release.HookEvent{release.HookTest},
+				DeletePolicies: []release.HookDeletePolicy{},
+			},
+		},
+		{
+			name:        "uninstall failure hook with timeout",
+			annotations: map[string]string{Hook: "post-uninstall-failed", HookTimeout: "90s"},
+			expect: &HookConfig{
+				Events:         []release.HookEvent{release.HookPostUninstallFailed},
+				DeletePolicies: []release.HookDeletePolicy{},
+				Timeout:        90 * time.Second,
+			},
+		},
+		{
+			name:        "invalid timeout",
+			annotations: map[string]string{Hook: "pre-delete", HookTimeout: "-1m"},
+			wantErr:     `invalid helm.sh/hook-timeout annotation "-1m"`,
+		},
+		{
+			name:        "unknown event",
+			annotations: map[string]string{Hook: "pre-install,post-launch"},
+			unknown:     "post-launch",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			hook, err := ParseHook(tt.annotations)
+			if tt.unknown != "" {
+				uerr, ok := err.(*UnknownHookError)
+				if !ok {
+					t.Fatalf("expected an *UnknownHookError, got %v", err)
+				}
+				if uerr.Event != tt.unknown {
+					t.Errorf("expected unknown event %q, got %q", tt.unknown, uerr.Event)
+				}
+				return
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected an error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(hook, tt.expect) {
+				t.Errorf("expected %+v, got %+v", tt.expect, hook)
+			}
+		})
+	}
+}
+
+func TestParseManifest(t *testing.T) {
+	a, err := ParseManifest(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: example
+  annotations:
+    helm.sh/resource-policy: " Keep "
+    helm.sh/recreate-on-immutable-change: "true"
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Hook != nil {
+		t.Errorf("expected no hook, got %+v", a.Hook)
+	}
+	if !a.Keep() {
+		t.Errorf("expected the resource to be kept, got policy %q", a.ResourcePolicy)
+	}
+	if !a.RecreateOnImmutableChange {
+		t.Error("expected the resource to be recreated on immutable changes")
+	}
+
+	a, err = ParseManifest("kind: Job\nmetadata:\n  name: example\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Hook != nil || a.Keep() || a.RecreateOnImmutableChange {
+		t.Errorf("expected no Helm annotations, got %+v", a)
+	}
+}
+
+func TestParseWait(t *testing.T) {
+	if !ParseNoWait(map[string]string{NoWait: "true"}) || ParseNoWait(map[string]string{NoWait: "yes"}) {
+		t.Error("expected only a true boolean to exclude the resource from waiting")
+	}
+
+	timeout, err := ParseWaitTimeout(map[string]string{WaitTimeout: "90s"})
+	if err != nil || timeout != 90*time.Second {
+		t.Errorf("expected a timeout of 90s, got %v, %v", timeout, err)
+	}
+	if timeout, err := ParseWaitTimeout(nil); err != nil || timeout != 0 {
+		t.Errorf("expected no timeout, got %v, %v", timeout, err)
+	}
+	for _, value := range []string{"10", "-1m", "soon"} {
+		if _, err := ParseWaitTimeout(map[string]string{WaitTimeout: value}); err == nil {
+			t.Errorf("expected an error for timeout %q", value)
+		}
+	}
+}
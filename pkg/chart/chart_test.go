@@ -209,3 +209,77 @@ func TestCRDObjects(t *testing.T) {
 	crds := chrt.CRDObjects()
 	is.Equal(expected, crds)
 }
+
+func TestCRDDefinitions(t *testing.T) {
+	crd := CRD{
+		Name:     "crds/crds.yaml",
+		Filename: "crds/crds.yaml",
+		File: &File{Name: "crds/crds.yaml", Data: []byte(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+    plural: widgets
+  scope: Cluster
+  versions:
+  - name: v1beta1
+    served: true
+  - name: v1
+    served: true
+    storage: true
+  - name: v1alpha1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: not-a-crd
+---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: gadgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Gadget
+    plural: gadgets
+  scope: Namespaced
+  version: v1
+`)},
+	}
+
+	is := assert.New(t)
+	defs, err := crd.Definitions()
+	is.NoError(err)
+	is.Equal([]CRDDefinition{{
+		Name:   "widgets.example.com",
+		Group:  "example.com",
+		Kind:   "Widget",
+		Plural: "widgets",
+		Scope:  "Cluster",
+		Versions: []CRDVersion{
+			{Name: "v1beta1", Served: true},
+			{Name: "v1", Served: true, Storage: true},
+			{Name: "v1alpha1"},
+		},
+	}, {
+		Name:     "gadgets.example.com",
+		Group:    "example.com",
+		Kind:     "Gadget",
+		Plural:   "gadgets",
+		Scope:    "Namespaced",
+		Versions: []CRDVersion{{Name: "v1", Served: true, Storage: true}},
+	}}, defs)
+
+	is.False(defs[0].Namespaced())
+	is.True(defs[1].Namespaced())
+	is.Equal("v1", defs[0].StorageVersion())
+	is.Equal([]string{"example.com/v1beta1", "example.com/v1beta1/Widget", "example.com/v1", "example.com/v1/Widget"}, defs[0].APIVersions())
+
+	crd.File.Data = []byte("kind: [")
+	_, err = crd.Definitions()
+	is.ErrorContains(err, "unable to parse CRD file crds/crds.yaml")
+}
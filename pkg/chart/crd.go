@@ -0,0 +1,145 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chart
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// crdDocumentSeparator splits the YAML documents of a CRD file.
+var crdDocumentSeparator = regexp.MustCompile("(?:^|\\s*\n)---\\s*")
+
+// CRDDefinition is the structure of a CustomResourceDefinition of a CRD file.
+type CRDDefinition struct {
+	// Name is the name of the CustomResourceDefinition, e.g.
+	// "widgets.example.com".
+	Name string
+	// Group is the API group of the custom resources.
+	Group string
+	// Kind is the kind of the custom resources.
+	Kind string
+	// Plural is the plural name of the custom resources, as used in the
+	// resource paths.
+	Plural string
+	// Scope is either "Namespaced" or "Cluster".
+	Scope string
+	// Versions are the versions of the custom resources, in the order of the
+	// definition.
+	Versions []CRDVersion
+}
+
+// CRDVersion is a version of a CustomResourceDefinition.
+type CRDVersion struct {
+	Name string
+	// Served is true when the version is served by the API.
+	Served bool
+	// Storage is true for the version the custom resources are stored as.
+	Storage bool
+}
+
+// Namespaced reports whether the custom resources are namespaced.
+func (d CRDDefinition) Namespaced() bool {
+	return d.Scope != "Cluster"
+}
+
+// StorageVersion returns the version the custom resources are stored as.
+func (d CRDDefinition) StorageVersion() string {
+	for _, v := range d.Versions {
+		if v.Storage {
+			return v.Name
+		}
+	}
+	return ""
+}
+
+// APIVersions returns the API versions provided by the definition in the
+// format of the capabilities of the cluster: "group/version" and
+// "group/version/Kind" for every served version.
+func (d CRDDefinition) APIVersions() []string {
+	var versions []string
+	for _, v := range d.Versions {
+		if v.Served {
+			gv := d.Group + "/" + v.Name
+			versions = append(versions, gv, gv+"/"+d.Kind)
+		}
+	}
+	return versions
+}
+
+// crdDocument is the part of a CustomResourceDefinition read by Definitions,
+// for both apiextensions.k8s.io/v1 and v1beta1.
+type crdDocument struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Group string `json:"group"`
+		Names struct {
+			Kind   string `json:"kind"`
+			Plural string `json:"plural"`
+		} `json:"names"`
+		Scope string `json:"scope"`
+		// Version is the single version of a v1beta1 definition.
+		Version  string `json:"version"`
+		Versions []struct {
+			Name    string `json:"name"`
+			Served  bool   `json:"served"`
+			Storage bool   `json:"storage"`
+		} `json:"versions"`
+	} `json:"spec"`
+}
+
+// Definitions parses the CustomResourceDefinitions of the CRD file. Other
+// objects of the file are ignored.
+func (c CRD) Definitions() ([]CRDDefinition, error) {
+	if c.File == nil {
+		return nil, nil
+	}
+	var defs []CRDDefinition
+	for _, doc := range crdDocumentSeparator.Split(string(c.File.Data), -1) {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		var d crdDocument
+		if err := yaml.Unmarshal([]byte(doc), &d); err != nil {
+			return nil, fmt.Errorf("unable to parse CRD file %s: %w", c.Filename, err)
+		}
+		if d.Kind != "CustomResourceDefinition" {
+			continue
+		}
+		def := CRDDefinition{
+			Name:   d.Metadata.Name,
+			Group:  d.Spec.Group,
+			Kind:   d.Spec.Names.Kind,
+			Plural: d.Spec.Names.Plural,
+			Scope:  d.Spec.Scope,
+		}
+		for _, v := range d.Spec.Versions {
+			def.Versions = append(def.Versions, CRDVersion{Name: v.Name, Served: v.Served, Storage: v.Storage})
+		}
+		if len(def.Versions) == 0 && d.Spec.Version != "" {
+			def.Versions = []CRDVersion{{Name: d.Spec.Version, Served: true, Storage: true}}
+		}
+		defs = append(defs, def)
+	}
+	return defs, nil
+}
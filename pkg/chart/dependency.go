@@ -40,6 +40,10 @@ type Dependency struct {
 	Condition string `json:"condition,omitempty" yaml:"condition,omitempty"`
 	// Tags can be used to group charts for enabling/disabling together
 	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// EnabledWhen is an expression over the parent chart's values used for
+	// enabling/disabling charts (e.g. .Values.global.env == "prod" && .Values.featureX).
+	// When set, it takes precedence over both Condition and Tags.
+	EnabledWhen string `json:"enabledWhen,omitempty" yaml:"enabledWhen,omitempty"`
 	// Enabled bool determines if chart should be loaded
 	Enabled bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
 	// ImportValues holds the mapping of source values to parent key to be imported. Each item can be a
@@ -60,6 +64,7 @@ func (d *Dependency) Validate() error {
 	d.Version = sanitizeString(d.Version)
 	d.Repository = sanitizeString(d.Repository)
 	d.Condition = sanitizeString(d.Condition)
+	d.EnabledWhen = sanitizeString(d.EnabledWhen)
 	for i := range d.Tags {
 		d.Tags[i] = sanitizeString(d.Tags[i])
 	}
@@ -79,4 +84,8 @@ type Lock struct {
 	Digest string `json:"digest"`
 	// Dependencies is the list of dependencies that this lock file has locked.
 	Dependencies []*Dependency `json:"dependencies"`
+	// Digests are the digests of the contents of the dependencies in charts/
+	// by name and version, e.g. "mariadb-11.4.2", used to detect dependencies
+	// modified after they were fetched.
+	Digests map[string]string `json:"digests,omitempty"`
 }
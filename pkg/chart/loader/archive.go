@@ -111,6 +111,15 @@ func isGZipApplication(data []byte) bool {
 // performs important path security checks and should always be used before
 // expanding a tarball
 func LoadArchiveFiles(in io.Reader) ([]*BufferedFile, error) {
+	return LoadArchiveFilesMatching(in, nil)
+}
+
+// LoadArchiveFilesMatching reads in the files of an archive whose names, e.g.
+// "templates/service.yaml", are matched by match, so that a few files can be
+// read from a large chart without holding all of it in memory. The paths of
+// all the files are checked as by LoadArchiveFiles. All files are read when
+// match is nil.
+func LoadArchiveFilesMatching(in io.Reader, match func(name string) bool) ([]*BufferedFile, error) {
 	unzipped, err := gzip.NewReader(in)
 	if err != nil {
 		return nil, err
@@ -118,6 +127,7 @@ func LoadArchiveFiles(in io.Reader) ([]*BufferedFile, error) {
 	defer unzipped.Close()
 
 	files := []*BufferedFile{}
+	found := false
 	tr := tar.NewReader(unzipped)
 	for {
 		b := bytes.NewBuffer(nil)
@@ -178,6 +188,11 @@ func LoadArchiveFiles(in io.Reader) ([]*BufferedFile, error) {
 			return nil, errors.New("chart yaml not in base directory")
 		}
 
+		found = true
+		if match != nil && !match(n) {
+			continue
+		}
+
 		if _, err := io.Copy(b, tr); err != nil {
 			return nil, err
 		}
@@ -188,7 +203,7 @@ func LoadArchiveFiles(in io.Reader) ([]*BufferedFile, error) {
 		b.Reset()
 	}
 
-	if len(files) == 0 {
+	if !found {
 		return nil, errors.New("no files in chart archive")
 	}
 	return files, nil
@@ -21,7 +21,9 @@ import (
 	"encoding/json"
 	"log"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -73,6 +75,7 @@ type BufferedFile struct {
 func LoadFiles(files []*BufferedFile) (*chart.Chart, error) {
 	c := new(chart.Chart)
 	subcharts := make(map[string][]*BufferedFile)
+	var valuesDir []*BufferedFile
 
 	// do not rely on assumed ordering of files in the chart and crash
 	// if Chart.yaml was not coming early enough to initialize metadata
@@ -113,6 +116,11 @@ func LoadFiles(files []*BufferedFile) (*chart.Chart, error) {
 			}
 		case f.Name == "values.schema.json":
 			c.Schema = f.Data
+		case IsValuesDirFile(f.Name):
+			// The files are kept with the other files so that they are
+			// packaged with the chart.
+			valuesDir = append(valuesDir, f)
+			c.Files = append(c.Files, &chart.File{Name: f.Name, Data: f.Data})
 
 		// Deprecated: requirements.yaml is deprecated use Chart.yaml.
 		// We will handle it for you because we are nice people
@@ -161,6 +169,10 @@ func LoadFiles(files []*BufferedFile) (*chart.Chart, error) {
 		}
 	}
 
+	if err := loadValuesDir(c, valuesDir); err != nil {
+		return c, err
+	}
+
 	if c.Metadata == nil {
 		return c, errors.New("Chart.yaml file is missing")
 	}
@@ -205,3 +217,54 @@ func LoadFiles(files []*BufferedFile) (*chart.Chart, error) {
 
 	return c, nil
 }
+
+// ValuesDir is the directory of a chart holding the files of default values
+// merged over values.yaml.
+const ValuesDir = "values.d"
+
+// IsValuesDirFile reports whether a file of a chart is a values file of
+// ValuesDir. Its subdirectories are ignored.
+func IsValuesDirFile(name string) bool {
+	ext := path.Ext(name)
+	return path.Dir(name) == ValuesDir && (ext == ".yaml" || ext == ".yml")
+}
+
+// loadValuesDir merges the values files of ValuesDir over the values of
+// values.yaml in the lexical order of their names, so that a large values.yaml
+// can be split into several files, e.g. "values.d/10-image.yaml" and
+// "values.d/20-ingress.yaml". Tables are merged while the other values of a
+// later file replace those of the earlier ones, including a null value, as if
+// all the files were a single values.yaml.
+func loadValuesDir(c *chart.Chart, files []*BufferedFile) error {
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	for _, f := range files {
+		vals := make(map[string]interface{})
+		if err := yaml.Unmarshal(f.Data, &vals, func(d *json.Decoder) *json.Decoder {
+			d.UseNumber()
+			return d
+		}); err != nil {
+			return errors.Wrapf(err, "cannot load %s", f.Name)
+		}
+		c.Values = mergeValues(c.Values, vals)
+	}
+	return nil
+}
+
+func mergeValues(a, b map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(a))
+	for k, v := range a {
+		out[k] = v
+	}
+	for k, v := range b {
+		if v, ok := v.(map[string]interface{}); ok {
+			if bv, ok := out[k]; ok {
+				if bv, ok := bv.(map[string]interface{}); ok {
+					out[k] = mergeValues(bv, v)
+					continue
+				}
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
@@ -376,6 +376,44 @@ icon: https://example.com/64x64.png
 
 }
 
+func TestLoadFilesValuesDir(t *testing.T) {
+	files := []*BufferedFile{
+		{Name: "Chart.yaml", Data: []byte("apiVersion: v2\nname: frobnitz\nversion: 1.2.3\n")},
+		{Name: "values.d/20-ingress.yaml", Data: []byte("ingress:\n  enabled: true\nimage:\n  tag: \"2.0\"\n")},
+		{Name: "values.yaml", Data: []byte("image:\n  repository: nginx\n  tag: \"1.0\"\nreplicas: 1\ndebug: true\n")},
+		{Name: "values.d/10-image.yml", Data: []byte("image:\n  tag: \"1.5\"\ndebug: null\n")},
+		{Name: "values.d/README.md", Data: []byte("not values")},
+		{Name: "values.d/nested/ignored.yaml", Data: []byte("ignored: true")},
+	}
+
+	c, err := LoadFiles(files)
+	if err != nil {
+		t.Fatalf("Expected good files to be loaded, got %v", err)
+	}
+
+	image := c.Values["image"].(map[string]interface{})
+	if image["repository"] != "nginx" || image["tag"] != "2.0" {
+		t.Errorf("Expected the image of values.yaml with the tag of the last values file, got %v", image)
+	}
+	if _, ok := c.Values["ingress"]; !ok {
+		t.Error("Expected the values of values.d/20-ingress.yaml to be loaded")
+	}
+	if debug, ok := c.Values["debug"]; !ok || debug != nil {
+		t.Errorf("Expected debug to be set to null, got %v", debug)
+	}
+	if _, ok := c.Values["ignored"]; ok {
+		t.Error("Expected the subdirectories of values.d to be ignored")
+	}
+	if len(c.Files) != 4 {
+		t.Errorf("Expected the files of values.d to be kept with the chart files, got %d files", len(c.Files))
+	}
+
+	files[1].Data = []byte("not: [valid")
+	if _, err := LoadFiles(files); err == nil || !strings.Contains(err.Error(), "cannot load values.d/20-ingress.yaml") {
+		t.Errorf("Expected an error loading values.d/20-ingress.yaml, got %v", err)
+	}
+}
+
 // Packaging the chart on a Windows machine will produce an
 // archive that has \\ as delimiters. Test that we support these archives
 func TestLoadFileBackslash(t *testing.T) {
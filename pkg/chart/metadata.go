@@ -18,11 +18,15 @@ package chart
 import (
 	"path/filepath"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/Masterminds/semver/v3"
 )
 
+// SupportedUntilFormat is the layout of the SupportedUntil date of a chart.
+const SupportedUntilFormat = "2006-01-02"
+
 // Maintainer describes a Chart maintainer.
 type Maintainer struct {
 	// Name is a user name or organization name
@@ -72,15 +76,25 @@ type Metadata struct {
 	AppVersion string `json:"appVersion,omitempty"`
 	// Whether or not this chart is deprecated
 	Deprecated bool `json:"deprecated,omitempty"`
+	// SupportedUntil is the last day, as YYYY-MM-DD, the chart is supported
+	// by its maintainers.
+	SupportedUntil string `json:"supportedUntil,omitempty"`
 	// Annotations are additional mappings uninterpreted by Helm,
 	// made available for inspection by other applications.
 	Annotations map[string]string `json:"annotations,omitempty"`
 	// KubeVersion is a SemVer constraint specifying the version of Kubernetes required.
 	KubeVersion string `json:"kubeVersion,omitempty"`
+	// HelmVersion is a SemVer constraint specifying the version of Helm required.
+	HelmVersion string `json:"helmVersion,omitempty"`
 	// Dependencies are a list of dependencies for a chart.
 	Dependencies []*Dependency `json:"dependencies,omitempty"`
 	// Specifies the chart type: application or library
 	Type string `json:"type,omitempty"`
+	// Requirements the cluster must meet before the chart is installed or upgraded.
+	Requirements *Requirements `json:"requirements,omitempty"`
+	// ValuesFrom are sources of values merged at install and upgrade time,
+	// when enabled by the user.
+	ValuesFrom []*ValuesSource `json:"valuesFrom,omitempty"`
 }
 
 // Validate checks the metadata for known issues and sanitizes string
@@ -98,6 +112,8 @@ func (md *Metadata) Validate() error {
 	md.Tags = sanitizeString(md.Tags)
 	md.AppVersion = sanitizeString(md.AppVersion)
 	md.KubeVersion = sanitizeString(md.KubeVersion)
+	md.HelmVersion = sanitizeString(md.HelmVersion)
+	md.SupportedUntil = sanitizeString(md.SupportedUntil)
 	for i := range md.Sources {
 		md.Sources[i] = sanitizeString(md.Sources[i])
 	}
@@ -122,6 +138,16 @@ func (md *Metadata) Validate() error {
 	if !isValidSemver(md.Version) {
 		return ValidationErrorf("chart.metadata.version %q is invalid", md.Version)
 	}
+	if md.HelmVersion != "" {
+		if _, err := semver.NewConstraint(md.HelmVersion); err != nil {
+			return ValidationErrorf("chart.metadata.helmVersion %q is invalid: %s", md.HelmVersion, err)
+		}
+	}
+	if md.SupportedUntil != "" {
+		if _, err := time.Parse(SupportedUntilFormat, md.SupportedUntil); err != nil {
+			return ValidationErrorf("chart.metadata.supportedUntil %q is invalid, expected a date as YYYY-MM-DD", md.SupportedUntil)
+		}
+	}
 	if !isValidChartType(md.Type) {
 		return ValidationError("chart.metadata.type must be application or library")
 	}
@@ -132,6 +158,16 @@ func (md *Metadata) Validate() error {
 		}
 	}
 
+	if err := md.Requirements.Validate(); err != nil {
+		return err
+	}
+
+	for _, s := range md.ValuesFrom {
+		if err := s.Validate(); err != nil {
+			return err
+		}
+	}
+
 	// Aliases need to be validated here to make sure that the alias name does
 	// not contain any illegal characters.
 	dependencies := map[string]*Dependency{}
@@ -151,6 +187,19 @@ func (md *Metadata) Validate() error {
 	return nil
 }
 
+// EndOfLife reports whether the chart is no longer supported at now, i.e.
+// now is after the day of SupportedUntil in UTC. An invalid date is ignored.
+func (md *Metadata) EndOfLife(now time.Time) bool {
+	if md.SupportedUntil == "" {
+		return false
+	}
+	until, err := time.Parse(SupportedUntilFormat, md.SupportedUntil)
+	if err != nil {
+		return false
+	}
+	return !now.Before(until.AddDate(0, 0, 1))
+}
+
 func isValidChartType(in string) bool {
 	switch in {
 	case "", "application", "library":
@@ -17,6 +17,7 @@ package chart
 
 import (
 	"testing"
+	"time"
 )
 
 func TestValidate(t *testing.T) {
@@ -177,6 +178,63 @@ func TestValidate(t *testing.T) {
 			&Metadata{APIVersion: "v2", Name: "test", Version: "1.2.3.4"},
 			ValidationError("chart.metadata.version \"1.2.3.4\" is invalid"),
 		},
+		{
+			"requirements with invalid api",
+			&Metadata{APIVersion: "v2", Name: "test", Version: "1.0", Requirements: &Requirements{APIs: []string{"a/b/c/d"}}},
+			ValidationError("requirements.apis entry \"a/b/c/d\" is invalid, expected a group version or group version and kind"),
+		},
+		{
+			"requirements with negative nodes",
+			&Metadata{APIVersion: "v2", Name: "test", Version: "1.0", Requirements: &Requirements{MinNodes: -1}},
+			ValidationError("requirements.minNodes must not be negative"),
+		},
+		{
+			"requirements valid",
+			&Metadata{APIVersion: "v2", Name: "test", Version: "1.0", Requirements: &Requirements{
+				APIs:          []string{"v1", "monitoring.coreos.com/v1/ServiceMonitor"},
+				ServerVersion: ">=1.27.0-0",
+				MinNodes:      3,
+			}},
+			nil,
+		},
+		{
+			"valuesFrom without source",
+			&Metadata{APIVersion: "v2", Name: "test", Version: "1.0", ValuesFrom: []*ValuesSource{{Key: "values.yaml"}}},
+			ValidationError("valuesFrom entries must set exactly one of configMap, secret and url"),
+		},
+		{
+			"valuesFrom with two sources",
+			&Metadata{APIVersion: "v2", Name: "test", Version: "1.0", ValuesFrom: []*ValuesSource{{ConfigMap: "a", Secret: "b"}}},
+			ValidationError("valuesFrom entries must set exactly one of configMap, secret and url"),
+		},
+		{
+			"valuesFrom url with key",
+			&Metadata{APIVersion: "v2", Name: "test", Version: "1.0", ValuesFrom: []*ValuesSource{{URL: "https://example.com/v.yaml", Key: "x"}}},
+			ValidationError("valuesFrom entry \"https://example.com/v.yaml\" must not set a key"),
+		},
+		{
+			"valuesFrom invalid failure policy",
+			&Metadata{APIVersion: "v2", Name: "test", Version: "1.0", ValuesFrom: []*ValuesSource{{Secret: "a", FailurePolicy: "Retry"}}},
+			ValidationError("valuesFrom failurePolicy \"Retry\" is invalid, expected Fail or Ignore"),
+		},
+		{
+			"valuesFrom valid",
+			&Metadata{APIVersion: "v2", Name: "test", Version: "1.0", ValuesFrom: []*ValuesSource{
+				{ConfigMap: "env-values", Environments: []string{"prod"}},
+				{URL: "oci://registry.example.com/values:prod", FailurePolicy: ValuesFailurePolicyIgnore},
+			}},
+			nil,
+		},
+		{
+			"invalid supportedUntil",
+			&Metadata{APIVersion: "v2", Name: "test", Version: "1.0", SupportedUntil: "31/12/2030"},
+			ValidationError("chart.metadata.supportedUntil \"31/12/2030\" is invalid, expected a date as YYYY-MM-DD"),
+		},
+		{
+			"valid supportedUntil",
+			&Metadata{APIVersion: "v2", Name: "test", Version: "1.0", SupportedUntil: "2030-12-31"},
+			nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -199,3 +257,18 @@ func TestValidate_sanitize(t *testing.T) {
 		t.Fatal("maintainer name was not sanitized")
 	}
 }
+
+func TestEndOfLife(t *testing.T) {
+	md := &Metadata{SupportedUntil: "2030-12-31"}
+	for now, expect := range map[time.Time]bool{
+		time.Date(2030, 12, 31, 23, 59, 0, 0, time.UTC): false,
+		time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC):     true,
+	} {
+		if eol := md.EndOfLife(now); eol != expect {
+			t.Errorf("expected EndOfLife at %s to be %t", now, expect)
+		}
+	}
+	if (&Metadata{}).EndOfLife(time.Now()) {
+		t.Error("expected a chart without supportedUntil to be supported")
+	}
+}
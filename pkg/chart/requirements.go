@@ -0,0 +1,69 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chart
+
+import (
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Requirements describes the cluster capabilities a chart needs.
+//
+// Unlike KubeVersion, which is checked against the capabilities used for
+// rendering, requirements are always evaluated against the live cluster
+// before any resources are created.
+type Requirements struct {
+	// APIs that must be served by the cluster. Each entry is either a group
+	// version (e.g. "networking.k8s.io/v1") or a group version and kind
+	// (e.g. "monitoring.coreos.com/v1/ServiceMonitor").
+	APIs []string `json:"apis,omitempty"`
+	// ServerVersion is a SemVer constraint the Kubernetes server version must satisfy.
+	ServerVersion string `json:"serverVersion,omitempty"`
+	// MinNodes is the minimum number of ready, schedulable nodes.
+	MinNodes int `json:"minNodes,omitempty"`
+	// DefaultStorageClass requires the cluster to have a default StorageClass.
+	DefaultStorageClass bool `json:"defaultStorageClass,omitempty"`
+	// StorageClasses lists StorageClasses that must exist.
+	StorageClasses []string `json:"storageClasses,omitempty"`
+}
+
+// Validate checks the requirements for known issues and sanitizes string
+// characters.
+func (r *Requirements) Validate() error {
+	if r == nil {
+		return nil
+	}
+	for i, api := range r.APIs {
+		r.APIs[i] = sanitizeString(api)
+		if parts := strings.Split(r.APIs[i], "/"); r.APIs[i] == "" || len(parts) > 3 {
+			return ValidationErrorf("requirements.apis entry %q is invalid, expected a group version or group version and kind", api)
+		}
+	}
+	r.ServerVersion = sanitizeString(r.ServerVersion)
+	if r.ServerVersion != "" {
+		if _, err := semver.NewConstraint(r.ServerVersion); err != nil {
+			return ValidationErrorf("requirements.serverVersion %q is invalid: %s", r.ServerVersion, err)
+		}
+	}
+	if r.MinNodes < 0 {
+		return ValidationError("requirements.minNodes must not be negative")
+	}
+	for i := range r.StorageClasses {
+		r.StorageClasses[i] = sanitizeString(r.StorageClasses[i])
+	}
+	return nil
+}
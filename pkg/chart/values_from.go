@@ -0,0 +1,100 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chart
+
+// Failure policies of a ValuesSource.
+const (
+	// ValuesFailurePolicyFail fails the install or upgrade when the source
+	// cannot be read.
+	ValuesFailurePolicyFail = "Fail"
+	// ValuesFailurePolicyIgnore skips the source when it cannot be read.
+	ValuesFailurePolicyIgnore = "Ignore"
+)
+
+// DefaultValuesKey is the key of the values in the ConfigMaps and Secrets of
+// values sources.
+const DefaultValuesKey = "values.yaml"
+
+// ValuesSource is a source of values declared by a chart with valuesFrom.
+// Values sources are read at install and upgrade time and merged in order, so
+// that a later source overrides an earlier one. They override the values of
+// the chart and are overridden by the values supplied by the user.
+//
+// Exactly one of ConfigMap, Secret and URL must be set.
+type ValuesSource struct {
+	// ConfigMap is the name of a ConfigMap in the namespace of the release.
+	ConfigMap string `json:"configMap,omitempty"`
+	// Secret is the name of a Secret in the namespace of the release.
+	Secret string `json:"secret,omitempty"`
+	// URL is the URL of a values file, e.g. an https or oci URL.
+	URL string `json:"url,omitempty"`
+	// Key is the key of the values file in the ConfigMap or Secret. Defaults
+	// to DefaultValuesKey.
+	Key string `json:"key,omitempty"`
+	// Environments restricts the source to these environments. Empty means
+	// all environments.
+	Environments []string `json:"environments,omitempty"`
+	// FailurePolicy is Fail (the default) or Ignore.
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+}
+
+// Validate checks the values source for known issues and sanitizes string
+// characters.
+func (s *ValuesSource) Validate() error {
+	if s == nil {
+		return ValidationError("valuesFrom entries must not be empty")
+	}
+	s.ConfigMap = sanitizeString(s.ConfigMap)
+	s.Secret = sanitizeString(s.Secret)
+	s.URL = sanitizeString(s.URL)
+	s.Key = sanitizeString(s.Key)
+	for i := range s.Environments {
+		s.Environments[i] = sanitizeString(s.Environments[i])
+	}
+
+	set := 0
+	for _, v := range []string{s.ConfigMap, s.Secret, s.URL} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return ValidationError("valuesFrom entries must set exactly one of configMap, secret and url")
+	}
+	if s.URL != "" && s.Key != "" {
+		return ValidationErrorf("valuesFrom entry %q must not set a key", s.URL)
+	}
+	switch s.FailurePolicy {
+	case "", ValuesFailurePolicyFail, ValuesFailurePolicyIgnore:
+	default:
+		return ValidationErrorf("valuesFrom failurePolicy %q is invalid, expected Fail or Ignore", s.FailurePolicy)
+	}
+	return nil
+}
+
+// AppliesTo reports whether the source applies to an environment.
+func (s *ValuesSource) AppliesTo(environment string) bool {
+	if len(s.Environments) == 0 {
+		return true
+	}
+	for _, e := range s.Environments {
+		if e == environment {
+			return true
+		}
+	}
+	return false
+}
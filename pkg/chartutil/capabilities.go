@@ -17,7 +17,9 @@ package chartutil
 
 import (
 	"fmt"
+	"path"
 	"strconv"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -57,13 +59,20 @@ type Capabilities struct {
 	APIVersions VersionSet
 	// HelmVersion is the build information for this helm version
 	HelmVersion helmversion.BuildInfo
+	// APIResources are the resource kinds served by the cluster. They are
+	// empty when Helm does not talk to a cluster, e.g. for 'helm template'.
+	APIResources APIResources
+	// Features are the optional features detected in the cluster.
+	Features Features
 }
 
 func (capabilities *Capabilities) Copy() *Capabilities {
 	return &Capabilities{
-		KubeVersion: capabilities.KubeVersion,
-		APIVersions: capabilities.APIVersions,
-		HelmVersion: capabilities.HelmVersion,
+		KubeVersion:  capabilities.KubeVersion,
+		APIVersions:  capabilities.APIVersions,
+		HelmVersion:  capabilities.HelmVersion,
+		APIResources: capabilities.APIResources,
+		Features:     capabilities.Features,
 	}
 }
 
@@ -110,6 +119,83 @@ func (v VersionSet) Has(apiVersion string) bool {
 	return false
 }
 
+// APIResource describes a resource kind served by the cluster.
+type APIResource struct {
+	// GroupVersion is the API version of the resource, e.g. "apps/v1".
+	GroupVersion string
+	// Kind is the kind of the resource, e.g. "Deployment".
+	Kind string
+	// Name is the plural name of the resource, e.g. "deployments".
+	Name string
+	// Namespaced reports whether the resource is namespaced.
+	Namespaced bool
+	// Verbs are the verbs the resource supports, e.g. "get" and "patch".
+	Verbs []string
+	// ShortNames are the short names of the resource, e.g. "deploy".
+	ShortNames []string
+}
+
+// APIResources are the resource kinds served by a cluster, in the order of
+// discovery, which lists the preferred versions first.
+type APIResources []APIResource
+
+// Get returns the resource of a kind at an API version, or nil when the
+// cluster does not serve it.
+//
+//	resources.Get("apps/v1/Deployment")
+func (r APIResources) Get(apiVersionKind string) *APIResource {
+	for i := range r {
+		if path.Join(r[i].GroupVersion, r[i].Kind) == apiVersionKind {
+			return &r[i]
+		}
+	}
+	return nil
+}
+
+// Find returns the first resource whose name, kind or short name matches the
+// name case-insensitively, like kubectl resolves resource names, or nil.
+//
+//	resources.Find("deploy")
+func (r APIResources) Find(name string) *APIResource {
+	for i := range r {
+		if strings.EqualFold(r[i].Name, name) || strings.EqualFold(r[i].Kind, name) {
+			return &r[i]
+		}
+		for _, short := range r[i].ShortNames {
+			if strings.EqualFold(short, name) {
+				return &r[i]
+			}
+		}
+	}
+	return nil
+}
+
+// Features are optional features of a cluster. Clusters do not publish their
+// feature gates, so the features are detected from the Kubernetes version and
+// the served resources.
+type Features struct {
+	// ServerSideApply reports that the API server supports server-side
+	// apply, which is generally available since Kubernetes 1.22.
+	ServerSideApply bool
+	// ValidatingAdmissionPolicy reports that the cluster serves validating
+	// admission policies, which are written in CEL.
+	ValidatingAdmissionPolicy bool
+}
+
+// DetectFeatures detects the features of a cluster from its version and the
+// resources it serves.
+func DetectFeatures(kubeVersion KubeVersion, resources APIResources) Features {
+	var f Features
+	f.ServerSideApply = IsCompatibleRange(">= 1.22.0-0", kubeVersion.Version)
+	for _, r := range resources {
+		group, _, _ := strings.Cut(r.GroupVersion, "/")
+		if group == "admissionregistration.k8s.io" && r.Kind == "ValidatingAdmissionPolicy" {
+			f.ValidatingAdmissionPolicy = true
+		}
+	}
+	return f
+}
+
 func allKnownVersions() VersionSet {
 	// We should register the built in extension APIs as well so CRDs are
 	// supported in the default version set. This has caused problems with `helm
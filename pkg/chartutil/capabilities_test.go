@@ -34,6 +34,50 @@ func TestVersionSet(t *testing.T) {
 	}
 }
 
+func TestAPIResources(t *testing.T) {
+	resources := APIResources{
+		{GroupVersion: "v1", Kind: "Pod", Name: "pods", Namespaced: true, ShortNames: []string{"po"}},
+		{GroupVersion: "apps/v1", Kind: "Deployment", Name: "deployments", Namespaced: true, ShortNames: []string{"deploy"}},
+		{GroupVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Name: "clusterroles"},
+	}
+
+	if r := resources.Get("apps/v1/Deployment"); r == nil || r.Name != "deployments" {
+		t.Errorf("Expected to get deployments, got %+v", r)
+	}
+	if r := resources.Get("v1/Pod"); r == nil || !r.Namespaced {
+		t.Errorf("Expected to get namespaced pods, got %+v", r)
+	}
+	if r := resources.Get("apps/v1beta1/Deployment"); r != nil {
+		t.Errorf("Expected no resource for apps/v1beta1, got %+v", r)
+	}
+
+	for _, name := range []string{"deploy", "Deployments", "deployment"} {
+		if r := resources.Find(name); r == nil || r.Kind != "Deployment" {
+			t.Errorf("Expected to find Deployment by %q, got %+v", name, r)
+		}
+	}
+	if r := resources.Find("clusterrole"); r == nil || r.Namespaced {
+		t.Errorf("Expected to find the cluster-scoped ClusterRole, got %+v", r)
+	}
+	if r := resources.Find("svc"); r != nil {
+		t.Errorf("Expected no resource for svc, got %+v", r)
+	}
+}
+
+func TestDetectFeatures(t *testing.T) {
+	f := DetectFeatures(KubeVersion{Version: "v1.21.4"}, nil)
+	if f.ServerSideApply || f.ValidatingAdmissionPolicy {
+		t.Errorf("Expected no features on Kubernetes 1.21, got %+v", f)
+	}
+
+	f = DetectFeatures(KubeVersion{Version: "v1.30.2-eks-1552ad0"}, APIResources{
+		{GroupVersion: "admissionregistration.k8s.io/v1", Kind: "ValidatingAdmissionPolicy", Name: "validatingadmissionpolicies"},
+	})
+	if !f.ServerSideApply || !f.ValidatingAdmissionPolicy {
+		t.Errorf("Expected all features on Kubernetes 1.30, got %+v", f)
+	}
+}
+
 func TestDefaultVersionSet(t *testing.T) {
 	if !DefaultVersionSet.Has("v1") {
 		t.Error("Expected core v1 version set")
@@ -16,7 +16,12 @@ limitations under the License.
 
 package chartutil
 
-import "github.com/Masterminds/semver/v3"
+import (
+	"github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/pkg/chart"
+)
 
 // IsCompatibleRange compares a version to a constraint.
 // It returns true if the version matches the constraint, and false in all other cases.
@@ -32,3 +37,43 @@ func IsCompatibleRange(constraint, ver string) bool {
 	}
 	return c.Check(sv)
 }
+
+// CheckHelmVersion checks that the Helm version satisfies the helmVersion
+// constraints of the chart and of its subcharts, returning an
+// ErrIncompatibleHelmVersion otherwise. An empty version checks the version
+// of this Helm, as used by the install and upgrade actions.
+//
+// The prerelease of the version is ignored, so that the release candidates
+// satisfy the constraints of the release.
+func CheckHelmVersion(ch *chart.Chart, version string) error {
+	if version == "" {
+		version = DefaultCapabilities.HelmVersion.Version
+	}
+	sv, err := semver.NewVersion(version)
+	if err != nil {
+		return errors.Wrapf(err, "invalid Helm version %q", version)
+	}
+	release, err := sv.SetPrerelease("")
+	if err != nil {
+		return err
+	}
+	return checkHelmVersion(ch, &release, version)
+}
+
+func checkHelmVersion(ch *chart.Chart, sv *semver.Version, version string) error {
+	if ch.Metadata != nil && ch.Metadata.HelmVersion != "" {
+		c, err := semver.NewConstraint(ch.Metadata.HelmVersion)
+		if err != nil {
+			return errors.Wrapf(err, "chart %q has an invalid helmVersion %q", ch.Name(), ch.Metadata.HelmVersion)
+		}
+		if !c.Check(sv) {
+			return ErrIncompatibleHelmVersion{Chart: ch.Name(), Constraint: ch.Metadata.HelmVersion, Version: version}
+		}
+	}
+	for _, dep := range ch.Dependencies() {
+		if err := checkHelmVersion(dep, sv, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
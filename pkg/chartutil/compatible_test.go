@@ -17,7 +17,11 @@ limitations under the License.
 // Package version represents the current version of the project.
 package chartutil
 
-import "testing"
+import (
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart"
+)
 
 func TestIsCompatibleRange(t *testing.T) {
 	tests := []struct {
@@ -41,3 +45,36 @@ func TestIsCompatibleRange(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckHelmVersion(t *testing.T) {
+	sub := &chart.Chart{Metadata: &chart.Metadata{Name: "sub", HelmVersion: ">= 4.1.0"}}
+	parent := &chart.Chart{Metadata: &chart.Metadata{Name: "parent", HelmVersion: ">= 3.10.0"}}
+	parent.SetDependencies(sub)
+
+	tests := []struct {
+		version string
+		err     string
+	}{
+		{"v4.1.0", ""},
+		{"v4.1.0-rc.1", ""},
+		{"v4.0.2", `chart "sub" requires Helm version >= 4.1.0, which is incompatible with Helm v4.0.2`},
+		{"v3.9.0", `chart "parent" requires Helm version >= 3.10.0, which is incompatible with Helm v3.9.0`},
+		{"four", `invalid Helm version "four": Invalid Semantic Version`},
+	}
+	for _, tt := range tests {
+		err := CheckHelmVersion(parent, tt.version)
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %s", tt.version, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("%s: expected error %q, got %v", tt.version, tt.err, err)
+		}
+	}
+
+	if err := CheckHelmVersion(&chart.Chart{Metadata: &chart.Metadata{Name: "any"}}, ""); err != nil {
+		t.Errorf("unexpected error for a chart without constraint: %s", err)
+	}
+}
@@ -20,11 +20,17 @@ import (
 	"strings"
 
 	"github.com/mitchellh/copystructure"
+	"github.com/pkg/errors"
 
 	"helm.sh/helm/v4/pkg/chart"
 )
 
 // ProcessDependencies checks through this chart's dependencies, processing accordingly.
+//
+// Whether a dependency is enabled is decided in order of increasing precedence:
+// tags, then condition, then enabledWhen. A condition only overrides tags when
+// its path resolves to a boolean, while an enabledWhen expression is always
+// authoritative when present.
 func ProcessDependencies(c *chart.Chart, v Values) error {
 	if err := processDependencyEnabled(c, v, ""); err != nil {
 		return err
@@ -32,6 +38,73 @@ func ProcessDependencies(c *chart.Chart, v Values) error {
 	return processDependencyImportValues(c, true)
 }
 
+// ScopeToSubchart narrows a chart down to one of its dependency subtrees.
+//
+// The path is a dot-separated list of dependency names (or aliases) leading
+// from c to the desired subchart, e.g. "subchart1.subcharta". The returned
+// chart is a copy of the subchart detached from its parent so that it renders
+// as a root chart. The returned values are the values the subchart would see
+// when rendered as part of c, including parent overrides and globals.
+//
+// ProcessDependencies should be called on c before scoping so that aliases,
+// conditions and tags have already been applied.
+func ScopeToSubchart(c *chart.Chart, v Values, path string) (*chart.Chart, Values, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, nil, errors.New("subchart path cannot be empty")
+	}
+
+	sub := c
+	for _, name := range parsePath(path) {
+		var found *chart.Chart
+		for _, dep := range sub.Dependencies() {
+			if dep.Name() == name {
+				found = dep
+				break
+			}
+		}
+		if found == nil {
+			return nil, nil, errors.Errorf("subchart %q not found in chart %q (it may be disabled or missing from the charts/ directory)", name, sub.ChartPath())
+		}
+		sub = found
+	}
+
+	cvals, err := CoalesceValues(c, v)
+	if err != nil {
+		return nil, nil, err
+	}
+	scoped, err := cvals.Table(path)
+	if err != nil {
+		scoped = Values{}
+	}
+
+	md := *sub.Metadata
+	out := &chart.Chart{
+		Raw:       sub.Raw,
+		Metadata:  &md,
+		Lock:      sub.Lock,
+		Templates: sub.Templates,
+		Values:    sub.Values,
+		Schema:    sub.Schema,
+		Files:     sub.Files,
+	}
+	out.SetDependencies(copyDependencies(sub)...)
+
+	return out, deepCopyMap(scoped), nil
+}
+
+// copyDependencies returns shallow copies of the dependency tree of a chart,
+// so that attaching them to another chart leaves the parents of the original
+// dependencies untouched.
+func copyDependencies(c *chart.Chart) []*chart.Chart {
+	var deps []*chart.Chart
+	for _, dep := range c.Dependencies() {
+		cp := *dep
+		cp.SetDependencies(copyDependencies(dep)...)
+		deps = append(deps, &cp)
+	}
+	return deps
+}
+
 // processDependencyConditions disables charts based on condition path value in values
 func processDependencyConditions(reqs []*chart.Dependency, cvals Values, cpath string) {
 	if reqs == nil {
@@ -58,6 +131,33 @@ func processDependencyConditions(reqs []*chart.Dependency, cvals Values, cpath s
 	}
 }
 
+// processDependencyExpressions disables charts based on enabledWhen expressions
+// evaluated against the values of the chart declaring the dependency.
+func processDependencyExpressions(reqs []*chart.Dependency, cvals Values, cpath string) error {
+	if reqs == nil {
+		return nil
+	}
+	scope := cvals
+	if p := strings.TrimSuffix(cpath, "."); p != "" {
+		t, err := cvals.Table(p)
+		if err != nil {
+			t = Values{}
+		}
+		scope = t
+	}
+	for _, r := range reqs {
+		if strings.TrimSpace(r.EnabledWhen) == "" {
+			continue
+		}
+		enabled, err := EvalEnabledExpression(r.EnabledWhen, scope)
+		if err != nil {
+			return errors.Wrapf(err, "dependency %s", r.Name)
+		}
+		r.Enabled = enabled
+	}
+	return nil
+}
+
 // processDependencyTags disables charts based on tags in values
 func processDependencyTags(reqs []*chart.Dependency, cvals Values) {
 	if reqs == nil {
@@ -161,6 +261,9 @@ Loop:
 	// flag dependencies as enabled/disabled
 	processDependencyTags(c.Metadata.Dependencies, cvals)
 	processDependencyConditions(c.Metadata.Dependencies, cvals, path)
+	if err := processDependencyExpressions(c.Metadata.Dependencies, cvals, path); err != nil {
+		return err
+	}
 	// make a map of charts to remove
 	rm := map[string]struct{}{}
 	for _, r := range c.Metadata.Dependencies {
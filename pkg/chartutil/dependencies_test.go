@@ -20,6 +20,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strconv"
+	"strings"
 	"testing"
 
 	"helm.sh/helm/v4/pkg/chart"
@@ -521,3 +522,150 @@ func TestDependentChartsWithSomeSubchartsSpecifiedInDependency(t *testing.T) {
 		t.Fatalf("expected 1 dependency specified in Chart.yaml, got %d", len(c.Metadata.Dependencies))
 	}
 }
+
+func TestScopeToSubchart(t *testing.T) {
+	c := loadChart(t, "testdata/subpop")
+	vals := map[string]interface{}{
+		"global": map[string]interface{}{"region": "eu"},
+		"subchart1": map[string]interface{}{
+			"service": map[string]interface{}{"type": "NodePort"},
+		},
+	}
+	if err := ProcessDependencies(c, vals); err != nil {
+		t.Fatalf("unexpected error processing dependencies: %s", err)
+	}
+
+	sub, scoped, err := ScopeToSubchart(c, vals, "subchart1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !sub.IsRoot() {
+		t.Error("expected scoped subchart to be a root chart")
+	}
+	if sub.Name() != "subchart1" {
+		t.Errorf("expected chart subchart1, got %s", sub.Name())
+	}
+	if got := len(sub.Dependencies()); got != 2 {
+		t.Errorf("expected scoped subchart to keep 2 dependencies, got %d", got)
+	}
+	if v, _ := scoped.PathValue("service.type"); v != "NodePort" {
+		t.Errorf("expected user override NodePort, got %v", v)
+	}
+	if v, _ := scoped.PathValue("service.name"); v != "nginx" {
+		t.Errorf("expected subchart default nginx, got %v", v)
+	}
+	if v, _ := scoped.PathValue("global.region"); v != "eu" {
+		t.Errorf("expected global value eu, got %v", v)
+	}
+
+	nested, _, err := ScopeToSubchart(c, vals, "subchart1.subcharta")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if nested.Name() != "subcharta" || !nested.IsRoot() {
+		t.Errorf("expected root chart subcharta, got %s", nested.ChartPath())
+	}
+
+	// Scoping must not re-parent the dependencies of the original chart.
+	for _, dep := range c.Dependencies()[0].Dependencies() {
+		if got := dep.ChartFullPath(); !strings.HasPrefix(got, "parentchart/charts/subchart1/") {
+			t.Errorf("expected the original dependency %s to keep its parents, got %s", dep.Name(), got)
+		}
+	}
+
+	for _, path := range []string{"", "nope", "subchart2", "subchart1.nope"} {
+		if _, _, err := ScopeToSubchart(c, vals, path); err == nil {
+			t.Errorf("expected error for subchart path %q", path)
+		}
+	}
+}
+
+func TestDependencyEnabledWhen(t *testing.T) {
+	type M = map[string]interface{}
+	tests := []struct {
+		name        string
+		enabledWhen string
+		condition   string
+		tags        []string
+		v           M
+		e           []string
+	}{{
+		"expression enables chart",
+		`.Values.global.env == "prod" && .Values.featureX`,
+		"", nil,
+		M{"global": M{"env": "prod"}, "featureX": true},
+		[]string{"parent", "parent.child"},
+	}, {
+		"expression disables chart",
+		`.Values.global.env == "prod" && .Values.featureX`,
+		"", nil,
+		M{"global": M{"env": "dev"}, "featureX": true},
+		[]string{"parent"},
+	}, {
+		"expression takes precedence over condition",
+		`.Values.featureX`,
+		"child.enabled", nil,
+		M{"featureX": true, "child": M{"enabled": false}},
+		[]string{"parent", "parent.child"},
+	}, {
+		"expression takes precedence over tags",
+		`.Values.featureX`,
+		"", []string{"backend"},
+		M{"featureX": false, "tags": M{"backend": true}},
+		[]string{"parent"},
+	}, {
+		"condition still applies without expression",
+		"",
+		"child.enabled", nil,
+		M{"child": M{"enabled": false}},
+		[]string{"parent"},
+	}}
+
+	for _, tc := range tests {
+		c := &chart.Chart{Metadata: &chart.Metadata{
+			Name:    "parent",
+			Version: "0.1.0",
+			Dependencies: []*chart.Dependency{{
+				Name:        "child",
+				Version:     "0.1.0",
+				Condition:   tc.condition,
+				Tags:        tc.tags,
+				EnabledWhen: tc.enabledWhen,
+			}},
+		}}
+		c.AddDependency(&chart.Chart{Metadata: &chart.Metadata{Name: "child", Version: "0.1.0"}})
+
+		t.Run(tc.name, func(t *testing.T) {
+			if err := processDependencyEnabled(c, tc.v, ""); err != nil {
+				t.Fatalf("error processing enabled dependencies %v", err)
+			}
+
+			names := extractChartNames(c)
+			if len(names) != len(tc.e) {
+				t.Fatalf("slice lengths do not match got %v, expected %v", len(names), len(tc.e))
+			}
+			for i := range names {
+				if names[i] != tc.e[i] {
+					t.Fatalf("slice values do not match got %v, expected %v", names, tc.e)
+				}
+			}
+		})
+	}
+}
+
+func TestDependencyEnabledWhenInvalid(t *testing.T) {
+	c := &chart.Chart{Metadata: &chart.Metadata{
+		Name:    "parent",
+		Version: "0.1.0",
+		Dependencies: []*chart.Dependency{{
+			Name:        "child",
+			Version:     "0.1.0",
+			EnabledWhen: `.Values.featureX ==`,
+		}},
+	}}
+	c.AddDependency(&chart.Chart{Metadata: &chart.Metadata{Name: "child", Version: "0.1.0"}})
+
+	if err := ProcessDependencies(c, Values{}); err == nil {
+		t.Fatal("expected an error for an invalid enabledWhen expression")
+	}
+}
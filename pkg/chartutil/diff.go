@@ -0,0 +1,189 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"sort"
+)
+
+// DiffType describes how a value, or another item such as a resource of a
+// release, differs between two versions.
+type DiffType string
+
+const (
+	// DiffAdded indicates the item only exists in the new version.
+	DiffAdded DiffType = "added"
+	// DiffRemoved indicates the item only exists in the old version.
+	DiffRemoved DiffType = "removed"
+	// DiffChanged indicates the item exists in both with different content.
+	DiffChanged DiffType = "changed"
+)
+
+// RedactedValue replaces values whose key matches the sensitive key pattern.
+const RedactedValue = "<redacted>"
+
+// DefaultSensitiveKeyPattern matches keys that commonly hold credentials.
+var DefaultSensitiveKeyPattern = regexp.MustCompile(`(?i)(password|passwd|secret|token|api[-_]?key|credential|private[-_]?key)`)
+
+// ValueDiff is a single difference between two sets of values.
+type ValueDiff struct {
+	// Path is the dotted path to the value, e.g. "image.tag".
+	Path string      `json:"path"`
+	Type DiffType    `json:"type"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// DiffValues compares two sets of values and returns the added, removed and
+// changed paths sorted by path.
+//
+// The comparison is semantic: map ordering is ignored and numbers are
+// compared by value regardless of how they were decoded. Tables that only
+// exist on one side are reported leaf by leaf. Values below any key matching
+// DefaultSensitiveKeyPattern are replaced with RedactedValue.
+func DiffValues(a, b Values) []ValueDiff {
+	return DiffValuesWithRedaction(a, b, DefaultSensitiveKeyPattern)
+}
+
+// DiffValuesWithRedaction is like DiffValues but redacts keys matching the
+// given pattern instead of the default one. A nil pattern disables redaction.
+func DiffValuesWithRedaction(a, b Values, sensitive *regexp.Regexp) []ValueDiff {
+	d := &valuesDiffer{sensitive: sensitive}
+	d.walk("", false, a, b)
+	sort.Slice(d.diffs, func(i, j int) bool { return d.diffs[i].Path < d.diffs[j].Path })
+	return d.diffs
+}
+
+// RedactValues returns a copy of the values with the values below any key
+// matching the sensitive pattern replaced with RedactedValue. A nil pattern
+// disables redaction.
+func RedactValues(v Values, sensitive *regexp.Regexp) Values {
+	if v == nil {
+		return nil
+	}
+	d := &valuesDiffer{sensitive: sensitive}
+	return d.redact(false, v).(map[string]interface{})
+}
+
+type valuesDiffer struct {
+	sensitive *regexp.Regexp
+	diffs     []ValueDiff
+}
+
+func (d *valuesDiffer) isSensitive(key string) bool {
+	return d.sensitive != nil && d.sensitive.MatchString(key)
+}
+
+func (d *valuesDiffer) walk(prefix string, redact bool, a, b map[string]interface{}) {
+	for k, av := range a {
+		p := joinDiffPath(prefix, k)
+		r := redact || d.isSensitive(k)
+		bv, ok := b[k]
+		if !ok {
+			d.leaves(p, r, DiffRemoved, av)
+			continue
+		}
+		am, aIsMap := asValueMap(av)
+		bm, bIsMap := asValueMap(bv)
+		if aIsMap && bIsMap {
+			d.walk(p, r, am, bm)
+			continue
+		}
+		if !semanticEqual(av, bv) {
+			d.add(ValueDiff{Path: p, Type: DiffChanged, Old: d.redact(r, av), New: d.redact(r, bv)})
+		}
+	}
+	for k, bv := range b {
+		if _, ok := a[k]; !ok {
+			d.leaves(joinDiffPath(prefix, k), redact || d.isSensitive(k), DiffAdded, bv)
+		}
+	}
+}
+
+// leaves reports every leaf of v as added or removed.
+func (d *valuesDiffer) leaves(path string, redact bool, t DiffType, v interface{}) {
+	if m, ok := asValueMap(v); ok && len(m) > 0 {
+		for k, vv := range m {
+			d.leaves(joinDiffPath(path, k), redact || d.isSensitive(k), t, vv)
+		}
+		return
+	}
+	v = d.redact(redact, v)
+	if t == DiffAdded {
+		d.add(ValueDiff{Path: path, Type: t, New: v})
+	} else {
+		d.add(ValueDiff{Path: path, Type: t, Old: v})
+	}
+}
+
+func (d *valuesDiffer) add(diff ValueDiff) {
+	d.diffs = append(d.diffs, diff)
+}
+
+// redact hides v entirely when redact is set, and otherwise hides any
+// sensitive keys nested within it.
+func (d *valuesDiffer) redact(redact bool, v interface{}) interface{} {
+	if redact {
+		return RedactedValue
+	}
+	m, ok := asValueMap(v)
+	if !ok {
+		return v
+	}
+	out := make(map[string]interface{}, len(m))
+	for k, vv := range m {
+		out[k] = d.redact(d.isSensitive(k), vv)
+	}
+	return out
+}
+
+func joinDiffPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func asValueMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case Values:
+		return m, true
+	}
+	return nil, false
+}
+
+// semanticEqual compares two values ignoring the concrete numeric types
+// produced by the different YAML, JSON and --set parsers.
+func semanticEqual(a, b interface{}) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	// Fall back to comparing the JSON encodings so that nested numbers in
+	// lists are compared by value as well.
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
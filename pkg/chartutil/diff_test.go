@@ -0,0 +1,124 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestDiffValues(t *testing.T) {
+	a := Values{
+		"image":    map[string]interface{}{"repository": "nginx", "tag": "1.0"},
+		"replicas": json.Number("3"),
+		"ports":    []interface{}{json.Number("80"), json.Number("443")},
+		"debug":    true,
+		"db": map[string]interface{}{
+			"host":     "db.local",
+			"password": "hunter2",
+		},
+	}
+	b := Values{
+		"image":    map[string]interface{}{"repository": "nginx", "tag": "1.1"},
+		"replicas": int64(3),
+		"ports":    []interface{}{80, 443},
+		"db": map[string]interface{}{
+			"host":     "db.prod",
+			"password": "correcthorse",
+		},
+		"auth": map[string]interface{}{
+			"apiKey": "abc",
+			"user":   "admin",
+		},
+	}
+
+	expect := []ValueDiff{
+		{Path: "auth.apiKey", Type: DiffAdded, New: RedactedValue},
+		{Path: "auth.user", Type: DiffAdded, New: "admin"},
+		{Path: "db.host", Type: DiffChanged, Old: "db.local", New: "db.prod"},
+		{Path: "db.password", Type: DiffChanged, Old: RedactedValue, New: RedactedValue},
+		{Path: "debug", Type: DiffRemoved, Old: true},
+		{Path: "image.tag", Type: DiffChanged, Old: "1.0", New: "1.1"},
+	}
+	if got := DiffValues(a, b); !reflect.DeepEqual(expect, got) {
+		t.Errorf("expected %v, got %v", expect, got)
+	}
+}
+
+func TestDiffValuesRedactsNestedTables(t *testing.T) {
+	a := Values{"config": "plain"}
+	b := Values{"config": map[string]interface{}{"token": "abc", "name": "x"}}
+
+	got := DiffValues(a, b)
+	expect := []ValueDiff{{
+		Path: "config",
+		Type: DiffChanged,
+		Old:  "plain",
+		New:  map[string]interface{}{"token": RedactedValue, "name": "x"},
+	}}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("expected %v, got %v", expect, got)
+	}
+
+	secrets := Values{"secrets": map[string]interface{}{"db": "a"}}
+	got = DiffValues(Values{}, secrets)
+	if len(got) != 1 || got[0].Path != "secrets.db" || got[0].New != RedactedValue {
+		t.Errorf("expected values below a sensitive table to be redacted, got %v", got)
+	}
+}
+
+func TestDiffValuesWithRedaction(t *testing.T) {
+	a := Values{"password": "a", "customerId": "1"}
+	b := Values{"password": "b", "customerId": "2"}
+
+	got := DiffValuesWithRedaction(a, b, nil)
+	if got[1].Old != "a" || got[1].New != "b" {
+		t.Errorf("expected redaction to be disabled, got %v", got)
+	}
+
+	got = DiffValuesWithRedaction(a, b, regexp.MustCompile(`(?i)customer`))
+	if got[0].Old != RedactedValue || got[1].Old != "a" {
+		t.Errorf("expected custom pattern to be used, got %v", got)
+	}
+
+	if got := DiffValues(a, a); len(got) != 0 {
+		t.Errorf("expected no differences, got %v", got)
+	}
+}
+
+func TestRedactValues(t *testing.T) {
+	vals := Values{
+		"image":    map[string]interface{}{"tag": "1.0"},
+		"database": map[string]interface{}{"password": "hunter2", "credentials": map[string]interface{}{"user": "admin"}},
+	}
+	redacted := RedactValues(vals, DefaultSensitiveKeyPattern)
+	expected := Values{
+		"image":    map[string]interface{}{"tag": "1.0"},
+		"database": map[string]interface{}{"password": RedactedValue, "credentials": RedactedValue},
+	}
+	if !reflect.DeepEqual(expected, redacted) {
+		t.Errorf("Expected %v, got %v", expected, redacted)
+	}
+	if vals["database"].(map[string]interface{})["password"] != "hunter2" {
+		t.Error("Expected the values to be left unchanged")
+	}
+	if RedactValues(nil, DefaultSensitiveKeyPattern) != nil {
+		t.Error("Expected nil values to stay nil")
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/pkg/chart"
+)
+
+// DigestChart returns the digest of the files of a loaded chart and of its
+// dependencies.
+//
+// The digest does not depend on whether the chart and its dependencies were
+// loaded from archives or directories, so a dependency can be compared with
+// the digest recorded when it was fetched whether it was expanded or not.
+func DigestChart(c *chart.Chart) string {
+	h := sha256.New()
+	write := func(name string, data []byte) {
+		// The lengths keep the boundaries between the names and the contents.
+		_ = binary.Write(h, binary.BigEndian, uint64(len(name)))
+		h.Write([]byte(name))
+		_ = binary.Write(h, binary.BigEndian, uint64(len(data)))
+		h.Write(data)
+	}
+
+	files := make([]*chart.File, 0, len(c.Raw))
+	for _, f := range c.Raw {
+		// The dependencies are digested as loaded charts instead.
+		if !strings.HasPrefix(f.Name, ChartsDir+"/") {
+			files = append(files, f)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	for _, f := range files {
+		write(f.Name, f.Data)
+	}
+
+	digests := DependencyDigests(c)
+	keys := make([]string, 0, len(digests))
+	for key := range digests {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		write(ChartsDir+"/"+key, []byte(digests[key]))
+	}
+	return fmt.Sprintf("sha256:%x", h.Sum(nil))
+}
+
+// DependencyDigests returns the digests of the dependencies of the chart, as
+// recorded in Chart.lock.
+func DependencyDigests(c *chart.Chart) map[string]string {
+	if len(c.Dependencies()) == 0 {
+		return nil
+	}
+	digests := make(map[string]string, len(c.Dependencies()))
+	for _, dep := range c.Dependencies() {
+		digests[lockKey(dep)] = DigestChart(dep)
+	}
+	return digests
+}
+
+// VerifyDependencyDigests verifies the dependencies of the chart in charts/
+// against the digests recorded in Chart.lock, failing when a dependency was
+// modified, added or removed after it was fetched.
+func VerifyDependencyDigests(c *chart.Chart) error {
+	if c.Lock == nil || len(c.Lock.Digests) == 0 {
+		if len(c.Dependencies()) == 0 {
+			return nil
+		}
+		return errors.Errorf("chart %s has no dependency digests in its lock file; run 'helm dependency update' to record them", c.Name())
+	}
+
+	found := make(map[string]bool, len(c.Dependencies()))
+	for _, dep := range c.Dependencies() {
+		key := lockKey(dep)
+		found[key] = true
+		expected, ok := c.Lock.Digests[key]
+		if !ok {
+			return errors.Errorf("dependency %s of chart %s is not recorded in its lock file", key, c.Name())
+		}
+		if actual := DigestChart(dep); actual != expected {
+			return errors.Errorf("dependency %s of chart %s was modified: its digest is %s, but the lock file records %s", key, c.Name(), actual, expected)
+		}
+	}
+	missing := []string{}
+	for key := range c.Lock.Digests {
+		if !found[key] {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return errors.Errorf("dependencies of chart %s are recorded in its lock file, but are missing from charts/: %s", c.Name(), strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// lockKey is the key of a dependency in the digests of a lock file.
+func lockKey(c *chart.Chart) string {
+	return c.Name() + "-" + c.Metadata.Version
+}
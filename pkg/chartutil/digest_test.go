@@ -0,0 +1,74 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart"
+)
+
+func TestDigestChart(t *testing.T) {
+	c := loadChart(t, "testdata/frobnitz")
+	tmp := t.TempDir()
+	if err := SaveDir(c, tmp); err != nil {
+		t.Fatal(err)
+	}
+	filename, err := Save(c, tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := loadChart(t, filepath.Join(tmp, c.Name()))
+	archive := loadChart(t, filename)
+	if DigestChart(dir) != DigestChart(archive) {
+		t.Errorf("expected the same digest for the directory and the archive, got %s and %s", DigestChart(dir), DigestChart(archive))
+	}
+
+	other := loadChart(t, "testdata/subpop")
+	if DigestChart(dir) == DigestChart(other) {
+		t.Error("expected different digests for different charts")
+	}
+}
+
+func TestVerifyDependencyDigests(t *testing.T) {
+	c := loadChart(t, "testdata/subpop")
+	if err := VerifyDependencyDigests(c); err == nil || !strings.Contains(err.Error(), "no dependency digests") {
+		t.Errorf("expected an error for a lock file without digests, got %v", err)
+	}
+
+	c.Lock = &chart.Lock{Digests: DependencyDigests(c)}
+	if err := VerifyDependencyDigests(c); err != nil {
+		t.Fatal(err)
+	}
+
+	dep := c.Dependencies()[0]
+	dep.Raw = append(dep.Raw, &chart.File{Name: "templates/extra.yaml", Data: []byte("kind: Secret")})
+	err := VerifyDependencyDigests(c)
+	if err == nil || !strings.Contains(err.Error(), "dependency "+dep.Name()+"-"+dep.Metadata.Version+" of chart parentchart was modified") {
+		t.Errorf("expected an error for the modified dependency, got %v", err)
+	}
+
+	c = loadChart(t, "testdata/subpop")
+	c.Lock = &chart.Lock{Digests: DependencyDigests(c)}
+	c.Lock.Digests["removed-1.0.0"] = "sha256:0"
+	err = VerifyDependencyDigests(c)
+	if err == nil || !strings.Contains(err.Error(), "missing from charts/: removed-1.0.0") {
+		t.Errorf("expected an error for the missing dependency, got %v", err)
+	}
+}
@@ -41,3 +41,16 @@ type ErrInvalidChartName struct {
 func (e ErrInvalidChartName) Error() string {
 	return fmt.Sprintf("%q is not a valid chart name", e.Name)
 }
+
+// ErrIncompatibleHelmVersion indicates that a chart requires another version
+// of Helm.
+type ErrIncompatibleHelmVersion struct {
+	// Chart is the name of the chart, or subchart, with the constraint.
+	Chart      string
+	Constraint string
+	Version    string
+}
+
+func (e ErrIncompatibleHelmVersion) Error() string {
+	return fmt.Sprintf("chart %q requires Helm version %s, which is incompatible with Helm %s", e.Chart, e.Constraint, e.Version)
+}
@@ -35,6 +35,23 @@ func Expand(dir string, r io.Reader) error {
 	if err != nil {
 		return err
 	}
+	return expandFiles(dir, files)
+}
+
+// ExpandMatching uncompresses and extracts the files of a chart matched by
+// match into the specified directory, keeping their paths within the chart.
+// Chart.yaml is always extracted, as it names the chart directory.
+func ExpandMatching(dir string, r io.Reader, match func(name string) bool) error {
+	files, err := loader.LoadArchiveFilesMatching(r, func(name string) bool {
+		return name == ChartfileName || match(name)
+	})
+	if err != nil {
+		return err
+	}
+	return expandFiles(dir, files)
+}
+
+func expandFiles(dir string, files []*loader.BufferedFile) error {
 
 	// Get the name of the chart
 	var chartName string
@@ -91,3 +108,14 @@ func ExpandFile(dest, src string) error {
 	defer h.Close()
 	return Expand(dest, h)
 }
+
+// ExpandFileMatching expands the files of the src file matched by match into
+// the dest directory, see ExpandMatching.
+func ExpandFileMatching(dest, src string, match func(name string) bool) error {
+	h, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+	return ExpandMatching(dest, h, match)
+}
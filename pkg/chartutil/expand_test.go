@@ -19,6 +19,7 @@ package chartutil
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -122,3 +123,26 @@ func TestExpandFile(t *testing.T) {
 		}
 	}
 }
+
+func TestExpandFileMatching(t *testing.T) {
+	dest := t.TempDir()
+
+	match := func(name string) bool {
+		return name == "values.yaml" || strings.HasPrefix(name, "docs/")
+	}
+	if err := ExpandFileMatching(dest, "testdata/frobnitz-1.2.3.tgz", match); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedChartPath := filepath.Join(dest, "frobnitz")
+	for _, name := range []string{"Chart.yaml", "values.yaml", "docs/README.md"} {
+		if _, err := os.Stat(filepath.Join(expectedChartPath, name)); err != nil {
+			t.Errorf("expected %s to be extracted: %s", name, err)
+		}
+	}
+	for _, name := range []string{"README.md", "templates", "charts"} {
+		if _, err := os.Stat(filepath.Join(expectedChartPath, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %s not to be extracted", name)
+		}
+	}
+}
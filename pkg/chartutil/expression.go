@@ -0,0 +1,347 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// EvalEnabledExpression evaluates a dependency enablement expression against
+// the given values.
+//
+// The expression language is intentionally small. It supports:
+//
+//   - value references: .Values.global.env
+//   - literals: "prod", 'prod', 42, 1.5, true, false, null
+//   - comparison: ==, !=
+//   - logic: &&, ||, ! and parentheses
+//
+// A value reference on its own is evaluated for truthiness: false, null, zero
+// numbers and empty strings, lists and maps are false; everything else is
+// true. References to values that do not exist evaluate to null.
+func EvalEnabledExpression(expr string, vals Values) (bool, error) {
+	p := &exprParser{input: expr}
+	if err := p.tokenize(); err != nil {
+		return false, errors.Wrapf(err, "invalid expression %q", expr)
+	}
+	if len(p.tokens) == 0 {
+		return false, errors.New("expression cannot be empty")
+	}
+	p.vals = vals
+	v, err := p.parseOr()
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid expression %q", expr)
+	}
+	if p.pos < len(p.tokens) {
+		return false, errors.Errorf("invalid expression %q: unexpected %q", expr, p.tokens[p.pos].text)
+	}
+	return truthy(v), nil
+}
+
+type exprTokenKind int
+
+const (
+	tokRef exprTokenKind = iota
+	tokString
+	tokNumber
+	tokIdent
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type exprToken struct {
+	kind exprTokenKind
+	text string
+}
+
+type exprParser struct {
+	input  string
+	tokens []exprToken
+	pos    int
+	vals   Values
+}
+
+func (p *exprParser) tokenize() error {
+	in := p.input
+	for i := 0; i < len(in); {
+		c := in[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			p.tokens = append(p.tokens, exprToken{tokLParen, "("})
+			i++
+		case c == ')':
+			p.tokens = append(p.tokens, exprToken{tokRParen, ")"})
+			i++
+		case strings.HasPrefix(in[i:], "&&"), strings.HasPrefix(in[i:], "||"),
+			strings.HasPrefix(in[i:], "=="), strings.HasPrefix(in[i:], "!="):
+			p.tokens = append(p.tokens, exprToken{tokOp, in[i : i+2]})
+			i += 2
+		case c == '!':
+			p.tokens = append(p.tokens, exprToken{tokOp, "!"})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(in[i+1:], c)
+			if end < 0 {
+				return errors.New("unterminated string literal")
+			}
+			p.tokens = append(p.tokens, exprToken{tokString, in[i+1 : i+1+end]})
+			i += end + 2
+		case c == '.':
+			j := i + 1
+			for j < len(in) && isExprIdentChar(in[j]) {
+				j++
+			}
+			ref := in[i:j]
+			if ref != ".Values" && !strings.HasPrefix(ref, ".Values.") {
+				return errors.Errorf("unsupported reference %q: only .Values references are allowed", ref)
+			}
+			if strings.HasSuffix(ref, ".") || strings.Contains(ref, "..") {
+				return errors.Errorf("malformed reference %q", ref)
+			}
+			p.tokens = append(p.tokens, exprToken{tokRef, ref})
+			i = j
+		case c == '-' || unicode.IsDigit(rune(c)):
+			j := i + 1
+			for j < len(in) && (unicode.IsDigit(rune(in[j])) || in[j] == '.') {
+				j++
+			}
+			p.tokens = append(p.tokens, exprToken{tokNumber, in[i:j]})
+			i = j
+		case unicode.IsLetter(rune(c)):
+			j := i + 1
+			for j < len(in) && unicode.IsLetter(rune(in[j])) {
+				j++
+			}
+			p.tokens = append(p.tokens, exprToken{tokIdent, in[i:j]})
+			i = j
+		default:
+			return errors.Errorf("unexpected character %q", c)
+		}
+	}
+	return nil
+}
+
+func isExprIdentChar(c byte) bool {
+	return c == '.' || c == '_' || c == '-' || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
+
+func (p *exprParser) peek() *exprToken {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+func (p *exprParser) acceptOp(op string) bool {
+	if t := p.peek(); t != nil && t.kind == tokOp && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) parseOr() (interface{}, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = truthy(left) || truthy(right)
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (interface{}, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp("&&") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = truthy(left) && truthy(right)
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseComparison() (interface{}, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case p.acceptOp("=="):
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return valuesEqual(left, right), nil
+	case p.acceptOp("!="):
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return !valuesEqual(left, right), nil
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (interface{}, error) {
+	if p.acceptOp("!") {
+		v, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return !truthy(v), nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (interface{}, error) {
+	t := p.peek()
+	if t == nil {
+		return nil, errors.New("unexpected end of expression")
+	}
+	p.pos++
+	switch t.kind {
+	case tokLParen:
+		v, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.peek(); t == nil || t.kind != tokRParen {
+			return nil, errors.New("missing closing parenthesis")
+		}
+		p.pos++
+		return v, nil
+	case tokRef:
+		return p.lookup(strings.TrimPrefix(t.text, ".Values")), nil
+	case tokString:
+		return t.text, nil
+	case tokNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid number %q", t.text)
+		}
+		return f, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null", "nil":
+			return nil, nil
+		}
+		return nil, errors.Errorf("unknown identifier %q", t.text)
+	}
+	return nil, errors.Errorf("unexpected %q", t.text)
+}
+
+// lookup resolves a reference relative to .Values. Tables are returned as
+// well as leaf values so that they can be tested for emptiness.
+func (p *exprParser) lookup(ref string) interface{} {
+	ref = strings.TrimPrefix(ref, ".")
+	if ref == "" {
+		return map[string]interface{}(p.vals)
+	}
+	keys := parsePath(ref)
+	t := p.vals
+	if len(keys) > 1 {
+		var err error
+		if t, err = p.vals.Table(joinPath(keys[:len(keys)-1]...)); err != nil {
+			return nil
+		}
+	}
+	return t[keys[len(keys)-1]]
+}
+
+// truthy reports whether a value is considered true, following the same
+// rules as Go templates.
+func truthy(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return t
+	case string:
+		return t != ""
+	}
+	if f, ok := toFloat(v); ok {
+		return f != 0
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return rv.Len() > 0
+	}
+	return true
+}
+
+func valuesEqual(a, b interface{}) bool {
+	if fa, ok := toFloat(a); ok {
+		if fb, ok := toFloat(b); ok {
+			return fa == fb
+		}
+		return false
+	}
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	switch a.(type) {
+	case string, bool:
+		return a == b
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,95 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEvalEnabledExpression(t *testing.T) {
+	vals := Values{
+		"global": map[string]interface{}{
+			"env": "prod",
+		},
+		"featureX": true,
+		"featureY": false,
+		"replicas": json.Number("3"),
+		"empty":    "",
+		"list":     []interface{}{"a"},
+		"dashed-key": map[string]interface{}{
+			"on": true,
+		},
+	}
+
+	tests := []struct {
+		expr   string
+		expect bool
+	}{
+		{`.Values.featureX`, true},
+		{`.Values.featureY`, false},
+		{`.Values.missing`, false},
+		{`.Values.missing.deeper`, false},
+		{`.Values.empty`, false},
+		{`.Values.list`, true},
+		{`.Values.global`, true},
+		{`.Values.global.env == "prod"`, true},
+		{`.Values.global.env == 'dev'`, false},
+		{`.Values.global.env != "dev"`, true},
+		{`.Values.global.env == "prod" && .Values.featureX`, true},
+		{`.Values.global.env == "prod" && .Values.featureY`, false},
+		{`.Values.featureY || .Values.featureX`, true},
+		{`!.Values.featureY`, true},
+		{`!(.Values.featureX && .Values.featureY)`, true},
+		{`.Values.replicas == 3`, true},
+		{`.Values.replicas != 3.0`, false},
+		{`.Values.missing == null`, true},
+		{`.Values.featureX == true`, true},
+		{`.Values.dashed-key.on`, true},
+		{`true`, true},
+		{`.Values.featureY || .Values.featureY && .Values.featureX`, false},
+	}
+
+	for _, tt := range tests {
+		got, err := EvalEnabledExpression(tt.expr, vals)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.expr, err)
+			continue
+		}
+		if got != tt.expect {
+			t.Errorf("%s: expected %t, got %t", tt.expr, tt.expect, got)
+		}
+	}
+}
+
+func TestEvalEnabledExpressionErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`.Values.a ==`,
+		`(.Values.a`,
+		`.Values.a)`,
+		`.Release.Name`,
+		`.Values.`,
+		`"unterminated`,
+		`maybe`,
+		`.Values.a = 1`,
+	} {
+		if _, err := EvalEnabledExpression(expr, Values{}); err == nil {
+			t.Errorf("expected error for expression %q", expr)
+		}
+	}
+}
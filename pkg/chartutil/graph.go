@@ -0,0 +1,173 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/chart"
+)
+
+// DependencyNode is a chart in a dependency graph, see BuildDependencyGraph.
+type DependencyNode struct {
+	// Name is the name of the chart.
+	Name string `json:"name"`
+	// Alias is the alias the parent chart uses for the chart.
+	Alias string `json:"alias,omitempty"`
+	// Version is the version of the chart in the charts/ directory of the
+	// parent chart.
+	Version string `json:"version,omitempty"`
+	// Constraint is the version range the parent chart declares.
+	Constraint string `json:"constraint,omitempty"`
+	// Repository is the repository the parent chart declares.
+	Repository string `json:"repository,omitempty"`
+	// Condition, Tags and EnabledWhen are the conditions the parent chart
+	// declares for the chart.
+	Condition   string   `json:"condition,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	EnabledWhen string   `json:"enabledWhen,omitempty"`
+	// Enabled reports whether the chart is enabled by the values. A chart is
+	// disabled when any of its ancestors is.
+	Enabled bool `json:"enabled"`
+	// Missing reports that the chart is declared but not present in the
+	// charts/ directory of the parent chart.
+	Missing bool `json:"missing,omitempty"`
+	// Dependencies are the subcharts of the chart, declared in Chart.yaml or
+	// only present in the charts/ directory.
+	Dependencies []*DependencyNode `json:"dependencies,omitempty"`
+
+	dep *chart.Dependency
+}
+
+// BuildDependencyGraph builds the graph of the transitive dependencies of a
+// chart, rooted at the chart itself. Aliased dependencies are separate nodes,
+// and the conditions, tags and enabledWhen expressions are evaluated against
+// the values to tell which dependencies are enabled.
+//
+// The dependencies of c are processed as by ProcessDependencies, so c should
+// not be rendered afterwards.
+func BuildDependencyGraph(c *chart.Chart, v Values) (*DependencyNode, error) {
+	root := &DependencyNode{
+		Name:         c.Name(),
+		Version:      c.Metadata.Version,
+		Dependencies: dependencyNodes(c),
+	}
+	if err := processDependencyEnabled(c, v, ""); err != nil {
+		return nil, err
+	}
+	root.resolveEnabled(true)
+	return root, nil
+}
+
+func dependencyNodes(c *chart.Chart) []*DependencyNode {
+	var nodes []*DependencyNode
+	for _, req := range c.Metadata.Dependencies {
+		if req == nil {
+			continue
+		}
+		n := &DependencyNode{
+			Name:        req.Name,
+			Alias:       req.Alias,
+			Constraint:  req.Version,
+			Repository:  req.Repository,
+			Condition:   req.Condition,
+			Tags:        req.Tags,
+			EnabledWhen: req.EnabledWhen,
+			dep:         req,
+		}
+		if sub := getAliasDependency(c.Dependencies(), req); sub != nil {
+			n.Version = sub.Metadata.Version
+			n.Dependencies = dependencyNodes(sub)
+		} else {
+			n.Missing = true
+		}
+		nodes = append(nodes, n)
+	}
+
+Loop:
+	for _, sub := range c.Dependencies() {
+		for _, req := range c.Metadata.Dependencies {
+			if req != nil && sub.Name() == req.Name && IsCompatibleRange(req.Version, sub.Metadata.Version) {
+				continue Loop
+			}
+		}
+		nodes = append(nodes, &DependencyNode{
+			Name:         sub.Name(),
+			Version:      sub.Metadata.Version,
+			Dependencies: dependencyNodes(sub),
+		})
+	}
+	return nodes
+}
+
+func (n *DependencyNode) resolveEnabled(parentEnabled bool) {
+	n.Enabled = parentEnabled && (n.dep == nil || n.dep.Enabled)
+	for _, d := range n.Dependencies {
+		d.resolveEnabled(n.Enabled)
+	}
+}
+
+// WriteDOT writes the graph rooted at the node in the DOT language of
+// Graphviz. Edges are labeled with the declared version ranges, disabled
+// charts are dashed and missing charts are red.
+func (n *DependencyNode) WriteDOT(out io.Writer) error {
+	w := bufio.NewWriter(out)
+	fmt.Fprintln(w, "digraph dependencies {")
+	fmt.Fprintf(w, "  %q [label=%q];\n", n.Name, n.Name+"\n"+n.Version)
+	n.writeDOTEdges(w, n.Name)
+	fmt.Fprintln(w, "}")
+	return w.Flush()
+}
+
+func (n *DependencyNode) writeDOTEdges(w io.Writer, id string) {
+	for _, d := range n.Dependencies {
+		name := d.Name
+		if d.Alias != "" {
+			name = d.Alias
+		}
+		child := id + "/" + name
+
+		label := name
+		if d.Alias != "" {
+			label += " (" + d.Name + ")"
+		}
+		if d.Missing {
+			label += "\nmissing"
+		} else {
+			label += "\n" + d.Version
+		}
+		var attrs []string
+		attrs = append(attrs, fmt.Sprintf("label=%q", label))
+		if !d.Enabled {
+			attrs = append(attrs, "style=dashed")
+		}
+		if d.Missing {
+			attrs = append(attrs, "color=red")
+		}
+		fmt.Fprintf(w, "  %q [%s];\n", child, strings.Join(attrs, ", "))
+
+		if d.Constraint != "" {
+			fmt.Fprintf(w, "  %q -> %q [label=%q];\n", id, child, d.Constraint)
+		} else {
+			fmt.Fprintf(w, "  %q -> %q;\n", id, child)
+		}
+		d.writeDOTEdges(w, child)
+	}
+}
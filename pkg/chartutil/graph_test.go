@@ -0,0 +1,114 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"bytes"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart"
+)
+
+func graphPaths(prefix string, nodes []*DependencyNode, out map[string]*DependencyNode) {
+	for _, n := range nodes {
+		name := n.Name
+		if n.Alias != "" {
+			name = n.Alias
+		}
+		out[prefix+name] = n
+		graphPaths(prefix+name+".", n.Dependencies, out)
+	}
+}
+
+func TestBuildDependencyGraph(t *testing.T) {
+	c := loadChart(t, "testdata/subpop")
+	graph, err := BuildDependencyGraph(c, Values{"subchart2alias": map[string]interface{}{"enabled": true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if graph.Name != "parentchart" || graph.Version != "0.1.0" || !graph.Enabled {
+		t.Errorf("unexpected root %+v", graph)
+	}
+	nodes := map[string]*DependencyNode{}
+	graphPaths("", graph.Dependencies, nodes)
+	for path, enabled := range map[string]bool{
+		"subchart1":                true,
+		"subchart1.subcharta":      true,
+		"subchart1.subchartb":      true,
+		"subchart2":                false,
+		"subchart2.subchartb":      false,
+		"subchart2.subchartc":      false,
+		"subchart2alias":           true,
+		"subchart2alias.subchartb": false,
+		"subchart2alias.subchartc": false,
+	} {
+		n, ok := nodes[path]
+		if !ok {
+			t.Errorf("expected node %s", path)
+			continue
+		}
+		if n.Enabled != enabled {
+			t.Errorf("expected %s to be enabled=%t", path, enabled)
+		}
+	}
+	if len(nodes) != 9 {
+		t.Errorf("expected 9 nodes, got %d", len(nodes))
+	}
+	if n := nodes["subchart2alias"]; n.Name != "subchart2" || n.Condition != "subchart2alias.enabled" {
+		t.Errorf("unexpected aliased node %+v", n)
+	}
+}
+
+func TestBuildDependencyGraphMissingAndUndeclared(t *testing.T) {
+	c := &chart.Chart{Metadata: &chart.Metadata{
+		Name:         "umbrella",
+		Version:      "1.0.0",
+		Dependencies: []*chart.Dependency{{Name: "missing", Version: "^1.0.0"}},
+	}}
+	c.AddDependency(&chart.Chart{Metadata: &chart.Metadata{Name: "vendored", Version: "0.1.0"}})
+
+	graph, err := BuildDependencyGraph(c, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.Dependencies) != 2 {
+		t.Fatalf("expected 2 dependencies, got %d", len(graph.Dependencies))
+	}
+	if missing := graph.Dependencies[0]; !missing.Missing || missing.Version != "" {
+		t.Errorf("expected a missing node, got %+v", missing)
+	}
+	if vendored := graph.Dependencies[1]; vendored.Missing || !vendored.Enabled || vendored.Version != "0.1.0" {
+		t.Errorf("expected an enabled undeclared node, got %+v", vendored)
+	}
+
+	var b bytes.Buffer
+	if err := graph.WriteDOT(&b); err != nil {
+		t.Fatal(err)
+	}
+	expect := `digraph dependencies {
+  "umbrella" [label="umbrella\n1.0.0"];
+  "umbrella/missing" [label="missing\nmissing", color=red];
+  "umbrella" -> "umbrella/missing" [label="^1.0.0"];
+  "umbrella/vendored" [label="vendored\n0.1.0"];
+  "umbrella" -> "umbrella/vendored";
+}
+`
+	if got := b.String(); got != expect {
+		t.Errorf("expected:\n%s\ngot:\n%s", expect, got)
+	}
+}
@@ -0,0 +1,353 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/pkg/chart"
+)
+
+// valuesPathPattern matches the values referenced by the expressions of the
+// conditions of the dependencies, e.g. ".Values.global.env".
+var valuesPathPattern = regexp.MustCompile(`\.Values((?:\.[\w-]+)*)`)
+
+// UnusedValues returns the paths of the values in the values.yaml file of the
+// chart which are never referenced, sorted, e.g. "image.pullSecrets".
+//
+// The references are found by analyzing the templates of the chart and of its
+// dependencies, and the conditions, tags and import-values of the
+// dependencies. The exports table is used by the charts importing it.
+//
+// The analysis is conservative: a value is used when the value itself, one of
+// the tables containing it or one of the values it contains is referenced, so
+// passing .Values.image to a function uses all of the image table, and passing
+// .Values itself uses all values. Values only referenced through 'tpl', by
+// variables holding tables or by computed keys may therefore be reported.
+func UnusedValues(c *chart.Chart) []string {
+	refs, ok := valuesReferences(c)
+	if !ok || refs[""] {
+		return nil
+	}
+	var unused []string
+	collectUnused(c.Values, "", refs, &unused)
+	sort.Strings(unused)
+	return unused
+}
+
+// collectUnused adds the paths of the values in the table which are neither
+// referenced nor contain a referenced value.
+func collectUnused(table map[string]interface{}, prefix string, refs map[string]bool, unused *[]string) {
+	for key, v := range table {
+		p := joinDiffPath(prefix, key)
+		if isReferenced(p, refs) {
+			continue
+		}
+		if !containsReference(p, refs) {
+			*unused = append(*unused, p)
+			continue
+		}
+		if m, ok := asValueMap(v); ok {
+			collectUnused(m, p, refs, unused)
+		}
+	}
+}
+
+// isReferenced reports whether the path, or a table containing it, is
+// referenced.
+func isReferenced(p string, refs map[string]bool) bool {
+	for {
+		if refs[p] {
+			return true
+		}
+		i := strings.LastIndex(p, ".")
+		if i < 0 {
+			return false
+		}
+		p = p[:i]
+	}
+}
+
+// containsReference reports whether a value within the path is referenced.
+func containsReference(p string, refs map[string]bool) bool {
+	for ref := range refs {
+		if strings.HasPrefix(ref, p+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// valuesReferences returns the paths of the values referenced by the chart
+// and its dependencies, relative to the values of the chart. The empty path
+// means that all values are referenced. It returns false when a template
+// cannot be parsed, as the references are unknown then.
+func valuesReferences(c *chart.Chart) (map[string]bool, bool) {
+	// The exported values are used by the parents importing them.
+	refs := map[string]bool{"exports": true}
+	for _, t := range c.Templates {
+		paths, ok := templateValuesReferences(t.Name, string(t.Data))
+		if !ok {
+			return nil, false
+		}
+		for _, p := range paths {
+			refs[p] = true
+		}
+	}
+
+	for _, dep := range c.Metadata.Dependencies {
+		for _, cond := range strings.Split(dep.Condition, ",") {
+			if cond = strings.TrimSpace(cond); cond != "" {
+				refs[cond] = true
+			}
+		}
+		for _, tag := range dep.Tags {
+			refs["tags."+tag] = true
+		}
+		for _, m := range valuesPathPattern.FindAllStringSubmatch(dep.EnabledWhen, -1) {
+			refs[strings.TrimPrefix(m[1], ".")] = true
+		}
+		for _, iv := range dep.ImportValues {
+			switch iv := iv.(type) {
+			case string:
+				refs[dep.Name+".exports."+iv] = true
+			case map[string]interface{}:
+				if child, ok := iv["child"].(string); ok {
+					refs[dep.Name+"."+child] = true
+				}
+			}
+		}
+	}
+
+	// The values of a dependency are the table named after it, except for the
+	// global values which are shared.
+	aliases := map[string]string{}
+	for _, dep := range c.Metadata.Dependencies {
+		if dep.Alias != "" {
+			aliases[dep.Alias] = dep.Name
+		}
+	}
+	for _, sub := range c.Dependencies() {
+		subRefs, ok := valuesReferences(sub)
+		if !ok {
+			return nil, false
+		}
+		names := []string{sub.Name()}
+		for alias, name := range aliases {
+			if name == sub.Name() {
+				names = append(names, alias)
+			}
+		}
+		for p := range subRefs {
+			if p == GlobalKey || strings.HasPrefix(p, GlobalKey+".") {
+				refs[p] = true
+			}
+			for _, name := range names {
+				if p == "" {
+					refs[name] = true
+				} else {
+					refs[name+"."+p] = true
+				}
+			}
+		}
+	}
+	return refs, true
+}
+
+// templateValuesReferences returns the paths of the values referenced by a
+// template, or false when the template cannot be parsed.
+func templateValuesReferences(name, text string) ([]string, bool) {
+	t := parse.New(name)
+	t.Mode = parse.SkipFuncCheck
+	trees := map[string]*parse.Tree{}
+	if _, err := t.Parse(text, "", "", trees); err != nil {
+		return nil, false
+	}
+	var refs []string
+	for _, tree := range trees {
+		walkValuesReferences(tree.Root, &refs)
+	}
+	return refs, true
+}
+
+func walkValuesReferences(node parse.Node, refs *[]string) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			walkValuesReferences(c, refs)
+		}
+	case *parse.ActionNode:
+		walkValuesReferences(n.Pipe, refs)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Cmds {
+			walkValuesReferences(c, refs)
+		}
+	case *parse.CommandNode:
+		// 'index .Values "a" "b"' references a.b.
+		if len(n.Args) > 2 {
+			if id, ok := n.Args[0].(*parse.IdentifierNode); ok && id.Ident == "index" {
+				if p, ok := valuesPath(n.Args[1]); ok {
+					for _, arg := range n.Args[2:] {
+						s, ok := arg.(*parse.StringNode)
+						if !ok {
+							break
+						}
+						p = joinDiffPath(p, s.Text)
+					}
+					*refs = append(*refs, p)
+					for _, arg := range n.Args[2:] {
+						walkValuesReferences(arg, refs)
+					}
+					return
+				}
+			}
+		}
+		for _, arg := range n.Args {
+			walkValuesReferences(arg, refs)
+		}
+	case *parse.FieldNode, *parse.VariableNode:
+		if p, ok := valuesPath(n); ok {
+			*refs = append(*refs, p)
+		}
+	case *parse.ChainNode:
+		walkValuesReferences(n.Node, refs)
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, refs)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, refs)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, refs)
+	case *parse.TemplateNode:
+		walkValuesReferences(n.Pipe, refs)
+	}
+}
+
+func walkBranch(n *parse.BranchNode, refs *[]string) {
+	walkValuesReferences(n.Pipe, refs)
+	walkValuesReferences(n.List, refs)
+	walkValuesReferences(n.ElseList, refs)
+}
+
+// valuesPath returns the path of the value referenced by a field, e.g.
+// ".Values.image.tag" or "$.Values.image.tag".
+func valuesPath(node parse.Node) (string, bool) {
+	var ident []string
+	switch n := node.(type) {
+	case *parse.FieldNode:
+		ident = n.Ident
+	case *parse.VariableNode:
+		if len(n.Ident) == 0 || n.Ident[0] != "$" {
+			return "", false
+		}
+		ident = n.Ident[1:]
+	default:
+		return "", false
+	}
+	if len(ident) == 0 || ident[0] != "Values" {
+		return "", false
+	}
+	return strings.Join(ident[1:], "."), true
+}
+
+// ValidateImportValues validates the import-values of the dependencies of the
+// chart and of their dependencies, returning an error for every import of a
+// table which does not exist in the values of the dependency.
+//
+// The imports are applied like ProcessDependencies does: those of the
+// dependencies first, so that a chart can import values its dependencies
+// import, and the values of a dependency are found under its alias. Such
+// imports are otherwise ignored with a warning when the chart is installed.
+func ValidateImportValues(c *chart.Chart) []error {
+	_, errs := importValues(c)
+	return errs
+}
+
+// importValues returns the values of the chart once the import-values of its
+// dependencies are applied, and the errors of the imports which are not valid.
+func importValues(c *chart.Chart) (map[string]interface{}, []error) {
+	vals := deepCopyMap(c.Values)
+	if vals == nil {
+		vals = map[string]interface{}{}
+	}
+	var errs []error
+	subVals := map[string]map[string]interface{}{}
+	for _, sub := range c.Dependencies() {
+		v, subErrs := importValues(sub)
+		subVals[sub.Name()] = v
+		errs = append(errs, subErrs...)
+	}
+	if c.Metadata.Dependencies == nil {
+		return vals, errs
+	}
+
+	imported := map[string]interface{}{}
+	for _, dep := range c.Metadata.Dependencies {
+		v, ok := subVals[dep.Name]
+		if !ok {
+			// Missing dependencies are reported by the dependency checks.
+			continue
+		}
+		name := dep.Name
+		if dep.Alias != "" {
+			name = dep.Alias
+		}
+		// The values the chart sets for the dependency override its own.
+		depVals := deepCopyMap(v)
+		if overrides, ok := vals[name].(map[string]interface{}); ok {
+			depVals = CoalesceTables(deepCopyMap(overrides), depVals)
+		}
+		vals[name] = depVals
+
+		for _, iv := range dep.ImportValues {
+			var child, parent string
+			switch iv := iv.(type) {
+			case string:
+				child, parent = "exports."+iv, "."
+			case map[string]interface{}:
+				var okChild, okParent bool
+				child, okChild = iv["child"].(string)
+				parent, okParent = iv["parent"].(string)
+				if !okChild || !okParent {
+					errs = append(errs, errors.Errorf("chart %s: import-values of dependency %s must have a child and a parent path: %v", c.Name(), name, iv))
+					continue
+				}
+			default:
+				errs = append(errs, errors.Errorf("chart %s: invalid import-values of dependency %s: %v", c.Name(), name, iv))
+				continue
+			}
+			t, err := Values(depVals).Table(child)
+			if err != nil {
+				errs = append(errs, errors.Errorf("chart %s: import-values of dependency %s refers to %q, which is not a table in the values of %s", c.Name(), name, child, name))
+				continue
+			}
+			imported = CoalesceTables(imported, pathToMap(parent, deepCopyMap(t.AsMap())))
+		}
+	}
+	// The values of the chart override the imported ones.
+	return CoalesceTables(vals, imported), errs
+}
@@ -0,0 +1,157 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/loader"
+)
+
+func TestUnusedValues(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name: "parent",
+			Dependencies: []*chart.Dependency{
+				{Name: "child", Alias: "db", Condition: "db.enabled"},
+			},
+		},
+		Templates: []*chart.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{ define "image" }}{{ .Values.image.repository }}:{{ .Values.image.tag }}{{ end }}`)},
+			{Name: "templates/deployment.yaml", Data: []byte(`image: {{ include "image" . }}
+{{- with .Values.resources }}
+resources: {{ toYaml . }}
+{{- end }}
+{{- range $k, $v := .Values.labels }}{{ $k }}: {{ $.Values.prefix }}{{ $v }}{{ end }}
+port: {{ index .Values "service" "port" }}`)},
+		},
+		Values: map[string]interface{}{
+			"image":     map[string]interface{}{"repository": "nginx", "tag": "1.0", "pullPolicy": "Always"},
+			"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "1"}},
+			"labels":    map[string]interface{}{"app": "web"},
+			"prefix":    "x-",
+			"service":   map[string]interface{}{"port": 80, "type": "ClusterIP"},
+			"legacy":    map[string]interface{}{"enabled": true},
+			"db":        map[string]interface{}{"enabled": true, "host": "db", "port": 5432},
+			"global":    map[string]interface{}{"env": "prod", "region": "eu"},
+			"exports":   map[string]interface{}{"data": map[string]interface{}{"a": 1}},
+		},
+	}
+	c.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "child"},
+		Templates: []*chart.File{
+			{Name: "templates/cm.yaml", Data: []byte(`host: {{ .Values.host }}
+env: {{ .Values.global.env }}`)},
+		},
+	})
+
+	expected := []string{"db.port", "global.region", "image.pullPolicy", "legacy", "service.type"}
+	if got := UnusedValues(c); !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	c.Templates = append(c.Templates, &chart.File{Name: "templates/all.yaml", Data: []byte(`{{ toYaml .Values }}`)})
+	if got := UnusedValues(c); len(got) != 0 {
+		t.Errorf("Expected no unused values when all values are used, got %v", got)
+	}
+
+	c.Templates = append(c.Templates, &chart.File{Name: "templates/broken.yaml", Data: []byte(`{{ .Values.x `)})
+	if got := UnusedValues(c); len(got) != 0 {
+		t.Errorf("Expected no unused values when a template cannot be parsed, got %v", got)
+	}
+}
+
+func TestUnusedValuesOfCreatedChart(t *testing.T) {
+	dir := t.TempDir()
+	path, err := Create("foo", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := loadChart(t, path)
+	if got := UnusedValues(c); len(got) != 0 {
+		t.Errorf("Expected the values of a created chart to be used, got %v", got)
+	}
+}
+
+func TestValidateImportValues(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name: "parent",
+			Dependencies: []*chart.Dependency{{
+				Name: "child",
+				ImportValues: []interface{}{
+					"data",
+					"missing",
+					map[string]interface{}{"child": "config", "parent": "childConfig"},
+					map[string]interface{}{"child": "config.port", "parent": "port"},
+					map[string]interface{}{"child": "nothing"},
+				},
+			}},
+		},
+	}
+	c.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "child"},
+		Values: map[string]interface{}{
+			"exports": map[string]interface{}{"data": map[string]interface{}{"a": 1}},
+			"config":  map[string]interface{}{"port": 80},
+		},
+	})
+
+	errs := ValidateImportValues(c)
+	if len(errs) != 3 {
+		t.Fatalf("Expected 3 errors, got %v", errs)
+	}
+	for i, expect := range []string{`refers to "exports.missing"`, `refers to "config.port", which is not a table`, "must have a child and a parent path"} {
+		if !strings.Contains(errs[i].Error(), expect) {
+			t.Errorf("Expected error %d to contain %q, got %q", i, expect, errs[i])
+		}
+	}
+}
+
+func TestValidateImportValuesChained(t *testing.T) {
+	// subpop imports exports.SCBexported2, which subchart1 imports from
+	// subchartb.
+	c, err := loader.Load("testdata/subpop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := ValidateImportValues(c); len(errs) != 0 {
+		t.Errorf("Expected no errors, got %v", errs)
+	}
+
+	// The values of an aliased dependency are found under its alias.
+	c = &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name: "parent",
+			Dependencies: []*chart.Dependency{{
+				Name:         "child",
+				Alias:        "renamed",
+				ImportValues: []interface{}{map[string]interface{}{"child": "extra", "parent": "extra"}},
+			}},
+		},
+		Values: map[string]interface{}{
+			"renamed": map[string]interface{}{"extra": map[string]interface{}{"a": 1}},
+		},
+	}
+	c.AddDependency(&chart.Chart{Metadata: &chart.Metadata{Name: "child"}})
+	if errs := ValidateImportValues(c); len(errs) != 0 {
+		t.Errorf("Expected no errors, got %v", errs)
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"net/http"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+
+	"helm.sh/helm/v4/internal/version"
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+// ClusterOptions describe how to reach one of several clusters, for SDK users
+// that operate on more clusters than the one of EnvSettings.
+type ClusterOptions struct {
+	// KubeConfig is the path to the kubeconfig file. The default loading
+	// rules apply when it is empty.
+	KubeConfig string
+	// KubeContext is the name of the kubeconfig context. The current context
+	// is used when it is empty.
+	KubeContext string
+	// Namespace is the default namespace. The namespace of the context is
+	// used when it is empty.
+	Namespace string
+	// CacheDir is the directory of the discovery cache. The cache is keyed by
+	// the API server, so getters that use the same directory share the
+	// discovery documents of a cluster. Empty uses the default directory.
+	CacheDir string
+	// BurstLimit is the client-side throttling limit of the cluster. Zero
+	// uses the default limit.
+	BurstLimit int
+	// QPS is the queries per second to the cluster, not including bursting.
+	// Zero uses the library default.
+	QPS float32
+}
+
+// NewRESTClientGetter returns a RESTClientGetter for the cluster described by
+// the options. The clients it creates are configured like the clients of
+// EnvSettings.RESTClientGetter.
+//
+// The getter caches its discovery client and REST mapper, so it should be
+// created once per cluster and shared, e.g. with an action.ConfigurationPool.
+func NewRESTClientGetter(opts ClusterOptions) genericclioptions.RESTClientGetter {
+	burstLimit := opts.BurstLimit
+	if burstLimit <= 0 {
+		burstLimit = defaultBurstLimit
+	}
+
+	config := genericclioptions.NewConfigFlags(true)
+	config.KubeConfig = &opts.KubeConfig
+	config.Context = &opts.KubeContext
+	config.Namespace = &opts.Namespace
+	if opts.CacheDir != "" {
+		config.CacheDir = &opts.CacheDir
+	}
+	config.WrapConfigFn = func(config *rest.Config) *rest.Config {
+		return configureRESTConfig(config, burstLimit, opts.QPS)
+	}
+	if burstLimit != defaultBurstLimit {
+		config = config.WithDiscoveryBurst(burstLimit)
+	}
+	return config
+}
+
+// configureRESTConfig applies the rate limits, the retries and the user agent
+// of Helm to a REST config.
+func configureRESTConfig(config *rest.Config, burstLimit int, qps float32) *rest.Config {
+	config.Burst = burstLimit
+	config.QPS = qps
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &kube.RetryingRoundTripper{Wrapped: rt}
+	})
+	config.UserAgent = version.GetUserAgent()
+	return config
+}
@@ -0,0 +1,84 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"helm.sh/helm/v4/internal/version"
+)
+
+const testKubeConfig = `apiVersion: v1
+kind: Config
+current-context: east
+clusters:
+- name: east
+  cluster:
+    server: https://east.example.com
+- name: west
+  cluster:
+    server: https://west.example.com
+contexts:
+- name: east
+  context:
+    cluster: east
+    namespace: shop
+- name: west
+  context:
+    cluster: west
+users: []
+`
+
+func TestNewRESTClientGetter(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(kubeconfig, []byte(testKubeConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	getter := NewRESTClientGetter(ClusterOptions{KubeConfig: kubeconfig, KubeContext: "west", QPS: 5, BurstLimit: 10})
+	config, err := getter.ToRESTConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Host != "https://west.example.com" {
+		t.Errorf("expected the server of the west context, got %q", config.Host)
+	}
+	if config.QPS != 5 || config.Burst != 10 {
+		t.Errorf("expected a QPS of 5 and a burst limit of 10, got %v and %d", config.QPS, config.Burst)
+	}
+	if config.UserAgent != version.GetUserAgent() {
+		t.Errorf("expected User-Agent header %q, got %q", version.GetUserAgent(), config.UserAgent)
+	}
+
+	getter = NewRESTClientGetter(ClusterOptions{KubeConfig: kubeconfig})
+	config, err = getter.ToRESTConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Host != "https://east.example.com" || config.Burst != defaultBurstLimit {
+		t.Errorf("expected the current context and the default burst limit, got %q and %d", config.Host, config.Burst)
+	}
+	namespace, _, err := getter.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if namespace != "shop" {
+		t.Errorf("expected the namespace of the context, got %q", namespace)
+	}
+}
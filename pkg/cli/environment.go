@@ -25,16 +25,18 @@ package cli
 
 import (
 	"fmt"
-	"net/http"
 	"os"
 	"strconv"
 	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
 
-	"helm.sh/helm/v4/internal/version"
 	"helm.sh/helm/v4/pkg/helmpath"
 	"helm.sh/helm/v4/pkg/kube"
 )
@@ -63,6 +65,10 @@ type EnvSettings struct {
 	KubeAsUser string
 	// Groups to impersonate for the operation, multiple groups parsed from a comma delimited list
 	KubeAsGroups []string
+	// KubeAsServiceAccount is the service account to impersonate for the
+	// operation, as "<namespace>:<name>". It cannot be combined with
+	// KubeAsUser and KubeAsGroups.
+	KubeAsServiceAccount string
 	// Kubernetes API Server Endpoint for authentication
 	KubeAPIServer string
 	// Custom certificate authority file.
@@ -79,8 +85,17 @@ type EnvSettings struct {
 	RegistryConfig string
 	// RepositoryConfig is the path to the repositories file.
 	RepositoryConfig string
+	// RepositoryConfigDir is the path to the directory of read-only
+	// repository fragment files, which are merged with the repositories file.
+	RepositoryConfigDir string
 	// RepositoryCache is the path to the repository cache directory.
 	RepositoryCache string
+	// ContentCache is the path to the directory of the content cache, which
+	// stores the downloaded charts and plugins by digest.
+	ContentCache string
+	// ContentCacheMaxSize is the maximum size of the content cache in bytes,
+	// zero for no limit.
+	ContentCacheMaxSize int64
 	// PluginsDirectory is the path to the plugins directory.
 	PluginsDirectory string
 	// MaxHistory is the max release history maintained.
@@ -89,6 +104,11 @@ type EnvSettings struct {
 	BurstLimit int
 	// QPS is queries per second which may be used to avoid throttling.
 	QPS float32
+	// CacheIncludes memoizes identical includes when rendering charts.
+	CacheIncludes bool
+	// RenderParallelism is the number of workers rendering the subcharts of
+	// a chart in parallel. Values below 2 render sequentially.
+	RenderParallelism int
 }
 
 func New() *EnvSettings {
@@ -99,6 +119,7 @@ func New() *EnvSettings {
 		KubeToken:                 os.Getenv("HELM_KUBETOKEN"),
 		KubeAsUser:                os.Getenv("HELM_KUBEASUSER"),
 		KubeAsGroups:              envCSV("HELM_KUBEASGROUPS"),
+		KubeAsServiceAccount:      os.Getenv("HELM_KUBEASSERVICEACCOUNT"),
 		KubeAPIServer:             os.Getenv("HELM_KUBEAPISERVER"),
 		KubeCaFile:                os.Getenv("HELM_KUBECAFILE"),
 		KubeTLSServerName:         os.Getenv("HELM_KUBETLS_SERVER_NAME"),
@@ -106,11 +127,16 @@ func New() *EnvSettings {
 		PluginsDirectory:          envOr("HELM_PLUGINS", helmpath.DataPath("plugins")),
 		RegistryConfig:            envOr("HELM_REGISTRY_CONFIG", helmpath.ConfigPath("registry/config.json")),
 		RepositoryConfig:          envOr("HELM_REPOSITORY_CONFIG", helmpath.ConfigPath("repositories.yaml")),
+		RepositoryConfigDir:       envOr("HELM_REPOSITORY_CONFIG_DIR", helmpath.ConfigPath("repositories.d")),
 		RepositoryCache:           envOr("HELM_REPOSITORY_CACHE", helmpath.CachePath("repository")),
+		ContentCache:              envOr("HELM_CONTENT_CACHE", helmpath.CachePath("content")),
+		ContentCacheMaxSize:       envSizeOr("HELM_CONTENT_CACHE_MAX_SIZE", 0),
 		BurstLimit:                envIntOr("HELM_BURST_LIMIT", defaultBurstLimit),
 		QPS:                       envFloat32Or("HELM_QPS", defaultQPS),
+		RenderParallelism:         envIntOr("HELM_RENDER_PARALLELISM", 1),
 	}
 	env.Debug, _ = strconv.ParseBool(os.Getenv("HELM_DEBUG"))
+	env.CacheIncludes, _ = strconv.ParseBool(os.Getenv("HELM_CACHE_INCLUDES"))
 
 	// bind to kubernetes config flags
 	config := &genericclioptions.ConfigFlags{
@@ -125,13 +151,10 @@ func New() *EnvSettings {
 		TLSServerName:    &env.KubeTLSServerName,
 		ImpersonateGroup: &env.KubeAsGroups,
 		WrapConfigFn: func(config *rest.Config) *rest.Config {
-			config.Burst = env.BurstLimit
-			config.QPS = env.QPS
-			config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
-				return &kube.RetryingRoundTripper{Wrapped: rt}
-			})
-			config.UserAgent = version.GetUserAgent()
-			return config
+			// An invalid impersonation is returned by the getter of
+			// RESTClientGetter before the config is built.
+			as, _ := env.Impersonation()
+			return configureRESTConfig(as.Apply(config), env.BurstLimit, env.QPS)
 		},
 	}
 	if env.BurstLimit != defaultBurstLimit {
@@ -150,6 +173,7 @@ func (s *EnvSettings) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&s.KubeToken, "kube-token", s.KubeToken, "bearer token used for authentication")
 	fs.StringVar(&s.KubeAsUser, "kube-as-user", s.KubeAsUser, "username to impersonate for the operation")
 	fs.StringArrayVar(&s.KubeAsGroups, "kube-as-group", s.KubeAsGroups, "group to impersonate for the operation, this flag can be repeated to specify multiple groups.")
+	fs.StringVar(&s.KubeAsServiceAccount, "kube-as-service-account", s.KubeAsServiceAccount, "service account to impersonate for the operation, as <namespace>:<name>")
 	fs.StringVar(&s.KubeAPIServer, "kube-apiserver", s.KubeAPIServer, "the address and the port for the Kubernetes API server")
 	fs.StringVar(&s.KubeCaFile, "kube-ca-file", s.KubeCaFile, "the certificate authority file for the Kubernetes API server connection")
 	fs.StringVar(&s.KubeTLSServerName, "kube-tls-server-name", s.KubeTLSServerName, "server name to use for Kubernetes API server certificate validation. If it is not provided, the hostname used to contact the server is used")
@@ -157,7 +181,9 @@ func (s *EnvSettings) AddFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&s.Debug, "debug", s.Debug, "enable verbose output")
 	fs.StringVar(&s.RegistryConfig, "registry-config", s.RegistryConfig, "path to the registry config file")
 	fs.StringVar(&s.RepositoryConfig, "repository-config", s.RepositoryConfig, "path to the file containing repository names and URLs")
+	fs.StringVar(&s.RepositoryConfigDir, "repository-config-dir", s.RepositoryConfigDir, "path to the directory containing read-only repository files, which are merged with the repository config")
 	fs.StringVar(&s.RepositoryCache, "repository-cache", s.RepositoryCache, "path to the directory containing cached repository indexes")
+	fs.StringVar(&s.ContentCache, "content-cache", s.ContentCache, "path to the directory containing the downloaded charts and plugins, stored by digest")
 	fs.IntVar(&s.BurstLimit, "burst-limit", s.BurstLimit, "client-side default throttling limit")
 	fs.Float32Var(&s.QPS, "qps", s.QPS, "queries per second used when communicating with the Kubernetes API, not including bursting")
 }
@@ -205,6 +231,20 @@ func envFloat32Or(name string, def float32) float32 {
 	return float32(ret)
 }
 
+// envSizeOr parses the size of the environment variable, in bytes or as a
+// quantity like "512Mi".
+func envSizeOr(name string, def int64) int64 {
+	envVal, ok := os.LookupEnv(name)
+	if !ok {
+		return def
+	}
+	q, err := resource.ParseQuantity(envVal)
+	if err != nil {
+		return def
+	}
+	return q.Value()
+}
+
 func envCSV(name string) (ls []string) {
 	trimmed := strings.Trim(os.Getenv(name), ", ")
 	if trimmed != "" {
@@ -215,25 +255,31 @@ func envCSV(name string) (ls []string) {
 
 func (s *EnvSettings) EnvVars() map[string]string {
 	envvars := map[string]string{
-		"HELM_BIN":               os.Args[0],
-		"HELM_CACHE_HOME":        helmpath.CachePath(""),
-		"HELM_CONFIG_HOME":       helmpath.ConfigPath(""),
-		"HELM_DATA_HOME":         helmpath.DataPath(""),
-		"HELM_DEBUG":             fmt.Sprint(s.Debug),
-		"HELM_PLUGINS":           s.PluginsDirectory,
-		"HELM_REGISTRY_CONFIG":   s.RegistryConfig,
-		"HELM_REPOSITORY_CACHE":  s.RepositoryCache,
-		"HELM_REPOSITORY_CONFIG": s.RepositoryConfig,
-		"HELM_NAMESPACE":         s.Namespace(),
-		"HELM_MAX_HISTORY":       strconv.Itoa(s.MaxHistory),
-		"HELM_BURST_LIMIT":       strconv.Itoa(s.BurstLimit),
-		"HELM_QPS":               strconv.FormatFloat(float64(s.QPS), 'f', 2, 32),
+		"HELM_BIN":                    os.Args[0],
+		"HELM_CACHE_HOME":             helmpath.CachePath(""),
+		"HELM_CACHE_INCLUDES":         strconv.FormatBool(s.CacheIncludes),
+		"HELM_CONTENT_CACHE":          s.ContentCache,
+		"HELM_CONTENT_CACHE_MAX_SIZE": strconv.FormatInt(s.ContentCacheMaxSize, 10),
+		"HELM_CONFIG_HOME":            helmpath.ConfigPath(""),
+		"HELM_DATA_HOME":              helmpath.DataPath(""),
+		"HELM_DEBUG":                  fmt.Sprint(s.Debug),
+		"HELM_PLUGINS":                s.PluginsDirectory,
+		"HELM_REGISTRY_CONFIG":        s.RegistryConfig,
+		"HELM_REPOSITORY_CACHE":       s.RepositoryCache,
+		"HELM_REPOSITORY_CONFIG":      s.RepositoryConfig,
+		"HELM_REPOSITORY_CONFIG_DIR":  s.RepositoryConfigDir,
+		"HELM_NAMESPACE":              s.Namespace(),
+		"HELM_MAX_HISTORY":            strconv.Itoa(s.MaxHistory),
+		"HELM_BURST_LIMIT":            strconv.Itoa(s.BurstLimit),
+		"HELM_QPS":                    strconv.FormatFloat(float64(s.QPS), 'f', 2, 32),
+		"HELM_RENDER_PARALLELISM":     strconv.Itoa(s.RenderParallelism),
 
 		// broken, these are populated from helm flags and not kubeconfig.
 		"HELM_KUBECONTEXT":                  s.KubeContext,
 		"HELM_KUBETOKEN":                    s.KubeToken,
 		"HELM_KUBEASUSER":                   s.KubeAsUser,
 		"HELM_KUBEASGROUPS":                 strings.Join(s.KubeAsGroups, ","),
+		"HELM_KUBEASSERVICEACCOUNT":         s.KubeAsServiceAccount,
 		"HELM_KUBEAPISERVER":                s.KubeAPIServer,
 		"HELM_KUBECAFILE":                   s.KubeCaFile,
 		"HELM_KUBEINSECURE_SKIP_TLS_VERIFY": strconv.FormatBool(s.KubeInsecureSkipTLSVerify),
@@ -245,6 +291,24 @@ func (s *EnvSettings) EnvVars() map[string]string {
 	return envvars
 }
 
+// Impersonation returns the service account to impersonate set by
+// KubeAsServiceAccount, or the zero Impersonation when it is not set. The
+// user and groups set by KubeAsUser and KubeAsGroups are applied by the
+// kubeconfig loader instead.
+func (s *EnvSettings) Impersonation() (kube.Impersonation, error) {
+	if s.KubeAsServiceAccount == "" {
+		return kube.Impersonation{}, nil
+	}
+	if s.KubeAsUser != "" || len(s.KubeAsGroups) > 0 {
+		return kube.Impersonation{}, errors.New("a service account cannot be impersonated together with a user or groups")
+	}
+	namespace, name, err := kube.ParseServiceAccount(s.KubeAsServiceAccount)
+	if err != nil {
+		return kube.Impersonation{}, err
+	}
+	return kube.ServiceAccountImpersonation(namespace, name), nil
+}
+
 // Namespace gets the namespace from the configuration
 func (s *EnvSettings) Namespace() string {
 	if ns, _, err := s.config.ToRawKubeConfigLoader().Namespace(); err == nil {
@@ -261,7 +325,36 @@ func (s *EnvSettings) SetNamespace(namespace string) {
 	s.namespace = namespace
 }
 
-// RESTClientGetter gets the kubeconfig from EnvSettings
+// RESTClientGetter gets the kubeconfig from EnvSettings. Its clients fail with
+// the error of Impersonation when the impersonation is invalid.
 func (s *EnvSettings) RESTClientGetter() genericclioptions.RESTClientGetter {
-	return s.config
+	return &impersonatingGetter{s.config, s}
+}
+
+// impersonatingGetter checks the impersonation of the settings before building
+// the clients, so that they are never built without it.
+type impersonatingGetter struct {
+	*genericclioptions.ConfigFlags
+	settings *EnvSettings
+}
+
+func (g *impersonatingGetter) ToRESTConfig() (*rest.Config, error) {
+	if _, err := g.settings.Impersonation(); err != nil {
+		return nil, err
+	}
+	return g.ConfigFlags.ToRESTConfig()
+}
+
+func (g *impersonatingGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	if _, err := g.settings.Impersonation(); err != nil {
+		return nil, err
+	}
+	return g.ConfigFlags.ToDiscoveryClient()
+}
+
+func (g *impersonatingGetter) ToRESTMapper() (meta.RESTMapper, error) {
+	if _, err := g.settings.Impersonation(); err != nil {
+		return nil, err
+	}
+	return g.ConfigFlags.ToRESTMapper()
 }
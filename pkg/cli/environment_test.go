@@ -246,6 +246,32 @@ func TestEnvOrBool(t *testing.T) {
 	}
 }
 
+func TestEnvSizeOr(t *testing.T) {
+	const envName = "TEST_ENV_SIZE_OR"
+	tests := []struct {
+		name     string
+		val      string
+		set      bool
+		expected int64
+	}{
+		{name: "unset", expected: 42},
+		{name: "bytes", val: "1024", set: true, expected: 1024},
+		{name: "quantity", val: "512Mi", set: true, expected: 512 << 20},
+		{name: "invalid", val: "lots", set: true, expected: 42},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.set {
+				t.Setenv(envName, tt.val)
+			}
+			if actual := envSizeOr(envName, 42); actual != tt.expected {
+				t.Errorf("expected result %d, got %d", tt.expected, actual)
+			}
+		})
+	}
+}
+
 func TestUserAgentHeaderInK8sRESTClientConfig(t *testing.T) {
 	defer resetEnv()()
 
@@ -261,6 +287,64 @@ func TestUserAgentHeaderInK8sRESTClientConfig(t *testing.T) {
 	}
 }
 
+func TestImpersonateServiceAccount(t *testing.T) {
+	defer resetEnv()()
+
+	os.Setenv("HELM_KUBEASSERVICEACCOUNT", "tenant-a:deployer")
+	settings := New()
+	restConfig, err := settings.RESTClientGetter().ToRESTConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restConfig.Impersonate.UserName != "system:serviceaccount:tenant-a:deployer" {
+		t.Errorf("expected the service account to be impersonated, got %q", restConfig.Impersonate.UserName)
+	}
+	if v := settings.EnvVars()["HELM_KUBEASSERVICEACCOUNT"]; v != "tenant-a:deployer" {
+		t.Errorf("expected HELM_KUBEASSERVICEACCOUNT to be tenant-a:deployer, got %q", v)
+	}
+
+	settings.KubeAsUser = "jane"
+	if _, err := settings.Impersonation(); err == nil {
+		t.Error("expected an error impersonating both a user and a service account")
+	}
+	if _, err := settings.RESTClientGetter().ToRESTConfig(); err == nil {
+		t.Error("expected the config not to be built with an invalid impersonation")
+	}
+	if _, err := settings.RESTClientGetter().ToDiscoveryClient(); err == nil {
+		t.Error("expected the discovery client not to be built with an invalid impersonation")
+	}
+	settings.KubeAsUser = ""
+	settings.KubeAsServiceAccount = "deployer"
+	if _, err := settings.Impersonation(); err == nil {
+		t.Error("expected an error for a service account without a namespace")
+	}
+}
+
+func TestRenderSettings(t *testing.T) {
+	defer resetEnv()()
+
+	os.Setenv("HELM_CACHE_INCLUDES", "1")
+	settings := New()
+	if !settings.CacheIncludes {
+		t.Error("expected CacheIncludes to be enabled by HELM_CACHE_INCLUDES")
+	}
+	if v := settings.EnvVars()["HELM_CACHE_INCLUDES"]; v != "true" {
+		t.Errorf("expected HELM_CACHE_INCLUDES to be true, got %q", v)
+	}
+	if settings.RenderParallelism != 1 {
+		t.Errorf("expected templates to be rendered sequentially by default, got RenderParallelism %d", settings.RenderParallelism)
+	}
+
+	os.Setenv("HELM_RENDER_PARALLELISM", "4")
+	settings = New()
+	if settings.RenderParallelism != 4 {
+		t.Errorf("expected RenderParallelism 4, got %d", settings.RenderParallelism)
+	}
+	if v := settings.EnvVars()["HELM_RENDER_PARALLELISM"]; v != "4" {
+		t.Errorf("expected HELM_RENDER_PARALLELISM to be 4, got %q", v)
+	}
+}
+
 func resetEnv() func() {
 	origEnv := os.Environ()
 
@@ -17,15 +17,21 @@ limitations under the License.
 package values
 
 import (
+	"fmt"
 	"io"
+	"net/http"
 	"net/url"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
 	"sigs.k8s.io/yaml"
 
 	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/registry"
+	"helm.sh/helm/v4/pkg/release"
 	"helm.sh/helm/v4/pkg/strvals"
 )
 
@@ -37,18 +43,119 @@ type Options struct {
 	FileValues    []string // --set-file
 	JSONValues    []string // --set-json
 	LiteralValues []string // --set-literal
+
+	// Strict parses the values of --set, --set-string and --set-file in the
+	// strict parsing mode of strvals, see strvals.ParseStrict.
+	Strict bool // --strict-set
+
+	// Sources configure how the values files of -f/--values and --set-file
+	// are fetched from URLs.
+	Sources []Source // --values-header
+
+	// PreserveAnchors keeps the values file as written, with its anchors,
+	// merge keys and comments, when the values are given by a single values
+	// file, see MergeValuesRaw.
+	PreserveAnchors bool // --preserve-anchors
+	// Record selects what Merge records of the inputs of the values.
+	Record RecordMode // --record-values
+}
+
+// RecordMode selects what is recorded of the inputs of the values, so that a
+// release can be reproduced. The arguments of the --set flags are recorded
+// by all modes but RecordNone, with the values of sensitive keys, e.g.
+// passwords and tokens, redacted.
+type RecordMode string
+
+const (
+	// RecordNone records nothing.
+	RecordNone RecordMode = ""
+	// RecordDigest records the paths and the digests of the values files.
+	RecordDigest RecordMode = "digest"
+	// RecordContent records the paths, the digests and the content of the
+	// values files, with the sensitive values redacted.
+	RecordContent RecordMode = "content"
+)
+
+// Source configures how values files are fetched from the URLs under
+// URLPrefix, e.g. https://config.example.com/prod/ or
+// oci://registry.example.com/values/. A URL is under the prefix when it has
+// the same scheme and host, and its path is the path of the prefix or below
+// it. When several sources match a URL, the one with the longest prefix is
+// used.
+//
+// Values files with the oci scheme are pulled as values artifacts, see
+// registry.Client.PullValues, with the credentials of the registry.
+type Source struct {
+	URLPrefix string
+	// Headers are sent with the requests of HTTP(S) URLs, e.g. an
+	// Authorization header.
+	Headers http.Header
+	// Username and Password are the basic auth credentials of HTTP(S) URLs.
+	Username string
+	Password string
+	// Options are passed to the getter, e.g. getter.WithTLSClientConfig.
+	Options []getter.Option
+}
+
+// AddFlags binds the flags for specifying values to the options.
+func (opts *Options) AddFlags(f *pflag.FlagSet) {
+	f.StringSliceVarP(&opts.ValueFiles, "values", "f", []string{}, "specify values in a YAML file or a URL (can specify multiple)")
+	f.StringArrayVar(&opts.Values, "set", []string{}, "set values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2)")
+	f.StringArrayVar(&opts.StringValues, "set-string", []string{}, "set STRING values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2)")
+	f.StringArrayVar(&opts.FileValues, "set-file", []string{}, "set values from respective files specified via the command line (can specify multiple or separate values with commas: key1=path1,key2=path2)")
+	f.StringArrayVar(&opts.JSONValues, "set-json", []string{}, "set JSON values on the command line (can specify multiple or separate values with commas: key1=jsonval1,key2=jsonval2)")
+	f.StringArrayVar(&opts.LiteralValues, "set-literal", []string{}, "set a literal STRING value on the command line")
+	f.Var(&headerValue{opts}, "values-header", "add an HTTP header to the requests of values files with a URL prefix: PREFIX=Name: value (can specify multiple)")
+	f.BoolVar(&opts.Strict, "strict-set", false, "parse --set, --set-string and --set-file strictly: reject ambiguous values such as 1.0 or True, and allow quoted values such as key=\"a,b\"")
 }
 
 // MergeValues merges values from files specified via -f/--values and directly
 // via --set-json, --set, --set-string, or --set-file, marshaling them to YAML
 func (opts *Options) MergeValues(p getter.Providers) (map[string]interface{}, error) {
+	base, _, err := opts.MergeValuesRaw(p)
+	return base, err
+}
+
+// MergeValuesRaw merges the values like MergeValues. With PreserveAnchors,
+// when the values are given by a single values file and no other flag, it
+// also returns the content of the file, as the merged values are the file
+// with its anchors expanded. The raw values are nil otherwise.
+func (opts *Options) MergeValuesRaw(p getter.Providers) (map[string]interface{}, []byte, error) {
+	merged, err := opts.Merge(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	return merged.Values, merged.Raw, nil
+}
+
+// MergedValues are the values merged by Options.Merge, with what is kept of
+// the inputs they were merged from.
+type MergedValues struct {
+	Values map[string]interface{}
+	// Raw is the values file the values were parsed from, see
+	// MergeValuesRaw.
+	Raw []byte
+	// Sources are the inputs of the values in order, as recorded for the
+	// Record mode of the options.
+	Sources []*release.ValuesSource
+}
+
+// Merge merges the values like MergeValues, keeping the values file as
+// written for PreserveAnchors and recording the inputs for Record.
+func (opts *Options) Merge(p getter.Providers) (*MergedValues, error) {
+	switch opts.Record {
+	case RecordNone, RecordDigest, RecordContent:
+	default:
+		return nil, errors.Errorf("invalid values record mode %q, must be %q or %q", opts.Record, RecordDigest, RecordContent)
+	}
 	base := map[string]interface{}{}
+	merged := &MergedValues{}
 
 	// User specified a values files via -f/--values
 	for _, filePath := range opts.ValueFiles {
 		currentMap := map[string]interface{}{}
 
-		bytes, err := readFile(filePath, p)
+		bytes, err := readFile(filePath, p, opts.getterOptions(filePath)...)
 		if err != nil {
 			return nil, err
 		}
@@ -58,41 +165,58 @@ func (opts *Options) MergeValues(p getter.Providers) (map[string]interface{}, er
 		}
 		// Merge with the previous map
 		base = mergeMaps(base, currentMap)
+		merged.Raw = bytes
+		if err := merged.recordFile(opts.Record, filePath, bytes, currentMap); err != nil {
+			return nil, err
+		}
+	}
+	if !opts.PreserveAnchors || len(opts.ValueFiles) != 1 || opts.hasFlagValues() {
+		merged.Raw = nil
 	}
 
 	// User specified a value via --set-json
 	for _, value := range opts.JSONValues {
 		if err := strvals.ParseJSON(value, base); err != nil {
-			return nil, errors.Errorf("failed parsing --set-json data %s", value)
+			return nil, errors.Wrapf(err, "failed parsing --set-json data %s", value)
 		}
+		merged.recordFlag(opts.Record, "set-json", value, strvals.ParseJSON)
+	}
+
+	parseInto, parseIntoString, parseIntoFile := strvals.ParseInto, strvals.ParseIntoString, strvals.ParseIntoFile
+	if opts.Strict {
+		parseInto, parseIntoString, parseIntoFile = strvals.ParseIntoStrict, strvals.ParseIntoStringStrict, strvals.ParseIntoFileStrict
 	}
 
 	// User specified a value via --set
 	for _, value := range opts.Values {
-		if err := strvals.ParseInto(value, base); err != nil {
+		if err := parseInto(value, base); err != nil {
 			return nil, errors.Wrap(err, "failed parsing --set data")
 		}
+		merged.recordFlag(opts.Record, "set", value, parseInto)
 	}
 
 	// User specified a value via --set-string
 	for _, value := range opts.StringValues {
-		if err := strvals.ParseIntoString(value, base); err != nil {
+		if err := parseIntoString(value, base); err != nil {
 			return nil, errors.Wrap(err, "failed parsing --set-string data")
 		}
+		merged.recordFlag(opts.Record, "set-string", value, parseIntoString)
 	}
 
 	// User specified a value via --set-file
 	for _, value := range opts.FileValues {
 		reader := func(rs []rune) (interface{}, error) {
-			bytes, err := readFile(string(rs), p)
+			bytes, err := readFile(string(rs), p, opts.getterOptions(string(rs))...)
 			if err != nil {
 				return nil, err
 			}
 			return string(bytes), err
 		}
-		if err := strvals.ParseIntoFile(value, base, reader); err != nil {
+		if err := parseIntoFile(value, base, reader); err != nil {
 			return nil, errors.Wrap(err, "failed parsing --set-file data")
 		}
+		// The paths are recorded, not the content of the files.
+		merged.recordFlag(opts.Record, "set-file", value, nil)
 	}
 
 	// User specified a value via --set-literal
@@ -100,9 +224,18 @@ func (opts *Options) MergeValues(p getter.Providers) (map[string]interface{}, er
 		if err := strvals.ParseLiteralInto(value, base); err != nil {
 			return nil, errors.Wrap(err, "failed parsing --set-literal data")
 		}
+		merged.recordFlag(opts.Record, "set-literal", value, strvals.ParseLiteralInto)
 	}
 
-	return base, nil
+	merged.Values = base
+	return merged, nil
+}
+
+// hasFlagValues reports whether values are given by the flags other than
+// -f/--values.
+func (opts *Options) hasFlagValues() bool {
+	return len(opts.JSONValues) > 0 || len(opts.Values) > 0 || len(opts.StringValues) > 0 ||
+		len(opts.FileValues) > 0 || len(opts.LiteralValues) > 0
 }
 
 func mergeMaps(a, b map[string]interface{}) map[string]interface{} {
@@ -124,8 +257,53 @@ func mergeMaps(a, b map[string]interface{}) map[string]interface{} {
 	return out
 }
 
+// getterOptions returns the getter options of the source with the longest
+// prefix of a URL.
+func (opts *Options) getterOptions(u string) []getter.Option {
+	var src *Source
+	for i := range opts.Sources {
+		s := &opts.Sources[i]
+		if underPrefix(u, s.URLPrefix) && (src == nil || len(s.URLPrefix) > len(src.URLPrefix)) {
+			src = s
+		}
+	}
+	if src == nil {
+		return nil
+	}
+
+	var getterOpts []getter.Option
+	if len(src.Headers) > 0 {
+		getterOpts = append(getterOpts, getter.WithHeaders(src.Headers))
+	}
+	if src.Username != "" || src.Password != "" {
+		getterOpts = append(getterOpts, getter.WithBasicAuth(src.Username, src.Password))
+	}
+	return append(getterOpts, src.Options...)
+}
+
+// underPrefix reports whether a URL has the scheme and the host of a prefix,
+// and a path equal to or below the path of the prefix. Unlike a plain string
+// prefix, https://example.com does not match https://example.com.evil.com,
+// nor does https://example.com/prod match https://example.com/production.
+func underPrefix(rawURL, prefix string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	p, err := url.Parse(prefix)
+	if err != nil {
+		return false
+	}
+	if !strings.EqualFold(u.Scheme, p.Scheme) || !strings.EqualFold(u.Host, p.Host) {
+		return false
+	}
+	dir := strings.TrimSuffix(p.Path, "/")
+	return u.Path == dir || strings.HasPrefix(u.Path, dir+"/")
+}
+
 // readFile load a file from stdin, the local directory, or a remote file with a url.
-func readFile(filePath string, p getter.Providers) ([]byte, error) {
+// Files with the oci scheme are pulled as values artifacts.
+func readFile(filePath string, p getter.Providers, options ...getter.Option) ([]byte, error) {
 	if strings.TrimSpace(filePath) == "-" {
 		return io.ReadAll(os.Stdin)
 	}
@@ -139,9 +317,67 @@ func readFile(filePath string, p getter.Providers) ([]byte, error) {
 	if err != nil {
 		return os.ReadFile(filePath)
 	}
-	data, err := g.Get(filePath, getter.WithURL(filePath))
+	getterOpts := []getter.Option{getter.WithURL(filePath)}
+	if u.Scheme == registry.OCIScheme {
+		getterOpts = append(getterOpts, getter.WithValues())
+	}
+	data, err := g.Get(filePath, append(getterOpts, options...)...)
 	if err != nil {
 		return nil, err
 	}
 	return data.Bytes(), err
 }
+
+// headerValue is the pflag.Value of --values-header, which adds headers to
+// the sources of the options.
+type headerValue struct {
+	opts *Options
+}
+
+// String only lists the prefixes and the names of the headers, as their values
+// may be secrets.
+func (h *headerValue) String() string {
+	var headers []string
+	for _, src := range h.opts.Sources {
+		var names []string
+		for name := range src.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			headers = append(headers, fmt.Sprintf("%s=%s", src.URLPrefix, name))
+		}
+	}
+	return "[" + strings.Join(headers, ",") + "]"
+}
+
+func (h *headerValue) Set(s string) error {
+	prefix, header, ok := strings.Cut(s, "=")
+	if !ok || prefix == "" {
+		return errors.Errorf("invalid header %q, expected PREFIX=Name: value", s)
+	}
+	name, value, ok := strings.Cut(header, ":")
+	if name = strings.TrimSpace(name); !ok || name == "" {
+		return errors.Errorf("invalid header %q, expected PREFIX=Name: value", s)
+	}
+
+	var src *Source
+	for i := range h.opts.Sources {
+		if h.opts.Sources[i].URLPrefix == prefix {
+			src = &h.opts.Sources[i]
+		}
+	}
+	if src == nil {
+		h.opts.Sources = append(h.opts.Sources, Source{URLPrefix: prefix})
+		src = &h.opts.Sources[len(h.opts.Sources)-1]
+	}
+	if src.Headers == nil {
+		src.Headers = http.Header{}
+	}
+	src.Headers.Add(name, strings.TrimSpace(value))
+	return nil
+}
+
+func (h *headerValue) Type() string {
+	return "stringArray"
+}
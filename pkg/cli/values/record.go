@@ -0,0 +1,106 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/release"
+)
+
+// recordFile records a values file and the values parsed from it.
+func (m *MergedValues) recordFile(mode RecordMode, filePath string, data []byte, vals map[string]interface{}) error {
+	if mode == RecordNone {
+		return nil
+	}
+	src := &release.ValuesSource{
+		Flag:   "values",
+		Digest: fmt.Sprintf("sha256:%x", sha256.Sum256(data)),
+	}
+	src.Value, src.Redacted = redactURL(filePath)
+	if mode == RecordContent {
+		src.Content = string(data)
+		if redacted, ok := redactValues(vals); ok {
+			out, err := yaml.Marshal(redacted)
+			if err != nil {
+				return errors.Wrapf(err, "failed to record %s", filePath)
+			}
+			src.Content, src.Redacted = string(out), true
+		}
+	}
+	m.Sources = append(m.Sources, src)
+	return nil
+}
+
+// recordFlag records the argument of a --set flag. The values it sets are
+// parsed again with parse to find the sensitive ones; a nil parse records the
+// argument as is.
+func (m *MergedValues) recordFlag(mode RecordMode, flag, value string, parse func(string, map[string]interface{}) error) {
+	if mode == RecordNone {
+		return
+	}
+	src := &release.ValuesSource{Flag: flag, Value: value}
+	if parse != nil {
+		vals := map[string]interface{}{}
+		if err := parse(value, vals); err != nil {
+			src.Value, src.Redacted = chartutil.RedactedValue, true
+		} else if redacted, ok := redactValues(vals); ok {
+			var out bytes.Buffer
+			enc := json.NewEncoder(&out)
+			enc.SetEscapeHTML(false)
+			if err := enc.Encode(redacted); err != nil {
+				src.Value, src.Redacted = chartutil.RedactedValue, true
+			} else {
+				src.Value, src.Redacted = strings.TrimSuffix(out.String(), "\n"), true
+			}
+		}
+	}
+	m.Sources = append(m.Sources, src)
+}
+
+// redactURL returns the URL of a values file without its user information and
+// query, which may hold credentials, and whether they were removed. Paths are
+// returned unchanged.
+func redactURL(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" || (u.User == nil && u.RawQuery == "") {
+		return rawURL, false
+	}
+	u.User, u.RawQuery, u.ForceQuery = nil, "", false
+	return u.String(), true
+}
+
+// redactValues returns the values with the sensitive ones redacted, and
+// whether there were any.
+func redactValues(vals map[string]interface{}) (map[string]interface{}, bool) {
+	redacted := chartutil.RedactValues(vals, chartutil.DefaultSensitiveKeyPattern)
+	before, err1 := json.Marshal(vals)
+	after, err2 := json.Marshal(redacted)
+	if err1 != nil || err2 != nil {
+		return redacted, true
+	}
+	return redacted, !bytes.Equal(before, after)
+}
@@ -0,0 +1,235 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/internal/fileutil"
+)
+
+// The types of the content stored in a Cache. The type of an entry is the
+// extension of its file.
+const (
+	// CacheChart is the type of chart archives.
+	CacheChart = ".chart"
+	// CachePlugin is the type of plugin archives.
+	CachePlugin = ".plugin"
+)
+
+// Cache stores downloaded content by the SHA-256 digest of the content, so
+// that the same chart is only downloaded once, whichever repository or
+// registry serves it.
+type Cache interface {
+	// Get returns the path of the cached content with the digest. It returns
+	// an error satisfying errors.Is(err, fs.ErrNotExist) when the content is
+	// not cached.
+	Get(key [sha256.Size]byte, cacheType string) (string, error)
+	// Put stores the content with the digest and returns its path.
+	Put(key [sha256.Size]byte, data []byte, cacheType string) (string, error)
+}
+
+// CacheEntry describes content stored in a DiskCache.
+type CacheEntry struct {
+	// Digest is the digest of the content, e.g. "sha256:4b0e...".
+	Digest string `json:"digest"`
+	// Type is the type of the content, e.g. CacheChart.
+	Type string `json:"type"`
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	// LastUsed is the last time the content was stored or read.
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// DiskCache is a Cache storing the content in files below a directory.
+//
+// The integrity of the content is verified whenever it is read: content not
+// matching its digest is removed and reported as not cached.
+type DiskCache struct {
+	// Root is the directory of the cache.
+	Root string
+	// MaxSize is the maximum total size of the content in bytes. When it is
+	// exceeded, the least recently used content is evicted. Zero disables
+	// the eviction.
+	MaxSize int64
+}
+
+// Get returns the path of the cached content with the digest, after
+// verifying it.
+func (c *DiskCache) Get(key [sha256.Size]byte, cacheType string) (string, error) {
+	p := c.path(key, cacheType)
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return "", err
+	}
+	if sha256.Sum256(data) != key {
+		os.Remove(p)
+		return "", errors.Wrapf(fs.ErrNotExist, "cached content %s is corrupted", p)
+	}
+	// The modification time tracks the use of the content for the eviction.
+	now := time.Now()
+	os.Chtimes(p, now, now)
+	return p, nil
+}
+
+// Put stores the content with the digest, evicting the least recently used
+// content when the cache exceeds its maximum size.
+func (c *DiskCache) Put(key [sha256.Size]byte, data []byte, cacheType string) (string, error) {
+	if sha256.Sum256(data) != key {
+		return "", errors.Errorf("content does not match the digest sha256:%x", key)
+	}
+	p := c.path(key, cacheType)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return "", err
+	}
+	if err := fileutil.AtomicWriteFile(p, bytes.NewReader(data), 0644); err != nil {
+		return "", err
+	}
+	if c.MaxSize > 0 {
+		if _, err := c.Prune(c.MaxSize); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// Entries returns the content of the cache, most recently used first.
+func (c *DiskCache) Entries() ([]CacheEntry, error) {
+	var entries []CacheEntry
+	err := filepath.WalkDir(c.Root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		ext := filepath.Ext(p)
+		name := strings.TrimSuffix(d.Name(), ext)
+		if b, err := hex.DecodeString(name); err != nil || len(b) != sha256.Size || !isCacheType(ext) {
+			// Temporary files of writes in progress, or unrelated files.
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entries = append(entries, CacheEntry{
+			Digest:   "sha256:" + name,
+			Type:     ext,
+			Path:     p,
+			Size:     info.Size(),
+			LastUsed: info.ModTime(),
+		})
+		return nil
+	})
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+	return entries, err
+}
+
+// Prune evicts the least recently used content until the total size of the
+// cache is at most maxSize, and returns the evicted entries.
+func (c *DiskCache) Prune(maxSize int64) ([]CacheEntry, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return nil, err
+	}
+	var size int64
+	var evicted []CacheEntry
+	for _, e := range entries {
+		if size+e.Size <= maxSize {
+			size += e.Size
+			continue
+		}
+		if err := os.Remove(e.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return evicted, err
+		}
+		evicted = append(evicted, e)
+	}
+	return evicted, nil
+}
+
+// Verify removes the content not matching its digest, and returns the
+// removed entries.
+func (c *DiskCache) Verify() ([]CacheEntry, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return nil, err
+	}
+	var corrupted []CacheEntry
+	for _, e := range entries {
+		data, err := os.ReadFile(e.Path)
+		if err != nil {
+			return corrupted, err
+		}
+		if fmt.Sprintf("sha256:%x", sha256.Sum256(data)) == e.Digest {
+			continue
+		}
+		if err := os.Remove(e.Path); err != nil {
+			return corrupted, err
+		}
+		corrupted = append(corrupted, e)
+	}
+	return corrupted, nil
+}
+
+// path returns the path of the content, in a subdirectory named after the
+// first byte of the digest to keep the directories small.
+func (c *DiskCache) path(key [sha256.Size]byte, cacheType string) string {
+	return filepath.Join(c.Root, fmt.Sprintf("%02x", key[0]), fmt.Sprintf("%x", key)+cacheType)
+}
+
+// isCacheType returns whether the extension is a cache type, which, unlike
+// the extension of a temporary file, only contains letters.
+func isCacheType(ext string) bool {
+	if len(ext) < 2 {
+		return false
+	}
+	for _, r := range ext[1:] {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseCacheKey parses a SHA-256 digest, as "sha256:<hex>" or "<hex>", into
+// the key of a Cache.
+func ParseCacheKey(digest string) ([sha256.Size]byte, error) {
+	var key [sha256.Size]byte
+	b, err := hex.DecodeString(strings.TrimPrefix(strings.ToLower(digest), "sha256:"))
+	if err != nil || len(b) != sha256.Size {
+		return key, errors.Errorf("invalid SHA-256 digest %q", digest)
+	}
+	copy(key[:], b)
+	return key, nil
+}
@@ -0,0 +1,227 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+func TestDiskCache(t *testing.T) {
+	c := &DiskCache{Root: t.TempDir()}
+	data := []byte("chart content")
+	key := sha256.Sum256(data)
+
+	if _, err := c.Get(key, CacheChart); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected a miss, got %v", err)
+	}
+	if _, err := c.Put(sha256.Sum256([]byte("other")), data, CacheChart); err == nil {
+		t.Fatal("expected an error storing content not matching its digest")
+	}
+
+	p, err := c.Put(key, data, CacheChart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.Get(key, CacheChart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != p {
+		t.Errorf("expected path %s, got %s", p, got)
+	}
+	if _, err := c.Get(key, CachePlugin); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected a miss for another type, got %v", err)
+	}
+
+	// Corrupted content is removed when read.
+	if err := os.WriteFile(p, []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(key, CacheChart); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected a miss for corrupted content, got %v", err)
+	}
+	if _, err := os.Stat(p); !os.IsNotExist(err) {
+		t.Errorf("expected corrupted content to be removed, got %v", err)
+	}
+}
+
+func TestDiskCacheEntries(t *testing.T) {
+	c := &DiskCache{Root: t.TempDir()}
+	if entries, err := c.Entries(); err != nil || len(entries) != 0 {
+		t.Fatalf("expected no entries, got %v, %v", entries, err)
+	}
+
+	p := putAt(t, c, "old", CacheChart, time.Now().Add(-time.Hour))
+	putAt(t, c, "new", CachePlugin, time.Now())
+	// A temporary file left by an interrupted write.
+	if err := os.WriteFile(p+"123456", []byte("partial"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := c.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if expect := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("new"))); entries[0].Digest != expect {
+		t.Errorf("expected the most recently used entry %s first, got %s", expect, entries[0].Digest)
+	}
+	if entries[0].Type != CachePlugin || entries[0].Size != 3 {
+		t.Errorf("unexpected entry %+v", entries[0])
+	}
+}
+
+func TestDiskCachePrune(t *testing.T) {
+	c := &DiskCache{Root: t.TempDir()}
+	now := time.Now()
+	putAt(t, c, "aaaa", CacheChart, now.Add(-3*time.Hour))
+	putAt(t, c, "bbbb", CacheChart, now.Add(-2*time.Hour))
+	putAt(t, c, "cccc", CacheChart, now.Add(-1*time.Hour))
+
+	evicted, err := c.Prune(8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evicted) != 1 || evicted[0].Digest != fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("aaaa"))) {
+		t.Errorf("expected the least recently used entry to be evicted, got %+v", evicted)
+	}
+
+	// Put evicts to the maximum size.
+	c.MaxSize = 8
+	putAt(t, c, "dddd", CacheChart, now)
+	entries, err := c.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if _, err := c.Get(sha256.Sum256([]byte("bbbb")), CacheChart); err == nil {
+		t.Error("expected the least recently used entry to be evicted by Put")
+	}
+
+	evicted, err = c.Prune(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evicted) != 2 {
+		t.Errorf("expected all the entries to be evicted, got %d", len(evicted))
+	}
+}
+
+func TestDiskCacheVerify(t *testing.T) {
+	c := &DiskCache{Root: t.TempDir()}
+	putAt(t, c, "good", CacheChart, time.Now())
+	p := putAt(t, c, "bad", CacheChart, time.Now())
+	if err := os.WriteFile(p, []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := c.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0].Path != p {
+		t.Errorf("expected %s to be removed, got %+v", p, removed)
+	}
+	if entries, _ := c.Entries(); len(entries) != 1 {
+		t.Errorf("expected 1 entry left, got %d", len(entries))
+	}
+}
+
+func TestParseCacheKey(t *testing.T) {
+	key := sha256.Sum256([]byte("chart"))
+	for _, digest := range []string{fmt.Sprintf("sha256:%x", key), fmt.Sprintf("%X", key)} {
+		got, err := ParseCacheKey(digest)
+		if err != nil {
+			t.Errorf("%s: %s", digest, err)
+		} else if got != key {
+			t.Errorf("%s: expected %x, got %x", digest, key, got)
+		}
+	}
+	for _, digest := range []string{"", "sha256:abc", "sha256:" + strings.Repeat("zz", sha256.Size)} {
+		if _, err := ParseCacheKey(digest); err == nil {
+			t.Errorf("%q: expected an error", digest)
+		}
+	}
+}
+
+type countingGetter struct {
+	data  []byte
+	calls int
+}
+
+func (g *countingGetter) Get(_ string, _ ...getter.Option) (*bytes.Buffer, error) {
+	g.calls++
+	return bytes.NewBuffer(g.data), nil
+}
+
+func TestChartDownloaderCache(t *testing.T) {
+	data := []byte("chart archive")
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+	g := &countingGetter{data: data}
+	c := ChartDownloader{Out: os.Stderr, Cache: &DiskCache{Root: t.TempDir()}}
+	u, _ := url.Parse("https://example.com/charts/chart-0.1.0.tgz")
+
+	for i := 0; i < 2; i++ {
+		got, err := c.get(g, u, digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got.Bytes(), data) {
+			t.Errorf("expected %q, got %q", data, got.Bytes())
+		}
+	}
+	if g.calls != 1 {
+		t.Errorf("expected the chart to be downloaded once, got %d downloads", g.calls)
+	}
+
+	// Without a digest, the chart is downloaded but still cached.
+	if _, err := c.get(g, u, ""); err != nil {
+		t.Fatal(err)
+	}
+	if g.calls != 2 {
+		t.Errorf("expected the chart to be downloaded without a digest, got %d downloads", g.calls)
+	}
+}
+
+func putAt(t *testing.T, c *DiskCache, content, cacheType string, lastUsed time.Time) string {
+	t.Helper()
+	data := []byte(content)
+	p, err := c.Put(sha256.Sum256(data), data, cacheType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(p, lastUsed, lastUsed); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
@@ -16,6 +16,8 @@ limitations under the License.
 package downloader
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"net/url"
@@ -71,7 +73,15 @@ type ChartDownloader struct {
 	Options          []getter.Option
 	RegistryClient   *registry.Client
 	RepositoryConfig string
-	RepositoryCache  string
+	// RepositoryConfigDir is the directory of the repository fragment files
+	// merged with RepositoryConfig, see repo.LoadFiles.
+	RepositoryConfigDir string
+	RepositoryCache     string
+	// Cache, when set, stores the downloaded charts by digest. Charts whose
+	// digest is known before the download, from the index of their repository
+	// or the manifest of their OCI artifact, are read from it instead of
+	// being downloaded again.
+	Cache Cache
 }
 
 // DownloadTo retrieves a chart. Depending on the settings, it may also download a provenance file.
@@ -86,7 +96,7 @@ type ChartDownloader struct {
 // Returns a string path to the location where the file was downloaded and a verification
 // (if provenance was verified), or an error if something bad happened.
 func (c *ChartDownloader) DownloadTo(ref, version, dest string) (string, *provenance.Verification, error) {
-	u, err := c.ResolveChartVersion(ref, version)
+	u, digest, err := c.resolveChartVersion(ref, version)
 	if err != nil {
 		return "", nil, err
 	}
@@ -98,7 +108,7 @@ func (c *ChartDownloader) DownloadTo(ref, version, dest string) (string, *proven
 
 	c.Options = append(c.Options, getter.WithAcceptHeader("application/gzip,application/octet-stream"))
 
-	data, err := g.Get(u.String(), c.Options...)
+	data, err := c.get(g, u, digest)
 	if err != nil {
 		return "", nil, err
 	}
@@ -142,6 +152,35 @@ func (c *ChartDownloader) DownloadTo(ref, version, dest string) (string, *proven
 	return destfile, ver, nil
 }
 
+// get returns the chart at the URL from the cache when the digest of the chart
+// is known, or downloads it and adds it to the cache.
+func (c *ChartDownloader) get(g getter.Getter, u *url.URL, digest string) (*bytes.Buffer, error) {
+	if c.Cache == nil {
+		return g.Get(u.String(), c.Options...)
+	}
+
+	if digest == "" && u.Scheme == registry.OCIScheme && c.RegistryClient != nil {
+		// A failure only means that the cache is not used.
+		digest, _ = c.RegistryClient.ChartDigest(strings.TrimPrefix(u.String(), registry.OCIScheme+"://"))
+	}
+	if key, err := ParseCacheKey(digest); err == nil {
+		if p, err := c.Cache.Get(key, CacheChart); err == nil {
+			if data, err := os.ReadFile(p); err == nil {
+				return bytes.NewBuffer(data), nil
+			}
+		}
+	}
+
+	data, err := g.Get(u.String(), c.Options...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.Cache.Put(sha256.Sum256(data.Bytes()), data.Bytes(), CacheChart); err != nil {
+		fmt.Fprintf(c.Out, "WARNING: unable to cache %s: %s\n", u, err)
+	}
+	return data, nil
+}
+
 // ResolveChartVersion resolves a chart reference to a URL.
 //
 // It returns the URL and sets the ChartDownloader's Options that can fetch
@@ -158,18 +197,27 @@ func (c *ChartDownloader) DownloadTo(ref, version, dest string) (string, *proven
 //   - If version is empty, this will return the URL for the latest version
 //   - If no version can be found, an error is returned
 func (c *ChartDownloader) ResolveChartVersion(ref, version string) (*url.URL, error) {
+	u, _, err := c.resolveChartVersion(ref, version)
+	return u, err
+}
+
+// resolveChartVersion resolves a chart reference like ResolveChartVersion,
+// and also returns the digest of the chart when the index of its repository
+// provides it.
+func (c *ChartDownloader) resolveChartVersion(ref, version string) (*url.URL, string, error) {
 	u, err := url.Parse(ref)
 	if err != nil {
-		return nil, errors.Errorf("invalid chart URL format: %s", ref)
+		return nil, "", errors.Errorf("invalid chart URL format: %s", ref)
 	}
 
 	if registry.IsOCI(u.String()) {
-		return c.RegistryClient.ValidateReference(ref, version, u)
+		u, err := c.RegistryClient.ValidateReference(ref, version, u)
+		return u, "", err
 	}
 
-	rf, err := loadRepoConfig(c.RepositoryConfig)
+	rf, err := loadRepoConfig(c.RepositoryConfig, c.RepositoryConfigDir)
 	if err != nil {
-		return u, err
+		return u, "", err
 	}
 
 	if u.IsAbs() && len(u.Host) > 0 && len(u.Path) > 0 {
@@ -179,16 +227,16 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (*url.URL, er
 		// we want to find the repo in case we have special SSL cert config
 		// for that repo.
 
-		rc, err := c.scanReposForURL(ref, rf)
+		rc, digest, err := c.scanReposForURL(ref, rf)
 		if err != nil {
 			// If there is no special config, return the default HTTP client and
 			// swallow the error.
 			if err == ErrNoOwnerRepo {
 				// Make sure to add the ref URL as the URL for the getter
 				c.Options = append(c.Options, getter.WithURL(ref))
-				return u, nil
+				return u, "", nil
 			}
-			return u, err
+			return u, "", err
 		}
 
 		// If we get here, we don't need to go through the next phase of looking
@@ -200,6 +248,7 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (*url.URL, er
 		if rc.CertFile != "" || rc.KeyFile != "" || rc.CAFile != "" {
 			c.Options = append(c.Options, getter.WithTLSClientConfig(rc.CertFile, rc.KeyFile, rc.CAFile))
 		}
+		c.Options = append(c.Options, getter.WithProxy(rc.Proxy), getter.WithMinTLSVersion(rc.MinTLSVersion))
 		if rc.Username != "" && rc.Password != "" {
 			c.Options = append(
 				c.Options,
@@ -207,13 +256,13 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (*url.URL, er
 				getter.WithPassCredentialsAll(rc.PassCredentialsAll),
 			)
 		}
-		return u, nil
+		return u, digest, nil
 	}
 
 	// See if it's of the form: repo/path_to_chart
 	p := strings.SplitN(u.Path, "/", 2)
 	if len(p) < 2 {
-		return u, errors.Errorf("non-absolute URLs should be in form of repo_name/path_to_chart, got: %s", u)
+		return u, "", errors.Errorf("non-absolute URLs should be in form of repo_name/path_to_chart, got: %s", u)
 	}
 
 	repoName := p[0]
@@ -221,7 +270,7 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (*url.URL, er
 	rc, err := pickChartRepositoryConfigByName(repoName, rf.Repositories)
 
 	if err != nil {
-		return u, err
+		return u, "", err
 	}
 
 	// Now that we have the chart repository information we can use that URL
@@ -230,13 +279,14 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (*url.URL, er
 
 	r, err := repo.NewChartRepository(rc, c.Getters)
 	if err != nil {
-		return u, err
+		return u, "", err
 	}
 
 	if r != nil && r.Config != nil {
 		if r.Config.CertFile != "" || r.Config.KeyFile != "" || r.Config.CAFile != "" {
 			c.Options = append(c.Options, getter.WithTLSClientConfig(r.Config.CertFile, r.Config.KeyFile, r.Config.CAFile))
 		}
+		c.Options = append(c.Options, getter.WithProxy(r.Config.Proxy), getter.WithMinTLSVersion(r.Config.MinTLSVersion))
 		if r.Config.Username != "" && r.Config.Password != "" {
 			c.Options = append(c.Options,
 				getter.WithBasicAuth(r.Config.Username, r.Config.Password),
@@ -249,26 +299,27 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (*url.URL, er
 	idxFile := filepath.Join(c.RepositoryCache, helmpath.CacheIndexFile(r.Config.Name))
 	i, err := repo.LoadIndexFile(idxFile)
 	if err != nil {
-		return u, errors.Wrap(err, "no cached repo found. (try 'helm repo update')")
+		return u, "", errors.Wrap(err, "no cached repo found. (try 'helm repo update')")
 	}
 
 	cv, err := i.Get(chartName, version)
 	if err != nil {
-		return u, errors.Wrapf(err, "chart %q matching %s not found in %s index. (try 'helm repo update')", chartName, version, r.Config.Name)
+		return u, "", errors.Wrapf(err, "chart %q matching %s not found in %s index. (try 'helm repo update')", chartName, version, r.Config.Name)
 	}
 
 	if len(cv.URLs) == 0 {
-		return u, errors.Errorf("chart %q has no downloadable URLs", ref)
+		return u, "", errors.Errorf("chart %q has no downloadable URLs", ref)
 	}
 
 	// TODO: Seems that picking first URL is not fully correct
 	resolvedURL, err := repo.ResolveReferenceURL(rc.URL, cv.URLs[0])
 
 	if err != nil {
-		return u, errors.Errorf("invalid chart URL format: %s", ref)
+		return u, "", errors.Errorf("invalid chart URL format: %s", ref)
 	}
 
-	return url.Parse(resolvedURL)
+	u, err = url.Parse(resolvedURL)
+	return u, cv.Digest, err
 }
 
 // VerifyChart takes a path to a chart archive and a keyring, and verifies the chart.
@@ -318,7 +369,8 @@ func pickChartRepositoryConfigByName(name string, cfgs []*repo.Entry) (*repo.Ent
 	return nil, errors.Errorf("repo %s not found", name)
 }
 
-// scanReposForURL scans all repos to find which repo contains the given URL.
+// scanReposForURL scans all repos to find which repo contains the given URL,
+// and returns the repo and the digest of the chart at the URL.
 //
 // This will attempt to find the given URL in all of the known repositories files.
 //
@@ -336,38 +388,38 @@ func pickChartRepositoryConfigByName(name string, cfgs []*repo.Entry) (*repo.Ent
 // The same URL can technically exist in two or more repositories. This algorithm
 // will return the first one it finds. Order is determined by the order of repositories
 // in the repositories.yaml file.
-func (c *ChartDownloader) scanReposForURL(u string, rf *repo.File) (*repo.Entry, error) {
+func (c *ChartDownloader) scanReposForURL(u string, rf *repo.File) (*repo.Entry, string, error) {
 	// FIXME: This is far from optimal. Larger installations and index files will
 	// incur a performance hit for this type of scanning.
 	for _, rc := range rf.Repositories {
 		r, err := repo.NewChartRepository(rc, c.Getters)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 
 		idxFile := filepath.Join(c.RepositoryCache, helmpath.CacheIndexFile(r.Config.Name))
 		i, err := repo.LoadIndexFile(idxFile)
 		if err != nil {
-			return nil, errors.Wrap(err, "no cached repo found. (try 'helm repo update')")
+			return nil, "", errors.Wrap(err, "no cached repo found. (try 'helm repo update')")
 		}
 
 		for _, entry := range i.Entries {
 			for _, ver := range entry {
 				for _, dl := range ver.URLs {
 					if urlutil.Equal(u, dl) {
-						return rc, nil
+						return rc, ver.Digest, nil
 					}
 				}
 			}
 		}
 	}
 	// This means that there is no repo file for the given URL.
-	return nil, ErrNoOwnerRepo
+	return nil, "", ErrNoOwnerRepo
 }
 
-func loadRepoConfig(file string) (*repo.File, error) {
-	r, err := repo.LoadFile(file)
-	if err != nil && !os.IsNotExist(errors.Cause(err)) {
+func loadRepoConfig(file, dir string) (*repo.File, error) {
+	r, err := repo.LoadFiles(file, dir)
+	if err != nil {
 		return nil, err
 	}
 	return r, nil
@@ -20,5 +20,9 @@ This package contains various tools for downloading charts from repository
 servers, and then storing them in Helm-specific directory structures. This
 library contains many functions that depend on a specific
 filesystem layout.
+
+Downloaded content can be stored in a Cache, which is content-addressable:
+archives are keyed by their SHA-256 digest, so the same chart is only
+downloaded once whether it is served by a chart repository or an OCI registry.
 */
 package downloader
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"maps"
 	"net/url"
 	"os"
 	"path"
@@ -74,7 +75,17 @@ type Manager struct {
 	Getters          []getter.Provider
 	RegistryClient   *registry.Client
 	RepositoryConfig string
-	RepositoryCache  string
+	// RepositoryConfigDir is the directory of the repository fragment files
+	// merged with RepositoryConfig, see repo.LoadFiles.
+	RepositoryConfigDir string
+	RepositoryCache     string
+	// RepositoryOptions are additional getter options by repository URL, e.g.
+	// the headers of a private chart server. They are only used to download
+	// the index file and the charts of the repository they are given for.
+	RepositoryOptions map[string][]getter.Option
+	// Cache, when set, stores the downloaded dependencies by digest, see
+	// ChartDownloader.Cache.
+	Cache Cache
 }
 
 // Build rebuilds a local charts directory from a lockfile.
@@ -208,9 +219,17 @@ func (m *Manager) Update() error {
 	}
 	lock.Digest = newDigest
 
+	// Record the digests of the fetched dependencies, to detect when they are
+	// modified.
+	fetched, err := m.loadChartDir()
+	if err != nil {
+		return err
+	}
+	lock.Digests = chartutil.DependencyDigests(fetched)
+
 	// If the lock file hasn't changed, don't write a new one.
 	oldLock := c.Lock
-	if oldLock != nil && oldLock.Digest == lock.Digest {
+	if oldLock != nil && oldLock.Digest == lock.Digest && maps.Equal(oldLock.Digests, lock.Digests) {
 		return nil
 	}
 
@@ -326,13 +345,15 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 		fmt.Fprintf(m.Out, "Downloading %s from repo %s\n", dep.Name, dep.Repository)
 
 		dl := ChartDownloader{
-			Out:              m.Out,
-			Verify:           m.Verify,
-			Keyring:          m.Keyring,
-			RepositoryConfig: m.RepositoryConfig,
-			RepositoryCache:  m.RepositoryCache,
-			RegistryClient:   m.RegistryClient,
-			Getters:          m.Getters,
+			Out:                 m.Out,
+			Verify:              m.Verify,
+			Keyring:             m.Keyring,
+			RepositoryConfig:    m.RepositoryConfig,
+			RepositoryConfigDir: m.RepositoryConfigDir,
+			RepositoryCache:     m.RepositoryCache,
+			RegistryClient:      m.RegistryClient,
+			Getters:             m.Getters,
+			Cache:               m.Cache,
 			Options: []getter.Option{
 				getter.WithBasicAuth(username, password),
 				getter.WithPassCredentialsAll(passcredentialsall),
@@ -340,6 +361,8 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 				getter.WithTLSClientConfig(certFile, keyFile, caFile),
 			},
 		}
+		dl.Options = append(dl.Options, repoTransportOptions(dep.Repository, repos)...)
+		dl.Options = append(dl.Options, m.repositoryOptions(dep.Repository)...)
 
 		version := ""
 		if registry.IsOCI(churl) {
@@ -460,7 +483,7 @@ func (m *Manager) safeMoveDeps(deps []*chart.Dependency, source, dest string) er
 
 // hasAllRepos ensures that all of the referenced deps are in the local repo cache.
 func (m *Manager) hasAllRepos(deps []*chart.Dependency) error {
-	rf, err := loadRepoConfig(m.RepositoryConfig)
+	rf, err := loadRepoConfig(m.RepositoryConfig, m.RepositoryConfigDir)
 	if err != nil {
 		return err
 	}
@@ -557,7 +580,7 @@ func (m *Manager) ensureMissingRepos(repoNames map[string]string, deps []*chart.
 // resolveRepoNames returns the repo names of the referenced deps which can be used to fetch the cached index file
 // and replaces aliased repository URLs into resolved URLs in dependencies.
 func (m *Manager) resolveRepoNames(deps []*chart.Dependency) (map[string]string, error) {
-	rf, err := loadRepoConfig(m.RepositoryConfig)
+	rf, err := loadRepoConfig(m.RepositoryConfig, m.RepositoryConfigDir)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return make(map[string]string), nil
@@ -643,7 +666,7 @@ repository, use "https://charts.example.com/" or "@example" instead of
 
 // UpdateRepositories updates all of the local repos to the latest.
 func (m *Manager) UpdateRepositories() error {
-	rf, err := loadRepoConfig(m.RepositoryConfig)
+	rf, err := loadRepoConfig(m.RepositoryConfig, m.RepositoryConfigDir)
 	if err != nil {
 		return err
 	}
@@ -668,6 +691,7 @@ func (m *Manager) parallelRepoUpdate(repos []*repo.Entry) error {
 			return err
 		}
 		r.CachePath = m.RepositoryCache
+		r.Options = m.repositoryOptions(c.URL)
 		wg.Add(1)
 		go func(r *repo.ChartRepository) {
 			if _, err := r.DownloadIndexFile(); err != nil {
@@ -695,6 +719,31 @@ func (m *Manager) parallelRepoUpdate(repos []*repo.Entry) error {
 	return nil
 }
 
+// repoTransportOptions returns the proxy and TLS version settings of the
+// configured repository with the given URL.
+func repoTransportOptions(repoURL string, repos map[string]*repo.ChartRepository) []getter.Option {
+	for _, cr := range repos {
+		if urlutil.Equal(repoURL, cr.Config.URL) {
+			return []getter.Option{
+				getter.WithProxy(cr.Config.Proxy),
+				getter.WithMinTLSVersion(cr.Config.MinTLSVersion),
+			}
+		}
+	}
+	return nil
+}
+
+// repositoryOptions returns the RepositoryOptions of the repository with the
+// given URL.
+func (m *Manager) repositoryOptions(repoURL string) []getter.Option {
+	for u, opts := range m.RepositoryOptions {
+		if urlutil.Equal(repoURL, u) {
+			return opts
+		}
+	}
+	return nil
+}
+
 // findChartURL searches the cache of repo data for a chart that has the name and the repoURL specified.
 //
 // 'name' is the name of the chart. Version is an exact semver, or an empty string. If empty, the
@@ -742,7 +791,7 @@ func (m *Manager) findChartURL(name, version, repoURL string, repos map[string]*
 			return
 		}
 	}
-	url, err = repo.FindChartInRepoURL(repoURL, name, version, certFile, keyFile, caFile, m.Getters)
+	url, err = repo.FindChartInRepoURLWithOptions(repoURL, "", "", name, version, certFile, keyFile, caFile, false, false, m.Getters, m.repositoryOptions(repoURL)...)
 	if err == nil {
 		return url, username, password, false, false, "", "", "", err
 	}
@@ -818,7 +867,7 @@ func (m *Manager) loadChartRepositories() (map[string]*repo.ChartRepository, err
 	indices := map[string]*repo.ChartRepository{}
 
 	// Load repositories.yaml file
-	rf, err := loadRepoConfig(m.RepositoryConfig)
+	rf, err := loadRepoConfig(m.RepositoryConfig, m.RepositoryConfigDir)
 	if err != nil {
 		return indices, errors.Wrapf(err, "failed to load %s", m.RepositoryConfig)
 	}
@@ -17,9 +17,12 @@ package downloader
 
 import (
 	"bytes"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"reflect"
+	"sync"
 	"testing"
 
 	"helm.sh/helm/v4/pkg/chart"
@@ -358,6 +361,72 @@ func TestUpdateBeforeBuild(t *testing.T) {
 	}
 }
 
+func TestUpdateRecordsDependencyDigests(t *testing.T) {
+	dir := t.TempDir()
+	d := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:       "dep-chart",
+			Version:    "0.1.0",
+			APIVersion: "v2",
+		},
+	}
+	if err := chartutil.SaveDir(d, dir); err != nil {
+		t.Fatal(err)
+	}
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:       "with-dependency",
+			Version:    "0.1.0",
+			APIVersion: "v2",
+			Dependencies: []*chart.Dependency{{
+				Name:       d.Metadata.Name,
+				Version:    ">=0.1.0",
+				Repository: "file://../dep-chart",
+			}},
+		},
+	}
+	if err := chartutil.SaveDir(c, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Manager{
+		ChartPath:        filepath.Join(dir, c.Metadata.Name),
+		Out:              io.Discard,
+		RepositoryConfig: filepath.Join(dir, "repositories.yaml"),
+		RepositoryCache:  dir,
+		SkipUpdate:       true,
+	}
+	if err := m.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := loader.LoadDir(m.ChartPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loaded.Lock.Digests["dep-chart-0.1.0"]; !ok {
+		t.Fatalf("expected a digest of dep-chart-0.1.0, got %v", loaded.Lock.Digests)
+	}
+	if err := chartutil.VerifyDependencyDigests(loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	// Replace the dependency with a modified copy.
+	d.Templates = []*chart.File{{Name: "templates/extra.yaml", Data: []byte("kind: ConfigMap")}}
+	if err := os.Remove(filepath.Join(m.ChartPath, "charts", "dep-chart-0.1.0.tgz")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chartutil.Save(d, filepath.Join(m.ChartPath, "charts")); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err = loader.LoadDir(m.ChartPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := chartutil.VerifyDependencyDigests(loaded); err == nil {
+		t.Error("expected the modified dependency to fail the verification")
+	}
+}
+
 // TestUpdateWithNoRepo is for the case of a dependency that has no repo listed.
 // This happens when the dependency is in the charts directory and does not need
 // to be fetched.
@@ -428,6 +497,86 @@ func TestUpdateWithNoRepo(t *testing.T) {
 	}
 }
 
+func TestUpdateWithRepositoryOptions(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string]string{}
+	newServer := func(name string) *repotest.Server {
+		srv, err := repotest.NewTempServerWithCleanup(t, "testdata/*.tgz*")
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv.WithMiddleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			requests[name+r.URL.Path] = r.Header.Get("Authorization") + ", " + r.Header.Get("X-Tenant")
+		}))
+		if err := srv.LinkIndices(); err != nil {
+			t.Fatal(err)
+		}
+		return srv
+	}
+	srv := newServer("private")
+	defer srv.Stop()
+	other := newServer("public")
+	defer other.Stop()
+	dir := func(p ...string) string {
+		return filepath.Join(append([]string{srv.Root()}, p...)...)
+	}
+
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:       "with-dependency",
+			Version:    "0.1.0",
+			APIVersion: "v2",
+			Dependencies: []*chart.Dependency{{
+				Name:       "local-subchart",
+				Version:    "0.1.0",
+				Repository: srv.URL(),
+			}, {
+				Name:       "signtest",
+				Version:    "0.1.0",
+				Repository: other.URL(),
+			}},
+		},
+	}
+	if err := chartutil.SaveDir(c, dir()); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Manager{
+		ChartPath: dir(c.Metadata.Name),
+		Out:       io.Discard,
+		Getters: getter.Providers{getter.Provider{
+			Schemes: []string{"http", "https"},
+			New:     getter.NewHTTPGetter,
+		}},
+		RepositoryConfig: dir("repositories.yaml"),
+		RepositoryCache:  dir(),
+		RepositoryOptions: map[string][]getter.Option{
+			srv.URL() + "/": {
+				getter.WithHeaders(http.Header{"X-Tenant": {"acme"}}),
+				getter.WithBearerToken("secret"),
+			},
+		},
+	}
+	if err := m.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, path := range []string{"/index.yaml", "/local-subchart-0.1.0.tgz"} {
+		if got := requests["private"+path]; got != "Bearer secret, acme" {
+			t.Errorf("Expected the request of %s to have the headers and the token, got %q", path, got)
+		}
+	}
+	for _, path := range []string{"/index.yaml", "/signtest-0.1.0.tgz"} {
+		if got, ok := requests["public"+path]; !ok || got != ", " {
+			t.Errorf("Expected the request of %s of the other repository to have no headers, got %q", path, got)
+		}
+	}
+}
+
 // This function is the skeleton test code of failing tests for #6416 and #6871 and bugs due to #5874.
 //
 // This function is used by below tests that ensures success of build operation
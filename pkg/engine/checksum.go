@@ -0,0 +1,149 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/pkg/chartutil"
+)
+
+// checksumCache memoizes the digests of the chart files for a render, so
+// that a large file referenced by several templates is only hashed once. It
+// is shared by the workers of a parallel render.
+type checksumCache struct {
+	mu      sync.Mutex
+	digests map[checksumKey]string
+}
+
+type checksumKey struct {
+	// files identifies the files of a chart, as every chart of the render
+	// has its own map.
+	files uintptr
+	name  string
+}
+
+func newChecksumCache() *checksumCache {
+	return &checksumCache{digests: map[checksumKey]string{}}
+}
+
+// digest returns the hex encoded SHA-256 digest of a file of the chart.
+func (c *checksumCache) digest(f files, name string) string {
+	key := checksumKey{files: reflect.ValueOf(f).Pointer(), name: name}
+	c.mu.Lock()
+	d, ok := c.digests[key]
+	c.mu.Unlock()
+	if ok {
+		return d
+	}
+	sum := sha256.Sum256(f[name])
+	d = hex.EncodeToString(sum[:])
+	c.mu.Lock()
+	c.digests[key] = d
+	c.mu.Unlock()
+	return d
+}
+
+// fileChecksumFun returns the 'fileChecksum' function, which returns the
+// hex encoded SHA-256 digest of a file of the chart of the context, e.g.
+//
+//	checksum/config: {{ fileChecksum "config/app.conf" . }}
+//
+// The name may be a glob pattern, like for .Files.Glob, to get a digest of
+// the names and contents of all the matching files. The digest only depends
+// on the contents, not on the modification times, so that it is the same for
+// a chart directory and its package. An error is returned when no file
+// matches.
+func fileChecksumFun(cache *checksumCache) func(string, interface{}) (string, error) {
+	return func(name string, context interface{}) (string, error) {
+		f, ok := contextValue(context, "Files").(files)
+		if !ok {
+			return "", errors.Errorf("fileChecksum %q: the context has no .Files, pass the top-level context", name)
+		}
+		if _, ok := f[name]; ok {
+			return cache.digest(f, name), nil
+		}
+		if !strings.ContainsAny(name, "*?[{") {
+			return "", errors.Errorf("fileChecksum: file %q does not exist", name)
+		}
+		matches := f.Glob(name)
+		if len(matches) == 0 {
+			return "", errors.Errorf("fileChecksum: no file matches %q", name)
+		}
+		names := make([]string, 0, len(matches))
+		for n := range matches {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		h := sha256.New()
+		for _, n := range names {
+			h.Write([]byte(n))
+			h.Write([]byte{0})
+			h.Write([]byte(cache.digest(f, n)))
+		}
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+}
+
+// templateChecksumFun returns the 'templateChecksum' function, which renders
+// a template with the context like 'include' and returns the hex encoded
+// SHA-256 digest of the output, e.g.
+//
+//	checksum/config: {{ templateChecksum "configmap.yaml" . }}
+//
+// A name which is not a template of the render is relative to the templates
+// directory of the chart of the context, replacing the boilerplate
+// {{ include (print $.Template.BasePath "/configmap.yaml") . | sha256sum }}.
+func templateChecksumFun(t *template.Template, include func(string, interface{}) (string, error)) func(string, interface{}) (string, error) {
+	return func(name string, context interface{}) (string, error) {
+		if t.Lookup(name) == nil {
+			if basePath, ok := contextValue(contextValue(context, "Template"), "BasePath").(string); ok {
+				name = path.Join(basePath, name)
+			}
+		}
+		if t.Lookup(name) == nil {
+			return "", errors.Errorf("templateChecksum: template %q does not exist", name)
+		}
+		out, err := include(name, context)
+		if err != nil {
+			return "", err
+		}
+		sum := sha256.Sum256([]byte(out))
+		return hex.EncodeToString(sum[:]), nil
+	}
+}
+
+// contextValue returns a key of a template context, which is either the
+// top-level context of a chart or a dictionary, or nil.
+func contextValue(context interface{}, key string) interface{} {
+	switch c := context.(type) {
+	case chartutil.Values:
+		return c[key]
+	case map[string]interface{}:
+		return c[key]
+	}
+	return nil
+}
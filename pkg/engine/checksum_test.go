@@ -0,0 +1,108 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chartutil"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func checksumChart() *chart.Chart {
+	sub := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "sub", Version: "1.0.0"},
+		Templates: []*chart.File{
+			{Name: "templates/configmap.yaml", Data: []byte(`sub: {{ .Values.greeting }}`)},
+			{Name: "templates/checksums", Data: []byte(`{{ fileChecksum "config/app.conf" . }} {{ templateChecksum "configmap.yaml" . }}`)},
+		},
+		Files: []*chart.File{{Name: "config/app.conf", Data: []byte("sub config")}},
+	}
+	parent := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "parent", Version: "1.0.0"},
+		Templates: []*chart.File{
+			{Name: "templates/configmap.yaml", Data: []byte(`parent: {{ .Values.greeting }}`)},
+			{Name: "templates/checksums", Data: []byte(`{{ fileChecksum "config/app.conf" . }} {{ templateChecksum "configmap.yaml" . }} {{ templateChecksum "parent/templates/configmap.yaml" . }}`)},
+			{Name: "templates/glob", Data: []byte(`{{ fileChecksum "config/*.conf" $ }}`)},
+		},
+		Files: []*chart.File{
+			{Name: "config/app.conf", Data: []byte("parent config")},
+			{Name: "config/other.conf", Data: []byte("other config")},
+		},
+	}
+	parent.AddDependency(sub)
+	return parent
+}
+
+func TestChecksumFuncs(t *testing.T) {
+	c := checksumChart()
+	vals, err := chartutil.ToRenderValues(c, map[string]interface{}{"greeting": "hello", "sub": map[string]interface{}{"greeting": "hi"}}, chartutil.ReleaseOptions{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := sha256.New()
+	h.Write([]byte("config/app.conf\x00" + sha256Hex("parent config")))
+	h.Write([]byte("config/other.conf\x00" + sha256Hex("other config")))
+	expect := map[string]string{
+		"parent/templates/checksums":                 sha256Hex("parent config") + " " + sha256Hex("parent: hello") + " " + sha256Hex("parent: hello"),
+		"parent/templates/glob":                      hex.EncodeToString(h.Sum(nil)),
+		"parent/charts/sub/templates/checksums":      sha256Hex("sub config") + " " + sha256Hex("sub: hi"),
+		"parent/templates/configmap.yaml":            "parent: hello",
+		"parent/charts/sub/templates/configmap.yaml": "sub: hi",
+	}
+
+	for _, e := range []Engine{{}, {Parallelism: 2}, {CacheIncludes: true}} {
+		out, err := e.Render(c, vals)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, data := range expect {
+			if out[name] != data {
+				t.Errorf("Expected %q with parallelism %d to be %q, got %q", name, e.Parallelism, data, out[name])
+			}
+		}
+	}
+}
+
+func TestChecksumFuncsErrors(t *testing.T) {
+	for tpl, expect := range map[string]string{
+		`{{ fileChecksum "missing.conf" . }}`:          `fileChecksum: file "missing.conf" does not exist`,
+		`{{ fileChecksum "config/*.yaml" . }}`:         `fileChecksum: no file matches "config/*.yaml"`,
+		`{{ fileChecksum "config/app.conf" .Values }}`: `fileChecksum "config/app.conf": the context has no .Files`,
+		`{{ templateChecksum "missing.yaml" . }}`:      `templateChecksum: template "parent/templates/missing.yaml" does not exist`,
+	} {
+		c := checksumChart()
+		c.Templates = append(c.Templates, &chart.File{Name: "templates/failing", Data: []byte(tpl)})
+		vals, err := chartutil.ToRenderValues(c, map[string]interface{}{}, chartutil.ReleaseOptions{}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Render(c, vals)
+		if err == nil || !strings.Contains(err.Error(), expect) {
+			t.Errorf("Expected %s to fail with %q, got %v", tpl, expect, err)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"bufio"
+	"context"
+	"sort"
+	"strings"
+	"text/template"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+)
+
+// ServerVersionProvider is implemented by client providers that can report
+// the version of the Kubernetes API server.
+type ServerVersionProvider interface {
+	ServerVersion() (*version.Info, error)
+}
+
+func (c clientProviderFromConfig) ServerVersion() (*version.Info, error) {
+	dc, err := discovery.NewDiscoveryClientForConfig(c.config)
+	if err != nil {
+		return nil, err
+	}
+	return dc.ServerVersion()
+}
+
+const defaultIngressClassAnnotation = "ingressclass.kubernetes.io/is-default-class"
+
+// clusterConfigFuncs returns placeholders for the cluster configuration
+// functions. They are used whenever the functions are not enabled or there is
+// no cluster connection, and return empty values so that charts can fall back
+// to a default, e.g. `{{ clusterDomain | default "cluster.local" }}`.
+func clusterConfigFuncs() template.FuncMap {
+	return template.FuncMap{
+		"clusterDomain":       func() (string, error) { return "", nil },
+		"serverVersion":       func() (map[string]interface{}, error) { return map[string]interface{}{}, nil },
+		"ingressClasses":      func() ([]string, error) { return []string{}, nil },
+		"defaultIngressClass": func() (string, error) { return "", nil },
+	}
+}
+
+// newClusterConfigFuncs returns the cluster configuration functions backed by
+// the given client provider.
+func newClusterConfigFuncs(clientProvider ClientProvider) template.FuncMap {
+	return template.FuncMap{
+		"clusterDomain": func() (string, error) {
+			return clusterDomain(clientProvider)
+		},
+		"serverVersion": func() (map[string]interface{}, error) {
+			return serverVersion(clientProvider)
+		},
+		"ingressClasses": func() ([]string, error) {
+			names, _, err := ingressClasses(clientProvider)
+			return names, err
+		},
+		"defaultIngressClass": func() (string, error) {
+			_, def, err := ingressClasses(clientProvider)
+			return def, err
+		},
+	}
+}
+
+// clusterDomain reads the DNS domain of the cluster from the CoreDNS
+// configuration in kube-system. An empty string is returned when it cannot be
+// determined.
+func clusterDomain(clientProvider ClientProvider) (string, error) {
+	c, _, err := clientProvider.GetClientFor("v1", "ConfigMap")
+	if err != nil {
+		return "", err
+	}
+	obj, err := c.Namespace("kube-system").Get(context.Background(), "coredns", metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	data, _ := obj.Object["data"].(map[string]interface{})
+	corefile, _ := data["Corefile"].(string)
+	return parseCorefileDomain(corefile), nil
+}
+
+// parseCorefileDomain returns the first zone of the kubernetes plugin in a
+// Corefile, e.g. "cluster.local" for "kubernetes cluster.local in-addr.arpa".
+func parseCorefileDomain(corefile string) string {
+	scanner := bufio.NewScanner(strings.NewReader(corefile))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 1 && fields[0] == "kubernetes" {
+			return strings.TrimSuffix(fields[1], ".")
+		}
+	}
+	return ""
+}
+
+// serverVersion returns the version details of the API server. An empty map
+// is returned when the client provider cannot report them.
+func serverVersion(clientProvider ClientProvider) (map[string]interface{}, error) {
+	vp, ok := clientProvider.(ServerVersionProvider)
+	if !ok {
+		return map[string]interface{}{}, nil
+	}
+	info, err := vp.ServerVersion()
+	if err != nil {
+		return map[string]interface{}{}, err
+	}
+	return map[string]interface{}{
+		"major":      info.Major,
+		"minor":      info.Minor,
+		"gitVersion": info.GitVersion,
+		"gitCommit":  info.GitCommit,
+		"buildDate":  info.BuildDate,
+		"goVersion":  info.GoVersion,
+		"compiler":   info.Compiler,
+		"platform":   info.Platform,
+	}, nil
+}
+
+// ingressClasses returns the sorted names of the IngressClasses in the
+// cluster and the name of the default one, if any.
+func ingressClasses(clientProvider ClientProvider) ([]string, string, error) {
+	c, _, err := clientProvider.GetClientFor("networking.k8s.io/v1", "IngressClass")
+	if err != nil {
+		return []string{}, "", err
+	}
+	list, err := c.List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return []string{}, "", nil
+		}
+		return []string{}, "", err
+	}
+	names := []string{}
+	def := ""
+	for _, item := range list.Items {
+		names = append(names, item.GetName())
+		if item.GetAnnotations()[defaultIngressClassAnnotation] == "true" {
+			def = item.GetName()
+		}
+	}
+	sort.Strings(names)
+	return names, def, nil
+}
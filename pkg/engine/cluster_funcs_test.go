@@ -0,0 +1,135 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"path"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chartutil"
+)
+
+type versionedClientProvider struct {
+	*testClientProvider
+	info *version.Info
+}
+
+func (p versionedClientProvider) ServerVersion() (*version.Info, error) {
+	return p.info, nil
+}
+
+func TestParseCorefileDomain(t *testing.T) {
+	corefile := `.:53 {
+    errors
+    health
+    kubernetes example.internal. in-addr.arpa ip6.arpa {
+       pods insecure
+    }
+    forward . /etc/resolv.conf
+}`
+	if got := parseCorefileDomain(corefile); got != "example.internal" {
+		t.Errorf("expected example.internal, got %q", got)
+	}
+	if got := parseCorefileDomain(".:53 {\n    errors\n}"); got != "" {
+		t.Errorf("expected no domain, got %q", got)
+	}
+}
+
+func TestRenderClusterConfigFuncs(t *testing.T) {
+	coredns := makeUnstructured("v1", "ConfigMap", "coredns", "kube-system")
+	coredns.Object["data"] = map[string]interface{}{
+		"Corefile": ".:53 {\n    kubernetes cluster.example in-addr.arpa ip6.arpa\n}",
+	}
+	nginx := makeUnstructured("networking.k8s.io/v1", "IngressClass", "nginx", "")
+	nginx.SetAnnotations(map[string]string{defaultIngressClassAnnotation: "true"})
+
+	var provider ClientProvider = versionedClientProvider{
+		testClientProvider: &testClientProvider{
+			t: t,
+			scheme: map[string]kindProps{
+				"v1/ConfigMap": {
+					gvr:        schema.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+					namespaced: true,
+				},
+				"networking.k8s.io/v1/IngressClass": {
+					gvr: schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingressclasses"},
+				},
+			},
+			objects: []runtime.Object{
+				coredns,
+				nginx,
+				makeUnstructured("networking.k8s.io/v1", "IngressClass", "alb", ""),
+			},
+		},
+		info: &version.Info{Major: "1", Minor: "31", GitVersion: "v1.31.2", Platform: "linux/amd64"},
+	}
+
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*chart.File{
+			{Name: "templates/domain", Data: []byte(`{{ clusterDomain | default "cluster.local" }}`)},
+			{Name: "templates/version", Data: []byte(`{{ (serverVersion).gitVersion }}`)},
+			{Name: "templates/classes", Data: []byte(`{{ ingressClasses | join "," }}`)},
+			{Name: "templates/default", Data: []byte(`{{ defaultIngressClass }}`)},
+		},
+		Values: map[string]interface{}{},
+	}
+	v, err := chartutil.CoalesceValues(c, map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Failed to coalesce values: %s", err)
+	}
+
+	expect := func(t *testing.T, out map[string]string, want map[string]string) {
+		t.Helper()
+		for name, w := range want {
+			if got := out[path.Join("moby/templates", name)]; got != w {
+				t.Errorf("%s: expected %q, got %q", name, w, got)
+			}
+		}
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		out, err := Engine{clientProvider: &provider, EnableClusterConfig: true}.Render(c, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect(t, out, map[string]string{
+			"domain":  "cluster.example",
+			"version": "v1.31.2",
+			"classes": "alb,nginx",
+			"default": "nginx",
+		})
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		out, err := Engine{clientProvider: &provider}.Render(c, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect(t, out, map[string]string{
+			"domain":  "cluster.local",
+			"version": "",
+			"classes": "",
+			"default": "",
+		})
+	})
+}
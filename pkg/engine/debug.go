@@ -0,0 +1,208 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"helm.sh/helm/v4/pkg/chartutil"
+)
+
+// RenderDebug collects the debugging information of the templates rendered by
+// an Engine with Debug set, to diagnose how values flow through a chart and
+// its dependencies and where rendering spends its time.
+//
+// A RenderDebug can be reused by several renders, each render replacing the
+// information of the previous one.
+type RenderDebug struct {
+	// SensitiveKeys matches the keys of the values which are redacted from
+	// the recorded values. When nil, chartutil.DefaultSensitiveKeyPattern is
+	// used.
+	SensitiveKeys *regexp.Regexp
+	// Templates is the debugging information of the rendered template files,
+	// sorted by name. Partials are only recorded as includes.
+	Templates []*TemplateDebug
+
+	mu sync.Mutex
+}
+
+// TemplateDebug is the debugging information of a rendered template file.
+type TemplateDebug struct {
+	// Name is the name of the template, e.g. "mychart/templates/service.yaml".
+	Name string `json:"name"`
+	// Values are the values in the scope of the template, i.e. .Values, with
+	// the sensitive values redacted.
+	Values chartutil.Values `json:"values"`
+	// Duration is the time spent rendering the template.
+	Duration metav1.Duration `json:"duration"`
+	// Includes are the templates included by the template, in order.
+	Includes []*IncludeDebug `json:"includes,omitempty"`
+	// Error is the error which made rendering the template fail.
+	Error string `json:"error,omitempty"`
+}
+
+// IncludeDebug is a call of 'include' made while rendering a template.
+type IncludeDebug struct {
+	// Name is the name of the included template.
+	Name string `json:"name"`
+	// Duration is the time spent rendering the included template, including
+	// the templates it includes.
+	Duration metav1.Duration `json:"duration"`
+	// Cached is set when the output was served from the include cache.
+	Cached bool `json:"cached,omitempty"`
+	// Includes are the templates included by the included template.
+	Includes []*IncludeDebug `json:"includes,omitempty"`
+}
+
+// reset clears the information of a previous render.
+func (d *RenderDebug) reset() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.Templates = nil
+}
+
+// start records the values of a template file before it is rendered, as the
+// template may modify them.
+func (d *RenderDebug) start(filename string, r renderable) *TemplateDebug {
+	if d == nil {
+		return nil
+	}
+	sensitive := d.SensitiveKeys
+	if sensitive == nil {
+		sensitive = chartutil.DefaultSensitiveKeyPattern
+	}
+	var values map[string]interface{}
+	switch v := r.vals["Values"].(type) {
+	case chartutil.Values:
+		values = v
+	case map[string]interface{}:
+		values = v
+	}
+	return &TemplateDebug{
+		Name:   filename,
+		Values: chartutil.RedactValues(values, sensitive),
+	}
+}
+
+// finish records a rendered template file.
+func (d *RenderDebug) finish(td *TemplateDebug, tracer *includeTracer, elapsed time.Duration, err error) {
+	if d == nil {
+		return
+	}
+	td.Duration = metav1.Duration{Duration: elapsed}
+	td.Includes = tracer.finish()
+	if err != nil {
+		td.Error = err.Error()
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.Templates = append(d.Templates, td)
+}
+
+// sort sorts the recorded templates by name once the render is done.
+func (d *RenderDebug) sort() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	sort.Slice(d.Templates, func(i, j int) bool { return d.Templates[i].Name < d.Templates[j].Name })
+}
+
+// includeTracer builds the call tree of the includes of a template file and
+// counts the includes and lookups it makes. It is used by a single goroutine
+// at a time, like the functions it traces.
+type includeTracer struct {
+	// tree is set when the call tree is built, for debugging.
+	tree bool
+	// stack holds the calls in progress; the first entry collects the
+	// includes made directly by the template file.
+	stack []*IncludeDebug
+
+	includes int
+	lookups  int
+}
+
+// newTracer returns a tracer of the template files, or nil when neither
+// debugging nor stats are enabled.
+func (e Engine) newTracer() *includeTracer {
+	if e.Debug == nil && e.Stats == nil {
+		return nil
+	}
+	return &includeTracer{tree: e.Debug != nil}
+}
+
+func (tr *includeTracer) start() {
+	if tr == nil {
+		return
+	}
+	tr.includes, tr.lookups = 0, 0
+	if tr.tree {
+		tr.stack = []*IncludeDebug{{}}
+	}
+}
+
+// enter records the start of an include, returning the time it started.
+func (tr *includeTracer) enter(name string) time.Time {
+	if tr == nil {
+		return time.Time{}
+	}
+	tr.includes++
+	if len(tr.stack) == 0 {
+		return time.Time{}
+	}
+	call := &IncludeDebug{Name: name}
+	parent := tr.stack[len(tr.stack)-1]
+	parent.Includes = append(parent.Includes, call)
+	tr.stack = append(tr.stack, call)
+	return time.Now()
+}
+
+// leave records the end of the include entered last.
+func (tr *includeTracer) leave(begin time.Time, cached bool) {
+	if tr == nil || len(tr.stack) < 2 {
+		return
+	}
+	call := tr.stack[len(tr.stack)-1]
+	call.Duration = metav1.Duration{Duration: time.Since(begin)}
+	call.Cached = cached
+	tr.stack = tr.stack[:len(tr.stack)-1]
+}
+
+// lookup counts a call of 'lookup'.
+func (tr *includeTracer) lookup() {
+	if tr != nil {
+		tr.lookups++
+	}
+}
+
+func (tr *includeTracer) finish() []*IncludeDebug {
+	if tr == nil || len(tr.stack) == 0 {
+		return nil
+	}
+	includes := tr.stack[0].Includes
+	tr.stack = nil
+	return includes
+}
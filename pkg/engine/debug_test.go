@@ -0,0 +1,99 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chartutil"
+)
+
+func debugChart(t *testing.T) (*chart.Chart, chartutil.Values) {
+	t.Helper()
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "parent", Version: "1.0.0"},
+		Templates: []*chart.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{ define "outer" }}{{ include "inner" . }}{{ end }}{{ define "inner" }}{{ .Values.name }}{{ end }}`)},
+			{Name: "templates/cm", Data: []byte(`{{ include "outer" . }}{{ include "inner" . }}`)},
+			{Name: "templates/broken", Data: []byte(`{{ fail "broken" }}`)},
+		},
+		Values: map[string]interface{}{"name": "parent", "password": "hunter2"},
+	}
+	sub := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "child", Version: "1.0.0"},
+		Templates: []*chart.File{
+			{Name: "templates/cm", Data: []byte(`{{ .Values.name }}`)},
+		},
+		Values: map[string]interface{}{"name": "child", "db": map[string]interface{}{"apiKey": "abc", "host": "db"}},
+	}
+	c.AddDependency(sub)
+	vals, err := chartutil.ToRenderValues(c, map[string]interface{}{}, chartutil.ReleaseOptions{Name: "rel"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, vals
+}
+
+func TestRenderDebug(t *testing.T) {
+	for _, e := range []Engine{{ReportAllErrors: true}, {ReportAllErrors: true, Parallelism: 2}, {ReportAllErrors: true, CacheIncludes: true}} {
+		c, vals := debugChart(t)
+		debug := &RenderDebug{}
+		e.Debug = debug
+		if _, err := e.Render(c, vals); err == nil {
+			t.Fatal("expected the broken template to fail")
+		}
+
+		if len(debug.Templates) != 3 {
+			t.Fatalf("expected 3 templates, got %d", len(debug.Templates))
+		}
+		names := []string{"parent/charts/child/templates/cm", "parent/templates/broken", "parent/templates/cm"}
+		for i, td := range debug.Templates {
+			if td.Name != names[i] {
+				t.Errorf("expected template %d to be %s, got %s", i, names[i], td.Name)
+			}
+		}
+
+		child, broken, parent := debug.Templates[0], debug.Templates[1], debug.Templates[2]
+		if child.Values["name"] != "child" {
+			t.Errorf("expected the values of the child, got %v", child.Values)
+		}
+		if db := child.Values["db"].(map[string]interface{}); db["apiKey"] != chartutil.RedactedValue || db["host"] != "db" {
+			t.Errorf("expected the API key to be redacted, got %v", db)
+		}
+		if parent.Values["password"] != chartutil.RedactedValue {
+			t.Errorf("expected the password to be redacted, got %v", parent.Values["password"])
+		}
+		if vals["Values"].(chartutil.Values)["password"] != "hunter2" {
+			t.Error("expected the values to be left unchanged")
+		}
+
+		if broken.Error == "" || parent.Error != "" {
+			t.Errorf("expected only the broken template to have an error, got %q and %q", broken.Error, parent.Error)
+		}
+
+		if len(parent.Includes) != 2 || parent.Includes[0].Name != "outer" || parent.Includes[1].Name != "inner" {
+			t.Fatalf("expected outer and inner to be included, got %v", parent.Includes)
+		}
+		if nested := parent.Includes[0].Includes; len(nested) != 1 || nested[0].Name != "inner" {
+			t.Errorf("expected outer to include inner, got %v", nested)
+		}
+		if e.CacheIncludes && !parent.Includes[1].Cached {
+			t.Error("expected the second include of inner to be cached")
+		}
+	}
+}
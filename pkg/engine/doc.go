@@ -20,5 +20,21 @@ Package engine implements the Go text template engine as needed for Helm.
 When Helm renders templates it does so with additional functions and different
 modes (e.g., strict, lint mode). This package handles the helm specific
 implementation.
+
+In addition to the Go template functions and the sprig function library,
+templates can use the following functions:
+
+  - toYaml, toYamlPretty, toYamlIndent, toJson and toToml encode a value.
+  - fromYaml, fromYamlArray, fromJson, fromJsonArray and fromToml decode a
+    dictionary or a list. They report parse errors in the result.
+  - mustToYaml, mustToJson, mustFromYaml, mustFromYamlArray, mustFromJson and
+    mustFromJsonArray fail rendering on errors instead.
+  - deepMerge merges dictionaries with the semantics of values coalescing:
+    later dictionaries win and null values delete keys.
+  - include, tpl, required and lookup, and the cluster configuration
+    functions, which are bound when rendering.
+  - fileChecksum and templateChecksum return the SHA-256 digest of a chart
+    file or of a rendered template, e.g. to annotate a Deployment with the
+    checksum of its configuration. They are bound when rendering.
 */
 package engine // import "helm.sh/helm/v4/pkg/engine"
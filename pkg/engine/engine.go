@@ -25,6 +25,7 @@ import (
 	"sort"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/pkg/errors"
 	"k8s.io/client-go/rest"
@@ -36,14 +37,41 @@ import (
 // Engine is an implementation of the Helm rendering implementation for templates.
 type Engine struct {
 	// If strict is enabled, template rendering will fail if a template references
-	// a value that was not passed in.
+	// a value that was not passed in. It is equivalent to FailOnMissingValues.
 	Strict bool
+	// Strictness selects the problems that make rendering fail.
+	Strictness
+	// ReportAllErrors continues rendering the remaining templates after a
+	// template fails, and returns the errors of all the templates as
+	// RenderErrors.
+	ReportAllErrors bool
 	// In LintMode, some 'required' template values may be missing, so don't fail
 	LintMode bool
 	// optional provider of clients to talk to the Kubernetes API
 	clientProvider *ClientProvider
 	// EnableDNS tells the engine to allow DNS lookups when rendering templates
 	EnableDNS bool
+	// EnableClusterConfig tells the engine to allow the cluster configuration
+	// functions (clusterDomain, serverVersion, ingressClasses and
+	// defaultIngressClass) to query the cluster when rendering templates
+	EnableClusterConfig bool
+	// Parallelism is the number of workers rendering the charts of a chart
+	// tree in parallel. Values below 2 render sequentially. The templates of
+	// a chart are always executed in order. Charts whose templates modify
+	// dictionaries, e.g. with 'set', are rendered sequentially, as the values
+	// are shared between the charts.
+	Parallelism int
+	// CacheIncludes memoizes the output of 'include' for identical data
+	// while rendering a template file. Templates whose output may differ
+	// between calls, e.g. because they use random or time functions, modify
+	// dictionaries or use custom template functions, are never cached.
+	CacheIncludes bool
+	// Debug, when set, collects the values, the render duration and the
+	// include call tree of every rendered template file.
+	Debug *RenderDebug
+	// Stats, when set, collects the render duration, the output size and the
+	// number of includes and lookups of every rendered template file.
+	Stats *RenderStats
 }
 
 // New creates a new instance of Engine using the passed in rest config.
@@ -126,11 +154,22 @@ func warnWrap(warn string) string {
 
 // 'include' needs to be defined in the scope of a 'tpl' template as
 // well as regular file-loaded templates.
-func includeFun(t *template.Template, includedNames map[string]int) func(string, interface{}) (string, error) {
+//
+// The cache is optional and only set for the file-loaded templates, as 'tpl'
+// may define templates that shadow the cached ones. The tracer is set when
+// debugging.
+func includeFun(t *template.Template, includedNames map[string]int, cache *includeCache, tracer *includeTracer) func(string, interface{}) (string, error) {
 	return func(name string, data interface{}) (string, error) {
+		begin := tracer.enter(name)
+		out, key, ok := cache.get(name, data)
+		if ok {
+			tracer.leave(begin, true)
+			return out, nil
+		}
 		var buf strings.Builder
 		if v, ok := includedNames[name]; ok {
 			if v > recursionMaxNums {
+				tracer.leave(begin, false)
 				return "", errors.Wrapf(fmt.Errorf("unable to execute template"), "rendering template has a nested reference name: %s", name)
 			}
 			includedNames[name]++
@@ -139,13 +178,17 @@ func includeFun(t *template.Template, includedNames map[string]int) func(string,
 		}
 		err := t.ExecuteTemplate(&buf, name, data)
 		includedNames[name]--
+		tracer.leave(begin, false)
+		if err == nil {
+			cache.put(key, buf.String(), data)
+		}
 		return buf.String(), err
 	}
 }
 
 // As does 'tpl', so that nested calls to 'tpl' see the templates
 // defined by their enclosing contexts.
-func tplFun(parent *template.Template, includedNames map[string]int, strict bool) func(string, interface{}) (string, error) {
+func tplFun(parent *template.Template, includedNames map[string]int, strict bool, tracer *includeTracer) func(string, interface{}) (string, error) {
 	return func(tpl string, vals interface{}) (string, error) {
 		t, err := parent.Clone()
 		if err != nil {
@@ -164,8 +207,8 @@ func tplFun(parent *template.Template, includedNames map[string]int, strict bool
 		// Re-inject 'include' so that it can close over our clone of t;
 		// this lets any 'define's inside tpl be 'include'd.
 		t.Funcs(template.FuncMap{
-			"include": includeFun(t, includedNames),
-			"tpl":     tplFun(t, includedNames, strict),
+			"include": includeFun(t, includedNames, nil, tracer),
+			"tpl":     tplFun(t, includedNames, strict, tracer),
 		})
 
 		// We need a .New template, as template text which is just blanks
@@ -190,13 +233,21 @@ func tplFun(parent *template.Template, includedNames map[string]int, strict bool
 }
 
 // initFunMap creates the Engine's FuncMap and adds context-specific functions.
-func (e Engine) initFunMap(t *template.Template) {
+func (e Engine) initFunMap(t *template.Template, cache *includeCache, tracer *includeTracer, checksums *checksumCache) {
+	t.Funcs(e.templateFuncs(t, cache, tracer, checksums))
+}
+
+// templateFuncs returns the Engine's FuncMap with the context-specific
+// functions bound to t.
+func (e Engine) templateFuncs(t *template.Template, cache *includeCache, tracer *includeTracer, checksums *checksumCache) template.FuncMap {
 	funcMap := funcMap()
 	includedNames := make(map[string]int)
 
 	// Add the template-rendering functions here so we can close over t.
-	funcMap["include"] = includeFun(t, includedNames)
-	funcMap["tpl"] = tplFun(t, includedNames, e.Strict)
+	funcMap["include"] = includeFun(t, includedNames, cache, tracer)
+	funcMap["tpl"] = tplFun(t, includedNames, e.failOnMissingValues(), tracer)
+	funcMap["fileChecksum"] = fileChecksumFun(checksums)
+	funcMap["templateChecksum"] = templateChecksumFun(t, includeFun(t, includedNames, cache, tracer))
 
 	// Add the `required` function here so we can use lintMode
 	funcMap["required"] = func(warn string, val interface{}) (interface{}, error) {
@@ -234,6 +285,16 @@ func (e Engine) initFunMap(t *template.Template) {
 	// implementation.
 	if !e.LintMode && e.clientProvider != nil {
 		funcMap["lookup"] = newLookupFunction(*e.clientProvider)
+
+		if e.EnableClusterConfig {
+			for k, v := range newClusterConfigFuncs(*e.clientProvider) {
+				funcMap[k] = v
+			}
+		}
+	} else if e.FailOnLookupWithoutCluster {
+		funcMap["lookup"] = func(apiversion, kind, _, _ string) (map[string]interface{}, error) {
+			return map[string]interface{}{}, errors.Errorf("lookup of %s %s requires a connection to a Kubernetes cluster", apiversion, kind)
+		}
 	}
 
 	// When DNS lookups are not enabled override the sprig function and return
@@ -244,7 +305,18 @@ func (e Engine) initFunMap(t *template.Template) {
 		}
 	}
 
-	t.Funcs(funcMap)
+	if lookup, ok := funcMap["lookup"].(lookupFunc); ok && tracer != nil {
+		funcMap["lookup"] = func(apiversion, resource, namespace, name string) (map[string]interface{}, error) {
+			tracer.lookup()
+			return lookup(apiversion, resource, namespace, name)
+		}
+	}
+
+	if cache != nil {
+		cache.wrapMutatingFuncs(funcMap)
+	}
+
+	return funcMap
 }
 
 // render takes a map of templates/values and renders them.
@@ -262,7 +334,7 @@ func (e Engine) render(tpls map[string]renderable) (rendered map[string]string,
 		}
 	}()
 	t := template.New("gotpl")
-	if e.Strict {
+	if e.failOnMissingValues() {
 		t.Option("missingkey=error")
 	} else {
 		// Not that zero will attempt to add default values for types it knows,
@@ -270,32 +342,83 @@ func (e Engine) render(tpls map[string]renderable) (rendered map[string]string,
 		t.Option("missingkey=zero")
 	}
 
-	e.initFunMap(t)
+	var cache *includeCache
+	if e.CacheIncludes {
+		cache = newIncludeCache(t)
+	}
+	tracer := e.newTracer()
+	checksums := newChecksumCache()
+	e.initFunMap(t, cache, tracer, checksums)
+	e.Debug.reset()
+	defer e.Debug.sort()
+	e.Stats.reset()
+	defer e.Stats.sort()
 
 	// We want to parse the templates in a predictable order. The order favors
 	// higher-level (in file system) templates over deeply nested templates.
 	keys := sortTemplates(tpls)
 
+	var errs RenderErrors
+	failed := map[string]bool{}
 	for _, filename := range keys {
 		r := tpls[filename]
 		if _, err := t.New(filename).Parse(r.tpl); err != nil {
-			return map[string]string{}, cleanupParseError(filename, err)
+			if !e.ReportAllErrors {
+				return map[string]string{}, cleanupParseError(filename, err)
+			}
+			errs = append(errs, newRenderError(filename, err, true))
+			failed[filename] = true
 		}
 	}
 
-	rendered = make(map[string]string, len(keys))
+	if e.FailOnUndefinedTemplates {
+		if err := checkTemplateReferences(t); err != nil {
+			return map[string]string{}, err
+		}
+	}
+
+	// Don't render partials. We don't care out the direct output of partials.
+	// They are only included from other templates.
+	var files []string
 	for _, filename := range keys {
-		// Don't render partials. We don't care out the direct output of partials.
-		// They are only included from other templates.
-		if strings.HasPrefix(path.Base(filename), "_") {
-			continue
+		if !strings.HasPrefix(path.Base(filename), "_") && !failed[filename] {
+			files = append(files, filename)
 		}
-		// At render time, add information about the template that is being rendered.
-		vals := tpls[filename].vals
-		vals["Template"] = chartutil.Values{"Name": filename, "BasePath": tpls[filename].basePath}
+	}
+
+	var results map[string]renderResult
+	if e.Parallelism > 1 && !callsMutatingFuncs(t) {
+		if results, err = e.executeParallel(t, tpls, files, checksums); err != nil {
+			return map[string]string{}, err
+		}
+	}
+
+	rendered = make(map[string]string, len(files))
+	for _, filename := range files {
 		var buf strings.Builder
-		if err := t.ExecuteTemplate(&buf, filename, vals); err != nil {
-			return map[string]string{}, cleanupExecError(filename, err)
+		var err error
+		if results != nil {
+			buf.WriteString(results[filename].out)
+			err = results[filename].err
+		} else {
+			// At render time, add information about the template that is being rendered.
+			vals := tpls[filename].vals
+			vals["Template"] = chartutil.Values{"Name": filename, "BasePath": tpls[filename].basePath}
+			cache.reset()
+			tracer.start()
+			debug := e.Debug.start(filename, tpls[filename])
+			begin := time.Now()
+			err = t.ExecuteTemplate(&buf, filename, vals)
+			elapsed := time.Since(begin)
+			e.Debug.finish(debug, tracer, elapsed, err)
+			e.Stats.record(filename, tpls[filename], tracer, elapsed, buf.Len())
+		}
+		if err != nil {
+			if !e.ReportAllErrors {
+				return map[string]string{}, cleanupExecError(filename, err)
+			}
+			errs = append(errs, newRenderError(filename, err, false))
+			continue
 		}
 
 		// Work around the issue where Go will emit "<no value>" even if Options(missing=zero)
@@ -304,6 +427,11 @@ func (e Engine) render(tpls map[string]renderable) (rendered map[string]string,
 		rendered[filename] = strings.ReplaceAll(buf.String(), "<no value>", "")
 	}
 
+	if len(errs) > 0 {
+		sort.SliceStable(errs, func(i, j int) bool { return errs[i].Template < errs[j].Template })
+		return map[string]string{}, errs
+	}
+
 	return rendered, nil
 }
 
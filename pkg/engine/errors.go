@@ -0,0 +1,124 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// RenderError is an error raised while parsing or executing a single
+// template.
+type RenderError struct {
+	// Template is the name of the template that failed to render.
+	Template string
+	// Location is where the error occurred, e.g. "mychart/templates/a.yaml:3:5".
+	// When the error was raised by an included template, this is the location
+	// in the included template.
+	Location string
+	// Message describes the error.
+	Message string
+	// Trace lists the template calls leading to the error, outermost first.
+	// It is empty for parse errors.
+	Trace []string
+	// Err is the original error.
+	Err error
+}
+
+func (e *RenderError) Error() string {
+	var b strings.Builder
+	if len(e.Trace) == 0 {
+		fmt.Fprintf(&b, "parse error at (%s): %s", e.Location, e.Message)
+		return b.String()
+	}
+	fmt.Fprintf(&b, "execution error at (%s): %s", e.Location, e.Message)
+	if len(e.Trace) > 1 {
+		for _, frame := range e.Trace {
+			b.WriteString("\n\t")
+			b.WriteString(frame)
+		}
+	}
+	return b.String()
+}
+
+func (e *RenderError) Unwrap() error {
+	return e.Err
+}
+
+// RenderErrors aggregates the errors of all the templates that failed to
+// render, sorted by template name.
+type RenderErrors []*RenderError
+
+func (e RenderErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the individual errors, so that they can be inspected with
+// errors.Is and errors.As.
+func (e RenderErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// execFrame matches a single call in the error chain produced by
+// text/template, e.g. `template: a.yaml:3:5: executing "a.yaml" at <include "b" .>: `.
+var execFrame = regexp.MustCompile(`template: (\S+?): executing "([^"]*)" at <(.*?)>: `)
+
+// newRenderError converts an error returned by text/template into a
+// RenderError.
+func newRenderError(filename string, err error, parse bool) *RenderError {
+	re := &RenderError{Template: filename, Location: filename, Message: err.Error(), Err: err}
+
+	if parse {
+		tokens := strings.Split(err.Error(), ": ")
+		if len(tokens) > 2 {
+			re.Location = tokens[1]
+			re.Message = tokens[len(tokens)-1]
+		}
+		return re
+	}
+
+	if _, ok := err.(template.ExecError); !ok {
+		re.Trace = []string{filename}
+		return re
+	}
+
+	msg := err.Error()
+	matches := execFrame.FindAllStringSubmatchIndex(msg, -1)
+	if len(matches) == 0 {
+		re.Trace = []string{filename}
+		return re
+	}
+	for _, m := range matches {
+		re.Trace = append(re.Trace, fmt.Sprintf("%s: executing %q at <%s>", msg[m[2]:m[3]], msg[m[4]:m[5]], msg[m[6]:m[7]]))
+		re.Location = msg[m[2]:m[3]]
+	}
+	re.Message = msg[matches[len(matches)-1][1]:]
+	if parts := warnRegex.FindStringSubmatch(re.Message); len(parts) >= 2 {
+		re.Message = parts[1]
+	}
+	return re
+}
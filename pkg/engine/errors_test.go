@@ -0,0 +1,79 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chartutil"
+)
+
+func TestRenderReportAllErrors(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*chart.File{
+			{Name: "templates/_helpers.tpl", Data: []byte("{{ define \"moby.check\" }}\n{{ fail \"port is required\" }}\n{{ end }}")},
+			{Name: "templates/a", Data: []byte(`{{ include "moby.check" . }}`)},
+			{Name: "templates/b", Data: []byte(`{{ .Values.missing.key }}`)},
+			{Name: "templates/c", Data: []byte(`{{ if }}`)},
+			{Name: "templates/d", Data: []byte(`fine`)},
+		},
+	}
+	v, err := chartutil.CoalesceValues(c, map[string]interface{}{})
+	require.NoError(t, err)
+	vals := chartutil.Values{"Values": v, "Chart": c.Metadata}
+
+	// By default rendering stops at the first error.
+	_, err = Engine{}.Render(c, vals)
+	require.Error(t, err)
+	var all RenderErrors
+	assert.False(t, errors.As(err, &all))
+
+	_, err = Engine{ReportAllErrors: true}.Render(c, vals)
+	require.Error(t, err)
+	require.True(t, errors.As(err, &all))
+	require.Len(t, all, 3)
+
+	parseErr := all[2]
+	assert.Equal(t, "moby/templates/c", parseErr.Template)
+	assert.Empty(t, parseErr.Trace)
+	assert.Contains(t, parseErr.Error(), "parse error at (moby/templates/c:1)")
+
+	included := all[0]
+	assert.Equal(t, "moby/templates/a", included.Template)
+	assert.Equal(t, "moby/templates/_helpers.tpl:2:3", included.Location)
+	assert.Equal(t, "port is required", included.Message)
+	assert.Equal(t, []string{
+		`moby/templates/a:1:3: executing "moby/templates/a" at <include "moby.check" .>`,
+		`moby/templates/_helpers.tpl:2:3: executing "moby.check" at <fail "port is required">`,
+	}, included.Trace)
+	assert.Equal(t, "execution error at (moby/templates/_helpers.tpl:2:3): port is required\n"+
+		"\tmoby/templates/a:1:3: executing \"moby/templates/a\" at <include \"moby.check\" .>\n"+
+		"\tmoby/templates/_helpers.tpl:2:3: executing \"moby.check\" at <fail \"port is required\">", included.Error())
+
+	missing := all[1]
+	assert.Equal(t, "moby/templates/b", missing.Template)
+	assert.Contains(t, missing.Message, "nil pointer evaluating interface {}.key")
+
+	var single *RenderError
+	assert.True(t, errors.As(err, &single))
+}
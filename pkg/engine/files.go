@@ -19,6 +19,7 @@ package engine
 import (
 	"encoding/base64"
 	"path"
+	"sort"
 	"strings"
 
 	"github.com/gobwas/glob"
@@ -63,6 +64,113 @@ func (f files) Get(name string) string {
 	return string(f.GetBytes(name))
 }
 
+// GetBytesRange returns at most length bytes of a file, starting at offset.
+// A negative length reads up to the end of the file.
+//
+// This is intended to be accessed from within a template, so a missed key or
+// an offset past the end of the file returns an empty []byte.
+//
+//	{{ .Files.GetBytesRange "data/large.bin" 0 1024 | b64enc }}
+func (f files) GetBytesRange(name string, offset, length int) []byte {
+	data := f.GetBytes(name)
+	if offset < 0 || offset >= len(data) {
+		return []byte{}
+	}
+	end := len(data)
+	if length >= 0 && offset+length < end {
+		end = offset + length
+	}
+	return data[offset:end]
+}
+
+// Exists reports whether a file exists.
+//
+//	{{ if .Files.Exists "config/extra.conf" }}...{{ end }}
+func (f files) Exists(name string) bool {
+	_, ok := f[name]
+	return ok
+}
+
+// fileInfo describes a file or directory of a chart.
+type fileInfo struct {
+	// Name is the base name of the file.
+	Name string
+	// Path is the path of the file relative to the chart root.
+	Path string
+	// Size is the size of the file in bytes. For a directory, it is the total
+	// size of the files it contains.
+	Size int
+	// IsDir reports whether this is a directory.
+	IsDir bool
+	// Children are the entries of a directory, sorted by name.
+	Children []*fileInfo
+}
+
+// Stat returns information about a file, or nil if it does not exist.
+//
+//	{{ with .Files.Stat "data/large.bin" }}size: {{ .Size }}{{ end }}
+func (f files) Stat(name string) *fileInfo {
+	data, ok := f[name]
+	if !ok {
+		return nil
+	}
+	return &fileInfo{Name: path.Base(name), Path: name, Size: len(data)}
+}
+
+// Tree returns the files as a directory tree. The root has an empty name and
+// path.
+//
+// This is designed to be called from a template, together with Glob to
+// restrict the files it contains.
+//
+//	{{ range (.Files.Glob "conf.d/**").Tree.Children }}
+//	{{ if .IsDir }}{{ .Name }}/{{ else }}{{ .Name }}: {{ .Size }}{{ end }}
+//	{{ end }}
+func (f files) Tree() *fileInfo {
+	root := &fileInfo{IsDir: true}
+	dirs := map[string]*fileInfo{"": root}
+
+	var dirFor func(p string) *fileInfo
+	dirFor = func(p string) *fileInfo {
+		if d, ok := dirs[p]; ok {
+			return d
+		}
+		parent := dirFor(parentDir(p))
+		d := &fileInfo{Name: path.Base(p), Path: p, IsDir: true}
+		parent.Children = append(parent.Children, d)
+		dirs[p] = d
+		return d
+	}
+
+	for name, data := range f {
+		dir := dirFor(parentDir(name))
+		dir.Children = append(dir.Children, &fileInfo{Name: path.Base(name), Path: name, Size: len(data)})
+	}
+	sortTree(root)
+	return root
+}
+
+func parentDir(name string) string {
+	if dir := path.Dir(name); dir != "." {
+		return dir
+	}
+	return ""
+}
+
+// sortTree sorts the children of every directory and computes directory sizes.
+func sortTree(d *fileInfo) int {
+	sort.Slice(d.Children, func(i, j int) bool { return d.Children[i].Name < d.Children[j].Name })
+	d.Size = 0
+	for _, c := range d.Children {
+		if c.IsDir {
+			d.Size += sortTree(c)
+		} else {
+			d.Size += c.Size
+		}
+	}
+	return d.Size
+}
+
 // Glob takes a glob pattern and returns another files object only containing
 // matched  files.
 //
@@ -109,3 +109,45 @@ func TestBlankLines(t *testing.T) {
 	as.Equal("bar", out[0])
 	as.Equal("", out[3])
 }
+
+func TestGetBytesRange(t *testing.T) {
+	as := assert.New(t)
+
+	f := getTestFiles()
+	as.Equal("The", string(f.GetBytesRange("ship/captain.txt", 0, 3)))
+	as.Equal("Captain", string(f.GetBytesRange("ship/captain.txt", 4, -1)))
+	as.Equal("Captain", string(f.GetBytesRange("ship/captain.txt", 4, 100)))
+	as.Equal("", string(f.GetBytesRange("ship/captain.txt", 100, 3)))
+	as.Equal("", string(f.GetBytesRange("ship/missing.txt", 0, 3)))
+}
+
+func TestExistsAndStat(t *testing.T) {
+	as := assert.New(t)
+
+	f := getTestFiles()
+	as.True(f.Exists("story/name.txt"))
+	as.False(f.Exists("story"))
+	as.Nil(f.Stat("story/missing.txt"))
+	as.Equal(&fileInfo{Name: "author.txt", Path: "story/author.txt", Size: 13}, f.Stat("story/author.txt"))
+}
+
+func TestTree(t *testing.T) {
+	as := assert.New(t)
+
+	tree := getTestFiles().Glob("s*/**").Tree()
+	as.True(tree.IsDir)
+	as.Equal(len("The Captain")+len("Legatt")+len("The Secret Sharer")+len("Joseph Conrad"), tree.Size)
+	as.Len(tree.Children, 2)
+
+	ship := tree.Children[0]
+	as.Equal("ship", ship.Name)
+	as.True(ship.IsDir)
+	as.Equal(len("The Captain")+len("Legatt"), ship.Size)
+	as.Equal([]*fileInfo{
+		{Name: "captain.txt", Path: "ship/captain.txt", Size: 11},
+		{Name: "stowaway.txt", Path: "ship/stowaway.txt", Size: 6},
+	}, ship.Children)
+
+	as.Equal("story", tree.Children[1].Name)
+	as.Equal("story/name.txt", tree.Children[1].Children[1].Path)
+}
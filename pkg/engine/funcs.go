@@ -19,6 +19,7 @@ package engine
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"strings"
 	"text/template"
 
@@ -26,6 +27,8 @@ import (
 	"github.com/Masterminds/sprig/v3"
 	"sigs.k8s.io/yaml"
 	goYaml "sigs.k8s.io/yaml/goyaml.v3"
+
+	"helm.sh/helm/v4/pkg/chartutil"
 )
 
 // funcMap returns a mapping of all of the functions that Engine has.
@@ -38,6 +41,8 @@ import (
 //
 //   - "include"
 //   - "tpl"
+//   - "lookup"
+//   - "clusterDomain", "serverVersion", "ingressClasses" and "defaultIngressClass"
 //
 // These are late-bound in Engine.Render().  The
 // version included in the FuncMap is a placeholder.
@@ -48,15 +53,23 @@ func funcMap() template.FuncMap {
 
 	// Add some extra functionality
 	extra := template.FuncMap{
-		"toToml":        toTOML,
-		"fromToml":      fromTOML,
-		"toYaml":        toYAML,
-		"toYamlPretty":  toYAMLPretty,
-		"fromYaml":      fromYAML,
-		"fromYamlArray": fromYAMLArray,
-		"toJson":        toJSON,
-		"fromJson":      fromJSON,
-		"fromJsonArray": fromJSONArray,
+		"toToml":            toTOML,
+		"fromToml":          fromTOML,
+		"toYaml":            toYAML,
+		"mustToYaml":        mustToYAML,
+		"toYamlPretty":      toYAMLPretty,
+		"toYamlIndent":      toYAMLIndent,
+		"fromYaml":          fromYAML,
+		"mustFromYaml":      mustFromYAML,
+		"fromYamlArray":     fromYAMLArray,
+		"mustFromYamlArray": mustFromYAMLArray,
+		"toJson":            toJSON,
+		"mustToJson":        mustToJSON,
+		"fromJson":          fromJSON,
+		"mustFromJson":      mustFromJSON,
+		"fromJsonArray":     fromJSONArray,
+		"mustFromJsonArray": mustFromJSONArray,
+		"deepMerge":         deepMerge,
 
 		// This is a placeholder for the "include" function, which is
 		// late-bound to a template. By declaring it here, we preserve the
@@ -64,6 +77,10 @@ func funcMap() template.FuncMap {
 		"include":  func(string, interface{}) string { return "not implemented" },
 		"tpl":      func(string, interface{}) interface{} { return "not implemented" },
 		"required": func(string, interface{}) (interface{}, error) { return "not implemented", nil },
+		// Placeholders for the checksum functions, which are bound to the
+		// templates and the files of a render.
+		"fileChecksum":     func(string, interface{}) (string, error) { return "not implemented", nil },
+		"templateChecksum": func(string, interface{}) (string, error) { return "not implemented", nil },
 		// Provide a placeholder for the "lookup" function, which requires a kubernetes
 		// connection.
 		"lookup": func(string, string, string, string) (map[string]interface{}, error) {
@@ -74,6 +91,9 @@ func funcMap() template.FuncMap {
 	for k, v := range extra {
 		f[k] = v
 	}
+	for k, v := range clusterConfigFuncs() {
+		f[k] = v
+	}
 
 	return f
 }
@@ -91,10 +111,31 @@ func toYAML(v interface{}) string {
 	return strings.TrimSuffix(string(data), "\n")
 }
 
+// mustToYAML is like toYAML but returns the marshal error.
+func mustToYAML(v interface{}) (string, error) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// toYAMLPretty is like toYAMLIndent with an indent of 2.
 func toYAMLPretty(v interface{}) string {
+	return toYAMLIndent(2, v)
+}
+
+// toYAMLIndent marshals an interface to yaml indented by the given number of
+// spaces, which also indents the items of sequences. It will always return a
+// string, even on marshal error (empty string).
+func toYAMLIndent(indent int, v interface{}) string {
+	if indent < 1 {
+		// Swallow errors inside of a template.
+		return ""
+	}
 	var data bytes.Buffer
 	encoder := goYaml.NewEncoder(&data)
-	encoder.SetIndent(2)
+	encoder.SetIndent(indent)
 	err := encoder.Encode(v)
 
 	if err != nil {
@@ -119,6 +160,15 @@ func fromYAML(str string) map[string]interface{} {
 	return m
 }
 
+// mustFromYAML is like fromYAML but returns the parse error.
+func mustFromYAML(str string) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(str), &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // fromYAMLArray converts a YAML array into a []interface{}.
 //
 // This is not a general-purpose YAML parser, and will not parse all valid
@@ -134,6 +184,15 @@ func fromYAMLArray(str string) []interface{} {
 	return a
 }
 
+// mustFromYAMLArray is like fromYAMLArray but returns the parse error.
+func mustFromYAMLArray(str string) ([]interface{}, error) {
+	a := []interface{}{}
+	if err := yaml.Unmarshal([]byte(str), &a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
 // toTOML takes an interface, marshals it to toml, and returns a string. It will
 // always return a string, even on marshal error (empty string).
 //
@@ -176,6 +235,15 @@ func toJSON(v interface{}) string {
 	return string(data)
 }
 
+// mustToJSON is like toJSON but returns the marshal error.
+func mustToJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
 // fromJSON converts a JSON document into a map[string]interface{}.
 //
 // This is not a general-purpose JSON parser, and will not parse all valid
@@ -191,6 +259,15 @@ func fromJSON(str string) map[string]interface{} {
 	return m
 }
 
+// mustFromJSON is like fromJSON but returns the parse error.
+func mustFromJSON(str string) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(str), &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // fromJSONArray converts a JSON array into a []interface{}.
 //
 // This is not a general-purpose JSON parser, and will not parse all valid
@@ -205,3 +282,71 @@ func fromJSONArray(str string) []interface{} {
 	}
 	return a
 }
+
+// mustFromJSONArray is like fromJSONArray but returns the parse error.
+func mustFromJSONArray(str string) ([]interface{}, error) {
+	a := []interface{}{}
+	if err := json.Unmarshal([]byte(str), &a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// deepMerge merges dictionaries the way Helm coalesces values: a later
+// dictionary overrides an earlier one, nested dictionaries are merged,
+// lists and other values are replaced, and a null value deletes the key.
+// The dictionaries are not modified, unlike with sprig's merge and
+// mergeOverwrite.
+func deepMerge(dicts ...interface{}) (map[string]interface{}, error) {
+	dst := map[string]interface{}{}
+	for i, d := range dicts {
+		switch src := d.(type) {
+		case nil:
+		case map[string]interface{}:
+			mergeInto(dst, src)
+		case chartutil.Values:
+			mergeInto(dst, src)
+		default:
+			return nil, fmt.Errorf("deepMerge: argument %d is a %T, not a dictionary", i+1, d)
+		}
+	}
+	return dst, nil
+}
+
+func mergeInto(dst, src map[string]interface{}) {
+	for key, val := range src {
+		switch v := val.(type) {
+		case nil:
+			delete(dst, key)
+		case map[string]interface{}:
+			dv, ok := dst[key].(map[string]interface{})
+			if !ok {
+				dv = map[string]interface{}{}
+				dst[key] = dv
+			}
+			mergeInto(dv, v)
+		default:
+			dst[key] = copyValue(val)
+		}
+	}
+}
+
+// copyValue deep copies the dictionaries and lists of a value, so that the
+// result of deepMerge does not share them with its arguments.
+func copyValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, vv := range v {
+			m[k] = copyValue(vv)
+		}
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i, vv := range v {
+			a[i] = copyValue(vv)
+		}
+		return a
+	}
+	return val
+}
@@ -22,6 +22,8 @@ import (
 	"text/template"
 
 	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v4/pkg/chartutil"
 )
 
 func TestFuncs(t *testing.T) {
@@ -122,6 +124,45 @@ keyInElement1 = "valueInElement1"`,
 		tpl:    `{{ fromYamlArray . }}`,
 		expect: `[error unmarshaling JSON: while decoding JSON: json: cannot unmarshal object into Go value of type []interface {}]`,
 		vars:   `hello: world`,
+	}, {
+		tpl:    `{{ toYamlIndent 4 . }}`,
+		expect: "baz:\n    - 1\n    - 2",
+		vars:   map[string]interface{}{"baz": []int{1, 2}},
+	}, {
+		tpl:    `{{ toYamlIndent 0 . }}`,
+		expect: "",
+		vars:   map[string]interface{}{"baz": []int{1, 2}},
+	}, {
+		tpl:    `{{ mustToYaml . }}`,
+		expect: `foo: bar`,
+		vars:   map[string]interface{}{"foo": "bar"},
+	}, {
+		tpl:    `{{ mustToJson . }}`,
+		expect: `{"foo":"bar"}`,
+		vars:   map[string]interface{}{"foo": "bar"},
+	}, {
+		tpl:    `{{ mustFromYaml . }}`,
+		expect: "map[hello:world]",
+		vars:   `hello: world`,
+	}, {
+		tpl:    `{{ mustFromYamlArray . }}`,
+		expect: "[one 2]",
+		vars:   "- one\n- 2\n",
+	}, {
+		tpl:    `{{ mustFromJson . }}`,
+		expect: "map[hello:world]",
+		vars:   `{"hello":"world"}`,
+	}, {
+		tpl:    `{{ mustFromJsonArray . }}`,
+		expect: "[one 2]",
+		vars:   `["one", 2]`,
+	}, {
+		tpl:    `{{ deepMerge .dst .src }}`,
+		expect: `map[a:map[b:d e:f] g:[h]]`,
+		vars: map[string]interface{}{
+			"dst": map[string]interface{}{"a": map[string]interface{}{"b": "c", "x": "y"}, "g": []interface{}{"i", "j"}},
+			"src": map[string]interface{}{"a": map[string]interface{}{"b": "d", "e": "f", "x": nil}, "g": []interface{}{"h"}},
+		},
 	}, {
 		// This should never result in a network lookup. Regression for #7955
 		tpl:    `{{ lookup "v1" "Namespace" "" "unlikelynamespace99999999" }}`,
@@ -204,3 +245,48 @@ func TestMerge(t *testing.T) {
 	}
 	assert.Equal(t, expected, dict["dst"])
 }
+
+func TestMustFuncsErrors(t *testing.T) {
+	tests := []struct {
+		tpl, vars, expect string
+	}{
+		{`{{ mustFromYaml . }}`, "- one\n- two\n", "cannot unmarshal array"},
+		{`{{ mustFromYamlArray . }}`, "hello: world", "cannot unmarshal object"},
+		{`{{ mustFromJson . }}`, `["one"]`, "cannot unmarshal array"},
+		{`{{ mustFromJsonArray . }}`, `{"hello": "world"}`, "cannot unmarshal object"},
+		{`{{ mustFromJson . }}`, `{"hello"`, "unexpected end of JSON input"},
+		{`{{ deepMerge . "b" }}`, "a", "argument 1 is a string, not a dictionary"},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		err := template.Must(template.New("test").Funcs(funcMap()).Parse(tt.tpl)).Execute(&b, tt.vars)
+		assert.ErrorContains(t, err, tt.expect, tt.tpl)
+	}
+}
+
+func TestDeepMerge(t *testing.T) {
+	defaults := map[string]interface{}{
+		"image": map[string]interface{}{"repository": "nginx", "tag": "1.27"},
+		"ports": []interface{}{map[string]interface{}{"port": 80}},
+		"debug": true,
+	}
+	overrides := chartutil.Values{
+		"image": map[string]interface{}{"tag": "1.28"},
+		"debug": nil,
+		"extra": map[string]interface{}{"unset": nil, "set": "yes"},
+	}
+	got, err := deepMerge(defaults, nil, overrides)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"image": map[string]interface{}{"repository": "nginx", "tag": "1.28"},
+		"ports": []interface{}{map[string]interface{}{"port": 80}},
+		"extra": map[string]interface{}{"set": "yes"},
+	}, got)
+
+	// The arguments must not be modified or shared with the result.
+	got["ports"].([]interface{})[0].(map[string]interface{})["port"] = 8080
+	got["image"].(map[string]interface{})["tag"] = "latest"
+	assert.Equal(t, 80, defaults["ports"].([]interface{})[0].(map[string]interface{})["port"])
+	assert.Equal(t, "1.27", defaults["image"].(map[string]interface{})["tag"])
+	assert.Equal(t, true, defaults["debug"])
+}
@@ -0,0 +1,327 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// uncacheableFuncs are the functions whose templates are never cached
+// because their results differ between calls, they query something outside
+// the render, or they add templates to the template set.
+var uncacheableFuncs = map[string]bool{
+	"now":                      true,
+	"ago":                      true,
+	"randAlphaNum":             true,
+	"randAlpha":                true,
+	"randAscii":                true,
+	"randNumeric":              true,
+	"randBytes":                true,
+	"randInt":                  true,
+	"uuidv4":                   true,
+	"shuffle":                  true,
+	"genPrivateKey":            true,
+	"genCA":                    true,
+	"genCAWithKey":             true,
+	"genSelfSignedCert":        true,
+	"genSelfSignedCertWithKey": true,
+	"genSignedCert":            true,
+	"genSignedCertWithKey":     true,
+	"encryptAES":               true,
+	"bcrypt":                   true,
+	"htpasswd":                 true,
+	"getHostByName":            true,
+	"lookup":                   true,
+	"tpl":                      true,
+	"templateChecksum":         true,
+}
+
+// mutatingFuncs are the functions that modify a dictionary in place. Their
+// templates are never cached, since a cached result would skip the
+// modification, and calling them clears the cache.
+var mutatingFuncs = []string{"set", "unset", "merge", "mergeOverwrite", "mustMerge", "mustMergeOverwrite"}
+
+// includeCache memoizes the output of 'include' while rendering a template
+// file.
+//
+// An include is cached when the included template and the templates it
+// includes use none of the uncacheable, mutating or custom functions and only
+// include templates by constant names. The data of the include must be a
+// scalar, a dictionary whose values are scalars, dictionaries, lists or
+// pointers, or a dictionary, list or pointer itself. Dictionaries, lists and
+// pointers nested in the data are compared by identity, which is why
+// modifying a dictionary with one of the mutating functions clears the
+// cache, and why the cache keeps the data of its entries alive. The cache is
+// cleared for every template file, as the engine sets .Template in the
+// shared top-level context before rendering a file.
+type includeCache struct {
+	t         *template.Template
+	entries   map[string]includeCacheEntry
+	cacheable map[string]bool
+	// depth and decided track the templates analyzed by the outermost
+	// isCacheable call.
+	depth   int
+	decided []string
+}
+
+type includeCacheEntry struct {
+	out string
+	// data keeps the data alive, so that the identities in the key are not
+	// reused while the entry exists.
+	data interface{}
+}
+
+func newIncludeCache(t *template.Template) *includeCache {
+	return &includeCache{
+		t:         t,
+		entries:   map[string]includeCacheEntry{},
+		cacheable: map[string]bool{},
+	}
+}
+
+// reset clears the cached output. It is safe to call on a nil cache.
+func (c *includeCache) reset() {
+	if c != nil && len(c.entries) > 0 {
+		c.entries = map[string]includeCacheEntry{}
+	}
+}
+
+// get returns the cached output of the include and the cache key, which is
+// empty when the include cannot be cached.
+func (c *includeCache) get(name string, data interface{}) (string, string, bool) {
+	if c == nil || !c.isCacheable(name) {
+		return "", "", false
+	}
+	key, ok := dataKey(data)
+	if !ok {
+		return "", "", false
+	}
+	key = name + "\x00" + key
+	e, ok := c.entries[key]
+	return e.out, key, ok
+}
+
+func (c *includeCache) put(key, out string, data interface{}) {
+	if c != nil && key != "" {
+		c.entries[key] = includeCacheEntry{out: out, data: data}
+	}
+}
+
+// wrapMutatingFuncs replaces the mutating functions of the map with ones that
+// clear the cache before modifying a dictionary.
+func (c *includeCache) wrapMutatingFuncs(funcMap template.FuncMap) {
+	for _, name := range mutatingFuncs {
+		fn, ok := funcMap[name]
+		if !ok {
+			continue
+		}
+		v := reflect.ValueOf(fn)
+		funcMap[name] = reflect.MakeFunc(v.Type(), func(args []reflect.Value) []reflect.Value {
+			c.reset()
+			if v.Type().IsVariadic() {
+				return v.CallSlice(args)
+			}
+			return v.Call(args)
+		}).Interface()
+	}
+}
+
+// isCacheable reports whether the output of the template only depends on its
+// data. Templates that are being analyzed are assumed to be cacheable, so
+// that recursive templates are decided by their other calls. When the
+// outermost template turns out not to be cacheable, the templates decided
+// under that assumption are analyzed again the next time.
+func (c *includeCache) isCacheable(name string) bool {
+	if ok, seen := c.cacheable[name]; seen {
+		return ok
+	}
+	c.cacheable[name] = true
+	c.depth++
+	c.decided = append(c.decided, name)
+	ok := false
+	if tmpl := c.t.Lookup(name); tmpl != nil && tmpl.Tree != nil {
+		ok = c.nodeCacheable(tmpl.Tree.Root)
+	}
+	c.cacheable[name] = ok
+	c.depth--
+	if c.depth == 0 {
+		if !ok {
+			for _, n := range c.decided {
+				if c.cacheable[n] {
+					delete(c.cacheable, n)
+				}
+			}
+		}
+		c.decided = c.decided[:0]
+	}
+	return ok
+}
+
+func (c *includeCache) nodeCacheable(node parse.Node) bool {
+	switch n := node.(type) {
+	case nil:
+		return true
+	case *parse.ListNode:
+		if n == nil {
+			return true
+		}
+		for _, child := range n.Nodes {
+			if !c.nodeCacheable(child) {
+				return false
+			}
+		}
+		return true
+	case *parse.ActionNode:
+		return c.pipeCacheable(n.Pipe)
+	case *parse.IfNode:
+		return c.branchCacheable(&n.BranchNode)
+	case *parse.RangeNode:
+		return c.branchCacheable(&n.BranchNode)
+	case *parse.WithNode:
+		return c.branchCacheable(&n.BranchNode)
+	case *parse.TemplateNode:
+		return c.pipeCacheable(n.Pipe) && c.isCacheable(n.Name)
+	case *parse.PipeNode:
+		return c.pipeCacheable(n)
+	case *parse.ChainNode:
+		return c.nodeCacheable(n.Node)
+	case *parse.CommandNode:
+		return c.commandCacheable(n)
+	case *parse.IdentifierNode:
+		return c.funcCacheable(n.Ident)
+	}
+	return true
+}
+
+func (c *includeCache) branchCacheable(n *parse.BranchNode) bool {
+	return c.pipeCacheable(n.Pipe) && c.nodeCacheable(n.List) && c.nodeCacheable(n.ElseList)
+}
+
+func (c *includeCache) pipeCacheable(n *parse.PipeNode) bool {
+	if n == nil {
+		return true
+	}
+	for _, cmd := range n.Cmds {
+		if !c.commandCacheable(cmd) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *includeCache) commandCacheable(n *parse.CommandNode) bool {
+	if len(n.Args) > 0 {
+		if ident, ok := n.Args[0].(*parse.IdentifierNode); ok && ident.Ident == "include" {
+			if len(n.Args) < 2 {
+				return false
+			}
+			name, ok := n.Args[1].(*parse.StringNode)
+			if !ok || !c.isCacheable(name.Text) {
+				return false
+			}
+		}
+	}
+	for _, arg := range n.Args {
+		if !c.nodeCacheable(arg) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *includeCache) funcCacheable(name string) bool {
+	if uncacheableFuncs[name] {
+		return false
+	}
+	for _, m := range mutatingFuncs {
+		if name == m {
+			return false
+		}
+	}
+	return true
+}
+
+// dataKey returns a key for the data of an include, and false when the data
+// cannot be keyed. A dictionary whose values can all be keyed by
+// elementKey is keyed by its content, any other dictionary by identity.
+func dataKey(data interface{}) (string, bool) {
+	if k, ok := scalarKey(data); ok {
+		return k, true
+	}
+	v := reflect.ValueOf(data)
+	if v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String {
+		keys := v.MapKeys()
+		parts := make([]string, 0, len(keys))
+		content := true
+		for _, k := range keys {
+			ek, ok := elementKey(v.MapIndex(k).Interface())
+			if !ok {
+				content = false
+				break
+			}
+			parts = append(parts, fmt.Sprintf("%q=%s", k.String(), ek))
+		}
+		if content {
+			sort.Strings(parts)
+			return fmt.Sprintf("%s{%s}", v.Type(), strings.Join(parts, ",")), true
+		}
+	}
+	return elementKey(data)
+}
+
+// elementKey keys scalars by value, and dictionaries, lists and pointers by
+// identity.
+func elementKey(data interface{}) (string, bool) {
+	if k, ok := scalarKey(data); ok {
+		return k, true
+	}
+	v := reflect.ValueOf(data)
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Pointer:
+		if v.IsNil() {
+			return fmt.Sprintf("%s(nil)", v.Type()), true
+		}
+		return fmt.Sprintf("%s@%x/%d", v.Type(), v.Pointer(), lenOf(v)), true
+	}
+	return "", false
+}
+
+func lenOf(v reflect.Value) int {
+	if v.Kind() == reflect.Pointer {
+		return 0
+	}
+	return v.Len()
+}
+
+func scalarKey(data interface{}) (string, bool) {
+	if data == nil {
+		return "nil", true
+	}
+	switch v := reflect.ValueOf(data); v.Kind() {
+	case reflect.String:
+		return fmt.Sprintf("%s(%q)", v.Type(), v.String()), true
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return fmt.Sprintf("%s(%v)", v.Type(), data), true
+	}
+	return "", false
+}
@@ -0,0 +1,233 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"text/template"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chartutil"
+)
+
+const cacheTestHelpers = `
+{{- define "labels" -}}
+app: {{ .Chart.Name }}
+release: {{ .Release.Name }}
+{{- end -}}
+{{- define "name" -}}{{ .Template.Name }}{{- end -}}
+{{- define "value" -}}{{ .value }}/{{ .context.Values.suffix }}{{- end -}}
+{{- define "nested" -}}{{ include "labels" . | indent 2 }}{{- end -}}
+{{- define "random" -}}{{ randAlphaNum 16 }}{{- end -}}
+{{- define "indirect" -}}{{ include "random" . }}{{- end -}}
+{{- define "mutate" -}}{{ $_ := set .Values "mutated" "yes" }}{{- end -}}
+{{- define "mutated" -}}{{ .Values.mutated }}{{- end -}}
+`
+
+func cacheTestChart() *chart.Chart {
+	return &chart.Chart{
+		Metadata: &chart.Metadata{Name: "cached", Version: "1.0.0"},
+		Templates: []*chart.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(cacheTestHelpers)},
+			{Name: "templates/a", Data: []byte(`{{ include "labels" . }}
+{{ include "labels" . }}
+{{ include "nested" . }}
+{{ include "name" . }}
+{{ include "value" (dict "value" "one" "context" $) }}
+{{ include "value" (dict "value" "one" "context" $) }}
+{{ include "value" (dict "value" "two" "context" $) }}`)},
+			{Name: "templates/b", Data: []byte(`{{ include "name" . }}
+[{{ include "mutated" . }}]{{ include "mutate" . }}[{{ include "mutated" . }}]`)},
+			{Name: "templates/random", Data: []byte(`{{ include "random" . }} {{ include "random" . }} {{ include "indirect" . }} {{ include "indirect" . }}`)},
+		},
+	}
+}
+
+func renderCacheTestChart(t *testing.T, cache bool) map[string]string {
+	t.Helper()
+	c := cacheTestChart()
+	vals, err := chartutil.ToRenderValues(c, map[string]interface{}{"suffix": "x"}, chartutil.ReleaseOptions{Name: "rel"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := Engine{CacheIncludes: cache}.Render(c, vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestRenderCacheIncludes(t *testing.T) {
+	uncached := renderCacheTestChart(t, false)
+	cached := renderCacheTestChart(t, true)
+
+	for _, name := range []string{"cached/templates/a", "cached/templates/b"} {
+		if cached[name] != uncached[name] {
+			t.Errorf("Expected %s to render the same with the cache, got\n%s\nwant\n%s", name, cached[name], uncached[name])
+		}
+	}
+
+	want := "app: cached\nrelease: rel\napp: cached\nrelease: rel\n  app: cached\n  release: rel\ncached/templates/a\none/x\none/x\ntwo/x"
+	if got := cached["cached/templates/a"]; got != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+	if want, got := "cached/templates/b\n[][yes]", cached["cached/templates/b"]; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	random := strings.Fields(cached["cached/templates/random"])
+	if len(random) != 4 {
+		t.Fatalf("Expected 4 random strings, got %q", random)
+	}
+	if random[0] == random[1] || random[2] == random[3] {
+		t.Errorf("Expected random includes not to be cached, got %q", random)
+	}
+}
+
+func TestIncludeCacheIsCacheable(t *testing.T) {
+	tpl := template.New("test").Funcs(funcMap())
+	tpl = template.Must(tpl.Parse(cacheTestHelpers + `
+{{- define "dynamic" -}}{{ include .name . }}{{- end -}}
+{{- define "tpl" -}}{{ tpl "{{ .x }}" . }}{{- end -}}
+{{- define "template" -}}{{ template "random" . }}{{- end -}}
+{{- define "recursive" -}}{{ if .next }}{{ include "recursive" .next }}{{ end }}{{- end -}}
+{{- define "cycle-a" -}}{{ include "cycle-b" . }}{{ now }}{{- end -}}
+{{- define "cycle-b" -}}{{ include "cycle-a" . }}{{- end -}}
+`))
+	c := newIncludeCache(tpl)
+
+	tests := map[string]bool{
+		"labels":    true,
+		"nested":    true,
+		"value":     true,
+		"recursive": true,
+		"random":    false,
+		"indirect":  false,
+		"mutate":    false,
+		"dynamic":   false,
+		"tpl":       false,
+		"template":  false,
+		"cycle-a":   false,
+		"cycle-b":   false,
+		"missing":   false,
+	}
+	for name, want := range tests {
+		if got := c.isCacheable(name); got != want {
+			t.Errorf("Expected isCacheable(%q) to be %t", name, want)
+		}
+	}
+}
+
+func TestIncludeCacheDataKey(t *testing.T) {
+	shared := map[string]interface{}{"a": "b"}
+	k1, ok1 := dataKey(map[string]interface{}{"value": "one", "context": shared})
+	k2, ok2 := dataKey(map[string]interface{}{"context": shared, "value": "one"})
+	if !ok1 || !ok2 || k1 != k2 {
+		t.Errorf("Expected equal dictionaries to have the same key, got %q and %q", k1, k2)
+	}
+	k3, _ := dataKey(map[string]interface{}{"value": "one", "context": map[string]interface{}{"a": "b"}})
+	if k3 == k1 {
+		t.Error("Expected nested dictionaries to be keyed by identity")
+	}
+	if k, _ := dataKey(chartutil.Values{"value": "one"}); k == k1 {
+		t.Error("Expected the type to be part of the key")
+	}
+	if _, ok := dataKey(struct{ A string }{"a"}); ok {
+		t.Error("Expected a struct not to be keyable")
+	}
+	root := map[string]interface{}{"Chart": struct{ Name string }{"x"}}
+	if k, ok := dataKey(root); !ok || !strings.Contains(k, "@") {
+		t.Errorf("Expected a dictionary with a struct to be keyed by identity, got %q", k)
+	}
+}
+
+// umbrellaChart returns an umbrella chart whose subcharts use a library of
+// helpers the way common library charts do, and its render values.
+func umbrellaChart(tb testing.TB) (*chart.Chart, chartutil.Values) {
+	tb.Helper()
+	library := `
+{{- define "lib.name" -}}{{ default .Chart.Name .Values.nameOverride | trunc 63 | trimSuffix "-" }}{{- end -}}
+{{- define "lib.fullname" -}}{{ printf "%s-%s" .Release.Name (include "lib.name" .) | trunc 63 | trimSuffix "-" }}{{- end -}}
+{{- define "lib.selectorLabels" -}}
+app.kubernetes.io/name: {{ include "lib.name" . }}
+app.kubernetes.io/instance: {{ .Release.Name }}
+{{- end -}}
+{{- define "lib.labels" -}}
+helm.sh/chart: {{ printf "%s-%s" .Chart.Name .Chart.Version | replace "+" "_" }}
+{{ include "lib.selectorLabels" . }}
+app.kubernetes.io/managed-by: {{ .Release.Service }}
+{{- end -}}
+{{- define "lib.image" -}}{{ .image.repository }}:{{ .image.tag | default .context.Chart.AppVersion }}{{- end -}}
+`
+	workload := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ include "lib.fullname" . }}
+  labels:
+    {{- include "lib.labels" . | nindent 4 }}
+spec:
+  selector:
+    matchLabels:
+      {{- include "lib.selectorLabels" . | nindent 6 }}
+  template:
+    metadata:
+      labels:
+        {{- include "lib.labels" . | nindent 8 }}
+    spec:
+      containers:
+      {{- range $i, $_ := until 10 }}
+      - name: {{ include "lib.name" $ }}-{{ $i }}
+        image: {{ include "lib.image" (dict "image" $.Values.image "context" $) }}
+        env:
+        - name: FULLNAME
+          value: {{ include "lib.fullname" $ }}
+      {{- end }}
+`
+	umbrella := &chart.Chart{Metadata: &chart.Metadata{Name: "umbrella", Version: "1.0.0"}}
+	for i := 0; i < 20; i++ {
+		sub := &chart.Chart{
+			Metadata:  &chart.Metadata{Name: fmt.Sprintf("sub%d", i), Version: "1.0.0", AppVersion: "1.0.0"},
+			Templates: []*chart.File{{Name: "templates/_lib.tpl", Data: []byte(library)}},
+			Values:    map[string]interface{}{"image": map[string]interface{}{"repository": "nginx"}},
+		}
+		for j := 0; j < 10; j++ {
+			sub.Templates = append(sub.Templates, &chart.File{Name: fmt.Sprintf("templates/deployment%d.yaml", j), Data: []byte(workload)})
+		}
+		umbrella.AddDependency(sub)
+	}
+	vals, err := chartutil.ToRenderValues(umbrella, map[string]interface{}{}, chartutil.ReleaseOptions{Name: "bench", Namespace: "default"}, nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return umbrella, vals
+}
+
+func BenchmarkRenderIncludes(b *testing.B) {
+	umbrella, vals := umbrellaChart(b)
+	for _, cache := range []bool{false, true} {
+		b.Run(fmt.Sprintf("cache=%t", cache), func(b *testing.B) {
+			e := Engine{CacheIncludes: cache}
+			for i := 0; i < b.N; i++ {
+				if _, err := e.Render(umbrella, vals); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
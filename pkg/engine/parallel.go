@@ -0,0 +1,218 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/pkg/chartutil"
+)
+
+type renderResult struct {
+	out string
+	err error
+}
+
+// executeParallel executes the templates of the files on e.Parallelism
+// workers and returns the result of every file.
+//
+// The files of a chart are executed in order by the same worker, and the
+// charts are distributed among the workers. Every worker executes a clone of
+// t with its own 'include' and 'tpl' functions, as these track the recursion
+// depth, and its own include cache. Every file gets a copy of the top-level
+// context of its chart, so that setting .Template does not race with the
+// templates of other charts that read the context through .Subcharts. The
+// digests of the chart files are shared by the workers.
+//
+// The values of the charts are shared by the workers, so render only calls
+// executeParallel when no template modifies a dictionary, see
+// callsMutatingFuncs. Templates parsed by 'tpl' are only known at render
+// time, so the workers fail on the mutating functions instead.
+func (e Engine) executeParallel(t *template.Template, tpls map[string]renderable, files []string, checksums *checksumCache) (map[string]renderResult, error) {
+	var charts [][]string
+	index := map[string]int{}
+	for _, filename := range files {
+		basePath := tpls[filename].basePath
+		i, ok := index[basePath]
+		if !ok {
+			i = len(charts)
+			index[basePath] = i
+			charts = append(charts, nil)
+		}
+		charts[i] = append(charts[i], filename)
+	}
+
+	workers := min(e.Parallelism, len(charts))
+	clones := make([]*template.Template, workers)
+	caches := make([]*includeCache, workers)
+	tracers := make([]*includeTracer, workers)
+	for i := range clones {
+		clone, err := t.Clone()
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot clone template")
+		}
+		if e.CacheIncludes {
+			caches[i] = newIncludeCache(clone)
+		}
+		tracers[i] = e.newTracer()
+		funcMap := e.templateFuncs(clone, caches[i], tracers[i], checksums)
+		rejectMutatingFuncs(funcMap)
+		clone.Funcs(funcMap)
+		clones[i] = clone
+	}
+
+	results := make([][]renderResult, len(charts))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(clone *template.Template, cache *includeCache, tracer *includeTracer) {
+			defer wg.Done()
+			for c := range jobs {
+				results[c] = make([]renderResult, len(charts[c]))
+				for j, filename := range charts[c] {
+					cache.reset()
+					tracer.start()
+					debug := e.Debug.start(filename, tpls[filename])
+					begin := time.Now()
+					results[c][j] = executeFile(clone, filename, tpls[filename])
+					elapsed := time.Since(begin)
+					e.Debug.finish(debug, tracer, elapsed, results[c][j].err)
+					e.Stats.record(filename, tpls[filename], tracer, elapsed, len(results[c][j].out))
+				}
+			}
+		}(clones[i], caches[i], tracers[i])
+	}
+	for c := range charts {
+		jobs <- c
+	}
+	close(jobs)
+	wg.Wait()
+
+	byFile := make(map[string]renderResult, len(files))
+	for c, filenames := range charts {
+		for j, filename := range filenames {
+			byFile[filename] = results[c][j]
+		}
+	}
+	return byFile, nil
+}
+
+// executeFile executes the template of a file with a copy of its context.
+// A panic is returned as an error, as it cannot be recovered by render.
+func executeFile(t *template.Template, filename string, r renderable) (res renderResult) {
+	defer func() {
+		if p := recover(); p != nil {
+			res = renderResult{err: errors.Errorf("rendering template failed: %v", p)}
+		}
+	}()
+	vals := make(chartutil.Values, len(r.vals)+1)
+	for k, v := range r.vals {
+		vals[k] = v
+	}
+	vals["Template"] = chartutil.Values{"Name": filename, "BasePath": r.basePath}
+
+	var buf strings.Builder
+	err := t.ExecuteTemplate(&buf, filename, vals)
+	return renderResult{out: buf.String(), err: err}
+}
+
+// rejectMutatingFuncs replaces the mutating functions of the map with ones
+// that fail, as a template calling them in a worker would race with the
+// templates reading the same values in the other workers.
+func rejectMutatingFuncs(funcMap template.FuncMap) {
+	for _, name := range mutatingFuncs {
+		fn, ok := funcMap[name]
+		if !ok {
+			continue
+		}
+		funcMap[name] = reflect.MakeFunc(reflect.TypeOf(fn), func([]reflect.Value) []reflect.Value {
+			panic(fmt.Sprintf("%s cannot be called through tpl when rendering in parallel", name))
+		}).Interface()
+	}
+}
+
+// callsMutatingFuncs reports whether a template of t calls a function that
+// modifies a dictionary in place, which requires rendering sequentially.
+func callsMutatingFuncs(t *template.Template) bool {
+	for _, tpl := range t.Templates() {
+		if tpl.Tree != nil && nodeCallsMutatingFuncs(tpl.Tree.Root) {
+			return true
+		}
+	}
+	return false
+}
+
+func nodeCallsMutatingFuncs(node parse.Node) bool {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, c := range n.Nodes {
+			if nodeCallsMutatingFuncs(c) {
+				return true
+			}
+		}
+	case *parse.ActionNode:
+		return nodeCallsMutatingFuncs(n.Pipe)
+	case *parse.IfNode:
+		return branchCallsMutatingFuncs(&n.BranchNode)
+	case *parse.RangeNode:
+		return branchCallsMutatingFuncs(&n.BranchNode)
+	case *parse.WithNode:
+		return branchCallsMutatingFuncs(&n.BranchNode)
+	case *parse.TemplateNode:
+		return nodeCallsMutatingFuncs(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, c := range n.Cmds {
+			if nodeCallsMutatingFuncs(c) {
+				return true
+			}
+		}
+	case *parse.ChainNode:
+		return nodeCallsMutatingFuncs(n.Node)
+	case *parse.CommandNode:
+		for _, a := range n.Args {
+			if nodeCallsMutatingFuncs(a) {
+				return true
+			}
+		}
+	case *parse.IdentifierNode:
+		for _, m := range mutatingFuncs {
+			if n.Ident == m {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func branchCallsMutatingFuncs(n *parse.BranchNode) bool {
+	return nodeCallsMutatingFuncs(n.Pipe) || nodeCallsMutatingFuncs(n.List) || nodeCallsMutatingFuncs(n.ElseList)
+}
@@ -0,0 +1,187 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chartutil"
+)
+
+func TestRenderParallel(t *testing.T) {
+	umbrella, vals := umbrellaChart(t)
+	want, err := Engine{}.Render(umbrella, vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, e := range []Engine{{Parallelism: 4}, {Parallelism: 4, CacheIncludes: true}, {Parallelism: 100}} {
+		got, err := e.Render(umbrella, vals)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("Expected parallelism %d with cache %t to render like sequential rendering", e.Parallelism, e.CacheIncludes)
+		}
+	}
+}
+
+func TestRenderParallelMutatingFuncs(t *testing.T) {
+	umbrella := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "umbrella", Version: "1.0.0"},
+		Templates: []*chart.File{
+			{Name: "templates/a", Data: []byte(`{{ $_ := set .Values "seen" "umbrella" }}{{ range $name, $sub := .Values }}{{ if kindIs "map" $sub }}{{ $name }}={{ $sub.seen }} {{ end }}{{ end }}`)},
+		},
+	}
+	for i := 0; i < 8; i++ {
+		umbrella.AddDependency(&chart.Chart{
+			Metadata: &chart.Metadata{Name: fmt.Sprintf("sub%d", i), Version: "1.0.0"},
+			Templates: []*chart.File{
+				{Name: "templates/a", Data: []byte(`{{ $_ := set .Values "seen" .Chart.Name }}`)},
+				{Name: "templates/b", Data: []byte(`{{ .Values.seen }}`)},
+			},
+		})
+	}
+	// The values are modified by the render, so every render gets its own.
+	render := func(e Engine) (map[string]string, error) {
+		vals, err := chartutil.ToRenderValues(umbrella, map[string]interface{}{}, chartutil.ReleaseOptions{Name: "rel"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e.Render(umbrella, vals)
+	}
+
+	want, err := render(Engine{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		got, err := render(Engine{Parallelism: 4})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Fatalf("Expected a chart calling set to render sequentially\nwant %v\ngot  %v", want, got)
+		}
+	}
+}
+
+func TestRenderParallelMutatingFuncsInTpl(t *testing.T) {
+	umbrella, vals := umbrellaChart(t)
+	umbrella.Templates = append(umbrella.Templates, &chart.File{Name: "templates/tpl", Data: []byte(`{{ tpl "{{ $_ := set .Values \"x\" 1 }}" . }}`)})
+
+	_, err := Engine{Parallelism: 4}.Render(umbrella, vals)
+	if err == nil || !strings.Contains(err.Error(), "set cannot be called through tpl when rendering in parallel") {
+		t.Errorf("Expected an error calling set through tpl, got %v", err)
+	}
+	if _, err := (Engine{}).Render(umbrella, vals); err != nil {
+		t.Errorf("Expected set to be callable through tpl when rendering sequentially, got %v", err)
+	}
+}
+
+func failingUmbrellaChart(t *testing.T) (*chart.Chart, chartutil.Values) {
+	t.Helper()
+	umbrella := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "umbrella", Version: "1.0.0"},
+		Templates: []*chart.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{ define "shared" }}{{ .Chart.Name }}-{{ .Template.Name }}{{ end }}`)},
+			{Name: "templates/ok", Data: []byte(`{{ include "shared" . }}`)},
+		},
+	}
+	for i := 0; i < 8; i++ {
+		sub := &chart.Chart{
+			Metadata: &chart.Metadata{Name: fmt.Sprintf("sub%d", i), Version: "1.0.0"},
+			Templates: []*chart.File{
+				{Name: "templates/ok", Data: []byte(`{{ include "shared" . }}`)},
+			},
+		}
+		if i%3 == 0 {
+			sub.Templates = append(sub.Templates, &chart.File{Name: "templates/fail", Data: []byte(fmt.Sprintf(`{{ fail "sub%d failed" }}`, i))})
+		}
+		umbrella.AddDependency(sub)
+	}
+	vals, err := chartutil.ToRenderValues(umbrella, map[string]interface{}{}, chartutil.ReleaseOptions{Name: "rel"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return umbrella, vals
+}
+
+func TestRenderParallelErrors(t *testing.T) {
+	umbrella, vals := failingUmbrellaChart(t)
+
+	_, want := Engine{}.Render(umbrella, vals)
+	if want == nil {
+		t.Fatal("Expected an error")
+	}
+	for i := 0; i < 10; i++ {
+		_, err := Engine{Parallelism: 4}.Render(umbrella, vals)
+		if err == nil || err.Error() != want.Error() {
+			t.Fatalf("Expected the error of sequential rendering %q, got %v", want, err)
+		}
+	}
+
+	_, want = Engine{ReportAllErrors: true}.Render(umbrella, vals)
+	_, err := Engine{ReportAllErrors: true, Parallelism: 4}.Render(umbrella, vals)
+	var wantErrs, gotErrs RenderErrors
+	if !errors.As(want, &wantErrs) || !errors.As(err, &gotErrs) {
+		t.Fatalf("Expected RenderErrors, got %v and %v", want, err)
+	}
+	if len(gotErrs) != 3 || want.Error() != err.Error() {
+		t.Errorf("Expected the errors of sequential rendering\n%s\ngot\n%s", want, err)
+	}
+}
+
+func TestRenderParallelTemplateName(t *testing.T) {
+	umbrella, vals := failingUmbrellaChart(t)
+	for _, dep := range umbrella.Dependencies() {
+		dep.Templates = dep.Templates[:1]
+	}
+
+	out, err := Engine{Parallelism: 3}.Render(umbrella, vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range out {
+		chartName := "umbrella"
+		if parts := strings.Split(name, "/"); parts[1] == "charts" {
+			chartName = parts[2]
+		}
+		if want := chartName + "-" + name; content != want {
+			t.Errorf("Expected %q, got %q", want, content)
+		}
+	}
+}
+
+func BenchmarkRenderParallel(b *testing.B) {
+	umbrella, vals := umbrellaChart(b)
+	for _, parallelism := range []int{1, 4} {
+		b.Run(fmt.Sprintf("parallelism=%d", parallelism), func(b *testing.B) {
+			e := Engine{Parallelism: parallelism}
+			for i := 0; i < b.N; i++ {
+				if _, err := e.Render(umbrella, vals); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RenderStats collects the resource usage of the templates rendered by an
+// Engine with Stats set, so that chart authors can find the templates that
+// make a large chart slow to render.
+//
+// Like RenderDebug, a RenderStats can be reused by several renders, each
+// render replacing the stats of the previous one.
+type RenderStats struct {
+	// Templates are the stats of the rendered template files, sorted by name.
+	// The time spent in partials is accounted to the files including them.
+	Templates []*TemplateStats
+
+	mu sync.Mutex
+}
+
+// TemplateStats is the resource usage of a rendered template file.
+type TemplateStats struct {
+	// Name is the name of the template, e.g. "mychart/templates/service.yaml".
+	Name string `json:"name"`
+	// Chart is the path of the chart of the template, e.g.
+	// "mychart/charts/subchart".
+	Chart string `json:"chart"`
+	// Duration is the time spent rendering the template.
+	Duration metav1.Duration `json:"duration"`
+	// OutputBytes is the size of the rendered template.
+	OutputBytes int `json:"outputBytes"`
+	// Includes is the number of calls of 'include', including the nested
+	// ones and the ones served from the include cache.
+	Includes int `json:"includes"`
+	// Lookups is the number of calls of 'lookup'.
+	Lookups int `json:"lookups"`
+}
+
+// ChartStats is the resource usage of the templates of a chart, not counting
+// its dependencies.
+type ChartStats struct {
+	// Chart is the path of the chart, e.g. "mychart/charts/subchart".
+	Chart       string          `json:"chart"`
+	Templates   int             `json:"templates"`
+	Duration    metav1.Duration `json:"duration"`
+	OutputBytes int             `json:"outputBytes"`
+	Includes    int             `json:"includes"`
+	Lookups     int             `json:"lookups"`
+}
+
+// Charts sums the stats of the templates by chart, sorted by chart path.
+func (s *RenderStats) Charts() []*ChartStats {
+	byChart := map[string]*ChartStats{}
+	var charts []*ChartStats
+	for _, ts := range s.Templates {
+		cs, ok := byChart[ts.Chart]
+		if !ok {
+			cs = &ChartStats{Chart: ts.Chart}
+			byChart[ts.Chart] = cs
+			charts = append(charts, cs)
+		}
+		cs.Templates++
+		cs.Duration.Duration += ts.Duration.Duration
+		cs.OutputBytes += ts.OutputBytes
+		cs.Includes += ts.Includes
+		cs.Lookups += ts.Lookups
+	}
+	sort.Slice(charts, func(i, j int) bool { return charts[i].Chart < charts[j].Chart })
+	return charts
+}
+
+// reset clears the stats of a previous render.
+func (s *RenderStats) reset() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Templates = nil
+}
+
+// record records the stats of a rendered template file.
+func (s *RenderStats) record(filename string, r renderable, tracer *includeTracer, elapsed time.Duration, size int) {
+	if s == nil {
+		return
+	}
+	ts := &TemplateStats{
+		Name:        filename,
+		Chart:       path.Dir(r.basePath),
+		Duration:    metav1.Duration{Duration: elapsed},
+		OutputBytes: size,
+		Includes:    tracer.includes,
+		Lookups:     tracer.lookups,
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Templates = append(s.Templates, ts)
+}
+
+// sort sorts the recorded templates by name once the render is done.
+func (s *RenderStats) sort() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sort.Slice(s.Templates, func(i, j int) bool { return s.Templates[i].Name < s.Templates[j].Name })
+}
@@ -0,0 +1,83 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chartutil"
+)
+
+func TestRenderStats(t *testing.T) {
+	for _, e := range []Engine{{}, {Parallelism: 2}, {CacheIncludes: true}, {Debug: &RenderDebug{}}} {
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{Name: "parent", Version: "1.0.0"},
+			Templates: []*chart.File{
+				{Name: "templates/_helpers.tpl", Data: []byte(`{{ define "outer" }}{{ include "inner" . }}{{ end }}{{ define "inner" }}{{ .Values.name }}{{ end }}`)},
+				{Name: "templates/cm", Data: []byte(`{{ include "outer" . }}{{ include "inner" . }}{{ lookup "v1" "ConfigMap" "ns" "name" | len }}`)},
+				{Name: "templates/svc", Data: []byte(`{{ .Values.name }}`)},
+			},
+			Values: map[string]interface{}{"name": "parent"},
+		}
+		sub := &chart.Chart{
+			Metadata: &chart.Metadata{Name: "child", Version: "1.0.0"},
+			Templates: []*chart.File{
+				{Name: "templates/cm", Data: []byte(`{{ tpl "{{ .Values.name }}" . }}`)},
+			},
+			Values: map[string]interface{}{"name": "child"},
+		}
+		c.AddDependency(sub)
+		vals, err := chartutil.ToRenderValues(c, map[string]interface{}{}, chartutil.ReleaseOptions{Name: "rel"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stats := &RenderStats{}
+		e.Stats = stats
+		if _, err := e.Render(c, vals); err != nil {
+			t.Fatal(err)
+		}
+
+		expect := []TemplateStats{
+			{Name: "parent/charts/child/templates/cm", Chart: "parent/charts/child", OutputBytes: 5},
+			{Name: "parent/templates/cm", Chart: "parent", OutputBytes: 13, Includes: 3, Lookups: 1},
+			{Name: "parent/templates/svc", Chart: "parent", OutputBytes: 6},
+		}
+		if len(stats.Templates) != len(expect) {
+			t.Fatalf("expected %d templates, got %d", len(expect), len(stats.Templates))
+		}
+		for i, ts := range stats.Templates {
+			got := *ts
+			got.Duration.Duration = 0
+			if got != expect[i] {
+				t.Errorf("expected %+v, got %+v", expect[i], got)
+			}
+		}
+
+		charts := stats.Charts()
+		if len(charts) != 2 {
+			t.Fatalf("expected 2 charts, got %d", len(charts))
+		}
+		if cs := charts[0]; cs.Chart != "parent" || cs.Templates != 2 || cs.OutputBytes != 19 || cs.Includes != 3 || cs.Lookups != 1 {
+			t.Errorf("unexpected stats of the parent chart: %+v", cs)
+		}
+		if cs := charts[1]; cs.Chart != "parent/charts/child" || cs.Templates != 1 {
+			t.Errorf("unexpected stats of the child chart: %+v", cs)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/pkg/errors"
+)
+
+// Strictness selects the problems that make rendering fail. By default the
+// engine is lenient and renders what it can.
+type Strictness struct {
+	// FailOnMissingValues fails rendering when a template references a value
+	// that was not passed in.
+	FailOnMissingValues bool
+	// FailOnUndefinedTemplates fails rendering when a template includes a
+	// named template that is not defined, even if the include is never
+	// executed, e.g. because it is in a disabled branch.
+	FailOnUndefinedTemplates bool
+	// FailOnLookupWithoutCluster fails rendering when a template calls lookup
+	// while no cluster is available, instead of returning an empty result.
+	FailOnLookupWithoutCluster bool
+}
+
+func (e Engine) failOnMissingValues() bool {
+	return e.Strict || e.FailOnMissingValues
+}
+
+// checkTemplateReferences reports every 'include' and 'template' that refers
+// to a named template which is not defined. Only constant names can be
+// checked.
+func checkTemplateReferences(t *template.Template) error {
+	var problems []string
+	for _, tpl := range t.Templates() {
+		if tpl.Tree == nil || tpl.Tree.Root == nil {
+			continue
+		}
+		walkTemplateReferences(tpl.Tree.Root, func(name string) {
+			if ref := t.Lookup(name); ref == nil || ref.Tree == nil {
+				problems = append(problems, fmt.Sprintf("%s: template %q is not defined", tpl.Tree.ParseName, name))
+			}
+		})
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return errors.Errorf("undefined templates:\n%s", strings.Join(dedupe(problems), "\n"))
+}
+
+func walkTemplateReferences(node parse.Node, fn func(string)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			walkTemplateReferences(c, fn)
+		}
+	case *parse.ActionNode:
+		walkTemplateReferences(n.Pipe, fn)
+	case *parse.IfNode:
+		walkBranchReferences(&n.BranchNode, fn)
+	case *parse.RangeNode:
+		walkBranchReferences(&n.BranchNode, fn)
+	case *parse.WithNode:
+		walkBranchReferences(&n.BranchNode, fn)
+	case *parse.TemplateNode:
+		fn(n.Name)
+		walkTemplateReferences(n.Pipe, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Cmds {
+			walkTemplateReferences(c, fn)
+		}
+	case *parse.CommandNode:
+		if len(n.Args) > 1 {
+			if id, ok := n.Args[0].(*parse.IdentifierNode); ok && id.Ident == "include" {
+				if s, ok := n.Args[1].(*parse.StringNode); ok {
+					fn(s.Text)
+				}
+			}
+		}
+		for _, a := range n.Args {
+			walkTemplateReferences(a, fn)
+		}
+	}
+}
+
+func walkBranchReferences(n *parse.BranchNode, fn func(string)) {
+	walkTemplateReferences(n.Pipe, fn)
+	walkTemplateReferences(n.List, fn)
+	walkTemplateReferences(n.ElseList, fn)
+}
+
+func dedupe(sorted []string) []string {
+	out := sorted[:0]
+	for i, s := range sorted {
+		if i == 0 || s != sorted[i-1] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
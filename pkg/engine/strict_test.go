@@ -0,0 +1,115 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chartutil"
+)
+
+func renderStrict(t *testing.T, e Engine, tpl string) error {
+	t.Helper()
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*chart.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{ define "moby.name" }}moby{{ end }}`)},
+			{Name: "templates/test", Data: []byte(tpl)},
+		},
+	}
+	v, err := chartutil.CoalesceValues(c, map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Failed to coalesce values: %s", err)
+	}
+	_, err = e.Render(c, chartutil.Values{"Values": v, "Chart": c.Metadata})
+	return err
+}
+
+func TestStrictness(t *testing.T) {
+	tests := []struct {
+		name       string
+		strictness Strictness
+		lintMode   bool
+		tpl        string
+		err        string
+	}{
+		{
+			name: "missing value is lenient by default",
+			tpl:  `{{ .Values.nope }}`,
+		},
+		{
+			name:       "missing value",
+			strictness: Strictness{FailOnMissingValues: true},
+			tpl:        `{{ .Values.nope }}`,
+			err:        "nope",
+		},
+		{
+			name: "undefined template in a disabled branch is lenient by default",
+			tpl:  `{{ if false }}{{ include "moby.nope" . }}{{ end }}`,
+		},
+		{
+			name:       "undefined include in a disabled branch",
+			strictness: Strictness{FailOnUndefinedTemplates: true},
+			tpl:        `{{ include "moby.name" . }}{{ if false }}{{ include "moby.nope" . | nindent 2 }}{{ end }}`,
+			err:        `moby/templates/test: template "moby.nope" is not defined`,
+		},
+		{
+			name:       "undefined template action in an else branch",
+			strictness: Strictness{FailOnUndefinedTemplates: true},
+			tpl:        `{{ with .Values }}ok{{ else }}{{ template "moby.other" . }}{{ end }}`,
+			err:        `template "moby.other" is not defined`,
+		},
+		{
+			name:       "defined templates",
+			strictness: Strictness{FailOnUndefinedTemplates: true},
+			tpl:        `{{ include "moby.name" . }}{{ template "moby.name" . }}`,
+		},
+		{
+			name: "lookup without cluster is lenient by default",
+			tpl:  `{{ lookup "v1" "Secret" "default" "creds" }}`,
+		},
+		{
+			name:       "lookup without cluster",
+			strictness: Strictness{FailOnLookupWithoutCluster: true},
+			tpl:        `{{ lookup "v1" "Secret" "default" "creds" }}`,
+			err:        "lookup of v1 Secret requires a connection to a Kubernetes cluster",
+		},
+		{
+			name:       "lookup while linting",
+			strictness: Strictness{FailOnLookupWithoutCluster: true},
+			lintMode:   true,
+			tpl:        `{{ lookup "v1" "Secret" "default" "creds" }}`,
+			err:        "requires a connection to a Kubernetes cluster",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := renderStrict(t, Engine{Strictness: tt.strictness, LintMode: tt.lintMode}, tt.tpl)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
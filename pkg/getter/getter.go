@@ -41,12 +41,18 @@ type options struct {
 	acceptHeader          string
 	username              string
 	password              string
+	bearerToken           string
 	passCredentialsAll    bool
 	userAgent             string
 	version               string
 	registryClient        *registry.Client
 	timeout               time.Duration
 	transport             *http.Transport
+	proxy                 string
+	minTLSVersion         string
+	credsProvider         registry.CredentialsProvider
+	headers               http.Header
+	values                bool
 }
 
 // Option allows specifying various settings configurable by the user for overriding the defaults
@@ -76,12 +82,31 @@ func WithBasicAuth(username, password string) Option {
 	}
 }
 
+// WithBearerToken sets the request's Authorization header to use the provided
+// bearer token. Like basic auth credentials, the token is only sent to the
+// host of the getter URL unless credentials are passed to all hosts.
+func WithBearerToken(token string) Option {
+	return func(opts *options) {
+		opts.bearerToken = token
+	}
+}
+
 func WithPassCredentialsAll(pass bool) Option {
 	return func(opts *options) {
 		opts.passCredentialsAll = pass
 	}
 }
 
+// WithHeaders adds headers to the requests of the HTTP getter, e.g. the
+// Authorization header of a server of values files. Like basic auth
+// credentials, the headers are only sent to the host of the getter URL unless
+// credentials are passed to all hosts.
+func WithHeaders(headers http.Header) Option {
+	return func(opts *options) {
+		opts.headers = headers
+	}
+}
+
 // WithUserAgent sets the request's User-Agent header to use the provided agent name.
 func WithUserAgent(userAgent string) Option {
 	return func(opts *options) {
@@ -105,6 +130,29 @@ func WithTLSClientConfig(certFile, keyFile, caFile string) Option {
 	}
 }
 
+// WithProxy sets the URL of the proxy to use instead of the one set in the
+// environment. The http, https, socks5 and socks5h schemes are supported.
+func WithProxy(proxy string) Option {
+	return func(opts *options) {
+		opts.proxy = proxy
+	}
+}
+
+// WithMinTLSVersion sets the minimum TLS version, e.g. "1.2".
+func WithMinTLSVersion(version string) Option {
+	return func(opts *options) {
+		opts.minTLSVersion = version
+	}
+}
+
+// WithCredentialsProvider sets a provider of credentials, such as an auth
+// plugin. Its credentials are used for hosts without explicit credentials.
+func WithCredentialsProvider(provider registry.CredentialsProvider) Option {
+	return func(opts *options) {
+		opts.credsProvider = provider
+	}
+}
+
 func WithPlainHTTP(plainHTTP bool) Option {
 	return func(opts *options) {
 		opts.plainHTTP = plainHTTP
@@ -136,6 +184,14 @@ func WithUntar() Option {
 	}
 }
 
+// WithValues informs the OCI getter that the reference is a values artifact
+// rather than a chart, see registry.Client.PullValues.
+func WithValues() Option {
+	return func(opts *options) {
+		opts.values = true
+	}
+}
+
 // WithTransport sets the http.Transport to allow overwriting the HTTPGetter default.
 func WithTransport(transport *http.Transport) Option {
 	return func(opts *options) {
@@ -214,6 +270,14 @@ var ociProvider = Provider{
 // notations are collected.
 func All(settings *cli.EnvSettings) Providers {
 	result := Providers{httpProvider, ociProvider}
+	if auth := collectAuthProvider(settings); auth != nil {
+		result[0] = Provider{
+			Schemes: httpProvider.Schemes,
+			New: func(options ...Option) (Getter, error) {
+				return httpProvider.New(append([]Option{WithCredentialsProvider(auth)}, options...)...)
+			},
+		}
+	}
 	pluginDownloaders, _ := collectPlugins(settings)
 	result = append(result, pluginDownloaders...)
 	return result
@@ -25,9 +25,10 @@ import (
 
 	"github.com/pkg/errors"
 
-	"helm.sh/helm/v4/internal/tlsutil"
+	"helm.sh/helm/v4/internal/transport"
 	"helm.sh/helm/v4/internal/urlutil"
 	"helm.sh/helm/v4/internal/version"
+	"helm.sh/helm/v4/pkg/registry"
 )
 
 // HTTPGetter is the default HTTP(/S) backend handler
@@ -62,8 +63,9 @@ func (g *HTTPGetter) get(href string) (*bytes.Buffer, error) {
 		req.Header.Set("User-Agent", g.opts.userAgent)
 	}
 
-	// Before setting the basic auth credentials, make sure the URL associated
-	// with the basic auth is the one being fetched.
+	// Before setting the basic auth credentials, the bearer token and the
+	// headers, make sure the URL associated with them is the one being
+	// fetched.
 	u1, err := url.Parse(g.opts.url)
 	if err != nil {
 		return nil, errors.Wrap(err, "Unable to parse getter URL")
@@ -80,6 +82,29 @@ func (g *HTTPGetter) get(href string) (*bytes.Buffer, error) {
 		if g.opts.username != "" && g.opts.password != "" {
 			req.SetBasicAuth(g.opts.username, g.opts.password)
 		}
+		if g.opts.bearerToken != "" {
+			req.Header.Set("Authorization", "Bearer "+g.opts.bearerToken)
+		}
+		for name, values := range g.opts.headers {
+			req.Header.Del(name)
+			for _, v := range values {
+				req.Header.Add(name, v)
+			}
+		}
+	}
+
+	// Credentials from the provider are specific to the host, so they are
+	// only used when no other credentials were set.
+	if req.Header.Get("Authorization") == "" {
+		creds, err := g.providedCredentials(u2.Host)
+		if err != nil {
+			return nil, err
+		}
+		if creds != nil {
+			if header := creds.AuthorizationHeader(); header != "" {
+				req.Header.Set("Authorization", header)
+			}
+		}
 	}
 
 	client, err := g.httpClient()
@@ -127,29 +152,25 @@ func (g *HTTPGetter) httpClient() (*http.Client, error) {
 		}
 	})
 
-	if (g.opts.certFile != "" && g.opts.keyFile != "") || g.opts.caFile != "" || g.opts.insecureSkipVerifyTLS {
-		tlsConf, err := tlsutil.NewClientTLS(g.opts.certFile, g.opts.keyFile, g.opts.caFile, g.opts.insecureSkipVerifyTLS)
-		if err != nil {
-			return nil, errors.Wrap(err, "can't create TLS config for client")
-		}
-
-		sni, err := urlutil.ExtractHostname(g.opts.url)
-		if err != nil {
-			return nil, err
-		}
-		tlsConf.ServerName = sni
-
-		g.transport.TLSClientConfig = tlsConf
+	sni, err := urlutil.ExtractHostname(g.opts.url)
+	if err != nil {
+		return nil, err
 	}
-
-	if g.opts.insecureSkipVerifyTLS {
-		if g.transport.TLSClientConfig == nil {
-			g.transport.TLSClientConfig = &tls.Config{
-				InsecureSkipVerify: true,
-			}
-		} else {
-			g.transport.TLSClientConfig.InsecureSkipVerify = true
-		}
+	certs, err := g.providedCertificates()
+	if err != nil {
+		return nil, err
+	}
+	if err := transport.Apply(g.transport, transport.Options{
+		Proxy:                 g.opts.proxy,
+		CAFile:                g.opts.caFile,
+		CertFile:              g.opts.certFile,
+		KeyFile:               g.opts.keyFile,
+		InsecureSkipTLSVerify: g.opts.insecureSkipVerifyTLS,
+		MinTLSVersion:         g.opts.minTLSVersion,
+		ServerName:            sni,
+		Certificates:          certs,
+	}); err != nil {
+		return nil, err
 	}
 
 	client := &http.Client{
@@ -159,3 +180,34 @@ func (g *HTTPGetter) httpClient() (*http.Client, error) {
 
 	return client, nil
 }
+
+// providedCredentials returns the credentials of the credentials provider for
+// the host, if any.
+func (g *HTTPGetter) providedCredentials(host string) (*registry.Credentials, error) {
+	if g.opts.credsProvider == nil || host == "" {
+		return nil, nil
+	}
+	creds, err := g.opts.credsProvider.Credentials(host)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to retrieve credentials for %s", host)
+	}
+	return creds, nil
+}
+
+// providedCertificates returns the client certificate of the credentials
+// provider for the host of the getter URL, if any.
+func (g *HTTPGetter) providedCertificates() ([]tls.Certificate, error) {
+	u, err := url.Parse(g.opts.url)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := g.providedCredentials(u.Host)
+	if err != nil || creds == nil || creds.ClientCertificate == "" {
+		return nil, err
+	}
+	cert, err := tls.X509KeyPair([]byte(creds.ClientCertificate), []byte(creds.ClientKey))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid client certificate for %s", u.Host)
+	}
+	return []tls.Certificate{cert}, nil
+}
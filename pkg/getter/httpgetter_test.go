@@ -33,6 +33,7 @@ import (
 	"helm.sh/helm/v4/internal/tlsutil"
 	"helm.sh/helm/v4/internal/version"
 	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/registry"
 )
 
 func TestHTTPGetter(t *testing.T) {
@@ -305,6 +306,142 @@ func TestDownload(t *testing.T) {
 	}
 }
 
+func TestDownloadThroughProxy(t *testing.T) {
+	expect := "Call me Ishmael"
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		fmt.Fprint(w, expect)
+	}))
+	defer proxy.Close()
+
+	target := "http://charts.example.com/index.yaml"
+	g, err := NewHTTPGetter(WithURL(target), WithProxy(proxy.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := g.Get(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != expect {
+		t.Errorf("Expected %q, got %q", expect, got.String())
+	}
+	if proxied != target {
+		t.Errorf("Expected the request for %q to go through the proxy, got %q", target, proxied)
+	}
+
+	g, _ = NewHTTPGetter(WithURL(target), WithProxy("ftp://proxy"))
+	if _, err := g.Get(target); err == nil {
+		t.Error("Expected an error for an invalid proxy URL")
+	}
+}
+
+type staticCredentials map[string]*registry.Credentials
+
+func (s staticCredentials) Credentials(host string) (*registry.Credentials, error) {
+	return s[host], nil
+}
+
+func TestDownloadWithCredentialsProvider(t *testing.T) {
+	var authHeader string
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		authHeader = r.Header.Get("Authorization")
+	}))
+	defer srv.Close()
+
+	u, _ := url.ParseRequestURI(srv.URL)
+	provider := staticCredentials{u.Host: {Token: "secret"}}
+
+	g, err := NewHTTPGetter(WithURL(srv.URL), WithCredentialsProvider(provider))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Get(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if authHeader != "Bearer secret" {
+		t.Errorf("Expected the provided token, got %q", authHeader)
+	}
+
+	// Explicit credentials take precedence over the provider.
+	g, _ = NewHTTPGetter(WithURL(srv.URL), WithCredentialsProvider(provider), WithBasicAuth("user", "pass"))
+	if _, err := g.Get(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if authHeader != "Basic dXNlcjpwYXNz" {
+		t.Errorf("Expected basic auth, got %q", authHeader)
+	}
+}
+
+func TestDownloadWithHeaders(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = r.Header
+	}))
+	defer srv.Close()
+
+	u, _ := url.ParseRequestURI(srv.URL)
+	provider := staticCredentials{u.Host: {Token: "secret"}}
+	headers := http.Header{"Authorization": {"Bearer values-token"}, "X-Env": {"prod"}}
+
+	g, err := NewHTTPGetter(WithURL(srv.URL), WithCredentialsProvider(provider), WithHeaders(headers))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Get(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("Authorization") != "Bearer values-token" || got.Get("X-Env") != "prod" {
+		t.Errorf("Expected the headers to be sent and to take precedence over the provider, got %v", got)
+	}
+
+	// The headers are not sent to other hosts.
+	g, _ = NewHTTPGetter(WithURL("https://values.example.com"), WithHeaders(headers))
+	if _, err := g.Get(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("Authorization") != "" || got.Get("X-Env") != "" {
+		t.Errorf("Expected no headers for another host, got %v", got)
+	}
+}
+
+func TestDownloadWithBearerToken(t *testing.T) {
+	var authHeader string
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		authHeader = r.Header.Get("Authorization")
+	}))
+	defer srv.Close()
+
+	g, err := NewHTTPGetter(WithURL(srv.URL), WithBasicAuth("user", "pass"), WithBearerToken("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Get(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if authHeader != "Bearer secret" {
+		t.Errorf("Expected the bearer token to take precedence over basic auth, got %q", authHeader)
+	}
+
+	// The token is not sent to other hosts.
+	g, _ = NewHTTPGetter(WithURL("https://charts.example.com"), WithBearerToken("secret"))
+	if _, err := g.Get(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if authHeader != "" {
+		t.Errorf("Expected no Authorization header for another host, got %q", authHeader)
+	}
+
+	g, _ = NewHTTPGetter(WithURL("https://charts.example.com"), WithBearerToken("secret"), WithPassCredentialsAll(true))
+	if _, err := g.Get(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if authHeader != "Bearer secret" {
+		t.Errorf("Expected the bearer token to be passed to all hosts, got %q", authHeader)
+	}
+}
+
 func TestDownloadTLS(t *testing.T) {
 	cd := "../../testdata"
 	ca, pub, priv := filepath.Join(cd, "rootca.crt"), filepath.Join(cd, "crt.pem"), filepath.Join(cd, "key.pem")
@@ -62,6 +62,14 @@ func (g *OCIGetter) get(href string) (*bytes.Buffer, error) {
 	if version := g.opts.version; version != "" && !strings.Contains(path.Base(ref), ":") {
 		ref = fmt.Sprintf("%s:%s", ref, version)
 	}
+	if g.opts.values {
+		result, err := client.PullValues(ref)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewBuffer(result.Values.Data), nil
+	}
+
 	var pullOpts []registry.PullOption
 	requestingProv := strings.HasSuffix(ref, ".prov")
 	if requestingProv {
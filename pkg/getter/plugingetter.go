@@ -27,6 +27,7 @@ import (
 
 	"helm.sh/helm/v4/pkg/cli"
 	"helm.sh/helm/v4/pkg/plugin"
+	"helm.sh/helm/v4/pkg/registry"
 )
 
 // collectPlugins scans for getter plugins.
@@ -36,6 +37,8 @@ func collectPlugins(settings *cli.EnvSettings) (Providers, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Plugins with unsatisfied dependencies are left out.
+	plugins, _ = plugin.ResolveDependencies(plugins)
 	var result Providers
 	for _, plugin := range plugins {
 		for _, downloader := range plugin.Metadata.Downloaders {
@@ -53,6 +56,21 @@ func collectPlugins(settings *cli.EnvSettings) (Providers, error) {
 	return result, nil
 }
 
+// collectAuthProvider returns a credentials provider backed by the auth/v1
+// plugins, or nil when there are none.
+func collectAuthProvider(settings *cli.EnvSettings) registry.CredentialsProvider {
+	plugins, err := plugin.FindPlugins(settings.PluginsDirectory)
+	if err != nil {
+		return nil
+	}
+	plugins, _ = plugin.ResolveDependencies(plugins)
+	auth := plugin.NewAuthProvider(plugins, plugin.DefaultExecLimits)
+	if auth.Empty() {
+		return nil
+	}
+	return auth
+}
+
 // pluginGetter is a generic type to invoke custom downloaders,
 // implemented in plugins.
 type pluginGetter struct {
@@ -0,0 +1,115 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmtest
+
+import (
+	"helm.sh/helm/v4/pkg/chart"
+)
+
+// DefaultTemplate is the template of the charts built by BuildChart, a
+// ConfigMap named after the release.
+const DefaultTemplate = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}-hello
+data:
+  greeting: hello
+`
+
+// ChartOption configures a chart built by BuildChart.
+type ChartOption func(*chart.Chart)
+
+// BuildChart returns an in-memory chart named "hello" with the version 0.1.0
+// and the templates/hello.yaml template, configured by the options.
+func BuildChart(opts ...ChartOption) *chart.Chart {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "hello",
+			Version:    "0.1.0",
+		},
+		Templates: []*chart.File{
+			{Name: "templates/hello.yaml", Data: []byte(DefaultTemplate)},
+		},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithName sets the name of the chart.
+func WithName(name string) ChartOption {
+	return func(c *chart.Chart) {
+		c.Metadata.Name = name
+	}
+}
+
+// WithVersion sets the version of the chart.
+func WithVersion(version string) ChartOption {
+	return func(c *chart.Chart) {
+		c.Metadata.Version = version
+	}
+}
+
+// WithKubeVersion sets the Kubernetes version constraint of the chart.
+func WithKubeVersion(constraint string) ChartOption {
+	return func(c *chart.Chart) {
+		c.Metadata.KubeVersion = constraint
+	}
+}
+
+// WithValues sets the default values of the chart.
+func WithValues(values map[string]interface{}) ChartOption {
+	return func(c *chart.Chart) {
+		c.Values = values
+	}
+}
+
+// WithTemplate adds a template to the chart. The name is relative to the
+// templates/ directory.
+func WithTemplate(name, data string) ChartOption {
+	return func(c *chart.Chart) {
+		c.Templates = append(c.Templates, &chart.File{Name: "templates/" + name, Data: []byte(data)})
+	}
+}
+
+// WithoutTemplates removes the templates added so far, including the default
+// template.
+func WithoutTemplates() ChartOption {
+	return func(c *chart.Chart) {
+		c.Templates = nil
+	}
+}
+
+// WithNotes sets the notes of the chart.
+func WithNotes(notes string) ChartOption {
+	return WithTemplate("NOTES.txt", notes)
+}
+
+// WithDependency adds a subchart built with the options to the charts/
+// directory of the chart and declares it in Chart.yaml.
+func WithDependency(opts ...ChartOption) ChartOption {
+	return func(c *chart.Chart) {
+		sub := BuildChart(opts...)
+		c.AddDependency(sub)
+		c.Metadata.Dependencies = append(c.Metadata.Dependencies, &chart.Dependency{
+			Name:    sub.Name(),
+			Version: sub.Metadata.Version,
+		})
+	}
+}
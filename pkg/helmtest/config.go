@@ -0,0 +1,45 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmtest
+
+import (
+	"testing"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/storage"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// NewConfiguration returns an action configuration for tests. Releases are
+// stored in memory, the Kubernetes client is a new *KubeClient, the
+// capabilities are the defaults of chartutil and the log goes to t.Logf.
+//
+// The Kubernetes client can be retrieved and scripted with
+// cfg.KubeClient.(*helmtest.KubeClient).
+func NewConfiguration(t testing.TB) *action.Configuration {
+	t.Helper()
+	return &action.Configuration{
+		Releases:     storage.Init(driver.NewMemory()),
+		KubeClient:   NewKubeClient(),
+		Capabilities: chartutil.DefaultCapabilities.Copy(),
+		Log: func(format string, v ...interface{}) {
+			t.Helper()
+			t.Logf(format, v...)
+		},
+	}
+}
@@ -0,0 +1,29 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package helmtest provides helpers for unit testing code that uses the Helm SDK.
+
+It provides builders for in-memory charts, an action.Configuration backed by
+in-memory release storage and a fake Kubernetes client, a Kubernetes client
+whose behavior can be scripted per method, and golden file assertions.
+
+	cfg := helmtest.NewConfiguration(t)
+	install := action.NewInstall(cfg)
+	install.ReleaseName = "test"
+	rel, err := install.Run(helmtest.BuildChart(helmtest.WithValues(vals)), nil)
+*/
+package helmtest // import "helm.sh/helm/v4/pkg/helmtest"
@@ -0,0 +1,68 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmtest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// UpdateGoldenEnvVar is the environment variable which, when set to true,
+// makes the golden file assertions rewrite the golden files instead of
+// failing, e.g. HELM_UPDATE_GOLDEN=true go test ./...
+const UpdateGoldenEnvVar = "HELM_UPDATE_GOLDEN"
+
+// AssertGoldenString asserts that the string matches the content of the
+// golden file. Relative file names are resolved against the testdata
+// directory. Setting UpdateGoldenEnvVar rewrites the golden files instead.
+func AssertGoldenString(t testing.TB, actual, filename string) {
+	t.Helper()
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join("testdata", filename)
+	}
+	got := normalize([]byte(actual))
+	if update, _ := strconv.ParseBool(os.Getenv(UpdateGoldenEnvVar)); update {
+		if err := os.WriteFile(filename, got, 0666); err != nil {
+			t.Fatalf("unable to update golden file %s: %v", filename, err)
+		}
+	}
+	want, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("unable to read golden file %s: %v", filename, err)
+	}
+	if want = normalize(want); !bytes.Equal(want, got) {
+		t.Fatalf("does not match golden file %s\n\nWANT:\n'%s'\n\nGOT:\n'%s'", filename, want, got)
+	}
+}
+
+// AssertGoldenFile asserts that the content of the file matches the content
+// of the golden file, like AssertGoldenString.
+func AssertGoldenFile(t testing.TB, actualFilename, expectedFilename string) {
+	t.Helper()
+	actual, err := os.ReadFile(actualFilename)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	AssertGoldenString(t, string(actual), expectedFilename)
+}
+
+func normalize(in []byte) []byte {
+	return bytes.ReplaceAll(in, []byte("\r\n"), []byte("\n"))
+}
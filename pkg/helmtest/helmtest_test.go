@@ -0,0 +1,101 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmtest_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/helmtest"
+	"helm.sh/helm/v4/pkg/kube"
+	"helm.sh/helm/v4/pkg/release"
+)
+
+func TestInstall(t *testing.T) {
+	cfg := helmtest.NewConfiguration(t)
+	install := action.NewInstall(cfg)
+	install.ReleaseName = "test"
+	install.Namespace = "default"
+
+	ch := helmtest.BuildChart(
+		helmtest.WithValues(map[string]interface{}{"replicas": 2}),
+		helmtest.WithTemplate("values.yaml", "replicas: {{ .Values.replicas }}\n"),
+		helmtest.WithNotes("installed {{ .Release.Name }}"),
+		helmtest.WithDependency(helmtest.WithName("sub"), helmtest.WithoutTemplates()),
+	)
+	rel, err := install.Run(ch, map[string]interface{}{"replicas": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rel.Info.Status != release.StatusDeployed {
+		t.Errorf("expected the release to be deployed, got %s", rel.Info.Status)
+	}
+	if rel.Info.Notes != "installed test" {
+		t.Errorf("unexpected notes %q", rel.Info.Notes)
+	}
+	helmtest.AssertGoldenString(t, rel.Manifest, "install-manifest.yaml")
+
+	if _, err := cfg.Releases.Get("test", 1); err != nil {
+		t.Errorf("expected the release to be stored: %s", err)
+	}
+}
+
+func TestKubeClientScripts(t *testing.T) {
+	cfg := helmtest.NewConfiguration(t)
+	client := cfg.KubeClient.(*helmtest.KubeClient)
+	client.WaitFunc = func(_ kube.ResourceList, timeout time.Duration) error {
+		return errors.New("timed out after " + timeout.String())
+	}
+
+	install := action.NewInstall(cfg)
+	install.ReleaseName = "test"
+	install.Wait = true
+	install.Timeout = time.Minute
+	install.DisableHooks = true
+	rel, err := install.Run(helmtest.BuildChart(), nil)
+	if err == nil || err.Error() != "timed out after 1m0s" {
+		t.Fatalf("expected the scripted error, got %v", err)
+	}
+	if rel.Info.Status != release.StatusFailed {
+		t.Errorf("expected the release to have failed, got %s", rel.Info.Status)
+	}
+
+	var methods []string
+	for _, call := range client.Calls() {
+		methods = append(methods, call.Method)
+	}
+	if len(methods) != 1 || methods[0] != "Wait" {
+		t.Errorf("expected a single call to Wait, got %v", methods)
+	}
+}
+
+func TestUpdateGolden(t *testing.T) {
+	golden := filepath.Join(t.TempDir(), "out.txt")
+	if err := os.WriteFile(golden, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(helmtest.UpdateGoldenEnvVar, "true")
+	helmtest.AssertGoldenString(t, "new\n", golden)
+	if b, err := os.ReadFile(golden); err != nil || string(b) != "new\n" {
+		t.Errorf("expected the golden file to be updated, got %q, %v", b, err)
+	}
+}
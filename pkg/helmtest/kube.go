@@ -0,0 +1,174 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmtest
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+)
+
+// Call is a call recorded by KubeClient.
+type Call struct {
+	// Method is the name of the called method, e.g. "Create".
+	Method string
+	// Resources are the resources passed to the method. For Update, they are
+	// the target resources.
+	Resources kube.ResourceList
+}
+
+// KubeClient is a fake Kubernetes client whose behavior can be scripted per
+// method. It records the calls to the methods that change or wait for
+// resources. Methods without a script succeed without doing anything.
+type KubeClient struct {
+	kubefake.PrintingKubeClient
+
+	// BuildFunc, when set, builds the resources of a manifest. By default no
+	// resources are built.
+	BuildFunc func(reader io.Reader, validate bool) (kube.ResourceList, error)
+	// CreateFunc, UpdateFunc and DeleteFunc, when set, replace the results of
+	// the methods. By default all the resources are reported as created,
+	// updated or deleted.
+	CreateFunc func(resources kube.ResourceList) (*kube.Result, error)
+	UpdateFunc func(original, target kube.ResourceList, force bool) (*kube.Result, error)
+	DeleteFunc func(resources kube.ResourceList) (*kube.Result, []error)
+	// WaitFunc, when set, is called by Wait, WaitWithJobs and WatchUntilReady.
+	WaitFunc func(resources kube.ResourceList, timeout time.Duration) error
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// NewKubeClient returns a KubeClient without scripts.
+func NewKubeClient() *KubeClient {
+	return &KubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}}
+}
+
+// Calls returns the calls recorded so far.
+func (c *KubeClient) Calls() []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Call(nil), c.calls...)
+}
+
+func (c *KubeClient) record(method string, resources kube.ResourceList) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, Call{Method: method, Resources: resources})
+}
+
+// Build implements kube.Interface.
+func (c *KubeClient) Build(reader io.Reader, validate bool) (kube.ResourceList, error) {
+	if c.BuildFunc != nil {
+		return c.BuildFunc(reader, validate)
+	}
+	return c.PrintingKubeClient.Build(reader, validate)
+}
+
+// Create implements kube.Interface.
+func (c *KubeClient) Create(resources kube.ResourceList) (*kube.Result, error) {
+	c.record("Create", resources)
+	if c.CreateFunc != nil {
+		return c.CreateFunc(resources)
+	}
+	return &kube.Result{Created: resources}, nil
+}
+
+// Update implements kube.Interface.
+func (c *KubeClient) Update(original, target kube.ResourceList, force bool) (*kube.Result, error) {
+	c.record("Update", target)
+	if c.UpdateFunc != nil {
+		return c.UpdateFunc(original, target, force)
+	}
+	return &kube.Result{Updated: target}, nil
+}
+
+// Delete implements kube.Interface.
+func (c *KubeClient) Delete(resources kube.ResourceList) (*kube.Result, []error) {
+	c.record("Delete", resources)
+	if c.DeleteFunc != nil {
+		return c.DeleteFunc(resources)
+	}
+	return &kube.Result{Deleted: resources}, nil
+}
+
+// BuildContext implements kube.Interface.
+func (c *KubeClient) BuildContext(ctx context.Context, reader io.Reader, validate bool) (kube.ResourceList, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.Build(reader, validate)
+}
+
+// CreateContext implements kube.Interface.
+func (c *KubeClient) CreateContext(ctx context.Context, resources kube.ResourceList, opts ...kube.ApplyOption) (*kube.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return &kube.Result{}, err
+	}
+	result, err := c.Create(resources)
+	if err == nil {
+		kube.ReportApplied(result, opts...)
+	}
+	return result, err
+}
+
+// UpdateContext implements kube.Interface.
+func (c *KubeClient) UpdateContext(ctx context.Context, original, target kube.ResourceList, force bool, opts ...kube.ApplyOption) (*kube.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return &kube.Result{}, err
+	}
+	result, err := c.Update(original, target, force)
+	if err == nil {
+		kube.ReportApplied(result, opts...)
+	}
+	return result, err
+}
+
+// DeleteContext implements kube.Interface.
+func (c *KubeClient) DeleteContext(ctx context.Context, resources kube.ResourceList) (*kube.Result, []error) {
+	if err := ctx.Err(); err != nil {
+		return nil, []error{err}
+	}
+	return c.Delete(resources)
+}
+
+// Wait implements kube.Interface.
+func (c *KubeClient) Wait(resources kube.ResourceList, timeout time.Duration) error {
+	return c.wait("Wait", resources, timeout)
+}
+
+// WaitWithJobs implements kube.Interface.
+func (c *KubeClient) WaitWithJobs(resources kube.ResourceList, timeout time.Duration) error {
+	return c.wait("WaitWithJobs", resources, timeout)
+}
+
+// WatchUntilReady implements kube.Interface.
+func (c *KubeClient) WatchUntilReady(resources kube.ResourceList, timeout time.Duration) error {
+	return c.wait("WatchUntilReady", resources, timeout)
+}
+
+func (c *KubeClient) wait(method string, resources kube.ResourceList, timeout time.Duration) error {
+	c.record(method, resources)
+	if c.WaitFunc != nil {
+		return c.WaitFunc(resources, timeout)
+	}
+	return nil
+}
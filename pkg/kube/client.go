@@ -139,11 +139,43 @@ func (c *Client) IsReachable() error {
 
 // Create creates Kubernetes resources specified in the resource list.
 func (c *Client) Create(resources ResourceList) (*Result, error) {
+	return c.CreateContext(context.Background(), resources)
+}
+
+// CreateContext creates Kubernetes resources specified in the resource list.
+// No request is made once ctx is done, and requests in flight are limited to
+// the deadline of ctx.
+//
+// If an error occurs, a Result is still returned with the timings of the
+// resources.
+func (c *Client) CreateContext(ctx context.Context, resources ResourceList, opts ...ApplyOption) (*Result, error) {
 	c.Log("creating %d resource(s)", len(resources))
-	if err := perform(resources, createResource); err != nil {
-		return nil, err
+	o := newApplyOptions(opts)
+	t := &resourceTimer{ctx: ctx, timeout: o.resourceTimeout}
+	defer t.limit(resources)()
+	if err := perform(resources, func(info *resource.Info) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if o.saveConfig {
+			if err := saveConfig(info); err != nil {
+				return err
+			}
+		}
+		start := time.Now()
+		if err := t.record(info, "create", start, createResource(info)); err != nil {
+			return err
+		}
+		o.applied("created", info)
+		return nil
+	}); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			// Report the cancellation once rather than for every resource
+			err = ctxErr
+		}
+		return &Result{Timings: t.timings}, err
 	}
-	return &Result{Created: resources}, nil
+	return &Result{Created: resources, Timings: t.timings}, nil
 }
 
 func transformRequests(req *rest.Request) {
@@ -339,6 +371,14 @@ func (c *Client) newBuilder() *resource.Builder {
 		Flatten()
 }
 
+// BuildContext works like Build, but fails when ctx is done.
+func (c *Client) BuildContext(ctx context.Context, reader io.Reader, validate bool) (ResourceList, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.Build(reader, validate)
+}
+
 // Build validates for Kubernetes objects and returns unstructured infos.
 func (c *Client) Build(reader io.Reader, validate bool) (ResourceList, error) {
 	validationDirective := metav1.FieldValidationIgnore
@@ -379,6 +419,19 @@ func (c *Client) BuildTable(reader io.Reader, validate bool) (ResourceList, erro
 	return result, scrubValidationError(err)
 }
 
+// Select returns the live resources of the kinds, e.g. "deployments" or
+// "all", that match the label selector, in the namespace of the client.
+func (c *Client) Select(kinds []string, selector string) (ResourceList, error) {
+	if len(kinds) == 0 {
+		return nil, errors.New("no kinds of resources to select")
+	}
+	return c.newBuilder().
+		Unstructured().
+		LabelSelectorParam(selector).
+		ResourceTypeOrNameArgs(true, strings.Join(kinds, ",")).
+		Do().Infos()
+}
+
 // Update takes the current list of objects and target list of objects and
 // creates resources that don't already exist, updates resources that have been
 // modified in the target configuration, and deletes resources from the current
@@ -387,31 +440,53 @@ func (c *Client) BuildTable(reader io.Reader, validate bool) (ResourceList, erro
 // resource updates, creations, and deletions that were attempted. These can be
 // used for cleanup or other logging purposes.
 func (c *Client) Update(original, target ResourceList, force bool) (*Result, error) {
+	return c.UpdateContext(context.Background(), original, target, force)
+}
+
+// UpdateContext works like Update, but no request is made once ctx is done,
+// and requests in flight are limited to the deadline of ctx.
+func (c *Client) UpdateContext(ctx context.Context, original, target ResourceList, force bool, opts ...ApplyOption) (*Result, error) {
 	updateErrors := []string{}
 	res := &Result{}
+	o := newApplyOptions(opts)
+	t := &resourceTimer{ctx: ctx, timeout: o.resourceTimeout}
+	defer t.limit(original, target)()
+	defer func() { res.Timings = t.timings }()
 
 	c.Log("checking %d resources for changes", len(target))
 	err := target.Visit(func(info *resource.Info, err error) error {
 		if err != nil {
 			return err
 		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
+		if o.saveConfig {
+			if err := saveConfig(info); err != nil {
+				return err
+			}
+		}
+
+		start := time.Now()
 		helper := resource.NewHelper(info.Client, info.Mapping).WithFieldManager(getManagedFieldsManager())
-		if _, err := helper.Get(info.Namespace, info.Name); err != nil {
+		live, err := helper.Get(info.Namespace, info.Name)
+		if err != nil {
 			if !apierrors.IsNotFound(err) {
-				return errors.Wrap(err, "could not get information about the resource")
+				return errors.Wrap(t.record(info, "get", start, err), "could not get information about the resource")
 			}
 
 			// Append the created resource to the results, even if something fails
 			res.Created = append(res.Created, info)
 
 			// Since the resource does not exist, create it.
-			if err := createResource(info); err != nil {
+			if err := t.record(info, "create", start, createResource(info)); err != nil {
 				return errors.Wrap(err, "failed to create resource")
 			}
 
 			kind := info.Mapping.GroupVersionKind.Kind
 			c.Log("Created a new %s called %q in %s\n", kind, info.Name, info.Namespace)
+			o.applied("created", info)
 			return nil
 		}
 
@@ -421,9 +496,24 @@ func (c *Client) Update(original, target ResourceList, force bool) (*Result, err
 			return errors.Errorf("no %s with the name %q found", kind, info.Name)
 		}
 
-		if err := updateResource(c, info, originalInfo.Object, force); err != nil {
+		originalObj := originalInfo.Object
+		if o.saveConfig {
+			// Like kubectl apply, merge from the configuration last applied
+			// by either of them.
+			lastApplied, err := lastAppliedConfig(live)
+			if err != nil {
+				return errors.Wrapf(err, "unable to read the last applied configuration of %q", info.Name)
+			}
+			if lastApplied != nil {
+				originalObj = lastApplied
+			}
+		}
+
+		if err := t.record(info, "update", start, updateResource(c, info, originalObj, force)); err != nil {
 			c.Log("error updating the resource %q:\n\t %v", info.Name, err)
 			updateErrors = append(updateErrors, err.Error())
+		} else {
+			o.applied("updated", info)
 		}
 		// Because we check for errors later, append the info regardless
 		res.Updated = append(res.Updated, info)
@@ -439,6 +529,9 @@ func (c *Client) Update(original, target ResourceList, force bool) (*Result, err
 	}
 
 	for _, info := range original.Difference(target) {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
 		c.Log("Deleting %s %q in namespace %s...", info.Mapping.GroupVersionKind.Kind, info.Name, info.Namespace)
 
 		if err := info.Get(); err != nil {
@@ -453,11 +546,13 @@ func (c *Client) Update(original, target ResourceList, force bool) (*Result, err
 			c.Log("Skipping delete of %q due to annotation [%s=%s]", info.Name, ResourcePolicyAnno, KeepPolicy)
 			continue
 		}
-		if err := deleteResource(info, metav1.DeletePropagationBackground); err != nil {
+		start := time.Now()
+		if err := t.record(info, "delete", start, deleteResource(info, metav1.DeletePropagationBackground)); err != nil {
 			c.Log("Failed to delete %q, err: %s", info.ObjectName(), err)
 			continue
 		}
 		res.Deleted = append(res.Deleted, info)
+		o.applied("deleted", info)
 	}
 	return res, nil
 }
@@ -467,7 +562,13 @@ func (c *Client) Update(original, target ResourceList, force bool) (*Result, err
 // if one or more fail and collect any errors. All successfully deleted items
 // will be returned in the `Deleted` ResourceList that is part of the result.
 func (c *Client) Delete(resources ResourceList) (*Result, []error) {
-	return rdelete(c, resources, metav1.DeletePropagationBackground)
+	return rdelete(context.Background(), c, resources, metav1.DeletePropagationBackground)
+}
+
+// DeleteContext works like Delete, but no request is made once ctx is done,
+// and requests in flight are limited to the deadline of ctx.
+func (c *Client) DeleteContext(ctx context.Context, resources ResourceList) (*Result, []error) {
+	return rdelete(ctx, c, resources, metav1.DeletePropagationBackground)
 }
 
 // Delete deletes Kubernetes resources specified in the resources list with
@@ -475,16 +576,21 @@ func (c *Client) Delete(resources ResourceList) (*Result, []error) {
 // if one or more fail and collect any errors. All successfully deleted items
 // will be returned in the `Deleted` ResourceList that is part of the result.
 func (c *Client) DeleteWithPropagationPolicy(resources ResourceList, policy metav1.DeletionPropagation) (*Result, []error) {
-	return rdelete(c, resources, policy)
+	return rdelete(context.Background(), c, resources, policy)
 }
 
-func rdelete(c *Client, resources ResourceList, propagation metav1.DeletionPropagation) (*Result, []error) {
+func rdelete(ctx context.Context, c *Client, resources ResourceList, propagation metav1.DeletionPropagation) (*Result, []error) {
 	var errs []error
 	res := &Result{}
 	mtx := sync.Mutex{}
+	t := &resourceTimer{ctx: ctx}
+	defer t.limit(resources)()
 	err := perform(resources, func(info *resource.Info) error {
 		c.Log("Starting delete for %q %s", info.Name, info.Mapping.GroupVersionKind.Kind)
-		err := deleteResource(info, propagation)
+		err := ctx.Err()
+		if err == nil {
+			err = deleteResource(info, propagation)
+		}
 		if err == nil || apierrors.IsNotFound(err) {
 			if err != nil {
 				c.Log("Ignoring delete failure for %q %s: %v", info.Name, info.Mapping.GroupVersionKind, err)
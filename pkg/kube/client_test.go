@@ -18,19 +18,27 @@ package kube
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/rest/fake"
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+
+	"helm.sh/helm/v4/pkg/annotations"
 )
 
 var unstructuredSerializer = resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer
@@ -276,7 +284,11 @@ func TestUpdate(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	result, err := c.Update(first, second, false)
+	// The resources are reported as soon as they are applied.
+	onApplied := OnApplied(func(action string, info *resource.Info) {
+		actions = append(actions, action+":"+info.Name)
+	})
+	result, err := c.UpdateContext(context.Background(), first, second, false, onApplied)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -305,15 +317,19 @@ func TestUpdate(t *testing.T) {
 		"/namespaces/default/pods/starfish:GET",
 		"/namespaces/default/pods/starfish:GET",
 		"/namespaces/default/pods/starfish:PATCH",
+		"updated:starfish",
 		"/namespaces/default/pods/otter:GET",
 		"/namespaces/default/pods/otter:GET",
 		"/namespaces/default/pods/otter:GET",
+		"updated:otter",
 		"/namespaces/default/pods/dolphin:GET",
 		"/namespaces/default/pods:POST", // create dolphin
 		"/namespaces/default/pods:POST", // retry due to 409
 		"/namespaces/default/pods:POST", // retry due to 409
+		"created:otter",                 // the object returned for dolphin
 		"/namespaces/default/pods/squid:GET",
 		"/namespaces/default/pods/squid:DELETE",
+		"deleted:squid",
 	}
 	if len(expectedActions) != len(actions) {
 		t.Fatalf("unexpected number of requests, expected %d, got %d", len(expectedActions), len(actions))
@@ -325,6 +341,127 @@ func TestUpdate(t *testing.T) {
 	}
 }
 
+func TestUpdateResourceTimeout(t *testing.T) {
+	original := newPodList("otter")
+	target := newPodList("otter", "dolphin")
+
+	var timeouts []string
+	c := newTestClient(t)
+	c.Factory.(*cmdtesting.TestFactory).UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: unstructuredSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			p, m := req.URL.Path, req.Method
+			timeouts = append(timeouts, req.URL.Query().Get("timeout"))
+			switch {
+			case p == "/namespaces/default/pods/otter" && m == "GET":
+				return newResponse(200, &original.Items[0])
+			case p == "/namespaces/default/pods/dolphin" && m == "GET":
+				return newResponse(404, notFoundBody())
+			case p == "/namespaces/default/pods" && m == "POST":
+				return newResponse(504, &metav1.Status{
+					Code:   http.StatusGatewayTimeout,
+					Status: metav1.StatusFailure,
+					Reason: metav1.StatusReasonTimeout,
+				})
+			default:
+				t.Fatalf("unexpected request: %s %s", req.Method, req.URL.Path)
+				return nil, nil
+			}
+		}),
+	}
+	first, err := c.Build(objBody(&original), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.Build(objBody(&target), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := c.UpdateContext(context.Background(), first, second, false, ResourceTimeout(5*time.Second))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), `Pod "dolphin" in namespace "default" did not create within the resource timeout of 5s`) {
+		t.Errorf("unexpected error message: %q", err)
+	}
+	for _, timeout := range timeouts {
+		if timeout != "5s" {
+			t.Errorf("expected every request to be limited to 5s, got %q", timeout)
+		}
+	}
+
+	if len(result.Timings) != 2 {
+		t.Fatalf("expected 2 timings, got %d", len(result.Timings))
+	}
+	if timing := result.Timings[0]; timing.Name != "otter" || timing.Operation != "update" || timing.TimedOut {
+		t.Errorf("unexpected timing %+v", timing)
+	}
+	timedOut := result.TimedOut()
+	if len(timedOut) != 1 || timedOut[0].Name != "dolphin" || timedOut[0].Operation != "create" {
+		t.Errorf("expected the creation of dolphin to time out, got %+v", timedOut)
+	}
+	for _, info := range second {
+		if _, ok := info.Client.(*fake.RESTClient); !ok {
+			t.Errorf("expected the client of %s to be restored, got %T", info.Name, info.Client)
+		}
+	}
+}
+
+func TestContext(t *testing.T) {
+	list := newPodList("otter")
+
+	var timeouts []string
+	c := newTestClient(t)
+	c.Factory.(*cmdtesting.TestFactory).UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: unstructuredSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			p, m := req.URL.Path, req.Method
+			timeouts = append(timeouts, req.URL.Query().Get("timeout"))
+			switch {
+			case p == "/namespaces/default/pods" && m == "POST":
+				return newResponse(200, &list.Items[0])
+			default:
+				t.Fatalf("unexpected request: %s %s", req.Method, req.URL.Path)
+				return nil, nil
+			}
+		}),
+	}
+	resources, err := c.Build(objBody(&list), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if _, err := c.CreateContext(ctx, resources, ResourceTimeout(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if len(timeouts) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(timeouts))
+	}
+	if timeout, err := time.ParseDuration(timeouts[0]); err != nil || timeout <= 0 || timeout > time.Minute {
+		t.Errorf("expected the request to be limited to the deadline of the context, got %q", timeouts[0])
+	}
+
+	cancel()
+	if _, err := c.CreateContext(ctx, resources); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the creation to be cancelled, got %v", err)
+	}
+	if _, err := c.UpdateContext(ctx, resources, resources, false); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the update to be cancelled, got %v", err)
+	}
+	if _, errs := c.DeleteContext(ctx, resources); len(errs) != 1 || !errors.Is(errs[0], context.Canceled) {
+		t.Errorf("expected the deletion to be cancelled, got %v", errs)
+	}
+	if _, err := c.BuildContext(ctx, objBody(&list), false); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the build to be cancelled, got %v", err)
+	}
+	if len(timeouts) != 1 {
+		t.Errorf("expected no request once the context is cancelled, got %d", len(timeouts)-1)
+	}
+}
+
 func TestBuild(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -403,6 +540,36 @@ func TestBuildTable(t *testing.T) {
 	}
 }
 
+func TestSelect(t *testing.T) {
+	list := newPodList("starfish", "otter")
+	c := newTestClient(t)
+	c.Factory.(*cmdtesting.TestFactory).UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: unstructuredSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			p, m := req.URL.Path, req.Method
+			if p != "/namespaces/default/pods" || m != http.MethodGet {
+				t.Fatalf("unexpected request: %s %s", m, p)
+			}
+			if s := req.URL.Query().Get("labelSelector"); s != "app=web" {
+				t.Errorf("expected the label selector app=web, got %q", s)
+			}
+			return newResponse(200, &list)
+		}),
+	}
+
+	infos, err := c.Select([]string{"pods"}, "app=web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 2 || infos[0].Name != "starfish" || infos[1].Name != "otter" {
+		t.Errorf("expected the pods starfish and otter, got %v", infos)
+	}
+
+	if _, err := c.Select(nil, "app=web"); err == nil {
+		t.Error("expected an error without kinds")
+	}
+}
+
 func TestPerform(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -585,6 +752,62 @@ func TestWaitJob(t *testing.T) {
 	}
 }
 
+func TestWaitAnnotations(t *testing.T) {
+	podList := newPodList("starfish", "otter", "squid")
+	podList.Items[0].Annotations = map[string]string{annotations.NoWait: "true"}
+	podList.Items[1].Annotations = map[string]string{annotations.WaitTimeout: "1s"}
+	podList.Items[2].Status.Conditions = []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}
+
+	c := newTestClient(t)
+	c.Factory.(*cmdtesting.TestFactory).Client = &fake.RESTClient{
+		NegotiatedSerializer: unstructuredSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			p, m := req.URL.Path, req.Method
+			t.Logf("got request %s %s", p, m)
+			switch {
+			case p == "/api/v1/namespaces/default/pods/otter" && m == "GET":
+				return newResponse(200, &podList.Items[1])
+			case p == "/api/v1/namespaces/default/pods/squid" && m == "GET":
+				return newResponse(200, &podList.Items[2])
+			default:
+				t.Fatalf("unexpected request: %s %s", req.Method, req.URL.Path)
+				return nil, nil
+			}
+		}),
+	}
+	resources, err := c.Build(objBody(&podList), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	err = c.Wait(resources, time.Second*30)
+	if err == nil || !strings.Contains(err.Error(), `pods/otter in namespace "default" was not ready within 1s`) {
+		t.Errorf("expected otter to time out, got %v", err)
+	}
+	if time.Since(start) > time.Second*10 {
+		t.Errorf("expected the wait to end after the timeout of otter, but it took %s", time.Since(start))
+	}
+
+	podList.Items[1].Annotations[annotations.NoWait] = "true"
+	resources, err = c.Build(objBody(&podList), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.WaitWithJobs(resources, time.Second*30); err != nil {
+		t.Errorf("expected wait without error, got %s", err)
+	}
+
+	podList.Items[1].Annotations = map[string]string{annotations.WaitTimeout: "soon"}
+	resources, err = c.Build(objBody(&podList), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Wait(resources, time.Second*30); err == nil || !strings.Contains(err.Error(), "invalid helm.sh/wait-timeout annotation") {
+		t.Errorf("expected an invalid annotation error, got %v", err)
+	}
+}
+
 func TestWaitDelete(t *testing.T) {
 	pod := newPod("starfish")
 
@@ -862,3 +1085,85 @@ spec:
 
 var resourceQuotaConflict = []byte(`
 {"kind":"Status","apiVersion":"v1","metadata":{},"status":"Failure","message":"Operation cannot be fulfilled on resourcequotas \"quota\": the object has been modified; please apply your changes to the latest version and try again","reason":"Conflict","details":{"name":"quota","kind":"resourcequotas"},"code":409}`)
+
+func TestIdentity(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost || req.URL.Path != "/apis/authentication.k8s.io/v1/selfsubjectreviews" {
+			t.Errorf("unexpected request: %s %s", req.Method, req.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", runtime.ContentTypeJSON)
+		io.WriteString(w, `{"kind":"SelfSubjectReview","apiVersion":"authentication.k8s.io/v1","status":{"userInfo":{"username":"system:serviceaccount:ci:deployer"}}}`)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t)
+	tf := c.Factory.(*cmdtesting.TestFactory)
+	tf.Client = &fake.RESTClient{}
+	tf.ClientConfigVal = &rest.Config{Host: srv.URL}
+
+	name, err := c.Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "system:serviceaccount:ci:deployer" {
+		t.Errorf("expected the service account, got %q", name)
+	}
+}
+
+func TestPodOutput(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", runtime.ContentTypeJSON)
+		switch req.URL.Path {
+		case "/apis/batch/v1/namespaces/default/jobs/migrate":
+			io.WriteString(w, `{"kind":"Job","apiVersion":"batch/v1","metadata":{"name":"migrate"},"spec":{"selector":{"matchLabels":{"controller-uid":"1234"}}}}`)
+		case "/api/v1/namespaces/default/pods":
+			if selector := req.URL.Query().Get("labelSelector"); selector != "controller-uid=1234" {
+				t.Errorf("unexpected pod selector %q", selector)
+			}
+			io.WriteString(w, `{"kind":"PodList","apiVersion":"v1","items":[
+{"metadata":{"name":"migrate-old","creationTimestamp":"2024-01-01T00:00:00Z"}},
+{"metadata":{"name":"migrate-new","creationTimestamp":"2024-01-01T00:01:00Z"},"status":{"containerStatuses":[
+{"name":"sidecar","state":{"terminated":{"exitCode":0}}},
+{"name":"migrate","state":{"terminated":{"exitCode":3}}}]}}]}`)
+		case "/api/v1/namespaces/default/pods/migrate-new/log":
+			if container, tail := req.URL.Query().Get("container"), req.URL.Query().Get("tailLines"); container != "migrate" || tail != "20" {
+				t.Errorf("unexpected logs query %q", req.URL.RawQuery)
+			}
+			w.Header().Set("Content-Type", "text/plain")
+			io.WriteString(w, "migration failed\n")
+		default:
+			t.Errorf("unexpected request: %s %s", req.Method, req.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t)
+	tf := c.Factory.(*cmdtesting.TestFactory)
+	tf.Client = &fake.RESTClient{}
+	tf.ClientConfigVal = &rest.Config{Host: srv.URL}
+
+	job := &resource.Info{
+		Name:      "migrate",
+		Namespace: "default",
+		Mapping:   &meta.RESTMapping{GroupVersionKind: schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"}},
+	}
+	out, err := c.PodOutput(job, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Pod != "migrate-new" || out.Container != "migrate" || out.ExitCode == nil || *out.ExitCode != 3 || out.Logs != "migration failed\n" {
+		t.Errorf("unexpected output of the job: %+v", out)
+	}
+
+	configMap := &resource.Info{
+		Name:      "settings",
+		Namespace: "default",
+		Mapping:   &meta.RESTMapping{GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}},
+	}
+	if out, err := c.PodOutput(configMap, 20); out != nil || err != nil {
+		t.Errorf("expected no output for a ConfigMap, got %+v, %v", out, err)
+	}
+}
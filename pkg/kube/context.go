@@ -0,0 +1,106 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v4/pkg/kube"
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// ApplyOption is a function that configures how resources are created or
+// updated.
+type ApplyOption func(*applyOptions)
+
+type applyOptions struct {
+	resourceTimeout time.Duration
+	saveConfig      bool
+	onApplied       func(action string, info *resource.Info)
+}
+
+// ResourceTimeout returns an ApplyOption that limits every request for a
+// single resource to the timeout, zero means no limit. The option is ignored
+// by the clients adapted by AdaptInterface.
+func ResourceTimeout(timeout time.Duration) ApplyOption {
+	return func(o *applyOptions) {
+		o.resourceTimeout = timeout
+	}
+}
+
+// SaveConfig returns an ApplyOption that maintains the
+// kubectl.kubernetes.io/last-applied-configuration annotation like kubectl
+// apply: the configuration of every resource is recorded in the annotation,
+// and the configuration recorded in the annotation of a live resource is the
+// original configuration of the three-way merge updating it. Helm and kubectl
+// apply then patch the resources consistently. The option is ignored by the
+// clients adapted by AdaptInterface.
+func SaveConfig() ApplyOption {
+	return func(o *applyOptions) {
+		o.saveConfig = true
+	}
+}
+
+// OnApplied returns an ApplyOption that calls fn as soon as a resource has
+// been created, updated or deleted, with the action "created", "updated" or
+// "deleted". fn is never called concurrently, even though resources may be
+// created in parallel. The clients adapted by AdaptInterface call fn for the
+// resources of the result once the call returns, see ReportApplied.
+func OnApplied(fn func(action string, info *resource.Info)) ApplyOption {
+	var mu sync.Mutex
+	return func(o *applyOptions) {
+		o.onApplied = func(action string, info *resource.Info) {
+			mu.Lock()
+			defer mu.Unlock()
+			fn(action, info)
+		}
+	}
+}
+
+// applied calls the OnApplied function, if any.
+func (o applyOptions) applied(action string, info *resource.Info) {
+	if o.onApplied != nil {
+		o.onApplied(action, info)
+	}
+}
+
+// ReportApplied calls the OnApplied function of the options for every
+// resource of the result. It is meant for the clients that cannot report the
+// resources as they are applied.
+func ReportApplied(result *Result, opts ...ApplyOption) {
+	o := newApplyOptions(opts)
+	if o.onApplied == nil || result == nil {
+		return
+	}
+	for _, info := range result.Created {
+		o.onApplied("created", info)
+	}
+	for _, info := range result.Updated {
+		o.onApplied("updated", info)
+	}
+	for _, info := range result.Deleted {
+		o.onApplied("deleted", info)
+	}
+}
+
+func newApplyOptions(opts []ApplyOption) applyOptions {
+	var o applyOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
@@ -0,0 +1,85 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v4/pkg/kube"
+
+import (
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// DryRunResult is the outcome of the server-side dry run of a resource.
+type DryRunResult struct {
+	// Info is the resource that was sent to the cluster.
+	Info *resource.Info
+	// Operation is "create" for a resource missing from the cluster, and
+	// "update" otherwise.
+	Operation string
+	// Object is the object the cluster would store, after defaulting and the
+	// mutating admission webhooks. It is nil when Err is set.
+	Object runtime.Object
+	// Err is the error returned by the cluster, e.g. the rejection of a
+	// validating admission webhook.
+	Err error
+}
+
+// DryRun sends the target resources to the cluster in a server-side dry run:
+// the missing resources are created and the others are patched like Update
+// does, without persisting anything. It returns the outcome of every
+// resource, in order; the failure of a resource does not stop the others.
+func (c *Client) DryRun(original, target ResourceList) []*DryRunResult {
+	results := make([]*DryRunResult, 0, len(target))
+	for _, info := range target {
+		results = append(results, dryRunResource(original, info))
+	}
+	return results
+}
+
+func dryRunResource(original ResourceList, info *resource.Info) *DryRunResult {
+	res := &DryRunResult{Info: info, Operation: "create"}
+	helper := resource.NewHelper(info.Client, info.Mapping).WithFieldManager(getManagedFieldsManager()).DryRun(true)
+
+	live, err := helper.Get(info.Namespace, info.Name)
+	switch {
+	case apierrors.IsNotFound(err):
+		res.Object, res.Err = helper.Create(info.Namespace, true, info.Object)
+		return res
+	case err != nil:
+		res.Err = errors.Wrap(err, "could not get information about the resource")
+		return res
+	}
+
+	res.Operation = "update"
+	// Without an original object, the fields removed from the chart are
+	// kept, as they cannot be told from the fields set by others.
+	current := live
+	if o := original.Get(info); o != nil {
+		current = o.Object
+	}
+	patch, patchType, err := createPatch(info, current)
+	if err != nil {
+		res.Err = errors.Wrap(err, "failed to create patch")
+		return res
+	}
+	if patch == nil || string(patch) == "{}" {
+		res.Object = live
+		return res
+	}
+	res.Object, res.Err = helper.Patch(info.Namespace, info.Name, patchType, patch, nil)
+	return res
+}
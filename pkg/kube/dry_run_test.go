@@ -0,0 +1,100 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func TestDryRun(t *testing.T) {
+	list := newPodList("starfish", "dolphin", "whale")
+	defaulted := newPod("starfish")
+	defaulted.Spec.RestartPolicy = "Always"
+	live := newPod("dolphin")
+	live.Spec.Containers[0].Image = "abc/app:v3"
+	mutated := newPod("dolphin")
+	mutated.Labels = map[string]string{"mutated": "true"}
+
+	c := newTestClient(t)
+	c.Factory.(*cmdtesting.TestFactory).UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: unstructuredSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			p, m := req.URL.Path, req.Method
+			if m != http.MethodGet && req.URL.Query().Get("dryRun") != "All" {
+				t.Fatalf("unexpected request without dry run: %s %s", m, p)
+			}
+			switch {
+			case p == "/namespaces/default/pods/dolphin" && m == http.MethodGet:
+				return newResponse(200, &live)
+			case strings.HasPrefix(p, "/namespaces/default/pods/") && m == http.MethodGet:
+				return newResponse(404, notFoundBody())
+			case p == "/namespaces/default/pods/dolphin" && m == http.MethodPatch:
+				return newResponse(200, &mutated)
+			case p == "/namespaces/default/pods" && m == http.MethodPost:
+				body := new(strings.Builder)
+				_, _ = io.Copy(body, req.Body)
+				if strings.Contains(body.String(), "whale") {
+					return newResponse(403, &metav1.Status{
+						Status:  metav1.StatusFailure,
+						Reason:  metav1.StatusReasonForbidden,
+						Message: `admission webhook "deny.example.com" denied the request`,
+						Code:    403,
+					})
+				}
+				return newResponse(201, &defaulted)
+			default:
+				t.Fatalf("unexpected request: %s %s", m, p)
+				return nil, nil
+			}
+		}),
+	}
+
+	target, err := c.Build(objBody(&list), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := c.DryRun(nil, target)
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	for i, operation := range []string{"create", "update", "create"} {
+		if r := results[i]; r.Operation != operation {
+			t.Errorf("expected %s of %s, got %s", operation, r.Info.Name, r.Operation)
+		}
+	}
+	if r := results[0]; r.Err != nil || r.Object == nil {
+		t.Errorf("unexpected result of the created pod: %+v", r)
+	}
+	labels, err := metadataAccessor.Labels(results[1].Object)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if labels["mutated"] != "true" {
+		t.Errorf("expected the object mutated by the cluster, got labels %v", labels)
+	}
+	if r := results[2]; r.Object != nil || r.Err == nil || !strings.Contains(r.Err.Error(), "denied the request") {
+		t.Errorf("unexpected result of the rejected pod: %+v", r)
+	}
+}
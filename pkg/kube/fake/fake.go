@@ -18,7 +18,9 @@ limitations under the License.
 package fake
 
 import (
+	"context"
 	"io"
+	"slices"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
@@ -47,6 +49,20 @@ type FailingKubeClient struct {
 	BuildUnstructuredError           error
 	WaitAndGetCompletedPodPhaseError error
 	WaitDuration                     time.Duration
+	IdentityName                     string
+	IdentityError                    error
+	MissingNamespaces                []string
+	NamespaceError                   error
+	IsReachableError                 error
+	DryRunError                      error
+}
+
+// IsReachable returns the configured error if set or prints
+func (f *FailingKubeClient) IsReachable() error {
+	if f.IsReachableError != nil {
+		return f.IsReachableError
+	}
+	return f.PrintingKubeClient.IsReachable()
 }
 
 // Create returns the configured error if set or prints
@@ -149,6 +165,79 @@ func (f *FailingKubeClient) DeleteWithPropagationPolicy(resources kube.ResourceL
 	return f.PrintingKubeClient.DeleteWithPropagationPolicy(resources, policy)
 }
 
+// Identity returns the configured error if set or the configured identity
+func (f *FailingKubeClient) Identity() (string, error) {
+	if f.IdentityError != nil {
+		return "", f.IdentityError
+	}
+	return f.IdentityName, nil
+}
+
+// NamespaceExists returns the configured error if set or reports that the
+// namespace exists unless it is one of MissingNamespaces
+func (f *FailingKubeClient) NamespaceExists(name string) (bool, error) {
+	if f.NamespaceError != nil {
+		return false, f.NamespaceError
+	}
+	return !slices.Contains(f.MissingNamespaces, name), nil
+}
+
+// DryRun returns the configured error as the outcome of every resource if
+// set or delegates to PrintingKubeClient
+func (f *FailingKubeClient) DryRun(original, target kube.ResourceList) []*kube.DryRunResult {
+	results := f.PrintingKubeClient.DryRun(original, target)
+	if f.DryRunError != nil {
+		for _, r := range results {
+			r.Object, r.Err = nil, f.DryRunError
+		}
+	}
+	return results
+}
+
+// CreateContext returns the configured error if set or prints, unless ctx
+// is done
+func (f *FailingKubeClient) CreateContext(ctx context.Context, resources kube.ResourceList, opts ...kube.ApplyOption) (*kube.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return &kube.Result{}, err
+	}
+	result, err := f.Create(resources)
+	if err == nil {
+		kube.ReportApplied(result, opts...)
+	}
+	return result, err
+}
+
+// UpdateContext returns the configured error if set or prints, unless ctx
+// is done
+func (f *FailingKubeClient) UpdateContext(ctx context.Context, original, target kube.ResourceList, force bool, opts ...kube.ApplyOption) (*kube.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return &kube.Result{}, err
+	}
+	result, err := f.Update(original, target, force)
+	if err == nil {
+		kube.ReportApplied(result, opts...)
+	}
+	return result, err
+}
+
+// DeleteContext returns the configured error if set or prints, unless ctx
+// is done
+func (f *FailingKubeClient) DeleteContext(ctx context.Context, resources kube.ResourceList) (*kube.Result, []error) {
+	if err := ctx.Err(); err != nil {
+		return nil, []error{err}
+	}
+	return f.Delete(resources)
+}
+
+// BuildContext returns the configured error if set or prints, unless ctx is
+// done
+func (f *FailingKubeClient) BuildContext(ctx context.Context, reader io.Reader, validate bool) (kube.ResourceList, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return f.Build(reader, validate)
+}
+
 func createDummyResourceList() kube.ResourceList {
 	var resInfo resource.Info
 	resInfo.Name = "dummyName"
@@ -17,6 +17,7 @@ limitations under the License.
 package fake
 
 import (
+	"context"
 	"io"
 	"strings"
 	"time"
@@ -127,6 +128,91 @@ func (p *PrintingKubeClient) DeleteWithPropagationPolicy(resources kube.Resource
 	return &kube.Result{Deleted: resources}, nil
 }
 
+// DryRun implements KubeClient DryRun.
+//
+// It reports every resource as created, unchanged by the cluster.
+func (p *PrintingKubeClient) DryRun(_, target kube.ResourceList) []*kube.DryRunResult {
+	results := make([]*kube.DryRunResult, 0, len(target))
+	for _, info := range target {
+		results = append(results, &kube.DryRunResult{Info: info, Operation: "create", Object: info.Object})
+	}
+	return results
+}
+
+// CreateContext implements KubeClient CreateContext.
+//
+// It prints the resources like Create, unless ctx is done.
+func (p *PrintingKubeClient) CreateContext(ctx context.Context, resources kube.ResourceList, opts ...kube.ApplyOption) (*kube.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return &kube.Result{}, err
+	}
+	result, err := p.Create(resources)
+	if err == nil {
+		kube.ReportApplied(result, opts...)
+	}
+	return result, err
+}
+
+// UpdateContext implements KubeClient UpdateContext.
+//
+// It prints the resources like Update, unless ctx is done.
+func (p *PrintingKubeClient) UpdateContext(ctx context.Context, original, target kube.ResourceList, force bool, opts ...kube.ApplyOption) (*kube.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return &kube.Result{}, err
+	}
+	result, err := p.Update(original, target, force)
+	if err == nil {
+		kube.ReportApplied(result, opts...)
+	}
+	return result, err
+}
+
+// DeleteContext implements KubeClient DeleteContext.
+//
+// It prints the resources like Delete, unless ctx is done.
+func (p *PrintingKubeClient) DeleteContext(ctx context.Context, resources kube.ResourceList) (*kube.Result, []error) {
+	if err := ctx.Err(); err != nil {
+		return nil, []error{err}
+	}
+	return p.Delete(resources)
+}
+
+// BuildContext implements KubeClient BuildContext.
+func (p *PrintingKubeClient) BuildContext(ctx context.Context, reader io.Reader, validate bool) (kube.ResourceList, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return p.Build(reader, validate)
+}
+
+// Identity implements KubeClient Identity.
+//
+// It reports no identity.
+func (p *PrintingKubeClient) Identity() (string, error) {
+	return "", nil
+}
+
+// NamespaceExists implements KubeClient NamespaceExists.
+//
+// It reports that every namespace exists.
+func (p *PrintingKubeClient) NamespaceExists(_ string) (bool, error) {
+	return true, nil
+}
+
+// PodOutput implements KubeClient PodOutput.
+//
+// It reports no pod for every resource.
+func (p *PrintingKubeClient) PodOutput(_ *resource.Info, _ int64) (*kube.PodOutput, error) {
+	return nil, nil
+}
+
+// Select implements KubeClient Select.
+//
+// It selects no resources.
+func (p *PrintingKubeClient) Select(_ []string, _ string) (kube.ResourceList, error) {
+	return nil, nil
+}
+
 func bufferize(resources kube.ResourceList) io.Reader {
 	var builder strings.Builder
 	for _, info := range resources {
@@ -0,0 +1,55 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v4/pkg/kube"
+
+import (
+	"context"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Identity returns the name of the user or service account the client
+// authenticates as, e.g. "system:serviceaccount:ci:deployer".
+//
+// The identity is reported by the cluster with a SelfSubjectReview. Clusters
+// that do not serve SelfSubjectReviews, before Kubernetes 1.28, or that do not
+// allow them, fall back to the user configured in the kubeconfig, which is
+// empty for most authentication methods.
+func (c *Client) Identity() (string, error) {
+	client, err := c.getKubeClient()
+	if err != nil {
+		return "", err
+	}
+	review, err := client.AuthenticationV1().SelfSubjectReviews().Create(context.Background(), &authenticationv1.SelfSubjectReview{}, metav1.CreateOptions{})
+	if err == nil {
+		return review.Status.UserInfo.Username, nil
+	}
+	if !apierrors.IsNotFound(err) && !apierrors.IsForbidden(err) {
+		return "", err
+	}
+
+	config, cerr := c.Factory.ToRawKubeConfigLoader().ClientConfig()
+	if cerr != nil {
+		return "", err
+	}
+	if config.Impersonate.UserName != "" {
+		return config.Impersonate.UserName, nil
+	}
+	return config.Username, nil
+}
@@ -0,0 +1,166 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v4/pkg/kube"
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Impersonation is an identity that the Kubernetes clients act as, e.g. the
+// identity of a tenant that a platform service installs releases for. The
+// authenticated user must be allowed to impersonate it.
+type Impersonation struct {
+	// UserName is the user to impersonate.
+	UserName string
+	// UID is the UID of the user to impersonate.
+	UID string
+	// Groups are the groups to impersonate.
+	Groups []string
+	// Extra are the extra fields of the user to impersonate.
+	Extra map[string][]string
+}
+
+// ServiceAccountImpersonation returns the Impersonation of a service account,
+// with the groups the API server gives to service accounts.
+func ServiceAccountImpersonation(namespace, name string) Impersonation {
+	return Impersonation{
+		UserName: fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name),
+		Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:" + namespace},
+	}
+}
+
+// ParseServiceAccount parses a service account written as
+// "<namespace>:<name>".
+func ParseServiceAccount(s string) (namespace, name string, err error) {
+	namespace, name, ok := strings.Cut(s, ":")
+	if !ok || len(validation.IsDNS1123Label(namespace)) > 0 || len(validation.IsDNS1123Subdomain(name)) > 0 {
+		return "", "", fmt.Errorf("invalid service account %q, expected <namespace>:<name>", s)
+	}
+	return namespace, name, nil
+}
+
+// IsZero reports whether the Impersonation impersonates no one.
+func (i Impersonation) IsZero() bool {
+	return i.UserName == "" && i.UID == "" && len(i.Groups) == 0 && len(i.Extra) == 0
+}
+
+// String returns a description of the identity, e.g.
+// "system:serviceaccount:tenant-a:deployer".
+func (i Impersonation) String() string {
+	var parts []string
+	if i.UserName != "" {
+		parts = append(parts, i.UserName)
+	}
+	if i.UID != "" {
+		parts = append(parts, "uid="+i.UID)
+	}
+	if len(i.Groups) > 0 {
+		parts = append(parts, "groups="+strings.Join(i.Groups, ","))
+	}
+	keys := make([]string, 0, len(i.Extra))
+	for k := range i.Extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		parts = append(parts, k+"="+strings.Join(i.Extra[k], ","))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Apply returns a copy of the REST config that impersonates the identity,
+// replacing any impersonation of the config. The config is returned as is
+// when the Impersonation is zero.
+func (i Impersonation) Apply(config *rest.Config) *rest.Config {
+	if i.IsZero() {
+		return config
+	}
+	config = rest.CopyConfig(config)
+	config.Impersonate = rest.ImpersonationConfig{
+		UserName: i.UserName,
+		UID:      i.UID,
+		Groups:   i.Groups,
+		Extra:    i.Extra,
+	}
+	return config
+}
+
+// NewImpersonatingRESTClientGetter returns a RESTClientGetter whose clients
+// impersonate the identity.
+//
+// The discovery client and the REST mapper of the getter are shared, so that
+// several impersonating getters of a cluster share its discovery cache.
+// Discovery is thus performed with the authenticated identity.
+func NewImpersonatingRESTClientGetter(getter genericclioptions.RESTClientGetter, as Impersonation) genericclioptions.RESTClientGetter {
+	if as.IsZero() {
+		return getter
+	}
+	return &impersonatingRESTClientGetter{RESTClientGetter: getter, as: as}
+}
+
+type impersonatingRESTClientGetter struct {
+	genericclioptions.RESTClientGetter
+	as Impersonation
+}
+
+func (g *impersonatingRESTClientGetter) ToRESTConfig() (*rest.Config, error) {
+	config, err := g.RESTClientGetter.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	return g.as.Apply(config), nil
+}
+
+func (g *impersonatingRESTClientGetter) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	return &impersonatingClientConfig{loader: g.RESTClientGetter.ToRawKubeConfigLoader(), as: g.as}
+}
+
+// impersonatingClientConfig is the kubeconfig loader of an impersonating
+// getter, whose client config is used by the clients built by kubectl's
+// factory.
+type impersonatingClientConfig struct {
+	loader clientcmd.ClientConfig
+	as     Impersonation
+}
+
+func (c *impersonatingClientConfig) RawConfig() (clientcmdapi.Config, error) {
+	return c.loader.RawConfig()
+}
+
+func (c *impersonatingClientConfig) Namespace() (string, bool, error) {
+	return c.loader.Namespace()
+}
+
+func (c *impersonatingClientConfig) ConfigAccess() clientcmd.ConfigAccess {
+	return c.loader.ConfigAccess()
+}
+
+func (c *impersonatingClientConfig) ClientConfig() (*rest.Config, error) {
+	config, err := c.loader.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	return c.as.Apply(config), nil
+}
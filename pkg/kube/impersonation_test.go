@@ -0,0 +1,119 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+)
+
+func TestParseServiceAccount(t *testing.T) {
+	namespace, name, err := ParseServiceAccount("tenant-a:deployer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if namespace != "tenant-a" || name != "deployer" {
+		t.Errorf("expected tenant-a and deployer, got %q and %q", namespace, name)
+	}
+
+	for _, s := range []string{"deployer", "tenant-a:", ":deployer", "Tenant:deployer"} {
+		if _, _, err := ParseServiceAccount(s); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+}
+
+func TestImpersonationApply(t *testing.T) {
+	config := &rest.Config{Host: "https://example.com"}
+	if got := (Impersonation{}).Apply(config); got != config {
+		t.Error("expected the config to be returned as is")
+	}
+
+	as := ServiceAccountImpersonation("tenant-a", "deployer")
+	got := as.Apply(config)
+	want := rest.ImpersonationConfig{
+		UserName: "system:serviceaccount:tenant-a:deployer",
+		Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:tenant-a"},
+	}
+	if !reflect.DeepEqual(got.Impersonate, want) {
+		t.Errorf("expected %+v, got %+v", want, got.Impersonate)
+	}
+	if config.Impersonate.UserName != "" {
+		t.Error("expected the config not to be modified")
+	}
+	if s := as.String(); s != "system:serviceaccount:tenant-a:deployer groups=system:serviceaccounts,system:serviceaccounts:tenant-a" {
+		t.Errorf("unexpected description %q", s)
+	}
+}
+
+const impersonationKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: east
+  cluster:
+    server: https://east.example.com
+contexts:
+- name: east
+  context:
+    cluster: east
+    namespace: shop
+current-context: east
+users: []
+`
+
+func TestNewImpersonatingRESTClientGetter(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(kubeconfig, []byte(impersonationKubeConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	flags := genericclioptions.NewConfigFlags(false)
+	flags.KubeConfig = &kubeconfig
+
+	if getter := NewImpersonatingRESTClientGetter(flags, Impersonation{}); getter != flags {
+		t.Error("expected the getter to be returned as is")
+	}
+
+	getter := NewImpersonatingRESTClientGetter(flags, Impersonation{UserName: "jane", Groups: []string{"tenants"}})
+	config, err := getter.ToRESTConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Host != "https://east.example.com" || config.Impersonate.UserName != "jane" {
+		t.Errorf("expected the east cluster impersonating jane, got %q and %q", config.Host, config.Impersonate.UserName)
+	}
+
+	loader := getter.ToRawKubeConfigLoader()
+	config, err = loader.ClientConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(config.Impersonate.Groups, []string{"tenants"}) {
+		t.Errorf("expected the client config to impersonate the tenants group, got %v", config.Impersonate.Groups)
+	}
+	namespace, _, err := loader.Namespace()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if namespace != "shop" {
+		t.Errorf("expected the namespace of the context, got %q", namespace)
+	}
+}
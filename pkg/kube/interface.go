@@ -17,18 +17,63 @@ limitations under the License.
 package kube
 
 import (
+	"context"
 	"io"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/resource"
 )
 
 // Interface represents a client capable of communicating with the Kubernetes API.
 //
-// A KubernetesClient must be concurrency safe.
+// A KubernetesClient must be concurrency safe. A client written for an earlier
+// version of Interface can be adapted with AdaptInterface.
 type Interface interface {
+	LegacyInterface
+
+	// CreateContext creates one or more resources. No request is made once ctx
+	// is done, and requests in flight are limited to the deadline of ctx.
+	CreateContext(ctx context.Context, resources ResourceList, opts ...ApplyOption) (*Result, error)
+
+	// UpdateContext updates one or more resources or creates the resource
+	// if it doesn't exist. ctx cancels the update like CreateContext.
+	UpdateContext(ctx context.Context, original, target ResourceList, force bool, opts ...ApplyOption) (*Result, error)
+
+	// DeleteContext destroys one or more resources. ctx cancels the deletion
+	// like CreateContext.
+	DeleteContext(ctx context.Context, resources ResourceList) (*Result, []error)
+
+	// BuildContext creates a resource list from a Reader, see Build. It fails
+	// when ctx is done.
+	BuildContext(ctx context.Context, reader io.Reader, validate bool) (ResourceList, error)
+
+	// Identity returns the name of the user or service account the client
+	// authenticates as.
+	Identity() (string, error)
+
+	// NamespaceExists reports whether the namespace exists in the cluster.
+	NamespaceExists(name string) (bool, error)
+
+	// PodOutput returns the exit code and the last lines of the logs of the
+	// pod run by a Pod or a Job resource, nil for the other resources.
+	PodOutput(info *resource.Info, tailLines int64) (*PodOutput, error)
+
+	// DryRun sends the target resources to the cluster in a server-side dry
+	// run and returns the objects the cluster would store.
+	DryRun(original, target ResourceList) []*DryRunResult
+
+	// Select returns the live resources of the kinds that match the label
+	// selector.
+	Select(kinds []string, selector string) (ResourceList, error)
+}
+
+// LegacyInterface is the Interface of the clients written before the methods
+// accepting a context, Identity, NamespaceExists, PodOutput, DryRun and Select
+// were added to it. Use AdaptInterface to use such a client as an Interface.
+type LegacyInterface interface {
 	// Create creates one or more resources.
 	Create(resources ResourceList) (*Result, error)
 
@@ -0,0 +1,50 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v4/pkg/kube"
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/kubectl/pkg/util"
+)
+
+// saveConfig records the configuration of the resource in its
+// kubectl.kubernetes.io/last-applied-configuration annotation, in the format
+// of kubectl apply.
+func saveConfig(info *resource.Info) error {
+	if err := util.CreateApplyAnnotation(info.Object, unstructured.UnstructuredJSONScheme); err != nil {
+		return errors.Wrapf(err, "unable to save the configuration of %q", info.Name)
+	}
+	return nil
+}
+
+// lastAppliedConfig returns the configuration recorded in the
+// kubectl.kubernetes.io/last-applied-configuration annotation of the live
+// object, or nil when it has none.
+func lastAppliedConfig(live runtime.Object) (runtime.Object, error) {
+	data, err := util.GetOriginalConfiguration(live)
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(data); err != nil {
+		return nil, errors.Wrap(err, "invalid last applied configuration")
+	}
+	return obj, nil
+}
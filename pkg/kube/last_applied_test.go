@@ -0,0 +1,97 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func TestUpdateSaveConfig(t *testing.T) {
+	list := newPodList("starfish", "dolphin")
+	// kubectl apply added a label to the pod installed by Helm.
+	applied := newPod("starfish")
+	applied.Labels = map[string]string{"tier": "web"}
+	live := newPod("starfish")
+	live.Labels = applied.Labels
+	live.Annotations = map[string]string{v1.LastAppliedConfigAnnotation: runtime.EncodeOrDie(codec, &applied)}
+
+	var patch, created map[string]interface{}
+	c := newTestClient(t)
+	c.Factory.(*cmdtesting.TestFactory).UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: unstructuredSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			p, m := req.URL.Path, req.Method
+			switch {
+			case p == "/namespaces/default/pods/starfish" && m == http.MethodGet:
+				return newResponse(200, &live)
+			case p == "/namespaces/default/pods/dolphin" && m == http.MethodGet:
+				return newResponse(404, notFoundBody())
+			case p == "/namespaces/default/pods/starfish" && m == http.MethodPatch:
+				data, _ := io.ReadAll(req.Body)
+				if err := json.Unmarshal(data, &patch); err != nil {
+					t.Fatal(err)
+				}
+				return newResponse(200, &list.Items[0])
+			case p == "/namespaces/default/pods" && m == http.MethodPost:
+				data, _ := io.ReadAll(req.Body)
+				if err := json.Unmarshal(data, &created); err != nil {
+					t.Fatal(err)
+				}
+				return newResponse(201, &list.Items[1])
+			default:
+				t.Fatalf("unexpected request: %s %s", m, p)
+				return nil, nil
+			}
+		}),
+	}
+	original, err := c.Build(objBody(&v1.PodList{Items: list.Items[:1]}), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := c.Build(objBody(&list), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.UpdateContext(context.Background(), original, target, false, SaveConfig()); err != nil {
+		t.Fatal(err)
+	}
+
+	metadata, _ := patch["metadata"].(map[string]interface{})
+	// The target has no labels left, so the patch removes them all.
+	if labels, ok := metadata["labels"]; !ok || labels != nil {
+		t.Errorf("expected the label applied by kubectl to be removed, got patch %v", patch)
+	}
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+	if lastApplied, _ := annotations[v1.LastAppliedConfigAnnotation].(string); lastApplied == "" {
+		t.Errorf("expected the last applied configuration to be updated, got patch %v", patch)
+	}
+	metadata, _ = created["metadata"].(map[string]interface{})
+	annotations, _ = metadata["annotations"].(map[string]interface{})
+	if _, ok := annotations[v1.LastAppliedConfigAnnotation]; !ok {
+		t.Errorf("expected the configuration of the created pod to be saved, got %v", created)
+	}
+}
@@ -0,0 +1,194 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v4/pkg/kube"
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// ErrNotSupported is returned by the clients adapted by AdaptInterface for the
+// methods the adapted client does not implement.
+var ErrNotSupported = errors.New("not supported by the Kubernetes client")
+
+// AdaptInterface returns the client if it implements Interface, or an adapter
+// otherwise. The adapter passes the calls on to the methods the client
+// implements. For the methods it does not implement:
+//
+//   - CreateContext, UpdateContext, DeleteContext and BuildContext check ctx
+//     before the call is passed on to Create, Update, Delete and Build.
+//     Requests in flight cannot be cancelled, and the apply options are
+//     ignored, except OnApplied which is called once the call returns.
+//   - Identity, NamespaceExists, PodOutput and Select return ErrNotSupported,
+//     and DryRun reports ErrNotSupported for every resource.
+//
+// The adapter implements InterfaceExt, InterfaceDeletionPropagation and
+// InterfaceResources as well. When the client does not implement them,
+// WaitForDelete returns at once, DeleteWithPropagationPolicy works like Delete,
+// and Get and BuildTable return ErrNotSupported.
+func AdaptInterface(c LegacyInterface) Interface {
+	if i, ok := c.(Interface); ok {
+		return i
+	}
+	return &legacyAdapter{c}
+}
+
+// legacyAdapter adapts a LegacyInterface to Interface.
+type legacyAdapter struct {
+	LegacyInterface
+}
+
+var _ InterfaceExt = (*legacyAdapter)(nil)
+var _ InterfaceDeletionPropagation = (*legacyAdapter)(nil)
+var _ InterfaceResources = (*legacyAdapter)(nil)
+
+func (a *legacyAdapter) CreateContext(ctx context.Context, resources ResourceList, opts ...ApplyOption) (*Result, error) {
+	if c, ok := a.LegacyInterface.(interface {
+		CreateContext(context.Context, ResourceList, ...ApplyOption) (*Result, error)
+	}); ok {
+		return c.CreateContext(ctx, resources, opts...)
+	}
+	if err := ctx.Err(); err != nil {
+		return &Result{}, err
+	}
+	result, err := a.Create(resources)
+	if err == nil {
+		ReportApplied(result, opts...)
+	}
+	return result, err
+}
+
+func (a *legacyAdapter) UpdateContext(ctx context.Context, original, target ResourceList, force bool, opts ...ApplyOption) (*Result, error) {
+	if c, ok := a.LegacyInterface.(interface {
+		UpdateContext(context.Context, ResourceList, ResourceList, bool, ...ApplyOption) (*Result, error)
+	}); ok {
+		return c.UpdateContext(ctx, original, target, force, opts...)
+	}
+	if err := ctx.Err(); err != nil {
+		return &Result{}, err
+	}
+	result, err := a.Update(original, target, force)
+	if err == nil {
+		ReportApplied(result, opts...)
+	}
+	return result, err
+}
+
+func (a *legacyAdapter) DeleteContext(ctx context.Context, resources ResourceList) (*Result, []error) {
+	if c, ok := a.LegacyInterface.(interface {
+		DeleteContext(context.Context, ResourceList) (*Result, []error)
+	}); ok {
+		return c.DeleteContext(ctx, resources)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, []error{err}
+	}
+	return a.Delete(resources)
+}
+
+func (a *legacyAdapter) BuildContext(ctx context.Context, reader io.Reader, validate bool) (ResourceList, error) {
+	if c, ok := a.LegacyInterface.(interface {
+		BuildContext(context.Context, io.Reader, bool) (ResourceList, error)
+	}); ok {
+		return c.BuildContext(ctx, reader, validate)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.Build(reader, validate)
+}
+
+func (a *legacyAdapter) Identity() (string, error) {
+	if c, ok := a.LegacyInterface.(interface{ Identity() (string, error) }); ok {
+		return c.Identity()
+	}
+	return "", ErrNotSupported
+}
+
+func (a *legacyAdapter) NamespaceExists(name string) (bool, error) {
+	if c, ok := a.LegacyInterface.(interface {
+		NamespaceExists(string) (bool, error)
+	}); ok {
+		return c.NamespaceExists(name)
+	}
+	return false, ErrNotSupported
+}
+
+func (a *legacyAdapter) PodOutput(info *resource.Info, tailLines int64) (*PodOutput, error) {
+	if c, ok := a.LegacyInterface.(interface {
+		PodOutput(*resource.Info, int64) (*PodOutput, error)
+	}); ok {
+		return c.PodOutput(info, tailLines)
+	}
+	return nil, ErrNotSupported
+}
+
+func (a *legacyAdapter) DryRun(original, target ResourceList) []*DryRunResult {
+	if c, ok := a.LegacyInterface.(interface {
+		DryRun(ResourceList, ResourceList) []*DryRunResult
+	}); ok {
+		return c.DryRun(original, target)
+	}
+	results := make([]*DryRunResult, 0, len(target))
+	for _, info := range target {
+		results = append(results, &DryRunResult{Info: info, Err: ErrNotSupported})
+	}
+	return results
+}
+
+func (a *legacyAdapter) Select(kinds []string, selector string) (ResourceList, error) {
+	if c, ok := a.LegacyInterface.(interface {
+		Select([]string, string) (ResourceList, error)
+	}); ok {
+		return c.Select(kinds, selector)
+	}
+	return nil, ErrNotSupported
+}
+
+func (a *legacyAdapter) WaitForDelete(resources ResourceList, timeout time.Duration) error {
+	if c, ok := a.LegacyInterface.(InterfaceExt); ok {
+		return c.WaitForDelete(resources, timeout)
+	}
+	return nil
+}
+
+func (a *legacyAdapter) DeleteWithPropagationPolicy(resources ResourceList, policy metav1.DeletionPropagation) (*Result, []error) {
+	if c, ok := a.LegacyInterface.(InterfaceDeletionPropagation); ok {
+		return c.DeleteWithPropagationPolicy(resources, policy)
+	}
+	return a.Delete(resources)
+}
+
+func (a *legacyAdapter) Get(resources ResourceList, related bool) (map[string][]runtime.Object, error) {
+	if c, ok := a.LegacyInterface.(InterfaceResources); ok {
+		return c.Get(resources, related)
+	}
+	return nil, ErrNotSupported
+}
+
+func (a *legacyAdapter) BuildTable(reader io.Reader, validate bool) (ResourceList, error) {
+	if c, ok := a.LegacyInterface.(InterfaceResources); ok {
+		return c.BuildTable(reader, validate)
+	}
+	return nil, ErrNotSupported
+}
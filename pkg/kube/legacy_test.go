@@ -0,0 +1,91 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// legacyClient only implements LegacyInterface, and Identity.
+type legacyClient struct {
+	LegacyInterface
+	calls []string
+}
+
+func (c *legacyClient) Create(_ ResourceList) (*Result, error) {
+	c.calls = append(c.calls, "create")
+	return &Result{Created: ResourceList{{Name: "otter"}}}, nil
+}
+
+func (c *legacyClient) Update(_, _ ResourceList, _ bool) (*Result, error) {
+	c.calls = append(c.calls, "update")
+	return &Result{}, nil
+}
+
+func (c *legacyClient) Identity() (string, error) {
+	return "otter", nil
+}
+
+func TestAdaptInterface(t *testing.T) {
+	if _, ok := AdaptInterface(&Client{}).(*Client); !ok {
+		t.Error("expected a Client to be used as is")
+	}
+
+	legacy := &legacyClient{}
+	c := AdaptInterface(legacy)
+	var applied []string
+	onApplied := OnApplied(func(action string, info *resource.Info) {
+		applied = append(applied, action+":"+info.Name)
+	})
+	if _, err := c.CreateContext(context.Background(), nil, onApplied); err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 1 || applied[0] != "created:otter" {
+		t.Errorf("expected the created resources to be reported, got %v", applied)
+	}
+	if _, err := c.UpdateContext(context.Background(), nil, nil, false, ResourceTimeout(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := c.CreateContext(ctx, nil)
+	if !errors.Is(err, context.Canceled) || result == nil {
+		t.Errorf("expected a result and a cancellation error, got %v, %v", result, err)
+	}
+	if _, errs := c.DeleteContext(ctx, nil); len(errs) != 1 || !errors.Is(errs[0], context.Canceled) {
+		t.Errorf("expected a cancellation error, got %v", errs)
+	}
+	if got := len(legacy.calls); got != 2 {
+		t.Errorf("expected no call once the context is cancelled, got %v", legacy.calls)
+	}
+
+	if identity, err := c.Identity(); identity != "otter" || err != nil {
+		t.Errorf("expected the identity of the client, got %q, %v", identity, err)
+	}
+	if _, err := c.Select(nil, "app=web"); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported, got %v", err)
+	}
+	if results := c.DryRun(nil, ResourceList{{Name: "otter"}}); len(results) != 1 || !errors.Is(results[0].Err, ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported for every resource, got %+v", results)
+	}
+}
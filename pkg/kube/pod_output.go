@@ -0,0 +1,112 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v4/pkg/kube"
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// PodOutput is the outcome of a pod run to completion, such as the pod of a
+// hook.
+type PodOutput struct {
+	// Pod is the name of the pod.
+	Pod string
+	// Container is the name of the container the outcome is read from: the
+	// first container that failed, or else the first that terminated.
+	Container string
+	// ExitCode is the exit code of the container, nil when no container has
+	// terminated.
+	ExitCode *int32
+	// Logs are the last lines of the logs of the container.
+	Logs string
+}
+
+// PodOutput returns the outcome of the pod run by the resource, which is
+// either a Pod or a Job, for which the latest pod is read. It returns nil for
+// the other resources and for Jobs without pods. At most tailLines lines of
+// logs are returned.
+func (c *Client) PodOutput(info *resource.Info, tailLines int64) (*PodOutput, error) {
+	client, err := c.getKubeClient()
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	pods := client.CoreV1().Pods(info.Namespace)
+
+	var pod *v1.Pod
+	switch gk := info.Mapping.GroupVersionKind.GroupKind(); gk {
+	case v1.SchemeGroupVersion.WithKind("Pod").GroupKind():
+		pod, err = pods.Get(ctx, info.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+	case batchv1.SchemeGroupVersion.WithKind("Job").GroupKind():
+		job, err := client.BatchV1().Jobs(info.Namespace).Get(ctx, info.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		selector, err := metav1.LabelSelectorAsSelector(job.Spec.Selector)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid selector of job %s", info.Name)
+		}
+		list, err := pods.List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			if pod == nil || pod.CreationTimestamp.Before(&list.Items[i].CreationTimestamp) {
+				pod = &list.Items[i]
+			}
+		}
+		if pod == nil {
+			return nil, nil
+		}
+	default:
+		return nil, nil
+	}
+
+	out := &PodOutput{Pod: pod.Name}
+	for _, status := range pod.Status.ContainerStatuses {
+		terminated := status.State.Terminated
+		if terminated == nil {
+			continue
+		}
+		if out.ExitCode == nil || (*out.ExitCode == 0 && terminated.ExitCode != 0) {
+			exitCode := terminated.ExitCode
+			out.Container, out.ExitCode = status.Name, &exitCode
+		}
+	}
+	if out.Container == "" {
+		if len(pod.Spec.Containers) == 0 {
+			return out, nil
+		}
+		out.Container = pod.Spec.Containers[0].Name
+	}
+
+	logs, err := pods.GetLogs(pod.Name, &v1.PodLogOptions{Container: out.Container, TailLines: &tailLines}).DoRaw(ctx)
+	if err != nil {
+		return out, errors.Wrapf(err, "unable to get the logs of pod %s", pod.Name)
+	}
+	out.Logs = string(logs)
+	return out, nil
+}
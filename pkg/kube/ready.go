@@ -23,12 +23,22 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	appsv1beta1 "k8s.io/api/apps/v1beta1"
 	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	policyv1 "k8s.io/api/policy/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/cli-runtime/pkg/resource"
@@ -85,7 +95,9 @@ type ReadyChecker struct {
 // IsReady checks if v is ready. It supports checking readiness for pods,
 // deployments, persistent volume claims, services, daemon sets, custom
 // resource definitions, stateful sets, replication controllers, jobs (optional),
-// and replica sets. All other resource kinds are always considered ready.
+// replica sets, ingresses, horizontal pod autoscalers, pod disruption budgets,
+// and the gateways and routes of the Gateway API. All other resource kinds are
+// always considered ready.
 //
 // IsReady will fetch the latest state of the object from the server prior to
 // performing readiness checks, and it will return any error encountered.
@@ -200,6 +212,47 @@ func (c *ReadyChecker) IsReady(ctx context.Context, v *resource.Info) (bool, err
 		if !ready || err != nil {
 			return false, err
 		}
+	case *networkingv1.Ingress, *networkingv1beta1.Ingress, *extensionsv1beta1.Ingress:
+		ing, err := c.client.NetworkingV1().Ingresses(v.Namespace).Get(ctx, v.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if !c.ingressReady(ing) {
+			return false, nil
+		}
+	case *autoscalingv1.HorizontalPodAutoscaler, *autoscalingv2.HorizontalPodAutoscaler, *autoscalingv2beta1.HorizontalPodAutoscaler, *autoscalingv2beta2.HorizontalPodAutoscaler:
+		hpa, err := c.client.AutoscalingV2().HorizontalPodAutoscalers(v.Namespace).Get(ctx, v.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if !c.horizontalPodAutoscalerReady(hpa) {
+			return false, nil
+		}
+	case *policyv1.PodDisruptionBudget, *policyv1beta1.PodDisruptionBudget:
+		pdb, err := c.client.PolicyV1().PodDisruptionBudgets(v.Namespace).Get(ctx, v.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if !c.podDisruptionBudgetReady(pdb) {
+			return false, nil
+		}
+	case *unstructured.Unstructured:
+		// The Gateway API is not part of the Kubernetes API, so its resources
+		// are only available as unstructured objects.
+		if value.GroupVersionKind().Group != gatewayAPIGroup {
+			break
+		}
+		if err := v.Get(); err != nil {
+			return false, err
+		}
+		obj, ok := v.Object.(*unstructured.Unstructured)
+		if !ok {
+			return false, fmt.Errorf("unexpected object type %T for %s/%s", v.Object, v.Namespace, v.Name)
+		}
+		ready, err := c.gatewayAPIReady(obj)
+		if !ready || err != nil {
+			return false, err
+		}
 	}
 	return true, nil
 }
@@ -455,6 +508,111 @@ func (c *ReadyChecker) replicaSetReady(rs *appsv1.ReplicaSet) bool {
 	return true
 }
 
+func (c *ReadyChecker) ingressReady(ing *networkingv1.Ingress) bool {
+	// Ingress controllers publish the address of the load balancer once the
+	// ingress is served
+	if len(ing.Status.LoadBalancer.Ingress) == 0 {
+		c.log("Ingress does not have load balancer ingress: %s/%s", ing.Namespace, ing.Name)
+		return false
+	}
+	return true
+}
+
+func (c *ReadyChecker) horizontalPodAutoscalerReady(hpa *autoscalingv2.HorizontalPodAutoscaler) bool {
+	// Verify the generation observed by the autoscaler matches the spec generation
+	if hpa.Status.ObservedGeneration == nil || *hpa.Status.ObservedGeneration != hpa.ObjectMeta.Generation {
+		c.log("HorizontalPodAutoscaler is not ready: %s/%s. spec generation (%d) has not been observed.", hpa.Namespace, hpa.Name, hpa.ObjectMeta.Generation)
+		return false
+	}
+	ableToScale := false
+	for _, cond := range hpa.Status.Conditions {
+		if cond.Type == autoscalingv2.AbleToScale && cond.Status == corev1.ConditionTrue {
+			ableToScale = true
+		}
+	}
+	if !ableToScale {
+		c.log("HorizontalPodAutoscaler is not able to scale: %s/%s", hpa.Namespace, hpa.Name)
+		return false
+	}
+	// The autoscaler cannot scale on metrics it cannot read, e.g. when the
+	// metrics server is missing
+	if len(hpa.Status.CurrentMetrics) == 0 {
+		c.log("HorizontalPodAutoscaler does not have current metrics: %s/%s", hpa.Namespace, hpa.Name)
+		return false
+	}
+	return true
+}
+
+func (c *ReadyChecker) podDisruptionBudgetReady(pdb *policyv1.PodDisruptionBudget) bool {
+	// Verify the generation observed by the disruption controller matches the spec generation
+	if pdb.Status.ObservedGeneration != pdb.ObjectMeta.Generation {
+		c.log("PodDisruptionBudget is not ready: %s/%s. observedGeneration (%d) does not match spec generation (%d).", pdb.Namespace, pdb.Name, pdb.Status.ObservedGeneration, pdb.ObjectMeta.Generation)
+		return false
+	}
+	if pdb.Status.CurrentHealthy < pdb.Status.DesiredHealthy {
+		c.log("PodDisruptionBudget is not ready: %s/%s. %d out of %d expected pods are healthy", pdb.Namespace, pdb.Name, pdb.Status.CurrentHealthy, pdb.Status.DesiredHealthy)
+		return false
+	}
+	return true
+}
+
+// gatewayAPIGroup is the API group of the Gateway API.
+const gatewayAPIGroup = "gateway.networking.k8s.io"
+
+// gatewayAPIStatus is the part of the status of the Gateway API resources that
+// their readiness depends on.
+type gatewayAPIStatus struct {
+	Conditions []metav1.Condition `json:"conditions"`
+	Parents    []struct {
+		Conditions []metav1.Condition `json:"conditions"`
+	} `json:"parents"`
+}
+
+// gatewayAPIReady checks the readiness of Gateway API resources. Gateway
+// classes must be accepted, gateways must be accepted and programmed, and
+// routes must be accepted by all of their parents. Other kinds of the Gateway
+// API are always considered ready.
+func (c *ReadyChecker) gatewayAPIReady(obj *unstructured.Unstructured) (bool, error) {
+	var status gatewayAPIStatus
+	if raw, ok := obj.Object["status"].(map[string]interface{}); ok {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &status); err != nil {
+			return false, err
+		}
+	}
+
+	kind, generation := obj.GetKind(), obj.GetGeneration()
+	switch kind {
+	case "GatewayClass":
+		return c.gatewayConditionsTrue(obj, status.Conditions, generation, "Accepted"), nil
+	case "Gateway":
+		return c.gatewayConditionsTrue(obj, status.Conditions, generation, "Accepted", "Programmed"), nil
+	case "HTTPRoute", "GRPCRoute", "TLSRoute", "TCPRoute", "UDPRoute":
+		if len(status.Parents) == 0 {
+			c.log("%s has not been accepted by a parent yet: %s/%s", kind, obj.GetNamespace(), obj.GetName())
+			return false, nil
+		}
+		for _, parent := range status.Parents {
+			if !c.gatewayConditionsTrue(obj, parent.Conditions, generation, "Accepted") {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// gatewayConditionsTrue reports whether the conditions of the given types are
+// true for the current generation of the object.
+func (c *ReadyChecker) gatewayConditionsTrue(obj *unstructured.Unstructured, conditions []metav1.Condition, generation int64, types ...string) bool {
+	for _, t := range types {
+		cond := meta.FindStatusCondition(conditions, t)
+		if cond == nil || cond.Status != metav1.ConditionTrue || cond.ObservedGeneration < generation {
+			c.log("%s is not ready: %s/%s. condition %s is not true for generation %d", obj.GetKind(), obj.GetNamespace(), obj.GetName(), t, generation)
+			return false
+		}
+	}
+	return true
+}
+
 func getPods(ctx context.Context, client kubernetes.Interface, namespace, selector string) ([]corev1.Pod, error) {
 	list, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: selector,
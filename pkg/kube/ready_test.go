@@ -21,12 +21,18 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/cli-runtime/pkg/resource"
@@ -1361,6 +1367,282 @@ func Test_ReadyChecker_crdReady(t *testing.T) {
 	}
 }
 
+func Test_ReadyChecker_IsReady_Ingress(t *testing.T) {
+	tests := []struct {
+		name    string
+		ing     *networkingv1.Ingress
+		info    *resource.Info
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "IsReady Ingress",
+			ing:  newIngress("foo", []networkingv1.IngressLoadBalancerIngress{{IP: "10.0.0.1"}}),
+			info: &resource.Info{Object: &networkingv1.Ingress{}, Name: "foo", Namespace: defaultNamespace},
+			want: true,
+		},
+		{
+			name: "IsReady Ingress without load balancer",
+			ing:  newIngress("foo", nil),
+			info: &resource.Info{Object: &extensionsv1beta1.Ingress{}, Name: "foo", Namespace: defaultNamespace},
+			want: false,
+		},
+		{
+			name:    "IsReady Ingress with error",
+			ing:     newIngress("bar", nil),
+			info:    &resource.Info{Object: &networkingv1.Ingress{}, Name: "foo", Namespace: defaultNamespace},
+			want:    false,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewReadyChecker(fake.NewSimpleClientset(), nil)
+			if _, err := c.client.NetworkingV1().Ingresses(defaultNamespace).Create(context.TODO(), tt.ing, metav1.CreateOptions{}); err != nil {
+				t.Errorf("Failed to create Ingress error: %v", err)
+				return
+			}
+			got, err := c.IsReady(context.TODO(), tt.info)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("IsReady() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("IsReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ReadyChecker_IsReady_HorizontalPodAutoscaler(t *testing.T) {
+	tests := []struct {
+		name    string
+		hpa     *autoscalingv2.HorizontalPodAutoscaler
+		info    *resource.Info
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "IsReady HorizontalPodAutoscaler",
+			hpa:  newHorizontalPodAutoscaler("foo", corev1.ConditionTrue, true, true),
+			info: &resource.Info{Object: &autoscalingv1.HorizontalPodAutoscaler{}, Name: "foo", Namespace: defaultNamespace},
+			want: true,
+		},
+		{
+			name:    "IsReady HorizontalPodAutoscaler with error",
+			hpa:     newHorizontalPodAutoscaler("bar", corev1.ConditionTrue, true, true),
+			info:    &resource.Info{Object: &autoscalingv2.HorizontalPodAutoscaler{}, Name: "foo", Namespace: defaultNamespace},
+			want:    false,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewReadyChecker(fake.NewSimpleClientset(), nil)
+			if _, err := c.client.AutoscalingV2().HorizontalPodAutoscalers(defaultNamespace).Create(context.TODO(), tt.hpa, metav1.CreateOptions{}); err != nil {
+				t.Errorf("Failed to create HorizontalPodAutoscaler error: %v", err)
+				return
+			}
+			got, err := c.IsReady(context.TODO(), tt.info)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("IsReady() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("IsReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ReadyChecker_IsReady_PodDisruptionBudget(t *testing.T) {
+	tests := []struct {
+		name    string
+		pdb     *policyv1.PodDisruptionBudget
+		info    *resource.Info
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "IsReady PodDisruptionBudget",
+			pdb:  newPodDisruptionBudget("foo", 2, 2, true),
+			info: &resource.Info{Object: &policyv1.PodDisruptionBudget{}, Name: "foo", Namespace: defaultNamespace},
+			want: true,
+		},
+		{
+			name:    "IsReady PodDisruptionBudget with error",
+			pdb:     newPodDisruptionBudget("bar", 2, 2, true),
+			info:    &resource.Info{Object: &policyv1beta1.PodDisruptionBudget{}, Name: "foo", Namespace: defaultNamespace},
+			want:    false,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewReadyChecker(fake.NewSimpleClientset(), nil)
+			if _, err := c.client.PolicyV1().PodDisruptionBudgets(defaultNamespace).Create(context.TODO(), tt.pdb, metav1.CreateOptions{}); err != nil {
+				t.Errorf("Failed to create PodDisruptionBudget error: %v", err)
+				return
+			}
+			got, err := c.IsReady(context.TODO(), tt.info)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("IsReady() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("IsReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ReadyChecker_horizontalPodAutoscalerReady(t *testing.T) {
+	tests := []struct {
+		name string
+		hpa  *autoscalingv2.HorizontalPodAutoscaler
+		want bool
+	}{
+		{
+			name: "hpa is ready",
+			hpa:  newHorizontalPodAutoscaler("foo", corev1.ConditionTrue, true, true),
+			want: true,
+		},
+		{
+			name: "hpa is not able to scale",
+			hpa:  newHorizontalPodAutoscaler("foo", corev1.ConditionFalse, true, true),
+			want: false,
+		},
+		{
+			name: "hpa does not have current metrics",
+			hpa:  newHorizontalPodAutoscaler("foo", corev1.ConditionTrue, false, true),
+			want: false,
+		},
+		{
+			name: "hpa generation not in sync",
+			hpa:  newHorizontalPodAutoscaler("foo", corev1.ConditionTrue, true, false),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewReadyChecker(fake.NewSimpleClientset(), nil)
+			if got := c.horizontalPodAutoscalerReady(tt.hpa); got != tt.want {
+				t.Errorf("horizontalPodAutoscalerReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ReadyChecker_podDisruptionBudgetReady(t *testing.T) {
+	tests := []struct {
+		name string
+		pdb  *policyv1.PodDisruptionBudget
+		want bool
+	}{
+		{
+			name: "pdb is ready",
+			pdb:  newPodDisruptionBudget("foo", 3, 2, true),
+			want: true,
+		},
+		{
+			name: "pdb does not have the expected healthy pods",
+			pdb:  newPodDisruptionBudget("foo", 1, 2, true),
+			want: false,
+		},
+		{
+			name: "pdb generation not in sync",
+			pdb:  newPodDisruptionBudget("foo", 2, 2, false),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewReadyChecker(fake.NewSimpleClientset(), nil)
+			if got := c.podDisruptionBudgetReady(tt.pdb); got != tt.want {
+				t.Errorf("podDisruptionBudgetReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ReadyChecker_gatewayAPIReady(t *testing.T) {
+	accepted := map[string]interface{}{"type": "Accepted", "status": "True", "observedGeneration": int64(2)}
+	programmed := map[string]interface{}{"type": "Programmed", "status": "True", "observedGeneration": int64(2)}
+	stale := map[string]interface{}{"type": "Programmed", "status": "True", "observedGeneration": int64(1)}
+	rejected := map[string]interface{}{"type": "Accepted", "status": "False", "observedGeneration": int64(2)}
+
+	tests := []struct {
+		name   string
+		kind   string
+		status map[string]interface{}
+		want   bool
+	}{
+		{
+			name:   "gateway is accepted and programmed",
+			kind:   "Gateway",
+			status: map[string]interface{}{"conditions": []interface{}{accepted, programmed}},
+			want:   true,
+		},
+		{
+			name:   "gateway is not programmed",
+			kind:   "Gateway",
+			status: map[string]interface{}{"conditions": []interface{}{accepted}},
+			want:   false,
+		},
+		{
+			name:   "gateway was programmed for an older generation",
+			kind:   "Gateway",
+			status: map[string]interface{}{"conditions": []interface{}{accepted, stale}},
+			want:   false,
+		},
+		{
+			name: "route is accepted by all parents",
+			kind: "HTTPRoute",
+			status: map[string]interface{}{"parents": []interface{}{
+				map[string]interface{}{"conditions": []interface{}{accepted}},
+				map[string]interface{}{"conditions": []interface{}{accepted}},
+			}},
+			want: true,
+		},
+		{
+			name: "route is rejected by a parent",
+			kind: "GRPCRoute",
+			status: map[string]interface{}{"parents": []interface{}{
+				map[string]interface{}{"conditions": []interface{}{accepted}},
+				map[string]interface{}{"conditions": []interface{}{rejected}},
+			}},
+			want: false,
+		},
+		{
+			name: "route without status",
+			kind: "HTTPRoute",
+			want: false,
+		},
+		{
+			name: "reference grant",
+			kind: "ReferenceGrant",
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "gateway.networking.k8s.io/v1",
+				"kind":       tt.kind,
+				"metadata":   map[string]interface{}{"name": "foo", "namespace": defaultNamespace, "generation": int64(2)},
+			}}
+			if tt.status != nil {
+				obj.Object["status"] = tt.status
+			}
+			c := NewReadyChecker(fake.NewSimpleClientset(), nil)
+			got, err := c.gatewayAPIReady(obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("gatewayAPIReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func newStatefulSetWithUpdateRevision(name string, replicas, partition, readyReplicas, updatedReplicas int, updateRevision string, generationInSync bool) *appsv1.StatefulSet {
 	ss := newStatefulSet(name, replicas, partition, readyReplicas, updatedReplicas, generationInSync)
 	ss.Status.UpdateRevision = updateRevision
@@ -1638,3 +1920,58 @@ func intToInt32(i int) *int32 {
 	i32 := int32(i)
 	return &i32
 }
+
+func newIngress(name string, lbIngress []networkingv1.IngressLoadBalancerIngress) *networkingv1.Ingress {
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: defaultNamespace,
+		},
+		Status: networkingv1.IngressStatus{
+			LoadBalancer: networkingv1.IngressLoadBalancerStatus{Ingress: lbIngress},
+		},
+	}
+}
+
+func newHorizontalPodAutoscaler(name string, ableToScale corev1.ConditionStatus, hasMetrics bool, generationInSync bool) *autoscalingv2.HorizontalPodAutoscaler {
+	var generation, observedGeneration int64 = 1, 1
+	if !generationInSync {
+		generation = 2
+	}
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  defaultNamespace,
+			Generation: generation,
+		},
+		Status: autoscalingv2.HorizontalPodAutoscalerStatus{
+			ObservedGeneration: &observedGeneration,
+			Conditions: []autoscalingv2.HorizontalPodAutoscalerCondition{
+				{Type: autoscalingv2.AbleToScale, Status: ableToScale},
+			},
+		},
+	}
+	if hasMetrics {
+		hpa.Status.CurrentMetrics = []autoscalingv2.MetricStatus{{Type: autoscalingv2.ResourceMetricSourceType}}
+	}
+	return hpa
+}
+
+func newPodDisruptionBudget(name string, currentHealthy, desiredHealthy int, generationInSync bool) *policyv1.PodDisruptionBudget {
+	var generation, observedGeneration int64 = 1, 1
+	if !generationInSync {
+		generation = 2
+	}
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  defaultNamespace,
+			Generation: generation,
+		},
+		Status: policyv1.PodDisruptionBudgetStatus{
+			ObservedGeneration: observedGeneration,
+			CurrentHealthy:     int32(currentHealthy),
+			DesiredHealthy:     int32(desiredHealthy),
+		},
+	}
+}
@@ -16,12 +16,14 @@ limitations under the License.
 
 package kube // import "helm.sh/helm/v4/pkg/kube"
 
+import "helm.sh/helm/v4/pkg/annotations"
+
 // ResourcePolicyAnno is the annotation name for a resource policy
-const ResourcePolicyAnno = "helm.sh/resource-policy"
+const ResourcePolicyAnno = annotations.ResourcePolicy
 
 // KeepPolicy is the resource policy type for keep
 //
 // This resource policy type allows resources to skip being deleted
 //
 //	during an uninstallRelease action.
-const KeepPolicy = "keep"
+const KeepPolicy = annotations.KeepPolicy
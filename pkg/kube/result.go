@@ -16,6 +16,8 @@ limitations under the License.
 
 package kube
 
+import "time"
+
 // Result contains the information of created, updated, and deleted resources
 // for various kube API calls along with helper methods for using those
 // resources
@@ -23,6 +25,35 @@ type Result struct {
 	Created ResourceList
 	Updated ResourceList
 	Deleted ResourceList
+	// Timings reports how long the operation on each resource took, in the
+	// order in which the operations finished. It is also set when an
+	// operation fails, to find the resources that held it up.
+	Timings []ResourceTiming
+}
+
+// ResourceTiming is the time that an operation on a single resource took.
+type ResourceTiming struct {
+	Kind      string
+	Name      string
+	Namespace string
+	// Operation is one of "get", "create", "update" or "delete".
+	Operation string
+	Duration  time.Duration
+	// TimedOut reports that a request of the operation exceeded the
+	// per-resource timeout.
+	TimedOut bool
+}
+
+// TimedOut returns the timings of the operations that exceeded the
+// per-resource timeout.
+func (r *Result) TimedOut() []ResourceTiming {
+	var timedOut []ResourceTiming
+	for _, t := range r.Timings {
+		if t.TimedOut {
+			timedOut = append(timedOut, t)
+		}
+	}
+	return timedOut
 }
 
 // If needed, we can add methods to the Result type for things like diffing
@@ -0,0 +1,106 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v4/pkg/kube"
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/rest"
+)
+
+// resourceTimer limits the requests for single resources to a timeout and
+// the deadline of a context, and records how long the operations on them take.
+type resourceTimer struct {
+	ctx     context.Context
+	timeout time.Duration
+
+	mtx     sync.Mutex
+	timings []ResourceTiming
+}
+
+// limit applies the timeout and the deadline of the context to the requests
+// made with the clients of the resources. It returns a function that restores
+// the clients.
+func (t *resourceTimer) limit(lists ...ResourceList) func() {
+	deadline, hasDeadline := t.context().Deadline()
+	if t.timeout <= 0 && !hasDeadline {
+		return func() {}
+	}
+	clients := map[*resource.Info]resource.RESTClient{}
+	for _, list := range lists {
+		for _, info := range list {
+			if _, ok := clients[info]; ok || info.Client == nil {
+				continue
+			}
+			clients[info] = info.Client
+			info.Client = resource.NewClientWithOptions(info.Client, func(req *rest.Request) {
+				timeout := t.timeout
+				if hasDeadline {
+					// A request must not outlive the context, and a
+					// request made after the deadline must fail at once
+					if until := time.Until(deadline); timeout <= 0 || until < timeout {
+						timeout = max(until, time.Nanosecond)
+					}
+				}
+				req.Timeout(timeout)
+			})
+		}
+	}
+	return func() {
+		for info, client := range clients {
+			info.Client = client
+		}
+	}
+}
+
+func (t *resourceTimer) context() context.Context {
+	if t.ctx == nil {
+		return context.Background()
+	}
+	return t.ctx
+}
+
+// record records the time that the operation on the resource took since
+// start. When a request of the operation timed out, the error is wrapped to
+// name the resource.
+func (t *resourceTimer) record(info *resource.Info, operation string, start time.Time, err error) error {
+	timing := ResourceTiming{
+		Kind:      info.Mapping.GroupVersionKind.Kind,
+		Name:      info.Name,
+		Namespace: info.Namespace,
+		Operation: operation,
+		Duration:  time.Since(start),
+	}
+	if err != nil && t.timeout > 0 && t.context().Err() == nil && isTimeout(err) {
+		timing.TimedOut = true
+		err = errors.Wrapf(err, "%s %q in namespace %q did not %s within the resource timeout of %s", timing.Kind, timing.Name, timing.Namespace, operation, t.timeout)
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.timings = append(t.timings, timing)
+	return err
+}
+
+func isTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err)
+}
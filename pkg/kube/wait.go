@@ -30,12 +30,15 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/resource"
 
 	"k8s.io/apimachinery/pkg/util/wait"
+
+	"helm.sh/helm/v4/pkg/annotations"
 )
 
 type waiter struct {
@@ -44,22 +47,38 @@ type waiter struct {
 	log     func(string, ...interface{})
 }
 
+// waitResource is a resource to wait for and the time to wait for it.
+type waitResource struct {
+	info    *resource.Info
+	timeout time.Duration
+}
+
 // waitForResources polls to get the current status of all pods, PVCs, Services and
 // Jobs(optional) until all are ready or a timeout is reached
+//
+// Resources annotated with annotations.NoWait are not waited for, and
+// resources annotated with annotations.WaitTimeout are waited for up to their
+// own timeout.
 func (w *waiter) waitForResources(created ResourceList) error {
-	w.log("beginning wait for %d resources with timeout of %v", len(created), w.timeout)
+	resources, timeout, err := w.waitResources(created)
+	if err != nil {
+		return err
+	}
+	w.log("beginning wait for %d resources with timeout of %v", len(resources), timeout)
 
-	ctx, cancel := context.WithTimeout(context.Background(), w.timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	numberOfErrors := make([]int, len(created))
+	start := time.Now()
+	numberOfErrors := make([]int, len(resources))
 	for i := range numberOfErrors {
 		numberOfErrors[i] = 0
 	}
 
 	return wait.PollUntilContextCancel(ctx, 2*time.Second, true, func(ctx context.Context) (bool, error) {
 		waitRetries := 30
-		for i, v := range created {
+		for i, r := range resources {
+			v := r.info
 			ready, err := w.c.IsReady(ctx, v)
 
 			if waitRetries > 0 && w.isRetryableError(err, v) {
@@ -73,6 +92,9 @@ func (w *waiter) waitForResources(created ResourceList) error {
 			}
 			numberOfErrors[i] = 0
 			if !ready {
+				if err == nil && time.Since(start) >= r.timeout {
+					return false, errors.Wrapf(context.DeadlineExceeded, "%s in namespace %q was not ready within %v", v.ObjectName(), v.Namespace, r.timeout)
+				}
 				return false, err
 			}
 		}
@@ -80,6 +102,38 @@ func (w *waiter) waitForResources(created ResourceList) error {
 	})
 }
 
+// waitResources returns the resources to wait for and the time to wait for
+// all of them, which is the longest timeout of the resources.
+func (w *waiter) waitResources(created ResourceList) ([]waitResource, time.Duration, error) {
+	var resources []waitResource
+	longest := w.timeout
+	for _, info := range created {
+		r := waitResource{info: info, timeout: w.timeout}
+		if info.Object != nil {
+			accessor, err := meta.Accessor(info.Object)
+			if err != nil {
+				return nil, 0, err
+			}
+			if annotations.ParseNoWait(accessor.GetAnnotations()) {
+				w.log("not waiting for %s in namespace %q as it is annotated with %s", info.ObjectName(), info.Namespace, annotations.NoWait)
+				continue
+			}
+			timeout, err := annotations.ParseWaitTimeout(accessor.GetAnnotations())
+			if err != nil {
+				return nil, 0, errors.Wrapf(err, "%s in namespace %q", info.ObjectName(), info.Namespace)
+			}
+			if timeout > 0 {
+				r.timeout = timeout
+			}
+		}
+		if r.timeout > longest {
+			longest = r.timeout
+		}
+		resources = append(resources, r)
+	}
+	return resources, longest, nil
+}
+
 func (w *waiter) isRetryableError(err error, resource *resource.Info) bool {
 	if err == nil {
 		return false
@@ -20,6 +20,7 @@ import (
 	"path/filepath"
 
 	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/engine"
 	"helm.sh/helm/v4/pkg/lint/rules"
 	"helm.sh/helm/v4/pkg/lint/support"
 )
@@ -27,6 +28,10 @@ import (
 type linterOptions struct {
 	KubeVersion          *chartutil.KubeVersion
 	SkipSchemaValidation bool
+	Strictness           engine.Strictness
+	ImportValues         bool
+	UnusedValues         bool
+	MetadataPolicy       *rules.MetadataPolicy
 }
 
 type LinterOption func(lo *linterOptions)
@@ -43,6 +48,35 @@ func WithSkipSchemaValidation(skipSchemaValidation bool) LinterOption {
 	}
 }
 
+// WithStrictness sets the template problems that are reported as errors.
+func WithStrictness(strictness engine.Strictness) LinterOption {
+	return func(lo *linterOptions) {
+		lo.Strictness = strictness
+	}
+}
+
+// WithImportValues reports the import-values of the dependencies that refer
+// to values which do not exist.
+func WithImportValues(importValues bool) LinterOption {
+	return func(lo *linterOptions) {
+		lo.ImportValues = importValues
+	}
+}
+
+// WithUnusedValues reports the values that no template references.
+func WithUnusedValues(unusedValues bool) LinterOption {
+	return func(lo *linterOptions) {
+		lo.UnusedValues = unusedValues
+	}
+}
+
+// WithMetadataPolicy checks the metadata of Chart.yaml against a policy.
+func WithMetadataPolicy(policy *rules.MetadataPolicy) LinterOption {
+	return func(lo *linterOptions) {
+		lo.MetadataPolicy = policy
+	}
+}
+
 func RunAll(baseDir string, values map[string]interface{}, namespace string, options ...LinterOption) support.Linter {
 
 	chartDir, _ := filepath.Abs(baseDir)
@@ -58,8 +92,10 @@ func RunAll(baseDir string, values map[string]interface{}, namespace string, opt
 
 	rules.Chartfile(&result)
 	rules.ValuesWithOverrides(&result, values)
-	rules.TemplatesWithSkipSchemaValidation(&result, values, namespace, lo.KubeVersion, lo.SkipSchemaValidation)
+	rules.TemplatesWithStrictness(&result, values, namespace, lo.KubeVersion, lo.SkipSchemaValidation, lo.Strictness)
 	rules.Dependencies(&result)
+	rules.ValuesUsage(&result, lo.ImportValues, lo.UnusedValues)
+	rules.Metadata(&result, lo.MetadataPolicy)
 
 	return result
 }
@@ -64,6 +64,7 @@ func Chartfile(linter *support.Linter) {
 	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartIconURL(chartFile))
 	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartType(chartFile))
 	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartDependencies(chartFile))
+	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartHelmVersion(chartFile))
 }
 
 func validateChartVersionType(data map[string]interface{}) error {
@@ -199,6 +200,16 @@ func validateChartType(cf *chart.Metadata) error {
 	return nil
 }
 
+func validateChartHelmVersion(cf *chart.Metadata) error {
+	if cf.HelmVersion == "" {
+		return nil
+	}
+	if _, err := semver.NewConstraint(cf.HelmVersion); err != nil {
+		return errors.Wrapf(err, "helmVersion '%s' is not a valid SemVerV2 constraint", cf.HelmVersion)
+	}
+	return nil
+}
+
 // loadChartFileForTypeCheck loads the Chart.yaml
 // in a generic form of a map[string]interface{}, so that the type
 // of the values can be checked
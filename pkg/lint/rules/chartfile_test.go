@@ -192,6 +192,16 @@ func TestValidateChartIconURL(t *testing.T) {
 	}
 }
 
+func TestValidateChartHelmVersion(t *testing.T) {
+	if err := validateChartHelmVersion(&chart.Metadata{HelmVersion: ">= 3.10.0"}); err != nil {
+		t.Errorf("validateChartHelmVersion to return no error, got %s", err)
+	}
+	err := validateChartHelmVersion(&chart.Metadata{HelmVersion: ">= three"})
+	if err == nil || !strings.Contains(err.Error(), "is not a valid SemVerV2 constraint") {
+		t.Errorf("validateChartHelmVersion to return an invalid constraint error, got %v", err)
+	}
+}
+
 func TestChartfile(t *testing.T) {
 	t.Run("Chart.yaml basic validity issues", func(t *testing.T) {
 		linter := support.Linter{ChartDir: badChartDir}
@@ -0,0 +1,309 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules // import "helm.sh/helm/v4/pkg/lint/rules"
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/lint/support"
+)
+
+// DefaultLicenseAnnotation is the annotation of Chart.yaml holding the
+// license of the chart, as used by Artifact Hub.
+const DefaultLicenseAnnotation = "artifacthub.io/license"
+
+// metadataFields are the fields of Chart.yaml a MetadataPolicy can require.
+var metadataFields = []string{"description", "home", "icon", "keywords", "license", "maintainers", "sources"}
+
+// MetadataPolicy is a policy for the completeness of the metadata of charts,
+// e.g. the requirements of the internal catalog of an organization. It is
+// loaded from a YAML file with LoadMetadataPolicy:
+//
+//	severity: error
+//	required: [home, icon, license, maintainers, sources]
+//	maintainerEmail: true
+//	spdxLicense: true
+//	allowedLicenses: [Apache-2.0, MIT]
+//	checkURLs: true
+type MetadataPolicy struct {
+	// Severity is the severity of the violations of the policy: "error",
+	// "warning" or "info". It defaults to "warning".
+	Severity string `json:"severity,omitempty"`
+	// Required are the fields of Chart.yaml that must be set, among
+	// "description", "home", "icon", "keywords", "license", "maintainers"
+	// and "sources".
+	Required []string `json:"required,omitempty"`
+	// MaintainerEmail requires an email for every maintainer.
+	MaintainerEmail bool `json:"maintainerEmail,omitempty"`
+	// LicenseAnnotation is the annotation holding the license of the chart.
+	// It defaults to DefaultLicenseAnnotation.
+	LicenseAnnotation string `json:"licenseAnnotation,omitempty"`
+	// SPDXLicense requires the license to be a valid SPDX license
+	// expression, e.g. "Apache-2.0" or "MIT OR GPL-2.0-only".
+	SPDXLicense bool `json:"spdxLicense,omitempty"`
+	// AllowedLicenses are the licenses the license expression may refer to.
+	// Every license is allowed when empty.
+	AllowedLicenses []string `json:"allowedLicenses,omitempty"`
+	// CheckURLs checks that the home, sources, icon and maintainer URLs are
+	// reachable. It requires network access.
+	CheckURLs bool `json:"checkURLs,omitempty"`
+	// URLTimeout is the timeout of checking a URL. It defaults to 10s.
+	URLTimeout string `json:"urlTimeout,omitempty"`
+
+	severity int
+	timeout  time.Duration
+	client   *http.Client
+}
+
+// LoadMetadataPolicy loads and validates a MetadataPolicy from a YAML file.
+func LoadMetadataPolicy(filename string) (*MetadataPolicy, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	p := &MetadataPolicy{}
+	if err := yaml.UnmarshalStrict(b, p); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse the metadata policy %s", filename)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid metadata policy %s", filename)
+	}
+	return p, nil
+}
+
+// Validate checks the policy and sets its defaults.
+func (p *MetadataPolicy) Validate() error {
+	switch strings.ToLower(p.Severity) {
+	case "", "warning":
+		p.severity = support.WarningSev
+	case "error":
+		p.severity = support.ErrorSev
+	case "info":
+		p.severity = support.InfoSev
+	default:
+		return errors.Errorf("unknown severity %q, must be one of error, warning or info", p.Severity)
+	}
+	for _, field := range p.Required {
+		if !containsString(metadataFields, field) {
+			return errors.Errorf("unknown required field %q, must be one of %s", field, strings.Join(metadataFields, ", "))
+		}
+	}
+	for _, l := range p.AllowedLicenses {
+		_, unknown, err := parseSPDXExpression(l)
+		if err == nil && len(unknown) > 0 {
+			err = errors.Errorf("unknown license identifier '%s'", unknown[0])
+		}
+		if err != nil {
+			return errors.Wrapf(err, "invalid allowed license %q", l)
+		}
+	}
+	if p.LicenseAnnotation == "" {
+		p.LicenseAnnotation = DefaultLicenseAnnotation
+	}
+	p.timeout = 10 * time.Second
+	if p.URLTimeout != "" {
+		d, err := time.ParseDuration(p.URLTimeout)
+		if err != nil {
+			return errors.Wrap(err, "invalid URL timeout")
+		}
+		p.timeout = d
+	}
+	return nil
+}
+
+// Metadata runs the lints of the metadata policy against Chart.yaml. It does
+// nothing when the policy is nil.
+func Metadata(linter *support.Linter, policy *MetadataPolicy) {
+	if policy == nil {
+		return
+	}
+	chartFileName := "Chart.yaml"
+	cf, err := chartutil.LoadChartfile(filepath.Join(linter.ChartDir, chartFileName))
+	if err != nil {
+		// Parsing errors are reported by Chartfile.
+		return
+	}
+	if !linter.RunLinterRule(support.ErrorSev, chartFileName, policy.Validate()) {
+		return
+	}
+
+	for _, err := range policy.validateRequired(cf) {
+		linter.RunLinterRule(policy.severity, chartFileName, err)
+	}
+	linter.RunLinterRule(policy.severity, chartFileName, policy.validateMaintainerEmails(cf))
+	linter.RunLinterRule(policy.severity, chartFileName, policy.validateLicense(cf))
+	linter.RunLinterRule(support.WarningSev, chartFileName, policy.validateLicenseIDs(cf))
+	if policy.CheckURLs {
+		for _, err := range policy.validateURLs(cf) {
+			linter.RunLinterRule(policy.severity, chartFileName, err)
+		}
+	}
+}
+
+func (p *MetadataPolicy) validateRequired(cf *chart.Metadata) []error {
+	var errs []error
+	for _, field := range p.Required {
+		var missing bool
+		switch field {
+		case "description":
+			missing = cf.Description == ""
+		case "home":
+			missing = cf.Home == ""
+		case "icon":
+			missing = cf.Icon == ""
+		case "keywords":
+			missing = len(cf.Keywords) == 0
+		case "license":
+			missing = cf.Annotations[p.LicenseAnnotation] == ""
+		case "maintainers":
+			missing = len(cf.Maintainers) == 0
+		case "sources":
+			missing = len(cf.Sources) == 0
+		}
+		if missing && field == "license" {
+			errs = append(errs, errors.Errorf("license is required by the metadata policy, set the annotation '%s'", p.LicenseAnnotation))
+		} else if missing {
+			errs = append(errs, errors.Errorf("%s is required by the metadata policy", field))
+		}
+	}
+	return errs
+}
+
+func (p *MetadataPolicy) validateMaintainerEmails(cf *chart.Metadata) error {
+	if !p.MaintainerEmail {
+		return nil
+	}
+	for _, m := range cf.Maintainers {
+		if m != nil && m.Email == "" {
+			return errors.Errorf("maintainer '%s' requires an email by the metadata policy", m.Name)
+		}
+	}
+	return nil
+}
+
+func (p *MetadataPolicy) validateLicense(cf *chart.Metadata) error {
+	license := cf.Annotations[p.LicenseAnnotation]
+	if license == "" || (!p.SPDXLicense && len(p.AllowedLicenses) == 0) {
+		return nil
+	}
+	ids, _, err := parseSPDXExpression(license)
+	if err != nil {
+		return errors.Wrapf(err, "license '%s' is not a valid SPDX license expression", license)
+	}
+	if len(p.AllowedLicenses) == 0 {
+		return nil
+	}
+	var allowed []string
+	for _, l := range p.AllowedLicenses {
+		a, _, _ := parseSPDXExpression(l)
+		allowed = append(allowed, a...)
+	}
+	for _, id := range ids {
+		if !containsFold(allowed, id) {
+			return errors.Errorf("license '%s' is not allowed by the metadata policy, allowed licenses are %s", id, strings.Join(p.AllowedLicenses, ", "))
+		}
+	}
+	return nil
+}
+
+// validateLicenseIDs returns an error when the license refers to identifiers
+// that are not on the SPDX license list. It is only a warning, as the list
+// of Helm may be older than the license.
+func (p *MetadataPolicy) validateLicenseIDs(cf *chart.Metadata) error {
+	license := cf.Annotations[p.LicenseAnnotation]
+	if license == "" || !p.SPDXLicense {
+		return nil
+	}
+	_, unknown, err := parseSPDXExpression(license)
+	if err != nil || len(unknown) == 0 {
+		return nil
+	}
+	return errors.Errorf("license '%s' refers to identifiers that are not on the SPDX license list: %s, use a 'LicenseRef-' identifier for other licenses", license, strings.Join(unknown, ", "))
+}
+
+// validateURLs returns an error for every URL of the chart that is not
+// reachable.
+func (p *MetadataPolicy) validateURLs(cf *chart.Metadata) []error {
+	urls := append([]string{cf.Home, cf.Icon}, cf.Sources...)
+	for _, m := range cf.Maintainers {
+		if m != nil {
+			urls = append(urls, m.URL)
+		}
+	}
+
+	client := p.client
+	if client == nil {
+		client = &http.Client{Timeout: p.timeout}
+	}
+	var errs []error
+	checked := map[string]bool{}
+	for _, u := range urls {
+		if u == "" || checked[u] {
+			continue
+		}
+		checked[u] = true
+		if err := checkURL(client, u); err != nil {
+			errs = append(errs, errors.Wrapf(err, "URL '%s' is not reachable", u))
+		}
+	}
+	return errs
+}
+
+// checkURL checks that a URL responds successfully to a HEAD request, or to a
+// GET request for the servers which do not allow HEAD.
+func checkURL(client *http.Client, u string) error {
+	resp, err := client.Head(u)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp.Body.Close()
+		resp, err = client.Get(u)
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return errors.Errorf("status %s", resp.Status)
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, s string) bool {
+	for _, l := range list {
+		if strings.EqualFold(l, s) {
+			return true
+		}
+	}
+	return false
+}
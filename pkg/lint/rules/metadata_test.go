@@ -0,0 +1,156 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/lint/support"
+)
+
+func TestLoadMetadataPolicy(t *testing.T) {
+	dir := t.TempDir()
+	for _, tt := range []struct {
+		policy  string
+		wantErr string
+	}{
+		{"required: [home, license]\nseverity: error", ""},
+		{"required: [homepage]", `unknown required field "homepage"`},
+		{"severity: fatal", `unknown severity "fatal"`},
+		{"allowedLicenses: [Apache-3.0]", "unknown license identifier 'Apache-3.0'"},
+		{"urlTimeout: soon", "invalid URL timeout"},
+		{"requiredFields: [home]", "unknown field"},
+	} {
+		filename := filepath.Join(dir, "policy.yaml")
+		if err := os.WriteFile(filename, []byte(tt.policy), 0644); err != nil {
+			t.Fatal(err)
+		}
+		p, err := LoadMetadataPolicy(filename)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error: %s", tt.policy, err)
+			} else if p.severity != support.ErrorSev || p.LicenseAnnotation != DefaultLicenseAnnotation {
+				t.Errorf("%q: the defaults are not set: %+v", tt.policy, p)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%q: expected error containing %q, got %v", tt.policy, tt.wantErr, err)
+		}
+	}
+}
+
+func TestMetadataPolicy(t *testing.T) {
+	cf := &chart.Metadata{
+		Home:        "https://example.com",
+		Maintainers: []*chart.Maintainer{{Name: "ops", Email: "ops@example.com"}, {Name: "dev"}},
+		Annotations: map[string]string{"artifacthub.io/license": "Apache-2.0 OR GPL-2.0-or-later"},
+	}
+	p := &MetadataPolicy{
+		Required:        []string{"home", "icon", "license", "sources"},
+		MaintainerEmail: true,
+		SPDXLicense:     true,
+		AllowedLicenses: []string{"Apache-2.0", "MIT"},
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	errs := p.validateRequired(cf)
+	if len(errs) != 2 || errs[0].Error() != "icon is required by the metadata policy" || errs[1].Error() != "sources is required by the metadata policy" {
+		t.Errorf("unexpected errors for the required fields: %v", errs)
+	}
+	if err := p.validateMaintainerEmails(cf); err == nil || err.Error() != "maintainer 'dev' requires an email by the metadata policy" {
+		t.Errorf("unexpected error for the maintainers: %v", err)
+	}
+	if err := p.validateLicense(cf); err == nil || !strings.Contains(err.Error(), "license 'GPL-2.0-or-later' is not allowed") {
+		t.Errorf("unexpected error for the license: %v", err)
+	}
+
+	cf.Annotations["artifacthub.io/license"] = "Apache-2.0 AND"
+	if err := p.validateLicense(cf); err == nil || !strings.Contains(err.Error(), "is not a valid SPDX license expression") {
+		t.Errorf("unexpected error for an invalid license: %v", err)
+	}
+	cf.Annotations["artifacthub.io/license"] = "mit"
+	if err := p.validateLicense(cf); err != nil {
+		t.Errorf("unexpected error for an allowed license: %s", err)
+	}
+
+	p.AllowedLicenses = nil
+	cf.Annotations["artifacthub.io/license"] = "CC-BY-2.0 AND curl"
+	if err := p.validateLicense(cf); err != nil {
+		t.Errorf("unexpected error for a valid license: %s", err)
+	}
+	if err := p.validateLicenseIDs(cf); err != nil {
+		t.Errorf("unexpected warning for a valid license: %s", err)
+	}
+	cf.Annotations["artifacthub.io/license"] = "MIT OR Future-License-1.0"
+	if err := p.validateLicense(cf); err != nil {
+		t.Errorf("unexpected error for a license missing from the SPDX list: %s", err)
+	}
+	if err := p.validateLicenseIDs(cf); err == nil || !strings.Contains(err.Error(), "not on the SPDX license list: Future-License-1.0") {
+		t.Errorf("unexpected warning for a license missing from the SPDX list: %v", err)
+	}
+}
+
+func TestMetadataPolicyURLs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/get-only":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		}
+	}))
+	defer srv.Close()
+
+	cf := &chart.Metadata{
+		Home:        srv.URL,
+		Icon:        srv.URL + "/get-only",
+		Sources:     []string{srv.URL + "/missing", srv.URL},
+		Maintainers: []*chart.Maintainer{{Name: "ops", URL: srv.URL + "/missing"}},
+	}
+	p := &MetadataPolicy{CheckURLs: true, client: srv.Client()}
+	errs := p.validateURLs(cf)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "URL '"+srv.URL+"/missing' is not reachable: status 404") {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	linter := support.Linter{ChartDir: "testdata/goodone"}
+	Metadata(&linter, nil)
+	if len(linter.Messages) != 0 {
+		t.Fatalf("expected no messages without a policy, got %v", linter.Messages)
+	}
+
+	Metadata(&linter, &MetadataPolicy{Severity: "info", Required: []string{"home", "icon"}})
+	if len(linter.Messages) != 1 {
+		t.Fatalf("expected one message, got %v", linter.Messages)
+	}
+	if msg := linter.Messages[0]; msg.Severity != support.InfoSev || msg.Err.Error() != "home is required by the metadata policy" {
+		t.Errorf("unexpected message: %v", msg)
+	}
+}
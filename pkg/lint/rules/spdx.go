@@ -0,0 +1,136 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules // import "helm.sh/helm/v4/pkg/lint/rules"
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//go:generate go run spdx_gen.go
+
+// parseSPDXExpression parses an SPDX license expression, e.g.
+// "Apache-2.0 OR (MIT AND BSD-3-Clause)", and returns the license
+// identifiers it refers to, without their "+" suffix, and the license and
+// exception identifiers that are not on the SPDX license list.
+func parseSPDXExpression(expr string) (ids, unknown []string, err error) {
+	p := &spdxParser{tokens: spdxTokens(expr)}
+	if len(p.tokens) == 0 {
+		return nil, nil, errors.New("empty license expression")
+	}
+	if err := p.expression(); err != nil {
+		return nil, nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, nil, errors.Errorf("unexpected '%s'", p.tokens[p.pos])
+	}
+	return p.ids, p.unknown, nil
+}
+
+// spdxTokens splits a license expression into identifiers, operators and
+// parentheses.
+func spdxTokens(expr string) []string {
+	expr = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expr)
+	return strings.Fields(expr)
+}
+
+type spdxParser struct {
+	tokens  []string
+	pos     int
+	ids     []string
+	unknown []string
+}
+
+func (p *spdxParser) next() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+// expression parses operands joined by AND and OR, in upper or lower case.
+func (p *spdxParser) expression() error {
+	for {
+		if err := p.operand(); err != nil {
+			return err
+		}
+		switch strings.ToUpper(p.next()) {
+		case "AND", "OR":
+			p.pos++
+		default:
+			return nil
+		}
+	}
+}
+
+// operand parses a parenthesized expression or a license identifier,
+// followed by an optional WITH exception.
+func (p *spdxParser) operand() error {
+	tok := p.next()
+	switch {
+	case tok == "":
+		return errors.New("unexpected end of expression")
+	case tok == "(":
+		p.pos++
+		if err := p.expression(); err != nil {
+			return err
+		}
+		if p.next() != ")" {
+			return errors.New("missing ')'")
+		}
+		p.pos++
+		return nil
+	case tok == ")" || isSPDXOperator(tok):
+		return errors.Errorf("unexpected '%s'", tok)
+	}
+
+	p.pos++
+	id := strings.TrimSuffix(tok, "+")
+	if !isSPDXRef(id) && !containsFold(spdxLicenses, id) {
+		p.unknown = append(p.unknown, tok)
+	}
+	p.ids = append(p.ids, id)
+
+	if strings.EqualFold(p.next(), "WITH") {
+		p.pos++
+		exception := p.next()
+		if exception == "" || isSPDXOperator(exception) || exception == "(" || exception == ")" {
+			return errors.New("missing license exception after WITH")
+		}
+		if !isSPDXRef(exception) && !containsFold(spdxExceptions, exception) {
+			p.unknown = append(p.unknown, exception)
+		}
+		p.pos++
+	}
+	return nil
+}
+
+func isSPDXOperator(tok string) bool {
+	switch strings.ToUpper(tok) {
+	case "AND", "OR", "WITH":
+		return true
+	}
+	return false
+}
+
+// isSPDXRef reports whether an identifier refers to a license outside of the
+// SPDX license list.
+func isSPDXRef(id string) bool {
+	return strings.HasPrefix(id, "LicenseRef-") || strings.HasPrefix(id, "DocumentRef-") ||
+		strings.HasPrefix(id, "AdditionRef-")
+}
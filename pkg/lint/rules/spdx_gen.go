@@ -0,0 +1,126 @@
+//go:build ignore
+
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// spdx_gen generates spdx_ids.go from the license list data of SPDX:
+//
+//	go run spdx_gen.go [-data <url>]
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+const output = "spdx_ids.go"
+
+const header = `/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+`
+
+type licenses struct {
+	Licenses []struct {
+		ID string `json:"licenseId"`
+	} `json:"licenses"`
+}
+
+type exceptions struct {
+	Exceptions []struct {
+		ID string `json:"licenseExceptionId"`
+	} `json:"exceptions"`
+}
+
+func main() {
+	data := flag.String("data", "https://raw.githubusercontent.com/spdx/license-list-data/main/json", "the URL of the JSON license list data")
+	flag.Parse()
+
+	var l licenses
+	fetch(*data+"/licenses.json", &l)
+	var e exceptions
+	fetch(*data+"/exceptions.json", &e)
+
+	var ids, exceptionIDs []string
+	for _, license := range l.Licenses {
+		ids = append(ids, license.ID)
+	}
+	for _, exception := range e.Exceptions {
+		exceptionIDs = append(exceptionIDs, exception.ID)
+	}
+
+	var b bytes.Buffer
+	b.WriteString(header)
+	b.WriteString("// Code generated by spdx_gen.go. DO NOT EDIT.\n\n")
+	b.WriteString("package rules\n\n")
+	b.WriteString("// spdxLicenses are the identifiers of the SPDX license list, including the\n")
+	b.WriteString("// deprecated ones which are still valid in license expressions.\n")
+	writeList(&b, "spdxLicenses", ids)
+	b.WriteString("\n// spdxExceptions are the identifiers of the SPDX license exceptions.\n")
+	writeList(&b, "spdxExceptions", exceptionIDs)
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(output, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func fetch(url string, v interface{}) {
+	resp, err := http.Get(url)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("unable to fetch %s: %s", url, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		log.Fatalf("unable to decode %s: %s", url, err)
+	}
+}
+
+func writeList(b *bytes.Buffer, name string, ids []string) {
+	sort.Slice(ids, func(i, j int) bool { return strings.ToLower(ids[i]) < strings.ToLower(ids[j]) })
+	fmt.Fprintf(b, "var %s = []string{\n", name)
+	for _, id := range ids {
+		fmt.Fprintf(b, "\t%q,\n", id)
+	}
+	b.WriteString("}\n")
+}
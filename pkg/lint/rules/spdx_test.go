@@ -0,0 +1,59 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSPDXExpression(t *testing.T) {
+	for _, tt := range []struct {
+		expr    string
+		ids     []string
+		unknown []string
+		wantErr string
+	}{
+		{expr: "Apache-2.0", ids: []string{"Apache-2.0"}},
+		{expr: "mit or apache-2.0", ids: []string{"mit", "apache-2.0"}},
+		{expr: "GPL-2.0-or-later WITH Classpath-exception-2.0", ids: []string{"GPL-2.0-or-later"}},
+		{expr: "(MIT AND BSD-3-Clause) OR LicenseRef-Proprietary", ids: []string{"MIT", "BSD-3-Clause", "LicenseRef-Proprietary"}},
+		{expr: "EPL-1.0+", ids: []string{"EPL-1.0"}},
+		{expr: "CC-BY-2.0 AND curl AND JSON", ids: []string{"CC-BY-2.0", "curl", "JSON"}},
+		{expr: "Some-License+ WITH Some-exception", ids: []string{"Some-License"}, unknown: []string{"Some-License+", "Some-exception"}},
+		{expr: "", wantErr: "empty license expression"},
+		{expr: "Apache 2.0", wantErr: "unexpected '2.0'"},
+		{expr: "MIT AND", wantErr: "unexpected end of expression"},
+		{expr: "MIT OR OR Apache-2.0", wantErr: "unexpected 'OR'"},
+		{expr: "(MIT OR Apache-2.0", wantErr: "missing ')'"},
+		{expr: "MIT Apache-2.0", wantErr: "unexpected 'Apache-2.0'"},
+		{expr: "GPL-2.0-only WITH", wantErr: "missing license exception after WITH"},
+	} {
+		ids, unknown, err := parseSPDXExpression(tt.expr)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("%q: expected error %q, got %v", tt.expr, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", tt.expr, err)
+		} else if !reflect.DeepEqual(ids, tt.ids) || !reflect.DeepEqual(unknown, tt.unknown) {
+			t.Errorf("%q: expected %v and unknown %v, got %v and %v", tt.expr, tt.ids, tt.unknown, ids, unknown)
+		}
+	}
+}
@@ -19,9 +19,11 @@ package rules
 import (
 	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/pkg/errors"
 
+	"helm.sh/helm/v4/pkg/chart/loader"
 	"helm.sh/helm/v4/pkg/chartutil"
 	"helm.sh/helm/v4/pkg/lint/support"
 )
@@ -57,6 +59,10 @@ func validateValuesFile(valuesPath string, overrides map[string]interface{}) err
 	if err != nil {
 		return errors.Wrap(err, "unable to parse YAML")
 	}
+	values, err = mergeValuesDir(values, filepath.Join(filepath.Dir(valuesPath), loader.ValuesDir))
+	if err != nil {
+		return err
+	}
 
 	// Helm 3.0.0 carried over the values linting from Helm 2.x, which only tests the top
 	// level values against the top-level expectations. Subchart values are not linted.
@@ -77,3 +83,25 @@ func validateValuesFile(valuesPath string, overrides map[string]interface{}) err
 	}
 	return chartutil.ValidateAgainstSingleSchema(coalescedValues, schema)
 }
+
+// mergeValuesDir merges the values files of the values.d directory of a chart
+// over the values of values.yaml, like the chart loader does.
+func mergeValuesDir(values map[string]interface{}, dir string) (map[string]interface{}, error) {
+	var files []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		vals, err := chartutil.ReadValuesFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse YAML of %s", filepath.Join(loader.ValuesDir, filepath.Base(file)))
+		}
+		values = chartutil.MergeTables(vals, values)
+	}
+	return values, nil
+}
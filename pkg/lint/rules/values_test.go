@@ -113,6 +113,29 @@ func TestValidateValuesFileSchemaOverrides(t *testing.T) {
 	}
 }
 
+func TestValidateValuesFileValuesDir(t *testing.T) {
+	tmpdir := ensure.TempFile(t, "values.yaml", []byte("username: admin\npassword:"))
+	createTestingSchema(t, tmpdir)
+	valuesDir := filepath.Join(tmpdir, "values.d")
+	if err := os.Mkdir(valuesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(valuesDir, "10-password.yaml"), []byte("password: swordfish"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	valfile := filepath.Join(tmpdir, "values.yaml")
+	if err := validateValuesFile(valfile, map[string]interface{}{}); err != nil {
+		t.Fatalf("Failed validation with %s", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(valuesDir, "20-username.yaml"), []byte("username: 1234"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := validateValuesFile(valfile, map[string]interface{}{})
+	assert.ErrorContains(t, err, "Expected: string, given: integer", "the values of values.d should be validated")
+}
+
 func TestValidateValuesFile(t *testing.T) {
 	tests := []struct {
 		name         string
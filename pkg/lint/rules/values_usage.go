@@ -0,0 +1,57 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules // import "helm.sh/helm/v4/pkg/lint/rules"
+
+import (
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/chart/loader"
+	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/lint/support"
+)
+
+// ValuesUsage runs lints against the use of the values: if importValues is
+// set, the import-values of the dependencies which refer to values that do not
+// exist and, if unused is set, the values in values.yaml which no template
+// references.
+func ValuesUsage(linter *support.Linter, importValues, unused bool) {
+	if !importValues && !unused {
+		return
+	}
+	c, err := loader.LoadDir(linter.ChartDir)
+	if err != nil {
+		// Loading errors are reported by the other rules.
+		return
+	}
+
+	if importValues {
+		for _, err := range chartutil.ValidateImportValues(c) {
+			linter.RunLinterRule(support.WarningSev, "Chart.yaml", err)
+		}
+	}
+	if unused {
+		linter.RunLinterRule(support.InfoSev, "values.yaml", validateValuesUsed(chartutil.UnusedValues(c)))
+	}
+}
+
+func validateValuesUsed(unused []string) error {
+	if len(unused) == 0 {
+		return nil
+	}
+	return fmt.Errorf("values are not referenced by any template: %s", strings.Join(unused, ", "))
+}
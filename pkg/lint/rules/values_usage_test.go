@@ -0,0 +1,79 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/lint/support"
+)
+
+func TestValuesUsage(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "parent",
+			Version:    "0.1.0",
+			Dependencies: []*chart.Dependency{{
+				Name:         "child",
+				Version:      "0.1.0",
+				ImportValues: []interface{}{"missing"},
+			}},
+		},
+		Templates: []*chart.File{
+			{Name: "templates/cm.yaml", Data: []byte("name: {{ .Values.name }}")},
+		},
+		Raw: []*chart.File{
+			{Name: "values.yaml", Data: []byte("name: parent\nunused: true\n")},
+		},
+	}
+	c.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: "v2", Name: "child", Version: "0.1.0"},
+	})
+	dir := t.TempDir()
+	if err := chartutil.SaveDir(c, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := support.Linter{ChartDir: filepath.Join(dir, c.Name())}
+	ValuesUsage(&linter, false, false)
+	if len(linter.Messages) != 0 {
+		t.Fatalf("Expected no message, got %v", linter.Messages)
+	}
+
+	linter = support.Linter{ChartDir: filepath.Join(dir, c.Name())}
+	ValuesUsage(&linter, true, false)
+	if len(linter.Messages) != 1 {
+		t.Fatalf("Expected 1 message, got %v", linter.Messages)
+	}
+	if msg := linter.Messages[0]; msg.Severity != support.WarningSev || !strings.Contains(msg.Err.Error(), `refers to "exports.missing"`) {
+		t.Errorf("Expected a warning about the missing import, got %v", msg)
+	}
+
+	linter = support.Linter{ChartDir: filepath.Join(dir, c.Name())}
+	ValuesUsage(&linter, true, true)
+	if len(linter.Messages) != 2 {
+		t.Fatalf("Expected 2 messages, got %v", linter.Messages)
+	}
+	if msg := linter.Messages[1]; msg.Severity != support.InfoSev || msg.Err.Error() != "values are not referenced by any template: unused" {
+		t.Errorf("Expected an info about the unused value, got %v", msg)
+	}
+}
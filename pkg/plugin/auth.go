@@ -0,0 +1,186 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin // import "helm.sh/helm/v4/pkg/plugin"
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/pkg/registry"
+)
+
+// TypeAuth is the type of plugins that provide credentials for registries
+// and chart repositories.
+const TypeAuth = "auth/v1"
+
+// authExpiryMargin is how long before their expiry cached credentials are
+// renewed.
+const authExpiryMargin = 30 * time.Second
+
+// authRequest is written to the standard input of the plugin command.
+type authRequest struct {
+	Host string `json:"host"`
+}
+
+// authResponse is read from the standard output of the plugin command.
+type authResponse struct {
+	registry.Credentials
+	Error string `json:"error,omitempty"`
+}
+
+// validateAuth validates the host patterns of an auth/v1 plugin.
+func validateAuth(md *Metadata, filepath string) error {
+	if md.Type != TypeAuth {
+		if len(md.AuthHosts) > 0 {
+			return fmt.Errorf("authHosts are only supported by %s plugins in %q", TypeAuth, filepath)
+		}
+		return nil
+	}
+	if len(md.AuthHosts) == 0 {
+		return fmt.Errorf("%s plugin does not declare any authHosts in %q", TypeAuth, filepath)
+	}
+	for _, pattern := range md.AuthHosts {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return fmt.Errorf("invalid auth host pattern %q in %q", pattern, filepath)
+		}
+	}
+	return nil
+}
+
+// matchesAuthHost reports whether an auth/v1 plugin provides credentials for
+// the host.
+func (p *Plugin) matchesAuthHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, pattern := range p.Metadata.AuthHosts {
+		if ok, _ := path.Match(strings.ToLower(pattern), host); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Credentials runs an auth/v1 plugin to get the credentials of the host.
+//
+// The plugin command receives a JSON request of the form {"host": "..."} on
+// standard input, and writes the credentials as a JSON object with the
+// username, password, token, clientCertificate, clientKey and expiresAt
+// fields, or {"error": "message"}, on standard output. The command runs in
+// the plugin directory with an environment that only contains PATH,
+// HELM_PLUGIN_NAME and HELM_PLUGIN_DIR, and is stopped when it exceeds the
+// limits.
+func (p *Plugin) Credentials(host string, limits ExecLimits) (*registry.Credentials, error) {
+	if p.Metadata.Type != TypeAuth {
+		return nil, errors.Errorf("plugin %q is not a %s plugin", p.Metadata.Name, TypeAuth)
+	}
+	cmd, err := p.isolatedCommand()
+	if err != nil {
+		return nil, err
+	}
+	in, err := json.Marshal(authRequest{Host: host})
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.run(limits, in)
+	if err != nil {
+		return nil, errors.Wrapf(err, "auth plugin %q", p.Metadata.Name)
+	}
+	var resp authResponse
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return nil, errors.Wrapf(err, "invalid response from auth plugin %q", p.Metadata.Name)
+	}
+	if resp.Error != "" {
+		return nil, errors.Errorf("auth plugin %q: %s", p.Metadata.Name, resp.Error)
+	}
+	return &resp.Credentials, nil
+}
+
+// authCache holds the credentials returned by auth/v1 plugins, keyed by
+// plugin directory and host. It is shared by all the providers so that
+// plugins are not run again for every getter or registry client.
+var authCache = struct {
+	sync.Mutex
+	creds map[string]*registry.Credentials
+}{creds: map[string]*registry.Credentials{}}
+
+// AuthProvider provides credentials from the auth/v1 plugins. It implements
+// registry.CredentialsProvider.
+//
+// Credentials are cached until shortly before they expire. Credentials
+// without an expiry are cached for the lifetime of the process.
+type AuthProvider struct {
+	plugins []*Plugin
+	limits  ExecLimits
+	now     func() time.Time
+}
+
+// NewAuthProvider returns a provider for the auth/v1 plugins in the list.
+// Other plugins are ignored.
+func NewAuthProvider(plugins []*Plugin, limits ExecLimits) *AuthProvider {
+	a := &AuthProvider{
+		limits: limits,
+		now:    time.Now,
+	}
+	for _, p := range plugins {
+		if p.Metadata.Type == TypeAuth {
+			a.plugins = append(a.plugins, p)
+		}
+	}
+	return a
+}
+
+// Empty reports whether the provider has no auth/v1 plugins.
+func (a *AuthProvider) Empty() bool {
+	return len(a.plugins) == 0
+}
+
+// Credentials returns the credentials of the first plugin that serves the
+// host, or nil when no plugin serves it.
+func (a *AuthProvider) Credentials(host string) (*registry.Credentials, error) {
+	var plug *Plugin
+	for _, p := range a.plugins {
+		if p.matchesAuthHost(host) {
+			plug = p
+			break
+		}
+	}
+	if plug == nil {
+		return nil, nil
+	}
+
+	authCache.Lock()
+	defer authCache.Unlock()
+
+	key := plug.Dir + "\x00" + host
+	if creds, ok := authCache.creds[key]; ok {
+		if creds.ExpiresAt.IsZero() || a.now().Add(authExpiryMargin).Before(creds.ExpiresAt) {
+			return creds, nil
+		}
+		delete(authCache.creds, key)
+	}
+
+	creds, err := plug.Credentials(host, a.limits)
+	if err != nil {
+		return nil, err
+	}
+	authCache.creds[key] = creds
+	return creds, nil
+}
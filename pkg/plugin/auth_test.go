@@ -0,0 +1,125 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin // import "helm.sh/helm/v4/pkg/plugin"
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// authScript answers with a token that counts the invocations of the plugin,
+// and with an error for hosts starting with "fail.".
+const authScript = `#!/bin/sh
+read -r request
+case "$request" in
+  *'"host":"fail.'*) echo '{"error":"no credentials"}'; exit 0 ;;
+esac
+echo x >> "$HELM_PLUGIN_DIR/calls"
+printf '{"token":"token-%s","expiresAt":"2030-01-01T00:00:00Z"}' "$(wc -l < "$HELM_PLUGIN_DIR/calls" | tr -d ' ')"
+`
+
+func authPlugin(t *testing.T) *Plugin {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "auth.sh"), []byte(authScript), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return &Plugin{
+		Dir: dir,
+		Metadata: &Metadata{
+			Name:      "auth",
+			Type:      TypeAuth,
+			Command:   "sh $HELM_PLUGIN_DIR/auth.sh",
+			AuthHosts: []string{"*.example.com", "fail.example.org"},
+		},
+	}
+}
+
+func TestValidateAuth(t *testing.T) {
+	tests := []struct {
+		name string
+		md   *Metadata
+		err  string
+	}{
+		{"not an auth plugin", &Metadata{Name: "a"}, ""},
+		{"hosts on command plugin", &Metadata{Name: "a", AuthHosts: []string{"example.com"}}, "only supported by auth/v1 plugins"},
+		{"no hosts", &Metadata{Name: "a", Type: TypeAuth}, "does not declare any authHosts"},
+		{"empty pattern", &Metadata{Name: "a", Type: TypeAuth, AuthHosts: []string{""}}, "invalid auth host pattern"},
+		{"bad pattern", &Metadata{Name: "a", Type: TypeAuth, AuthHosts: []string{"[example.com"}}, "invalid auth host pattern"},
+		{"valid", &Metadata{Name: "a", Type: TypeAuth, AuthHosts: []string{"*.example.com", "registry.local:5000"}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAuth(tt.md, "plugin.yaml")
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestAuthProvider(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test plugin requires a POSIX shell")
+	}
+
+	plug := authPlugin(t)
+	provider := NewAuthProvider([]*Plugin{mockPlugin("other"), plug}, DefaultExecLimits)
+	if provider.Empty() {
+		t.Fatal("expected the provider to have an auth plugin")
+	}
+	now := time.Date(2029, 12, 31, 0, 0, 0, 0, time.UTC)
+	provider.now = func() time.Time { return now }
+
+	creds, err := provider.Credentials("unknown.org")
+	if err != nil || creds != nil {
+		t.Fatalf("expected no credentials for an unserved host, got %v, %v", creds, err)
+	}
+
+	creds, err = provider.Credentials("charts.EXAMPLE.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.Token != "token-1" {
+		t.Errorf("expected token-1, got %q", creds.Token)
+	}
+	if got := creds.AuthorizationHeader(); got != "Bearer token-1" {
+		t.Errorf("unexpected authorization header %q", got)
+	}
+
+	// Cached until shortly before the credentials expire.
+	if creds, _ = provider.Credentials("charts.EXAMPLE.com"); creds.Token != "token-1" {
+		t.Errorf("expected the cached token, got %q", creds.Token)
+	}
+	now = time.Date(2029, 12, 31, 23, 59, 45, 0, time.UTC)
+	if creds, _ = provider.Credentials("charts.EXAMPLE.com"); creds.Token != "token-2" {
+		t.Errorf("expected a renewed token, got %q", creds.Token)
+	}
+
+	if _, err := provider.Credentials("fail.example.org"); err == nil || err.Error() != `auth plugin "auth": no credentials` {
+		t.Errorf("expected plugin error, got %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin // import "helm.sh/helm/v4/pkg/plugin"
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Dependency declares another plugin that a plugin requires, e.g. a getter
+// used by a downloader.
+type Dependency struct {
+	// Name is the name of the required plugin.
+	Name string `json:"name"`
+	// Version is a SemVer constraint on the version of the required plugin,
+	// e.g. ">=1.2.0 <2.0.0". An empty constraint matches any version.
+	Version string `json:"version,omitempty"`
+}
+
+// DependencyError reports the plugins whose dependencies cannot be satisfied.
+type DependencyError struct {
+	// Problems maps the names of the plugins that cannot be loaded to the
+	// reasons why.
+	Problems map[string][]string
+}
+
+func (e *DependencyError) Error() string {
+	names := make([]string, 0, len(e.Problems))
+	for name := range e.Problems {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("unsatisfiable plugin dependencies:")
+	for _, name := range names {
+		for _, problem := range e.Problems[name] {
+			fmt.Fprintf(&b, "\n- %s", problem)
+		}
+	}
+	return b.String()
+}
+
+func (e *DependencyError) add(name, format string, args ...interface{}) {
+	e.Problems[name] = append(e.Problems[name], fmt.Sprintf(format, args...))
+}
+
+// validateDependencies validates the dependency declarations of a plugin.
+func validateDependencies(md *Metadata, filepath string) error {
+	seen := map[string]bool{}
+	for _, dep := range md.Dependencies {
+		if !validPluginName.MatchString(dep.Name) {
+			return fmt.Errorf("invalid dependency name %q in %q", dep.Name, filepath)
+		}
+		if dep.Name == md.Name {
+			return fmt.Errorf("plugin depends on itself in %q", filepath)
+		}
+		if seen[dep.Name] {
+			return fmt.Errorf("dependency %q is declared more than once in %q", dep.Name, filepath)
+		}
+		seen[dep.Name] = true
+		if dep.Version != "" {
+			if _, err := semver.NewConstraint(dep.Version); err != nil {
+				return fmt.Errorf("invalid version constraint %q for dependency %q in %q: %s", dep.Version, dep.Name, filepath, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ResolveDependencies checks the dependencies of the plugins against each
+// other. It returns the plugins whose dependencies are satisfied, ordered so
+// that every plugin comes after the plugins it depends on.
+//
+// A plugin is left out when one of its dependencies is missing, does not
+// match the version constraint, is part of a dependency cycle or is itself
+// left out. The reasons are reported with a *DependencyError.
+func ResolveDependencies(plugins []*Plugin) ([]*Plugin, error) {
+	byName := make(map[string]*Plugin, len(plugins))
+	names := make([]string, 0, len(plugins))
+	for _, p := range plugins {
+		byName[p.Metadata.Name] = p
+		names = append(names, p.Metadata.Name)
+	}
+	sort.Strings(names)
+
+	const (
+		visiting = iota + 1
+		done
+	)
+	state := map[string]int{}
+	usable := map[string]bool{}
+	derr := &DependencyError{Problems: map[string][]string{}}
+	var resolved []*Plugin
+	var stack []string
+
+	var resolve func(name string) bool
+	resolve = func(name string) bool {
+		if state[name] == done {
+			return usable[name]
+		}
+
+		state[name] = visiting
+		stack = append(stack, name)
+		p := byName[name]
+		ok := true
+		for _, dep := range p.Metadata.Dependencies {
+			target, found := byName[dep.Name]
+			if !found {
+				derr.add(name, "plugin %q requires plugin %q, which is not installed", name, dep.Name)
+				ok = false
+				continue
+			}
+			if err := checkDependencyVersion(dep, target); err != nil {
+				derr.add(name, "plugin %q requires plugin %q %s: %s", name, dep.Name, dep.Version, err)
+				ok = false
+				continue
+			}
+			if state[dep.Name] == visiting {
+				derr.add(name, "plugin %q is part of a dependency cycle: %s", name, cyclePath(stack, dep.Name))
+				ok = false
+				continue
+			}
+			if !resolve(dep.Name) {
+				derr.add(name, "plugin %q requires plugin %q, which cannot be loaded", name, dep.Name)
+				ok = false
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = done
+		usable[name] = ok
+		if ok {
+			resolved = append(resolved, p)
+		}
+		return ok
+	}
+
+	for _, name := range names {
+		resolve(name)
+	}
+
+	if len(derr.Problems) > 0 {
+		return resolved, derr
+	}
+	return resolved, nil
+}
+
+// CheckDependencies checks that the dependencies of a plugin are satisfied by
+// the given plugins. It is meant to be called when a plugin is installed.
+func CheckDependencies(p *Plugin, plugins []*Plugin) error {
+	all := []*Plugin{p}
+	for _, other := range plugins {
+		if other.Metadata.Name != p.Metadata.Name {
+			all = append(all, other)
+		}
+	}
+	_, err := ResolveDependencies(all)
+	derr, ok := err.(*DependencyError)
+	if !ok || len(derr.Problems[p.Metadata.Name]) == 0 {
+		return nil
+	}
+	return &DependencyError{Problems: map[string][]string{p.Metadata.Name: derr.Problems[p.Metadata.Name]}}
+}
+
+func checkDependencyVersion(dep Dependency, target *Plugin) error {
+	if dep.Version == "" {
+		return nil
+	}
+	c, err := semver.NewConstraint(dep.Version)
+	if err != nil {
+		return err
+	}
+	v, err := semver.NewVersion(target.Metadata.Version)
+	if err != nil {
+		return fmt.Errorf("installed version %q is not a valid SemVer version", target.Metadata.Version)
+	}
+	if !c.Check(v) {
+		return fmt.Errorf("installed version is %s", target.Metadata.Version)
+	}
+	return nil
+}
+
+// cyclePath formats the cycle closed by a dependency on name, e.g. "a -> b -> a".
+func cyclePath(stack []string, name string) string {
+	for i, n := range stack {
+		if n == name {
+			return strings.Join(append(append([]string{}, stack[i:]...), name), " -> ")
+		}
+	}
+	return name
+}
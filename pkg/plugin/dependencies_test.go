@@ -0,0 +1,131 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin // import "helm.sh/helm/v4/pkg/plugin"
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func dependentPlugin(name, version string, deps ...Dependency) *Plugin {
+	p := mockPlugin(name)
+	p.Metadata.Version = version
+	p.Metadata.Dependencies = deps
+	return p
+}
+
+func pluginNames(plugins []*Plugin) []string {
+	names := []string{}
+	for _, p := range plugins {
+		names = append(names, p.Metadata.Name)
+	}
+	return names
+}
+
+func TestResolveDependencies(t *testing.T) {
+	plugins := []*Plugin{
+		dependentPlugin("downloader", "1.0.0", Dependency{Name: "getter", Version: "^2.1.0"}),
+		dependentPlugin("getter", "2.3.0", Dependency{Name: "auth"}),
+		dependentPlugin("auth", "0.1.0"),
+		dependentPlugin("standalone", "1.0.0"),
+	}
+
+	resolved, err := ResolveDependencies(plugins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{"auth", "getter", "downloader", "standalone"}
+	if got := pluginNames(resolved); !reflect.DeepEqual(got, expect) {
+		t.Errorf("expected %v, got %v", expect, got)
+	}
+}
+
+func TestResolveDependenciesUnsatisfiable(t *testing.T) {
+	plugins := []*Plugin{
+		dependentPlugin("downloader", "1.0.0", Dependency{Name: "getter", Version: ">=3.0.0"}),
+		dependentPlugin("getter", "2.3.0"),
+		dependentPlugin("uploader", "1.0.0", Dependency{Name: "missing"}),
+		dependentPlugin("wrapper", "1.0.0", Dependency{Name: "uploader"}),
+		dependentPlugin("cycle-a", "1.0.0", Dependency{Name: "cycle-b"}),
+		dependentPlugin("cycle-b", "1.0.0", Dependency{Name: "cycle-a"}),
+	}
+
+	resolved, err := ResolveDependencies(plugins)
+	if got := pluginNames(resolved); !reflect.DeepEqual(got, []string{"getter"}) {
+		t.Errorf("expected only getter to be resolved, got %v", got)
+	}
+	derr, ok := err.(*DependencyError)
+	if !ok {
+		t.Fatalf("expected a *DependencyError, got %v", err)
+	}
+
+	for name, expect := range map[string]string{
+		"downloader": `plugin "downloader" requires plugin "getter" >=3.0.0: installed version is 2.3.0`,
+		"uploader":   `plugin "uploader" requires plugin "missing", which is not installed`,
+		"wrapper":    `plugin "wrapper" requires plugin "uploader", which cannot be loaded`,
+		"cycle-b":    `plugin "cycle-b" is part of a dependency cycle: cycle-a -> cycle-b -> cycle-a`,
+		"cycle-a":    `plugin "cycle-a" requires plugin "cycle-b", which cannot be loaded`,
+	} {
+		if got := strings.Join(derr.Problems[name], "\n"); got != expect {
+			t.Errorf("%s: expected %q, got %q", name, expect, got)
+		}
+	}
+	if !strings.HasPrefix(err.Error(), "unsatisfiable plugin dependencies:\n- ") {
+		t.Errorf("unexpected error message %q", err)
+	}
+}
+
+func TestCheckDependencies(t *testing.T) {
+	installed := []*Plugin{
+		dependentPlugin("getter", "2.3.0"),
+		dependentPlugin("broken", "1.0.0", Dependency{Name: "missing"}),
+	}
+
+	if err := CheckDependencies(dependentPlugin("downloader", "1.0.0", Dependency{Name: "getter", Version: "~2.3"}), installed); err != nil {
+		t.Errorf("expected dependencies to be satisfied, got %s", err)
+	}
+
+	err := CheckDependencies(dependentPlugin("downloader", "1.0.0", Dependency{Name: "getter", Version: "<2.0.0"}), installed)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if strings.Contains(err.Error(), "broken") {
+		t.Errorf("expected only the problems of the checked plugin, got %q", err)
+	}
+}
+
+func TestValidateDependencies(t *testing.T) {
+	for i, item := range []struct {
+		pass bool
+		deps []Dependency
+	}{
+		{true, []Dependency{{Name: "getter"}, {Name: "auth", Version: ">=1.0.0 <2.0.0"}}},
+		{false, []Dependency{{Name: "bad name"}}},
+		{false, []Dependency{{Name: "foo"}}},
+		{false, []Dependency{{Name: "getter"}, {Name: "getter"}}},
+		{false, []Dependency{{Name: "getter", Version: "not-a-constraint"}}},
+	} {
+		plug := mockPlugin("foo")
+		plug.Metadata.Dependencies = item.deps
+		err := validatePluginData(plug, "test")
+		if item.pass && err != nil {
+			t.Errorf("failed to validate case %d: %s", i, err)
+		} else if !item.pass && err == nil {
+			t.Errorf("expected case %d to fail", i)
+		}
+	}
+}
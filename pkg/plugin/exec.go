@@ -0,0 +1,145 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin // import "helm.sh/helm/v4/pkg/plugin"
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ExecLimits bounds the resources used by a single invocation of a plugin
+// that exchanges JSON with Helm, such as an auth plugin.
+type ExecLimits struct {
+	// Timeout is the maximum duration of an invocation.
+	Timeout time.Duration
+	// MaxOutputBytes is the maximum size of the response of an invocation.
+	MaxOutputBytes int64
+}
+
+// DefaultExecLimits are the limits used when none are given.
+var DefaultExecLimits = ExecLimits{
+	Timeout:        5 * time.Second,
+	MaxOutputBytes: 1 << 20,
+}
+
+// isolatedCommand is a plugin command that runs in the plugin directory with
+// an environment that only contains PATH, HELM_PLUGIN_NAME and
+// HELM_PLUGIN_DIR.
+type isolatedCommand struct {
+	dir     string
+	main    string
+	argv    []string
+	environ []string
+}
+
+func (p *Plugin) isolatedCommand() (*isolatedCommand, error) {
+	cmds := p.Metadata.PlatformCommand
+	if len(cmds) == 0 && len(p.Metadata.Command) > 0 {
+		cmds = []PlatformCommand{{Command: p.Metadata.Command}}
+	}
+	main, argv, err := PrepareCommands(cmds, false, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "plugin %q", p.Metadata.Name)
+	}
+
+	dir, err := filepath.Abs(p.Dir)
+	if err != nil {
+		return nil, err
+	}
+	env := map[string]string{
+		"HELM_PLUGIN_NAME": p.Metadata.Name,
+		"HELM_PLUGIN_DIR":  dir,
+		"PATH":             os.Getenv("PATH"),
+	}
+	expand := func(s string) string {
+		return os.Expand(s, func(k string) string { return env[k] })
+	}
+	main = expand(main)
+	for i := range argv {
+		argv[i] = expand(argv[i])
+	}
+	environ := make([]string, 0, len(env))
+	for k, v := range env {
+		environ = append(environ, k+"="+v)
+	}
+	return &isolatedCommand{dir: dir, main: main, argv: argv, environ: environ}, nil
+}
+
+// run runs the command with the input on its standard input and returns its
+// standard output. The command is stopped when it exceeds the limits.
+func (c *isolatedCommand) run(limits ExecLimits, in []byte) ([]byte, error) {
+	ctx := context.Background()
+	if limits.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limits.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, c.main, c.argv...)
+	cmd.Dir = c.dir
+	cmd.Env = c.environ
+	cmd.Stdin = bytes.NewReader(in)
+	stdout := &limitedBuffer{limit: limits.MaxOutputBytes}
+	stderr := &limitedBuffer{limit: 4096, truncate: true}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, errors.Errorf("timed out after %s", limits.Timeout)
+	}
+	if stdout.exceeded {
+		return nil, errors.Errorf("output exceeded the limit of %d bytes", limits.MaxOutputBytes)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "plugin failed: %s", strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// limitedBuffer is a buffer that stops accepting data once it reaches its
+// limit. A limit of zero or less means no limit. When truncate is set, data
+// over the limit is silently dropped instead of failing the write.
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	limit    int64
+	truncate bool
+	exceeded bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.limit > 0 && int64(b.buf.Len()+len(p)) > b.limit {
+		b.exceeded = true
+		if b.truncate {
+			b.buf.Write(p[:b.limit-int64(b.buf.Len())])
+			return len(p), nil
+		}
+		return 0, io.ErrShortWrite
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) Bytes() []byte { return b.buf.Bytes() }
+
+func (b *limitedBuffer) String() string { return b.buf.String() }
@@ -15,6 +15,15 @@ limitations under the License.
 
 package plugin // import "helm.sh/helm/v4/pkg/plugin"
 
+import (
+	"os"
+	"os/exec"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/pkg/cli"
+)
+
 // Types of hooks
 const (
 	// Install is executed after the plugin is added.
@@ -30,3 +39,35 @@ type PlatformHooks map[string][]PlatformCommand
 
 // Hooks is a map of events to commands.
 type Hooks map[string]string
+
+// RunHook executes the hook of a plugin for the given event, if any. The
+// output of the hook goes to the standard output and error of the process.
+func RunHook(settings *cli.EnvSettings, p *Plugin, event string) error {
+	SetupPluginEnv(settings, p.Metadata.Name, p.Dir)
+
+	cmds := p.Metadata.PlatformHooks[event]
+	expandArgs := true
+	if len(cmds) == 0 && len(p.Metadata.Hooks) > 0 {
+		cmd := p.Metadata.Hooks[event]
+		if len(cmd) > 0 {
+			cmds = []PlatformCommand{{Command: "sh", Args: []string{"-c", cmd}}}
+			expandArgs = false
+		}
+	}
+
+	main, argv, err := PrepareCommands(cmds, expandArgs, []string{})
+	if err != nil {
+		return nil
+	}
+
+	prog := exec.Command(main, argv...)
+	prog.Stdout, prog.Stderr = os.Stdout, os.Stderr
+	if err := prog.Run(); err != nil {
+		if eerr, ok := err.(*exec.ExitError); ok {
+			os.Stderr.Write(eerr.Stderr)
+			return errors.Errorf("plugin %s hook for %q exited with error", event, p.Metadata.Name)
+		}
+		return err
+	}
+	return nil
+}
@@ -19,7 +19,10 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"io"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
@@ -31,15 +34,30 @@ import (
 
 	"helm.sh/helm/v4/internal/third_party/dep/fs"
 	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/downloader"
 	"helm.sh/helm/v4/pkg/getter"
 	"helm.sh/helm/v4/pkg/helmpath"
 	"helm.sh/helm/v4/pkg/plugin/cache"
+	"helm.sh/helm/v4/pkg/provenance"
 )
 
 // HTTPInstaller installs plugins from an archive served by a web server.
 type HTTPInstaller struct {
 	CacheDir   string
 	PluginName string
+	// Checksum pins the SHA-256 of the archive, as "sha256:<hex>" or "<hex>".
+	// The plugin is not installed when the archive does not match it.
+	Checksum string
+	// Verify requires the archive to be signed. The provenance file is
+	// fetched from the source URL with a ".prov" suffix. Like the provenance
+	// file of a chart, it is a clear-signed YAML document whose "files" map
+	// holds the SHA-256 of the archive, keyed by its file name.
+	Verify bool
+	// Keyring is the keyring used to verify the provenance file.
+	Keyring string
+	// Cache, when set, stores the downloaded archives by digest. An archive
+	// pinned by Checksum is read from it instead of being downloaded again.
+	Cache downloader.Cache
 	base
 	extractor Extractor
 	getter    getter.Getter
@@ -126,11 +144,25 @@ func stripPluginName(name string) string {
 //
 // Implements Installer.
 func (i *HTTPInstaller) Install() error {
-	pluginData, err := i.getter.Get(i.Source)
+	pluginData, cached, err := i.download()
 	if err != nil {
 		return err
 	}
 
+	if err := i.checkChecksum(pluginData.Bytes()); err != nil {
+		return err
+	}
+	if i.Cache != nil && !cached {
+		if _, err := i.Cache.Put(sha256.Sum256(pluginData.Bytes()), pluginData.Bytes(), downloader.CachePlugin); err != nil {
+			debug("unable to cache %s: %s", i.Source, err)
+		}
+	}
+	if i.Verify {
+		if err := i.verify(pluginData.Bytes()); err != nil {
+			return err
+		}
+	}
+
 	if err := i.extractor.Extract(pluginData, i.CacheDir); err != nil {
 		return errors.Wrap(err, "extracting files from archive")
 	}
@@ -148,6 +180,74 @@ func (i *HTTPInstaller) Install() error {
 	return fs.CopyDir(src, i.Path())
 }
 
+// download returns the archive, from the cache when it is pinned by a
+// checksum and cached.
+func (i *HTTPInstaller) download() (*bytes.Buffer, bool, error) {
+	if i.Cache != nil && i.Checksum != "" {
+		if key, err := downloader.ParseCacheKey(i.Checksum); err == nil {
+			if p, err := i.Cache.Get(key, downloader.CachePlugin); err == nil {
+				if data, err := os.ReadFile(p); err == nil {
+					return bytes.NewBuffer(data), true, nil
+				}
+			}
+		}
+	}
+	data, err := i.getter.Get(i.Source)
+	return data, false, err
+}
+
+// checkChecksum compares the archive to the pinned checksum, if any.
+func (i *HTTPInstaller) checkChecksum(data []byte) error {
+	if i.Checksum == "" {
+		return nil
+	}
+	sum := sha256.Sum256(data)
+	got := hex.EncodeToString(sum[:])
+	want := strings.ToLower(strings.TrimPrefix(i.Checksum, "sha256:"))
+	if got != want {
+		return errors.Errorf("checksum mismatch for %s: expected sha256:%s, got sha256:%s", i.Source, want, got)
+	}
+	return nil
+}
+
+// verify checks the archive against its provenance file.
+func (i *HTTPInstaller) verify(data []byte) error {
+	provData, err := i.getter.Get(i.Source + ".prov")
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch provenance file for %s", i.Source)
+	}
+
+	tmp, err := os.MkdirTemp("", "helm-plugin-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	// The provenance file records the digest of the archive by file name.
+	name := path.Base(i.Source)
+	if u, err := url.Parse(i.Source); err == nil {
+		name = path.Base(u.Path)
+	}
+	archive := filepath.Join(tmp, name)
+	if err := os.WriteFile(archive, data, 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(archive+".prov", provData.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	sig, err := provenance.NewFromKeyring(i.Keyring, "")
+	if err != nil {
+		return errors.Wrap(err, "failed to load keyring")
+	}
+	ver, err := sig.Verify(archive, archive+".prov")
+	if err != nil {
+		return errors.Wrapf(err, "failed to verify %s", i.Source)
+	}
+	debug("verified %s signed by %v", i.Source, ver.SignedBy.Identities)
+	return nil
+}
+
 // Update updates a local repository
 // Not implemented for now since tarball most likely will be packaged by version
 func (i *HTTPInstaller) Update() error {
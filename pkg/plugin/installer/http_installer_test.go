@@ -30,10 +30,12 @@ import (
 	"testing"
 
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/openpgp/clearsign" //nolint
 
 	"helm.sh/helm/v4/internal/test/ensure"
 	"helm.sh/helm/v4/pkg/getter"
 	"helm.sh/helm/v4/pkg/helmpath"
+	"helm.sh/helm/v4/pkg/provenance"
 )
 
 var _ Installer = new(HTTPInstaller)
@@ -127,6 +129,106 @@ func TestHTTPInstaller(t *testing.T) {
 
 }
 
+// Fake http client serving a response per URL
+type mapHTTPGetter map[string][]byte
+
+func (m mapHTTPGetter) Get(href string, _ ...getter.Option) (*bytes.Buffer, error) {
+	data, ok := m[href]
+	if !ok {
+		return nil, errors.Errorf("%s not found", href)
+	}
+	return bytes.NewBuffer(data), nil
+}
+
+// signPluginArchive returns a provenance file for the archive signed with the
+// test key.
+func signPluginArchive(t *testing.T, name string, archive []byte) []byte {
+	t.Helper()
+	signer, err := provenance.NewFromFiles("../../provenance/testdata/helm-test-key.secret", "../../provenance/testdata/helm-test-key.pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := provenance.Digest(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := fmt.Sprintf("name: fake-plugin\n\n...\nfiles:\n  %s: sha256:%s\n", name, sum)
+
+	out := &bytes.Buffer{}
+	w, err := clearsign.Encode(out, signer.Entity.PrivateKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(msg)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+func TestHTTPInstallerChecksumAndVerify(t *testing.T) {
+	ensure.HelmHome(t)
+
+	srv := mockArchiveServer()
+	defer srv.Close()
+	source := srv.URL + "/plugins/fake-plugin-0.0.1.tar.gz"
+
+	mockTgz, err := base64.StdEncoding.DecodeString(fakePluginB64)
+	if err != nil {
+		t.Fatalf("Could not decode fake tgz plugin: %s", err)
+	}
+	sum, err := provenance.Digest(bytes.NewReader(mockTgz))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prov := signPluginArchive(t, "fake-plugin-0.0.1.tar.gz", mockTgz)
+	tampered := append([]byte{}, mockTgz...)
+	tampered[len(tampered)-1] ^= 0xff
+
+	for _, tt := range []struct {
+		name      string
+		files     mapHTTPGetter
+		checksum  string
+		verify    bool
+		expectErr string
+	}{
+		{name: "checksum", files: mapHTTPGetter{source: mockTgz}, checksum: "sha256:" + sum},
+		{name: "checksum mismatch", files: mapHTTPGetter{source: mockTgz}, checksum: "sha256:0000", expectErr: "checksum mismatch"},
+		{name: "verify", files: mapHTTPGetter{source: mockTgz, source + ".prov": prov}, verify: true},
+		{name: "verify tampered", files: mapHTTPGetter{source: tampered, source + ".prov": prov}, verify: true, expectErr: "sha256 sum does not match"},
+		{name: "verify missing provenance", files: mapHTTPGetter{source: mockTgz}, verify: true, expectErr: "failed to fetch provenance file"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			i, err := NewForSource(source, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			httpInstaller := i.(*HTTPInstaller)
+			httpInstaller.getter = tt.files
+			httpInstaller.Checksum = tt.checksum
+			httpInstaller.Verify = tt.verify
+			httpInstaller.Keyring = "../../provenance/testdata/helm-test-key.pub"
+			defer os.RemoveAll(i.Path())
+
+			err = Install(i)
+			if tt.expectErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.expectErr, err)
+			}
+			if _, err := os.Stat(i.Path()); !os.IsNotExist(err) {
+				t.Errorf("expected the plugin not to be installed")
+			}
+		})
+	}
+}
+
 func TestHTTPInstallerNonExistentVersion(t *testing.T) {
 	ensure.HelmHome(t)
 	srv := mockArchiveServer()
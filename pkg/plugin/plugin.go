@@ -60,6 +60,10 @@ type Metadata struct {
 	// Version is a SemVer 2 version of the plugin.
 	Version string `json:"version"`
 
+	// Type is the type of the plugin. It is empty for plugins that add a
+	// command to Helm, or TypeAuth for plugins that provide credentials.
+	Type string `json:"type,omitempty"`
+
 	// Usage is the single-line usage text shown in help
 	Usage string `json:"usage"`
 
@@ -134,6 +138,14 @@ type Metadata struct {
 	// for special protocols.
 	Downloaders []Downloaders `json:"downloaders"`
 
+	// AuthHosts are the hosts an auth/v1 plugin provides credentials for.
+	// They are matched with path.Match, e.g. "*.azurecr.io".
+	AuthHosts []string `json:"authHosts,omitempty"`
+
+	// Dependencies are the other plugins this plugin requires. A plugin is
+	// not loaded when its dependencies are not satisfied.
+	Dependencies []Dependency `json:"dependencies,omitempty"`
+
 	// UseTunnelDeprecated indicates that this command needs a tunnel.
 	// Setting this will cause a number of side effects, such as the
 	// automatic setting of HELM_HOST.
@@ -273,6 +285,18 @@ func validatePluginData(plug *Plugin, filepath string) error {
 		return fmt.Errorf("both platformHooks and hooks are set in %q", filepath)
 	}
 
+	switch plug.Metadata.Type {
+	case "", TypeAuth:
+	default:
+		return fmt.Errorf("unknown plugin type %q in %q", plug.Metadata.Type, filepath)
+	}
+	if err := validateAuth(plug.Metadata, filepath); err != nil {
+		return err
+	}
+	if err := validateDependencies(plug.Metadata, filepath); err != nil {
+		return err
+	}
+
 	// We could also validate SemVer, executable, and other fields should we so choose.
 	return nil
 }
@@ -18,9 +18,13 @@ package postrender
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -28,6 +32,7 @@ import (
 type execRender struct {
 	binaryPath string
 	args       []string
+	policy     ExecPolicy
 }
 
 // NewExec returns a PostRenderer implementation that calls the provided binary.
@@ -35,16 +40,47 @@ type execRender struct {
 // contain any separators, it will search in $PATH, otherwise it will resolve
 // any relative paths to a fully qualified path
 func NewExec(binaryPath string, args ...string) (PostRenderer, error) {
+	return NewExecWithPolicy(ExecPolicy{}, binaryPath, args...)
+}
+
+// NewExecWithPolicy is like NewExec, but the binary runs under the given
+// execution policy. It returns a *PolicyError if the policy does not allow
+// the binary.
+//
+// When running the binary violates the policy, Run returns a *PolicyError;
+// when the binary fails, it returns a *RendererError.
+func NewExecWithPolicy(policy ExecPolicy, binaryPath string, args ...string) (PostRenderer, error) {
 	fullPath, err := getFullPath(binaryPath)
 	if err != nil {
 		return nil, err
 	}
-	return &execRender{fullPath, args}, nil
+	if err := policy.checkBinary(fullPath); err != nil {
+		return nil, err
+	}
+	return &execRender{fullPath, args, policy}, nil
 }
 
 // Run the configured binary for the post render
 func (p *execRender) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
-	cmd := exec.Command(p.binaryPath, p.args...)
+	ctx := context.Background()
+	if p.policy.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.policy.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, p.binaryPath, p.args...)
+	cmd.Env = p.policy.environ()
+	// Do not wait for the output of processes started by a killed binary.
+	cmd.WaitDelay = time.Second
+	if p.policy.IsolateWorkDir {
+		dir, err := os.MkdirTemp("", "helm-post-render-")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(dir)
+		cmd.Dir = dir
+	}
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
 		return nil, err
@@ -52,7 +88,8 @@ func (p *execRender) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error)
 
 	var postRendered = &bytes.Buffer{}
 	var stderr = &bytes.Buffer{}
-	cmd.Stdout = postRendered
+	stdout := &limitedBuffer{w: postRendered, limit: p.policy.MaxOutputBytes}
+	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 
 	go func() {
@@ -60,8 +97,14 @@ func (p *execRender) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error)
 		io.Copy(stdin, renderedManifests)
 	}()
 	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, &PolicyError{BinaryPath: p.binaryPath, Reason: fmt.Sprintf("timed out after %s", p.policy.Timeout)}
+	}
+	if stdout.exceeded {
+		return nil, &PolicyError{BinaryPath: p.binaryPath, Reason: fmt.Sprintf("output exceeded the limit of %d bytes", p.policy.MaxOutputBytes)}
+	}
 	if err != nil {
-		return nil, errors.Wrapf(err, "error while running command %s. error output:\n%s", p.binaryPath, stderr.String())
+		return nil, &RendererError{BinaryPath: p.binaryPath, Stderr: stderr.String(), Err: err}
 	}
 
 	return postRendered, nil
@@ -19,9 +19,12 @@ package postrender
 import (
 	"bytes"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -153,6 +156,91 @@ func TestNewExecWithTwoArgsRun(t *testing.T) {
 	is.Contains(output.String(), "ARG1 ARG2")
 }
 
+func TestExecPolicyAllowedPaths(t *testing.T) {
+	testpath := setupTestingScript(t)
+
+	_, err := NewExecWithPolicy(ExecPolicy{AllowedPaths: []string{filepath.Dir(testpath)}}, testpath)
+	assert.NoError(t, err)
+	_, err = NewExecWithPolicy(ExecPolicy{AllowedPaths: []string{testpath}}, testpath)
+	assert.NoError(t, err)
+
+	_, err = NewExecWithPolicy(ExecPolicy{AllowedPaths: []string{t.TempDir(), testpath + "-other"}}, testpath)
+	var perr *PolicyError
+	require.ErrorAs(t, err, &perr)
+	assert.Equal(t, testpath, perr.BinaryPath)
+	assert.Contains(t, err.Error(), "not in the allowed paths")
+}
+
+func TestExecPolicyRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping on windows")
+	}
+
+	t.Run("scrubbed environment and isolated working directory", func(t *testing.T) {
+		t.Setenv("HELM_POST_RENDER_SECRET", "secret")
+		t.Setenv("HELM_POST_RENDER_ALLOWED", "allowed")
+		testpath := writeScript(t, `cat >/dev/null; echo "secret=$HELM_POST_RENDER_SECRET allowed=$HELM_POST_RENDER_ALLOWED"; ls -A; pwd`)
+
+		renderer, err := NewExecWithPolicy(ExecPolicy{
+			ScrubEnv:       true,
+			AllowedEnv:     []string{"HELM_POST_RENDER_ALLOWED"},
+			IsolateWorkDir: true,
+		}, testpath)
+		require.NoError(t, err)
+		output, err := renderer.Run(bytes.NewBufferString("FOOTEST"))
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+		require.Len(t, lines, 2, "expected an empty working directory, got %q", output)
+		assert.Equal(t, "secret= allowed=allowed", lines[0])
+		wd, _ := os.Getwd()
+		assert.NotEqual(t, wd, lines[1])
+		assert.NoDirExists(t, lines[1], "expected the working directory to be removed")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		testpath := writeScript(t, `sleep 5`)
+		renderer, err := NewExecWithPolicy(ExecPolicy{Timeout: 100 * time.Millisecond}, testpath)
+		require.NoError(t, err)
+
+		_, err = renderer.Run(bytes.NewBufferString("FOOTEST"))
+		var perr *PolicyError
+		require.ErrorAs(t, err, &perr)
+		assert.Equal(t, "timed out after 100ms", perr.Reason)
+	})
+
+	t.Run("output limit", func(t *testing.T) {
+		renderer, err := NewExecWithPolicy(ExecPolicy{MaxOutputBytes: 4}, setupTestingScript(t))
+		require.NoError(t, err)
+
+		_, err = renderer.Run(bytes.NewBufferString("FOOTEST"))
+		var perr *PolicyError
+		require.ErrorAs(t, err, &perr)
+		assert.Equal(t, "output exceeded the limit of 4 bytes", perr.Reason)
+	})
+
+	t.Run("renderer failure", func(t *testing.T) {
+		testpath := writeScript(t, `echo broken >&2; exit 3`)
+		renderer, err := NewExecWithPolicy(ExecPolicy{Timeout: 5 * time.Second}, testpath)
+		require.NoError(t, err)
+
+		_, err = renderer.Run(bytes.NewBufferString("FOOTEST"))
+		var rerr *RendererError
+		require.ErrorAs(t, err, &rerr)
+		assert.Equal(t, "broken\n", rerr.Stderr)
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 3, exitErr.ExitCode())
+	})
+}
+
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "post-render.sh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755))
+	return path
+}
+
 func setupTestingScript(t *testing.T) (filepath string) {
 	t.Helper()
 
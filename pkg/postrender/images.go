@@ -0,0 +1,280 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postrender
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/releaseutil"
+)
+
+// ImageOverrides is a PostRenderer that rewrites the container images of
+// rendered workloads, e.g. to pull them from a mirror in an air-gapped
+// environment without relying on every chart to expose its images as values.
+//
+// Images are found in the containers, initContainers and ephemeralContainers
+// lists of any resource, which covers pods, the workload controllers and
+// custom resources embedding a pod template. Documents with rewritten images
+// are re-encoded, which drops their comments apart from the leading ones such
+// as the "# Source:" header. The zero value leaves the manifests unchanged.
+type ImageOverrides struct {
+	// Registries maps a registry, optionally followed by a repository path,
+	// to its replacement, e.g. "docker.io" to "mirror.example.com/dockerhub".
+	// Images are normalized before matching, so "nginx" is matched as
+	// "docker.io/library/nginx", and a prefix without a registry, e.g.
+	// "bitnami", refers to docker.io. The longest matching prefix wins.
+	Registries map[string]string
+	// Digests pins images to digests, e.g. "nginx:1.27" to "sha256:...".
+	// Images are matched before the registries are rewritten, and normalized
+	// like Registries with the "latest" tag as default. Images that already
+	// carry a digest are not pinned.
+	Digests map[string]string
+}
+
+// IsZero reports whether the overrides leave all images unchanged.
+func (o *ImageOverrides) IsZero() bool {
+	return o == nil || (len(o.Registries) == 0 && len(o.Digests) == 0)
+}
+
+// Run implements PostRenderer.
+func (o *ImageOverrides) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	if o.IsZero() {
+		return renderedManifests, nil
+	}
+	out, err := o.Manifest(renderedManifests.String())
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewBufferString(out), nil
+}
+
+// Manifest rewrites the images of every document of a multi-document
+// manifest. Documents without rewritten images are kept as they are, and a
+// manifest without rewritten images is returned unchanged.
+func (o *ImageOverrides) Manifest(manifest string) (string, error) {
+	if o.IsZero() {
+		return manifest, nil
+	}
+	docs := releaseutil.SplitDocuments(manifest)
+	if len(docs) == 0 {
+		return manifest, nil
+	}
+	changed := false
+	for i, doc := range docs {
+		out, err := o.Document(doc)
+		if err != nil {
+			return manifest, err
+		}
+		changed = changed || out != doc
+		docs[i] = out
+	}
+	if !changed {
+		return manifest, nil
+	}
+	return "---\n" + strings.Join(docs, "\n---\n") + "\n", nil
+}
+
+// Document rewrites the images of a single YAML document, such as the
+// manifest of a hook. A document without rewritten images is returned
+// unchanged.
+func (o *ImageOverrides) Document(doc string) (string, error) {
+	if o.IsZero() {
+		return doc, nil
+	}
+	header, body := leadingComments(doc)
+	var obj map[string]interface{}
+	if err := yaml.Unmarshal([]byte(body), &obj); err != nil {
+		return doc, errors.Wrap(err, "unable to parse manifest to override images")
+	}
+	changed, err := o.walk(obj)
+	if err != nil || !changed {
+		return doc, err
+	}
+	out, err := yaml.Marshal(obj)
+	if err != nil {
+		return doc, err
+	}
+	return header + strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// walk rewrites the images of the container lists found in obj.
+func (o *ImageOverrides) walk(obj interface{}) (bool, error) {
+	changed := false
+	switch v := obj.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			switch key {
+			case "containers", "initContainers", "ephemeralContainers":
+				if list, ok := val.([]interface{}); ok {
+					c, err := o.containers(list)
+					if err != nil {
+						return changed, err
+					}
+					changed = changed || c
+					continue
+				}
+			}
+			c, err := o.walk(val)
+			if err != nil {
+				return changed, err
+			}
+			changed = changed || c
+		}
+	case []interface{}:
+		for _, val := range v {
+			c, err := o.walk(val)
+			if err != nil {
+				return changed, err
+			}
+			changed = changed || c
+		}
+	}
+	return changed, nil
+}
+
+func (o *ImageOverrides) containers(list []interface{}) (bool, error) {
+	changed := false
+	for _, item := range list {
+		container, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		image, ok := container["image"].(string)
+		if !ok || image == "" {
+			continue
+		}
+		newImage, err := o.Image(image)
+		if err != nil {
+			return changed, err
+		}
+		if newImage != image {
+			container["image"] = newImage
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// Image returns the image with the overrides applied. An image that no
+// override applies to is returned unchanged.
+func (o *ImageOverrides) Image(image string) (string, error) {
+	if o.IsZero() {
+		return image, nil
+	}
+	ref, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return image, errors.Wrapf(err, "invalid image %q", image)
+	}
+
+	var named reference.Named = ref
+	changed := false
+	if prefix, repl, ok := o.registry(ref.Name()); ok {
+		named, err = reference.ParseNormalizedNamed(repl + ref.Name()[len(prefix):])
+		if err != nil {
+			return image, errors.Wrapf(err, "invalid image registry override %q for %q", repl, prefix)
+		}
+		changed = true
+	}
+	if tagged, ok := ref.(reference.Tagged); ok {
+		if named, err = reference.WithTag(named, tagged.Tag()); err != nil {
+			return image, err
+		}
+	}
+	if digested, ok := ref.(reference.Digested); ok {
+		if named, err = reference.WithDigest(named, digested.Digest()); err != nil {
+			return image, err
+		}
+	} else if d, ok := o.digest(ref); ok {
+		if named, err = reference.WithDigest(named, digest.Digest(d)); err != nil {
+			return image, errors.Wrapf(err, "invalid digest %q for image %q", d, image)
+		}
+		changed = true
+	}
+	if !changed {
+		return image, nil
+	}
+	return named.String(), nil
+}
+
+// registry returns the longest registry prefix matching the name and its
+// replacement.
+func (o *ImageOverrides) registry(name string) (string, string, bool) {
+	var prefix, repl string
+	for from, to := range o.Registries {
+		from = normalizePrefix(from)
+		if name != from && !strings.HasPrefix(name, from+"/") {
+			continue
+		}
+		if len(from) > len(prefix) {
+			prefix, repl = from, strings.TrimSuffix(to, "/")
+		}
+	}
+	return prefix, repl, prefix != ""
+}
+
+// normalizePrefix qualifies a registry prefix without a registry with the
+// default registry, like the image names it is matched against. Unlike image
+// names, a single path component is not moved to the "library" namespace.
+func normalizePrefix(prefix string) string {
+	prefix = strings.TrimSuffix(prefix, "/")
+	first, _, _ := strings.Cut(prefix, "/")
+	if strings.ContainsAny(first, ".:") || first == "localhost" {
+		return prefix
+	}
+	return "docker.io/" + prefix
+}
+
+func (o *ImageOverrides) digest(ref reference.Named) (string, bool) {
+	name := reference.TagNameOnly(ref).String()
+	for image, d := range o.Digests {
+		key, err := reference.ParseNormalizedNamed(image)
+		if err != nil {
+			continue
+		}
+		if reference.TagNameOnly(key).String() == name {
+			return d, true
+		}
+	}
+	return "", false
+}
+
+// leadingComments separates the leading blank and comment lines of a document
+// from its body.
+func leadingComments(doc string) (string, string) {
+	i := 0
+	for i < len(doc) {
+		end := strings.IndexByte(doc[i:], '\n')
+		if end < 0 {
+			end = len(doc) - i
+		}
+		line := strings.TrimSpace(doc[i : i+end])
+		if line != "" && !strings.HasPrefix(line, "#") {
+			break
+		}
+		i += end + 1
+	}
+	if i > len(doc) {
+		i = len(doc)
+	}
+	return doc[:i], doc[i:]
+}
@@ -0,0 +1,164 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postrender
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestImageOverridesImage(t *testing.T) {
+	o := &ImageOverrides{
+		Registries: map[string]string{
+			"docker.io":         "mirror.example.com/dockerhub",
+			"docker.io/bitnami": "mirror.example.com/bitnami/",
+			"quay.io/":          "mirror.example.com/quay",
+		},
+		Digests: map[string]string{
+			"nginx:1.27":            testDigest,
+			"registry.k8s.io/pause": testDigest,
+		},
+	}
+
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{"nginx:1.27", "mirror.example.com/dockerhub/library/nginx:1.27@" + testDigest},
+		{"docker.io/library/nginx:1.26", "mirror.example.com/dockerhub/library/nginx:1.26"},
+		{"bitnami/redis:7", "mirror.example.com/bitnami/redis:7"},
+		{"quay.io/prometheus/prometheus@" + testDigest, "mirror.example.com/quay/prometheus/prometheus@" + testDigest},
+		{"registry.k8s.io/pause", "registry.k8s.io/pause@" + testDigest},
+		{"registry.k8s.io/pause:3.9", "registry.k8s.io/pause:3.9"},
+		{"quay.io.example.com/app:1", "quay.io.example.com/app:1"},
+		{"bitnami/redis-sentinel:7", "mirror.example.com/bitnami/redis-sentinel:7"},
+		{"bitnamilegacy/redis:7", "mirror.example.com/dockerhub/bitnamilegacy/redis:7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			got, err := o.Image(tt.image)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := o.Image("Invalid:Image")
+	assert.Error(t, err)
+
+	_, err = (&ImageOverrides{Digests: map[string]string{"nginx": "sha256:bad"}}).Image("nginx")
+	assert.ErrorContains(t, err, `invalid digest "sha256:bad"`)
+}
+
+func TestImageOverridesRun(t *testing.T) {
+	manifests := `---
+# Source: chart/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  image: nginx:1.27
+---
+# Source: chart/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox
+      containers:
+      - name: web
+        image: nginx:1.27
+---
+# Source: chart/templates/cronjob.yaml
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: job
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: job
+            image: quay.io/app/job:2
+`
+	want := `---
+# Source: chart/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  image: nginx:1.27
+---
+# Source: chart/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - image: mirror.example.com/library/nginx:1.27@` + testDigest + `
+        name: web
+      initContainers:
+      - image: mirror.example.com/library/busybox
+        name: init
+---
+# Source: chart/templates/cronjob.yaml
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: job
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: job
+            image: quay.io/app/job:2
+`
+	o := &ImageOverrides{
+		Registries: map[string]string{"docker.io": "mirror.example.com"},
+		Digests:    map[string]string{"nginx:1.27": testDigest},
+	}
+	got, err := o.Run(bytes.NewBufferString(manifests))
+	require.NoError(t, err)
+	assert.Equal(t, want, got.String())
+
+	o = &ImageOverrides{Registries: map[string]string{"ghcr.io": "mirror.example.com"}}
+	got, err = o.Run(bytes.NewBufferString(manifests))
+	require.NoError(t, err)
+	assert.Equal(t, manifests, got.String(), "manifests without overridden images must not change")
+
+	got, err = (&ImageOverrides{}).Run(bytes.NewBufferString(manifests))
+	require.NoError(t, err)
+	assert.Equal(t, manifests, got.String())
+}
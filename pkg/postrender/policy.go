@@ -0,0 +1,139 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postrender
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ExecPolicy restricts how an exec post-renderer runs. The zero value
+// imposes no restrictions.
+type ExecPolicy struct {
+	// AllowedPaths lists the binaries that may be run. An entry is either the
+	// path of a binary or a directory whose binaries may be run. Paths are
+	// compared after resolving symlinks. An empty list allows any binary.
+	AllowedPaths []string
+	// ScrubEnv runs the binary with an environment that only contains the
+	// variables named in AllowedEnv, instead of the environment of Helm.
+	ScrubEnv bool
+	// AllowedEnv are the names of the environment variables passed to the
+	// binary when ScrubEnv is set, e.g. PATH and HOME.
+	AllowedEnv []string
+	// IsolateWorkDir runs the binary in an empty temporary directory that is
+	// removed afterwards, instead of the working directory of Helm.
+	IsolateWorkDir bool
+	// Timeout is the maximum duration of a run. Zero means no timeout.
+	Timeout time.Duration
+	// MaxOutputBytes is the maximum size of the post-rendered manifests. Zero
+	// means no limit.
+	MaxOutputBytes int64
+}
+
+// PolicyError reports that an exec post-renderer violated its ExecPolicy.
+type PolicyError struct {
+	// BinaryPath is the path of the post-renderer binary.
+	BinaryPath string
+	// Reason describes the violation.
+	Reason string
+}
+
+func (e *PolicyError) Error() string {
+	return fmt.Sprintf("post-renderer %s violates the execution policy: %s", e.BinaryPath, e.Reason)
+}
+
+// RendererError reports that an exec post-renderer failed on its own, e.g.
+// exited with a non-zero status.
+type RendererError struct {
+	// BinaryPath is the path of the post-renderer binary.
+	BinaryPath string
+	// Stderr is the error output of the binary.
+	Stderr string
+	// Err is the error returned when running the binary.
+	Err error
+}
+
+func (e *RendererError) Error() string {
+	return fmt.Sprintf("error while running command %s. error output:\n%s: %s", e.BinaryPath, e.Stderr, e.Err)
+}
+
+func (e *RendererError) Unwrap() error {
+	return e.Err
+}
+
+// checkBinary returns a *PolicyError when the policy does not allow the
+// binary.
+func (p ExecPolicy) checkBinary(binaryPath string) error {
+	if len(p.AllowedPaths) == 0 {
+		return nil
+	}
+	resolved, err := filepath.EvalSymlinks(binaryPath)
+	if err != nil {
+		return err
+	}
+	for _, allowed := range p.AllowedPaths {
+		allowed, err := filepath.Abs(allowed)
+		if err != nil {
+			continue
+		}
+		if r, err := filepath.EvalSymlinks(allowed); err == nil {
+			allowed = r
+		}
+		if resolved == allowed || strings.HasPrefix(resolved, allowed+string(filepath.Separator)) {
+			return nil
+		}
+	}
+	return &PolicyError{BinaryPath: binaryPath, Reason: "the binary is not in the allowed paths"}
+}
+
+// environ returns the environment of the binary, nil meaning the environment
+// of Helm.
+func (p ExecPolicy) environ() []string {
+	if !p.ScrubEnv {
+		return nil
+	}
+	env := []string{}
+	for _, name := range p.AllowedEnv {
+		if v, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+v)
+		}
+	}
+	return env
+}
+
+// limitedBuffer is a writer that fails once the size of its content would
+// exceed the limit. A limit of zero or less means no limit.
+type limitedBuffer struct {
+	w        io.Writer
+	written  int64
+	limit    int64
+	exceeded bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.limit > 0 && b.written+int64(len(p)) > b.limit {
+		b.exceeded = true
+		return 0, io.ErrShortWrite
+	}
+	n, err := b.w.Write(p)
+	b.written += int64(n)
+	return n, err
+}
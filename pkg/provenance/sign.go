@@ -258,8 +258,25 @@ func (s *Signatory) Verify(chartpath, sigpath string) (*Verification, error) {
 		}
 	}
 
+	sigData, err := os.ReadFile(sigpath)
+	if err != nil {
+		return ver, err
+	}
+	chartData, err := os.ReadFile(chartpath)
+	if err != nil {
+		return ver, err
+	}
+	return s.VerifyData(chartData, sigData, filepath.Base(chartpath))
+}
+
+// VerifyData checks a signature and verifies that it is legit for a chart
+// archive held in memory, e.g. pulled from a registry. The provenance must
+// hold the SHA of a file named filename, e.g. "mychart-1.2.3.tgz".
+func (s *Signatory) VerifyData(chartData, sigData []byte, filename string) (*Verification, error) {
+	ver := &Verification{}
+
 	// First verify the signature
-	sig, err := s.decodeSignature(sigpath)
+	sig, err := decodeSignatureData(sigData)
 	if err != nil {
 		return ver, errors.Wrap(err, "failed to decode signature")
 	}
@@ -271,7 +288,7 @@ func (s *Signatory) Verify(chartpath, sigpath string) (*Verification, error) {
 	ver.SignedBy = by
 
 	// Second, verify the hash of the tarball.
-	sum, err := DigestFile(chartpath)
+	sum, err := Digest(bytes.NewReader(chartData))
 	if err != nil {
 		return ver, err
 	}
@@ -281,14 +298,13 @@ func (s *Signatory) Verify(chartpath, sigpath string) (*Verification, error) {
 	}
 
 	sum = "sha256:" + sum
-	basename := filepath.Base(chartpath)
-	if sha, ok := sums.Files[basename]; !ok {
-		return ver, errors.Errorf("provenance does not contain a SHA for a file named %q", basename)
+	if sha, ok := sums.Files[filename]; !ok {
+		return ver, errors.Errorf("provenance does not contain a SHA for a file named %q", filename)
 	} else if sha != sum {
-		return ver, errors.Errorf("sha256 sum does not match for %s: %q != %q", basename, sha, sum)
+		return ver, errors.Errorf("sha256 sum does not match for %s: %q != %q", filename, sha, sum)
 	}
 	ver.FileHash = sum
-	ver.FileName = basename
+	ver.FileName = filename
 
 	// TODO: when image signing is added, verify that here.
 
@@ -300,7 +316,10 @@ func (s *Signatory) decodeSignature(filename string) (*clearsign.Block, error) {
 	if err != nil {
 		return nil, err
 	}
+	return decodeSignatureData(data)
+}
 
+func decodeSignatureData(data []byte) (*clearsign.Block, error) {
 	block, _ := clearsign.Decode(data)
 	if block == nil {
 		// There was no sig in the file.
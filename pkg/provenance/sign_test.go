@@ -331,6 +331,34 @@ func TestVerify(t *testing.T) {
 	}
 }
 
+func TestVerifyData(t *testing.T) {
+	signer, err := NewFromFiles(testKeyfile, testPubfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chartData, err := os.ReadFile(testChartfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigData, err := os.ReadFile(testSigBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ver, err := signer.VerifyData(chartData, sigData, "hashtest-1.2.3.tgz"); err != nil {
+		t.Errorf("Failed to pass verify. Err: %s", err)
+	} else if ver.FileName != "hashtest-1.2.3.tgz" {
+		t.Errorf("FileName is unexpectedly %q", ver.FileName)
+	}
+
+	if _, err := signer.VerifyData(chartData, sigData, "hashtest-1.2.4.tgz"); err == nil {
+		t.Error("Expected the verification of another file name to fail")
+	}
+	if _, err := signer.VerifyData(append(chartData, 0), sigData, "hashtest-1.2.3.tgz"); err == nil {
+		t.Error("Expected the verification of a tampered chart to fail")
+	}
+}
+
 // readSumFile reads a file containing a sum generated by the UNIX shasum tool.
 func readSumFile(sumfile string) (string, error) {
 	data, err := os.ReadFile(sumfile)
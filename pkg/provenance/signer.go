@@ -0,0 +1,223 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// The extensions of the signature files written next to chart archives.
+const (
+	// ProvenanceExtension is the extension of PGP provenance files.
+	ProvenanceExtension = ".prov"
+	// SignatureExtension is the extension of cosign signature files.
+	SignatureExtension = ".sig"
+)
+
+// Signer signs chart archives.
+type Signer interface {
+	// Sign returns the signature of the chart archive at chartpath.
+	Sign(chartpath string) ([]byte, error)
+	// Extension returns the extension of the signature file, which is written
+	// next to the chart archive.
+	Extension() string
+}
+
+// Sign implements Signer, returning a provenance file.
+func (s *Signatory) Sign(chartpath string) ([]byte, error) {
+	sig, err := s.ClearSign(chartpath)
+	return []byte(sig), err
+}
+
+// Extension implements Signer.
+func (s *Signatory) Extension() string {
+	return ProvenanceExtension
+}
+
+// KMS is a key management service, e.g. a cloud KMS, holding a private key
+// which never leaves the service.
+type KMS interface {
+	// SignDigest signs the SHA-256 digest of a message with the private key,
+	// returning an ASN.1 encoded signature.
+	SignDigest(ctx context.Context, digest []byte) ([]byte, error)
+}
+
+// CryptoSignerKMS is a KMS backed by a crypto.Signer, such as a local private
+// key, a hardware token or the client library of a cloud KMS implementing
+// crypto.Signer.
+type CryptoSignerKMS struct {
+	crypto.Signer
+}
+
+// SignDigest implements KMS.
+func (k CryptoSignerKMS) SignDigest(_ context.Context, digest []byte) ([]byte, error) {
+	return k.Sign(rand.Reader, digest, crypto.SHA256)
+}
+
+// CosignSigner signs chart archives with a KMS, producing the base64 encoded
+// signatures of 'cosign sign-blob', which can be verified with:
+//
+//	$ cosign verify-blob --key cosign.pub --signature mychart-0.1.0.tgz.sig mychart-0.1.0.tgz
+type CosignSigner struct {
+	KMS KMS
+}
+
+// NewCosignSigner creates a CosignSigner signing with a key of the KMS.
+func NewCosignSigner(kms KMS) *CosignSigner {
+	return &CosignSigner{KMS: kms}
+}
+
+// NewCosignSignerFromKeyFile creates a CosignSigner signing with the private
+// key in the given file, either generated by 'cosign generate-key-pair' and
+// decrypted with the passphrase, or an unencrypted PEM encoded PKCS #8 key.
+func NewCosignSignerFromKeyFile(keyfile string, fn PassphraseFetcher) (*CosignSigner, error) {
+	data, err := os.ReadFile(keyfile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := parseCosignKey(data, keyfile, fn)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot load the cosign key %s", keyfile)
+	}
+	return NewCosignSigner(CryptoSignerKMS{key}), nil
+}
+
+// Sign implements Signer.
+func (c *CosignSigner) Sign(chartpath string) ([]byte, error) {
+	data, err := os.ReadFile(chartpath)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(data)
+	sig, err := c.KMS.SignDigest(context.Background(), digest[:])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign the chart")
+	}
+	return []byte(base64.StdEncoding.EncodeToString(sig)), nil
+}
+
+// Extension implements Signer.
+func (c *CosignSigner) Extension() string {
+	return SignatureExtension
+}
+
+// VerifyCosignSignature verifies the signature of the chart archive at
+// chartpath, as written by a CosignSigner, with the ECDSA public key.
+func VerifyCosignSignature(chartpath, sigpath string, key *ecdsa.PublicKey) error {
+	data, err := os.ReadFile(chartpath)
+	if err != nil {
+		return err
+	}
+	encoded, err := os.ReadFile(sigpath)
+	if err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(string(encoded))
+	if err != nil {
+		return errors.Wrapf(err, "invalid signature %s", sigpath)
+	}
+	digest := sha256.Sum256(data)
+	if !ecdsa.VerifyASN1(key, digest[:], sig) {
+		return errors.Errorf("the signature %s does not match %s", sigpath, chartpath)
+	}
+	return nil
+}
+
+// The PEM block types of the private keys of cosign.
+const (
+	cosignPrivateKeyType   = "ENCRYPTED COSIGN PRIVATE KEY"
+	sigstorePrivateKeyType = "ENCRYPTED SIGSTORE PRIVATE KEY"
+)
+
+// encryptedKey is the encrypted private key of cosign, a PKCS #8 key encrypted
+// with a secretbox keyed by the scrypt derivation of the passphrase.
+type encryptedKey struct {
+	KDF struct {
+		Name   string `json:"name"`
+		Params struct {
+			N int `json:"N"`
+			R int `json:"r"`
+			P int `json:"p"`
+		} `json:"params"`
+		Salt []byte `json:"salt"`
+	} `json:"kdf"`
+	Cipher struct {
+		Name  string `json:"name"`
+		Nonce []byte `json:"nonce"`
+	} `json:"cipher"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func parseCosignKey(data []byte, name string, fn PassphraseFetcher) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	der := block.Bytes
+	switch block.Type {
+	case cosignPrivateKeyType, sigstorePrivateKeyType:
+		var ek encryptedKey
+		if err := json.Unmarshal(block.Bytes, &ek); err != nil {
+			return nil, err
+		}
+		if ek.KDF.Name != "scrypt" || ek.Cipher.Name != "nacl/secretbox" || len(ek.Cipher.Nonce) != 24 {
+			return nil, errors.Errorf("unsupported encryption %s, %s", ek.KDF.Name, ek.Cipher.Name)
+		}
+		passphrase, err := fn(name)
+		if err != nil {
+			return nil, err
+		}
+		k, err := scrypt.Key(passphrase, ek.KDF.Salt, ek.KDF.Params.N, ek.KDF.Params.R, ek.KDF.Params.P, 32)
+		if err != nil {
+			return nil, err
+		}
+		var key [32]byte
+		var nonce [24]byte
+		copy(key[:], k)
+		copy(nonce[:], ek.Cipher.Nonce)
+		var ok bool
+		if der, ok = secretbox.Open(nil, ek.Ciphertext, &nonce, &key); !ok {
+			return nil, errors.New("wrong passphrase")
+		}
+	case "PRIVATE KEY":
+	default:
+		return nil, errors.Errorf("unsupported PEM block %q", block.Type)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.Errorf("unsupported private key %T, expected an ECDSA key", key)
+	}
+	return signer, nil
+}
@@ -0,0 +1,146 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+func TestSignatorySigner(t *testing.T) {
+	signer, err := NewFromFiles(testKeyfile, testPubfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s Signer = signer
+	sig, err := s.Sign(testChartfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(sig), testMessageBlock) {
+		t.Errorf("expected message block to be in sig: %s", sig)
+	}
+	if s.Extension() != ".prov" {
+		t.Errorf("expected .prov, got %s", s.Extension())
+	}
+}
+
+func TestCosignSigner(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	for name, data := range map[string][]byte{
+		"plain":     pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+		"encrypted": encryptCosignKey(t, der, []byte("secret")),
+	} {
+		t.Run(name, func(t *testing.T) {
+			keyfile := filepath.Join(dir, name+".key")
+			if err := os.WriteFile(keyfile, data, 0600); err != nil {
+				t.Fatal(err)
+			}
+			signer, err := NewCosignSignerFromKeyFile(keyfile, func(string) ([]byte, error) {
+				return []byte("secret"), nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if signer.Extension() != ".sig" {
+				t.Errorf("expected .sig, got %s", signer.Extension())
+			}
+
+			sig, err := signer.Sign(testChartfile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sigfile := filepath.Join(dir, name+".sig")
+			if err := os.WriteFile(sigfile, sig, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := VerifyCosignSignature(testChartfile, sigfile, &key.PublicKey); err != nil {
+				t.Error(err)
+			}
+			if err := VerifyCosignSignature(testSumfile, sigfile, &key.PublicKey); err == nil {
+				t.Error("expected the signature of another file to fail the verification")
+			}
+		})
+	}
+
+	keyfile := filepath.Join(dir, "wrong.key")
+	if err := os.WriteFile(keyfile, encryptCosignKey(t, der, []byte("secret")), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewCosignSignerFromKeyFile(keyfile, func(string) ([]byte, error) {
+		return []byte("wrong"), nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("expected a wrong passphrase error, got %v", err)
+	}
+
+	if _, err := NewCosignSignerFromKeyFile(testKeyfile, nil); err == nil {
+		t.Error("expected an error loading a PGP key")
+	}
+}
+
+// encryptCosignKey encrypts the key as 'cosign generate-key-pair' does, with
+// weaker scrypt parameters to keep the test fast.
+func encryptCosignKey(t *testing.T, der, passphrase []byte) []byte {
+	t.Helper()
+	var ek encryptedKey
+	ek.KDF.Name = "scrypt"
+	ek.KDF.Params.N, ek.KDF.Params.R, ek.KDF.Params.P = 1024, 8, 1
+	ek.KDF.Salt = make([]byte, 32)
+	ek.Cipher.Name = "nacl/secretbox"
+	ek.Cipher.Nonce = make([]byte, 24)
+	if _, err := rand.Read(ek.KDF.Salt); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rand.Read(ek.Cipher.Nonce); err != nil {
+		t.Fatal(err)
+	}
+	k, err := scrypt.Key(passphrase, ek.KDF.Salt, 1024, 8, 1, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var key [32]byte
+	var nonce [24]byte
+	copy(key[:], k)
+	copy(nonce[:], ek.Cipher.Nonce)
+	ek.Ciphertext = secretbox.Seal(nil, der, &nonce, &key)
+	data, err := json.Marshal(ek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: sigstorePrivateKeyType, Bytes: data})
+}
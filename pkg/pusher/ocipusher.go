@@ -93,6 +93,12 @@ func (pusher *OCIPusher) push(chartRef, href string) error {
 	// The time the chart was "created" is semantically the time the chart archive file was last written(modified)
 	chartArchiveFileCreatedTime := ctime.Modified(stat)
 	pushOpts = append(pushOpts, registry.PushOptCreationTime(chartArchiveFileCreatedTime.Format(time.RFC3339)))
+	if len(pusher.opts.annotations) > 0 {
+		pushOpts = append(pushOpts, registry.PushOptAnnotations(pusher.opts.annotations))
+	}
+	if pusher.opts.artifactType != "" {
+		pushOpts = append(pushOpts, registry.PushOptArtifactType(pusher.opts.artifactType))
+	}
 
 	_, err = client.Push(chartBytes, ref, pushOpts...)
 	return err
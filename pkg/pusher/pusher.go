@@ -33,6 +33,8 @@ type options struct {
 	caFile                string
 	insecureSkipTLSverify bool
 	plainHTTP             bool
+	annotations           map[string]string
+	artifactType          string
 }
 
 // Option allows specifying various settings configurable by the user for overriding the defaults
@@ -68,6 +70,22 @@ func WithPlainHTTP(plainHTTP bool) Option {
 	}
 }
 
+// WithAnnotations sets the annotations added to the pushed artifact, for the
+// pushers supporting them.
+func WithAnnotations(annotations map[string]string) Option {
+	return func(opts *options) {
+		opts.annotations = annotations
+	}
+}
+
+// WithArtifactType sets the type of the pushed artifact, for the pushers
+// supporting it.
+func WithArtifactType(artifactType string) Option {
+	return func(opts *options) {
+		opts.artifactType = artifactType
+	}
+}
+
 // Pusher is an interface to support upload to the specified URL.
 type Pusher interface {
 	// Push file content by url string
@@ -0,0 +1,245 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package redact removes sensitive data from rendered manifests before they
+// are shown to a user.
+//
+// A Redactor handles a single YAML document. FieldRedactor covers the common
+// case of hiding selected fields of a given kind, and can be used for custom
+// resources that carry credentials. Organizations with other needs can
+// implement Redactor themselves.
+package redact
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// Mode controls how a FieldRedactor redacts the fields it matches.
+type Mode string
+
+const (
+	// ModeHide replaces the whole resource with a comment.
+	ModeHide Mode = "hide"
+	// ModeHash replaces each value with the SHA-256 of its content. This
+	// keeps changes to a value detectable without revealing it.
+	ModeHash Mode = "hash"
+	// ModeKeys keeps the keys of each field but replaces the values.
+	ModeKeys Mode = "keys"
+)
+
+// RedactedValue replaces values when using ModeKeys.
+const RedactedValue = "<redacted>"
+
+// ParseMode converts a string to a Mode.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(strings.ToLower(s)); m {
+	case ModeHide, ModeHash, ModeKeys:
+		return m, nil
+	}
+	return "", errors.Errorf("invalid redaction mode %q: must be one of %s, %s, %s", s, ModeHide, ModeHash, ModeKeys)
+}
+
+// Redactor redacts sensitive data from a single YAML document.
+type Redactor interface {
+	// Redact returns the redacted document. A document the redactor is not
+	// concerned with must be returned unchanged.
+	Redact(doc string) (string, error)
+}
+
+// FieldRedactor redacts fields of resources of a single kind.
+type FieldRedactor struct {
+	// APIVersion of the resources to redact, e.g. "v1". Empty matches any version.
+	APIVersion string
+	// Kind of the resources to redact, e.g. "Secret".
+	Kind string
+	// Fields are dotted paths to the fields to redact, e.g. "spec.password".
+	// When a field is a map, each of its values is redacted and its keys are
+	// kept. Fields are ignored with ModeHide.
+	Fields []string
+	// Mode is the redaction mode.
+	Mode Mode
+}
+
+// NewSecretRedactor returns a redactor for the data of core v1 Secrets.
+func NewSecretRedactor(mode Mode) *FieldRedactor {
+	return &FieldRedactor{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Fields:     []string{"data", "stringData"},
+		Mode:       mode,
+	}
+}
+
+// Redact implements Redactor. The redacted fields are replaced in place, so
+// that the order of the keys and the comments of the document are kept.
+func (r *FieldRedactor) Redact(doc string) (string, error) {
+	var head struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+	}
+	if err := k8syaml.Unmarshal([]byte(doc), &head); err != nil {
+		return doc, errors.Wrap(err, "unable to parse manifest for redaction")
+	}
+	if head.Kind != r.Kind || (r.APIVersion != "" && head.APIVersion != r.APIVersion) {
+		return doc, nil
+	}
+
+	if r.Mode == ModeHide {
+		return fmt.Sprintf("# HIDDEN: The %s output has been suppressed", r.Kind), nil
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(doc), &root); err != nil {
+		return doc, errors.Wrap(err, "unable to parse manifest for redaction")
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return doc, nil
+	}
+	obj := root.Content[0]
+
+	changed := false
+	for _, field := range r.Fields {
+		if r.redactField(obj, strings.Split(field, ".")) {
+			changed = true
+		}
+	}
+	if !changed {
+		return doc, nil
+	}
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&root); err != nil {
+		return doc, err
+	}
+	if err := enc.Close(); err != nil {
+		return doc, err
+	}
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
+
+func (r *FieldRedactor) redactField(obj *yaml.Node, path []string) bool {
+	v := mappingValue(obj, path[0])
+	if v == nil || v.Tag == "!!null" {
+		return false
+	}
+	if len(path) > 1 {
+		if v.Kind != yaml.MappingNode {
+			return false
+		}
+		return r.redactField(v, path[1:])
+	}
+	if v.Kind == yaml.MappingNode {
+		for i := 1; i < len(v.Content); i += 2 {
+			r.redactNode(v.Content[i])
+		}
+		return len(v.Content) > 0
+	}
+	r.redactNode(v)
+	return true
+}
+
+// redactNode replaces the value of a node with a string, keeping its
+// comments.
+func (r *FieldRedactor) redactNode(n *yaml.Node) {
+	value := RedactedValue
+	if r.Mode == ModeHash {
+		content := n.Value
+		if n.Kind != yaml.ScalarNode {
+			b, _ := yaml.Marshal(n)
+			content = string(b)
+		}
+		sum := sha256.Sum256([]byte(content))
+		value = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	n.Kind, n.Tag, n.Style, n.Value, n.Content, n.Anchor, n.Alias = yaml.ScalarNode, "!!str", 0, value, nil, "", nil
+}
+
+// mappingValue returns the value of a key of a mapping node, or nil.
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+var docSeparator = regexp.MustCompile(`(?m)^---[ \t]*$`)
+
+// Manifest applies the redactors to every document of a multi-document
+// manifest, such as the one stored in a release. Leading comments, like the
+// "# Source:" header, are kept.
+func Manifest(manifest string, redactors ...Redactor) (string, error) {
+	if len(redactors) == 0 {
+		return manifest, nil
+	}
+
+	docs := docSeparator.Split(manifest, -1)
+	for i, doc := range docs {
+		header, body := splitLeadingComments(doc)
+		if strings.TrimSpace(body) == "" {
+			continue
+		}
+		trailing := body[len(strings.TrimRight(body, "\n")):]
+		redacted, err := Document(strings.TrimRight(body, "\n"), redactors...)
+		if err != nil {
+			return manifest, err
+		}
+		docs[i] = header + redacted + trailing
+	}
+	return strings.Join(docs, "---"), nil
+}
+
+// Document applies the redactors in order to a single YAML document.
+func Document(doc string, redactors ...Redactor) (string, error) {
+	for _, r := range redactors {
+		var err error
+		if doc, err = r.Redact(doc); err != nil {
+			return doc, err
+		}
+	}
+	return doc, nil
+}
+
+// splitLeadingComments separates leading blank and comment lines of a
+// document from its body.
+func splitLeadingComments(doc string) (string, string) {
+	i := 0
+	for i < len(doc) {
+		end := strings.IndexByte(doc[i:], '\n')
+		if end < 0 {
+			end = len(doc) - i
+		} else {
+			end++
+		}
+		line := strings.TrimSpace(doc[i : i+end])
+		if line != "" && !strings.HasPrefix(line, "#") {
+			break
+		}
+		i += end
+	}
+	return doc[:i], doc[i:]
+}
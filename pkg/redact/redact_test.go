@@ -0,0 +1,173 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redact
+
+import (
+	"testing"
+)
+
+const secretManifest = `---
+# Source: chart/templates/cm.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  password: not-a-secret
+---
+# Source: chart/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+data:
+  password: aHVudGVyMg==
+stringData:
+  token: abc
+`
+
+func TestManifestModes(t *testing.T) {
+	tests := []struct {
+		mode   Mode
+		expect string
+	}{{
+		mode: ModeHide,
+		expect: `---
+# Source: chart/templates/cm.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  password: not-a-secret
+---
+# Source: chart/templates/secret.yaml
+# HIDDEN: The Secret output has been suppressed
+`,
+	}, {
+		mode: ModeKeys,
+		expect: `---
+# Source: chart/templates/cm.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  password: not-a-secret
+---
+# Source: chart/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+data:
+  password: <redacted>
+stringData:
+  token: <redacted>
+`,
+	}, {
+		mode: ModeHash,
+		expect: `---
+# Source: chart/templates/cm.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  password: not-a-secret
+---
+# Source: chart/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+data:
+  password: sha256:b073aefd7c9215dd0179def431a8e7b5b1c39770f72ab676e9d9bd4a466268d1
+stringData:
+  token: sha256:ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad
+`,
+	}}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			got, err := Manifest(secretManifest, NewSecretRedactor(tt.mode))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.expect {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestManifestWithoutRedactors(t *testing.T) {
+	got, err := Manifest(secretManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != secretManifest {
+		t.Errorf("expected manifest to be unchanged, got:\n%s", got)
+	}
+}
+
+func TestFieldRedactorCustomResource(t *testing.T) {
+	r := &FieldRedactor{
+		APIVersion: "db.example.com/v1",
+		Kind:       "Database",
+		Fields:     []string{"spec.auth.password", "spec.missing"},
+		Mode:       ModeKeys,
+	}
+
+	doc := "apiVersion: db.example.com/v1\nkind: Database\nmetadata:\n  name: db\nspec:\n  auth:\n    password: hunter2\n    user: admin"
+	got, err := r.Redact(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "apiVersion: db.example.com/v1\nkind: Database\nmetadata:\n  name: db\nspec:\n  auth:\n    password: <redacted>\n    user: admin"
+	if got != expect {
+		t.Errorf("expected:\n%s\ngot:\n%s", expect, got)
+	}
+
+	other := "apiVersion: db.example.com/v2\nkind: Database\nspec:\n  auth:\n    password: hunter2"
+	if got, _ := r.Redact(other); got != other {
+		t.Errorf("expected other API versions to be left untouched, got:\n%s", got)
+	}
+}
+
+func TestFieldRedactorKeepsOrderAndComments(t *testing.T) {
+	doc := "kind: Secret\napiVersion: v1\n# credentials of the database\nstringData:\n  user: admin # not secret either\n  password: |\n    hunter2\nmetadata:\n  name: creds"
+	got, err := NewSecretRedactor(ModeKeys).Redact(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "kind: Secret\napiVersion: v1\n# credentials of the database\nstringData:\n  user: <redacted> # not secret either\n  password: <redacted>\nmetadata:\n  name: creds"
+	if got != expect {
+		t.Errorf("expected:\n%s\ngot:\n%s", expect, got)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for _, s := range []string{"hide", "HASH", "keys"} {
+		if _, err := ParseMode(s); err != nil {
+			t.Errorf("unexpected error for %q: %s", s, err)
+		}
+	}
+	if _, err := ParseMode("encrypt"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
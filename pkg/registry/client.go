@@ -24,11 +24,14 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"sort"
+	"os"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/containerd/containerd/remotes"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/credentials"
+	"github.com/docker/cli/cli/config/types"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
 	"oras.land/oras-go/pkg/auth"
@@ -38,7 +41,9 @@ import (
 	"oras.land/oras-go/pkg/registry"
 	registryremote "oras.land/oras-go/pkg/registry/remote"
 	registryauth "oras.land/oras-go/pkg/registry/remote/auth"
+	"sigs.k8s.io/yaml"
 
+	"helm.sh/helm/v4/internal/transport"
 	"helm.sh/helm/v4/internal/version"
 	"helm.sh/helm/v4/pkg/chart"
 	"helm.sh/helm/v4/pkg/helmpath"
@@ -73,6 +78,13 @@ type (
 		resolver           func(ref registry.Reference) (remotes.Resolver, error)
 		httpClient         *http.Client
 		plainHTTP          bool
+		credsProvider      CredentialsProvider
+		// path to the proxy and TLS settings of the registries
+		hostsFile string
+		// settings of the hosts file by host
+		hosts map[string]HostConfig
+		// TLS settings of all registries, see ClientOptTLSClientConfig
+		tls *transport.Options
 	}
 
 	// ClientOption allows specifying various settings configurable by the user for overriding the defaults
@@ -91,6 +103,18 @@ func NewClient(options ...ClientOption) (*Client, error) {
 	if client.credentialsFile == "" {
 		client.credentialsFile = helmpath.ConfigPath(CredentialsFileBasename)
 	}
+	if client.tls != nil && client.httpClient == nil {
+		t, err := transport.New(*client.tls)
+		if err != nil {
+			return nil, err
+		}
+		client.httpClient = &http.Client{Transport: t}
+	}
+	if client.hostsFile != "" {
+		if err := client.applyHostsFile(); err != nil {
+			return nil, err
+		}
+	}
 	if client.authorizer == nil {
 		authClient, err := dockerauth.NewClientWithDockerFallback(client.credentialsFile)
 		if err != nil {
@@ -113,7 +137,7 @@ func NewClient(options ...ClientOption) (*Client, error) {
 		if client.httpClient != nil {
 			opts = append(opts, auth.WithResolverClient(client.httpClient))
 		}
-		if client.plainHTTP {
+		if client.isPlainHTTP(ref.Registry) {
 			opts = append(opts, auth.WithResolverPlainHTTP())
 		}
 
@@ -127,6 +151,16 @@ func NewClient(options ...ClientOption) (*Client, error) {
 					"Authorization": []string{"Basic " + encodedAuth},
 				},
 			))
+		} else if creds, err := client.providedCredentials(ref.Registry); err != nil {
+			return nil, err
+		} else if creds != nil {
+			if header := creds.AuthorizationHeader(); header != "" {
+				opts = append(opts, auth.WithResolverHeaders(
+					http.Header{
+						"Authorization": []string{header},
+					},
+				))
+			}
 		}
 
 		resolver, err := client.authorizer.ResolverWithOpts(opts...)
@@ -156,6 +190,20 @@ func NewClient(options ...ClientOption) (*Client, error) {
 					}, nil
 				}
 
+				if creds, err := client.providedCredentials(reg); err != nil {
+					return registryauth.EmptyCredential, err
+				} else if creds != nil {
+					if creds.Token != "" {
+						return registryauth.Credential{
+							AccessToken: creds.Token,
+						}, nil
+					}
+					return registryauth.Credential{
+						Username: creds.Username,
+						Password: creds.Password,
+					}, nil
+				}
+
 				dockerClient, ok := client.authorizer.(*dockerauth.Client)
 				if !ok {
 					return registryauth.EmptyCredential, errors.New("unable to obtain docker client")
@@ -185,6 +233,88 @@ func NewClient(options ...ClientOption) (*Client, error) {
 	return client, nil
 }
 
+// applyHostsFile routes the requests to the registries of the hosts file
+// through the transports of their settings, merged with the TLS settings of
+// the client.
+func (c *Client) applyHostsFile() error {
+	f, err := LoadHostsFile(c.hostsFile)
+	if err != nil || len(f.Hosts) == 0 {
+		return err
+	}
+	c.hosts = map[string]HostConfig{}
+	for host, h := range f.Hosts {
+		c.hosts[strings.ToLower(host)] = h
+	}
+	httpClient := &http.Client{}
+	if c.httpClient != nil {
+		*httpClient = *c.httpClient
+	}
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t, err := newHostsTransport(base, f, c.tls)
+	if err != nil {
+		return err
+	}
+	httpClient.Transport = t
+	c.httpClient = httpClient
+	return nil
+}
+
+// isPlainHTTP reports whether the registry is reached over HTTP, as set for
+// all registries with ClientOptPlainHTTP or for the host in the hosts file.
+func (c *Client) isPlainHTTP(host string) bool {
+	return c.plainHTTP || c.hosts[strings.ToLower(hostname(host))].PlainHTTP
+}
+
+// scheme returns the URL scheme of the registry.
+func (c *Client) scheme(host string) string {
+	if c.isPlainHTTP(host) {
+		return "http"
+	}
+	return "https"
+}
+
+// Ping checks that a registry is reachable with a request to the base
+// endpoint of the OCI distribution API, through the proxy, TLS and plain HTTP
+// settings of the client and of the hosts file. The registry may reply with
+// a 401 to the unauthenticated request.
+func (c *Client) Ping(ctx context.Context, host string) error {
+	host = hostname(host)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s://%s/v2/", c.scheme(host), host), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", version.GetUserAgent())
+	httpClient := c.httpClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusUnauthorized {
+		return errors.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// providedCredentials returns the credentials of the credentials provider for
+// the host, if any.
+func (c *Client) providedCredentials(host string) (*Credentials, error) {
+	if c.credsProvider == nil {
+		return nil, nil
+	}
+	creds, err := c.credsProvider.Credentials(host)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to retrieve credentials for %s", host)
+	}
+	return creds, nil
+}
+
 // ClientOptDebug returns a function that sets the debug setting on client options set
 func ClientOptDebug(debug bool) ClientOption {
 	return func(client *Client) {
@@ -241,6 +371,14 @@ func ClientOptCredentialsFile(credentialsFile string) ClientOption {
 	}
 }
 
+// ClientOptCredentialsProvider returns a function that sets the credentials
+// provider on a client options set
+func ClientOptCredentialsProvider(provider CredentialsProvider) ClientOption {
+	return func(client *Client) {
+		client.credsProvider = provider
+	}
+}
+
 // ClientOptHTTPClient returns a function that sets the httpClient setting on a client options set
 func ClientOptHTTPClient(httpClient *http.Client) ClientOption {
 	return func(client *Client) {
@@ -248,6 +386,31 @@ func ClientOptHTTPClient(httpClient *http.Client) ClientOption {
 	}
 }
 
+// ClientOptTLSClientConfig returns a function that sets the TLS settings used
+// with all registries. For the registries of the hosts file, they take
+// precedence over the saved TLS settings, which are otherwise still used along
+// with the saved proxy and minimum TLS version.
+func ClientOptTLSClientConfig(certFile, keyFile, caFile string, insecureSkipTLSVerify bool) ClientOption {
+	return func(client *Client) {
+		client.tls = &transport.Options{
+			CertFile:              certFile,
+			KeyFile:               keyFile,
+			CAFile:                caFile,
+			InsecureSkipTLSVerify: insecureSkipTLSVerify,
+		}
+	}
+}
+
+// ClientOptHostsFile returns a function that sets the path of the hosts file
+// holding the proxy and TLS settings of the registries, see HostsFile. The
+// settings of a registry apply to the requests to its host, and are saved on
+// login.
+func ClientOptHostsFile(hostsFile string) ClientOption {
+	return func(client *Client) {
+		client.hostsFile = hostsFile
+	}
+}
+
 func ClientOptPlainHTTP() ClientOption {
 	return func(c *Client) {
 		c.plainHTTP = true
@@ -274,6 +437,10 @@ type (
 		certFile string
 		keyFile  string
 		caFile   string
+		// saveTLS saves the TLS settings of the login to the hosts file.
+		saveTLS       bool
+		proxy         string
+		minTLSVersion string
 	}
 )
 
@@ -283,6 +450,10 @@ func (c *Client) Login(host string, options ...LoginOption) error {
 	for _, option := range options {
 		option(operation)
 	}
+	t, err := transport.New(operation.transportOptions())
+	if err != nil {
+		return err
+	}
 	authorizerLoginOpts := []auth.LoginOption{
 		auth.WithLoginContext(ctx(c.out, c.debug)),
 		auth.WithLoginHostname(host),
@@ -294,11 +465,113 @@ func (c *Client) Login(host string, options ...LoginOption) error {
 	if operation.insecure {
 		authorizerLoginOpts = append(authorizerLoginOpts, auth.WithLoginInsecure())
 	}
-	if err := c.authorizer.LoginWithOpts(authorizerLoginOpts...); err != nil {
+	if operation.proxy != "" || operation.minTLSVersion != "" {
+		// The docker login only uses the proxy environment variables and
+		// the default TLS versions.
+		err = c.loginWithTransport(host, operation, t)
+	} else {
+		err = c.authorizer.LoginWithOpts(authorizerLoginOpts...)
+	}
+	if err != nil {
 		return err
 	}
 	fmt.Fprintln(c.out, "Login Succeeded")
-	return nil
+	return c.saveHostConfig(host, operation.hostConfig())
+}
+
+// loginWithTransport checks the credentials of the login with a request to
+// the registry through the transport, and stores them in the credentials
+// file.
+func (c *Client) loginWithTransport(host string, o *loginOperation, t http.RoundTripper) error {
+	host = hostname(host)
+	cred := registryauth.Credential{Username: o.username, Password: o.password}
+	if o.username == "" {
+		cred = registryauth.Credential{RefreshToken: o.password}
+	}
+	authClient := &registryauth.Client{
+		Client: &http.Client{Transport: t},
+		Header: http.Header{"User-Agent": {version.GetUserAgent()}},
+		Credential: func(context.Context, string) (registryauth.Credential, error) {
+			return cred, nil
+		},
+	}
+	req, err := http.NewRequestWithContext(ctx(c.out, c.debug), http.MethodGet, fmt.Sprintf("%s://%s/v2/", c.scheme(host), host), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := authClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "login to %s failed", host)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("login to %s failed: %s", host, resp.Status)
+	}
+	return storeCredentials(c.credentialsFile, host, o.username, o.password)
+}
+
+// storeCredentials stores the credentials of the host in the credentials
+// file, or in the credentials store it configures, like the docker login.
+func storeCredentials(credentialsFile, host, username, password string) error {
+	cfg := configfile.New(credentialsFile)
+	if f, err := os.Open(credentialsFile); err == nil {
+		defer f.Close()
+		if err := cfg.LoadFromReader(f); err != nil {
+			return errors.Wrapf(err, "unable to load %s", credentialsFile)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if !cfg.ContainsAuth() {
+		cfg.CredentialsStore = credentials.DetectDefaultStore(cfg.CredentialsStore)
+	}
+	auth := types.AuthConfig{ServerAddress: host, Username: username, Password: password}
+	if username == "" {
+		auth = types.AuthConfig{ServerAddress: host, IdentityToken: password}
+	}
+	return cfg.GetCredentialsStore(host).Store(auth)
+}
+
+func (o *loginOperation) transportOptions() transport.Options {
+	return transport.Options{
+		Proxy:                 o.proxy,
+		CertFile:              o.certFile,
+		KeyFile:               o.keyFile,
+		CAFile:                o.caFile,
+		InsecureSkipTLSVerify: o.insecure,
+		MinTLSVersion:         o.minTLSVersion,
+	}
+}
+
+// hostConfig returns the settings of the login saved for the host. The TLS
+// files and the verification are only saved when requested, so that a login
+// with --insecure does not weaken the following operations unnoticed.
+func (o *loginOperation) hostConfig() HostConfig {
+	h := HostConfig{
+		Proxy:         o.proxy,
+		MinTLSVersion: o.minTLSVersion,
+	}
+	if o.saveTLS {
+		h.CertFile = o.certFile
+		h.KeyFile = o.keyFile
+		h.CAFile = o.caFile
+		h.InsecureSkipTLSVerify = o.insecure
+	}
+	return h
+}
+
+// saveHostConfig saves the settings of the host to the hosts file, unless
+// the client has none or the settings are empty.
+func (c *Client) saveHostConfig(host string, h HostConfig) error {
+	if c.hostsFile == "" || h == (HostConfig{}) {
+		return nil
+	}
+	f, err := LoadHostsFile(c.hostsFile)
+	if err != nil {
+		return err
+	}
+	f.Hosts[hostname(host)] = h
+	return errors.Wrap(f.WriteFile(c.hostsFile, 0600), "unable to save the registry settings")
 }
 
 // LoginOptBasicAuth returns a function that sets the username/password settings on login
@@ -325,6 +598,31 @@ func LoginOptTLSClientConfig(certFile, keyFile, caFile string) LoginOption {
 	}
 }
 
+// LoginOptSaveTLS returns a function that sets whether the TLS settings of the
+// login are saved for the registry. The proxy and the minimum TLS version are
+// always saved.
+func LoginOptSaveTLS(save bool) LoginOption {
+	return func(operation *loginOperation) {
+		operation.saveTLS = save
+	}
+}
+
+// LoginOptProxy returns a function that sets the proxy used to log into the
+// registry, which is saved for the registry as well.
+func LoginOptProxy(proxy string) LoginOption {
+	return func(operation *loginOperation) {
+		operation.proxy = proxy
+	}
+}
+
+// LoginOptMinTLSVersion returns a function that sets the minimum TLS version
+// used to log into the registry, which is saved for the registry as well.
+func LoginOptMinTLSVersion(version string) LoginOption {
+	return func(operation *loginOperation) {
+		operation.minTLSVersion = version
+	}
+}
+
 type (
 	// LogoutOption allows specifying various settings on logout
 	LogoutOption func(*logoutOperation)
@@ -589,6 +887,8 @@ type (
 		provData     []byte
 		strictMode   bool
 		creationTime string
+		annotations  map[string]string
+		artifactType string
 	}
 )
 
@@ -643,8 +943,15 @@ func (c *Client) Push(data []byte, ref string, options ...PushOption) (*PushResu
 	}
 
 	ociAnnotations := generateOCIAnnotations(meta, operation.creationTime)
+	ociAnnotations = mergeOCIAnnotations(ociAnnotations, operation.annotations)
 
-	manifestData, manifest, err := content.GenerateManifest(&configDescriptor, ociAnnotations, descriptors...)
+	var manifestData []byte
+	var manifest ocispec.Descriptor
+	if operation.artifactType != "" {
+		manifestData, manifest, err = generateArtifactManifest(configDescriptor, operation.artifactType, ociAnnotations, descriptors)
+	} else {
+		manifestData, manifest, err = content.GenerateManifest(&configDescriptor, ociAnnotations, descriptors...)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -718,6 +1025,160 @@ func PushOptCreationTime(creationTime string) PushOption {
 	}
 }
 
+// PushOptAnnotations returns a function that adds annotations to the manifest,
+// e.g. the standard "org.opencontainers.image.revision" annotation or the
+// annotations required by the policies of a registry. They take precedence
+// over the annotations generated from the chart, except for its name and
+// version.
+func PushOptAnnotations(annotations map[string]string) PushOption {
+	return func(operation *pushOperation) {
+		operation.annotations = annotations
+	}
+}
+
+// PushOptArtifactType returns a function that sets the artifactType of the
+// manifest, which registries use to tell the kinds of artifacts apart. The
+// manifest has no artifactType by default.
+func PushOptArtifactType(artifactType string) PushOption {
+	return func(operation *pushOperation) {
+		operation.artifactType = artifactType
+	}
+}
+
+type (
+	// PushValuesResult is the result returned upon successful push of values.
+	PushValuesResult struct {
+		Manifest *descriptorPushSummary `json:"manifest"`
+		Values   *descriptorPushSummary `json:"values"`
+		Ref      string                 `json:"ref"`
+	}
+
+	// PullValuesResult is the result returned upon successful pull of values.
+	PullValuesResult struct {
+		Manifest *DescriptorPullSummary `json:"manifest"`
+		Values   *DescriptorPullSummary `json:"values"`
+		Ref      string                 `json:"ref"`
+	}
+)
+
+// PushValues uploads a values file to a registry as a values artifact, which
+// holds the file in a single layer with ValuesLayerMediaType.
+func (c *Client) PushValues(data []byte, ref string) (*PushValuesResult, error) {
+	parsedRef, err := newReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	var vals map[string]interface{}
+	if err := yaml.Unmarshal(data, &vals); err != nil {
+		return nil, errors.Wrap(err, "invalid values file")
+	}
+
+	memoryStore := content.NewMemory()
+	valuesDescriptor, err := memoryStore.Add("", ValuesLayerMediaType, data)
+	if err != nil {
+		return nil, err
+	}
+	configDescriptor, err := memoryStore.Add("", ValuesConfigMediaType, []byte("{}"))
+	if err != nil {
+		return nil, err
+	}
+	manifestData, manifest, err := content.GenerateManifest(&configDescriptor, nil, valuesDescriptor)
+	if err != nil {
+		return nil, err
+	}
+	if err := memoryStore.StoreManifest(parsedRef.String(), manifest, manifestData); err != nil {
+		return nil, err
+	}
+
+	remotesResolver, err := c.resolver(parsedRef.orasReference)
+	if err != nil {
+		return nil, err
+	}
+	registryStore := content.Registry{Resolver: remotesResolver}
+	_, err = oras.Copy(ctx(c.out, c.debug), memoryStore, parsedRef.orasReference.String(), registryStore, "",
+		oras.WithNameValidation(nil))
+	if err != nil {
+		return nil, err
+	}
+	result := &PushValuesResult{
+		Manifest: &descriptorPushSummary{
+			Digest: manifest.Digest.String(),
+			Size:   manifest.Size,
+		},
+		Values: &descriptorPushSummary{
+			Digest: valuesDescriptor.Digest.String(),
+			Size:   valuesDescriptor.Size,
+		},
+		Ref: parsedRef.String(),
+	}
+	fmt.Fprintf(c.out, "Pushed: %s\n", result.Ref)
+	fmt.Fprintf(c.out, "Digest: %s\n", result.Manifest.Digest)
+	return result, nil
+}
+
+// PullValues downloads the values file of a values artifact from a registry,
+// see PushValues.
+func (c *Client) PullValues(ref string) (*PullValuesResult, error) {
+	parsedRef, err := newReference(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	memoryStore := content.NewMemory()
+	remotesResolver, err := c.resolver(parsedRef.orasReference)
+	if err != nil {
+		return nil, err
+	}
+	registryStore := content.Registry{Resolver: remotesResolver}
+
+	var layers []ocispec.Descriptor
+	manifest, err := oras.Copy(ctx(c.out, c.debug), registryStore, parsedRef.String(), memoryStore, "",
+		oras.WithPullEmptyNameAllowed(),
+		oras.WithAllowedMediaTypes([]string{ValuesConfigMediaType, ValuesLayerMediaType}),
+		oras.WithLayerDescriptors(func(l []ocispec.Descriptor) {
+			layers = l
+		}))
+	if err != nil {
+		return nil, err
+	}
+
+	var valuesDescriptor *ocispec.Descriptor
+	for _, l := range layers {
+		if l.MediaType == ValuesLayerMediaType {
+			d := l
+			valuesDescriptor = &d
+		}
+	}
+	if valuesDescriptor == nil {
+		return nil, fmt.Errorf("manifest does not contain a layer with mediatype %s", ValuesLayerMediaType)
+	}
+	_, manifestData, ok := memoryStore.Get(manifest)
+	if !ok {
+		return nil, errors.Errorf("Unable to retrieve blob with digest %s", manifest.Digest)
+	}
+	_, valuesData, ok := memoryStore.Get(*valuesDescriptor)
+	if !ok {
+		return nil, errors.Errorf("Unable to retrieve blob with digest %s", valuesDescriptor.Digest)
+	}
+
+	result := &PullValuesResult{
+		Manifest: &DescriptorPullSummary{
+			Data:   manifestData,
+			Digest: manifest.Digest.String(),
+			Size:   manifest.Size,
+		},
+		Values: &DescriptorPullSummary{
+			Data:   valuesData,
+			Digest: valuesDescriptor.Digest.String(),
+			Size:   valuesDescriptor.Size,
+		},
+		Ref: parsedRef.String(),
+	}
+	fmt.Fprintf(c.out, "Pulled: %s\n", result.Ref)
+	fmt.Fprintf(c.out, "Digest: %s\n", result.Manifest.Digest)
+	return result, nil
+}
+
 // Tags provides a sorted list all semver compliant tags for a given repository
 func (c *Client) Tags(ref string) ([]string, error) {
 	parsedReference, err := registry.ParseReference(ref)
@@ -728,7 +1189,7 @@ func (c *Client) Tags(ref string) ([]string, error) {
 	repository := registryremote.Repository{
 		Reference: parsedReference,
 		Client:    c.registryAuthorizer,
-		PlainHTTP: c.plainHTTP,
+		PlainHTTP: c.isPlainHTTP(parsedReference.Registry),
 	}
 
 	var registryTags []string
@@ -738,22 +1199,9 @@ func (c *Client) Tags(ref string) ([]string, error) {
 		return nil, err
 	}
 
-	var tagVersions []*semver.Version
-	for _, tag := range registryTags {
-		// Change underscore (_) back to plus (+) for Helm
-		// See https://github.com/helm/helm/issues/10166
-		tagVersion, err := semver.StrictNewVersion(strings.ReplaceAll(tag, "_", "+"))
-		if err == nil {
-			tagVersions = append(tagVersions, tagVersion)
-		}
-	}
-
-	// Sort the collection
-	sort.Sort(sort.Reverse(semver.Collection(tagVersions)))
-
-	tags := make([]string, len(tagVersions))
-
-	for iTv, tv := range tagVersions {
+	versions := tagVersions(registryTags)
+	tags := make([]string, len(versions))
+	for iTv, tv := range versions {
 		tags[iTv] = tv.String()
 	}
 
@@ -781,6 +1229,46 @@ func (c *Client) Resolve(ref string) (*ocispec.Descriptor, error) {
 	return &desc, err
 }
 
+// ChartDigest returns the digest of the chart layer of a reference. Only the
+// manifest is fetched, so that a chart can be looked up in a content cache
+// before it is pulled.
+func (c *Client) ChartDigest(ref string) (string, error) {
+	parsedRef, err := newReference(ref)
+	if err != nil {
+		return "", err
+	}
+	remotesResolver, err := c.resolver(parsedRef.orasReference)
+	if err != nil {
+		return "", err
+	}
+
+	fetchCtx := ctx(c.out, c.debug)
+	name, desc, err := remotesResolver.Resolve(fetchCtx, parsedRef.orasReference.String())
+	if err != nil {
+		return "", err
+	}
+	fetcher, err := remotesResolver.Fetcher(fetchCtx, name)
+	if err != nil {
+		return "", err
+	}
+	rc, err := fetcher.Fetch(fetchCtx, desc)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	var manifest ocispec.Manifest
+	if err := json.NewDecoder(io.LimitReader(rc, maxManifestSize)).Decode(&manifest); err != nil {
+		return "", errors.Wrapf(err, "failed to decode the manifest of %s", ref)
+	}
+	for _, l := range manifest.Layers {
+		if l.MediaType == ChartLayerMediaType || l.MediaType == LegacyChartLayerMediaType {
+			return l.Digest.String(), nil
+		}
+	}
+	return "", errors.Errorf("manifest does not contain a layer with mediatype %s", ChartLayerMediaType)
+}
+
 // ValidateReference for path and version
 func (c *Client) ValidateReference(ref, version string, u *url.URL) (*url.URL, error) {
 	var tag string
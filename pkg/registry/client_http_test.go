@@ -54,6 +54,14 @@ func (suite *HTTPRegistryClientTestSuite) Test_3_Tags() {
 	testTags(&suite.TestSuite)
 }
 
+func (suite *HTTPRegistryClientTestSuite) Test_5_Values() {
+	testValues(&suite.TestSuite)
+}
+
+func (suite *HTTPRegistryClientTestSuite) Test_6_Releases() {
+	testReleases(&suite.TestSuite)
+}
+
 func (suite *HTTPRegistryClientTestSuite) Test_4_ManInTheMiddle() {
 	ref := fmt.Sprintf("%s/testrepo/supposedlysafechart:9.9.9", suite.CompromisedRegistryHost)
 
@@ -34,4 +34,19 @@ const (
 
 	// LegacyChartLayerMediaType is the legacy reserved media type for Helm chart package content.
 	LegacyChartLayerMediaType = "application/tar+gzip"
+
+	// ValuesConfigMediaType is the reserved media type for the config of Helm values artifacts
+	ValuesConfigMediaType = "application/vnd.cncf.helm.values.config.v1+json"
+
+	// ValuesLayerMediaType is the reserved media type for the values file of Helm values artifacts
+	ValuesLayerMediaType = "application/vnd.cncf.helm.values.content.v1+yaml"
+
+	// ReleaseConfigMediaType is the reserved media type for the config of Helm release artifacts
+	ReleaseConfigMediaType = "application/vnd.cncf.helm.release.config.v1+json"
+
+	// ReleaseLayerMediaType is the reserved media type for the gzipped release record of Helm release artifacts
+	ReleaseLayerMediaType = "application/vnd.cncf.helm.release.content.v1.json+gzip"
+
+	// ReleaseLabelsAnnotation is the manifest annotation holding the labels of a Helm release artifact as JSON
+	ReleaseLabelsAnnotation = "sh.helm.release.labels"
 )
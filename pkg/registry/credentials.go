@@ -0,0 +1,59 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry // import "helm.sh/helm/v4/pkg/registry"
+
+import (
+	"encoding/base64"
+	"time"
+)
+
+// Credentials are the credentials of a registry or chart repository host.
+type Credentials struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Token is a bearer token. It takes precedence over the username and
+	// password.
+	Token string `json:"token,omitempty"`
+	// ClientCertificate and ClientKey are a PEM encoded client certificate
+	// and key. They are only used with chart repositories.
+	ClientCertificate string `json:"clientCertificate,omitempty"`
+	ClientKey         string `json:"clientKey,omitempty"`
+	// ExpiresAt is when the credentials expire. The zero value means they do
+	// not expire.
+	ExpiresAt time.Time `json:"expiresAt,omitempty"`
+}
+
+// AuthorizationHeader returns the value of the Authorization header for the
+// credentials, or an empty string when there is none.
+func (c *Credentials) AuthorizationHeader() string {
+	switch {
+	case c.Token != "":
+		return "Bearer " + c.Token
+	case c.Username != "" || c.Password != "":
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(c.Username+":"+c.Password))
+	}
+	return ""
+}
+
+// CredentialsProvider supplies credentials for hosts, e.g. from a cloud
+// credential helper. The registry client asks it for credentials when none
+// were given explicitly, before falling back to the credentials file.
+type CredentialsProvider interface {
+	// Credentials returns the credentials for the host, or nil when the
+	// provider has none.
+	Credentials(host string) (*Credentials, error)
+}
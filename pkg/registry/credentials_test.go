@@ -0,0 +1,34 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import "testing"
+
+func TestCredentialsAuthorizationHeader(t *testing.T) {
+	for _, tt := range []struct {
+		creds  Credentials
+		expect string
+	}{
+		{Credentials{Token: "abc", Username: "ignored"}, "Bearer abc"},
+		{Credentials{Username: "user", Password: "pass"}, "Basic dXNlcjpwYXNz"},
+		{Credentials{ClientCertificate: "cert", ClientKey: "key"}, ""},
+	} {
+		if got := tt.creds.AuthorizationHeader(); got != tt.expect {
+			t.Errorf("expected %q, got %q", tt.expect, got)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry // import "helm.sh/helm/v4/pkg/registry"
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/internal/transport"
+)
+
+// HostsFileBasename is the name of the file holding the proxy and TLS
+// settings of the registries. It is stored next to the credentials file.
+const HostsFileBasename = "registries.yaml"
+
+// HostsFilePath returns the path of the hosts file of the credentials file.
+func HostsFilePath(credentialsFile string) string {
+	return filepath.Join(filepath.Dir(credentialsFile), HostsFileBasename)
+}
+
+// hostname returns the host of a registry given with or without a scheme.
+func hostname(host string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://")
+}
+
+// HostConfig are the proxy, TLS and plain HTTP settings of a registry.
+type HostConfig struct {
+	// Proxy is the URL of the proxy used to reach the registry, e.g.
+	// "socks5://127.0.0.1:1080". When empty, the proxy environment variables
+	// are used.
+	Proxy string `json:"proxy,omitempty"`
+	// CertFile and KeyFile are the client certificate and key.
+	CertFile string `json:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty"`
+	// CAFile is a PEM file with the certificate authorities of the registry.
+	CAFile string `json:"caFile,omitempty"`
+	// InsecureSkipTLSVerify disables the verification of the certificate of
+	// the registry.
+	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty"`
+	// MinTLSVersion is the minimum TLS version used with the registry.
+	MinTLSVersion string `json:"minTLSVersion,omitempty"`
+	// PlainHTTP reaches the registry over HTTP instead of HTTPS.
+	PlainHTTP bool `json:"plainHTTP,omitempty"`
+}
+
+func (h HostConfig) transportOptions() transport.Options {
+	return transport.Options{
+		Proxy:                 h.Proxy,
+		CertFile:              h.CertFile,
+		KeyFile:               h.KeyFile,
+		CAFile:                h.CAFile,
+		InsecureSkipTLSVerify: h.InsecureSkipTLSVerify,
+		MinTLSVersion:         h.MinTLSVersion,
+	}
+}
+
+// HostsFile holds the settings of the registries by host, e.g. "ghcr.io" or
+// "localhost:5000":
+//
+//	hosts:
+//	  registry.example.com:
+//	    proxy: socks5://127.0.0.1:1080
+//	    caFile: /etc/ssl/example-ca.pem
+//	    minTLSVersion: "1.3"
+//	  localhost:5000:
+//	    plainHTTP: true
+type HostsFile struct {
+	Hosts map[string]HostConfig `json:"hosts"`
+}
+
+// LoadHostsFile loads a hosts file. A missing file is empty.
+func LoadHostsFile(path string) (*HostsFile, error) {
+	f := &HostsFile{Hosts: map[string]HostConfig{}}
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return f, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := yaml.UnmarshalStrict(b, f); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse the registry hosts file %s", path)
+	}
+	if f.Hosts == nil {
+		f.Hosts = map[string]HostConfig{}
+	}
+	return f, nil
+}
+
+// WriteFile writes the hosts file to the path, creating its directory.
+func (f *HostsFile) WriteFile(path string, perm os.FileMode) error {
+	b, err := yaml.Marshal(f)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, perm)
+}
+
+// hostsTransport sends the requests to the hosts of a hosts file through the
+// transports of their settings, and the other requests, e.g. to the token
+// servers of the registries, through the base transport.
+type hostsTransport struct {
+	base  http.RoundTripper
+	hosts map[string]http.RoundTripper
+}
+
+// newHostsTransport returns the transport of the hosts file. The TLS files and
+// the verification of tls, if any, take precedence over the settings of the
+// hosts.
+func newHostsTransport(base http.RoundTripper, f *HostsFile, tls *transport.Options) (*hostsTransport, error) {
+	t := &hostsTransport{base: base, hosts: map[string]http.RoundTripper{}}
+	for host, h := range f.Hosts {
+		o := h.transportOptions()
+		if tls != nil {
+			if tls.CertFile != "" && tls.KeyFile != "" {
+				o.CertFile, o.KeyFile = tls.CertFile, tls.KeyFile
+			}
+			if tls.CAFile != "" {
+				o.CAFile = tls.CAFile
+			}
+			o.InsecureSkipTLSVerify = o.InsecureSkipTLSVerify || tls.InsecureSkipTLSVerify
+		}
+		ht, err := transport.New(o)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid settings of registry %s", host)
+		}
+		t.hosts[strings.ToLower(host)] = ht
+	}
+	return t, nil
+}
+
+func (t *hostsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if ht, ok := t.hosts[strings.ToLower(req.URL.Host)]; ok {
+		return ht.RoundTrip(req)
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,206 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHostsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry", HostsFileBasename)
+	f, err := LoadHostsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Hosts) != 0 {
+		t.Fatalf("expected a missing file to be empty, got %v", f.Hosts)
+	}
+
+	f.Hosts["registry.example.com"] = HostConfig{Proxy: "socks5://127.0.0.1:1080", MinTLSVersion: "1.3"}
+	if err := f.WriteFile(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+	f, err = LoadHostsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := f.Hosts["registry.example.com"]; h.Proxy != "socks5://127.0.0.1:1080" || h.MinTLSVersion != "1.3" {
+		t.Errorf("unexpected settings %+v", h)
+	}
+}
+
+func TestClientOptHostsFile(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "proxied %s", r.URL.Host)
+	}))
+	defer proxy.Close()
+	direct := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "direct")
+	}))
+	defer direct.Close()
+
+	path := filepath.Join(t.TempDir(), HostsFileBasename)
+	f := &HostsFile{Hosts: map[string]HostConfig{"registry.example:5000": {Proxy: proxy.URL}}}
+	if err := f.WriteFile(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(ClientOptHostsFile(path), ClientOptCredentialsFile(filepath.Join(t.TempDir(), "config.json")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for url, expect := range map[string]string{
+		"http://registry.example:5000/v2/": "proxied registry.example:5000",
+		direct.URL:                         "direct",
+	} {
+		resp, err := client.httpClient.Get(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != expect {
+			t.Errorf("expected %q for %s, got %q", expect, url, body)
+		}
+	}
+
+	f.Hosts["registry.example:5000"] = HostConfig{Proxy: "ftp://proxy"}
+	if err := f.WriteFile(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewClient(ClientOptHostsFile(path)); err == nil {
+		t.Error("expected an invalid proxy to be rejected")
+	}
+}
+
+func TestLoginWithProxy(t *testing.T) {
+	// The proxy answers for the registry, which accepts user:pass.
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Host != "registry.example:5000" || r.URL.Path != "/v2/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer proxy.Close()
+
+	dir := t.TempDir()
+	hostsFile := filepath.Join(dir, HostsFileBasename)
+	credentialsFile := filepath.Join(dir, "config.json")
+	client, err := NewClient(ClientOptHostsFile(hostsFile), ClientOptCredentialsFile(credentialsFile), ClientOptPlainHTTP())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Login("registry.example:5000", LoginOptBasicAuth("user", "wrong"), LoginOptProxy(proxy.URL)); err == nil {
+		t.Fatal("expected the login to fail with a wrong password")
+	}
+	if err := client.Login("registry.example:5000", LoginOptBasicAuth("user", "pass"), LoginOptProxy(proxy.URL), LoginOptInsecure(true)); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "registry.example:5000") {
+		t.Errorf("expected the credentials to be stored, got %s", b)
+	}
+	f, err := LoadHostsFile(hostsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := f.Hosts["registry.example:5000"]; h != (HostConfig{Proxy: proxy.URL}) {
+		t.Errorf("expected only the proxy to be saved without LoginOptSaveTLS, got %+v", h)
+	}
+
+	if err := client.Login("registry.example:5000", LoginOptBasicAuth("user", "pass"), LoginOptProxy(proxy.URL), LoginOptInsecure(true), LoginOptSaveTLS(true)); err != nil {
+		t.Fatal(err)
+	}
+	if f, err = LoadHostsFile(hostsFile); err != nil {
+		t.Fatal(err)
+	}
+	if h := f.Hosts["registry.example:5000"]; !h.InsecureSkipTLSVerify {
+		t.Errorf("expected the TLS settings to be saved with LoginOptSaveTLS, got %+v", h)
+	}
+}
+
+func TestClientOptTLSClientConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), HostsFileBasename)
+	f := &HostsFile{Hosts: map[string]HostConfig{"registry.example:5000": {Proxy: "socks5://127.0.0.1:1080", MinTLSVersion: "1.3"}}}
+	if err := f.WriteFile(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(ClientOptHostsFile(path), ClientOptTLSClientConfig("", "", "", true), ClientOptCredentialsFile(filepath.Join(t.TempDir(), "config.json")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hosts := client.httpClient.Transport.(*hostsTransport)
+	for name, rt := range map[string]http.RoundTripper{"base": hosts.base, "host": hosts.hosts["registry.example:5000"]} {
+		if tr := rt.(*http.Transport); tr.TLSClientConfig == nil || !tr.TLSClientConfig.InsecureSkipVerify {
+			t.Errorf("expected the %s transport to skip the TLS verification", name)
+		}
+	}
+	if tr := hosts.hosts["registry.example:5000"].(*http.Transport); tr.TLSClientConfig.MinVersion != tls.VersionTLS13 {
+		t.Error("expected the host transport to keep its minimum TLS version")
+	}
+}
+
+func TestClientPingPlainHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	path := filepath.Join(t.TempDir(), HostsFileBasename)
+	client, err := NewClient(ClientOptHostsFile(path), ClientOptCredentialsFile(filepath.Join(t.TempDir(), "config.json")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Ping(context.Background(), host); err == nil {
+		t.Error("expected the registry to be requested over HTTPS without the plain HTTP setting")
+	}
+
+	f := &HostsFile{Hosts: map[string]HostConfig{host: {PlainHTTP: true}}}
+	if err := f.WriteFile(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+	client, err = NewClient(ClientOptHostsFile(path), ClientOptCredentialsFile(filepath.Join(t.TempDir(), "config.json")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Ping(context.Background(), host); err != nil {
+		t.Errorf("expected the registry to be reachable over HTTP: %s", err)
+	}
+}
@@ -0,0 +1,258 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry // import "helm.sh/helm/v4/pkg/registry"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/remotes"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"oras.land/oras-go/pkg/content"
+	"oras.land/oras-go/pkg/oras"
+	"oras.land/oras-go/pkg/registry"
+	registryremote "oras.land/oras-go/pkg/registry/remote"
+	registryauth "oras.land/oras-go/pkg/registry/remote/auth"
+)
+
+// ErrNotFound indicates that a reference or a repository does not exist.
+var ErrNotFound = errors.New("not found")
+
+// maxManifestSize is the maximum size of a release artifact manifest.
+const maxManifestSize = 4 * 1024 * 1024
+
+// PushRelease uploads a release record to a registry as a release artifact,
+// which holds the record in a single layer with ReleaseLayerMediaType and the
+// labels of the record in the ReleaseLabelsAnnotation of the manifest. An
+// existing tag is moved to the new artifact.
+//
+// Unlike Push, PushRelease does not print the pushed reference, as release
+// records are written by every install, upgrade and rollback.
+func (c *Client) PushRelease(data []byte, labels map[string]string, ref string) error {
+	parsedRef, err := newReference(ref)
+	if err != nil {
+		return err
+	}
+	labelData, err := json.Marshal(labels)
+	if err != nil {
+		return err
+	}
+
+	memoryStore := content.NewMemory()
+	releaseDescriptor, err := memoryStore.Add("", ReleaseLayerMediaType, data)
+	if err != nil {
+		return err
+	}
+	configDescriptor, err := memoryStore.Add("", ReleaseConfigMediaType, []byte("{}"))
+	if err != nil {
+		return err
+	}
+	annotations := map[string]string{ReleaseLabelsAnnotation: string(labelData)}
+	manifestData, manifest, err := content.GenerateManifest(&configDescriptor, annotations, releaseDescriptor)
+	if err != nil {
+		return err
+	}
+	if err := memoryStore.StoreManifest(parsedRef.String(), manifest, manifestData); err != nil {
+		return err
+	}
+
+	remotesResolver, err := c.resolver(parsedRef.orasReference)
+	if err != nil {
+		return err
+	}
+	registryStore := content.Registry{Resolver: remotesResolver}
+	_, err = oras.Copy(ctx(c.out, c.debug), memoryStore, parsedRef.orasReference.String(), registryStore, "",
+		oras.WithNameValidation(nil))
+	return err
+}
+
+// PullRelease downloads the record and the labels of a release artifact, see
+// PushRelease. The error wraps ErrNotFound when the reference does not exist.
+func (c *Client) PullRelease(ref string) ([]byte, map[string]string, error) {
+	manifest, fetcher, err := c.fetchReleaseManifest(ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	labels, err := releaseLabels(manifest)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "invalid release artifact %s", ref)
+	}
+
+	for _, l := range manifest.Layers {
+		if l.MediaType != ReleaseLayerMediaType {
+			continue
+		}
+		rc, err := fetcher.Fetch(ctx(c.out, c.debug), l)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(io.LimitReader(rc, l.Size))
+		if err != nil {
+			return nil, nil, err
+		}
+		return data, labels, nil
+	}
+	return nil, nil, errors.Errorf("manifest does not contain a layer with mediatype %s", ReleaseLayerMediaType)
+}
+
+// ReleaseLabels returns the labels of a release artifact without downloading
+// the record. The error wraps ErrNotFound when the reference does not exist.
+func (c *Client) ReleaseLabels(ref string) (map[string]string, error) {
+	manifest, _, err := c.fetchReleaseManifest(ref)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := releaseLabels(manifest)
+	return labels, errors.Wrapf(err, "invalid release artifact %s", ref)
+}
+
+// DeleteRelease deletes the manifest of a release artifact, which removes its
+// tag. The registry must allow deleting manifests. The error wraps ErrNotFound
+// when the reference does not exist.
+func (c *Client) DeleteRelease(ref string) error {
+	parsedRef, err := newReference(ref)
+	if err != nil {
+		return err
+	}
+	desc, err := c.Resolve(ref)
+	if err != nil {
+		return notFound(err, ref)
+	}
+
+	url := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", c.scheme(parsedRef.Registry), parsedRef.Registry, parsedRef.Repository, desc.Digest)
+	reqCtx := registryauth.WithScopes(context.Background(), registryauth.ScopeRepository(parsedRef.Repository, registryauth.ActionDelete))
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.registryAuthorizer.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusAccepted, http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return errors.Wrapf(ErrNotFound, "%s", ref)
+	default:
+		return errors.Errorf("failed to delete %s: unexpected status code %d", ref, resp.StatusCode)
+	}
+}
+
+// ListTags lists all tags of a repository. Unlike Tags, it does not filter or
+// sort the tags. The error wraps ErrNotFound when the repository does not
+// exist.
+func (c *Client) ListTags(ref string) ([]string, error) {
+	parsedReference, err := registry.ParseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &statusRecorder{RemoteClient: c.registryAuthorizer}
+	repository := registryremote.Repository{
+		Reference: parsedReference,
+		Client:    client,
+		PlainHTTP: c.isPlainHTTP(parsedReference.Registry),
+	}
+
+	var tags []string
+	err = repository.Tags(ctx(c.out, c.debug), func(page []string) error {
+		tags = append(tags, page...)
+		return nil
+	})
+	if err != nil && client.status == http.StatusNotFound {
+		return nil, errors.Wrapf(ErrNotFound, "%s", ref)
+	}
+	return tags, err
+}
+
+// fetchReleaseManifest resolves a reference and fetches its manifest, which
+// must be the manifest of a release artifact.
+func (c *Client) fetchReleaseManifest(ref string) (*ocispec.Manifest, remotes.Fetcher, error) {
+	parsedRef, err := newReference(ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	remotesResolver, err := c.resolver(parsedRef.orasReference)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fetchCtx := ctx(c.out, c.debug)
+	name, desc, err := remotesResolver.Resolve(fetchCtx, parsedRef.orasReference.String())
+	if err != nil {
+		return nil, nil, notFound(err, ref)
+	}
+	fetcher, err := remotesResolver.Fetcher(fetchCtx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	rc, err := fetcher.Fetch(fetchCtx, desc)
+	if err != nil {
+		return nil, nil, notFound(err, ref)
+	}
+	defer rc.Close()
+
+	var manifest ocispec.Manifest
+	if err := json.NewDecoder(io.LimitReader(rc, maxManifestSize)).Decode(&manifest); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to decode the manifest of %s", ref)
+	}
+	if manifest.Config.MediaType != ReleaseConfigMediaType {
+		return nil, nil, errors.Errorf("%s is not a release artifact: config media type is %q", ref, manifest.Config.MediaType)
+	}
+	return &manifest, fetcher, nil
+}
+
+func releaseLabels(manifest *ocispec.Manifest) (map[string]string, error) {
+	labels := map[string]string{}
+	if data, ok := manifest.Annotations[ReleaseLabelsAnnotation]; ok {
+		if err := json.Unmarshal([]byte(data), &labels); err != nil {
+			return nil, errors.Wrap(err, "invalid labels")
+		}
+	}
+	return labels, nil
+}
+
+// notFound wraps ErrNotFound when err is a not found error of the resolver.
+func notFound(err error, ref string) error {
+	if errdefs.IsNotFound(err) {
+		return errors.Wrapf(ErrNotFound, "%s", ref)
+	}
+	return err
+}
+
+// statusRecorder records the status code of the last response.
+type statusRecorder struct {
+	RemoteClient
+	status int
+}
+
+func (r *statusRecorder) Do(req *http.Request) (*http.Response, error) {
+	resp, err := r.RemoteClient.Do(req)
+	if err == nil {
+		r.status = resp.StatusCode
+	}
+	return resp, err
+}
@@ -19,15 +19,20 @@ package registry // import "helm.sh/helm/v4/pkg/registry"
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	helmtime "helm.sh/helm/v4/pkg/time"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -210,6 +215,45 @@ func generateChartOCIAnnotations(meta *chart.Metadata, creationTime string) map[
 	return chartOCIAnnotations
 }
 
+// mergeOCIAnnotations adds the extra annotations to the generated ones,
+// leaving out the immutable ones.
+func mergeOCIAnnotations(ociAnnotations, extra map[string]string) map[string]string {
+	for k, v := range extra {
+		if slices.Contains(immutableOciAnnotations, k) {
+			continue
+		}
+		ociAnnotations[k] = v
+	}
+	return ociAnnotations
+}
+
+// generateArtifactManifest generates the manifest of an artifact with the
+// given artifactType, like content.GenerateManifest does for images.
+func generateArtifactManifest(config ocispec.Descriptor, artifactType string, annotations map[string]string, layers []ocispec.Descriptor) ([]byte, ocispec.Descriptor, error) {
+	layers = slices.Clone(layers)
+	sort.Slice(layers, func(i, j int) bool {
+		return layers[i].Digest < layers[j].Digest
+	})
+	manifest := ocispec.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: artifactType,
+		Config:       config,
+		Layers:       layers,
+		Annotations:  annotations,
+	}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, ocispec.Descriptor{}, err
+	}
+	desc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(data),
+		Size:      int64(len(data)),
+	}
+	return data, desc, nil
+}
+
 // addToMap takes an existing map and adds an item if the value is not empty
 func addToMap(inputMap map[string]string, newKey string, newValue string) map[string]string {
 
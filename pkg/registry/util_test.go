@@ -273,3 +273,25 @@ func TestGenerateOCICreatedAnnotations(t *testing.T) {
 	}
 
 }
+
+func TestMergeOCIAnnotations(t *testing.T) {
+	generated := generateOCIAnnotations(&chart.Metadata{Name: "oci", Version: "0.0.1", Sources: []string{"https://example.com/a"}}, "1977-09-02T22:04:05Z")
+	result := mergeOCIAnnotations(generated, map[string]string{
+		ocispec.AnnotationSource:   "https://example.com/b",
+		ocispec.AnnotationRevision: "abc123",
+		ocispec.AnnotationVersion:  "9.9.9",
+		"com.example/team":         "platform",
+	})
+
+	expected := map[string]string{
+		ocispec.AnnotationTitle:    "oci",
+		ocispec.AnnotationVersion:  "0.0.1",
+		ocispec.AnnotationCreated:  "1977-09-02T22:04:05Z",
+		ocispec.AnnotationSource:   "https://example.com/b",
+		ocispec.AnnotationRevision: "abc123",
+		"com.example/team":         "platform",
+	}
+	if !reflect.DeepEqual(expected, result) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net"
@@ -36,6 +37,7 @@ import (
 	_ "github.com/distribution/distribution/v3/registry/auth/htpasswd"
 	_ "github.com/distribution/distribution/v3/registry/storage/driver/inmemory"
 	"github.com/foxcpp/go-mockdns"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/phayes/freeport"
 	"github.com/stretchr/testify/suite"
 	"golang.org/x/crypto/bcrypt"
@@ -139,7 +141,10 @@ func setup(suite *TestSuite, tlsEnabled, insecure bool) *registry.Registry {
 
 	config.HTTP.Addr = fmt.Sprintf(":%d", port)
 	config.HTTP.DrainTimeout = time.Duration(10) * time.Second
-	config.Storage = map[string]configuration.Parameters{"inmemory": map[string]interface{}{}}
+	config.Storage = map[string]configuration.Parameters{
+		"inmemory": map[string]interface{}{},
+		"delete":   map[string]interface{}{"enabled": true},
+	}
 
 	// Basic auth is not possible if we are serving HTTP.
 	if tlsEnabled {
@@ -302,6 +307,22 @@ func testPush(suite *TestSuite) {
 	suite.Equal(
 		"sha256:b0a02b7412f78ae93324d48df8fcc316d8482e5ad7827b5b238657a29a22f256",
 		result.Prov.Digest)
+
+	// push with annotations and an artifact type
+	ref = fmt.Sprintf("%s/testrepo/annotated/%s:%s", suite.DockerRegistryHost, meta.Name, meta.Version)
+	_, err = suite.RegistryClient.Push(chartData, ref,
+		PushOptCreationTime(testingChartCreationTime),
+		PushOptAnnotations(map[string]string{ocispec.AnnotationRevision: "abc123"}),
+		PushOptArtifactType("application/vnd.example.chart"))
+	suite.Nil(err, "no error pushing with annotations")
+
+	pulled, err := suite.RegistryClient.Pull(ref)
+	suite.Nil(err, "no error pulling an annotated chart")
+	var manifest ocispec.Manifest
+	suite.Nil(json.Unmarshal(pulled.Manifest.Data, &manifest))
+	suite.Equal("application/vnd.example.chart", manifest.ArtifactType)
+	suite.Equal("abc123", manifest.Annotations[ocispec.AnnotationRevision])
+	suite.Equal(meta.Name, manifest.Annotations[ocispec.AnnotationTitle])
 }
 
 func testPull(suite *TestSuite) {
@@ -381,6 +402,73 @@ func testPull(suite *TestSuite) {
 		string(result.Config.Data))
 	suite.Equal(chartData, result.Chart.Data)
 	suite.Equal(provData, result.Prov.Data)
+
+	// the digest of the chart layer is resolved from the manifest only
+	digest, err := suite.RegistryClient.ChartDigest(ref)
+	suite.Require().Nil(err, "no error resolving the chart digest")
+	suite.Equal(result.Chart.Digest, digest)
+}
+
+func testValues(suite *TestSuite) {
+	ref := fmt.Sprintf("%s/testrepo/values:prod", suite.DockerRegistryHost)
+	data := []byte("replicaCount: 3\nimage:\n  tag: \"1.0\"\n")
+
+	_, err := suite.RegistryClient.PushValues([]byte("- not a map"), ref)
+	suite.NotNil(err, "error pushing invalid values")
+
+	pushed, err := suite.RegistryClient.PushValues(data, ref)
+	suite.Require().Nil(err, "no error pushing values")
+	suite.Equal(ref, pushed.Ref)
+
+	result, err := suite.RegistryClient.PullValues(ref)
+	suite.Require().Nil(err, "no error pulling values")
+	suite.Equal(data, result.Values.Data)
+	suite.Equal(pushed.Values.Digest, result.Values.Digest)
+	suite.Equal(pushed.Manifest.Digest, result.Manifest.Digest)
+
+	// a chart is not a values artifact
+	chartData, err := os.ReadFile("../downloader/testdata/local-subchart-0.1.0.tgz")
+	suite.Nil(err, "no error loading test chart")
+	meta, err := extractChartMeta(chartData)
+	suite.Nil(err, "no error extracting chart meta")
+	_, err = suite.RegistryClient.PullValues(fmt.Sprintf("%s/testrepo/%s:%s", suite.DockerRegistryHost, meta.Name, meta.Version))
+	suite.NotNil(err, "error pulling values from a chart")
+}
+
+func testReleases(suite *TestSuite) {
+	repo := fmt.Sprintf("%s/releases/default", suite.DockerRegistryHost)
+	ref := repo + ":sh.helm.release.v1.web.v1"
+	labels := map[string]string{"name": "web", "status": "deployed"}
+
+	_, err := suite.RegistryClient.ListTags(repo)
+	suite.ErrorIs(err, ErrNotFound, "no tags in a missing repository")
+	_, _, err = suite.RegistryClient.PullRelease(ref)
+	suite.ErrorIs(err, ErrNotFound, "missing release")
+
+	suite.Require().Nil(suite.RegistryClient.PushRelease([]byte("release"), labels, ref), "no error pushing release")
+	suite.Require().Nil(suite.RegistryClient.PushRelease([]byte("other"), nil, repo+":sh.helm.release.v1.web.v2"), "no error pushing release")
+
+	data, got, err := suite.RegistryClient.PullRelease(ref)
+	suite.Require().Nil(err, "no error pulling release")
+	suite.Equal([]byte("release"), data)
+	suite.Equal(labels, got)
+
+	got, err = suite.RegistryClient.ReleaseLabels(ref)
+	suite.Nil(err, "no error getting release labels")
+	suite.Equal(labels, got)
+
+	tags, err := suite.RegistryClient.ListTags(repo)
+	suite.Nil(err, "no error listing tags")
+	suite.ElementsMatch([]string{"sh.helm.release.v1.web.v1", "sh.helm.release.v1.web.v2"}, tags)
+
+	suite.Nil(suite.RegistryClient.DeleteRelease(ref), "no error deleting release")
+	_, err = suite.RegistryClient.ReleaseLabels(ref)
+	suite.ErrorIs(err, ErrNotFound, "deleted release")
+	suite.ErrorIs(suite.RegistryClient.DeleteRelease(ref), ErrNotFound, "deleting a deleted release")
+
+	// a values artifact is not a release artifact
+	_, _, err = suite.RegistryClient.PullRelease(fmt.Sprintf("%s/testrepo/values:prod", suite.DockerRegistryHost))
+	suite.NotNil(err, "error pulling a release from a values artifact")
 }
 
 func testTags(suite *TestSuite) {
@@ -395,4 +483,33 @@ func testTags(suite *TestSuite) {
 	tags, err := suite.RegistryClient.Tags(ref)
 	suite.Nil(err, "no error retrieving tags")
 	suite.Equal(1, len(tags))
+
+	// Push several versions to list them
+	ref = fmt.Sprintf("%s/testrepo/versions/%s", suite.DockerRegistryHost, meta.Name)
+	for _, tag := range []string{"0.2.0", "1.0.0-rc.1", "1.0.0", "1.5.0", "2.0.0", "latest"} {
+		_, err := suite.RegistryClient.Push(chartData, ref+":"+tag, PushOptStrictMode(false))
+		suite.Nil(err, "no error pushing %s", tag)
+	}
+
+	res, err := suite.RegistryClient.Versions("oci://"+ref, VersionsOptions{})
+	suite.Nil(err, "no error listing versions")
+	suite.Equal(&VersionsResult{Versions: []string{"2.0.0", "1.5.0", "1.0.0", "0.2.0"}, Total: 4}, res)
+
+	res, err = suite.RegistryClient.Versions(ref, VersionsOptions{Devel: true, Offset: 1, Limit: 2})
+	suite.Nil(err, "no error listing versions")
+	suite.Equal(&VersionsResult{Versions: []string{"1.5.0", "1.0.0"}, Total: 5}, res)
+
+	res, err = suite.RegistryClient.Versions(ref, VersionsOptions{Constraint: ">=1.0 <2"})
+	suite.Nil(err, "no error listing versions")
+	suite.Equal(&VersionsResult{Versions: []string{"1.5.0", "1.0.0"}, Total: 2}, res)
+
+	res, err = suite.RegistryClient.Versions(ref, VersionsOptions{Offset: 10})
+	suite.Nil(err, "no error listing versions")
+	suite.Equal(&VersionsResult{Versions: []string{}, Total: 4}, res)
+
+	_, err = suite.RegistryClient.Versions(ref, VersionsOptions{Constraint: "not a constraint"})
+	suite.ErrorContains(err, "invalid version constraint")
+
+	_, err = suite.RegistryClient.Versions(fmt.Sprintf("%s/testrepo/no-existy", suite.DockerRegistryHost), VersionsOptions{})
+	suite.ErrorIs(err, ErrNotFound)
 }
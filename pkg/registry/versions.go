@@ -0,0 +1,115 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry // import "helm.sh/helm/v4/pkg/registry"
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
+)
+
+// VersionsOptions select the versions returned by Client.Versions.
+type VersionsOptions struct {
+	// Constraint is a semver constraint the versions must satisfy, e.g.
+	// ">=1.2 <2". Empty matches all versions.
+	Constraint string
+	// Devel includes the prerelease versions when Constraint is empty. As for
+	// chart repositories, a constraint only matches prerelease versions when
+	// it contains a prerelease itself, e.g. ">=1.2.0-0".
+	Devel bool
+	// Offset is the number of versions to skip, to fetch the next page.
+	Offset int
+	// Limit is the maximum number of versions returned, 0 for all.
+	Limit int
+}
+
+// VersionsResult is a page of the versions of a chart.
+type VersionsResult struct {
+	// Versions are sorted from the latest to the oldest.
+	Versions []string `json:"versions"`
+	// Total is the number of versions matching the options, in all pages.
+	Total int `json:"total"`
+}
+
+// Versions lists the versions of the chart at ref, e.g.
+// "registry.example.com/charts/mychart", that match the options. The tags of
+// the repository that are not semantic versions are ignored. The error wraps
+// ErrNotFound when the repository does not exist.
+func (c *Client) Versions(ref string, opts VersionsOptions) (*VersionsResult, error) {
+	constraint, err := versionsConstraint(opts)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := c.ListTags(strings.TrimPrefix(ref, fmt.Sprintf("%s://", OCIScheme)))
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []*semver.Version
+	for _, v := range tagVersions(tags) {
+		if constraint.Check(v) {
+			versions = append(versions, v)
+		}
+	}
+
+	result := &VersionsResult{Versions: []string{}, Total: len(versions)}
+	if opts.Offset >= len(versions) {
+		return result, nil
+	}
+	versions = versions[max(opts.Offset, 0):]
+	if opts.Limit > 0 && opts.Limit < len(versions) {
+		versions = versions[:opts.Limit]
+	}
+	for _, v := range versions {
+		result.Versions = append(result.Versions, v.String())
+	}
+	return result, nil
+}
+
+func versionsConstraint(opts VersionsOptions) (*semver.Constraints, error) {
+	expr := opts.Constraint
+	if expr == "" {
+		expr = "*"
+		if opts.Devel {
+			expr = ">0.0.0-0"
+		}
+	}
+	constraint, err := semver.NewConstraint(expr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid version constraint %q", opts.Constraint)
+	}
+	return constraint, nil
+}
+
+// tagVersions returns the tags that are semantic versions, sorted from the
+// latest to the oldest.
+func tagVersions(tags []string) []*semver.Version {
+	var versions []*semver.Version
+	for _, tag := range tags {
+		// Change underscore (_) back to plus (+) for Helm
+		// See https://github.com/helm/helm/issues/10166
+		v, err := semver.StrictNewVersion(strings.ReplaceAll(tag, "_", "+"))
+		if err == nil {
+			versions = append(versions, v)
+		}
+	}
+	sort.Sort(sort.Reverse(semver.Collection(versions)))
+	return versions
+}
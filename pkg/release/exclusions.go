@@ -0,0 +1,42 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+// ResourceExclusions select rendered resources that are left out of a
+// revision: they are neither applied nor tracked by the release, so they are
+// not deleted when they were part of the previous revision either. This is
+// meant for resources the user cannot apply, e.g. the PodSecurityPolicies of
+// a chart on a cluster that no longer serves them.
+type ResourceExclusions struct {
+	// Kinds are the kinds of the excluded resources, e.g.
+	// "PodSecurityPolicy", matched case-insensitively.
+	Kinds []string `json:"kinds,omitempty"`
+	// Resources are the excluded resources as "KIND/NAME", e.g.
+	// "Secret/credentials".
+	Resources []string `json:"resources,omitempty"`
+	// Selector is a label selector of the excluded resources, e.g.
+	// "app.kubernetes.io/component=legacy".
+	Selector string `json:"selector,omitempty"`
+	// Excluded are the resources and hooks that were left out of the
+	// revision, as "KIND/NAME".
+	Excluded []string `json:"excluded,omitempty"`
+}
+
+// IsZero reports whether no resource is excluded.
+func (e *ResourceExclusions) IsZero() bool {
+	return e == nil || (len(e.Kinds) == 0 && len(e.Resources) == 0 && e.Selector == "")
+}
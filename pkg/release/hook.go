@@ -17,6 +17,8 @@ limitations under the License.
 package release
 
 import (
+	gotime "time"
+
 	"helm.sh/helm/v4/pkg/time"
 )
 
@@ -34,6 +36,11 @@ const (
 	HookPreRollback  HookEvent = "pre-rollback"
 	HookPostRollback HookEvent = "post-rollback"
 	HookTest         HookEvent = "test"
+
+	// HookPostUninstallFailed hooks run when an uninstall fails, in its
+	// pre-delete hooks, while deleting the resources or in its post-delete
+	// hooks, so that charts can clean up after a partial uninstall.
+	HookPostUninstallFailed HookEvent = "post-uninstall-failed"
 )
 
 func (x HookEvent) String() string { return string(x) }
@@ -59,6 +66,9 @@ const HookWeightAnnotation = "helm.sh/hook-weight"
 // HookDeleteAnnotation is the label name for the delete policy for a hook
 const HookDeleteAnnotation = "helm.sh/hook-delete-policy"
 
+// HookTimeoutAnnotation is the label name for the timeout of a hook
+const HookTimeoutAnnotation = "helm.sh/hook-timeout"
+
 // Hook defines a hook object.
 type Hook struct {
 	Name string `json:"name,omitempty"`
@@ -76,6 +86,9 @@ type Hook struct {
 	Weight int `json:"weight,omitempty"`
 	// DeletePolicies are the policies that indicate when to delete the hook
 	DeletePolicies []HookDeletePolicy `json:"delete_policies,omitempty"`
+	// Timeout is the time to wait for the hook to complete, overriding the
+	// timeout of the operation when set.
+	Timeout gotime.Duration `json:"timeout,omitempty"`
 }
 
 // A HookExecution records the result for the last execution of a hook for a given release.
@@ -86,6 +99,16 @@ type HookExecution struct {
 	CompletedAt time.Time `json:"completed_at,omitempty"`
 	// Phase indicates whether the hook completed successfully
 	Phase HookPhase `json:"phase"`
+	// Event is the event the hook was last run for.
+	Event HookEvent `json:"event,omitempty"`
+	// Attempts is the number of times the hook was run for the revision.
+	Attempts int `json:"attempts,omitempty"`
+	// Message is the error the hook failed with.
+	Message string `json:"message,omitempty"`
+	// ExitCode is the exit code of the pod run by a Pod or a Job hook.
+	ExitCode *int32 `json:"exit_code,omitempty"`
+	// LogTail is the end of the logs of the pod run by a Pod or a Job hook.
+	LogTail string `json:"log_tail,omitempty"`
 }
 
 // A HookPhase indicates the state of a hook execution
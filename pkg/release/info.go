@@ -31,10 +31,41 @@ type Info struct {
 	Deleted time.Time `json:"deleted"`
 	// Description is human-friendly "log entry" about this release.
 	Description string `json:"description,omitempty"`
+	// Operator is the user or service account that created this revision,
+	// if known.
+	Operator string `json:"operator,omitempty"`
+	// ClientVersion is the version of the Helm client that created this
+	// revision, if known.
+	ClientVersion string `json:"client_version,omitempty"`
 	// Status is the current state of the release
 	Status Status `json:"status,omitempty"`
 	// Contains the rendered templates/NOTES.txt if available
 	Notes string `json:"notes,omitempty"`
 	// Contains the deployed resources information
 	Resources map[string][]runtime.Object `json:"resources,omitempty"`
+	// Reason is the outcome of the operation that created the revision, of
+	// which Description is the human-readable form.
+	Reason Reason `json:"reason,omitempty"`
+	// ErrorDetail is the error the operation failed with, if any.
+	ErrorDetail string `json:"error_detail,omitempty"`
+	// OperatorMessage is the description of the operation given by its
+	// operator, e.g. with --description.
+	OperatorMessage string `json:"operator_message,omitempty"`
+	// Outputs are the status fields of the live resources, declared by the
+	// chart with the helm.sh/status-field annotation.
+	Outputs []*Output `json:"outputs,omitempty"`
+}
+
+// Output is a status field of a live resource of the release.
+type Output struct {
+	// Resource is the kind and name of the resource, e.g. "Service/web".
+	Resource  string `json:"resource"`
+	Namespace string `json:"namespace,omitempty"`
+	// Expression is the JSONPath expression of the annotation.
+	Expression string `json:"expression"`
+	// Value is empty when the field is not set yet, e.g. the address of a
+	// load balancer being provisioned.
+	Value string `json:"value"`
+	// Error is set when the field could not be evaluated.
+	Error string `json:"error,omitempty"`
 }
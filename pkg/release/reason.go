@@ -0,0 +1,67 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+// Reason is the machine-readable outcome of the operation that created a
+// revision. Unlike the description, it does not change with the wording of
+// the messages, so tools can rely on it to tell the failures apart.
+type Reason string
+
+// Release reasons
+const (
+	// ReasonPending is the reason of a revision whose operation is running.
+	ReasonPending Reason = "Pending"
+	// ReasonInstalled is the reason of a successful install.
+	ReasonInstalled Reason = "Installed"
+	// ReasonUpgraded is the reason of a successful upgrade.
+	ReasonUpgraded Reason = "Upgraded"
+	// ReasonRolledBack is the reason of a successful rollback.
+	ReasonRolledBack Reason = "RolledBack"
+	// ReasonUninstalled is the reason of a successful uninstall.
+	ReasonUninstalled Reason = "Uninstalled"
+	// ReasonDryRun is the reason of a dry run.
+	ReasonDryRun Reason = "DryRun"
+	// ReasonImported is the reason of a release created from existing
+	// resources of the cluster.
+	ReasonImported Reason = "Imported"
+
+	// ReasonFailed is the reason of a failure that is not told apart.
+	ReasonFailed Reason = "Failed"
+	// ReasonRenderFailed is the reason of a failure to render the chart.
+	ReasonRenderFailed Reason = "RenderFailed"
+	// ReasonHookFailed is the reason of a failure of a hook.
+	ReasonHookFailed Reason = "HookFailed"
+	// ReasonApplyFailed is the reason of a failure to create, update or
+	// delete the resources of the release.
+	ReasonApplyFailed Reason = "ApplyFailed"
+	// ReasonWaitFailed is the reason of resources that did not become ready.
+	ReasonWaitFailed Reason = "WaitFailed"
+	// ReasonInterrupted is the reason of an operation that was cancelled or
+	// timed out.
+	ReasonInterrupted Reason = "Interrupted"
+)
+
+func (r Reason) String() string { return string(r) }
+
+// IsFailure reports whether the reason is a failure.
+func (r Reason) IsFailure() bool {
+	switch r {
+	case ReasonFailed, ReasonRenderFailed, ReasonHookFailed, ReasonApplyFailed, ReasonWaitFailed, ReasonInterrupted:
+		return true
+	}
+	return false
+}
@@ -40,6 +40,20 @@ type Release struct {
 	// Labels of the release.
 	// Disabled encoding into Json cause labels are stored in storage driver metadata field.
 	Labels map[string]string `json:"-"`
+	// PinnedValues are the values pinned to the release by an operator. They
+	// are carried over to every new revision and merged into the values
+	// upgrades render with, but not into Config.
+	PinnedValues map[string]interface{} `json:"pinned_values,omitempty"`
+	// ConfigRaw is the values file supplied by the user as written, with its
+	// anchors, merge keys and comments, when it was kept for the release.
+	// Config holds the same values with the anchors expanded.
+	ConfigRaw string `json:"config_raw,omitempty"`
+	// ValuesSources are the inputs the user supplied the values of this
+	// revision with, in order, when they were recorded.
+	ValuesSources []*ValuesSource `json:"values_sources,omitempty"`
+	// Exclusions are the rules the rendered resources were excluded from
+	// this revision with, and the excluded resources, when any were set.
+	Exclusions *ResourceExclusions `json:"exclusions,omitempty"`
 }
 
 // SetStatus is a helper for setting the status on a release.
@@ -47,3 +61,11 @@ func (r *Release) SetStatus(status Status, msg string) {
 	r.Info.Status = status
 	r.Info.Description = msg
 }
+
+// SetFailed is a helper for setting the failed status on a release, with the
+// reason and the error of the failure.
+func (r *Release) SetFailed(reason Reason, err error, msg string) {
+	r.SetStatus(StatusFailed, msg)
+	r.Info.Reason = reason
+	r.Info.ErrorDetail = err.Error()
+}
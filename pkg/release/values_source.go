@@ -0,0 +1,52 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import "fmt"
+
+// ValuesSource is an input the user supplied the values of a revision with,
+// e.g. a values file or a --set flag, recorded so that the revision can be
+// reproduced.
+type ValuesSource struct {
+	// Flag is the flag of the input, without dashes: "values", "set",
+	// "set-string", "set-file", "set-json" or "set-literal".
+	Flag string `json:"flag"`
+	// Value is the argument of the flag: the path or URL of a values file, or
+	// the values set by the other flags. When Redacted, the user information
+	// and the query of a URL are removed, and the values of a --set flag are
+	// replaced by the values it sets as JSON, with the sensitive values
+	// redacted.
+	Value string `json:"value"`
+	// Digest is the SHA-256 digest of a values file, e.g. "sha256:4f1a...".
+	Digest string `json:"digest,omitempty"`
+	// Content is the content of a values file, when it was recorded. When
+	// Redacted, it is the values of the file with the sensitive values
+	// redacted.
+	Content string `json:"content,omitempty"`
+	// Redacted is set when sensitive values were removed from Value or
+	// Content.
+	Redacted bool `json:"redacted,omitempty"`
+}
+
+// String returns the input as a command line argument, followed by the
+// digest of a values file.
+func (s *ValuesSource) String() string {
+	if s.Digest != "" {
+		return fmt.Sprintf("--%s %s (%s)", s.Flag, s.Value, s.Digest)
+	}
+	return fmt.Sprintf("--%s %s", s.Flag, s.Value)
+}
@@ -0,0 +1,84 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releaseutil
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// ManifestFormat is the format in which rendered manifests are emitted.
+type ManifestFormat string
+
+const (
+	// ManifestFormatRendered keeps the manifests as the templates rendered them.
+	ManifestFormatRendered ManifestFormat = ""
+	// ManifestFormatYAML normalizes the manifests to YAML with sorted keys
+	// and without comments.
+	ManifestFormatYAML ManifestFormat = "yaml"
+	// ManifestFormatJSON converts the manifests to compact JSON documents
+	// with sorted keys, one per resource.
+	ManifestFormatJSON ManifestFormat = "json"
+)
+
+// ParseManifestFormat parses the name of a manifest format. "rendered" is
+// accepted for ManifestFormatRendered.
+func ParseManifestFormat(s string) (ManifestFormat, error) {
+	switch f := ManifestFormat(s); f {
+	case ManifestFormatRendered, ManifestFormatYAML, ManifestFormatJSON:
+		return f, nil
+	case "rendered":
+		return ManifestFormatRendered, nil
+	}
+	return "", errors.Errorf("invalid manifest format %q, must be one of rendered, yaml or json", s)
+}
+
+// FormatManifest converts the YAML documents of a manifest to the format.
+// Empty documents are dropped by the conversion.
+func FormatManifest(content string, format ManifestFormat) (string, error) {
+	if format == ManifestFormatRendered {
+		return content, nil
+	}
+
+	var docs []string
+	for _, doc := range SplitDocuments(content) {
+		data, err := yaml.YAMLToJSON([]byte(doc))
+		if err != nil {
+			return content, errors.Wrap(err, "unable to parse manifest")
+		}
+		if string(data) == "null" {
+			continue
+		}
+
+		switch format {
+		case ManifestFormatJSON:
+			docs = append(docs, string(data))
+		case ManifestFormatYAML:
+			data, err = yaml.JSONToYAML(data)
+			if err != nil {
+				return content, err
+			}
+			docs = append(docs, strings.TrimSuffix(string(data), "\n"))
+		default:
+			return content, fmt.Errorf("unknown manifest format %q", format)
+		}
+	}
+	return strings.Join(docs, "\n---\n"), nil
+}
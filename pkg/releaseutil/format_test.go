@@ -0,0 +1,89 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releaseutil // import "helm.sh/helm/v4/pkg/releaseutil"
+
+import (
+	"testing"
+)
+
+const unformattedManifest = `# a comment
+kind: ConfigMap
+apiVersion: v1
+metadata:
+  name: config
+data:
+  b: "1"
+  a: two
+---
+---
+kind: Secret
+apiVersion: v1
+metadata: {name: secret}
+`
+
+func TestFormatManifest(t *testing.T) {
+	for _, tt := range []struct {
+		format ManifestFormat
+		expect string
+	}{
+		{ManifestFormatRendered, unformattedManifest},
+		{ManifestFormatJSON, `{"apiVersion":"v1","data":{"a":"two","b":"1"},"kind":"ConfigMap","metadata":{"name":"config"}}
+---
+{"apiVersion":"v1","kind":"Secret","metadata":{"name":"secret"}}`},
+		{ManifestFormatYAML, `apiVersion: v1
+data:
+  a: two
+  b: "1"
+kind: ConfigMap
+metadata:
+  name: config
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: secret`},
+	} {
+		got, err := FormatManifest(unformattedManifest, tt.format)
+		if err != nil {
+			t.Fatalf("%q: %s", tt.format, err)
+		}
+		if got != tt.expect {
+			t.Errorf("%q: expected\n%s\ngot\n%s", tt.format, tt.expect, got)
+		}
+	}
+
+	if _, err := FormatManifest("a: [", ManifestFormatJSON); err == nil {
+		t.Error("expected an error for invalid YAML")
+	}
+}
+
+func TestParseManifestFormat(t *testing.T) {
+	for in, expect := range map[string]ManifestFormat{
+		"":         ManifestFormatRendered,
+		"rendered": ManifestFormatRendered,
+		"yaml":     ManifestFormatYAML,
+		"json":     ManifestFormatJSON,
+	} {
+		got, err := ParseManifestFormat(in)
+		if err != nil || got != expect {
+			t.Errorf("%q: expected %q, got %q (%v)", in, expect, got, err)
+		}
+	}
+	if _, err := ParseManifestFormat("xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
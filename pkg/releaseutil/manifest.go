@@ -18,9 +18,12 @@ package releaseutil
 
 import (
 	"fmt"
-	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
 )
 
 // SimpleHead defines what the structure of the head of a manifest file
@@ -29,32 +32,120 @@ type SimpleHead struct {
 	Kind     string `json:"kind,omitempty"`
 	Metadata *struct {
 		Name        string            `json:"name"`
+		Namespace   string            `json:"namespace,omitempty"`
 		Annotations map[string]string `json:"annotations"`
 	} `json:"metadata,omitempty"`
 }
 
-var sep = regexp.MustCompile("(?:^|\\s*\n)---\\s*")
+// GroupVersionKind returns the group, version and kind of the resource.
+func (h *SimpleHead) GroupVersionKind() schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(h.Version, h.Kind)
+}
+
+// ObjectName returns the name of the resource.
+func (h *SimpleHead) ObjectName() string {
+	if h.Metadata == nil {
+		return ""
+	}
+	return h.Metadata.Name
+}
+
+// ObjectNamespace returns the namespace of the resource, which is empty when
+// the manifest does not set it.
+func (h *SimpleHead) ObjectNamespace() string {
+	if h.Metadata == nil {
+		return ""
+	}
+	return h.Metadata.Namespace
+}
+
+// SplitDocuments splits a stream of YAML documents at the document markers,
+// the "---" and "..." lines. Only markers at the start of a line count, so
+// that "---" inside block scalars or longer lines like "----" do not split a
+// document. Documents are trimmed and empty documents are dropped.
+func SplitDocuments(stream string) []string {
+	var docs []string
+	add := func(doc string) {
+		if doc = strings.TrimSpace(doc); doc != "" {
+			docs = append(docs, doc)
+		}
+	}
+
+	start := 0
+	for lineStart := 0; lineStart < len(stream); {
+		lineEnd := strings.IndexByte(stream[lineStart:], '\n')
+		next := len(stream)
+		if lineEnd < 0 {
+			lineEnd = len(stream)
+		} else {
+			lineEnd += lineStart
+			next = lineEnd + 1
+		}
+		if isDocumentMarker(stream[lineStart:lineEnd]) {
+			add(stream[start:lineStart])
+			// Content after the marker, e.g. "--- |", belongs to the next document.
+			start = lineStart + 3
+		}
+		lineStart = next
+	}
+	add(stream[start:])
+	return docs
+}
+
+func isDocumentMarker(line string) bool {
+	if !strings.HasPrefix(line, "---") && !strings.HasPrefix(line, "...") {
+		return false
+	}
+	if len(line) == 3 {
+		return true
+	}
+	switch line[3] {
+	case ' ', '\t', '\r':
+		return true
+	}
+	return false
+}
+
+// ParseManifests splits a stream of YAML documents into manifests and parses
+// the head of every document. The name of a manifest is the template path of
+// its "# Source:" comment, if it has one.
+func ParseManifests(stream string) ([]Manifest, error) {
+	docs := SplitDocuments(stream)
+	manifests := make([]Manifest, 0, len(docs))
+	for i, doc := range docs {
+		var head SimpleHead
+		if err := yaml.Unmarshal([]byte(doc), &head); err != nil {
+			return manifests, errors.Wrapf(err, "YAML parse error on document %d", i)
+		}
+		manifests = append(manifests, Manifest{
+			Name:    sourceName(doc),
+			Content: doc,
+			Head:    &head,
+		})
+	}
+	return manifests, nil
+}
+
+// sourceName returns the template path of the "# Source:" comment that
+// starts a document.
+func sourceName(doc string) string {
+	const prefix = "# Source: "
+	if !strings.HasPrefix(doc, prefix) {
+		return ""
+	}
+	line, _, _ := strings.Cut(doc[len(prefix):], "\n")
+	return strings.TrimSpace(line)
+}
 
 // SplitManifests takes a string of manifest and returns a map contains individual manifests
 func SplitManifests(bigFile string) map[string]string {
-	// Basically, we're quickly splitting a stream of YAML documents into an
-	// array of YAML docs. The file name is just a place holder, but should be
-	// integer-sortable so that manifests get output in the same order as the
-	// input (see `BySplitManifestsOrder`).
+	// The file name is just a place holder, but should be integer-sortable so
+	// that manifests get output in the same order as the input (see
+	// `BySplitManifestsOrder`).
 	tpl := "manifest-%d"
 	res := map[string]string{}
-	// Making sure that any extra whitespace in YAML stream doesn't interfere in splitting documents correctly.
-	bigFileTmp := strings.TrimSpace(bigFile)
-	docs := sep.Split(bigFileTmp, -1)
-	var count int
-	for _, d := range docs {
-		if d == "" {
-			continue
-		}
-
-		d = strings.TrimSpace(d)
+	for count, d := range SplitDocuments(bigFile) {
 		res[fmt.Sprintf(tpl, count)] = d
-		count = count + 1
 	}
 	return res
 }
@@ -20,12 +20,12 @@ import (
 	"log"
 	"path"
 	"sort"
-	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/yaml"
 
+	"helm.sh/helm/v4/pkg/annotations"
 	"helm.sh/helm/v4/pkg/chartutil"
 	"helm.sh/helm/v4/pkg/release"
 )
@@ -39,7 +39,7 @@ type Manifest struct {
 
 // manifestFile represents a file that contains a manifest.
 type manifestFile struct {
-	entries map[string]string
+	entries []string
 	path    string
 }
 
@@ -49,22 +49,6 @@ type result struct {
 	generic []Manifest
 }
 
-// TODO: Refactor this out. It's here because naming conventions were not followed through.
-// So fix the Test hook names and then remove this.
-var events = map[string]release.HookEvent{
-	release.HookPreInstall.String():   release.HookPreInstall,
-	release.HookPostInstall.String():  release.HookPostInstall,
-	release.HookPreDelete.String():    release.HookPreDelete,
-	release.HookPostDelete.String():   release.HookPostDelete,
-	release.HookPreUpgrade.String():   release.HookPreUpgrade,
-	release.HookPostUpgrade.String():  release.HookPostUpgrade,
-	release.HookPreRollback.String():  release.HookPreRollback,
-	release.HookPostRollback.String(): release.HookPostRollback,
-	release.HookTest.String():         release.HookTest,
-	// Support test-success for backward compatibility with Helm 2 tests
-	"test-success": release.HookTest,
-}
-
 // SortManifests takes a map of filename/YAML contents, splits the file
 // by manifest entries, and sorts the entries into hook types.
 //
@@ -97,7 +81,7 @@ func SortManifests(files map[string]string, _ chartutil.VersionSet, ordering Kin
 		}
 
 		manifestFile := &manifestFile{
-			entries: SplitManifests(content),
+			entries: SplitDocuments(content),
 			path:    filePath,
 		}
 
@@ -129,16 +113,8 @@ func SortManifests(files map[string]string, _ chartutil.VersionSet, ordering Kin
 //			annotations:
 //				helm.sh/hook-delete-policy: hook-succeeded
 func (file *manifestFile) sort(result *result) error {
-	// Go through manifests in order found in file (function `SplitManifests` creates integer-sortable keys)
-	var sortedEntryKeys []string
-	for entryKey := range file.entries {
-		sortedEntryKeys = append(sortedEntryKeys, entryKey)
-	}
-	sort.Sort(BySplitManifestsOrder(sortedEntryKeys))
-
-	for _, entryKey := range sortedEntryKeys {
-		m := file.entries[entryKey]
-
+	// Go through manifests in order found in file
+	for _, m := range file.entries {
 		var entry SimpleHead
 		if err := yaml.Unmarshal([]byte(m), &entry); err != nil {
 			return errors.Wrapf(err, "YAML parse error on %s", file.path)
@@ -153,8 +129,15 @@ func (file *manifestFile) sort(result *result) error {
 			continue
 		}
 
-		hookTypes, ok := entry.Metadata.Annotations[release.HookAnnotation]
-		if !ok {
+		hook, err := annotations.ParseHook(entry.Metadata.Annotations)
+		var unknown *annotations.UnknownHookError
+		if errors.As(err, &unknown) {
+			log.Printf("info: skipping unknown hook: %q", entry.Metadata.Annotations[release.HookAnnotation])
+			continue
+		} else if err != nil {
+			return errors.Wrapf(err, "%s %q in %s", entry.Kind, entry.Metadata.Name, file.path)
+		}
+		if hook == nil {
 			result.generic = append(result.generic, Manifest{
 				Name:    file.path,
 				Content: m,
@@ -163,38 +146,15 @@ func (file *manifestFile) sort(result *result) error {
 			continue
 		}
 
-		hw := calculateHookWeight(entry)
-
-		h := &release.Hook{
+		result.hooks = append(result.hooks, &release.Hook{
 			Name:           entry.Metadata.Name,
 			Kind:           entry.Kind,
 			Path:           file.path,
 			Manifest:       m,
-			Events:         []release.HookEvent{},
-			Weight:         hw,
-			DeletePolicies: []release.HookDeletePolicy{},
-		}
-
-		isUnknownHook := false
-		for _, hookType := range strings.Split(hookTypes, ",") {
-			hookType = strings.ToLower(strings.TrimSpace(hookType))
-			e, ok := events[hookType]
-			if !ok {
-				isUnknownHook = true
-				break
-			}
-			h.Events = append(h.Events, e)
-		}
-
-		if isUnknownHook {
-			log.Printf("info: skipping unknown hook: %q", hookTypes)
-			continue
-		}
-
-		result.hooks = append(result.hooks, h)
-
-		operateAnnotationValues(entry, release.HookDeleteAnnotation, func(value string) {
-			h.DeletePolicies = append(h.DeletePolicies, release.HookDeletePolicy(value))
+			Events:         hook.Events,
+			Weight:         hook.Weight,
+			DeletePolicies: hook.DeletePolicies,
+			Timeout:        hook.Timeout,
 		})
 	}
 
@@ -207,25 +167,3 @@ func hasAnyAnnotation(entry SimpleHead) bool {
 		entry.Metadata.Annotations != nil &&
 		len(entry.Metadata.Annotations) != 0
 }
-
-// calculateHookWeight finds the weight in the hook weight annotation.
-//
-// If no weight is found, the assigned weight is 0
-func calculateHookWeight(entry SimpleHead) int {
-	hws := entry.Metadata.Annotations[release.HookWeightAnnotation]
-	hw, err := strconv.Atoi(hws)
-	if err != nil {
-		hw = 0
-	}
-	return hw
-}
-
-// operateAnnotationValues finds the given annotation and runs the operate function with the value of that annotation
-func operateAnnotationValues(entry SimpleHead, annotation string, operate func(p string)) {
-	if dps, ok := entry.Metadata.Annotations[annotation]; ok {
-		for _, dp := range strings.Split(dps, ",") {
-			dp = strings.ToLower(strings.TrimSpace(dp))
-			operate(dp)
-		}
-	}
-}
@@ -18,6 +18,7 @@ package releaseutil
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"sigs.k8s.io/yaml"
@@ -225,3 +226,19 @@ metadata:
 		}
 	}
 }
+
+func TestSortManifestsInvalidHookTimeout(t *testing.T) {
+	manifests := map[string]string{
+		"templates/job.yaml": `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  annotations:
+    "helm.sh/hook": pre-install
+    "helm.sh/hook-timeout": soon`,
+	}
+	_, _, err := SortManifests(manifests, nil, InstallOrder)
+	if err == nil || !strings.Contains(err.Error(), `Job "migrate" in templates/job.yaml: invalid helm.sh/hook-timeout annotation "soon"`) {
+		t.Errorf("expected an invalid hook timeout error, got %v", err)
+	}
+}
@@ -17,8 +17,12 @@ limitations under the License.
 package releaseutil // import "helm.sh/helm/v4/pkg/releaseutil"
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 const mockManifestFile = `
@@ -59,3 +63,144 @@ func TestSplitManifest(t *testing.T) {
 		t.Errorf("Expected %v, got %v", expected, manifests)
 	}
 }
+
+func TestSplitDocuments(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		stream string
+		expect []string
+	}{
+		{
+			name:   "separators and empty documents",
+			stream: "---\na: 1\n---\n\n---   \nb: 2\n...\n---\n",
+			expect: []string{"a: 1", "b: 2"},
+		},
+		{
+			name:   "separator inside a block scalar",
+			stream: "data:\n  script: |\n    echo one\n    ---\n    echo two\n---\nb: 2",
+			expect: []string{"data:\n  script: |\n    echo one\n    ---\n    echo two", "b: 2"},
+		},
+		{
+			name:   "lines starting with dashes",
+			stream: "a: |\n----\n---- not a separator\n---b",
+			expect: []string{"a: |\n----\n---- not a separator\n---b"},
+		},
+		{
+			name:   "content after the separator",
+			stream: "a: 1\n--- # second\nb: 2\r\n---\r\nc: 3",
+			expect: []string{"a: 1", "# second\nb: 2", "c: 3"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitDocuments(tt.stream); !reflect.DeepEqual(got, tt.expect) {
+				t.Errorf("expected %q, got %q", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestParseManifests(t *testing.T) {
+	manifests, err := ParseManifests(`---
+# Source: chart/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: production
+---
+# Source: chart/templates/empty.yaml
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifests) != 3 {
+		t.Fatalf("expected 3 manifests, got %d", len(manifests))
+	}
+
+	m := manifests[0]
+	if m.Name != "chart/templates/deployment.yaml" {
+		t.Errorf("unexpected name %q", m.Name)
+	}
+	if gvk := m.Head.GroupVersionKind(); gvk != (schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}) {
+		t.Errorf("unexpected GroupVersionKind %v", gvk)
+	}
+	if m.Head.ObjectName() != "web" || m.Head.ObjectNamespace() != "production" {
+		t.Errorf("unexpected name %q and namespace %q", m.Head.ObjectName(), m.Head.ObjectNamespace())
+	}
+
+	if m := manifests[1]; m.Name != "chart/templates/empty.yaml" || m.Head.ObjectName() != "" {
+		t.Errorf("expected an empty manifest, got %+v", m)
+	}
+	if m := manifests[2]; m.Name != "" || m.Head.ObjectNamespace() != "" || m.Head.ObjectName() != "config" {
+		t.Errorf("unexpected manifest %+v", m)
+	}
+
+	if _, err := ParseManifests("a: [\n---\nb: 1"); err == nil || !strings.Contains(err.Error(), "document 0") {
+		t.Errorf("expected a parse error for document 0, got %v", err)
+	}
+}
+
+// benchmarkStream returns a manifest stream of n Deployments.
+func benchmarkStream(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, `---
+# Source: chart/templates/deployment-%d.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web-%d
+  annotations:
+    checksum/config: abcdef
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx
+        args:
+        - |
+          ---
+          not a separator
+`, i, i)
+	}
+	return b.String()
+}
+
+func BenchmarkSplitDocuments(b *testing.B) {
+	stream := benchmarkStream(1000)
+	b.SetBytes(int64(len(stream)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		SplitDocuments(stream)
+	}
+}
+
+func BenchmarkParseManifests(b *testing.B) {
+	stream := benchmarkStream(1000)
+	b.SetBytes(int64(len(stream)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseManifests(stream); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSortManifests(b *testing.B) {
+	files := map[string]string{}
+	for i := 0; i < 100; i++ {
+		files[fmt.Sprintf("chart/templates/file-%d.yaml", i)] = benchmarkStream(10)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := SortManifests(files, nil, InstallOrder); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
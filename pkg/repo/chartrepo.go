@@ -48,6 +48,23 @@ type Entry struct {
 	CAFile                string `json:"caFile"`
 	InsecureSkipTLSverify bool   `json:"insecure_skip_tls_verify"`
 	PassCredentialsAll    bool   `json:"pass_credentials_all"`
+	// Proxy is the URL of the proxy used to reach the repository, e.g.
+	// "socks5://127.0.0.1:1080". When empty, the proxy environment variables
+	// are used.
+	Proxy string `json:"proxy,omitempty"`
+	// MinTLSVersion is the minimum TLS version used with the repository.
+	MinTLSVersion string `json:"minTLSVersion,omitempty"`
+}
+
+// TransportOptions returns the getter options that configure the proxy and
+// TLS settings of the repository.
+func (e *Entry) TransportOptions() []getter.Option {
+	return []getter.Option{
+		getter.WithInsecureSkipVerifyTLS(e.InsecureSkipTLSverify),
+		getter.WithTLSClientConfig(e.CertFile, e.KeyFile, e.CAFile),
+		getter.WithProxy(e.Proxy),
+		getter.WithMinTLSVersion(e.MinTLSVersion),
+	}
 }
 
 // ChartRepository represents a chart repository
@@ -57,6 +74,9 @@ type ChartRepository struct {
 	IndexFile  *IndexFile
 	Client     getter.Getter
 	CachePath  string
+	// Options are additional getter options used to download the index file,
+	// e.g. the headers of a private chart server.
+	Options []getter.Option
 }
 
 // NewChartRepository constructs ChartRepository
@@ -120,13 +140,12 @@ func (r *ChartRepository) DownloadIndexFile() (string, error) {
 		return "", err
 	}
 
-	resp, err := r.Client.Get(indexURL,
-		getter.WithURL(r.Config.URL),
-		getter.WithInsecureSkipVerifyTLS(r.Config.InsecureSkipTLSverify),
-		getter.WithTLSClientConfig(r.Config.CertFile, r.Config.KeyFile, r.Config.CAFile),
+	opts := append([]getter.Option{getter.WithURL(r.Config.URL)}, r.Config.TransportOptions()...)
+	opts = append(opts,
 		getter.WithBasicAuth(r.Config.Username, r.Config.Password),
 		getter.WithPassCredentialsAll(r.Config.PassCredentialsAll),
 	)
+	resp, err := r.Client.Get(indexURL, append(opts, r.Options...)...)
 	if err != nil {
 		return "", err
 	}
@@ -223,6 +242,14 @@ func FindChartInAuthAndTLSRepoURL(repoURL, username, password, chartName, chartV
 // be passed on to other domains.
 // TODO Helm 4, FindChartInAuthAndTLSAndPassRepoURL should be integrated into FindChartInAuthRepoURL.
 func FindChartInAuthAndTLSAndPassRepoURL(repoURL, username, password, chartName, chartVersion, certFile, keyFile, caFile string, insecureSkipTLSverify, passCredentialsAll bool, getters getter.Providers) (string, error) {
+	return FindChartInRepoURLWithOptions(repoURL, username, password, chartName, chartVersion, certFile, keyFile, caFile, insecureSkipTLSverify, passCredentialsAll, getters)
+}
+
+// FindChartInRepoURLWithOptions finds chart in chart repository pointed by
+// repoURL without adding repo to repositories, like
+// FindChartInAuthAndTLSAndPassRepoURL, but it also receives getter options
+// used to download the index file, e.g. headers or a bearer token.
+func FindChartInRepoURLWithOptions(repoURL, username, password, chartName, chartVersion, certFile, keyFile, caFile string, insecureSkipTLSverify, passCredentialsAll bool, getters getter.Providers, options ...getter.Option) (string, error) {
 
 	// Download and write the index file to a temporary location
 	buf := make([]byte, 20)
@@ -244,6 +271,7 @@ func FindChartInAuthAndTLSAndPassRepoURL(repoURL, username, password, chartName,
 	if err != nil {
 		return "", err
 	}
+	r.Options = options
 	idx, err := r.DownloadIndexFile()
 	if err != nil {
 		return "", errors.Wrapf(err, "looks like %q is not a valid chart repository or cannot be reached", repoURL)
@@ -344,6 +344,37 @@ func TestFindChartInRepoURL(t *testing.T) {
 	}
 }
 
+func TestFindChartInRepoURLWithOptions(t *testing.T) {
+	fileBytes, err := os.ReadFile("testdata/local-index.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, err := startLocalServerForTests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("X-Tenant") != "acme" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write(fileBytes)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	if _, err := FindChartInRepoURLWithOptions(srv.URL, "", "", "nginx", "", "", "", "", false, false, getter.All(&cli.EnvSettings{})); err == nil {
+		t.Error("Expected an error without the headers")
+	}
+
+	chartURL, err := FindChartInRepoURLWithOptions(srv.URL, "", "", "nginx", "", "", "", "", false, false, getter.All(&cli.EnvSettings{}),
+		getter.WithHeaders(http.Header{"X-Tenant": {"acme"}}), getter.WithBearerToken("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chartURL != "https://charts.helm.sh/stable/nginx-0.2.0.tgz" {
+		t.Errorf("%s is not the valid URL", chartURL)
+	}
+}
+
 func TestErrorFindChartInRepoURL(t *testing.T) {
 
 	g := getter.All(&cli.EnvSettings{
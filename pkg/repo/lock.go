@@ -0,0 +1,55 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo // import "helm.sh/helm/v4/pkg/repo"
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/pkg/errors"
+)
+
+// LockFile acquires the file lock of the repositories file at path, which
+// synchronizes the processes modifying the file, and returns the function
+// releasing it. It waits for the lock until the context is done.
+//
+// The lock file is next to the repositories file, with the extension .lock
+// instead of the extension of the repositories file.
+func LockFile(ctx context.Context, path string) (func() error, error) {
+	// Ensure the file directory exists as it is required for file locking
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil && !os.IsExist(err) {
+		return nil, err
+	}
+
+	lockPath := path + ".lock"
+	if ext := filepath.Ext(path); len(ext) > 0 && len(ext) < len(path) {
+		lockPath = strings.TrimSuffix(path, ext) + ".lock"
+	}
+	fileLock := flock.New(lockPath)
+	locked, err := fileLock.TryLockContext(ctx, time.Second)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to lock the repositories file %s", path)
+	}
+	if !locked {
+		return nil, errors.Errorf("unable to lock the repositories file %s", path)
+	}
+	return fileLock.Unlock, nil
+}
@@ -17,12 +17,15 @@ limitations under the License.
 package repo // import "helm.sh/helm/v4/pkg/repo"
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/internal/fileutil"
 )
 
 // File represents the repositories.yaml file
@@ -30,6 +33,10 @@ type File struct {
 	APIVersion   string    `json:"apiVersion"`
 	Generated    time.Time `json:"generated"`
 	Repositories []*Entry  `json:"repositories"`
+
+	// sources maps the names of the repositories merged from fragment files
+	// to their fragment file, see LoadFiles.
+	sources map[string]string
 }
 
 // NewFile generates an empty repositories file.
@@ -55,6 +62,72 @@ func LoadFile(path string) (*File, error) {
 	return r, err
 }
 
+// LoadFiles loads the repositories file at path and merges the fragment
+// files of dir into it, which allows shipping managed repositories separately
+// from the repositories of the user.
+//
+// The fragment files are the files of dir with the extension .yaml, which have
+// the format of a repositories file. They are merged in lexical order after
+// the repositories file, and a repository replaces the repository with the same
+// name of an earlier file. A missing repositories file or directory is treated
+// as empty.
+//
+// The repositories of the fragment files are read-only, see Source, and the
+// merged file cannot be written.
+func LoadFiles(path, dir string) (*File, error) {
+	r, err := LoadFile(path)
+	if err != nil && !os.IsNotExist(errors.Cause(err)) {
+		return r, err
+	}
+	fragments, err := LoadFragments(dir)
+	if err != nil {
+		return r, err
+	}
+	for _, e := range fragments.Repositories {
+		r.update(e)
+	}
+	r.sources = fragments.sources
+	return r, nil
+}
+
+// LoadFragments loads and merges the fragment files of dir, see LoadFiles. A
+// missing directory is treated as empty.
+func LoadFragments(dir string) (*File, error) {
+	r := new(File)
+	if dir == "" {
+		return r, nil
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return r, err
+	}
+	// Glob returns the files in lexical order
+	for _, file := range files {
+		fragment, err := LoadFile(file)
+		if err != nil {
+			return r, err
+		}
+		for _, e := range fragment.Repositories {
+			if e == nil {
+				continue
+			}
+			r.update(e)
+			if r.sources == nil {
+				r.sources = map[string]string{}
+			}
+			r.sources[e.Name] = file
+		}
+	}
+	return r, nil
+}
+
+// Source returns the fragment file that defines the named repository, or an
+// empty string if the repository is not defined by a fragment file. The
+// repositories of fragment files are read-only.
+func (r *File) Source(name string) string {
+	return r.sources[name]
+}
+
 // Add adds one or more repo entries to a repo file.
 func (r *File) Add(re ...*Entry) {
 	r.Repositories = append(r.Repositories, re...)
@@ -112,8 +185,14 @@ func (r *File) Remove(name string) bool {
 	return found
 }
 
-// WriteFile writes a repositories file to the given path.
+// WriteFile atomically writes a repositories file to the given path, so that
+// concurrent readers never see a partially written file. Concurrent writers
+// must hold the lock of the file, see LockFile. Files merged with fragment
+// files by LoadFiles cannot be written.
 func (r *File) WriteFile(path string, perm os.FileMode) error {
+	if len(r.sources) > 0 {
+		return errors.New("repositories merged from fragment files cannot be written")
+	}
 	data, err := yaml.Marshal(r)
 	if err != nil {
 		return err
@@ -121,5 +200,5 @@ func (r *File) WriteFile(path string, perm os.FileMode) error {
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, perm)
+	return fileutil.AtomicWriteFile(path, bytes.NewReader(data), perm)
 }
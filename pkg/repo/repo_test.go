@@ -18,6 +18,7 @@ package repo
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -255,3 +256,67 @@ func TestRemoveRepositoryInvalidEntries(t *testing.T) {
 		t.Errorf("repository %s not deleted", removeRepository)
 	}
 }
+
+func TestLoadFiles(t *testing.T) {
+	dir := t.TempDir()
+	fragments := filepath.Join(dir, "repositories.d")
+	writeRepositories := func(path string, entries ...*Entry) {
+		t.Helper()
+		f := NewFile()
+		f.Add(entries...)
+		if err := f.WriteFile(path, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeRepositories(filepath.Join(dir, "repositories.yaml"),
+		&Entry{Name: "stable", URL: "https://example.com/user/stable"},
+		&Entry{Name: "mine", URL: "https://example.com/user/mine"},
+	)
+	writeRepositories(filepath.Join(fragments, "20-team.yaml"),
+		&Entry{Name: "team", URL: "https://example.com/team"},
+		&Entry{Name: "stable", URL: "https://example.com/team/stable"},
+	)
+	writeRepositories(filepath.Join(fragments, "10-org.yaml"),
+		&Entry{Name: "stable", URL: "https://example.com/org/stable"},
+		&Entry{Name: "org", URL: "https://example.com/org"},
+	)
+	if err := os.WriteFile(filepath.Join(fragments, "README.md"), []byte("not a fragment"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := LoadFiles(filepath.Join(dir, "repositories.yaml"), fragments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"stable": "https://example.com/team/stable",
+		"mine":   "https://example.com/user/mine",
+		"org":    "https://example.com/org",
+		"team":   "https://example.com/team",
+	}
+	if len(f.Repositories) != len(want) {
+		t.Errorf("expected %d repositories, got %d", len(want), len(f.Repositories))
+	}
+	for name, url := range want {
+		if e := f.Get(name); e == nil || e.URL != url {
+			t.Errorf("expected repository %s with URL %s, got %v", name, url, e)
+		}
+	}
+	if src := f.Source("stable"); src != filepath.Join(fragments, "20-team.yaml") {
+		t.Errorf("expected stable to be defined by 20-team.yaml, got %q", src)
+	}
+	if src := f.Source("mine"); src != "" {
+		t.Errorf("expected mine not to be defined by a fragment file, got %q", src)
+	}
+	if err := f.WriteFile(filepath.Join(dir, "merged.yaml"), 0600); err == nil {
+		t.Error("expected writing a merged file to fail")
+	}
+
+	f, err = LoadFiles(filepath.Join(dir, "missing.yaml"), filepath.Join(dir, "missing.d"))
+	if err != nil {
+		t.Fatalf("expected missing files to be treated as empty: %s", err)
+	}
+	if len(f.Repositories) != 0 {
+		t.Errorf("expected no repositories, got %d", len(f.Repositories))
+	}
+}
@@ -35,6 +35,9 @@ import (
 )
 
 var _ Driver = (*ConfigMaps)(nil)
+var _ Scanner = (*ConfigMaps)(nil)
+var _ MetadataLister = (*ConfigMaps)(nil)
+var _ DriverV2 = (*ConfigMaps)(nil)
 
 // ConfigMapsDriverName is the string name of the driver.
 const ConfigMapsDriverName = "ConfigMap"
@@ -63,8 +66,13 @@ func (cfgmaps *ConfigMaps) Name() string {
 // Get fetches the release named by key. The corresponding release is returned
 // or error if not found.
 func (cfgmaps *ConfigMaps) Get(key string) (*rspb.Release, error) {
+	return cfgmaps.GetContext(context.Background(), key)
+}
+
+// GetContext is like Get, but passes ctx to the Kubernetes API.
+func (cfgmaps *ConfigMaps) GetContext(ctx context.Context, key string) (*rspb.Release, error) {
 	// fetch the configmap holding the release named by key
-	obj, err := cfgmaps.impl.Get(context.Background(), key, metav1.GetOptions{})
+	obj, err := cfgmaps.impl.Get(ctx, key, metav1.GetOptions{})
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			return nil, ErrReleaseNotFound
@@ -88,6 +96,52 @@ func (cfgmaps *ConfigMaps) Get(key string) (*rspb.Release, error) {
 // that filter(release) == true. An error is returned if the
 // configmap fails to retrieve the releases.
 func (cfgmaps *ConfigMaps) List(filter func(*rspb.Release) bool) ([]*rspb.Release, error) {
+	return cfgmaps.list(filter, decodeRelease)
+}
+
+// ListMetadata is like List, but only decodes the metadata of the releases.
+func (cfgmaps *ConfigMaps) ListMetadata(filter func(*rspb.Release) bool) ([]*rspb.Release, error) {
+	return cfgmaps.list(filter, decodeReleaseMetadata)
+}
+
+// ListContext fetches a page of the releases selected by opts. The filter is
+// translated to a label selector, and Limit and Continue are passed to the
+// Kubernetes API. Releases are then filtered by NamePrefix, so a page may hold
+// fewer than Limit releases.
+func (cfgmaps *ConfigMaps) ListContext(ctx context.Context, opts ListOptions) (*ListResult, error) {
+	lsel, err := opts.labelSelector()
+	if err != nil {
+		return nil, err
+	}
+	list, err := cfgmaps.impl.List(ctx, metav1.ListOptions{
+		LabelSelector: lsel.String(),
+		Limit:         int64(opts.Limit),
+		Continue:      opts.Continue,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list: failed to list")
+	}
+
+	decode := decodeRelease
+	if opts.MetadataOnly {
+		decode = decodeReleaseMetadata
+	}
+	res := &ListResult{Continue: list.Continue}
+	for _, item := range list.Items {
+		rls, err := decode(item.Data["release"])
+		if err != nil {
+			cfgmaps.Log("list: failed to decode release: %v: %s", item, err)
+			continue
+		}
+		rls.Labels = item.ObjectMeta.Labels
+		if opts.Matches(rls) {
+			res.Releases = append(res.Releases, rls)
+		}
+	}
+	return res, nil
+}
+
+func (cfgmaps *ConfigMaps) list(filter func(*rspb.Release) bool, decode func(string) (*rspb.Release, error)) ([]*rspb.Release, error) {
 	lsel := kblabels.Set{"owner": "helm"}.AsSelector()
 	opts := metav1.ListOptions{LabelSelector: lsel.String()}
 
@@ -102,7 +156,7 @@ func (cfgmaps *ConfigMaps) List(filter func(*rspb.Release) bool) ([]*rspb.Releas
 	// iterate over the configmaps object list
 	// and decode each release
 	for _, item := range list.Items {
-		rls, err := decodeRelease(item.Data["release"])
+		rls, err := decode(item.Data["release"])
 		if err != nil {
 			cfgmaps.Log("list: failed to decode release: %v: %s", item, err)
 			continue
@@ -156,6 +210,11 @@ func (cfgmaps *ConfigMaps) Query(labels map[string]string) ([]*rspb.Release, err
 // Create creates a new ConfigMap holding the release. If the
 // ConfigMap already exists, ErrReleaseExists is returned.
 func (cfgmaps *ConfigMaps) Create(key string, rls *rspb.Release) error {
+	return cfgmaps.CreateContext(context.Background(), key, rls)
+}
+
+// CreateContext is like Create, but passes ctx to the Kubernetes API.
+func (cfgmaps *ConfigMaps) CreateContext(ctx context.Context, key string, rls *rspb.Release) error {
 	// set labels for configmaps object meta data
 	var lbs labels
 
@@ -170,7 +229,7 @@ func (cfgmaps *ConfigMaps) Create(key string, rls *rspb.Release) error {
 		return err
 	}
 	// push the configmap object out into the kubiverse
-	if _, err := cfgmaps.impl.Create(context.Background(), obj, metav1.CreateOptions{}); err != nil {
+	if _, err := cfgmaps.impl.Create(ctx, obj, metav1.CreateOptions{}); err != nil {
 		if apierrors.IsAlreadyExists(err) {
 			return ErrReleaseExists
 		}
@@ -184,6 +243,11 @@ func (cfgmaps *ConfigMaps) Create(key string, rls *rspb.Release) error {
 // Update updates the ConfigMap holding the release. If not found
 // the ConfigMap is created to hold the release.
 func (cfgmaps *ConfigMaps) Update(key string, rls *rspb.Release) error {
+	return cfgmaps.UpdateContext(context.Background(), key, rls)
+}
+
+// UpdateContext is like Update, but passes ctx to the Kubernetes API.
+func (cfgmaps *ConfigMaps) UpdateContext(ctx context.Context, key string, rls *rspb.Release) error {
 	// set labels for configmaps object meta data
 	var lbs labels
 
@@ -198,7 +262,7 @@ func (cfgmaps *ConfigMaps) Update(key string, rls *rspb.Release) error {
 		return err
 	}
 	// push the configmap object out into the kubiverse
-	_, err = cfgmaps.impl.Update(context.Background(), obj, metav1.UpdateOptions{})
+	_, err = cfgmaps.impl.Update(ctx, obj, metav1.UpdateOptions{})
 	if err != nil {
 		cfgmaps.Log("update: failed to update: %s", err)
 		return err
@@ -207,18 +271,60 @@ func (cfgmaps *ConfigMaps) Update(key string, rls *rspb.Release) error {
 }
 
 // Delete deletes the ConfigMap holding the release named by key.
-func (cfgmaps *ConfigMaps) Delete(key string) (rls *rspb.Release, err error) {
+func (cfgmaps *ConfigMaps) Delete(key string) (*rspb.Release, error) {
+	return cfgmaps.DeleteContext(context.Background(), key)
+}
+
+// DeleteContext is like Delete, but passes ctx to the Kubernetes API.
+func (cfgmaps *ConfigMaps) DeleteContext(ctx context.Context, key string) (rls *rspb.Release, err error) {
 	// fetch the release to check existence
-	if rls, err = cfgmaps.Get(key); err != nil {
+	if rls, err = cfgmaps.GetContext(ctx, key); err != nil {
 		return nil, err
 	}
 	// delete the release
-	if err = cfgmaps.impl.Delete(context.Background(), key, metav1.DeleteOptions{}); err != nil {
+	if err = cfgmaps.impl.Delete(ctx, key, metav1.DeleteOptions{}); err != nil {
 		return rls, err
 	}
 	return rls, nil
 }
 
+// Scan fetches all configmaps owned by Helm and decodes the releases they hold.
+// ConfigMaps that fail to decode are returned with the decoding error.
+func (cfgmaps *ConfigMaps) Scan() ([]*Record, error) {
+	lsel := kblabels.Set{"owner": "helm"}.AsSelector()
+	opts := metav1.ListOptions{LabelSelector: lsel.String()}
+
+	list, err := cfgmaps.impl.List(context.Background(), opts)
+	if err != nil {
+		cfgmaps.Log("scan: failed to list: %s", err)
+		return nil, err
+	}
+
+	records := make([]*Record, 0, len(list.Items))
+	for _, item := range list.Items {
+		rec := &Record{Key: item.Name, Labels: item.ObjectMeta.Labels}
+		rls, err := decodeRelease(item.Data["release"])
+		if err != nil {
+			rec.Err = err
+		} else {
+			rls.Labels = item.ObjectMeta.Labels
+			rec.Release = rls
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// DeleteRecord deletes the ConfigMap named by key without decoding the release
+// it holds.
+func (cfgmaps *ConfigMaps) DeleteRecord(key string) error {
+	err := cfgmaps.impl.Delete(context.Background(), key, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return ErrReleaseNotFound
+	}
+	return err
+}
+
 // newConfigMapsObject constructs a kubernetes ConfigMap object
 // to store a release. Each configmap data entry is the base64
 // encoded gzipped string of a release.
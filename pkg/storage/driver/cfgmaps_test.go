@@ -249,3 +249,33 @@ func TestConfigMapDelete(t *testing.T) {
 		t.Errorf("Expected {%v}, got {%v}", ErrReleaseNotFound, err)
 	}
 }
+
+func TestConfigMapScan(t *testing.T) {
+	rel := releaseStub("smug-pigeon", 1, "default", rspb.StatusDeployed)
+	corrupted := releaseStub("smug-pigeon", 2, "default", rspb.StatusDeployed)
+
+	var mock MockConfigMapsInterface
+	mock.Init(t, rel, corrupted)
+	mock.objects[testKey("smug-pigeon", 2)].Data["release"] = "not a release"
+	cfgmaps := NewConfigMaps(&mock)
+
+	records, err := cfgmaps.Scan()
+	if err != nil {
+		t.Fatalf("Failed to scan: %s", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	for _, rec := range records {
+		if (rec.Key == testKey("smug-pigeon", 2)) != (rec.Err != nil) {
+			t.Errorf("Unexpected decoding result for %q: %v", rec.Key, rec.Err)
+		}
+	}
+
+	if err := cfgmaps.DeleteRecord(testKey("smug-pigeon", 2)); err != nil {
+		t.Fatalf("Failed to delete record: %s", err)
+	}
+	if _, ok := mock.objects[testKey("smug-pigeon", 2)]; ok {
+		t.Error("Expected the corrupted record to be deleted")
+	}
+}
@@ -103,3 +103,28 @@ type Driver interface {
 	Queryor
 	Name() string
 }
+
+// MetadataLister is the interface implemented by drivers that can list
+// releases without decoding their chart templates and files, values, manifests
+// and hooks, which is much faster for large releases. Only the name,
+// namespace, version, labels, info and chart metadata of the releases are set.
+type MetadataLister interface {
+	ListMetadata(filter func(*rspb.Release) bool) ([]*rspb.Release, error)
+}
+
+// Record is a release record as it is stored by a driver. Release is nil when
+// the record cannot be decoded, and Err tells why.
+type Record struct {
+	Key     string
+	Labels  map[string]string
+	Release *rspb.Release
+	Err     error
+}
+
+// Scanner is the interface implemented by drivers that can list all of their
+// release records, including the records that cannot be decoded, and delete
+// records regardless of their content.
+type Scanner interface {
+	Scan() ([]*Record, error)
+	DeleteRecord(key string) error
+}
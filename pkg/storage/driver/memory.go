@@ -25,6 +25,7 @@ import (
 )
 
 var _ Driver = (*Memory)(nil)
+var _ Scanner = (*Memory)(nil)
 
 const (
 	// MemoryDriverName is the string name of this driver.
@@ -222,6 +223,46 @@ func (mem *Memory) Delete(key string) (*rspb.Release, error) {
 	return nil, ErrReleaseNotFound
 }
 
+// Scan returns the records of all releases in the namespace, or in all
+// namespaces when no namespace is set.
+func (mem *Memory) Scan() ([]*Record, error) {
+	defer unlock(mem.rlock())
+
+	var records []*Record
+	for namespace, rels := range mem.cache {
+		if mem.namespace != "" && namespace != mem.namespace {
+			continue
+		}
+		for _, recs := range rels {
+			recs.Iter(func(_ int, rec *record) bool {
+				records = append(records, &Record{Key: rec.key, Labels: rec.lbs.toMap(), Release: rec.rls})
+				return true
+			})
+		}
+	}
+	return records, nil
+}
+
+// DeleteRecord deletes the record named by key in the namespace, or in any
+// namespace when no namespace is set.
+func (mem *Memory) DeleteRecord(key string) error {
+	defer unlock(mem.wlock())
+
+	for namespace, rels := range mem.cache {
+		if mem.namespace != "" && namespace != mem.namespace {
+			continue
+		}
+		for name, recs := range rels {
+			if r := recs.Remove(key); r != nil {
+				// recs.Remove changes the slice reference, so we have to re-assign it.
+				mem.cache[namespace][name] = recs
+				return nil
+			}
+		}
+	}
+	return ErrReleaseNotFound
+}
+
 // wlock locks mem for writing
 func (mem *Memory) wlock() func() {
 	mem.Lock()
@@ -19,6 +19,8 @@ package driver // import "helm.sh/helm/v4/pkg/storage/driver"
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"testing"
 
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
@@ -31,6 +33,7 @@ import (
 	kblabels "k8s.io/apimachinery/pkg/labels"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 
+	"helm.sh/helm/v4/pkg/registry"
 	rspb "helm.sh/helm/v4/pkg/release"
 )
 
@@ -206,7 +209,9 @@ func (mock *MockSecretsInterface) Get(_ context.Context, name string, _ metav1.G
 	return object, nil
 }
 
-// List returns all Secrets.
+// List returns the Secrets matching the label selector. Like the Kubernetes
+// API, it returns at most opts.Limit Secrets ordered by name, and the continue
+// token is the name of the last Secret returned.
 func (mock *MockSecretsInterface) List(_ context.Context, opts metav1.ListOptions) (*v1.SecretList, error) {
 	var list v1.SecretList
 
@@ -216,10 +221,15 @@ func (mock *MockSecretsInterface) List(_ context.Context, opts metav1.ListOption
 	}
 
 	for _, secret := range mock.objects {
-		if labelSelector.Matches(kblabels.Set(secret.ObjectMeta.Labels)) {
+		if secret.Name > opts.Continue && labelSelector.Matches(kblabels.Set(secret.ObjectMeta.Labels)) {
 			list.Items = append(list.Items, *secret)
 		}
 	}
+	sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].Name < list.Items[j].Name })
+	if opts.Limit > 0 && int64(len(list.Items)) > opts.Limit {
+		list.Items = list.Items[:opts.Limit]
+		list.Continue = list.Items[len(list.Items)-1].Name
+	}
 	return &list, nil
 }
 
@@ -267,3 +277,67 @@ func newTestFixtureSQL(t *testing.T, _ ...*rspb.Release) (*SQL, sqlmock.Sqlmock)
 		statementBuilder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
 	}, mock
 }
+
+func newTestFixtureOCI(t *testing.T, releases ...*rspb.Release) (*OCI, *MockOCIClient) {
+	t.Helper()
+	mock := &MockOCIClient{artifacts: map[string]mockOCIArtifact{}}
+	d, err := NewOCI(mock, "oci://registry.example.com/releases/test/", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rls := range releases {
+		if err := d.Create(testKey(rls.Name, rls.Version), rls); err != nil {
+			t.Fatalf("Failed to create release: %s", err)
+		}
+	}
+	return d, mock
+}
+
+// MockOCIClient mocks a registry client storing release artifacts.
+type MockOCIClient struct {
+	artifacts map[string]mockOCIArtifact
+}
+
+type mockOCIArtifact struct {
+	data   []byte
+	labels map[string]string
+}
+
+func (mock *MockOCIClient) PushRelease(data []byte, labels map[string]string, ref string) error {
+	mock.artifacts[ref] = mockOCIArtifact{data: data, labels: labels}
+	return nil
+}
+
+func (mock *MockOCIClient) PullRelease(ref string) ([]byte, map[string]string, error) {
+	a, ok := mock.artifacts[ref]
+	if !ok {
+		return nil, nil, registry.ErrNotFound
+	}
+	return a.data, a.labels, nil
+}
+
+func (mock *MockOCIClient) ReleaseLabels(ref string) (map[string]string, error) {
+	_, labels, err := mock.PullRelease(ref)
+	return labels, err
+}
+
+func (mock *MockOCIClient) DeleteRelease(ref string) error {
+	if _, ok := mock.artifacts[ref]; !ok {
+		return registry.ErrNotFound
+	}
+	delete(mock.artifacts, ref)
+	return nil
+}
+
+func (mock *MockOCIClient) ListTags(ref string) ([]string, error) {
+	var tags []string
+	for r := range mock.artifacts {
+		if repo, tag, _ := strings.Cut(r, ":"); repo == ref {
+			tags = append(tags, tag)
+		}
+	}
+	if tags == nil {
+		return nil, registry.ErrNotFound
+	}
+	return tags, nil
+}
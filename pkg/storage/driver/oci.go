@@ -0,0 +1,279 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver // import "helm.sh/helm/v4/pkg/storage/driver"
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v4/pkg/registry"
+	rspb "helm.sh/helm/v4/pkg/release"
+)
+
+var _ Driver = (*OCI)(nil)
+
+// OCIDriverName is the string name of this driver.
+const OCIDriverName = "OCI"
+
+// keyPrefix is the prefix of the keys of the release records.
+const keyPrefix = "sh.helm.release.v1."
+
+// OCIClient is the interface of the registry client used by the OCI driver.
+// It is implemented by registry.Client. Errors for references and repositories
+// that do not exist must wrap registry.ErrNotFound.
+type OCIClient interface {
+	PushRelease(data []byte, labels map[string]string, ref string) error
+	PullRelease(ref string) ([]byte, map[string]string, error)
+	ReleaseLabels(ref string) (map[string]string, error)
+	DeleteRelease(ref string) error
+	ListTags(ref string) ([]string, error)
+}
+
+// OCI is the storage driver that stores release records as artifacts in an
+// OCI registry, so that the release history survives the loss of the cluster
+// and can be shared by several clusters.
+//
+// The records of a namespace are stored in the repository named after the
+// namespace below the base repository of the driver, and tagged with their
+// key, e.g. registry.example.com/releases/prod/default:sh.helm.release.v1.web.v1.
+// Using a base repository per cluster keeps the releases of the clusters
+// apart. The labels of a record are stored in the manifest, so that queries
+// only download the records that match.
+type OCI struct {
+	client     OCIClient
+	repository string
+	namespace  string
+	Log        func(string, ...interface{})
+}
+
+// NewOCI initializes a new OCI driver that stores the records of the namespace
+// below the base repository, e.g. oci://registry.example.com/releases/prod.
+func NewOCI(client OCIClient, repository, namespace string) (*OCI, error) {
+	repository = strings.TrimSuffix(strings.TrimPrefix(repository, registry.OCIScheme+"://"), "/")
+	if repository == "" {
+		return nil, errors.New("the OCI driver requires a repository")
+	}
+	return &OCI{
+		client:     client,
+		repository: repository,
+		namespace:  namespace,
+		Log:        func(_ string, _ ...interface{}) {},
+	}, nil
+}
+
+// Name returns the name of the driver.
+func (o *OCI) Name() string {
+	return OCIDriverName
+}
+
+// Get returns the release named by key or returns ErrReleaseNotFound.
+func (o *OCI) Get(key string) (*rspb.Release, error) {
+	return o.get(o.ref(o.namespace, key))
+}
+
+// List returns the list of all releases of the namespace such that
+// filter(release) == true. The OCI driver cannot list the releases of all
+// namespaces, as registries do not reliably list their repositories.
+func (o *OCI) List(filter func(*rspb.Release) bool) ([]*rspb.Release, error) {
+	keys, err := o.keys("")
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*rspb.Release
+	for _, key := range keys {
+		rls, err := o.get(o.ref(o.namespace, key))
+		if err != nil {
+			o.Log("list: failed to get release %s: %s", key, err)
+			continue
+		}
+		if filter(rls) {
+			results = append(results, rls)
+		}
+	}
+	return results, nil
+}
+
+// Query returns the set of releases of the namespace that match the provided
+// set of labels.
+func (o *OCI) Query(selector map[string]string) ([]*rspb.Release, error) {
+	keys, err := o.keys(selector["name"])
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*rspb.Release
+	for _, key := range keys {
+		ref := o.ref(o.namespace, key)
+		lbs, err := o.client.ReleaseLabels(ref)
+		if err != nil {
+			o.Log("query: failed to get the labels of release %s: %s", key, err)
+			continue
+		}
+		if !labels(lbs).match(selector) {
+			continue
+		}
+		rls, err := o.get(ref)
+		if err != nil {
+			o.Log("query: failed to get release %s: %s", key, err)
+			continue
+		}
+		results = append(results, rls)
+	}
+	if len(results) == 0 {
+		return nil, ErrReleaseNotFound
+	}
+	return results, nil
+}
+
+// Create stores the release in the repository of its namespace, or returns
+// ErrReleaseExists if a release with the same key exists.
+func (o *OCI) Create(key string, rls *rspb.Release) error {
+	ref := o.ref(o.releaseNamespace(rls), key)
+	if _, err := o.client.ReleaseLabels(ref); err == nil {
+		return ErrReleaseExists
+	} else if !errors.Is(err, registry.ErrNotFound) {
+		return errors.Wrapf(err, "create: failed to check release %q", key)
+	}
+
+	var lbs labels
+	lbs.init()
+	lbs.set("createdAt", strconv.Itoa(int(time.Now().Unix())))
+	return errors.Wrapf(o.push(ref, rls, lbs), "create: failed to create release %q", key)
+}
+
+// Update replaces the release stored under key, or returns ErrReleaseNotFound
+// if no release is stored under key.
+func (o *OCI) Update(key string, rls *rspb.Release) error {
+	ref := o.ref(o.releaseNamespace(rls), key)
+	existing, err := o.client.ReleaseLabels(ref)
+	if errors.Is(err, registry.ErrNotFound) {
+		return ErrReleaseNotFound
+	} else if err != nil {
+		return errors.Wrapf(err, "update: failed to get release %q", key)
+	}
+
+	var lbs labels
+	lbs.init()
+	if createdAt, ok := existing["createdAt"]; ok {
+		lbs.set("createdAt", createdAt)
+	}
+	lbs.set("modifiedAt", strconv.Itoa(int(time.Now().Unix())))
+	return errors.Wrapf(o.push(ref, rls, lbs), "update: failed to update release %q", key)
+}
+
+// Delete deletes the release named by key and returns it, or returns
+// ErrReleaseNotFound.
+func (o *OCI) Delete(key string) (*rspb.Release, error) {
+	ref := o.ref(o.namespace, key)
+	rls, err := o.get(ref)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.client.DeleteRelease(ref); err != nil {
+		if errors.Is(err, registry.ErrNotFound) {
+			return nil, ErrReleaseNotFound
+		}
+		return nil, errors.Wrapf(err, "delete: failed to delete release %q", key)
+	}
+	return rls, nil
+}
+
+// get pulls and decodes the release of a reference.
+func (o *OCI) get(ref string) (*rspb.Release, error) {
+	data, lbs, err := o.client.PullRelease(ref)
+	if errors.Is(err, registry.ErrNotFound) {
+		return nil, ErrReleaseNotFound
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "get: failed to get %q", ref)
+	}
+	rls, err := decodeRelease(b64.EncodeToString(data))
+	if err != nil {
+		return nil, errors.Wrapf(err, "get: failed to decode data %q", ref)
+	}
+	rls.Labels = filterSystemLabels(lbs)
+	return rls, nil
+}
+
+// push encodes the release and pushes it with the labels, the custom labels
+// of the release and the system labels.
+func (o *OCI) push(ref string, rls *rspb.Release, lbs labels) error {
+	s, err := encodeRelease(rls)
+	if err != nil {
+		return err
+	}
+	data, err := b64.DecodeString(s)
+	if err != nil {
+		return err
+	}
+
+	lbs.fromMap(filterSystemLabels(rls.Labels))
+	lbs.set("name", rls.Name)
+	lbs.set("owner", DefaultOwner)
+	lbs.set("status", rls.Info.Status.String())
+	lbs.set("version", strconv.Itoa(rls.Version))
+	return o.client.PushRelease(data, lbs.toMap(), ref)
+}
+
+// keys returns the sorted keys of the records in the repository of the
+// namespace, optionally only those of the named release.
+func (o *OCI) keys(name string) ([]string, error) {
+	if o.namespace == "" {
+		return nil, errors.New("the OCI driver cannot list the releases of all namespaces")
+	}
+	tags, err := o.client.ListTags(o.repositoryOf(o.namespace))
+	if errors.Is(err, registry.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the releases of namespace %q", o.namespace)
+	}
+
+	var keys []string
+	for _, tag := range tags {
+		if name == "" || strings.HasPrefix(strings.TrimPrefix(tag, keyPrefix), name+".v") {
+			keys = append(keys, tag)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (o *OCI) releaseNamespace(rls *rspb.Release) string {
+	switch {
+	case rls.Namespace != "":
+		return rls.Namespace
+	case o.namespace != "":
+		return o.namespace
+	default:
+		return defaultNamespace
+	}
+}
+
+func (o *OCI) repositoryOf(namespace string) string {
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	return o.repository + "/" + namespace
+}
+
+func (o *OCI) ref(namespace, key string) string {
+	return o.repositoryOf(namespace) + ":" + key
+}
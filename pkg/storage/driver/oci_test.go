@@ -0,0 +1,193 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	rspb "helm.sh/helm/v4/pkg/release"
+)
+
+func TestOCIName(t *testing.T) {
+	d, _ := newTestFixtureOCI(t)
+	if d.Name() != OCIDriverName {
+		t.Errorf("Expected name to be %q, got %q", OCIDriverName, d.Name())
+	}
+	if _, err := NewOCI(&MockOCIClient{}, "oci://", "default"); err == nil {
+		t.Error("Expected an error without a repository")
+	}
+}
+
+func TestOCIGet(t *testing.T) {
+	rel := releaseStub("smug-pigeon", 1, "default", rspb.StatusDeployed)
+	d, mock := newTestFixtureOCI(t, rel)
+
+	got, err := d.Get(testKey("smug-pigeon", 1))
+	if err != nil {
+		t.Fatalf("Failed to get release: %s", err)
+	}
+	if !reflect.DeepEqual(rel, got) {
+		t.Errorf("Expected {%v}, got {%v}", rel, got)
+	}
+
+	a, ok := mock.artifacts["registry.example.com/releases/test/default:smug-pigeon.v1"]
+	if !ok {
+		t.Fatalf("Expected the release in the repository of its namespace, got %v", mock.artifacts)
+	}
+	for k, v := range map[string]string{"name": "smug-pigeon", "owner": "helm", "status": "deployed", "version": "1", "key1": "val1"} {
+		if a.labels[k] != v {
+			t.Errorf("Expected label %s=%s, got %q", k, v, a.labels[k])
+		}
+	}
+	if _, ok := a.labels["createdAt"]; !ok {
+		t.Error("Expected a createdAt label")
+	}
+
+	if _, err := d.Get(testKey("smug-pigeon", 2)); !errors.Is(err, ErrReleaseNotFound) {
+		t.Errorf("Expected ErrReleaseNotFound, got %v", err)
+	}
+}
+
+func TestOCIList(t *testing.T) {
+	d, _ := newTestFixtureOCI(t,
+		releaseStub("key-1", 1, "default", rspb.StatusUninstalled),
+		releaseStub("key-2", 1, "default", rspb.StatusUninstalled),
+		releaseStub("key-3", 1, "default", rspb.StatusDeployed),
+		releaseStub("key-4", 1, "default", rspb.StatusDeployed),
+		releaseStub("key-5", 1, "default", rspb.StatusSuperseded),
+		releaseStub("key-6", 1, "other", rspb.StatusSuperseded),
+	)
+
+	deployed, err := d.List(func(rel *rspb.Release) bool {
+		return rel.Info.Status == rspb.StatusDeployed
+	})
+	if err != nil {
+		t.Fatalf("Failed to list deployed releases: %s", err)
+	}
+	if len(deployed) != 2 {
+		t.Errorf("Expected 2 deployed, got %d", len(deployed))
+	}
+
+	all, err := d.List(func(*rspb.Release) bool { return true })
+	if err != nil {
+		t.Fatalf("Failed to list releases: %s", err)
+	}
+	if len(all) != 5 {
+		t.Errorf("Expected the 5 releases of the namespace, got %d", len(all))
+	}
+
+	empty, _ := NewOCI(&MockOCIClient{}, "registry.example.com/releases/test", "empty")
+	if rels, err := empty.List(func(*rspb.Release) bool { return true }); err != nil || len(rels) != 0 {
+		t.Errorf("Expected no releases in an empty namespace, got %v, %v", rels, err)
+	}
+
+	d.namespace = ""
+	if _, err := d.List(func(*rspb.Release) bool { return true }); err == nil {
+		t.Error("Expected an error listing all namespaces")
+	}
+}
+
+func TestOCIQuery(t *testing.T) {
+	d, _ := newTestFixtureOCI(t,
+		releaseStub("smug-pigeon", 1, "default", rspb.StatusSuperseded),
+		releaseStub("smug-pigeon", 2, "default", rspb.StatusDeployed),
+		releaseStub("smug-pigeon-2", 1, "default", rspb.StatusDeployed),
+	)
+
+	rls, err := d.Query(map[string]string{"name": "smug-pigeon", "owner": "helm", "status": "deployed"})
+	if err != nil {
+		t.Fatalf("Failed to query: %s", err)
+	}
+	if len(rls) != 1 || rls[0].Version != 2 {
+		t.Errorf("Expected revision 2 of smug-pigeon, got %v", rls)
+	}
+
+	rls, err = d.Query(map[string]string{"key1": "val1"})
+	if err != nil {
+		t.Fatalf("Failed to query: %s", err)
+	}
+	if len(rls) != 3 {
+		t.Errorf("Expected 3 releases with a custom label, got %d", len(rls))
+	}
+
+	if _, err := d.Query(map[string]string{"name": "smug-pigeon", "status": "failed"}); !errors.Is(err, ErrReleaseNotFound) {
+		t.Errorf("Expected ErrReleaseNotFound, got %v", err)
+	}
+}
+
+func TestOCICreate(t *testing.T) {
+	d, _ := newTestFixtureOCI(t)
+	rel := releaseStub("smug-pigeon", 1, "default", rspb.StatusDeployed)
+	key := testKey(rel.Name, rel.Version)
+
+	if err := d.Create(key, rel); err != nil {
+		t.Fatalf("Failed to create release with key %q: %s", key, err)
+	}
+	if err := d.Create(key, rel); !errors.Is(err, ErrReleaseExists) {
+		t.Errorf("Expected ErrReleaseExists, got %v", err)
+	}
+}
+
+func TestOCIUpdate(t *testing.T) {
+	rel := releaseStub("smug-pigeon", 1, "default", rspb.StatusDeployed)
+	d, mock := newTestFixtureOCI(t, rel)
+	key := testKey(rel.Name, rel.Version)
+	ref := "registry.example.com/releases/test/default:" + key
+	createdAt := mock.artifacts[ref].labels["createdAt"]
+
+	rel.Info.Status = rspb.StatusSuperseded
+	if err := d.Update(key, rel); err != nil {
+		t.Fatalf("Failed to update release: %s", err)
+	}
+	got, err := d.Get(key)
+	if err != nil {
+		t.Fatalf("Failed to get release: %s", err)
+	}
+	if got.Info.Status != rspb.StatusSuperseded {
+		t.Errorf("Expected status %s, got %s", rspb.StatusSuperseded, got.Info.Status)
+	}
+	lbs := mock.artifacts[ref].labels
+	if lbs["status"] != "superseded" || lbs["createdAt"] != createdAt || lbs["modifiedAt"] == "" {
+		t.Errorf("Expected updated labels, got %v", lbs)
+	}
+
+	if err := d.Update(testKey(rel.Name, 2), rel); !errors.Is(err, ErrReleaseNotFound) {
+		t.Errorf("Expected ErrReleaseNotFound, got %v", err)
+	}
+}
+
+func TestOCIDelete(t *testing.T) {
+	rel := releaseStub("smug-pigeon", 1, "default", rspb.StatusDeployed)
+	d, _ := newTestFixtureOCI(t, rel)
+	key := testKey(rel.Name, rel.Version)
+
+	got, err := d.Delete(key)
+	if err != nil {
+		t.Fatalf("Failed to delete release: %s", err)
+	}
+	if !reflect.DeepEqual(rel, got) {
+		t.Errorf("Expected {%v}, got {%v}", rel, got)
+	}
+	if _, err := d.Get(key); !errors.Is(err, ErrReleaseNotFound) {
+		t.Errorf("Expected ErrReleaseNotFound, got %v", err)
+	}
+	if _, err := d.Delete(key); !errors.Is(err, ErrReleaseNotFound) {
+		t.Errorf("Expected ErrReleaseNotFound, got %v", err)
+	}
+}
@@ -35,6 +35,9 @@ import (
 )
 
 var _ Driver = (*Secrets)(nil)
+var _ Scanner = (*Secrets)(nil)
+var _ MetadataLister = (*Secrets)(nil)
+var _ DriverV2 = (*Secrets)(nil)
 
 // SecretsDriverName is the string name of the driver.
 const SecretsDriverName = "Secret"
@@ -63,8 +66,13 @@ func (secrets *Secrets) Name() string {
 // Get fetches the release named by key. The corresponding release is returned
 // or error if not found.
 func (secrets *Secrets) Get(key string) (*rspb.Release, error) {
+	return secrets.GetContext(context.Background(), key)
+}
+
+// GetContext is like Get, but passes ctx to the Kubernetes API.
+func (secrets *Secrets) GetContext(ctx context.Context, key string) (*rspb.Release, error) {
 	// fetch the secret holding the release named by key
-	obj, err := secrets.impl.Get(context.Background(), key, metav1.GetOptions{})
+	obj, err := secrets.impl.Get(ctx, key, metav1.GetOptions{})
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			return nil, ErrReleaseNotFound
@@ -81,6 +89,52 @@ func (secrets *Secrets) Get(key string) (*rspb.Release, error) {
 // that filter(release) == true. An error is returned if the
 // secret fails to retrieve the releases.
 func (secrets *Secrets) List(filter func(*rspb.Release) bool) ([]*rspb.Release, error) {
+	return secrets.list(filter, decodeRelease)
+}
+
+// ListMetadata is like List, but only decodes the metadata of the releases.
+func (secrets *Secrets) ListMetadata(filter func(*rspb.Release) bool) ([]*rspb.Release, error) {
+	return secrets.list(filter, decodeReleaseMetadata)
+}
+
+// ListContext fetches a page of the releases selected by opts. The filter is
+// translated to a label selector, and Limit and Continue are passed to the
+// Kubernetes API. Releases are then filtered by NamePrefix, so a page may hold
+// fewer than Limit releases.
+func (secrets *Secrets) ListContext(ctx context.Context, opts ListOptions) (*ListResult, error) {
+	lsel, err := opts.labelSelector()
+	if err != nil {
+		return nil, err
+	}
+	list, err := secrets.impl.List(ctx, metav1.ListOptions{
+		LabelSelector: lsel.String(),
+		Limit:         int64(opts.Limit),
+		Continue:      opts.Continue,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list: failed to list")
+	}
+
+	decode := decodeRelease
+	if opts.MetadataOnly {
+		decode = decodeReleaseMetadata
+	}
+	res := &ListResult{Continue: list.Continue}
+	for _, item := range list.Items {
+		rls, err := decode(string(item.Data["release"]))
+		if err != nil {
+			secrets.Log("list: failed to decode release: %v: %s", item, err)
+			continue
+		}
+		rls.Labels = item.ObjectMeta.Labels
+		if opts.Matches(rls) {
+			res.Releases = append(res.Releases, rls)
+		}
+	}
+	return res, nil
+}
+
+func (secrets *Secrets) list(filter func(*rspb.Release) bool, decode func(string) (*rspb.Release, error)) ([]*rspb.Release, error) {
 	lsel := kblabels.Set{"owner": "helm"}.AsSelector()
 	opts := metav1.ListOptions{LabelSelector: lsel.String()}
 
@@ -94,7 +148,7 @@ func (secrets *Secrets) List(filter func(*rspb.Release) bool) ([]*rspb.Release,
 	// iterate over the secrets object list
 	// and decode each release
 	for _, item := range list.Items {
-		rls, err := decodeRelease(string(item.Data["release"]))
+		rls, err := decode(string(item.Data["release"]))
 		if err != nil {
 			secrets.Log("list: failed to decode release: %v: %s", item, err)
 			continue
@@ -147,6 +201,11 @@ func (secrets *Secrets) Query(labels map[string]string) ([]*rspb.Release, error)
 // Create creates a new Secret holding the release. If the
 // Secret already exists, ErrReleaseExists is returned.
 func (secrets *Secrets) Create(key string, rls *rspb.Release) error {
+	return secrets.CreateContext(context.Background(), key, rls)
+}
+
+// CreateContext is like Create, but passes ctx to the Kubernetes API.
+func (secrets *Secrets) CreateContext(ctx context.Context, key string, rls *rspb.Release) error {
 	// set labels for secrets object meta data
 	var lbs labels
 
@@ -160,7 +219,7 @@ func (secrets *Secrets) Create(key string, rls *rspb.Release) error {
 		return errors.Wrapf(err, "create: failed to encode release %q", rls.Name)
 	}
 	// push the secret object out into the kubiverse
-	if _, err := secrets.impl.Create(context.Background(), obj, metav1.CreateOptions{}); err != nil {
+	if _, err := secrets.impl.Create(ctx, obj, metav1.CreateOptions{}); err != nil {
 		if apierrors.IsAlreadyExists(err) {
 			return ErrReleaseExists
 		}
@@ -173,6 +232,11 @@ func (secrets *Secrets) Create(key string, rls *rspb.Release) error {
 // Update updates the Secret holding the release. If not found
 // the Secret is created to hold the release.
 func (secrets *Secrets) Update(key string, rls *rspb.Release) error {
+	return secrets.UpdateContext(context.Background(), key, rls)
+}
+
+// UpdateContext is like Update, but passes ctx to the Kubernetes API.
+func (secrets *Secrets) UpdateContext(ctx context.Context, key string, rls *rspb.Release) error {
 	// set labels for secrets object meta data
 	var lbs labels
 
@@ -186,21 +250,62 @@ func (secrets *Secrets) Update(key string, rls *rspb.Release) error {
 		return errors.Wrapf(err, "update: failed to encode release %q", rls.Name)
 	}
 	// push the secret object out into the kubiverse
-	_, err = secrets.impl.Update(context.Background(), obj, metav1.UpdateOptions{})
+	_, err = secrets.impl.Update(ctx, obj, metav1.UpdateOptions{})
 	return errors.Wrap(err, "update: failed to update")
 }
 
 // Delete deletes the Secret holding the release named by key.
-func (secrets *Secrets) Delete(key string) (rls *rspb.Release, err error) {
+func (secrets *Secrets) Delete(key string) (*rspb.Release, error) {
+	return secrets.DeleteContext(context.Background(), key)
+}
+
+// DeleteContext is like Delete, but passes ctx to the Kubernetes API.
+func (secrets *Secrets) DeleteContext(ctx context.Context, key string) (rls *rspb.Release, err error) {
 	// fetch the release to check existence
-	if rls, err = secrets.Get(key); err != nil {
+	if rls, err = secrets.GetContext(ctx, key); err != nil {
 		return nil, err
 	}
 	// delete the release
-	err = secrets.impl.Delete(context.Background(), key, metav1.DeleteOptions{})
+	err = secrets.impl.Delete(ctx, key, metav1.DeleteOptions{})
 	return rls, err
 }
 
+// Scan fetches all secrets owned by Helm and decodes the releases they hold.
+// Secrets that fail to decode are returned with the decoding error.
+func (secrets *Secrets) Scan() ([]*Record, error) {
+	lsel := kblabels.Set{"owner": "helm"}.AsSelector()
+	opts := metav1.ListOptions{LabelSelector: lsel.String()}
+
+	list, err := secrets.impl.List(context.Background(), opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "scan: failed to list")
+	}
+
+	records := make([]*Record, 0, len(list.Items))
+	for _, item := range list.Items {
+		rec := &Record{Key: item.Name, Labels: item.ObjectMeta.Labels}
+		rls, err := decodeRelease(string(item.Data["release"]))
+		if err != nil {
+			rec.Err = err
+		} else {
+			rls.Labels = item.ObjectMeta.Labels
+			rec.Release = rls
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// DeleteRecord deletes the Secret named by key without decoding the release it
+// holds.
+func (secrets *Secrets) DeleteRecord(key string) error {
+	err := secrets.impl.Delete(context.Background(), key, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return ErrReleaseNotFound
+	}
+	return errors.Wrapf(err, "delete: failed to delete %q", key)
+}
+
 // newSecretsObject constructs a kubernetes Secret object
 // to store a release. Each secret data entry is the base64
 // encoded gzipped string of a release.
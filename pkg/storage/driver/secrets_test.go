@@ -14,8 +14,10 @@ limitations under the License.
 package driver
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"reflect"
 	"testing"
 
@@ -140,6 +142,28 @@ func TestSecretList(t *testing.T) {
 	}
 }
 
+func TestSecretListMetadata(t *testing.T) {
+	rel := releaseStub("key-1", 1, "default", rspb.StatusDeployed)
+	rel.Manifest = "kind: ConfigMap"
+	secrets := newTestFixtureSecrets(t, rel, releaseStub("key-2", 1, "default", rspb.StatusUninstalled))
+
+	dpl, err := secrets.ListMetadata(func(rel *rspb.Release) bool {
+		return rel.Info.Status == rspb.StatusDeployed
+	})
+	if err != nil {
+		t.Fatalf("Failed to list deployed: %s", err)
+	}
+	if len(dpl) != 1 {
+		t.Fatalf("Expected 1 deployed, got %d", len(dpl))
+	}
+	if got := dpl[0]; got.Name != "key-1" || got.Namespace != "default" || got.Version != 1 || got.Manifest != "" {
+		t.Errorf("Expected only the metadata of key-1, got %v", got)
+	}
+	if _, ok := dpl[0].Labels["key1"]; !ok {
+		t.Errorf("Expected 'key1' label in results, actual %v", dpl[0].Labels)
+	}
+}
+
 func TestSecretQuery(t *testing.T) {
 	secrets := newTestFixtureSecrets(t, []*rspb.Release{
 		releaseStub("key-1", 1, "default", rspb.StatusUninstalled),
@@ -249,3 +273,96 @@ func TestSecretDelete(t *testing.T) {
 		t.Errorf("Expected {%v}, got {%v}", ErrReleaseNotFound, err)
 	}
 }
+
+func TestSecretScan(t *testing.T) {
+	rel := releaseStub("smug-pigeon", 1, "default", rspb.StatusDeployed)
+	corrupted := releaseStub("smug-pigeon", 2, "default", rspb.StatusDeployed)
+
+	var mock MockSecretsInterface
+	mock.Init(t, rel, corrupted)
+	mock.objects[testKey("smug-pigeon", 2)].Data["release"] = []byte("not a release")
+	secrets := NewSecrets(&mock)
+
+	records, err := secrets.Scan()
+	if err != nil {
+		t.Fatalf("Failed to scan: %s", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	for _, rec := range records {
+		switch rec.Key {
+		case testKey("smug-pigeon", 1):
+			if rec.Err != nil || rec.Release == nil || rec.Release.Version != 1 {
+				t.Errorf("Expected revision 1 to be decoded, got %v (%v)", rec.Release, rec.Err)
+			}
+		case testKey("smug-pigeon", 2):
+			if rec.Err == nil || rec.Release != nil {
+				t.Errorf("Expected a decoding error for revision 2")
+			}
+			if rec.Labels["version"] != "2" {
+				t.Errorf("Expected the labels of revision 2, got %v", rec.Labels)
+			}
+		default:
+			t.Errorf("Unexpected record %q", rec.Key)
+		}
+	}
+
+	// corrupted records cannot be deleted by Delete
+	if err := secrets.DeleteRecord(testKey("smug-pigeon", 2)); err != nil {
+		t.Fatalf("Failed to delete record: %s", err)
+	}
+	if err := secrets.DeleteRecord(testKey("smug-pigeon", 2)); err != ErrReleaseNotFound {
+		t.Errorf("Expected ErrReleaseNotFound, got: {%v}", err)
+	}
+}
+
+func TestSecretListContext(t *testing.T) {
+	secrets := newTestFixtureSecrets(t, []*rspb.Release{
+		releaseStub("key-1", 1, "default", rspb.StatusSuperseded),
+		releaseStub("key-1", 2, "default", rspb.StatusDeployed),
+		releaseStub("key-2", 1, "default", rspb.StatusFailed),
+		releaseStub("other", 1, "default", rspb.StatusDeployed),
+	}...)
+
+	opts := ListOptions{Filter: Filter{NamePrefix: "key-"}, Limit: 2}
+	res, err := secrets.ListContext(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Failed to list: %s", err)
+	}
+	if got := fmt.Sprint(releaseIDs(res.Releases)); got != "[default/key-1.v1 default/key-1.v2]" {
+		t.Errorf("Expected the first page to hold key-1, got %s", got)
+	}
+	if res.Continue == "" {
+		t.Fatal("Expected a continue token")
+	}
+
+	opts.Continue = res.Continue
+	if res, err = secrets.ListContext(context.Background(), opts); err != nil {
+		t.Fatalf("Failed to list: %s", err)
+	}
+	if got := fmt.Sprint(releaseIDs(res.Releases)); got != "[default/key-2.v1]" {
+		t.Errorf("Expected the second page to hold key-2 only, got %s", got)
+	}
+	if res.Continue != "" {
+		t.Errorf("Expected no continue token, got %q", res.Continue)
+	}
+
+	all, err := ListAll(context.Background(), secrets, ListOptions{
+		Filter:       Filter{Statuses: []rspb.Status{rspb.StatusDeployed, rspb.StatusFailed}},
+		MetadataOnly: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to list: %s", err)
+	}
+	if got := fmt.Sprint(releaseIDs(all)); got != "[default/key-1.v2 default/key-2.v1 default/other.v1]" {
+		t.Errorf("Expected the deployed and failed releases, got %s", got)
+	}
+
+	if res, err = secrets.ListContext(context.Background(), ListOptions{Filter: Filter{Owner: "someone-else"}}); err != nil {
+		t.Fatalf("Failed to list: %s", err)
+	}
+	if len(res.Releases) != 0 {
+		t.Errorf("Expected no releases of another owner, got %s", releaseIDs(res.Releases))
+	}
+}
@@ -34,6 +34,7 @@ import (
 )
 
 var _ Driver = (*SQL)(nil)
+var _ MetadataLister = (*SQL)(nil)
 
 var labelMap = map[string]struct{}{
 	"modifiedAt": {},
@@ -335,6 +336,15 @@ func (s *SQL) Get(key string) (*rspb.Release, error) {
 
 // List returns the list of all releases such that filter(release) == true
 func (s *SQL) List(filter func(*rspb.Release) bool) ([]*rspb.Release, error) {
+	return s.list(filter, decodeRelease)
+}
+
+// ListMetadata is like List, but only decodes the metadata of the releases.
+func (s *SQL) ListMetadata(filter func(*rspb.Release) bool) ([]*rspb.Release, error) {
+	return s.list(filter, decodeReleaseMetadata)
+}
+
+func (s *SQL) list(filter func(*rspb.Release) bool, decode func(string) (*rspb.Release, error)) ([]*rspb.Release, error) {
 	sb := s.statementBuilder.
 		Select(sqlReleaseTableKeyColumn, sqlReleaseTableNamespaceColumn, sqlReleaseTableBodyColumn).
 		From(sqlReleaseTableName).
@@ -359,7 +369,7 @@ func (s *SQL) List(filter func(*rspb.Release) bool) ([]*rspb.Release, error) {
 
 	var releases []*rspb.Release
 	for _, record := range records {
-		release, err := decodeRelease(record.Body)
+		release, err := decode(record.Body)
 		if err != nil {
 			s.Log("list: failed to decode release: %v: %v", record, err)
 			continue
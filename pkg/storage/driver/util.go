@@ -21,8 +21,10 @@ import (
 	"compress/gzip"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io"
 
+	"helm.sh/helm/v4/pkg/chart"
 	rspb "helm.sh/helm/v4/pkg/release"
 )
 
@@ -32,10 +34,48 @@ var magicGzip = []byte{0x1f, 0x8b, 0x08}
 
 var systemLabels = []string{"name", "owner", "status", "version", "createdAt", "modifiedAt"}
 
+// releaseEnvelope holds the fields of a release in the order in which they
+// are encoded: the metadata first, so that decodeReleaseMetadata can stop
+// reading before the chart templates, the values, the manifest and the hooks.
+// The JSON representation is the same as that of a release otherwise.
+type releaseEnvelope struct {
+	Name      string                 `json:"name,omitempty"`
+	Namespace string                 `json:"namespace,omitempty"`
+	Version   int                    `json:"version,omitempty"`
+	Info      *rspb.Info             `json:"info,omitempty"`
+	Chart     *chart.Chart           `json:"chart,omitempty"`
+	Config    map[string]interface{} `json:"config,omitempty"`
+	Manifest  string                 `json:"manifest,omitempty"`
+	Hooks     []*rspb.Hook           `json:"hooks,omitempty"`
+
+	PinnedValues map[string]interface{} `json:"pinned_values,omitempty"`
+	ConfigRaw    string                 `json:"config_raw,omitempty"`
+
+	ValuesSources []*rspb.ValuesSource `json:"values_sources,omitempty"`
+
+	Exclusions *rspb.ResourceExclusions `json:"exclusions,omitempty"`
+}
+
 // encodeRelease encodes a release returning a base64 encoded
 // gzipped string representation, or error.
 func encodeRelease(rls *rspb.Release) (string, error) {
-	b, err := json.Marshal(rls)
+	b, err := json.Marshal(&releaseEnvelope{
+		Name:      rls.Name,
+		Namespace: rls.Namespace,
+		Version:   rls.Version,
+		Info:      rls.Info,
+		Chart:     rls.Chart,
+		Config:    rls.Config,
+		Manifest:  rls.Manifest,
+		Hooks:     rls.Hooks,
+
+		PinnedValues: rls.PinnedValues,
+		ConfigRaw:    rls.ConfigRaw,
+
+		ValuesSources: rls.ValuesSources,
+
+		Exclusions: rls.Exclusions,
+	})
 	if err != nil {
 		return "", err
 	}
@@ -56,34 +96,145 @@ func encodeRelease(rls *rspb.Release) (string, error) {
 // type. Data must contain a base64 encoded gzipped string of a
 // valid release, otherwise an error is returned.
 func decodeRelease(data string) (*rspb.Release, error) {
-	// base64 decode string
-	b, err := b64.DecodeString(data)
+	r, err := releaseDataReader(data)
+	if err != nil {
+		return nil, err
+	}
+	b, err := io.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
 
-	// For backwards compatibility with releases that were stored before
-	// compression was introduced we skip decompression if the
-	// gzip magic header is not found
-	if len(b) > 3 && bytes.Equal(b[0:3], magicGzip) {
-		r, err := gzip.NewReader(bytes.NewReader(b))
+	var rls rspb.Release
+	// unmarshal release object bytes
+	if err := json.Unmarshal(b, &rls); err != nil {
+		return nil, err
+	}
+	return &rls, nil
+}
+
+// decodeReleaseMetadata decodes the bytes of data like decodeRelease, but
+// only the name, namespace, version, info and chart metadata of the release.
+//
+// The data is decoded as a stream, which stops as soon as these fields are
+// read. For releases encoded by encodeRelease, that is before the chart
+// templates, the values, the manifest and the hooks, which are neither
+// decompressed nor decoded. Releases encoded in another order are read to the
+// end, but their other fields are still skipped.
+func decodeReleaseMetadata(data string) (*rspb.Release, error) {
+	r, err := releaseDataReader(data)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	rls := &rspb.Release{}
+	seen := map[string]bool{}
+	done := func() bool {
+		return seen["name"] && seen["namespace"] && seen["version"] && seen["info"] && seen["chart"]
+	}
+	for !done() && dec.More() {
+		key, err := decodeKey(dec)
 		if err != nil {
 			return nil, err
 		}
-		defer r.Close()
-		b2, err := io.ReadAll(r)
+		seen[key] = true
+		switch key {
+		case "name":
+			err = dec.Decode(&rls.Name)
+		case "namespace":
+			err = dec.Decode(&rls.Namespace)
+		case "version":
+			err = dec.Decode(&rls.Version)
+		case "info":
+			err = dec.Decode(&rls.Info)
+		case "chart":
+			rls.Chart, err = decodeChartMetadata(dec, done)
+		default:
+			err = dec.Decode(&json.RawMessage{})
+		}
 		if err != nil {
 			return nil, err
 		}
-		b = b2
 	}
+	return rls, nil
+}
 
-	var rls rspb.Release
-	// unmarshal release object bytes
-	if err := json.Unmarshal(b, &rls); err != nil {
+// decodeChartMetadata decodes the metadata of a chart and skips the rest of
+// the chart, unless done reports that the release metadata is complete.
+func decodeChartMetadata(dec *json.Decoder, done func() bool) (*chart.Chart, error) {
+	tok, err := dec.Token()
+	if err != nil || tok == nil {
 		return nil, err
 	}
-	return &rls, nil
+	if tok != json.Delim('{') {
+		return nil, fmt.Errorf("unexpected %v in chart", tok)
+	}
+
+	c := &chart.Chart{}
+	for dec.More() {
+		key, err := decodeKey(dec)
+		if err != nil {
+			return nil, err
+		}
+		if key != "metadata" {
+			if err := dec.Decode(&json.RawMessage{}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := dec.Decode(&c.Metadata); err != nil {
+			return nil, err
+		}
+		if done() {
+			return c, nil
+		}
+	}
+	return c, expectDelim(dec, '}')
+}
+
+func decodeKey(dec *json.Decoder) (string, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := tok.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected %v instead of a key", tok)
+	}
+	return key, nil
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("unexpected %v instead of %v", tok, delim)
+	}
+	return nil
+}
+
+// releaseDataReader returns a reader for the JSON representation of the
+// base64 encoded and optionally gzipped data of a release.
+func releaseDataReader(data string) (io.Reader, error) {
+	// base64 decode string
+	b, err := b64.DecodeString(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// For backwards compatibility with releases that were stored before
+	// compression was introduced we skip decompression if the
+	// gzip magic header is not found
+	if len(b) > 3 && bytes.Equal(b[0:3], magicGzip) {
+		return gzip.NewReader(bytes.NewReader(b))
+	}
+	return bytes.NewReader(b), nil
 }
 
 // Checks if label is system
@@ -14,8 +14,15 @@ limitations under the License.
 package driver
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"reflect"
+	"strings"
 	"testing"
+
+	"helm.sh/helm/v4/pkg/chart"
+	rspb "helm.sh/helm/v4/pkg/release"
 )
 
 func TestGetSystemLabel(t *testing.T) {
@@ -106,3 +113,111 @@ func TestContainsSystemLabels(t *testing.T) {
 		}
 	}
 }
+
+func largeReleaseStub() *rspb.Release {
+	rls := releaseStub("smug-pigeon", 3, "default", rspb.StatusDeployed)
+	rls.Info.Description = "Upgrade complete"
+	rls.Chart = &chart.Chart{
+		Metadata: &chart.Metadata{Name: "pigeon", Version: "1.2.3", AppVersion: "4.5.6"},
+		Templates: []*chart.File{
+			{Name: "templates/configmap.yaml", Data: []byte(strings.Repeat("data: value\n", 10000))},
+		},
+	}
+	rls.Config = map[string]interface{}{"replicas": 3}
+	rls.Manifest = strings.Repeat("---\napiVersion: v1\nkind: ConfigMap\n", 10000)
+	rls.Hooks = []*rspb.Hook{{Name: "pre-install", Manifest: "kind: Job"}}
+	return rls
+}
+
+func TestReleaseEnvelope(t *testing.T) {
+	fields := func(typ reflect.Type) map[string]bool {
+		tags := map[string]bool{}
+		for i := 0; i < typ.NumField(); i++ {
+			if tag := typ.Field(i).Tag.Get("json"); tag != "-" {
+				tags[tag] = true
+			}
+		}
+		return tags
+	}
+	if want, got := fields(reflect.TypeOf(rspb.Release{})), fields(reflect.TypeOf(releaseEnvelope{})); !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected the envelope to hold the release fields {%v}, got {%v}", want, got)
+	}
+
+	rls := largeReleaseStub()
+	data, err := encodeRelease(rls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decodeRelease(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := json.Marshal(rls)
+	if b, _ := json.Marshal(got); !bytes.Equal(want, b) {
+		t.Error("Expected the decoded release to equal the encoded one")
+	}
+}
+
+func TestDecodeReleaseMetadata(t *testing.T) {
+	rls := largeReleaseStub()
+	encoded, err := encodeRelease(rls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// releases stored by older versions hold the metadata in another order
+	b, err := json.Marshal(rls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unordered := base64.StdEncoding.EncodeToString(b)
+
+	for _, data := range []string{encoded, unordered} {
+		testDecodeReleaseMetadata(t, rls, data)
+	}
+
+	if _, err := decodeReleaseMetadata("not a release"); err == nil {
+		t.Error("Expected an error for invalid data")
+	}
+}
+
+func testDecodeReleaseMetadata(t *testing.T, rls *rspb.Release, data string) {
+	t.Helper()
+	got, err := decodeReleaseMetadata(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != rls.Name || got.Namespace != rls.Namespace || got.Version != rls.Version {
+		t.Errorf("Expected release %s/%s revision %d, got %s/%s revision %d",
+			rls.Namespace, rls.Name, rls.Version, got.Namespace, got.Name, got.Version)
+	}
+	if !reflect.DeepEqual(rls.Info, got.Info) {
+		t.Errorf("Expected info {%v}, got {%v}", rls.Info, got.Info)
+	}
+	if got.Chart == nil || !reflect.DeepEqual(rls.Chart.Metadata, got.Chart.Metadata) {
+		t.Errorf("Expected chart metadata {%v}, got {%v}", rls.Chart.Metadata, got.Chart)
+	}
+	if got.Chart.Templates != nil || got.Config != nil || got.Manifest != "" || got.Hooks != nil {
+		t.Error("Expected the templates, values, manifest and hooks to be skipped")
+	}
+}
+
+func BenchmarkDecodeRelease(b *testing.B) {
+	data, err := encodeRelease(largeReleaseStub())
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("full", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := decodeRelease(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("metadata", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := decodeReleaseMetadata(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,228 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver // import "helm.sh/helm/v4/pkg/storage/driver"
+
+import (
+	"context"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	kblabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+
+	rspb "helm.sh/helm/v4/pkg/release"
+)
+
+// DefaultOwner is the owner of the release records stored by Helm.
+const DefaultOwner = "helm"
+
+// ErrInvalidContinue indicates that a continue token is not valid for the
+// driver or the query.
+var ErrInvalidContinue = errors.New("invalid continue token")
+
+// Filter selects releases by typed criteria. The zero value selects all
+// releases owned by Helm.
+type Filter struct {
+	// Name selects the releases with this name.
+	Name string
+	// NamePrefix selects the releases whose name starts with the prefix.
+	NamePrefix string
+	// Statuses selects the releases with one of the statuses. Empty selects
+	// all statuses.
+	Statuses []rspb.Status
+	// Owner selects the releases of this owner. Empty selects DefaultOwner.
+	Owner string
+}
+
+// Matches reports whether the release is selected by the filter.
+// Releases without an owner label are assumed to be owned by Helm.
+func (f Filter) Matches(rls *rspb.Release) bool {
+	if f.Name != "" && rls.Name != f.Name {
+		return false
+	}
+	if !strings.HasPrefix(rls.Name, f.NamePrefix) {
+		return false
+	}
+	if len(f.Statuses) > 0 && (rls.Info == nil || !slices.Contains(f.Statuses, rls.Info.Status)) {
+		return false
+	}
+	if owner, ok := rls.Labels["owner"]; ok && owner != f.owner() {
+		return false
+	}
+	return true
+}
+
+func (f Filter) owner() string {
+	if f.Owner == "" {
+		return DefaultOwner
+	}
+	return f.Owner
+}
+
+// labelSelector returns the selector for the labels of the records of the
+// releases matched by the filter, except NamePrefix which cannot be selected
+// by labels.
+func (f Filter) labelSelector() (kblabels.Selector, error) {
+	set := kblabels.Set{"owner": f.owner()}
+	if f.Name != "" {
+		set["name"] = f.Name
+	}
+	sel := set.AsSelector()
+	if len(f.Statuses) > 0 {
+		statuses := make([]string, 0, len(f.Statuses))
+		for _, s := range f.Statuses {
+			statuses = append(statuses, s.String())
+		}
+		req, err := kblabels.NewRequirement("status", selection.In, statuses)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid status filter")
+		}
+		sel = sel.Add(*req)
+	}
+	return sel, nil
+}
+
+// ListOptions are the options of a DriverV2 list.
+type ListOptions struct {
+	Filter
+	// Limit is the maximum number of releases returned. Zero means no limit.
+	// It is a hint: the drivers adapted by NewDriverV2 return all the
+	// releases in one page.
+	Limit int
+	// Continue is the token returned by the previous list to fetch the next
+	// page.
+	Continue string
+	// MetadataOnly only decodes the metadata of the releases, see
+	// MetadataLister.
+	MetadataOnly bool
+}
+
+// ListResult is a page of releases returned by a DriverV2 list.
+type ListResult struct {
+	Releases []*rspb.Release
+	// Continue is set when more releases may be available. Pass it in
+	// ListOptions to fetch the next page. A page may hold fewer than Limit
+	// releases although more are available; only an empty Continue marks the
+	// last page.
+	Continue string
+}
+
+// DriverV2 is the storage driver interface with context cancellation,
+// pagination and typed filters.
+//
+// DriverV2 is introduced to avoid breaking backwards compatibility for Driver
+// implementers. Use NewDriverV2 to adapt a Driver.
+type DriverV2 interface {
+	Name() string
+	GetContext(ctx context.Context, key string) (*rspb.Release, error)
+	CreateContext(ctx context.Context, key string, rls *rspb.Release) error
+	UpdateContext(ctx context.Context, key string, rls *rspb.Release) error
+	DeleteContext(ctx context.Context, key string) (*rspb.Release, error)
+	ListContext(ctx context.Context, opts ListOptions) (*ListResult, error)
+}
+
+// NewDriverV2 returns the driver if it implements DriverV2, or an adapter
+// otherwise. The adapter checks the context before every call, and filters
+// the releases after listing all of them, which it returns in one page.
+func NewDriverV2(d Driver) DriverV2 {
+	if v2, ok := d.(DriverV2); ok {
+		return v2
+	}
+	return &driverAdapter{d}
+}
+
+// ListAll lists all pages of releases selected by the options.
+func ListAll(ctx context.Context, d DriverV2, opts ListOptions) ([]*rspb.Release, error) {
+	var releases []*rspb.Release
+	for {
+		res, err := d.ListContext(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, res.Releases...)
+		if res.Continue == "" {
+			return releases, nil
+		}
+		opts.Continue = res.Continue
+	}
+}
+
+type driverAdapter struct {
+	Driver
+}
+
+func (a *driverAdapter) GetContext(ctx context.Context, key string) (*rspb.Release, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.Get(key)
+}
+
+func (a *driverAdapter) CreateContext(ctx context.Context, key string, rls *rspb.Release) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.Create(key, rls)
+}
+
+func (a *driverAdapter) UpdateContext(ctx context.Context, key string, rls *rspb.Release) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.Update(key, rls)
+}
+
+func (a *driverAdapter) DeleteContext(ctx context.Context, key string) (*rspb.Release, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.Delete(key)
+}
+
+// ListContext lists all matching releases and sorts them by namespace, name
+// and version. The Driver cannot list a part of the releases, so they are all
+// returned in one page regardless of the limit: paging in memory would list
+// and decode every record again for each page, and skip or repeat releases
+// changed between the pages.
+func (a *driverAdapter) ListContext(ctx context.Context, opts ListOptions) (*ListResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if opts.Continue != "" {
+		return nil, ErrInvalidContinue
+	}
+
+	list := a.List
+	if ml, ok := a.Driver.(MetadataLister); ok && opts.MetadataOnly {
+		list = ml.ListMetadata
+	}
+	releases, err := list(opts.Filter.Matches)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		a, b := releases[i], releases[j]
+		if ka, kb := path.Join(a.Namespace, a.Name), path.Join(b.Namespace, b.Name); ka != kb {
+			return ka < kb
+		}
+		return a.Version < b.Version
+	})
+	return &ListResult{Releases: releases}, nil
+}
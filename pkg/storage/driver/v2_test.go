@@ -0,0 +1,133 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver // import "helm.sh/helm/v4/pkg/storage/driver"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	rspb "helm.sh/helm/v4/pkg/release"
+)
+
+func releaseIDs(rels []*rspb.Release) []string {
+	ids := make([]string, 0, len(rels))
+	for _, rel := range rels {
+		ids = append(ids, fmt.Sprintf("%s/%s.v%d", rel.Namespace, rel.Name, rel.Version))
+	}
+	return ids
+}
+
+func TestNewDriverV2(t *testing.T) {
+	secrets := newTestFixtureSecrets(t)
+	if d := NewDriverV2(secrets); d != secrets {
+		t.Errorf("Expected the Secrets driver to implement DriverV2, got %T", d)
+	}
+	if d := NewDriverV2(NewMemory()); d.Name() != MemoryDriverName {
+		t.Errorf("Expected the adapter to keep the driver name, got %q", d.Name())
+	}
+}
+
+func TestDriverAdapterListContext(t *testing.T) {
+	mem := tsFixtureMemory(t)
+	mem.SetNamespace("")
+	d := NewDriverV2(mem)
+
+	// The adapter returns all the releases in one page.
+	res, err := d.ListContext(context.Background(), ListOptions{Limit: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Releases) != 12 {
+		t.Errorf("Expected 12 releases, got %d", len(res.Releases))
+	}
+	want := "[default/rls-a.v1 default/rls-a.v2 default/rls-a.v3 default/rls-a.v4 default/rls-b.v1]"
+	if got := fmt.Sprint(releaseIDs(res.Releases[:5])); got != want {
+		t.Errorf("Expected the releases to start with %s, got %s", want, got)
+	}
+	if res.Continue != "" {
+		t.Errorf("Expected no continue token, got %q", res.Continue)
+	}
+
+	all, err := ListAll(context.Background(), d, ListOptions{Limit: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 12 {
+		t.Errorf("Expected 12 releases, got %d", len(all))
+	}
+
+	if _, err := d.ListContext(context.Background(), ListOptions{Continue: "5"}); !errors.Is(err, ErrInvalidContinue) {
+		t.Errorf("Expected ErrInvalidContinue, got %v", err)
+	}
+}
+
+func TestDriverAdapterFilter(t *testing.T) {
+	mem := tsFixtureMemory(t)
+	mem.SetNamespace("")
+	d := NewDriverV2(mem)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   string
+	}{
+		{
+			name:   "name",
+			filter: Filter{Name: "rls-c"},
+			want:   "[mynamespace/rls-c.v1 mynamespace/rls-c.v2 mynamespace/rls-c.v3 mynamespace/rls-c.v4]",
+		},
+		{
+			name:   "name prefix and status",
+			filter: Filter{NamePrefix: "rls-", Statuses: []rspb.Status{rspb.StatusDeployed}},
+			want:   "[default/rls-a.v4 default/rls-b.v4 mynamespace/rls-c.v4]",
+		},
+		{
+			name:   "status",
+			filter: Filter{Statuses: []rspb.Status{rspb.StatusFailed}},
+			want:   "[]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := d.ListContext(context.Background(), ListOptions{Filter: tt.filter})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := fmt.Sprint(releaseIDs(res.Releases)); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDriverAdapterCanceled(t *testing.T) {
+	d := NewDriverV2(tsFixtureMemory(t))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := d.ListContext(ctx, ListOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from ListContext, got %v", err)
+	}
+	if _, err := d.GetContext(ctx, testKey("rls-a", 1)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from GetContext, got %v", err)
+	}
+	if err := d.CreateContext(ctx, testKey("rls-d", 1), releaseStub("rls-d", 1, "default", rspb.StatusDeployed)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from CreateContext, got %v", err)
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package storage // import "helm.sh/helm/v4/pkg/storage"
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -34,6 +35,10 @@ import (
 // This constant is used as a prefix for the Kubernetes storage object name.
 const HelmStorageType = "sh.helm.release.v1"
 
+// historyPageSize is the number of revisions fetched per request by
+// HistoryContext.
+const historyPageSize = 100
+
 // Storage represents a storage engine for a Release.
 type Storage struct {
 	driver.Driver
@@ -92,6 +97,25 @@ func (s *Storage) ListReleases() ([]*rspb.Release, error) {
 	return s.Driver.List(func(_ *rspb.Release) bool { return true })
 }
 
+// ListMetadata returns the releases such that filter(release) == true, like
+// List. When the storage driver implements driver.MetadataLister, only the
+// metadata of the releases is decoded: their charts only hold the chart
+// metadata, and their values, manifests and hooks are not set.
+func (s *Storage) ListMetadata(filter func(*rspb.Release) bool) ([]*rspb.Release, error) {
+	s.Log("listing release metadata in storage")
+	if ml, ok := s.Driver.(driver.MetadataLister); ok {
+		return ml.ListMetadata(filter)
+	}
+	return s.Driver.List(filter)
+}
+
+// ListContext returns a page of the releases selected by opts. ctx cancels the
+// request to the storage backend.
+func (s *Storage) ListContext(ctx context.Context, opts driver.ListOptions) (*driver.ListResult, error) {
+	s.Log("listing releases in storage (limit %d)", opts.Limit)
+	return driver.NewDriverV2(s.Driver).ListContext(ctx, opts)
+}
+
 // ListUninstalled returns all releases with Status == UNINSTALLED. An error is returned
 // if the storage backend fails to retrieve the releases.
 func (s *Storage) ListUninstalled() ([]*rspb.Release, error) {
@@ -156,6 +180,97 @@ func (s *Storage) History(name string) ([]*rspb.Release, error) {
 	return s.Driver.Query(map[string]string{"name": name, "owner": "helm"})
 }
 
+// HistoryContext is like History, but ctx cancels the requests to the storage
+// backend. Revisions are fetched in pages of historyPageSize.
+func (s *Storage) HistoryContext(ctx context.Context, name string) ([]*rspb.Release, error) {
+	s.Log("getting release history for %q", name)
+
+	h, err := driver.ListAll(ctx, driver.NewDriverV2(s.Driver), driver.ListOptions{
+		Filter: driver.Filter{Name: name},
+		Limit:  historyPageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(h) == 0 {
+		return nil, driver.ErrReleaseNotFound
+	}
+	return h, nil
+}
+
+// ExcessHistory returns the oldest revisions of the named release beyond
+// maximum, in order, without deleting them. The deployed and the latest
+// revisions are never part of it, so more than maximum revisions may remain.
+// Values of maximum of 0 or less mean no limit.
+func (s *Storage) ExcessHistory(name string, maximum int) ([]*rspb.Release, error) {
+	if maximum <= 0 {
+		return nil, nil
+	}
+	h, err := s.History(name)
+	if err != nil {
+		return nil, err
+	}
+	return ExcessRevisions(h, maximum), nil
+}
+
+// ExcessRevisions returns the oldest of the revisions of a release beyond
+// maximum, like ExcessHistory. It sorts h by revision.
+func ExcessRevisions(h []*rspb.Release, maximum int) []*rspb.Release {
+	if maximum <= 0 || len(h) <= maximum {
+		return nil
+	}
+	relutil.SortByRevision(h)
+
+	latest := h[len(h)-1].Version
+	deployed := 0
+	for _, rel := range h {
+		if rel.Info != nil && rel.Info.Status == rspb.StatusDeployed {
+			deployed = rel.Version
+		}
+	}
+
+	var excess []*rspb.Release
+	for _, rel := range h {
+		if len(h)-len(excess) <= maximum {
+			break
+		}
+		if rel.Version != latest && rel.Version != deployed {
+			excess = append(excess, rel)
+		}
+	}
+	return excess
+}
+
+// TrimHistory deletes the revisions of the named release returned by
+// ExcessHistory, e.g. to repair a history that grew beyond the limit before
+// it was set. It returns the deleted revisions.
+func (s *Storage) TrimHistory(name string, maximum int) ([]*rspb.Release, error) {
+	excess, err := s.ExcessHistory(name, maximum)
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted []*rspb.Release
+	var errs []error
+	for _, rel := range excess {
+		if err := s.deleteReleaseVersion(name, rel.Version); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		deleted = append(deleted, rel)
+	}
+
+	s.Log("Trimmed %d record(s) from %s with %d error(s)", len(deleted), name, len(errs))
+	switch c := len(errs); c {
+	case 0:
+		return deleted, nil
+	case 1:
+		return deleted, errs[0]
+	default:
+		return deleted, errors.Errorf("encountered %d deletion errors. First is: %s", c, errs[0])
+	}
+}
+
 // removeLeastRecent removes items from history until the length number of releases
 // does not exceed max.
 //
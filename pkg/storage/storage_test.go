@@ -444,6 +444,59 @@ func TestStorageDoNotDeleteDeployed(t *testing.T) {
 	}
 }
 
+func TestStorageTrimHistory(t *testing.T) {
+	storage := Init(driver.NewMemory())
+	storage.Log = t.Logf
+
+	const name = "angry-bird"
+
+	statuses := []rspb.Status{
+		rspb.StatusSuperseded,
+		rspb.StatusDeployed,
+		rspb.StatusFailed,
+		rspb.StatusFailed,
+		rspb.StatusPendingUpgrade,
+	}
+	for i, status := range statuses {
+		rls := ReleaseTestData{Name: name, Version: i + 1, Status: status}.ToRelease()
+		assertErrNil(t.Fatal, storage.Create(rls), "Storing release 'angry-bird'")
+	}
+
+	excess, err := storage.ExcessHistory(name, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(excess) != 3 {
+		t.Fatalf("expected 3 revisions beyond the limit, got %d", len(excess))
+	}
+
+	deleted, err := storage.TrimHistory(name, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 2 || deleted[0].Version != 1 || deleted[1].Version != 3 {
+		t.Fatalf("expected revisions 1 and 3 to be deleted, got %v", deleted)
+	}
+
+	// The deployed and the latest revisions are kept beyond the limit.
+	if _, err := storage.TrimHistory(name, 1); err != nil {
+		t.Fatal(err)
+	}
+	hist, err := storage.History(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedVersions := map[int]bool{2: true, 5: true}
+	if len(hist) != len(expectedVersions) {
+		t.Fatalf("expected %d items in history, got %d", len(expectedVersions), len(hist))
+	}
+	for _, item := range hist {
+		if !expectedVersions[item.Version] {
+			t.Errorf("Release version %d, found when not expected", item.Version)
+		}
+	}
+}
+
 func TestStorageLast(t *testing.T) {
 	storage := Init(driver.NewMemory())
 
@@ -29,5 +29,10 @@ The above is equivalent to the YAML document
 
 This package provides a parser and utilities for converting the strvals format
 to other formats.
+
+The strict parsing mode, see ParseStrict, avoids the surprising type coercions
+of the default mode: values that would be ambiguous must be quoted, e.g.
+
+	tag="1.0",replicas=3
 */
 package strvals
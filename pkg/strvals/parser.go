@@ -20,9 +20,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"regexp"
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/yaml"
@@ -35,6 +37,10 @@ var ErrNotList = errors.New("not a list")
 // The default value 65536 = 1024 * 64
 var MaxIndex = 65536
 
+// numberPattern matches the values that the strict parsing mode reads as
+// numbers.
+var numberPattern = regexp.MustCompile(`^[+-]?(0[xXoObB][0-9a-fA-F_]+|(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?)$`)
+
 // MaxNestedNameLevel is the maximum level of nesting for a value name that
 // will be allowed.
 var MaxNestedNameLevel = 30
@@ -124,6 +130,65 @@ func ParseIntoFile(s string, dest map[string]interface{}, reader RunesValueReade
 	return t.parse()
 }
 
+// ParseStrict parses a set line in the strict parsing mode.
+//
+// In the strict parsing mode, a value is only typed when it is exactly true,
+// false, null, or a decimal integer that fits in an int64. Other spellings of
+// these values, e.g. True or 0123, and other numbers, e.g. 1.0 or integers
+// that do not fit, are rejected instead of being silently kept as strings.
+// Values in double quotes are always strings and may contain the special
+// characters, e.g. name="1.0" or name="a,b". In quoted values, only \" and \\
+// can be escaped. Outside of quoted values, only the special characters
+// \ , . = [ ] { } " can be escaped.
+//
+// Errors of the strict parsing mode are *ParseError.
+func ParseStrict(s string) (map[string]interface{}, error) {
+	vals := map[string]interface{}{}
+	err := ParseIntoStrict(s, vals)
+	return vals, err
+}
+
+// ParseIntoStrict is like ParseInto, but uses the strict parsing mode, see
+// ParseStrict.
+func ParseIntoStrict(s string, dest map[string]interface{}) error {
+	t := newStrictParser(bytes.NewBufferString(s), dest, false)
+	return t.parse()
+}
+
+// ParseIntoStringStrict is like ParseIntoString, but uses the strict parsing
+// mode for keys and quoted values, see ParseStrict.
+func ParseIntoStringStrict(s string, dest map[string]interface{}) error {
+	t := newStrictParser(bytes.NewBufferString(s), dest, true)
+	return t.parse()
+}
+
+// ParseIntoFileStrict is like ParseIntoFile, but uses the strict parsing mode
+// for keys and quoted paths, see ParseStrict.
+func ParseIntoFileStrict(s string, dest map[string]interface{}, reader RunesValueReader) error {
+	t := newFileParser(bytes.NewBufferString(s), dest, reader)
+	t.setStrict(s, reader)
+	return t.parse()
+}
+
+// ParseError is an error of the strict parsing mode. It reports the position
+// of the error in the set line.
+type ParseError struct {
+	// Line is the set line.
+	Line string
+	// Pos is the byte offset of the error in Line.
+	Pos int
+	// Err is the cause of the error.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("parse error at position %d of %q: %s", e.Pos, e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
 // RunesValueReader is a function that takes the given value (a slice of runes)
 // and returns the parsed value
 type RunesValueReader func([]rune) (interface{}, error)
@@ -138,6 +203,15 @@ type parser struct {
 	data      map[string]interface{}
 	reader    RunesValueReader
 	isjsonval bool
+
+	// strict enables the strict parsing mode, see ParseStrict.
+	strict bool
+	// line is the set line, used for the positions of strict parsing errors.
+	line string
+	// quotedReader reads the quoted values of the strict parsing mode.
+	quotedReader RunesValueReader
+	// isFileParser reports whether the values are paths of files.
+	isFileParser bool
 }
 
 func newParser(sc *bytes.Buffer, data map[string]interface{}, stringBool bool) *parser {
@@ -147,12 +221,34 @@ func newParser(sc *bytes.Buffer, data map[string]interface{}, stringBool bool) *
 	return &parser{sc: sc, data: data, reader: stringConverter}
 }
 
+func newStrictParser(sc *bytes.Buffer, data map[string]interface{}, stringBool bool) *parser {
+	t := &parser{sc: sc, data: data}
+	if stringBool {
+		t.setStrict(sc.String(), stringVal)
+	} else {
+		t.setStrict(sc.String(), strictTypedVal)
+	}
+	return t
+}
+
+// setStrict enables the strict parsing mode. Quoted values are read as strings
+// unless the parser reads files.
+func (t *parser) setStrict(line string, reader RunesValueReader) {
+	t.strict = true
+	t.line = line
+	t.reader = reader
+	t.quotedReader = stringVal
+	if t.isFileParser {
+		t.quotedReader = reader
+	}
+}
+
 func newJSONParser(sc *bytes.Buffer, data map[string]interface{}) *parser {
 	return &parser{sc: sc, data: data, reader: nil, isjsonval: true}
 }
 
 func newFileParser(sc *bytes.Buffer, data map[string]interface{}, reader RunesValueReader) *parser {
-	return &parser{sc: sc, data: data, reader: reader}
+	return &parser{sc: sc, data: data, reader: reader, isFileParser: true}
 }
 
 func (t *parser) parse() error {
@@ -164,8 +260,27 @@ func (t *parser) parse() error {
 		if err == io.EOF {
 			return nil
 		}
+		return t.parseError(err)
+	}
+}
+
+// pos returns the byte offset of the next rune in the set line.
+func (t *parser) pos() int {
+	return len(t.line) - t.sc.Len()
+}
+
+// errorAt returns an error at a position of the set line.
+func (t *parser) errorAt(pos int, format string, args ...interface{}) error {
+	return &ParseError{Line: t.line, Pos: pos, Err: errors.Errorf(format, args...)}
+}
+
+// parseError adds the position to the errors of the strict parsing mode.
+func (t *parser) parseError(err error) error {
+	var perr *ParseError
+	if !t.strict || errors.As(err, &perr) {
 		return err
 	}
+	return &ParseError{Line: t.line, Pos: t.pos(), Err: err}
 }
 
 func runeSet(r []rune) map[rune]bool {
@@ -184,14 +299,17 @@ func (t *parser) key(data map[string]interface{}, nestedNameLevel int) (reterr e
 	}()
 	stop := runeSet([]rune{'=', '[', ',', '.'})
 	for {
-		switch k, last, err := runesUntil(t.sc, stop); {
+		start := t.pos()
+		switch k, last, err := t.runesUntil(stop); {
 		case err != nil:
-			if len(k) == 0 {
+			if len(k) == 0 || err != io.EOF {
 				return err
 			}
 			return errors.Errorf("key %q has no value", string(k))
 			//set(data, string(k), "")
 			//return err
+		case t.strict && len(k) == 0 && last != ',':
+			return t.errorAt(start, "empty key")
 		case last == '[':
 			// We are in a list index context, so we need to set an index.
 			i, err := t.keyIndex()
@@ -248,11 +366,12 @@ func (t *parser) key(data map[string]interface{}, nestedNameLevel int) (reterr e
 				set(data, string(k), "")
 				return e
 			case ErrNotList:
-				rs, e := t.val()
+				valStart := t.pos()
+				rs, quoted, e := t.val()
 				if e != nil && e != io.EOF {
 					return e
 				}
-				v, e := t.reader(rs)
+				v, e := t.read(rs, quoted, valStart)
 				set(data, string(k), v)
 				return e
 			default:
@@ -324,7 +443,7 @@ func setIndex(list []interface{}, index int, val interface{}) (l2 []interface{},
 func (t *parser) keyIndex() (int, error) {
 	// First, get the key.
 	stop := runeSet([]rune{']'})
-	v, _, err := runesUntil(t.sc, stop)
+	v, _, err := t.runesUntil(stop)
 	if err != nil {
 		return 0, err
 	}
@@ -337,7 +456,7 @@ func (t *parser) listItem(list []interface{}, i, nestedNameLevel int) ([]interfa
 		return list, fmt.Errorf("negative %d index not allowed", i)
 	}
 	stop := runeSet([]rune{'[', '.', '='})
-	switch k, last, err := runesUntil(t.sc, stop); {
+	switch k, last, err := t.runesUntil(stop); {
 	case len(k) > 0:
 		return list, errors.Errorf("unexpected data at end of array index: %q", k)
 	case err != nil:
@@ -378,11 +497,12 @@ func (t *parser) listItem(list []interface{}, i, nestedNameLevel int) ([]interfa
 		case io.EOF:
 			return setIndex(list, i, "")
 		case ErrNotList:
-			rs, e := t.val()
+			valStart := t.pos()
+			rs, quoted, e := t.val()
 			if e != nil && e != io.EOF {
 				return list, e
 			}
-			v, e := t.reader(rs)
+			v, e := t.read(rs, quoted, valStart)
 			if e != nil {
 				return list, e
 			}
@@ -456,10 +576,84 @@ func (t *parser) emptyVal() (bool, error) {
 	}
 }
 
-func (t *parser) val() ([]rune, error) {
+func (t *parser) val() ([]rune, bool, error) {
 	stop := runeSet([]rune{','})
-	v, _, err := runesUntil(t.sc, stop)
-	return v, err
+	v, _, quoted, err := t.listVal(stop)
+	return v, quoted, err
+}
+
+// listVal reads a value up to a stop rune. In the strict parsing mode, the
+// value may be quoted, and the stop rune or the end of the line must follow
+// the closing quote.
+func (t *parser) listVal(stop map[rune]bool) ([]rune, rune, bool, error) {
+	if t.strict {
+		r, _, e := t.sc.ReadRune()
+		if e == nil && r == '"' {
+			v, err := t.quoted()
+			if err != nil {
+				return v, 0, true, err
+			}
+			r, _, e := t.sc.ReadRune()
+			if e != nil {
+				return v, r, true, e
+			}
+			if !inMap(r, stop) {
+				return v, r, true, t.errorAt(t.pos()-utf8.RuneLen(r), "unexpected %q after quoted value", r)
+			}
+			return v, r, true, nil
+		}
+		if e == nil {
+			t.sc.UnreadRune()
+		}
+	}
+	v, last, err := t.runesUntil(stop)
+	return v, last, false, err
+}
+
+// quoted reads a quoted value after the opening quote. Only \" and \\ can be
+// escaped.
+func (t *parser) quoted() ([]rune, error) {
+	start := t.pos() - 1
+	v := []rune{}
+	for {
+		pos := t.pos()
+		r, _, e := t.sc.ReadRune()
+		switch {
+		case e != nil:
+			return v, t.errorAt(start, "unterminated quoted value")
+		case r == '"':
+			return v, nil
+		case r == '\\':
+			next, _, e := t.sc.ReadRune()
+			if e != nil {
+				return v, t.errorAt(start, "unterminated quoted value")
+			}
+			if next != '"' && next != '\\' {
+				return v, t.errorAt(pos, "invalid escape sequence \\%c in quoted value", next)
+			}
+			v = append(v, next)
+		default:
+			v = append(v, r)
+		}
+	}
+}
+
+// read converts a value with the reader of the parser. In the strict parsing
+// mode, quoted values are converted with the quoted value reader, and errors
+// are reported at the start of the value.
+func (t *parser) read(rs []rune, quoted bool, start int) (interface{}, error) {
+	if !t.strict {
+		return t.reader(rs)
+	}
+	reader := t.reader
+	if quoted {
+		reader = t.quotedReader
+	}
+	v, err := reader(rs)
+	if err != nil {
+		return v, &ParseError{Line: t.line, Pos: start, Err: err}
+	}
+	return v, nil
 }
 
 func (t *parser) valList() ([]interface{}, error) {
@@ -476,7 +670,8 @@ func (t *parser) valList() ([]interface{}, error) {
 	list := []interface{}{}
 	stop := runeSet([]rune{',', '}'})
 	for {
-		switch rs, last, err := runesUntil(t.sc, stop); {
+		start := t.pos()
+		switch rs, last, quoted, err := t.listVal(stop); {
 		case err != nil:
 			if err == io.EOF {
 				err = errors.New("list must terminate with '}'")
@@ -487,11 +682,11 @@ func (t *parser) valList() ([]interface{}, error) {
 			if r, _, e := t.sc.ReadRune(); e == nil && r != ',' {
 				t.sc.UnreadRune()
 			}
-			v, e := t.reader(rs)
+			v, e := t.read(rs, quoted, start)
 			list = append(list, v)
 			return list, e
 		case last == ',':
-			v, e := t.reader(rs)
+			v, e := t.read(rs, quoted, start)
 			if e != nil {
 				return list, e
 			}
@@ -520,6 +715,38 @@ func runesUntil(in io.RuneReader, stop map[rune]bool) ([]rune, rune, error) {
 	}
 }
 
+// escapable are the runes that can be escaped in the strict parsing mode.
+const escapable = `\,.=[]{}"`
+
+// runesUntil is like the runesUntil function, but only allows the escapable
+// runes to be escaped in the strict parsing mode.
+func (t *parser) runesUntil(stop map[rune]bool) ([]rune, rune, error) {
+	if !t.strict {
+		return runesUntil(t.sc, stop)
+	}
+	v := []rune{}
+	for {
+		pos := t.pos()
+		switch r, _, e := t.sc.ReadRune(); {
+		case e != nil:
+			return v, r, e
+		case inMap(r, stop):
+			return v, r, nil
+		case r == '\\':
+			next, _, e := t.sc.ReadRune()
+			if e != nil {
+				return v, next, t.errorAt(pos, "unterminated escape sequence")
+			}
+			if !strings.ContainsRune(escapable, next) {
+				return v, next, t.errorAt(pos, "invalid escape sequence \\%c", next)
+			}
+			v = append(v, next)
+		default:
+			v = append(v, r)
+		}
+	}
+}
+
 func inMap(k rune, m map[rune]bool) bool {
 	_, ok := m[k]
 	return ok
@@ -557,3 +784,32 @@ func typedVal(v []rune, st bool) interface{} {
 
 	return val
 }
+
+func stringVal(rs []rune) (interface{}, error) {
+	return string(rs), nil
+}
+
+// strictTypedVal types a value of the strict parsing mode, see ParseStrict.
+func strictTypedVal(rs []rune) (interface{}, error) {
+	val := string(rs)
+	switch val {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	for _, lit := range []string{"true", "false", "null"} {
+		if strings.EqualFold(val, lit) {
+			return nil, errors.Errorf("ambiguous value %q: use %s, or quote the value to keep it as a string", val, lit)
+		}
+	}
+	if numberPattern.MatchString(val) {
+		if iv, err := strconv.ParseInt(val, 10, 64); err == nil && strconv.FormatInt(iv, 10) == val {
+			return iv, nil
+		}
+		return nil, errors.Errorf("ambiguous number %q: only decimal integers that fit in an int64 are typed, quote the value to keep it as a string", val)
+	}
+	return val, nil
+}
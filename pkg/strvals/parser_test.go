@@ -16,7 +16,9 @@ limitations under the License.
 package strvals
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
 	"sigs.k8s.io/yaml"
@@ -429,6 +431,102 @@ func TestParseSet(t *testing.T) {
 	}
 }
 
+func TestParseStrict(t *testing.T) {
+	tests := []struct {
+		str    string
+		expect map[string]interface{}
+	}{
+		{"name=value", map[string]interface{}{"name": "value"}},
+		{"a=true,b=false,c=null", map[string]interface{}{"a": true, "b": false, "c": nil}},
+		{"zero=0,neg=-12,big=9223372036854775807", map[string]interface{}{"zero": int64(0), "neg": int64(-12), "big": int64(9223372036854775807)}},
+		{`tag="1.0",flag="true",id="0123"`, map[string]interface{}{"tag": "1.0", "flag": "true", "id": "0123"}},
+		{`list="a,b",quote="say \"hi\"",path="C:\\"`, map[string]interface{}{"list": "a,b", "quote": `say "hi"`, "path": `C:\`}},
+		{`list={1,"2",three}`, map[string]interface{}{"list": []interface{}{int64(1), "2", "three"}}},
+		{`outer.inner[0].name=x`, map[string]interface{}{"outer": map[string]interface{}{"inner": []interface{}{map[string]interface{}{"name": "x"}}}}},
+		{`dotted\.key=a\,b`, map[string]interface{}{"dotted.key": "a,b"}},
+		{"empty=", map[string]interface{}{"empty": ""}},
+		{"version=v1.0", map[string]interface{}{"version": "v1.0"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseStrict(tt.str)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.str, err)
+		}
+		y1, err := yaml.Marshal(tt.expect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		y2, err := yaml.Marshal(got)
+		if err != nil {
+			t.Fatalf("Error serializing parsed value: %s", err)
+		}
+		if string(y1) != string(y2) {
+			t.Errorf("%s: Expected:\n%s\nGot:\n%s", tt.str, y1, y2)
+		}
+	}
+}
+
+func TestParseStrictErrors(t *testing.T) {
+	tests := []struct {
+		str string
+		pos int
+		msg string
+	}{
+		{"a=1,b=True", 6, `ambiguous value "True"`},
+		{"a=0123", 2, `ambiguous number "0123"`},
+		{"a=1.0", 2, `ambiguous number "1.0"`},
+		{"big=9223372036854775808", 4, `ambiguous number "9223372036854775808"`},
+		{"list={1,+2}", 8, `ambiguous number "+2"`},
+		{`a=x\qy`, 3, `invalid escape sequence \q`},
+		{`a="x\qy"`, 4, `invalid escape sequence \q in quoted value`},
+		{`a="unterminated`, 2, "unterminated quoted value"},
+		{`a="x"y`, 5, `unexpected 'y' after quoted value`},
+		{"=1", 0, "empty key"},
+		{"a..b=1", 2, "empty key"},
+		{"a", 1, `key "a" has no value`},
+	}
+	for _, tt := range tests {
+		_, err := ParseStrict(tt.str)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%s: Expected a *ParseError, got %v", tt.str, err)
+			continue
+		}
+		if perr.Pos != tt.pos || perr.Line != tt.str || !strings.Contains(perr.Err.Error(), tt.msg) {
+			t.Errorf("%s: Expected %q at position %d, got %q at position %d", tt.str, tt.msg, tt.pos, perr.Err, perr.Pos)
+		}
+	}
+}
+
+func TestParseIntoStrictVariants(t *testing.T) {
+	dest := map[string]interface{}{}
+	if err := ParseIntoStringStrict(`a=1,b="x,y"`, dest); err != nil {
+		t.Fatal(err)
+	}
+	if dest["a"] != "1" || dest["b"] != "x,y" {
+		t.Errorf("Expected string values, got %v", dest)
+	}
+
+	reader := func(rs []rune) (interface{}, error) {
+		return "contents of " + string(rs), nil
+	}
+	if err := ParseIntoFileStrict(`c="dir,1/file"`, dest, reader); err != nil {
+		t.Fatal(err)
+	}
+	if dest["c"] != "contents of dir,1/file" {
+		t.Errorf("Expected the quoted path to be read, got %v", dest["c"])
+	}
+
+	failing := func([]rune) (interface{}, error) {
+		return nil, fmt.Errorf("no such file")
+	}
+	err := ParseIntoFileStrict("a=x,d=missing", dest, failing)
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Pos != 2 {
+		t.Errorf("Expected a *ParseError at position 2, got %v", err)
+	}
+}
+
 func TestParseInto(t *testing.T) {
 	tests := []struct {
 		input  string
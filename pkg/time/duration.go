@@ -0,0 +1,73 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package time
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Day is the duration of a day in the durations parsed by ParseDuration.
+const Day = 24 * time.Hour
+
+// ParseDuration parses a duration like time.ParseDuration, e.g. "2h30m",
+// additionally accepting a number of days first, e.g. "1d" or "1d12h".
+func ParseDuration(s string) (time.Duration, error) {
+	value := s
+	sign := time.Duration(1)
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		if value[0] == '-' {
+			sign = -1
+		}
+		value = value[1:]
+	}
+	days, rest, ok := strings.Cut(value, "d")
+	if !ok {
+		return time.ParseDuration(s)
+	}
+	n, err := strconv.ParseFloat(days, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	d := time.Duration(n * float64(Day))
+	if rest != "" {
+		r, err := time.ParseDuration(rest)
+		if err != nil || r < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		d += r
+	}
+	return sign * d, nil
+}
+
+// ParseTimeout parses a timeout given either as a duration accepted by
+// ParseDuration, or as an absolute RFC 3339 deadline, e.g.
+// "2025-06-01T18:00:00Z", which is converted to the duration left from now. A
+// deadline that has passed is an error.
+func ParseTimeout(s string, now time.Time) (time.Duration, error) {
+	deadline, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return ParseDuration(s)
+	}
+	d := deadline.Sub(now)
+	if d <= 0 {
+		return 0, fmt.Errorf("the deadline %s has passed", s)
+	}
+	return d, nil
+}
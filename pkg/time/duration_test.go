@@ -0,0 +1,71 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package time
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	for _, tt := range []struct {
+		in     string
+		expect time.Duration
+		err    bool
+	}{
+		{in: "2h30m", expect: 2*time.Hour + 30*time.Minute},
+		{in: "300s", expect: 5 * time.Minute},
+		{in: "1d", expect: 24 * time.Hour},
+		{in: "1d12h", expect: 36 * time.Hour},
+		{in: "0.5d", expect: 12 * time.Hour},
+		{in: "-1d", expect: -24 * time.Hour},
+		{in: "0", expect: 0},
+		{in: "d", err: true},
+		{in: "1d-2h", err: true},
+		{in: "1dd", err: true},
+		{in: "5 minutes", err: true},
+	} {
+		d, err := ParseDuration(tt.in)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %s", tt.in, d)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", tt.in, err)
+		} else if d != tt.expect {
+			t.Errorf("%q: expected %s, got %s", tt.in, tt.expect, d)
+		}
+	}
+}
+
+func TestParseTimeout(t *testing.T) {
+	now := time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	d, err := ParseTimeout("2025-06-01T18:00:00Z", now)
+	if err != nil || d != 6*time.Hour {
+		t.Errorf("expected a 6h timeout until the deadline, got %s, %v", d, err)
+	}
+	d, err = ParseTimeout("1d", now)
+	if err != nil || d != Day {
+		t.Errorf("expected a 24h timeout, got %s, %v", d, err)
+	}
+	if _, err := ParseTimeout("2025-06-01T11:00:00Z", now); err == nil {
+		t.Error("expected an error for a deadline that has passed")
+	}
+}
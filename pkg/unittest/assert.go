@@ -0,0 +1,317 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unittest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Assertion checks the documents rendered by a test. Exactly one of the
+// checks must be set.
+type Assertion struct {
+	// Template limits the assertion to the documents rendered from a template.
+	Template string `json:"template,omitempty"`
+	// DocumentIndex limits the assertion to a single document, counted from
+	// 0 in the order the documents are rendered.
+	DocumentIndex *int `json:"documentIndex,omitempty"`
+	// Not inverts the check.
+	Not bool `json:"not,omitempty"`
+
+	// Equal checks that the value at a path equals a value.
+	Equal *PathValue `json:"equal,omitempty"`
+	// Contains checks that the list at a path contains a value.
+	Contains *PathValue `json:"contains,omitempty"`
+	// MatchRegex checks that the string at a path matches a pattern.
+	MatchRegex *PathPattern `json:"matchRegex,omitempty"`
+	// Exists checks that a path is set.
+	Exists *PathOnly `json:"exists,omitempty"`
+	// IsKind checks the kind of the documents.
+	IsKind *KindOf `json:"isKind,omitempty"`
+	// HasDocuments checks the number of rendered documents.
+	HasDocuments *DocumentCount `json:"hasDocuments,omitempty"`
+	// FailedTemplate checks that the templates fail to render.
+	FailedTemplate *TemplateError `json:"failedTemplate,omitempty"`
+}
+
+// PathValue is a value at a path of a document.
+//
+// Paths are dotted keys with list indexes and quoted keys in brackets, e.g.
+// spec.template.spec.containers[0].image or metadata.labels["app.kubernetes.io/name"].
+type PathValue struct {
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// PathPattern is a regular expression for the string at a path of a document.
+type PathPattern struct {
+	Path    string `json:"path"`
+	Pattern string `json:"pattern"`
+}
+
+// PathOnly is a path of a document.
+type PathOnly struct {
+	Path string `json:"path"`
+}
+
+// KindOf is the kind, and optionally the API version, of a document.
+type KindOf struct {
+	Of         string `json:"of"`
+	APIVersion string `json:"apiVersion,omitempty"`
+}
+
+// DocumentCount is the number of rendered documents.
+type DocumentCount struct {
+	Count int `json:"count"`
+}
+
+// TemplateError is the expected error of the rendering. An empty message
+// matches any error.
+type TemplateError struct {
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// document is a rendered document.
+type document struct {
+	template string
+	index    int
+	content  map[string]interface{}
+}
+
+func (d document) String() string {
+	return fmt.Sprintf("%s[%d]", d.template, d.index)
+}
+
+func (a *Assertion) validate() error {
+	checks := 0
+	for _, set := range []bool{a.Equal != nil, a.Contains != nil, a.MatchRegex != nil, a.Exists != nil, a.IsKind != nil, a.HasDocuments != nil, a.FailedTemplate != nil} {
+		if set {
+			checks++
+		}
+	}
+	if checks != 1 {
+		return errors.Errorf("expected exactly one check, found %d", checks)
+	}
+	if a.Equal != nil || a.Contains != nil || a.MatchRegex != nil || a.Exists != nil {
+		if _, err := parsePath(a.path()); err != nil {
+			return err
+		}
+	}
+	if a.MatchRegex != nil {
+		if _, err := regexp.Compile(a.MatchRegex.Pattern); err != nil {
+			return errors.Wrap(err, "invalid pattern")
+		}
+	}
+	if a.IsKind != nil && a.IsKind.Of == "" {
+		return errors.New("isKind requires 'of'")
+	}
+	return nil
+}
+
+// path returns the path of the check, if it has one.
+func (a *Assertion) path() string {
+	switch {
+	case a.Equal != nil:
+		return a.Equal.Path
+	case a.Contains != nil:
+		return a.Contains.Path
+	case a.MatchRegex != nil:
+		return a.MatchRegex.Path
+	case a.Exists != nil:
+		return a.Exists.Path
+	}
+	return ""
+}
+
+// evaluate runs the check against the documents rendered for the assertion,
+// or the error of the rendering, and returns the failures.
+func (a *Assertion) evaluate(docs []document, renderErr error) []string {
+	not := ""
+	if a.Not {
+		not = "not "
+	}
+
+	if a.FailedTemplate != nil {
+		failed := renderErr != nil && strings.Contains(renderErr.Error(), a.FailedTemplate.ErrorMessage)
+		switch {
+		case failed == a.Not && renderErr == nil:
+			return []string{fmt.Sprintf("expected the templates %sto fail to render", not)}
+		case failed == a.Not:
+			return []string{fmt.Sprintf("expected the templates %sto fail to render with %q, got %q", not, a.FailedTemplate.ErrorMessage, renderErr)}
+		}
+		return nil
+	}
+	if renderErr != nil {
+		return []string{fmt.Sprintf("the templates failed to render: %s", renderErr)}
+	}
+
+	if a.HasDocuments != nil {
+		if (len(docs) == a.HasDocuments.Count) == a.Not {
+			return []string{fmt.Sprintf("expected %sto have %d documents, got %d", not, a.HasDocuments.Count, len(docs))}
+		}
+		return nil
+	}
+
+	if a.DocumentIndex != nil {
+		i := *a.DocumentIndex
+		if i < 0 || i >= len(docs) {
+			return []string{fmt.Sprintf("document index %d is out of range, %d documents were rendered", i, len(docs))}
+		}
+		docs = docs[i : i+1]
+	}
+	if len(docs) == 0 {
+		return []string{"no documents were rendered"}
+	}
+
+	var failures []string
+	for _, doc := range docs {
+		subject, predicate, ok, detail := a.check(doc)
+		if ok == a.Not {
+			failures = append(failures, fmt.Sprintf("%s: expected %s %sto %s, %s", doc, subject, not, predicate, detail))
+		}
+	}
+	return failures
+}
+
+// check runs the check against a document. It returns the subject and the
+// predicate of the expectation, whether it holds and the actual state.
+func (a *Assertion) check(doc document) (subject, predicate string, ok bool, detail string) {
+	if a.IsKind != nil {
+		kind, _ := doc.content["kind"].(string)
+		apiVersion, _ := doc.content["apiVersion"].(string)
+		predicate = "be of kind " + a.IsKind.Of
+		ok = kind == a.IsKind.Of
+		if a.IsKind.APIVersion != "" {
+			predicate += " in " + a.IsKind.APIVersion
+			ok = ok && apiVersion == a.IsKind.APIVersion
+		}
+		return "the document", predicate, ok, fmt.Sprintf("got kind %s in %s", kind, apiVersion)
+	}
+
+	path := a.path()
+	// The path is checked by validate.
+	parts, _ := parsePath(path)
+	value, found := lookup(doc.content, parts)
+	detail = "got " + jsonValue(value)
+	if !found {
+		detail = "the path is not set"
+	}
+
+	switch {
+	case a.Equal != nil:
+		return path, "equal " + jsonValue(a.Equal.Value), found && reflect.DeepEqual(value, a.Equal.Value), detail
+	case a.Contains != nil:
+		list, isList := value.([]interface{})
+		ok = false
+		for _, item := range list {
+			if reflect.DeepEqual(item, a.Contains.Value) {
+				ok = true
+				break
+			}
+		}
+		if found && !isList {
+			detail = "got a non-list value " + jsonValue(value)
+		}
+		return path, "contain " + jsonValue(a.Contains.Value), ok, detail
+	case a.MatchRegex != nil:
+		s, isString := value.(string)
+		if found && !isString {
+			detail = "got a non-string value " + jsonValue(value)
+		}
+		return path, "match " + a.MatchRegex.Pattern, isString && regexp.MustCompile(a.MatchRegex.Pattern).MatchString(s), detail
+	default:
+		return path, "be set", found, detail
+	}
+}
+
+// parsePath splits a path into map keys and list indexes.
+func parsePath(path string) ([]interface{}, error) {
+	if path == "" {
+		return nil, errors.New("path is required")
+	}
+	var parts []interface{}
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			if i == 0 || i == len(path)-1 || path[i+1] == '.' || path[i+1] == '[' {
+				return nil, errors.Errorf("invalid path %q", path)
+			}
+			i++
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, errors.Errorf("invalid path %q: missing ]", path)
+			}
+			inner := path[i+1 : i+end]
+			if len(inner) >= 2 && (inner[0] == '"' || inner[0] == '\'') && inner[len(inner)-1] == inner[0] {
+				parts = append(parts, inner[1:len(inner)-1])
+			} else {
+				n, err := strconv.Atoi(inner)
+				if err != nil || n < 0 {
+					return nil, errors.Errorf("invalid path %q: %q is neither a list index nor a quoted key", path, inner)
+				}
+				parts = append(parts, n)
+			}
+			i += end + 1
+		default:
+			j := i
+			for j < len(path) && path[j] != '.' && path[j] != '[' {
+				j++
+			}
+			parts = append(parts, path[i:j])
+			i = j
+		}
+	}
+	return parts, nil
+}
+
+// lookup returns the value at a parsed path and whether it is set.
+func lookup(value interface{}, parts []interface{}) (interface{}, bool) {
+	for _, part := range parts {
+		switch p := part.(type) {
+		case string:
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if value, ok = m[p]; !ok {
+				return nil, false
+			}
+		case int:
+			l, ok := value.([]interface{})
+			if !ok || p >= len(l) {
+				return nil, false
+			}
+			value = l[p]
+		}
+	}
+	return value, true
+}
+
+func jsonValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
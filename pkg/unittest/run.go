@@ -0,0 +1,288 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unittest
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/engine"
+	"helm.sh/helm/v4/pkg/releaseutil"
+)
+
+// Result is the result of the test suites of a chart.
+type Result struct {
+	Chart  string         `json:"chart"`
+	Suites []*SuiteResult `json:"suites"`
+}
+
+// SuiteResult is the result of a test suite.
+type SuiteResult struct {
+	Name  string        `json:"name"`
+	Path  string        `json:"path"`
+	Tests []*TestResult `json:"tests"`
+}
+
+// TestResult is the result of a test.
+type TestResult struct {
+	Name     string   `json:"name"`
+	Passed   bool     `json:"passed"`
+	Failures []string `json:"failures,omitempty"`
+}
+
+// Counts returns the number of passed and failed tests.
+func (r *Result) Counts() (passed, failed int) {
+	for _, s := range r.Suites {
+		for _, t := range s.Tests {
+			if t.Passed {
+				passed++
+			} else {
+				failed++
+			}
+		}
+	}
+	return passed, failed
+}
+
+// Passed reports whether all tests passed.
+func (r *Result) Passed() bool {
+	_, failed := r.Counts()
+	return failed == 0
+}
+
+// Runner runs test suites against a chart.
+type Runner struct {
+	// Load loads the chart. The chart is loaded for every test, because the
+	// dependencies of a chart are modified when they are processed.
+	Load func() (*chart.Chart, error)
+	// Files are files of the chart by their path in the chart, e.g. the test
+	// suites and their values files. Values files are looked up here before
+	// the files of the loaded chart.
+	Files map[string][]byte
+	// KubeVersion is the Kubernetes version used unless a suite sets one.
+	KubeVersion *chartutil.KubeVersion
+}
+
+// Run runs the test suites.
+func (r *Runner) Run(chartName string, suites []*Suite) *Result {
+	result := &Result{Chart: chartName, Suites: []*SuiteResult{}}
+	for _, s := range suites {
+		result.Suites = append(result.Suites, r.RunSuite(s))
+	}
+	return result
+}
+
+// RunSuite runs the tests of a test suite.
+func (r *Runner) RunSuite(s *Suite) *SuiteResult {
+	result := &SuiteResult{Name: s.Name, Path: s.Path, Tests: []*TestResult{}}
+	for _, t := range s.Tests {
+		tr := &TestResult{Name: t.Name}
+		in, err := r.prepare(s, t)
+		if err != nil {
+			tr.Failures = []string{err.Error()}
+		} else {
+			docs, renderErr := in.render()
+			templates := t.Templates
+			if len(templates) == 0 {
+				templates = s.Templates
+			}
+			for i, a := range t.Asserts {
+				selected := templates
+				if a.Template != "" {
+					selected = []string{a.Template}
+				}
+				for _, failure := range a.evaluate(selectDocuments(docs, selected), renderErr) {
+					tr.Failures = append(tr.Failures, fmt.Sprintf("asserts[%d]: %s", i, failure))
+				}
+			}
+		}
+		tr.Passed = len(tr.Failures) == 0
+		result.Tests = append(result.Tests, tr)
+	}
+	return result
+}
+
+// renderInput is the input for rendering the templates of a chart for a test.
+type renderInput struct {
+	chart   *chart.Chart
+	values  map[string]interface{}
+	options chartutil.ReleaseOptions
+	caps    *chartutil.Capabilities
+}
+
+// prepare loads the chart and computes the values, the release options and the
+// capabilities for a test.
+func (r *Runner) prepare(s *Suite, t *Test) (*renderInput, error) {
+	c, err := r.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	vals := map[string]interface{}{}
+	for _, layer := range []struct {
+		files []string
+		set   map[string]interface{}
+	}{{s.Values, s.Set}, {t.Values, t.Set}} {
+		for _, f := range layer.files {
+			fileVals, err := r.readValues(c, f)
+			if err != nil {
+				return nil, err
+			}
+			vals = chartutil.MergeTables(fileVals, vals)
+		}
+		vals = chartutil.MergeTables(expandKeys(layer.set), vals)
+	}
+
+	caps := chartutil.DefaultCapabilities.Copy()
+	if r.KubeVersion != nil {
+		caps.KubeVersion = *r.KubeVersion
+	}
+	if s.Capabilities.KubeVersion != "" {
+		kubeVersion, err := chartutil.ParseKubeVersion(s.Capabilities.KubeVersion)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid kube version %q", s.Capabilities.KubeVersion)
+		}
+		caps.KubeVersion = *kubeVersion
+	}
+	caps.APIVersions = append(caps.APIVersions, s.Capabilities.APIVersions...)
+
+	options := chartutil.ReleaseOptions{
+		Name:      s.Release.Name,
+		Namespace: s.Release.Namespace,
+		Revision:  s.Release.Revision,
+		IsUpgrade: s.Release.IsUpgrade,
+		IsInstall: !s.Release.IsUpgrade,
+	}
+	if options.Name == "" {
+		options.Name = "release-name"
+	}
+	if options.Namespace == "" {
+		options.Namespace = "default"
+	}
+	if options.Revision == 0 {
+		options.Revision = 1
+	}
+
+	return &renderInput{chart: c, values: vals, options: options, caps: caps}, nil
+}
+
+// render renders the templates of the chart into documents. Its errors,
+// including the errors of invalid values, can be checked by assertions.
+func (in *renderInput) render() ([]document, error) {
+	if err := chartutil.ProcessDependencies(in.chart, in.values); err != nil {
+		return nil, err
+	}
+	vals, err := chartutil.ToRenderValues(in.chart, in.values, in.options, in.caps)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := engine.Render(in.chart, vals)
+	if err != nil {
+		return nil, err
+	}
+	return parseDocuments(in.chart.Name(), rendered)
+}
+
+// readValues reads a values file of the chart.
+func (r *Runner) readValues(c *chart.Chart, filename string) (map[string]interface{}, error) {
+	data, ok := r.Files[filename]
+	if !ok {
+		for _, f := range c.Files {
+			if f.Name == filename {
+				data, ok = f.Data, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return nil, errors.Errorf("values file %s not found in the chart", filename)
+	}
+	vals := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &vals); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", filename)
+	}
+	return vals, nil
+}
+
+// parseDocuments parses the rendered templates into documents, ordered by
+// template. Template names are made relative to the chart.
+func parseDocuments(chartName string, rendered map[string]string) ([]document, error) {
+	names := make([]string, 0, len(rendered))
+	for name := range rendered {
+		if path.Base(name) != "NOTES.txt" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var docs []document
+	for _, name := range names {
+		template := strings.TrimPrefix(name, chartName+"/")
+		index := 0
+		for _, content := range releaseutil.SplitDocuments(rendered[name]) {
+			var m map[string]interface{}
+			if err := yaml.Unmarshal([]byte(content), &m); err != nil {
+				return nil, errors.Wrapf(err, "YAML parse error on %s", template)
+			}
+			if m == nil {
+				continue
+			}
+			docs = append(docs, document{template: template, index: index, content: m})
+			index++
+		}
+	}
+	return docs, nil
+}
+
+// selectDocuments returns the documents rendered from the templates, or all
+// documents when no templates are given.
+func selectDocuments(docs []document, templates []string) []document {
+	if len(templates) == 0 {
+		return docs
+	}
+	var selected []document
+	for _, d := range docs {
+		for _, t := range templates {
+			if d.template == t {
+				selected = append(selected, d)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// expandKeys expands the dotted keys of values into nested maps.
+func expandKeys(values map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	for key, value := range values {
+		parts := strings.Split(key, ".")
+		nested := map[string]interface{}{parts[len(parts)-1]: value}
+		for i := len(parts) - 2; i >= 0; i-- {
+			nested = map[string]interface{}{parts[i]: nested}
+		}
+		out = chartutil.MergeTables(nested, out)
+	}
+	return out
+}
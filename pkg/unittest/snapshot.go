@@ -0,0 +1,99 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unittest
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// SnapshotDir is the directory of a chart that contains the snapshots of its
+// rendered manifests.
+const SnapshotDir = SuiteDir + "/__snapshot__"
+
+// NormalizedValue replaces the volatile values of manifests.
+const NormalizedValue = "<normalized>"
+
+// NormalizeRule replaces the volatile parts of rendered manifests, such as
+// timestamps and random values, before they are compared with a snapshot.
+type NormalizeRule struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// TimestampRule replaces RFC 3339 timestamps and the timestamps printed by
+// the now template function.
+var TimestampRule = NormalizeRule{
+	Pattern:     regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?( [+-]\d{4} \w+)?( m=[+-]\d+\.\d+)?`),
+	Replacement: "<timestamp>",
+}
+
+// DefaultNormalizeRules are the rules applied to every snapshot.
+var DefaultNormalizeRules = []NormalizeRule{TimestampRule}
+
+// NormalizeKey returns a rule that replaces the values of a YAML key, e.g.
+// a password generated with randAlphaNum.
+func NormalizeKey(key string) NormalizeRule {
+	return NormalizeRule{
+		Pattern:     regexp.MustCompile(`(?m)^(\s*(?:- )?` + regexp.QuoteMeta(key) + `:)[ \t]+\S.*$`),
+		Replacement: "${1} " + NormalizedValue,
+	}
+}
+
+// NormalizePattern returns a rule that replaces the matches of a regular
+// expression.
+func NormalizePattern(pattern string) (NormalizeRule, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return NormalizeRule{}, err
+	}
+	return NormalizeRule{Pattern: re, Replacement: NormalizedValue}, nil
+}
+
+// Normalize applies the rules to rendered manifests.
+func Normalize(manifests string, rules []NormalizeRule) string {
+	for _, r := range rules {
+		manifests = r.Pattern.ReplaceAllString(manifests, r.Replacement)
+	}
+	return manifests
+}
+
+// DiffSnapshot returns the unified diff between a snapshot and the rendered
+// manifests, or an empty string when they are equal.
+func DiffSnapshot(name, snapshot, manifests string) (string, error) {
+	if snapshot == manifests {
+		return "", nil
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(snapshot),
+		B:        splitLines(manifests),
+		FromFile: name,
+		ToFile:   "rendered",
+		Context:  3,
+	})
+}
+
+// splitLines splits a string into lines that keep their line breaks.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
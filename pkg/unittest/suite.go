@@ -0,0 +1,142 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package unittest runs the unit tests of a chart.
+
+The tests are defined in YAML test suites in the tests/ directory of a chart,
+in files named *_test.yaml. Every test renders the templates of the chart with
+a set of values and checks the rendered documents with assertions:
+
+	suite: deployment
+	templates:
+	  - templates/deployment.yaml
+	tests:
+	  - it: sets the number of replicas
+	    set:
+	      replicaCount: 3
+	    asserts:
+	      - isKind:
+	          of: Deployment
+	      - equal:
+	          path: spec.replicas
+	          value: 3
+*/
+package unittest // import "helm.sh/helm/v4/pkg/unittest"
+
+import (
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// SuiteDir is the directory of a chart that contains its test suites.
+const SuiteDir = "tests"
+
+// SuiteSuffix is the suffix of the names of test suite files.
+const SuiteSuffix = "_test.yaml"
+
+// Suite is a test suite of a chart.
+type Suite struct {
+	// Path is the path of the suite file in the chart.
+	Path string `json:"-"`
+	// Name is the name of the suite. It defaults to the path.
+	Name string `json:"suite,omitempty"`
+	// Templates limits the documents that are checked to the ones rendered
+	// from these templates, e.g. "templates/deployment.yaml".
+	Templates []string `json:"templates,omitempty"`
+	// Values are the paths of values files in the chart, applied to every
+	// test of the suite.
+	Values []string `json:"values,omitempty"`
+	// Set are values applied to every test of the suite. Keys may be dotted
+	// paths, e.g. "image.tag".
+	Set map[string]interface{} `json:"set,omitempty"`
+	// Release configures the release the templates are rendered for.
+	Release Release `json:"release,omitempty"`
+	// Capabilities configures the capabilities of the cluster.
+	Capabilities Capabilities `json:"capabilities,omitempty"`
+	Tests        []*Test      `json:"tests"`
+}
+
+// Release configures the release the templates of a chart are rendered for.
+type Release struct {
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Revision  int    `json:"revision,omitempty"`
+	IsUpgrade bool   `json:"upgrade,omitempty"`
+}
+
+// Capabilities configures the capabilities of the cluster the templates of a
+// chart are rendered for.
+type Capabilities struct {
+	KubeVersion string   `json:"kubeVersion,omitempty"`
+	APIVersions []string `json:"apiVersions,omitempty"`
+}
+
+// Test is a test of a suite.
+type Test struct {
+	// Name describes the expected behavior of the chart.
+	Name string `json:"it"`
+	// Values and Set are added to the values of the suite.
+	Values []string               `json:"values,omitempty"`
+	Set    map[string]interface{} `json:"set,omitempty"`
+	// Templates overrides the templates of the suite.
+	Templates []string     `json:"templates,omitempty"`
+	Asserts   []*Assertion `json:"asserts"`
+}
+
+// ParseSuite parses the test suite in a file of a chart.
+func ParseSuite(filename string, data []byte) (*Suite, error) {
+	s := &Suite{}
+	if err := yaml.UnmarshalStrict(data, s); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse test suite %s", filename)
+	}
+	s.Path = filename
+	if s.Name == "" {
+		s.Name = filename
+	}
+	if err := s.validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid test suite %s", filename)
+	}
+	return s, nil
+}
+
+// IsSuite reports whether a file of a chart is a test suite.
+func IsSuite(filename string) bool {
+	return path.Dir(filename) == SuiteDir && strings.HasSuffix(filename, SuiteSuffix)
+}
+
+func (s *Suite) validate() error {
+	if len(s.Tests) == 0 {
+		return errors.New("no tests")
+	}
+	for i, t := range s.Tests {
+		if t.Name == "" {
+			return errors.Errorf("tests[%d]: 'it' is required", i)
+		}
+		if len(t.Asserts) == 0 {
+			return errors.Errorf("test %q has no assertions", t.Name)
+		}
+		for j, a := range t.Asserts {
+			if err := a.validate(); err != nil {
+				return errors.Wrapf(err, "test %q: asserts[%d]", t.Name, j)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unittest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart"
+)
+
+func testChart() (*chart.Chart, error) {
+	return &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "example", Version: "0.1.0"},
+		Values:   map[string]interface{}{"name": "web", "ports": []interface{}{80}},
+		Templates: []*chart.File{
+			{Name: "templates/configmap.yaml", Data: []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}-{{ .Values.name }}
+  labels:
+    app.kubernetes.io/name: {{ .Values.name }}
+data:
+  ports: {{ .Values.ports | toJson | quote }}
+ports: {{ .Values.ports | toJson }}
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: {{ required "name is required" .Values.name }}
+`)},
+			{Name: "templates/NOTES.txt", Data: []byte("notes")},
+		},
+	}, nil
+}
+
+func TestParseSuite(t *testing.T) {
+	s, err := ParseSuite("tests/example_test.yaml", []byte(`tests:
+- it: works
+  asserts:
+  - exists:
+      path: metadata.labels["app.kubernetes.io/name"]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name != "tests/example_test.yaml" {
+		t.Errorf("expected the suite to be named after its path, got %q", s.Name)
+	}
+
+	for suite, expect := range map[string]string{
+		"tests: []": "no tests",
+		"tests:\n- asserts: [{exists: {path: a}}]":                          "'it' is required",
+		"tests:\n- it: x\n  asserts: []":                                    `test "x" has no assertions`,
+		"tests:\n- it: x\n  asserts: [{}]":                                  "expected exactly one check, found 0",
+		"tests:\n- it: x\n  asserts: [{exists: {path: a..b}}]":              `invalid path "a..b"`,
+		"tests:\n- it: x\n  asserts: [{matchRegex: {path: a, pattern: (}}]": "invalid pattern",
+		"tests:\n- it: x\n  asserts: [{equals: {path: a}}]":                 `unknown field "equals"`,
+	} {
+		if _, err := ParseSuite("tests/example_test.yaml", []byte(suite)); err == nil || !strings.Contains(err.Error(), expect) {
+			t.Errorf("%q: expected an error containing %q, got %v", suite, expect, err)
+		}
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	parts, err := parsePath(`spec.containers[1].env['A.B'].value`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []interface{}{"spec", "containers", 1, "env", "A.B", "value"}
+	if !reflect.DeepEqual(parts, expect) {
+		t.Errorf("expected %v, got %v", expect, parts)
+	}
+
+	for _, path := range []string{"", ".a", "a.", "a[", "a[x]", "a[-1]", "a.[0]"} {
+		if _, err := parsePath(path); err == nil {
+			t.Errorf("expected path %q to be invalid", path)
+		}
+	}
+}
+
+func TestRunSuite(t *testing.T) {
+	s, err := ParseSuite("tests/example_test.yaml", []byte(`suite: example
+release:
+  name: test
+values:
+- tests/values.yaml
+tests:
+- it: renders the documents
+  asserts:
+  - hasDocuments: {count: 2}
+  - isKind: {of: ConfigMap}
+    documentIndex: 0
+  - equal: {path: metadata.name, value: test-api}
+    documentIndex: 0
+  - equal: {path: 'metadata.labels["app.kubernetes.io/name"]', value: api}
+    documentIndex: 0
+  - matchRegex: {path: data.ports, pattern: '^\[80\]$'}
+    documentIndex: 0
+  - exists: {path: data.missing}
+    not: true
+- it: merges the set values
+  set:
+    ports: [80, 443]
+  asserts:
+  - contains: {path: ports, value: 443}
+    documentIndex: 0
+  - contains: {path: data.ports, value: 443}
+    documentIndex: 0
+- it: reports failures
+  asserts:
+  - equal: {path: metadata.name, value: other}
+    documentIndex: 1
+  - isKind: {of: Secret}
+  - hasDocuments: {count: 1}
+  - failedTemplate: {}
+  - equal: {path: spec.replicas, value: 1}
+    documentIndex: 5
+- it: checks render errors
+  set:
+    name: null
+  asserts:
+  - failedTemplate: {errorMessage: name is required}
+  - hasDocuments: {count: 2}
+- it: reports setup errors
+  values:
+  - tests/missing.yaml
+  asserts:
+  - hasDocuments: {count: 2}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &Runner{Load: testChart, Files: map[string][]byte{"tests/values.yaml": []byte("name: api\n")}}
+	result := r.RunSuite(s)
+
+	expect := map[string][]string{
+		"renders the documents": nil,
+		"merges the set values": {`asserts[1]: templates/configmap.yaml[0]: expected data.ports to contain 443, got a non-list value "[80,443]"`},
+		"reports failures": {
+			`asserts[0]: templates/configmap.yaml[1]: expected metadata.name to equal "other", got "api"`,
+			`asserts[1]: templates/configmap.yaml[0]: expected the document to be of kind Secret, got kind ConfigMap in v1`,
+			`asserts[2]: expected to have 1 documents, got 2`,
+			`asserts[3]: expected the templates to fail to render`,
+			`asserts[4]: document index 5 is out of range, 2 documents were rendered`,
+		},
+		"checks render errors": {`asserts[1]: the templates failed to render: execution error at (example/templates/configmap.yaml:14:11): name is required`},
+		"reports setup errors": {"values file tests/missing.yaml not found in the chart"},
+	}
+	if len(result.Tests) != len(expect) {
+		t.Fatalf("expected %d results, got %d", len(expect), len(result.Tests))
+	}
+	for _, tr := range result.Tests {
+		if !reflect.DeepEqual(tr.Failures, expect[tr.Name]) {
+			t.Errorf("%s: expected failures\n%q\ngot\n%q", tr.Name, expect[tr.Name], tr.Failures)
+		}
+		if tr.Passed != (len(expect[tr.Name]) == 0) {
+			t.Errorf("%s: unexpected result passed=%t", tr.Name, tr.Passed)
+		}
+	}
+
+	res := &Result{Suites: []*SuiteResult{result}}
+	if passed, failed := res.Counts(); passed != 1 || failed != 4 || res.Passed() {
+		t.Errorf("expected 1 passed and 4 failed tests, got %d and %d", passed, failed)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	pattern, err := NormalizePattern(`uid-[0-9a-f]+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := append(DefaultNormalizeRules, NormalizeKey("password"), pattern)
+	in := `metadata:
+  annotations:
+    created: 2024-05-01T10:20:30.123Z
+    now: "2024-05-01 10:20:30.123456789 +0000 UTC m=+0.012345678"
+    id: uid-3fa9
+stringData:
+  password: "aX9zQ"
+  passwords:
+    - password: abc
+`
+	expect := `metadata:
+  annotations:
+    created: <timestamp>
+    now: "<timestamp>"
+    id: <normalized>
+stringData:
+  password: <normalized>
+  passwords:
+    - password: <normalized>
+`
+	if got := Normalize(in, rules); got != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, got)
+	}
+
+	if _, err := NormalizePattern("("); err == nil {
+		t.Error("expected an invalid pattern to fail")
+	}
+}
+
+func TestDiffSnapshot(t *testing.T) {
+	diff, err := DiffSnapshot("snapshot.yaml", "a: 1\n", "a: 1\n")
+	if err != nil || diff != "" {
+		t.Errorf("expected no diff, got %q, %v", diff, err)
+	}
+	diff, err = DiffSnapshot("snapshot.yaml", "a: 1\nb: 2\n", "a: 1\nb: 3\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "--- snapshot.yaml\n+++ rendered\n@@ -1,2 +1,2 @@\n a: 1\n-b: 2\n+b: 3\n"
+	if diff != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, diff)
+	}
+}